@@ -0,0 +1,520 @@
+package caddyfile
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Token 指令中的一个参数，Raw 为原文，Value 为去引号后的语义值
+type Token struct {
+	Raw   string
+	Value string
+}
+
+// Node 一条指令（或站点、片段、全局选项块）。Leading 保存其前方的空白、换行与注释，
+// Trailing 保存同一行末尾的空白与注释，未修改的节点按原文输出
+type Node struct {
+	Leading  string
+	Tokens   []Token
+	Block    *Block
+	Trailing string
+
+	// StartLine、OpenLine、EndLine 为解析时的 0 起始行号，修改后不再更新
+	StartLine int
+	OpenLine  int
+	EndLine   int
+
+	seps     []string
+	detached bool
+}
+
+// Block 花括号块，Close 为最后一个子节点之后、右花括号之前的原文
+type Block struct {
+	OpenSep string
+	Nodes   []*Node
+	Close   string
+}
+
+// Document 完整的 Caddyfile，Tail 为最后一个节点之后的原文
+type Document struct {
+	Nodes   []*Node
+	Tail    string
+	Newline string
+	Indent  string
+}
+
+func Parse(input string) (*Document, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+	parser := &parser{tokens: tokens}
+	nodes, tail, err := parser.parseNodes(0)
+	if err != nil {
+		return nil, err
+	}
+	return &Document{
+		Nodes:   nodes,
+		Tail:    tail,
+		Newline: detectNewline(input),
+		Indent:  detectIndent(tokens),
+	}, nil
+}
+
+type parser struct {
+	tokens []rawToken
+	pos    int
+}
+
+func (p *parser) peek() *rawToken {
+	if p.pos >= len(p.tokens) {
+		return nil
+	}
+	return &p.tokens[p.pos]
+}
+
+func (p *parser) parseNodes(depth int) ([]*Node, string, error) {
+	nodes := make([]*Node, 0)
+	var trivia strings.Builder
+
+	for {
+		tok := p.peek()
+		if tok == nil {
+			if depth > 0 {
+				return nil, "", fmt.Errorf("缺少右花括号 }")
+			}
+			return nodes, trivia.String(), nil
+		}
+
+		switch tok.kind {
+		case tokenSpace, tokenNewline, tokenComment:
+			trivia.WriteString(tok.raw)
+			p.pos++
+		case tokenClose:
+			if depth == 0 {
+				return nil, "", fmt.Errorf("第 %d 行: 多余的右花括号 }", tok.line+1)
+			}
+			return nodes, trivia.String(), nil
+		default:
+			node, err := p.parseNode(depth, trivia.String())
+			if err != nil {
+				return nil, "", err
+			}
+			trivia.Reset()
+			nodes = append(nodes, node)
+		}
+	}
+}
+
+func (p *parser) parseNode(depth int, leading string) (*Node, error) {
+	first := p.peek()
+	node := &Node{Leading: leading, StartLine: first.line, OpenLine: -1}
+
+	pendingSep := ""
+	for {
+		tok := p.peek()
+		if tok == nil {
+			break
+		}
+		switch tok.kind {
+		case tokenWord:
+			if len(node.Tokens) > 0 {
+				node.seps = append(node.seps, pendingSep)
+			}
+			pendingSep = ""
+			node.Tokens = append(node.Tokens, Token{Raw: tok.raw, Value: tokenValue(tok.raw)})
+			node.EndLine = tok.line + strings.Count(tok.raw, "\n")
+			p.pos++
+			continue
+		case tokenSpace:
+			pendingSep += tok.raw
+			p.pos++
+			continue
+		case tokenNewline:
+			// 顶层站点地址以逗号结尾时可换行续写
+			if depth == 0 && len(node.Tokens) > 0 && strings.HasSuffix(node.Tokens[len(node.Tokens)-1].Value, ",") {
+				pendingSep += tok.raw
+				p.pos++
+				continue
+			}
+		case tokenOpen:
+			node.OpenLine = tok.line
+			p.pos++
+			children, closing, err := p.parseNodes(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			closeTok := p.peek()
+			if closeTok == nil || closeTok.kind != tokenClose {
+				return nil, fmt.Errorf("第 %d 行: 缺少右花括号 }", tok.line+1)
+			}
+			p.pos++
+			node.Block = &Block{OpenSep: pendingSep, Nodes: children, Close: closing}
+			node.EndLine = closeTok.line
+			pendingSep = ""
+		}
+		break
+	}
+
+	// 同一行的尾随空白与注释归属当前节点；若后面还有其他单词（例如 "} handle"），空白交还给下一个节点
+	trailing := pendingSep
+	save := p.pos
+	extra := ""
+	for tok := p.peek(); tok != nil && tok.kind == tokenSpace; tok = p.peek() {
+		extra += tok.raw
+		p.pos++
+	}
+	if tok := p.peek(); tok != nil && tok.kind == tokenComment {
+		extra += tok.raw
+		p.pos++
+	} else if tok != nil && (tok.kind == tokenWord || tok.kind == tokenOpen) {
+		p.pos = save
+		extra = ""
+	}
+	node.Trailing = trailing + extra
+	if len(node.Tokens) == 0 && node.Block == nil {
+		return nil, fmt.Errorf("第 %d 行: 无法解析的内容", first.line+1)
+	}
+	return node, nil
+}
+
+// String 还原为 Caddyfile 文本，未修改部分与输入逐字节一致
+func (d *Document) String() string {
+	var builder strings.Builder
+	for _, node := range d.Nodes {
+		node.write(&builder, true)
+	}
+	builder.WriteString(d.Tail)
+	return builder.String()
+}
+
+// String 返回节点自身的文本（不含 Leading）
+func (n *Node) String() string {
+	var builder strings.Builder
+	n.write(&builder, false)
+	return builder.String()
+}
+
+func (n *Node) write(builder *strings.Builder, withLeading bool) {
+	if withLeading {
+		builder.WriteString(n.Leading)
+	}
+	for i, token := range n.Tokens {
+		if i > 0 {
+			builder.WriteString(n.sep(i - 1))
+		}
+		builder.WriteString(token.Raw)
+	}
+	if n.Block != nil {
+		if len(n.Tokens) > 0 {
+			if n.Block.OpenSep == "" {
+				builder.WriteString(" ")
+			} else {
+				builder.WriteString(n.Block.OpenSep)
+			}
+		}
+		builder.WriteString("{")
+		for _, child := range n.Block.Nodes {
+			child.write(builder, true)
+		}
+		builder.WriteString(n.Block.Close)
+		builder.WriteString("}")
+	}
+	builder.WriteString(n.Trailing)
+}
+
+func (n *Node) sep(index int) string {
+	if index < len(n.seps) && n.seps[index] != "" {
+		return n.seps[index]
+	}
+	return " "
+}
+
+// Name 返回指令名（首个参数的语义值）
+func (n *Node) Name() string {
+	if n == nil || len(n.Tokens) == 0 {
+		return ""
+	}
+	return n.Tokens[0].Value
+}
+
+// Args 返回全部参数的语义值
+func (n *Node) Args() []string {
+	values := make([]string, 0, len(n.Tokens))
+	for _, token := range n.Tokens {
+		values = append(values, token.Value)
+	}
+	return values
+}
+
+// SetArgs 替换参数，值未变化的位置沿用原文（保留引号与 heredoc 写法）
+func (n *Node) SetArgs(values []string) {
+	tokens := make([]Token, 0, len(values))
+	for i, value := range values {
+		if i < len(n.Tokens) && n.Tokens[i].Value == value {
+			tokens = append(tokens, n.Tokens[i])
+			continue
+		}
+		tokens = append(tokens, Token{Raw: quoteValue(value), Value: value})
+	}
+	n.Tokens = tokens
+	if len(n.seps) > len(tokens)-1 && len(tokens) > 0 {
+		n.seps = n.seps[:len(tokens)-1]
+	}
+}
+
+// Children 返回块内子节点，无块时返回空
+func (n *Node) Children() []*Node {
+	if n == nil || n.Block == nil {
+		return nil
+	}
+	return n.Block.Nodes
+}
+
+// Find 返回第一个指定名称的子节点
+func (n *Node) Find(name string) *Node {
+	for _, child := range n.Children() {
+		if child.Name() == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// NewNode 创建新节点，挂载到文档时按周围缩进格式化
+func NewNode(args ...string) *Node {
+	node := &Node{StartLine: -1, OpenLine: -1, EndLine: -1, detached: true}
+	node.SetArgs(args)
+	return node
+}
+
+// WithBlock 为节点追加子块（新节点使用）
+func (n *Node) WithBlock(children ...*Node) *Node {
+	if n.Block == nil {
+		n.Block = &Block{}
+	}
+	n.Block.Nodes = append(n.Block.Nodes, children...)
+	return n
+}
+
+// ParseNode 解析单条指令文本（可含子块），用于原文编辑
+func ParseNode(text string) (*Node, error) {
+	doc, err := Parse(strings.TrimSpace(text))
+	if err != nil {
+		return nil, err
+	}
+	if len(doc.Nodes) != 1 {
+		return nil, fmt.Errorf("需要且只能包含一条指令")
+	}
+	node := doc.Nodes[0]
+	node.Leading = ""
+	node.Trailing = strings.TrimRight(node.Trailing, " \t")
+	node.detached = true
+	return node, nil
+}
+
+// Indentation 返回节点 Leading 中最后一行的缩进
+func (n *Node) Indentation() string {
+	leading := n.Leading
+	if idx := strings.LastIndex(leading, "\n"); idx >= 0 {
+		leading = leading[idx+1:]
+	}
+	return leading[:len(leading)-len(strings.TrimLeft(leading, " \t"))]
+}
+
+// Insert 在父节点（nil 表示顶层）的 index 位置插入子节点，index 越界时追加到末尾
+func (d *Document) Insert(parent *Node, index int, node *Node) error {
+	siblings, indent, err := d.siblings(parent)
+	if err != nil {
+		return err
+	}
+	if index < 0 || index > len(siblings) {
+		index = len(siblings)
+	}
+
+	if parent == nil {
+		switch {
+		case len(siblings) == 0:
+			node.Leading = strings.TrimLeft(node.Leading, "\r\n")
+		case index == 0:
+			node.Leading = ""
+			siblings[0].Leading = d.Newline + d.Newline + strings.TrimLeft(siblings[0].Leading, "\r\n")
+		default:
+			node.Leading = d.Newline + d.Newline
+		}
+	} else {
+		if parent.Block == nil {
+			parent.Block = &Block{Close: d.Newline + parent.Indentation()}
+		} else if len(parent.Block.Nodes) == 0 && !strings.Contains(parent.Block.Close, "\n") {
+			parent.Block.Close = d.Newline + parent.Indentation()
+		}
+		node.Leading = d.Newline + indent
+	}
+	d.place(node, indent)
+
+	updated := make([]*Node, 0, len(siblings)+1)
+	updated = append(updated, siblings[:index]...)
+	updated = append(updated, node)
+	updated = append(updated, siblings[index:]...)
+	d.setSiblings(parent, updated)
+	return nil
+}
+
+// Append 在父节点末尾追加子节点
+func (d *Document) Append(parent *Node, node *Node) error {
+	return d.Insert(parent, -1, node)
+}
+
+// Remove 移除子节点及其前方注释
+func (d *Document) Remove(parent *Node, node *Node) bool {
+	siblings, _, err := d.siblings(parent)
+	if err != nil {
+		return false
+	}
+	for i, sibling := range siblings {
+		if sibling != node {
+			continue
+		}
+		updated := append(append([]*Node{}, siblings[:i]...), siblings[i+1:]...)
+		// 与上一元素同行的注释（例如 "{ # 说明"）不属于被删除的节点，转交给后继内容
+		if sameLine := sameLineComment(node.Leading); sameLine != "" {
+			switch {
+			case i < len(updated):
+				updated[i].Leading = sameLine + updated[i].Leading
+			case parent != nil:
+				parent.Block.Close = sameLine + parent.Block.Close
+			default:
+				d.Tail = sameLine + d.Tail
+			}
+		}
+		if parent == nil && i == 0 && len(updated) > 0 {
+			updated[0].Leading = strings.TrimLeft(updated[0].Leading, "\r\n")
+		}
+		d.setSiblings(parent, updated)
+		return true
+	}
+	return false
+}
+
+func sameLineComment(leading string) string {
+	line := leading
+	if idx := strings.IndexAny(leading, "\r\n"); idx >= 0 {
+		line = leading[:idx]
+	}
+	if !strings.Contains(line, "#") {
+		return ""
+	}
+	return line
+}
+
+// Replace 用新节点替换原节点，沿用原节点的前导内容与缩进
+func (d *Document) Replace(parent *Node, old, replacement *Node) bool {
+	siblings, _, err := d.siblings(parent)
+	if err != nil {
+		return false
+	}
+	for i, sibling := range siblings {
+		if sibling != old {
+			continue
+		}
+		replacement.Leading = old.Leading
+		d.place(replacement, old.Indentation())
+		siblings[i] = replacement
+		return true
+	}
+	return false
+}
+
+func (d *Document) siblings(parent *Node) ([]*Node, string, error) {
+	if parent == nil {
+		return d.Nodes, "", nil
+	}
+	if parent.Block == nil {
+		return nil, parent.Indentation() + d.indentUnit(), nil
+	}
+	indent := parent.Indentation() + d.indentUnit()
+	if len(parent.Block.Nodes) > 0 {
+		indent = parent.Block.Nodes[0].Indentation()
+	}
+	return parent.Block.Nodes, indent, nil
+}
+
+func (d *Document) setSiblings(parent *Node, nodes []*Node) {
+	if parent == nil {
+		d.Nodes = nodes
+		return
+	}
+	if parent.Block == nil {
+		parent.Block = &Block{}
+	}
+	parent.Block.Nodes = nodes
+}
+
+// place 调整即将挂载的游离节点（NewNode/ParseNode 创建）：先按零缩进补齐格式，再整体平移到目标缩进
+func (d *Document) place(node *Node, indent string) {
+	if !node.detached {
+		return
+	}
+	d.format(node, "")
+	d.shiftIndent(node, indent)
+}
+
+func (d *Document) shiftIndent(node *Node, indent string) {
+	if node.Block == nil {
+		return
+	}
+	shift := func(text string) string {
+		return strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\n", d.Newline+indent)
+	}
+	for _, child := range node.Block.Nodes {
+		child.Leading = shift(child.Leading)
+		d.shiftIndent(child, indent)
+	}
+	node.Block.Close = shift(node.Block.Close)
+}
+
+// format 为新建节点的子块补齐换行与缩进，已有原文的子节点保持不变
+func (d *Document) format(node *Node, indent string) {
+	node.detached = false
+	if node.Block == nil {
+		return
+	}
+	childIndent := indent + d.indentUnit()
+	for _, child := range node.Block.Nodes {
+		if child.Leading == "" {
+			child.Leading = d.Newline + childIndent
+		}
+		d.format(child, childIndent)
+	}
+	if node.Block.Close == "" {
+		node.Block.Close = d.Newline + indent
+	}
+}
+
+func (d *Document) indentUnit() string {
+	if d.Indent == "" {
+		return "\t"
+	}
+	return d.Indent
+}
+
+func detectNewline(input string) string {
+	if strings.Contains(input, "\r\n") {
+		return "\r\n"
+	}
+	return "\n"
+}
+
+// detectIndent 取第一处缩进作为缩进单位，默认与 caddy fmt 一致使用制表符
+func detectIndent(tokens []rawToken) string {
+	for i := 1; i < len(tokens); i++ {
+		if tokens[i].kind != tokenSpace || tokens[i-1].kind != tokenNewline {
+			continue
+		}
+		if i+1 < len(tokens) && tokens[i+1].kind == tokenNewline {
+			continue
+		}
+		return tokens[i].raw
+	}
+	return "\t"
+}
@@ -0,0 +1,169 @@
+package caddyfile
+
+import (
+	"strings"
+	"testing"
+)
+
+const sampleCaddyfile = `{
+	email ops@example.com
+	order coraza_waf first
+}
+
+# 公共片段
+(common) {
+	encode gzip
+}
+
+example.com, www.example.com { # 主站
+	import common
+	@api path /api/*   # 接口
+	reverse_proxy @api 127.0.0.1:8080 {
+		lb_policy round_robin
+	}
+	respond "brace { inside } quote" 200
+	respond /heredoc <<HTML
+		<html>{not a block}</html>
+		HTML
+	header \
+		X-Frame-Options DENY
+}
+`
+
+func TestParseRoundTripIsLossless(t *testing.T) {
+	inputs := []string{
+		sampleCaddyfile,
+		strings.ReplaceAll(sampleCaddyfile, "\n", "\r\n"),
+		":80 {\n    respond `raw {text}`\n}",
+		"",
+		"\n\n# only comment\n",
+		"a.com {\n}\n\nb.com {\n\troute {\n\t\tfile_server\n\t}\n} # end\n",
+	}
+	for _, input := range inputs {
+		doc, err := Parse(input)
+		if err != nil {
+			t.Fatalf("parse %q: %v", input, err)
+		}
+		if got := doc.String(); got != input {
+			t.Fatalf("round trip mismatch:\nwant %q\ngot  %q", input, got)
+		}
+	}
+}
+
+func TestParseStructure(t *testing.T) {
+	doc, err := Parse(sampleCaddyfile)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(doc.Nodes) != 3 {
+		t.Fatalf("expected 3 top level nodes, got %d", len(doc.Nodes))
+	}
+	site := doc.Nodes[2]
+	if site.StartLine != 10 || site.OpenLine != 10 || site.EndLine != 22 {
+		t.Fatalf("unexpected site lines: %d %d %d", site.StartLine, site.OpenLine, site.EndLine)
+	}
+	names := make([]string, 0)
+	for _, child := range site.Children() {
+		names = append(names, child.Name())
+	}
+	if strings.Join(names, ",") != "import,@api,reverse_proxy,respond,respond,header" {
+		t.Fatalf("unexpected children: %v", names)
+	}
+	if got := site.Children()[3].Args()[1]; got != "brace { inside } quote" {
+		t.Fatalf("unexpected quoted value: %q", got)
+	}
+	if got := site.Children()[4].Args()[2]; got != "<html>{not a block}</html>" {
+		t.Fatalf("unexpected heredoc value: %q", got)
+	}
+	if got := site.Children()[5].Args(); len(got) != 3 || got[2] != "DENY" {
+		t.Fatalf("unexpected continued args: %v", got)
+	}
+	if !strings.Contains(site.Children()[1].Trailing, "# 接口") {
+		t.Fatalf("expected trailing comment to stay with matcher, got %q", site.Children()[1].Trailing)
+	}
+}
+
+func TestLexHeredocClosingMarkerFollowedByTokens(t *testing.T) {
+	input := "respond <<HTML\n\tHTMLish body\n\tHTML 200\nheader X-A b\n"
+	tokens, err := lex(input)
+	if err != nil {
+		t.Fatalf("lex: %v", err)
+	}
+	words := make([]string, 0)
+	for _, token := range tokens {
+		if token.kind == tokenWord {
+			words = append(words, tokenValue(token.raw))
+		}
+	}
+	want := []string{"respond", "HTMLish body", "200", "header", "X-A", "b"}
+	if strings.Join(words, "|") != strings.Join(want, "|") {
+		t.Fatalf("unexpected words: %q", words)
+	}
+	if tokens[len(tokens)-2].line != 3 {
+		t.Fatalf("unexpected line after heredoc: %d", tokens[len(tokens)-2].line)
+	}
+
+	doc, err := Parse(input)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if got := doc.Nodes[0].Args(); len(got) != 3 || got[2] != "200" {
+		t.Fatalf("unexpected respond args: %q", got)
+	}
+	if got := doc.String(); got != input {
+		t.Fatalf("round trip mismatch: %q", got)
+	}
+}
+
+func TestParseRejectsUnbalancedBraces(t *testing.T) {
+	for _, input := range []string{"a.com {\n", "}\n", "a.com {\n\trespond \"x\n}\n"} {
+		if _, err := Parse(input); err == nil {
+			t.Fatalf("expected %q to be rejected", input)
+		}
+	}
+}
+
+func TestDocumentEditsKeepUntouchedText(t *testing.T) {
+	doc, err := Parse(sampleCaddyfile)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	site := doc.Nodes[2]
+	proxy := site.Find("reverse_proxy")
+	proxy.SetArgs([]string{"reverse_proxy", "@api", "127.0.0.1:9090"})
+	if err := doc.Insert(site, 1, NewNode("encode", "zstd", "gzip")); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	if err := doc.Append(proxy, NewNode("health_uri", "/healthz")); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	doc.Remove(site, site.Find("header"))
+
+	want := strings.Replace(sampleCaddyfile, "127.0.0.1:8080 {\n\t\tlb_policy round_robin\n", "127.0.0.1:9090 {\n\t\tlb_policy round_robin\n\t\thealth_uri /healthz\n", 1)
+	want = strings.Replace(want, "\timport common\n", "\timport common\n\tencode zstd gzip\n", 1)
+	want = strings.Replace(want, "\theader \\\n\t\tX-Frame-Options DENY\n", "", 1)
+	if got := doc.String(); got != want {
+		t.Fatalf("unexpected edit result:\n%s", got)
+	}
+}
+
+func TestInsertParsedNodeShiftsIndentation(t *testing.T) {
+	doc, err := Parse("a.com {\n    respond ok\n}\n")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	node, err := ParseNode("handle /x {\n    respond x\n}")
+	if err != nil {
+		t.Fatalf("parse node: %v", err)
+	}
+	if err := doc.Append(doc.Nodes[0], node); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if err := doc.Append(nil, NewNode("b.com").WithBlock(NewNode("respond", "hello world"))); err != nil {
+		t.Fatalf("append site: %v", err)
+	}
+	want := "a.com {\n    respond ok\n    handle /x {\n        respond x\n    }\n}\n\nb.com {\n    respond \"hello world\"\n}\n"
+	if got := doc.String(); got != want {
+		t.Fatalf("unexpected result:\nwant %q\ngot  %q", want, got)
+	}
+}
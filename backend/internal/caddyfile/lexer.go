@@ -0,0 +1,269 @@
+package caddyfile
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenOpen
+	tokenClose
+	tokenSpace
+	tokenNewline
+	tokenComment
+)
+
+// rawToken 词法单元保留原文，拼接全部 raw 即可还原输入
+type rawToken struct {
+	kind tokenKind
+	raw  string
+	line int
+}
+
+// lex 按 Caddyfile 规则切分：空白分隔单词，单独的 { } 为块边界，
+// 单词开头的 " ` << 分别开启引号、反引号与 heredoc，# 仅在单词开头时表示注释
+func lex(input string) ([]rawToken, error) {
+	tokens := make([]rawToken, 0, len(input)/4)
+	line := 0
+	pos := 0
+
+	for pos < len(input) {
+		start := pos
+		ch := input[pos]
+
+		switch {
+		case ch == '\n' || (ch == '\r' && pos+1 < len(input) && input[pos+1] == '\n'):
+			if ch == '\r' {
+				pos++
+			}
+			pos++
+			tokens = append(tokens, rawToken{kind: tokenNewline, raw: input[start:pos], line: line})
+			line++
+			continue
+		case ch == ' ' || ch == '\t' || ch == '\r' || isLineContinuation(input, pos):
+			for pos < len(input) {
+				if input[pos] == ' ' || input[pos] == '\t' || (input[pos] == '\r' && !(pos+1 < len(input) && input[pos+1] == '\n')) {
+					pos++
+					continue
+				}
+				if isLineContinuation(input, pos) {
+					pos = skipLineContinuation(input, pos)
+					line++
+					continue
+				}
+				break
+			}
+			tokens = append(tokens, rawToken{kind: tokenSpace, raw: input[start:pos], line: line})
+			continue
+		case ch == '#':
+			for pos < len(input) && input[pos] != '\n' && !(input[pos] == '\r' && pos+1 < len(input) && input[pos+1] == '\n') {
+				pos++
+			}
+			tokens = append(tokens, rawToken{kind: tokenComment, raw: input[start:pos], line: line})
+			continue
+		}
+
+		end, lines, err := scanWord(input, pos)
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 行: %w", line+1, err)
+		}
+		word := input[start:end]
+		kind := tokenWord
+		switch word {
+		case "{":
+			kind = tokenOpen
+		case "}":
+			kind = tokenClose
+		}
+		tokens = append(tokens, rawToken{kind: kind, raw: word, line: line})
+		line += lines
+		pos = end
+	}
+	return tokens, nil
+}
+
+func isLineContinuation(input string, pos int) bool {
+	if input[pos] != '\\' {
+		return false
+	}
+	next := pos + 1
+	if next < len(input) && input[next] == '\r' {
+		next++
+	}
+	return next < len(input) && input[next] == '\n'
+}
+
+func skipLineContinuation(input string, pos int) int {
+	pos++
+	if input[pos] == '\r' {
+		pos++
+	}
+	return pos + 1
+}
+
+// scanWord 返回单词结束位置及其跨越的换行数
+func scanWord(input string, pos int) (int, int, error) {
+	switch {
+	case input[pos] == '"':
+		return scanQuoted(input, pos, '"', true)
+	case input[pos] == '`':
+		return scanQuoted(input, pos, '`', false)
+	case strings.HasPrefix(input[pos:], "<<"):
+		if end, lines, ok, err := scanHeredoc(input, pos); ok || err != nil {
+			return end, lines, err
+		}
+	}
+
+	end := pos
+	for end < len(input) {
+		ch := input[end]
+		if ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' || isLineContinuation(input, end) {
+			break
+		}
+		if ch == '\\' && end+1 < len(input) {
+			end += 2
+			continue
+		}
+		end++
+	}
+	return end, 0, nil
+}
+
+func scanQuoted(input string, pos int, quote byte, escapable bool) (int, int, error) {
+	lines := 0
+	end := pos + 1
+	for end < len(input) {
+		ch := input[end]
+		if escapable && ch == '\\' && end+1 < len(input) {
+			if input[end+1] == '\n' {
+				lines++
+			}
+			end += 2
+			continue
+		}
+		if ch == '\n' {
+			lines++
+		}
+		end++
+		if ch == quote {
+			// 引号结束后紧跟的非空白字符仍属于同一单词
+			for end < len(input) && !isWordBoundary(input, end) {
+				end++
+			}
+			return end, lines, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("引号未闭合")
+}
+
+func isWordBoundary(input string, pos int) bool {
+	ch := input[pos]
+	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r'
+}
+
+// scanHeredoc 识别 <<MARKER 形式的 heredoc，结束标记位于行首（允许缩进），
+// 与 Caddy 一致，标记后可继续跟随参数（如 "HTML 200"），这些参数按普通单词切分
+func scanHeredoc(input string, pos int) (int, int, bool, error) {
+	markerStart := pos + 2
+	markerEnd := markerStart
+	for markerEnd < len(input) && isHeredocMarkerChar(input[markerEnd]) {
+		markerEnd++
+	}
+	if markerEnd == markerStart {
+		return 0, 0, false, nil
+	}
+	rest := input[markerEnd:]
+	if !strings.HasPrefix(rest, "\n") && !strings.HasPrefix(rest, "\r\n") {
+		return 0, 0, false, nil
+	}
+	marker := input[markerStart:markerEnd]
+
+	lines := 0
+	cursor := markerEnd
+	for cursor < len(input) {
+		newline := strings.IndexByte(input[cursor:], '\n')
+		if newline < 0 {
+			break
+		}
+		lines++
+		lineStart := cursor + newline + 1
+		lineEnd := len(input)
+		if next := strings.IndexByte(input[lineStart:], '\n'); next >= 0 {
+			lineEnd = lineStart + next
+		}
+		if end, ok := heredocClosingEnd(input[lineStart:lineEnd], marker); ok {
+			return lineStart + end, lines, true, nil
+		}
+		cursor = lineStart
+	}
+	return 0, 0, true, fmt.Errorf("heredoc 缺少结束标记 %s", marker)
+}
+
+// heredocClosingEnd 判断一行是否以结束标记开头，返回标记在行内的结束位置
+func heredocClosingEnd(line, marker string) (int, bool) {
+	indent := len(line) - len(strings.TrimLeft(line, " \t"))
+	if !strings.HasPrefix(line[indent:], marker) {
+		return 0, false
+	}
+	end := indent + len(marker)
+	if end < len(line) && !isWordBoundary(line, end) {
+		return 0, false
+	}
+	return end, true
+}
+
+func isHeredocMarkerChar(ch byte) bool {
+	return ch == '_' || ch == '-' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9')
+}
+
+// tokenValue 返回单词的语义值：去掉引号、反转义，heredoc 去掉标记与结束缩进
+func tokenValue(raw string) string {
+	switch {
+	case len(raw) >= 2 && raw[0] == '"' && raw[len(raw)-1] == '"':
+		inner := raw[1 : len(raw)-1]
+		var builder strings.Builder
+		for i := 0; i < len(inner); i++ {
+			if inner[i] == '\\' && i+1 < len(inner) && (inner[i+1] == '"' || inner[i+1] == '\\') {
+				i++
+			}
+			builder.WriteByte(inner[i])
+		}
+		return builder.String()
+	case len(raw) >= 2 && raw[0] == '`' && raw[len(raw)-1] == '`':
+		return raw[1 : len(raw)-1]
+	case strings.HasPrefix(raw, "<<") && strings.Contains(raw, "\n"):
+		return heredocValue(raw)
+	}
+	return raw
+}
+
+func heredocValue(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+	if len(lines) < 2 {
+		return raw
+	}
+	closing := lines[len(lines)-1]
+	indent := closing[:len(closing)-len(strings.TrimLeft(closing, " \t"))]
+	body := lines[1 : len(lines)-1]
+	for i, line := range body {
+		body[i] = strings.TrimPrefix(line, indent)
+	}
+	return strings.Join(body, "\n")
+}
+
+// quoteValue 将语义值编码为单词，含空白、引号或为空时加双引号
+func quoteValue(value string) string {
+	if value == "" {
+		return `""`
+	}
+	if value == "{" || value == "}" || strings.ContainsAny(value, " \t\n\r\"`") || strings.HasPrefix(value, "#") {
+		if strings.Contains(value, "\n") && !strings.Contains(value, "`") {
+			return "`" + value + "`"
+		}
+		return `"` + strings.ReplaceAll(strings.ReplaceAll(value, `\`, `\\`), `"`, `\"`) + `"`
+	}
+	return value
+}
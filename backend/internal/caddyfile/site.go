@@ -0,0 +1,666 @@
+package caddyfile
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 站点内指令的类型，无法识别或结构超出模型表达能力的指令归为 raw，只能按原文编辑
+const (
+	KindMatcher      = "matcher"
+	KindReverseProxy = "reverse_proxy"
+	KindHeader       = "header"
+	KindRedirect     = "redir"
+	KindFileServer   = "file_server"
+	KindTLS          = "tls"
+	KindRaw          = "raw"
+)
+
+// Route 站点块内的一条指令及其结构化视图，只有与 Kind 对应的字段有值
+type Route struct {
+	Index        int
+	Kind         string
+	Raw          string
+	Matcher      *Matcher
+	ReverseProxy *ReverseProxy
+	Header       *Header
+	Redirect     *Redirect
+	FileServer   *FileServer
+	TLS          *TLS
+}
+
+type MatcherCondition struct {
+	Type   string
+	Values []string
+}
+
+// Matcher 命名匹配器，例如 @api path /api/*
+type Matcher struct {
+	Name       string
+	Conditions []MatcherCondition
+}
+
+type ReverseProxy struct {
+	Matcher   string
+	Upstreams []string
+	LbPolicy  string
+	HealthURI string
+}
+
+// HeaderOp 响应头操作，Op 为 set/add/delete/default/replace
+type HeaderOp struct {
+	Op      string
+	Field   string
+	Value   string
+	Replace string
+}
+
+type Header struct {
+	Matcher string
+	Ops     []HeaderOp
+}
+
+type Redirect struct {
+	Matcher string
+	To      string
+	Code    string
+}
+
+type FileServer struct {
+	Matcher string
+	Root    string
+	Browse  bool
+}
+
+// TLS 证书配置，Mode 为 internal/email/files，其他写法为 custom，只能按原文编辑
+type TLS struct {
+	Mode     string
+	Email    string
+	CertFile string
+	KeyFile  string
+}
+
+// IsSite 判断顶层节点是否为站点块（排除全局选项与 (snippet) 片段）
+func IsSite(node *Node) bool {
+	if node == nil || node.Block == nil || len(node.Tokens) == 0 {
+		return false
+	}
+	name := node.Name()
+	return !(strings.HasPrefix(name, "(") && strings.HasSuffix(name, ")"))
+}
+
+// Sites 返回全部站点块，顺序与文件一致
+func (d *Document) Sites() []*Node {
+	sites := make([]*Node, 0)
+	for _, node := range d.Nodes {
+		if IsSite(node) {
+			sites = append(sites, node)
+		}
+	}
+	return sites
+}
+
+// SiteAddresses 返回站点地址列表，兼容 "a, b" 与 "a,b" 两种写法
+func SiteAddresses(site *Node) []string {
+	addresses := make([]string, 0, len(site.Tokens))
+	for _, token := range site.Tokens {
+		for _, address := range strings.Split(token.Value, ",") {
+			if address = strings.TrimSpace(address); address != "" {
+				addresses = append(addresses, address)
+			}
+		}
+	}
+	return addresses
+}
+
+// SetSiteAddresses 更新站点地址，地址未变化时不改动原文
+func SetSiteAddresses(site *Node, addresses []string) error {
+	if err := validateAddresses(addresses); err != nil {
+		return err
+	}
+	if strings.Join(SiteAddresses(site), "\n") == strings.Join(addresses, "\n") {
+		return nil
+	}
+	site.SetArgs(addressTokens(addresses))
+	return nil
+}
+
+// NewSite 创建空站点块
+func NewSite(addresses []string) (*Node, error) {
+	if err := validateAddresses(addresses); err != nil {
+		return nil, err
+	}
+	site := NewNode(addressTokens(addresses)...)
+	site.Block = &Block{}
+	return site, nil
+}
+
+func addressTokens(addresses []string) []string {
+	values := make([]string, 0, len(addresses))
+	for i, address := range addresses {
+		if i < len(addresses)-1 {
+			address += ","
+		}
+		values = append(values, address)
+	}
+	return values
+}
+
+func validateAddresses(addresses []string) error {
+	if len(addresses) == 0 {
+		return fmt.Errorf("站点地址不能为空")
+	}
+	for _, address := range addresses {
+		if address == "" || strings.ContainsAny(address, " \t\r\n{}#,\"`") {
+			return fmt.Errorf("站点地址不合法: %q", address)
+		}
+		if strings.HasPrefix(address, "(") {
+			return fmt.Errorf("站点地址不能以 ( 开头: %s", address)
+		}
+	}
+	return nil
+}
+
+// SiteRoutes 返回站点块内全部指令的结构化视图
+func SiteRoutes(site *Node) []Route {
+	children := site.Children()
+	routes := make([]Route, 0, len(children))
+	for i, child := range children {
+		route := ParseRoute(child)
+		route.Index = i
+		routes = append(routes, route)
+	}
+	return routes
+}
+
+// ParseRoute 识别单条指令，解析失败时退化为 raw
+func ParseRoute(node *Node) Route {
+	route := Route{Kind: KindRaw, Raw: node.String()}
+	name := node.Name()
+	switch {
+	case strings.HasPrefix(name, "@"):
+		if matcher, ok := parseMatcher(node); ok {
+			route.Kind, route.Matcher = KindMatcher, matcher
+		}
+	case name == "reverse_proxy":
+		if proxy, ok := parseReverseProxy(node); ok {
+			route.Kind, route.ReverseProxy = KindReverseProxy, proxy
+		}
+	case name == "header":
+		if header, ok := parseHeader(node); ok {
+			route.Kind, route.Header = KindHeader, header
+		}
+	case name == "redir":
+		if redirect, ok := parseRedirect(node); ok {
+			route.Kind, route.Redirect = KindRedirect, redirect
+		}
+	case name == "file_server":
+		if fileServer, ok := parseFileServer(node); ok {
+			route.Kind, route.FileServer = KindFileServer, fileServer
+		}
+	case name == "tls":
+		if tls, ok := parseTLS(node); ok && tls.Mode != "custom" {
+			route.Kind, route.TLS = KindTLS, tls
+		}
+	}
+	return route
+}
+
+// AddRoute 在站点的 index 位置插入指令，index 越界时追加
+func (d *Document) AddRoute(site *Node, index int, route Route) (*Node, error) {
+	node, err := d.buildRoute(route)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.Insert(site, index, node); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// UpdateRoute 更新站点第 index 条指令：类型不变时就地改写参数，保留注释与未建模的子指令
+func (d *Document) UpdateRoute(site *Node, index int, route Route) error {
+	children := site.Children()
+	if index < 0 || index >= len(children) {
+		return fmt.Errorf("指令序号 %d 不存在", index)
+	}
+	node := children[index]
+	if route.Kind != KindRaw && ParseRoute(node).Kind == route.Kind {
+		return d.applyRoute(node, route)
+	}
+	replacement, err := d.buildRoute(route)
+	if err != nil {
+		return err
+	}
+	if route.Kind != KindRaw && replacement.Trailing == "" {
+		replacement.Trailing = node.Trailing
+	}
+	d.Replace(site, node, replacement)
+	return nil
+}
+
+// RemoveRoute 删除站点第 index 条指令
+func (d *Document) RemoveRoute(site *Node, index int) error {
+	children := site.Children()
+	if index < 0 || index >= len(children) {
+		return fmt.Errorf("指令序号 %d 不存在", index)
+	}
+	d.Remove(site, children[index])
+	return nil
+}
+
+func (d *Document) buildRoute(route Route) (*Node, error) {
+	if route.Kind == KindRaw {
+		if strings.TrimSpace(route.Raw) == "" {
+			return nil, fmt.Errorf("指令内容不能为空")
+		}
+		return ParseNode(route.Raw)
+	}
+	node := NewNode()
+	if err := d.applyRoute(node, route); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func (d *Document) applyRoute(node *Node, route Route) error {
+	switch route.Kind {
+	case KindMatcher:
+		if route.Matcher == nil {
+			break
+		}
+		return route.Matcher.apply(d, node)
+	case KindReverseProxy:
+		if route.ReverseProxy == nil {
+			break
+		}
+		return route.ReverseProxy.apply(d, node)
+	case KindHeader:
+		if route.Header == nil {
+			break
+		}
+		return route.Header.apply(d, node)
+	case KindRedirect:
+		if route.Redirect == nil {
+			break
+		}
+		return route.Redirect.apply(node)
+	case KindFileServer:
+		if route.FileServer == nil {
+			break
+		}
+		return route.FileServer.apply(d, node)
+	case KindTLS:
+		if route.TLS == nil {
+			break
+		}
+		return route.TLS.apply(node)
+	default:
+		return fmt.Errorf("不支持的指令类型: %s", route.Kind)
+	}
+	return fmt.Errorf("缺少 %s 指令内容", route.Kind)
+}
+
+// isMatcherToken 与 Caddy 一致：* 、/ 开头的路径与 @ 开头的命名匹配器都视为匹配器
+func isMatcherToken(value string) bool {
+	return value == "*" || strings.HasPrefix(value, "/") || strings.HasPrefix(value, "@")
+}
+
+// splitMatcher 拆分指令参数中的匹配器，min 为匹配器之后至少需要的参数个数
+func splitMatcher(args []string, min int) (string, []string) {
+	if len(args) > min && isMatcherToken(args[0]) {
+		return args[0], args[1:]
+	}
+	return "", args
+}
+
+func directiveArgs(name, matcher string, values ...string) []string {
+	args := []string{name}
+	if matcher != "" {
+		args = append(args, matcher)
+	}
+	return append(args, values...)
+}
+
+func validateMatcherToken(matcher string) error {
+	if matcher != "" && !isMatcherToken(matcher) {
+		return fmt.Errorf("匹配器需为 *、/path 或 @name: %s", matcher)
+	}
+	return nil
+}
+
+// setChild 设置子指令参数，values 为空时删除该子指令
+func (d *Document) setChild(parent *Node, name string, values []string) {
+	child := parent.Find(name)
+	switch {
+	case len(values) == 0 && child != nil:
+		d.Remove(parent, child)
+	case len(values) == 0:
+	case child != nil:
+		child.SetArgs(append([]string{name}, values...))
+	default:
+		_ = d.Append(parent, NewNode(append([]string{name}, values...)...))
+	}
+	dropEmptyBlock(parent)
+}
+
+// syncLines 按顺序改写子指令：已有行就地更新，多出的追加，缺少的删除
+func (d *Document) syncLines(parent *Node, lines [][]string) {
+	children := parent.Children()
+	for i, line := range lines {
+		if i < len(children) {
+			children[i].SetArgs(line)
+			continue
+		}
+		_ = d.Append(parent, NewNode(line...))
+	}
+	for i := len(lines); i < len(children); i++ {
+		d.Remove(parent, children[i])
+	}
+	dropEmptyBlock(parent)
+}
+
+func dropEmptyBlock(node *Node) {
+	if node.Block != nil && len(node.Block.Nodes) == 0 && strings.TrimSpace(node.Block.Close) == "" {
+		node.Block = nil
+	}
+}
+
+func hasNestedBlock(node *Node) bool {
+	for _, child := range node.Children() {
+		if child.Block != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func parseMatcher(node *Node) (*Matcher, bool) {
+	if hasNestedBlock(node) {
+		return nil, false
+	}
+	matcher := &Matcher{Name: node.Name()}
+	args := node.Args()[1:]
+	switch len(args) {
+	case 0:
+	case 1:
+		matcher.Conditions = append(matcher.Conditions, MatcherCondition{Type: "expression", Values: args})
+	default:
+		matcher.Conditions = append(matcher.Conditions, MatcherCondition{Type: args[0], Values: args[1:]})
+	}
+	for _, child := range node.Children() {
+		args := child.Args()
+		matcher.Conditions = append(matcher.Conditions, MatcherCondition{Type: args[0], Values: args[1:]})
+	}
+	return matcher, true
+}
+
+func (m *Matcher) apply(d *Document, node *Node) error {
+	if !strings.HasPrefix(m.Name, "@") || len(m.Name) < 2 || strings.ContainsAny(m.Name, " \t{}") {
+		return fmt.Errorf("匹配器名称需以 @ 开头: %s", m.Name)
+	}
+	if len(m.Conditions) == 0 {
+		return fmt.Errorf("匹配器 %s 至少需要一个条件", m.Name)
+	}
+	lines := make([][]string, 0, len(m.Conditions))
+	for _, condition := range m.Conditions {
+		if condition.Type == "" || len(condition.Values) == 0 {
+			return fmt.Errorf("匹配器 %s 的条件不完整", m.Name)
+		}
+		lines = append(lines, append([]string{condition.Type}, condition.Values...))
+	}
+
+	if len(lines) == 1 && node.Block == nil {
+		line := lines[0]
+		if line[0] == "expression" && len(line) == 2 {
+			line = line[1:]
+		}
+		node.SetArgs(append([]string{m.Name}, line...))
+		return nil
+	}
+	node.SetArgs([]string{m.Name})
+	d.syncLines(node, lines)
+	return nil
+}
+
+func parseReverseProxy(node *Node) (*ReverseProxy, bool) {
+	matcher, upstreams := splitMatcher(node.Args()[1:], 0)
+	proxy := &ReverseProxy{Matcher: matcher, Upstreams: append([]string{}, upstreams...)}
+	for _, child := range node.Children() {
+		args := child.Args()
+		switch args[0] {
+		case "to":
+			proxy.Upstreams = append(proxy.Upstreams, args[1:]...)
+		case "lb_policy":
+			proxy.LbPolicy = strings.Join(args[1:], " ")
+		case "health_uri":
+			proxy.HealthURI = strings.Join(args[1:], " ")
+		}
+	}
+	return proxy, true
+}
+
+func (r *ReverseProxy) apply(d *Document, node *Node) error {
+	if err := validateMatcherToken(r.Matcher); err != nil {
+		return err
+	}
+	if len(r.Upstreams) == 0 {
+		return fmt.Errorf("反向代理至少需要一个上游地址")
+	}
+	if to := node.Find("to"); to != nil {
+		node.SetArgs(directiveArgs("reverse_proxy", r.Matcher))
+		to.SetArgs(append([]string{"to"}, r.Upstreams...))
+	} else {
+		node.SetArgs(directiveArgs("reverse_proxy", r.Matcher, r.Upstreams...))
+	}
+	d.setChild(node, "lb_policy", strings.Fields(r.LbPolicy))
+	d.setChild(node, "health_uri", strings.Fields(r.HealthURI))
+	return nil
+}
+
+func parseHeader(node *Node) (*Header, bool) {
+	if hasNestedBlock(node) {
+		return nil, false
+	}
+	matcher, rest := splitMatcher(node.Args()[1:], 0)
+	header := &Header{Matcher: matcher}
+	if len(rest) > 0 {
+		op, ok := parseHeaderOp(rest)
+		if !ok {
+			return nil, false
+		}
+		header.Ops = append(header.Ops, op)
+	}
+	for _, child := range node.Children() {
+		op, ok := parseHeaderOp(child.Args())
+		if !ok {
+			return nil, false
+		}
+		header.Ops = append(header.Ops, op)
+	}
+	return header, true
+}
+
+func parseHeaderOp(args []string) (HeaderOp, bool) {
+	if len(args) == 0 || len(args) > 3 {
+		return HeaderOp{}, false
+	}
+	field := args[0]
+	op := HeaderOp{Op: "set", Field: field}
+	switch {
+	case strings.HasPrefix(field, "+"):
+		op.Op, op.Field = "add", field[1:]
+	case strings.HasPrefix(field, "-"):
+		op.Op, op.Field = "delete", field[1:]
+	case strings.HasPrefix(field, "?"):
+		op.Op, op.Field = "default", field[1:]
+	}
+	if len(args) > 1 {
+		op.Value = args[1]
+	}
+	if len(args) == 3 {
+		if op.Op != "set" {
+			return HeaderOp{}, false
+		}
+		op.Op, op.Replace = "replace", args[2]
+	}
+	return op, op.Field != ""
+}
+
+func headerOpArgs(op HeaderOp) ([]string, error) {
+	if op.Field == "" || strings.ContainsAny(op.Field, " \t") {
+		return nil, fmt.Errorf("响应头名称不合法: %q", op.Field)
+	}
+	switch op.Op {
+	case "", "set":
+		return []string{op.Field, op.Value}, nil
+	case "add":
+		return []string{"+" + op.Field, op.Value}, nil
+	case "default":
+		return []string{"?" + op.Field, op.Value}, nil
+	case "delete":
+		return []string{"-" + op.Field}, nil
+	case "replace":
+		return []string{op.Field, op.Value, op.Replace}, nil
+	}
+	return nil, fmt.Errorf("不支持的响应头操作: %s", op.Op)
+}
+
+func (h *Header) apply(d *Document, node *Node) error {
+	if err := validateMatcherToken(h.Matcher); err != nil {
+		return err
+	}
+	if len(h.Ops) == 0 {
+		return fmt.Errorf("header 至少需要一项操作")
+	}
+	lines := make([][]string, 0, len(h.Ops))
+	for _, op := range h.Ops {
+		line, err := headerOpArgs(op)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, line)
+	}
+
+	if len(lines) == 1 && node.Block == nil {
+		node.SetArgs(directiveArgs("header", h.Matcher, lines[0]...))
+		return nil
+	}
+	node.SetArgs(directiveArgs("header", h.Matcher))
+	d.syncLines(node, lines)
+	return nil
+}
+
+func parseRedirect(node *Node) (*Redirect, bool) {
+	if node.Block != nil {
+		return nil, false
+	}
+	matcher, rest := splitMatcher(node.Args()[1:], 1)
+	if len(rest) == 0 || len(rest) > 2 {
+		return nil, false
+	}
+	redirect := &Redirect{Matcher: matcher, To: rest[0]}
+	if len(rest) == 2 {
+		redirect.Code = rest[1]
+	}
+	return redirect, true
+}
+
+func (r *Redirect) apply(node *Node) error {
+	if err := validateMatcherToken(r.Matcher); err != nil {
+		return err
+	}
+	if strings.TrimSpace(r.To) == "" {
+		return fmt.Errorf("重定向目标不能为空")
+	}
+	values := []string{r.To}
+	if r.Code != "" {
+		values = append(values, r.Code)
+	}
+	node.SetArgs(directiveArgs("redir", r.Matcher, values...))
+	return nil
+}
+
+func parseFileServer(node *Node) (*FileServer, bool) {
+	matcher, rest := splitMatcher(node.Args()[1:], 0)
+	fileServer := &FileServer{Matcher: matcher}
+	for _, arg := range rest {
+		if arg != "browse" {
+			return nil, false
+		}
+		fileServer.Browse = true
+	}
+	for _, child := range node.Children() {
+		switch child.Name() {
+		case "root":
+			fileServer.Root = strings.Join(child.Args()[1:], " ")
+		case "browse":
+			fileServer.Browse = true
+		}
+	}
+	return fileServer, true
+}
+
+func (f *FileServer) apply(d *Document, node *Node) error {
+	if err := validateMatcherToken(f.Matcher); err != nil {
+		return err
+	}
+	browseChild := node.Find("browse")
+	if browseChild != nil && !f.Browse {
+		d.Remove(node, browseChild)
+		browseChild = nil
+	}
+	var values []string
+	if f.Browse && browseChild == nil {
+		values = append(values, "browse")
+	}
+	node.SetArgs(directiveArgs("file_server", f.Matcher, values...))
+	if f.Root != "" {
+		d.setChild(node, "root", []string{f.Root})
+	} else {
+		d.setChild(node, "root", nil)
+	}
+	return nil
+}
+
+func parseTLS(node *Node) (*TLS, bool) {
+	args := node.Args()[1:]
+	tls := &TLS{Mode: "custom"}
+	switch {
+	case node.Block != nil:
+		if issuer := node.Find("issuer"); issuer != nil && len(node.Children()) == 1 && len(args) == 0 &&
+			issuer.Block == nil && strings.Join(issuer.Args(), " ") == "issuer internal" {
+			tls.Mode = "internal"
+		}
+	case len(args) == 1 && args[0] == "internal":
+		tls.Mode = "internal"
+	case len(args) == 1 && strings.Contains(args[0], "@"):
+		tls.Mode, tls.Email = "email", args[0]
+	case len(args) == 2:
+		tls.Mode, tls.CertFile, tls.KeyFile = "files", args[0], args[1]
+	}
+	return tls, true
+}
+
+func (t *TLS) apply(node *Node) error {
+	var values []string
+	switch t.Mode {
+	case "internal":
+		values = []string{"internal"}
+	case "email":
+		if !strings.Contains(t.Email, "@") {
+			return fmt.Errorf("ACME 邮箱不合法: %s", t.Email)
+		}
+		values = []string{t.Email}
+	case "files":
+		if t.CertFile == "" || t.KeyFile == "" {
+			return fmt.Errorf("证书与私钥路径不能为空")
+		}
+		values = []string{t.CertFile, t.KeyFile}
+	default:
+		return fmt.Errorf("不支持的 TLS 模式: %s", t.Mode)
+	}
+	node.SetArgs(append([]string{"tls"}, values...))
+	node.Block = nil
+	return nil
+}
@@ -0,0 +1,150 @@
+package caddyfile
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSiteRoutesTypedViews(t *testing.T) {
+	doc, err := Parse(sampleCaddyfile + `
+static.example.com {
+	tls internal
+	@old {
+		path /old/*
+		method GET
+	}
+	redir @old /new/ 301
+	header {
+		+Vary Accept
+		-Server
+		?Cache-Control "max-age=60"
+	}
+	file_server browse {
+		root /srv/www
+	}
+}
+`)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	sites := doc.Sites()
+	if len(sites) != 2 {
+		t.Fatalf("expected 2 sites, got %d", len(sites))
+	}
+	if got := SiteAddresses(sites[0]); strings.Join(got, " ") != "example.com www.example.com" {
+		t.Fatalf("unexpected addresses: %v", got)
+	}
+
+	routes := SiteRoutes(sites[0])
+	if routes[0].Kind != KindRaw || routes[1].Kind != KindMatcher || routes[2].Kind != KindReverseProxy {
+		t.Fatalf("unexpected kinds: %s %s %s", routes[0].Kind, routes[1].Kind, routes[2].Kind)
+	}
+	if proxy := routes[2].ReverseProxy; proxy.Matcher != "@api" || proxy.Upstreams[0] != "127.0.0.1:8080" || proxy.LbPolicy != "round_robin" {
+		t.Fatalf("unexpected reverse proxy: %+v", proxy)
+	}
+
+	routes = SiteRoutes(sites[1])
+	if tls := routes[0].TLS; tls == nil || tls.Mode != "internal" {
+		t.Fatalf("unexpected tls: %+v", routes[0])
+	}
+	if matcher := routes[1].Matcher; len(matcher.Conditions) != 2 || matcher.Conditions[1].Type != "method" {
+		t.Fatalf("unexpected matcher: %+v", matcher)
+	}
+	if redirect := routes[2].Redirect; redirect.Matcher != "@old" || redirect.To != "/new/" || redirect.Code != "301" {
+		t.Fatalf("unexpected redirect: %+v", redirect)
+	}
+	if header := routes[3].Header; len(header.Ops) != 3 || header.Ops[1].Op != "delete" || header.Ops[2].Value != "max-age=60" {
+		t.Fatalf("unexpected header: %+v", header)
+	}
+	if fileServer := routes[4].FileServer; !fileServer.Browse || fileServer.Root != "/srv/www" {
+		t.Fatalf("unexpected file server: %+v", fileServer)
+	}
+}
+
+func TestUpdateRouteInPlacePreservesComments(t *testing.T) {
+	input := "# 站点\nexample.com {\n\t# 上游\n\treverse_proxy 10.0.0.1:80 10.0.0.2:80 { # 负载均衡\n\t\tlb_policy first\n\t\ttransport http {\n\t\t\tread_timeout 5s\n\t\t}\n\t}\n}\n"
+	doc, err := Parse(input)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	site := doc.Sites()[0]
+	err = doc.UpdateRoute(site, 0, Route{Kind: KindReverseProxy, ReverseProxy: &ReverseProxy{
+		Upstreams: []string{"10.0.0.1:80", "10.0.0.3:80"},
+		HealthURI: "/healthz",
+	}})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	want := "# 站点\nexample.com {\n\t# 上游\n\treverse_proxy 10.0.0.1:80 10.0.0.3:80 { # 负载均衡\n\t\ttransport http {\n\t\t\tread_timeout 5s\n\t\t}\n\t\thealth_uri /healthz\n\t}\n}\n"
+	if got := doc.String(); got != want {
+		t.Fatalf("unexpected result:\nwant %q\ngot  %q", want, got)
+	}
+}
+
+func TestAddUpdateRemoveRoutes(t *testing.T) {
+	doc, err := Parse("example.com {\n\trespond ok # 占位\n}\n")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	site := doc.Sites()[0]
+	if _, err := doc.AddRoute(site, 0, Route{Kind: KindHeader, Header: &Header{Ops: []HeaderOp{
+		{Op: "set", Field: "X-Frame-Options", Value: "DENY"},
+		{Op: "delete", Field: "Server"},
+	}}}); err != nil {
+		t.Fatalf("add header: %v", err)
+	}
+	if _, err := doc.AddRoute(site, -1, Route{Kind: KindMatcher, Matcher: &Matcher{
+		Name:       "@static",
+		Conditions: []MatcherCondition{{Type: "path", Values: []string{"/assets/*"}}},
+	}}); err != nil {
+		t.Fatalf("add matcher: %v", err)
+	}
+	if err := doc.UpdateRoute(site, 1, Route{Kind: KindRedirect, Redirect: &Redirect{To: "https://example.org{uri}", Code: "permanent"}}); err != nil {
+		t.Fatalf("replace respond: %v", err)
+	}
+	if _, err := doc.AddRoute(site, -1, Route{Kind: KindRaw, Raw: "handle @static {\n\tfile_server\n}"}); err != nil {
+		t.Fatalf("add raw: %v", err)
+	}
+	want := "example.com {\n\theader {\n\t\tX-Frame-Options DENY\n\t\t-Server\n\t}\n\tredir https://example.org{uri} permanent # 占位\n\t@static path /assets/*\n\thandle @static {\n\t\tfile_server\n\t}\n}\n"
+	if got := doc.String(); got != want {
+		t.Fatalf("unexpected result:\nwant %q\ngot  %q", want, got)
+	}
+
+	if err := doc.RemoveRoute(site, 0); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if err := doc.RemoveRoute(site, 9); err == nil {
+		t.Fatalf("expected out of range index to be rejected")
+	}
+	if strings.Contains(doc.String(), "header") {
+		t.Fatalf("expected header to be removed:\n%s", doc.String())
+	}
+}
+
+func TestSiteAddressEditing(t *testing.T) {
+	doc, err := Parse("a.com,\n    b.com {\n}\n")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	site := doc.Sites()[0]
+	if err := SetSiteAddresses(site, []string{"a.com", "b.com"}); err != nil || doc.String() != "a.com,\n    b.com {\n}\n" {
+		t.Fatalf("unchanged addresses should keep text, got %q err=%v", doc.String(), err)
+	}
+	if err := SetSiteAddresses(site, []string{"a.com", "c.com"}); err != nil || doc.String() != "a.com,\n    c.com {\n}\n" {
+		t.Fatalf("unexpected address update: %q err=%v", doc.String(), err)
+	}
+	if err := SetSiteAddresses(site, []string{"bad host"}); err == nil {
+		t.Fatalf("expected invalid address to be rejected")
+	}
+
+	added, err := NewSite([]string{":8080"})
+	if err != nil {
+		t.Fatalf("new site: %v", err)
+	}
+	if err := doc.Append(nil, added); err != nil {
+		t.Fatalf("append site: %v", err)
+	}
+	if got := doc.String(); got != "a.com,\n    c.com {\n}\n\n:8080 {\n}\n" {
+		t.Fatalf("unexpected document: %q", got)
+	}
+}
@@ -18,8 +18,10 @@ type Config struct {
 	CaddyLogPath        string `json:",optional"`
 	BackendLogPath      string `json:",optional"` // 后端日志文件/目录（用于入库）
 	CaddyRuntimeLogPath string `json:",optional"` // Caddy 后台日志文件/目录（用于入库）
+	WafAuditLogPath     string `json:",optional"` // Coraza WAF 审计日志文件/目录（用于入库）
 	Archive             ArchiveConf
 	Waf                 WafConf
+	Caddy               CaddyConf        `json:",optional"`
 	Notification        NotificationConf `json:",optional"`
 }
 
@@ -67,6 +69,18 @@ type WafConf struct {
 	CorazaReleaseAPI      string `json:",optional"`
 	CorazaCurrentVersion  string `json:",optional"`
 	CorazaCheckProxy      string `json:",optional"`
+	// 离线包受信公钥（minisign 格式，可多行），导入离线包时与已启用 minisign 更新源的公钥及本实例签名公钥合并；导入请求不能追加受信公钥
+	BundleTrustedKeys string `json:",optional"`
+}
+
+// CaddyConf Caddy 配置管理
+type CaddyConf struct {
+	// 开启后用户直接写入 Caddy 运行配置的入口都被拒绝，变更须经变更申请审批后应用；封禁同步、灰度自动转正与终止等系统流程不受限制
+	RequireChangeApproval bool `json:",optional"`
+	// 证书到期提醒阈值（天），默认 30、7、1
+	CertWarnDays []int `json:",optional"`
+	// 定时巡检证书时对配置中的站点做 TLS 握手探测，默认开启；关闭后站点证书没有到期时间，不会发送到期提醒
+	CertProbe bool `json:",default=true"`
 }
 
 // NotificationConf 通知配置
@@ -80,7 +94,7 @@ type NotificationConf struct {
 // ChannelConf 通知渠道配置
 type ChannelConf struct {
 	Name        string                 // 渠道名称
-	Type        string                 // 渠道类型: webhook, email, telegram, slack, wecom, wechat_mp, dingtalk
+	Type        string                 // 渠道类型: webhook, email, telegram, slack, wecom, wechat_mp, dingtalk, feishu, pagerduty, ntfy, gotify
 	Enabled     bool                   // 是否启用
 	Config      map[string]interface{} // 渠道配置 (根据类型不同而不同)
 	Events      []string               // 订阅的事件类型 (支持通配符)
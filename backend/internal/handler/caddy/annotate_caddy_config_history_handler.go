@@ -0,0 +1,26 @@
+package caddy
+
+import (
+	"net/http"
+
+	"logflux/common/result"
+	"logflux/internal/logic/caddy"
+	"logflux/internal/svc"
+	"logflux/internal/types"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+func AnnotateCaddyConfigHistoryHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.CaddyConfigHistoryAnnotateReq
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := caddy.NewAnnotateCaddyConfigHistoryLogic(r.Context(), svcCtx)
+		resp, err := l.AnnotateCaddyConfigHistory(&req)
+		result.HttpResult(r, w, resp, err)
+	}
+}
@@ -0,0 +1,25 @@
+package caddy
+
+import (
+	"net/http"
+
+	"logflux/internal/logic/caddy"
+	"logflux/internal/svc"
+	"logflux/internal/types"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+func DownloadWafMirrorSignatureHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.WafMirrorFileReq
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := caddy.NewDownloadWafMirrorSignatureLogic(withWafMirrorClientIP(r), svcCtx)
+		filePath, fileName, err := l.DownloadWafMirrorSignature(&req)
+		serveWafFile(w, r, filePath, fileName, err)
+	}
+}
@@ -0,0 +1,17 @@
+package caddy
+
+import (
+	"net/http"
+
+	"logflux/common/result"
+	"logflux/internal/logic/caddy"
+	"logflux/internal/svc"
+)
+
+func EvaluateWafBansHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := caddy.NewEvaluateWafBansLogic(r.Context(), svcCtx)
+		resp, err := l.EvaluateWafBans()
+		result.HttpResult(r, w, resp, err)
+	}
+}
@@ -0,0 +1,17 @@
+package caddy
+
+import (
+	"net/http"
+
+	"logflux/common/result"
+	"logflux/internal/logic/caddy"
+	"logflux/internal/svc"
+)
+
+func GetWafBundleKeyHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := caddy.NewGetWafBundleKeyLogic(r.Context(), svcCtx)
+		resp, err := l.GetWafBundleKey()
+		result.HttpResult(r, w, resp, err)
+	}
+}
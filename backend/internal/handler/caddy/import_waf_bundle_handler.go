@@ -0,0 +1,38 @@
+package caddy
+
+import (
+	"net/http"
+	"os"
+
+	"logflux/common/result"
+	logiccaddy "logflux/internal/logic/caddy"
+	"logflux/internal/svc"
+	"logflux/internal/types"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+func ImportWafBundleHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		r.Body = http.MaxBytesReader(w, r.Body, maxWafUploadRequestBytes(svcCtx))
+		uploadCtx, tempPath, err := stageWafUploadFile(ctx, r, svcCtx)
+		if err != nil {
+			httpx.ErrorCtx(ctx, w, err)
+			return
+		}
+		activateNow, err := parseWafUploadActivateNow(r)
+		if err != nil {
+			_ = os.Remove(tempPath)
+			httpx.ErrorCtx(ctx, w, err)
+			return
+		}
+
+		req := types.WafBundleImportReq{
+			ActivateNow: activateNow,
+		}
+		l := logiccaddy.NewImportWafBundleLogic(uploadCtx, svcCtx)
+		resp, err := l.ImportWafBundle(&req)
+		result.HttpResult(r, w, resp, err)
+	}
+}
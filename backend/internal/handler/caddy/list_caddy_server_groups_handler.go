@@ -0,0 +1,17 @@
+package caddy
+
+import (
+	"net/http"
+
+	"logflux/common/result"
+	"logflux/internal/logic/caddy"
+	"logflux/internal/svc"
+)
+
+func ListCaddyServerGroupsHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := caddy.NewListCaddyServerGroupsLogic(r.Context(), svcCtx)
+		resp, err := l.ListCaddyServerGroups()
+		result.HttpResult(r, w, resp, err)
+	}
+}
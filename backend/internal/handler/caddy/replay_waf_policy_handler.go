@@ -0,0 +1,91 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"logflux/common/result"
+	logiccaddy "logflux/internal/logic/caddy"
+	"logflux/internal/svc"
+	"logflux/internal/types"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// 回放语料只在内存中解析，限制上传大小
+const maxWafReplayCorpusBytes int64 = 16 * 1024 * 1024
+
+func ReplayWafPolicyHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.WafPolicyReplayReq
+
+		ctx := r.Context()
+		contentType := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Type")))
+		if strings.Contains(contentType, "multipart/form-data") {
+			r.Body = http.MaxBytesReader(w, r.Body, maxWafReplayCorpusBytes+1024*1024)
+			parsedReq, replayCtx, err := parseWafReplayMultipart(ctx, r)
+			if err != nil {
+				httpx.ErrorCtx(ctx, w, err)
+				return
+			}
+			req = *parsedReq
+			ctx = replayCtx
+		} else {
+			if err := httpx.Parse(r, &req); err != nil {
+				httpx.ErrorCtx(ctx, w, err)
+				return
+			}
+		}
+
+		l := logiccaddy.NewReplayWafPolicyLogic(ctx, svcCtx)
+		resp, err := l.ReplayWafPolicy(&req)
+		result.HttpResult(r, w, resp, err)
+	}
+}
+
+func parseWafReplayMultipart(ctx context.Context, r *http.Request) (*types.WafPolicyReplayReq, context.Context, error) {
+	req := &types.WafPolicyReplayReq{}
+	if err := httpx.ParsePath(r, req); err != nil {
+		return nil, ctx, err
+	}
+	if err := r.ParseMultipartForm(maxWafReplayCorpusBytes); err != nil {
+		return nil, ctx, fmt.Errorf("解析 multipart 表单失败: %w", err)
+	}
+
+	req.Source = strings.TrimSpace(r.FormValue("source"))
+	req.Host = strings.TrimSpace(r.FormValue("host"))
+	req.CorpusFormat = strings.TrimSpace(r.FormValue("corpusFormat"))
+	for field, target := range map[string]*int{"sampleSize": &req.SampleSize, "hours": &req.Hours} {
+		rawValue := strings.TrimSpace(r.FormValue(field))
+		if rawValue == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(rawValue)
+		if err != nil {
+			return nil, ctx, fmt.Errorf("参数 %s 无效", field)
+		}
+		*target = parsed
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		return req, ctx, nil
+	}
+	defer file.Close()
+
+	corpus, err := io.ReadAll(&io.LimitedReader{R: file, N: maxWafReplayCorpusBytes + 1})
+	if err != nil {
+		return nil, ctx, fmt.Errorf("读取回放语料失败: %w", err)
+	}
+	if int64(len(corpus)) > maxWafReplayCorpusBytes {
+		return nil, ctx, fmt.Errorf("回放语料过大: %d > %d", len(corpus), maxWafReplayCorpusBytes)
+	}
+	if req.Source == "" {
+		req.Source = "upload"
+	}
+	return req, logiccaddy.WithWafReplayCorpus(ctx, corpus), nil
+}
@@ -48,72 +48,89 @@ func UploadWafPackageHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 }
 
 func parseWafUploadMultipart(ctx context.Context, r *http.Request, svcCtx *svc.ServiceContext) (*types.WafUploadReq, context.Context, error) {
+	uploadCtx, tempPath, err := stageWafUploadFile(ctx, r, svcCtx)
+	if err != nil {
+		return nil, ctx, err
+	}
+
+	activateNow, err := parseWafUploadActivateNow(r)
+	if err != nil {
+		_ = os.Remove(tempPath)
+		return nil, ctx, err
+	}
+
+	req := &types.WafUploadReq{
+		Kind:        strings.TrimSpace(r.FormValue("kind")),
+		Version:     strings.TrimSpace(r.FormValue("version")),
+		Checksum:    strings.TrimSpace(r.FormValue("checksum")),
+		ActivateNow: activateNow,
+	}
+	if req.Kind == "" {
+		req.Kind = "crs"
+	}
+	return req, uploadCtx, nil
+}
+
+// stageWafUploadFile 解析 multipart 表单并把 file 字段保存到暂存目录，临时路径与原文件名写入 ctx
+func stageWafUploadFile(ctx context.Context, r *http.Request, svcCtx *svc.ServiceContext) (context.Context, string, error) {
 	maxBytes := svcCtx.Config.Waf.MaxPackageBytes
 	if maxBytes <= 0 {
 		maxBytes = waf.DefaultMaxPackageBytes
 	}
 
 	if err := r.ParseMultipartForm(maxBytes); err != nil {
-		return nil, ctx, fmt.Errorf("解析 multipart 表单失败: %w", err)
+		return ctx, "", fmt.Errorf("解析 multipart 表单失败: %w", err)
 	}
 
 	file, fileHeader, err := r.FormFile("file")
 	if err != nil {
-		return nil, ctx, fmt.Errorf("上传文件不能为空")
+		return ctx, "", fmt.Errorf("上传文件不能为空")
 	}
 	defer file.Close()
 
 	store := waf.NewStore(svcCtx.Config.Waf.WorkDir)
 	if err := store.EnsureDirs(); err != nil {
-		return nil, ctx, fmt.Errorf("准备上传工作区失败: %w", err)
+		return ctx, "", fmt.Errorf("准备上传工作区失败: %w", err)
 	}
 
 	tempName := fmt.Sprintf("upload_%d_%s", time.Now().UnixNano(), filepathSafeBase(fileHeader.Filename))
 	tempPath := store.StagePath(tempName)
 	targetFile, err := os.OpenFile(tempPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
 	if err != nil {
-		return nil, ctx, fmt.Errorf("创建临时上传文件失败: %w", err)
+		return ctx, "", fmt.Errorf("创建临时上传文件失败: %w", err)
 	}
 	limitedFile := &io.LimitedReader{R: file, N: maxBytes + 1}
 	writtenBytes, err := io.Copy(targetFile, limitedFile)
 	if err != nil {
 		_ = targetFile.Close()
 		_ = os.Remove(tempPath)
-		return nil, ctx, fmt.Errorf("保存上传文件失败: %w", err)
+		return ctx, "", fmt.Errorf("保存上传文件失败: %w", err)
 	}
 	if writtenBytes > maxBytes {
 		_ = targetFile.Close()
 		_ = os.Remove(tempPath)
-		return nil, ctx, fmt.Errorf("上传包过大: %d > %d", writtenBytes, maxBytes)
+		return ctx, "", fmt.Errorf("上传包过大: %d > %d", writtenBytes, maxBytes)
 	}
 	if err := targetFile.Close(); err != nil {
 		_ = os.Remove(tempPath)
-		return nil, ctx, fmt.Errorf("关闭上传文件失败: %w", err)
+		return ctx, "", fmt.Errorf("关闭上传文件失败: %w", err)
 	}
 
-	activateNow := false
-	if rawValue := strings.TrimSpace(r.FormValue("activateNow")); rawValue != "" {
-		parsed, parseErr := strconv.ParseBool(rawValue)
-		if parseErr != nil {
-			_ = os.Remove(tempPath)
-			return nil, ctx, fmt.Errorf("立即激活参数无效")
-		}
-		activateNow = parsed
-	}
+	uploadCtx := context.WithValue(ctx, "waf_upload_temp_path", tempPath)
+	uploadCtx = context.WithValue(uploadCtx, "waf_upload_file_name", fileHeader.Filename)
+	return uploadCtx, tempPath, nil
+}
 
-	req := &types.WafUploadReq{
-		Kind:        strings.TrimSpace(r.FormValue("kind")),
-		Version:     strings.TrimSpace(r.FormValue("version")),
-		Checksum:    strings.TrimSpace(r.FormValue("checksum")),
-		ActivateNow: activateNow,
+func parseWafUploadActivateNow(r *http.Request) (bool, error) {
+	rawValue := strings.TrimSpace(r.FormValue("activateNow"))
+	if rawValue == "" {
+		return false, nil
 	}
-	if req.Kind == "" {
-		req.Kind = "crs"
+	parsed, err := strconv.ParseBool(rawValue)
+	if err != nil {
+		return false, fmt.Errorf("立即激活参数无效")
 	}
-
-	uploadCtx := context.WithValue(ctx, "waf_upload_temp_path", tempPath)
-	uploadCtx = context.WithValue(uploadCtx, "waf_upload_file_name", fileHeader.Filename)
-	return req, uploadCtx, nil
+	return parsed, nil
 }
 
 func maxWafUploadRequestBytes(svcCtx *svc.ServiceContext) int64 {
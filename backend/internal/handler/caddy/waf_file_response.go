@@ -0,0 +1,49 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"logflux/internal/response"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// withWafMirrorClientIP 记录镜像请求来源地址，用于凭据的最近使用信息
+func withWafMirrorClientIP(r *http.Request) context.Context {
+	return context.WithValue(r.Context(), "waf_mirror_client_ip", httpx.GetRemoteAddr(r))
+}
+
+// serveWafFile 以附件形式返回规则包文件。
+// 出错时 HTTP 状态码与业务错误码一致，避免下游把错误响应当作规则包保存。
+func serveWafFile(w http.ResponseWriter, r *http.Request, filePath, fileName string, err error) {
+	if err != nil {
+		writeWafFileError(w, r, err)
+		return
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		writeWafFileError(w, r, fmt.Errorf("读取文件失败: %w", err))
+		return
+	}
+	defer file.Close()
+	stat, err := file.Stat()
+	if err != nil {
+		writeWafFileError(w, r, fmt.Errorf("读取文件失败: %w", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filepath.Base(fileName)))
+	http.ServeContent(w, r, filepath.Base(fileName), stat.ModTime(), file)
+}
+
+func writeWafFileError(w http.ResponseWriter, r *http.Request, err error) {
+	logx.WithContext(r.Context()).Errorf("WAF 文件下载失败: path=%s err=%v", r.URL.Path, err)
+	body := response.ErrorFromErr(err)
+	httpx.WriteJsonCtx(r.Context(), w, body.Code, body)
+}
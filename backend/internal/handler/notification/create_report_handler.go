@@ -0,0 +1,25 @@
+package notification
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+	"logflux/common/result"
+	"logflux/internal/logic/notification"
+	"logflux/internal/svc"
+	"logflux/internal/types"
+)
+
+func CreateReportHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.ReportReq
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := notification.NewCreateReportLogic(r.Context(), svcCtx)
+		resp, err := l.CreateReport(&req)
+		result.HttpResult(r, w, resp, err)
+	}
+}
@@ -0,0 +1,17 @@
+package notification
+
+import (
+	"net/http"
+
+	"logflux/common/result"
+	"logflux/internal/logic/notification"
+	"logflux/internal/svc"
+)
+
+func GetUserContactListHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := notification.NewGetUserContactListLogic(r.Context(), svcCtx)
+		resp, err := l.GetUserContactList()
+		result.HttpResult(r, w, resp, err)
+	}
+}
@@ -0,0 +1,38 @@
+package notification
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+	"logflux/common/result"
+	"logflux/internal/logic/notification"
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/internal/xerr"
+)
+
+// 单次入站告警负载上限
+const maxInboundAlertBytes = 1 << 20
+
+func ReceiveInboundAlertHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.InboundAlertReq
+		if err := httpx.ParsePath(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxInboundAlertBytes))
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, xerr.NewBusinessErrorWith("读取告警负载失败或负载过大"))
+			return
+		}
+
+		ctx := notification.WithInboundAlertClientIP(r.Context(), httpx.GetRemoteAddr(r))
+		ctx = notification.WithInboundAlertBody(ctx, body)
+		l := notification.NewReceiveInboundAlertLogic(ctx, svcCtx)
+		resp, err := l.ReceiveInboundAlert(&req)
+		result.HttpResult(r, w, resp, err)
+	}
+}
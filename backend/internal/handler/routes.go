@@ -62,6 +62,26 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 					Path:    "/caddy/server/:id",
 					Handler: caddy.DeleteCaddyServerHandler(serverCtx),
 				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/caddy/server-group",
+					Handler: caddy.ListCaddyServerGroupsHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/caddy/server-group",
+					Handler: caddy.CreateCaddyServerGroupHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPut,
+					Path:    "/caddy/server-group/:id",
+					Handler: caddy.UpdateCaddyServerGroupHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodDelete,
+					Path:    "/caddy/server-group/:id",
+					Handler: caddy.DeleteCaddyServerGroupHandler(serverCtx),
+				},
 				{
 					Method:  http.MethodGet,
 					Path:    "/caddy/server/:serverId/config",
@@ -82,11 +102,151 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 					Path:    "/caddy/server/:serverId/config/history/:historyId",
 					Handler: caddy.GetCaddyConfigHistoryDetailHandler(serverCtx),
 				},
+				{
+					Method:  http.MethodPut,
+					Path:    "/caddy/server/:serverId/config/history/:historyId",
+					Handler: caddy.AnnotateCaddyConfigHistoryHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/caddy/server/:serverId/config/diff",
+					Handler: caddy.DiffCaddyConfigHandler(serverCtx),
+				},
 				{
 					Method:  http.MethodPost,
 					Path:    "/caddy/server/:serverId/config/rollback",
 					Handler: caddy.RollbackCaddyConfigHandler(serverCtx),
 				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/caddy/server/:serverId/verify",
+					Handler: caddy.GetCaddyApplyVerifySettingHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPut,
+					Path:    "/caddy/server/:serverId/verify",
+					Handler: caddy.UpdateCaddyApplyVerifySettingHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/caddy/server/:serverId/verify/test",
+					Handler: caddy.TestCaddyApplyVerifyHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/caddy/server/:serverId/site",
+					Handler: caddy.ListCaddySitesHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/caddy/server/:serverId/site",
+					Handler: caddy.CreateCaddySiteHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPut,
+					Path:    "/caddy/server/:serverId/site/:siteIndex",
+					Handler: caddy.UpdateCaddySiteHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodDelete,
+					Path:    "/caddy/server/:serverId/site/:siteIndex",
+					Handler: caddy.DeleteCaddySiteHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/caddy/server/:serverId/site/:siteIndex/route",
+					Handler: caddy.CreateCaddyRouteHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPut,
+					Path:    "/caddy/server/:serverId/site/:siteIndex/route/:routeIndex",
+					Handler: caddy.UpdateCaddyRouteHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodDelete,
+					Path:    "/caddy/server/:serverId/site/:siteIndex/route/:routeIndex",
+					Handler: caddy.DeleteCaddyRouteHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/caddy/drift",
+					Handler: caddy.ListCaddyConfigDriftsHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/caddy/server/:serverId/drift/check",
+					Handler: caddy.CheckCaddyConfigDriftHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/caddy/drift/:id/adopt",
+					Handler: caddy.AdoptCaddyConfigDriftHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/caddy/drift/:id/reapply",
+					Handler: caddy.ReapplyCaddyConfigDriftHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/caddy/change-request",
+					Handler: caddy.ListCaddyChangeRequestsHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/caddy/change-request",
+					Handler: caddy.CreateCaddyChangeRequestHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/caddy/change-request/:id",
+					Handler: caddy.GetCaddyChangeRequestHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPut,
+					Path:    "/caddy/change-request/:id",
+					Handler: caddy.UpdateCaddyChangeRequestHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/caddy/change-request/:id/submit",
+					Handler: caddy.SubmitCaddyChangeRequestHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/caddy/change-request/:id/comment",
+					Handler: caddy.CommentCaddyChangeRequestHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/caddy/change-request/:id/approve",
+					Handler: caddy.ApproveCaddyChangeRequestHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/caddy/change-request/:id/reject",
+					Handler: caddy.RejectCaddyChangeRequestHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/caddy/change-request/:id/cancel",
+					Handler: caddy.CancelCaddyChangeRequestHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/caddy/change-request/:id/apply",
+					Handler: caddy.ApplyCaddyChangeRequestHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/caddy/certificate",
+					Handler: caddy.ListCaddyCertificatesHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/caddy/server/:serverId/certificate/check",
+					Handler: caddy.CheckCaddyCertificatesHandler(serverCtx),
+				},
 				{
 					Method:  http.MethodPost,
 					Path:    "/caddy/waf/engine/check",
@@ -142,6 +302,11 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 					Path:    "/caddy/waf/policy/:id/preview",
 					Handler: caddy.PreviewWafPolicyHandler(serverCtx),
 				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/caddy/waf/policy/:id/replay",
+					Handler: caddy.ReplayWafPolicyHandler(serverCtx),
+				},
 				{
 					Method:  http.MethodPost,
 					Path:    "/caddy/waf/policy/:id/publish",
@@ -192,6 +357,156 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 					Path:    "/caddy/waf/policy/exclusion/:id",
 					Handler: caddy.DeleteWafRuleExclusionHandler(serverCtx),
 				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/caddy/waf/policy/custom-rule",
+					Handler: caddy.ListWafCustomRulesHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/caddy/waf/policy/custom-rule",
+					Handler: caddy.CreateWafCustomRuleHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/caddy/waf/policy/custom-rule/validate",
+					Handler: caddy.ValidateWafCustomRuleHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPut,
+					Path:    "/caddy/waf/policy/custom-rule/:id",
+					Handler: caddy.UpdateWafCustomRuleHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodDelete,
+					Path:    "/caddy/waf/policy/custom-rule/:id",
+					Handler: caddy.DeleteWafCustomRuleHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/caddy/waf/policy/rollout",
+					Handler: caddy.ListWafPolicyRolloutsHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/caddy/waf/policy/:id/rollout",
+					Handler: caddy.StartWafPolicyRolloutHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/caddy/waf/policy/rollout/:id/evaluate",
+					Handler: caddy.EvaluateWafPolicyRolloutHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/caddy/waf/policy/rollout/:id/promote",
+					Handler: caddy.PromoteWafPolicyRolloutHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/caddy/waf/policy/rollout/:id/abort",
+					Handler: caddy.AbortWafPolicyRolloutHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/caddy/waf/ip-list",
+					Handler: caddy.ListWafIPListsHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/caddy/waf/ip-list",
+					Handler: caddy.CreateWafIPListHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPut,
+					Path:    "/caddy/waf/ip-list/:id",
+					Handler: caddy.UpdateWafIPListHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodDelete,
+					Path:    "/caddy/waf/ip-list/:id",
+					Handler: caddy.DeleteWafIPListHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/caddy/waf/ip-list/:id/entry",
+					Handler: caddy.ListWafIPListEntriesHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/caddy/waf/ip-list/:id/entry",
+					Handler: caddy.CreateWafIPListEntryHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodDelete,
+					Path:    "/caddy/waf/ip-list/entry/:id",
+					Handler: caddy.DeleteWafIPListEntryHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/caddy/waf/ban-rule",
+					Handler: caddy.ListWafBanRulesHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/caddy/waf/ban-rule",
+					Handler: caddy.CreateWafBanRuleHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPut,
+					Path:    "/caddy/waf/ban-rule/:id",
+					Handler: caddy.UpdateWafBanRuleHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodDelete,
+					Path:    "/caddy/waf/ban-rule/:id",
+					Handler: caddy.DeleteWafBanRuleHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/caddy/waf/ban",
+					Handler: caddy.ListWafBansHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/caddy/waf/ban",
+					Handler: caddy.CreateWafBanHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/caddy/waf/ban/:id/revoke",
+					Handler: caddy.RevokeWafBanHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/caddy/waf/ban/evaluate",
+					Handler: caddy.EvaluateWafBansHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/caddy/waf/rate-limit",
+					Handler: caddy.ListWafRateLimitZonesHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/caddy/waf/rate-limit",
+					Handler: caddy.CreateWafRateLimitZoneHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/caddy/waf/rate-limit/validate",
+					Handler: caddy.ValidateWafRateLimitZoneHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPut,
+					Path:    "/caddy/waf/rate-limit/:id",
+					Handler: caddy.UpdateWafRateLimitZoneHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodDelete,
+					Path:    "/caddy/waf/rate-limit/:id",
+					Handler: caddy.DeleteWafRateLimitZoneHandler(serverCtx),
+				},
 				{
 					Method:  http.MethodGet,
 					Path:    "/caddy/waf/policy/false-positive-feedback",
@@ -212,6 +527,16 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 					Path:    "/caddy/waf/policy/false-positive-feedback/batch-status",
 					Handler: caddy.BatchUpdateWafPolicyFalsePositiveFeedbackStatusHandler(serverCtx),
 				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/caddy/waf/policy/false-positive-feedback/:id/suggestion",
+					Handler: caddy.GetWafPolicyFalsePositiveFeedbackSuggestionHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/caddy/waf/policy/false-positive-feedback/:id/apply-suggestion",
+					Handler: caddy.ApplyWafPolicyFalsePositiveFeedbackSuggestionHandler(serverCtx),
+				},
 				{
 					Method:  http.MethodGet,
 					Path:    "/caddy/waf/policy/revision",
@@ -237,11 +562,66 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 					Path:    "/caddy/waf/release/:id/activate",
 					Handler: caddy.ActivateWafReleaseHandler(serverCtx),
 				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/caddy/waf/release/:id/diff",
+					Handler: caddy.GetWafReleaseDiffHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/caddy/waf/release/:id/bundle",
+					Handler: caddy.ExportWafReleaseBundleHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/caddy/waf/release/import",
+					Handler: caddy.ImportWafBundleHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/caddy/waf/bundle/key",
+					Handler: caddy.GetWafBundleKeyHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/caddy/waf/mirror",
+					Handler: caddy.ListWafMirrorClientsHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/caddy/waf/mirror",
+					Handler: caddy.CreateWafMirrorClientHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPut,
+					Path:    "/caddy/waf/mirror/:id",
+					Handler: caddy.UpdateWafMirrorClientHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodDelete,
+					Path:    "/caddy/waf/mirror/:id",
+					Handler: caddy.DeleteWafMirrorClientHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/caddy/waf/mirror/:id/rotate",
+					Handler: caddy.RotateWafMirrorClientHandler(serverCtx),
+				},
 				{
 					Method:  http.MethodPost,
 					Path:    "/caddy/waf/release/clear",
 					Handler: caddy.ClearWafReleasesHandler(serverCtx),
 				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/caddy/waf/distribute",
+					Handler: caddy.DistributeWafHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/caddy/waf/deployment",
+					Handler: caddy.ListWafServerDeploymentsHandler(serverCtx),
+				},
 				{
 					Method:  http.MethodPost,
 					Path:    "/caddy/waf/release/rollback",
@@ -308,6 +688,32 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 		rest.WithPrefix("/api"),
 	)
 
+	server.AddRoutes(
+		[]rest.Route{
+			{
+				Method:  http.MethodGet,
+				Path:    "/waf/mirror/:kind/manifest",
+				Handler: caddy.GetWafMirrorManifestHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/waf/mirror/:kind/:version/package.tar.gz",
+				Handler: caddy.DownloadWafMirrorPackageHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/waf/mirror/:kind/:version/package.tar.gz.minisig",
+				Handler: caddy.DownloadWafMirrorSignatureHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/waf/mirror/:kind/:version/bundle.tar.gz",
+				Handler: caddy.DownloadWafMirrorBundleHandler(serverCtx),
+			},
+		},
+		rest.WithPrefix("/api"),
+	)
+
 	server.AddRoutes(
 		rest.WithMiddlewares(
 			[]rest.Middleware{serverCtx.Permission},
@@ -482,6 +888,76 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 					Path:    "/notification/channel/test",
 					Handler: notification.TestChannelHandler(serverCtx),
 				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/notification/contact",
+					Handler: notification.GetUserContactListHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPut,
+					Path:    "/notification/contact/:userId",
+					Handler: notification.UpdateUserContactHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/notification/escalation",
+					Handler: notification.GetEscalationListHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/notification/escalation-policy",
+					Handler: notification.GetEscalationPolicyListHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/notification/escalation-policy",
+					Handler: notification.CreateEscalationPolicyHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPut,
+					Path:    "/notification/escalation-policy/:id",
+					Handler: notification.UpdateEscalationPolicyHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodDelete,
+					Path:    "/notification/escalation-policy/:id",
+					Handler: notification.DeleteEscalationPolicyHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/notification/escalation/:id/ack",
+					Handler: notification.AckEscalationHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/notification/escalation/:id/resolve",
+					Handler: notification.ResolveEscalationHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/notification/inbound-token",
+					Handler: notification.GetInboundTokenListHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/notification/inbound-token",
+					Handler: notification.CreateInboundTokenHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPut,
+					Path:    "/notification/inbound-token/:id",
+					Handler: notification.UpdateInboundTokenHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodDelete,
+					Path:    "/notification/inbound-token/:id",
+					Handler: notification.DeleteInboundTokenHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/notification/inbound-token/:id/rotate",
+					Handler: notification.RotateInboundTokenHandler(serverCtx),
+				},
 				{
 					Method:  http.MethodGet,
 					Path:    "/notification/log",
@@ -512,6 +988,31 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 					Path:    "/notification/read/all",
 					Handler: notification.ReadAllNotificationsHandler(serverCtx),
 				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/notification/report",
+					Handler: notification.GetReportListHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/notification/report",
+					Handler: notification.CreateReportHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPut,
+					Path:    "/notification/report/:id",
+					Handler: notification.UpdateReportHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodDelete,
+					Path:    "/notification/report/:id",
+					Handler: notification.DeleteReportHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/notification/report/:id/run",
+					Handler: notification.RunReportHandler(serverCtx),
+				},
 				{
 					Method:  http.MethodGet,
 					Path:    "/notification/rule",
@@ -532,6 +1033,26 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 					Path:    "/notification/rule/:id",
 					Handler: notification.DeleteRuleHandler(serverCtx),
 				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/notification/schedule",
+					Handler: notification.GetOnCallScheduleListHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/notification/schedule",
+					Handler: notification.CreateOnCallScheduleHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPut,
+					Path:    "/notification/schedule/:id",
+					Handler: notification.UpdateOnCallScheduleHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodDelete,
+					Path:    "/notification/schedule/:id",
+					Handler: notification.DeleteOnCallScheduleHandler(serverCtx),
+				},
 				{
 					Method:  http.MethodGet,
 					Path:    "/notification/template",
@@ -568,6 +1089,17 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 		rest.WithPrefix("/api"),
 	)
 
+	server.AddRoutes(
+		[]rest.Route{
+			{
+				Method:  http.MethodPost,
+				Path:    "/notification/inbound/:token",
+				Handler: notification.ReceiveInboundAlertHandler(serverCtx),
+			},
+		},
+		rest.WithPrefix("/api"),
+	)
+
 	server.AddRoutes(
 		rest.WithMiddlewares(
 			[]rest.Middleware{serverCtx.Permission},
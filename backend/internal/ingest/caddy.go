@@ -1,13 +1,9 @@
 package ingest
 
 import (
-	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"io"
 	"math"
-	"os"
 	"path/filepath"
 	"regexp"
 	"strconv"
@@ -15,14 +11,11 @@ import (
 	"sync"
 	"time"
 
-	"logflux/internal/utils/safego"
 	"logflux/model"
 
-	"github.com/nxadm/tail"
 	"github.com/zeromicro/go-zero/core/logx"
 
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 )
 
 // Log Format:
@@ -30,28 +23,47 @@ import (
 
 var logRegex = regexp.MustCompile(`^\[(.*?)\] "(.*?)" "(.*?)" "(.*?)" "(.*?)" "(.*?) (.*?) (.*?)" (\d+) (\d+) "(.*?)" "(.*?)" "(.*?)"$`)
 
-const defaultScanIntervalSec = 60
-
-type dirWatcher struct {
-	stopCh   chan struct{}
-	interval time.Duration
-}
-
 type CaddyIngestor struct {
-	db          *gorm.DB
-	tails       map[string]*tail.Tail
-	dirWatchers map[string]dirWatcher
-	dirFiles    map[string]map[string]struct{}
+	*logTailer
+	db *gorm.DB
+	// pathServers 监听路径（文件或目录）到所属 Caddy 节点的映射，入库时写入 server_id
+	pathServers map[string]uint
 	mu          sync.Mutex
 }
 
 func NewCaddyIngestor(db *gorm.DB) *CaddyIngestor {
-	return &CaddyIngestor{
+	i := &CaddyIngestor{
 		db:          db,
-		tails:       make(map[string]*tail.Tail),
-		dirWatchers: make(map[string]dirWatcher),
-		dirFiles:    make(map[string]map[string]struct{}),
+		pathServers: make(map[string]uint),
+	}
+	i.logTailer = newLogTailer(db, "Caddy 日志", i.tailLine)
+	return i
+}
+
+// SetServer 记录监听路径所属的 Caddy 节点，目录下的文件沿用目录的节点
+func (i *CaddyIngestor) SetServer(path string, serverID uint) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return
+	}
+	path = filepath.Clean(path)
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if serverID == 0 {
+		delete(i.pathServers, path)
+		return
+	}
+	i.pathServers[path] = serverID
+}
+
+func (i *CaddyIngestor) serverFor(filePath string) uint {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if serverID, ok := i.pathServers[filePath]; ok {
+		return serverID
 	}
+	return i.pathServers[filepath.Dir(filePath)]
 }
 
 func (i *CaddyIngestor) ParseLine(line string) (*model.CaddyLog, error) {
@@ -118,10 +130,18 @@ func (i *CaddyIngestor) parseTime(ts string) (time.Time, error) {
 }
 
 func (i *CaddyIngestor) Ingest(line string) error {
+	return i.ingestFile("", line)
+}
+
+// ingestFile 入库来自 filePath 的一行日志，并按路径标记所属节点
+func (i *CaddyIngestor) ingestFile(filePath, line string) error {
 	logEntry, err := i.ParseLine(line)
 	if err != nil {
 		return err
 	}
+	if filePath != "" {
+		logEntry.ServerID = i.serverFor(filePath)
+	}
 	if err := i.db.Create(logEntry).Error; err != nil {
 		logx.Errorf("写入数据库失败: %v", err)
 		return err
@@ -140,247 +160,19 @@ func (i *CaddyIngestor) StartWithInterval(filePath string, scanIntervalSec int)
 	}
 	filePath = filepath.Clean(filePath)
 
-	if info, err := os.Stat(filePath); err == nil && info.IsDir() {
-		i.startDir(filePath, scanIntervalSec)
-		return
-	}
-
-	i.startFile(filePath)
+	i.start(filePath, scanIntervalSec)
 }
 
-func (i *CaddyIngestor) startFile(filePath string) bool {
-	i.mu.Lock()
-	if _, exists := i.tails[filePath]; exists {
-		i.mu.Unlock()
-		return false
-	}
-	i.mu.Unlock()
-
-	startOffset := i.resolveStartOffset(filePath)
-
-	t, err := tail.TailFile(filePath, tail.Config{
-		Follow:   true,
-		ReOpen:   true,
-		Poll:     true,
-		Location: &tail.SeekInfo{Offset: startOffset, Whence: io.SeekStart},
-	})
-	if err != nil {
-		logx.Errorf("监听文件失败: %v", err)
-		return false
-	}
-
-	i.mu.Lock()
-	if _, exists := i.tails[filePath]; exists {
-		i.mu.Unlock()
-		t.Stop()
-		t.Cleanup()
+// tailLine 入库监听到的一行日志，失败时不推进游标
+func (i *CaddyIngestor) tailLine(filePath, line string) bool {
+	if err := i.ingestFile(filePath, line); err != nil {
+		// keep noisy errors in stdout for now
+		logx.Errorf("日志入库失败: %v", err)
 		return false
 	}
-	i.tails[filePath] = t
-	i.mu.Unlock()
-
-	logx.Infof("开始监听文件: %s", filePath)
-
-	watchPath := filePath
-	safego.New(context.Background(), "Caddy 日志文件监听").Go(func() {
-		path := watchPath
-		for line := range t.Lines {
-			if line == nil {
-				continue
-			}
-			if line.Err != nil {
-				logx.Errorf("读取监听内容失败: %v", line.Err)
-				continue
-			}
-			if err := i.Ingest(line.Text); err != nil {
-				// keep noisy errors in stdout for now
-				logx.Errorf("日志入库失败: %v", err)
-				continue
-			}
-			if err := i.saveOffset(path, line.SeekInfo.Offset); err != nil {
-				logx.Errorf("保存日志采集游标失败: %v", err)
-			}
-		}
-	})
-
 	return true
 }
 
-func (i *CaddyIngestor) resolveStartOffset(filePath string) int64 {
-	var cursor model.LogIngestCursor
-	if err := i.db.Where("file_path = ?", filePath).Take(&cursor).Error; err != nil {
-		if !errors.Is(err, gorm.ErrRecordNotFound) {
-			logx.Errorf("加载日志采集游标失败: %v", err)
-		}
-		return 0
-	}
-
-	offset := cursor.Offset
-	if offset < 0 {
-		return 0
-	}
-
-	info, err := os.Stat(filePath)
-	if err != nil {
-		return offset
-	}
-	if offset > info.Size() {
-		return 0
-	}
-
-	return offset
-}
-
-func (i *CaddyIngestor) saveOffset(filePath string, offset int64) error {
-	if offset < 0 {
-		offset = 0
-	}
-
-	cursor := model.LogIngestCursor{
-		FilePath: filePath,
-		Offset:   offset,
-	}
-
-	return i.db.Clauses(clause.OnConflict{
-		Columns: []clause.Column{{Name: "file_path"}},
-		DoUpdates: clause.Assignments(map[string]any{
-			"offset":     offset,
-			"updated_at": time.Now(),
-		}),
-	}).Create(&cursor).Error
-}
-
-func (i *CaddyIngestor) startDir(dirPath string, scanIntervalSec int) {
-	if scanIntervalSec <= 0 {
-		scanIntervalSec = defaultScanIntervalSec
-	}
-	interval := time.Duration(scanIntervalSec) * time.Second
-
-	var oldStopCh chan struct{}
-	i.mu.Lock()
-	if watcher, exists := i.dirWatchers[dirPath]; exists {
-		if watcher.interval == interval {
-			i.mu.Unlock()
-			return
-		}
-		oldStopCh = watcher.stopCh
-	}
-	stopCh := make(chan struct{})
-	i.dirWatchers[dirPath] = dirWatcher{stopCh: stopCh, interval: interval}
-	if _, ok := i.dirFiles[dirPath]; !ok {
-		i.dirFiles[dirPath] = make(map[string]struct{})
-	}
-	i.mu.Unlock()
-
-	if oldStopCh != nil {
-		close(oldStopCh)
-	}
-
-	i.scanDir(dirPath)
-
-	safego.New(context.Background(), "Caddy 日志目录扫描").Go(func() {
-		ticker := time.NewTicker(interval)
-		defer ticker.Stop()
-		for {
-			select {
-			case <-ticker.C:
-				i.scanDir(dirPath)
-			case <-stopCh:
-				return
-			}
-		}
-	})
-}
-
-func (i *CaddyIngestor) scanDir(dirPath string) {
-	entries, err := os.ReadDir(dirPath)
-	if err != nil {
-		logx.Errorf("读取目录失败: %v", err)
-		return
-	}
-
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-		name := entry.Name()
-		if !isLogFileName(name) {
-			continue
-		}
-		filePath := filepath.Join(dirPath, name)
-
-		i.mu.Lock()
-		dirFiles, ok := i.dirFiles[dirPath]
-		if !ok {
-			i.mu.Unlock()
-			return
-		}
-		_, tracked := dirFiles[filePath]
-		i.mu.Unlock()
-		if tracked {
-			continue
-		}
-
-		if i.startFile(filePath) {
-			i.mu.Lock()
-			if dirFiles, ok := i.dirFiles[dirPath]; ok {
-				dirFiles[filePath] = struct{}{}
-			}
-			i.mu.Unlock()
-		}
-	}
-}
-
-func (i *CaddyIngestor) Stop(filePath string) {
-	filePath = strings.TrimSpace(filePath)
-	if filePath == "" {
-		return
-	}
-	filePath = filepath.Clean(filePath)
-
-	i.mu.Lock()
-	watcher, isDir := i.dirWatchers[filePath]
-	files := i.dirFiles[filePath]
-	if isDir {
-		delete(i.dirWatchers, filePath)
-		delete(i.dirFiles, filePath)
-	}
-	i.mu.Unlock()
-
-	if isDir {
-		close(watcher.stopCh)
-		for file := range files {
-			i.stopFile(file)
-		}
-		return
-	}
-
-	i.stopFile(filePath)
-}
-
-func (i *CaddyIngestor) stopFile(filePath string) {
-	i.mu.Lock()
-	t, exists := i.tails[filePath]
-	if exists {
-		delete(i.tails, filePath)
-	}
-	i.mu.Unlock()
-
-	if exists {
-		t.Stop()
-		t.Cleanup()
-		logx.Infof("停止监听文件: %s", filePath)
-	}
-}
-
-func isLogFileName(name string) bool {
-	return strings.EqualFold(filepath.Ext(name), ".log")
-}
-
-func DefaultScanIntervalSec() int {
-	return defaultScanIntervalSec
-}
-
 func (i *CaddyIngestor) parseJSONLine(line string) (*model.CaddyLog, error) {
 	decoder := json.NewDecoder(strings.NewReader(line))
 	decoder.UseNumber()
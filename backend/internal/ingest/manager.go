@@ -10,14 +10,16 @@ import (
 
 // IngestManager 统一管理不同类型日志入库
 type IngestManager struct {
-	caddy  *CaddyIngestor
-	system *SystemIngestor
+	caddy    *CaddyIngestor
+	system   *SystemIngestor
+	wafAudit *WafAuditIngestor
 }
 
 func NewIngestManager(db *gorm.DB) *IngestManager {
 	return &IngestManager{
-		caddy:  NewCaddyIngestor(db),
-		system: NewSystemIngestor(db),
+		caddy:    NewCaddyIngestor(db),
+		system:   NewSystemIngestor(db),
+		wafAudit: NewWafAuditIngestor(db),
 	}
 }
 
@@ -25,6 +27,7 @@ func (m *IngestManager) StartSource(source model.LogSource) {
 	if !source.Enabled || strings.TrimSpace(source.Path) == "" {
 		return
 	}
+	m.caddy.SetServer(source.Path, source.ServerID)
 	m.StartWithInterval(source.Path, source.ScanInterval, source.Type)
 }
 
@@ -41,6 +44,8 @@ func (m *IngestManager) StartWithInterval(path string, scanIntervalSec int, sour
 		m.caddy.StartWithInterval(path, scanIntervalSec)
 	case "caddy_runtime":
 		m.system.StartWithInterval(path, scanIntervalSec, normalizeSourceType(sourceType))
+	case "waf_audit":
+		m.wafAudit.StartWithInterval(path, scanIntervalSec)
 	case "backend":
 		// backend 日志直接写入数据库，不再从文件读取
 		return
@@ -56,6 +61,8 @@ func (m *IngestManager) Stop(path string, sourceType string) {
 		m.caddy.Stop(path)
 	case "caddy_runtime":
 		m.system.Stop(path)
+	case "waf_audit":
+		m.wafAudit.Stop(path)
 	case "backend":
 		return
 	default:
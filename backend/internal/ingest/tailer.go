@@ -0,0 +1,291 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"logflux/internal/utils/safego"
+	"logflux/model"
+
+	"github.com/nxadm/tail"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const defaultScanIntervalSec = 60
+
+type dirWatcher struct {
+	stopCh   chan struct{}
+	interval time.Duration
+}
+
+// logTailer 监听日志文件或目录，按 log_ingest_cursors 游标续读，每行交给 handle 处理；
+// handle 返回 true 时推进游标，onStop 在停止监听某个文件时清理调用方的按文件状态
+type logTailer struct {
+	db          *gorm.DB
+	label       string
+	handle      func(filePath, line string) bool
+	onStop      func(filePath string)
+	tails       map[string]*tail.Tail
+	dirWatchers map[string]dirWatcher
+	dirFiles    map[string]map[string]struct{}
+	mu          sync.Mutex
+}
+
+func newLogTailer(db *gorm.DB, label string, handle func(filePath, line string) bool) *logTailer {
+	return &logTailer{
+		db:          db,
+		label:       label,
+		handle:      handle,
+		tails:       make(map[string]*tail.Tail),
+		dirWatchers: make(map[string]dirWatcher),
+		dirFiles:    make(map[string]map[string]struct{}),
+	}
+}
+
+// start 监听文件，路径为目录时定期扫描目录下的 .log 文件
+func (t *logTailer) start(filePath string, scanIntervalSec int) {
+	if info, err := os.Stat(filePath); err == nil && info.IsDir() {
+		t.startDir(filePath, scanIntervalSec)
+		return
+	}
+
+	t.startFile(filePath)
+}
+
+func (t *logTailer) startFile(filePath string) bool {
+	t.mu.Lock()
+	if _, exists := t.tails[filePath]; exists {
+		t.mu.Unlock()
+		return false
+	}
+	t.mu.Unlock()
+
+	startOffset := t.resolveStartOffset(filePath)
+
+	tf, err := tail.TailFile(filePath, tail.Config{
+		Follow:   true,
+		ReOpen:   true,
+		Poll:     true,
+		Location: &tail.SeekInfo{Offset: startOffset, Whence: io.SeekStart},
+	})
+	if err != nil {
+		logx.Errorf("监听文件失败: %v", err)
+		return false
+	}
+
+	t.mu.Lock()
+	if _, exists := t.tails[filePath]; exists {
+		t.mu.Unlock()
+		tf.Stop()
+		tf.Cleanup()
+		return false
+	}
+	t.tails[filePath] = tf
+	t.mu.Unlock()
+
+	logx.Infof("开始监听%s: %s", t.label, filePath)
+
+	watchPath := filePath
+	safego.New(context.Background(), t.label+"文件监听").Go(func() {
+		path := watchPath
+		for line := range tf.Lines {
+			if line == nil {
+				continue
+			}
+			if line.Err != nil {
+				logx.Errorf("读取监听内容失败: %v", line.Err)
+				continue
+			}
+			if !t.handle(path, line.Text) {
+				continue
+			}
+			if err := t.saveOffset(path, line.SeekInfo.Offset); err != nil {
+				logx.Errorf("保存日志采集游标失败: %v", err)
+			}
+		}
+	})
+
+	return true
+}
+
+func (t *logTailer) resolveStartOffset(filePath string) int64 {
+	var cursor model.LogIngestCursor
+	if err := t.db.Where("file_path = ?", filePath).Take(&cursor).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logx.Errorf("加载日志采集游标失败: %v", err)
+		}
+		return 0
+	}
+
+	offset := cursor.Offset
+	if offset < 0 {
+		return 0
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return offset
+	}
+	if offset > info.Size() {
+		return 0
+	}
+
+	return offset
+}
+
+func (t *logTailer) saveOffset(filePath string, offset int64) error {
+	if offset < 0 {
+		offset = 0
+	}
+
+	cursor := model.LogIngestCursor{
+		FilePath: filePath,
+		Offset:   offset,
+	}
+
+	return t.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "file_path"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"offset":     offset,
+			"updated_at": time.Now(),
+		}),
+	}).Create(&cursor).Error
+}
+
+func (t *logTailer) startDir(dirPath string, scanIntervalSec int) {
+	if scanIntervalSec <= 0 {
+		scanIntervalSec = defaultScanIntervalSec
+	}
+	interval := time.Duration(scanIntervalSec) * time.Second
+
+	var oldStopCh chan struct{}
+	t.mu.Lock()
+	if watcher, exists := t.dirWatchers[dirPath]; exists {
+		if watcher.interval == interval {
+			t.mu.Unlock()
+			return
+		}
+		oldStopCh = watcher.stopCh
+	}
+	stopCh := make(chan struct{})
+	t.dirWatchers[dirPath] = dirWatcher{stopCh: stopCh, interval: interval}
+	if _, ok := t.dirFiles[dirPath]; !ok {
+		t.dirFiles[dirPath] = make(map[string]struct{})
+	}
+	t.mu.Unlock()
+
+	if oldStopCh != nil {
+		close(oldStopCh)
+	}
+
+	t.scanDir(dirPath)
+
+	safego.New(context.Background(), t.label+"目录扫描").Go(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				t.scanDir(dirPath)
+			case <-stopCh:
+				return
+			}
+		}
+	})
+}
+
+func (t *logTailer) scanDir(dirPath string) {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		logx.Errorf("读取目录失败: %v", err)
+		return
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !isLogFileName(entry.Name()) {
+			continue
+		}
+		filePath := filepath.Join(dirPath, entry.Name())
+
+		t.mu.Lock()
+		dirFiles, ok := t.dirFiles[dirPath]
+		if !ok {
+			t.mu.Unlock()
+			return
+		}
+		_, tracked := dirFiles[filePath]
+		t.mu.Unlock()
+		if tracked {
+			continue
+		}
+
+		if t.startFile(filePath) {
+			t.mu.Lock()
+			if dirFiles, ok := t.dirFiles[dirPath]; ok {
+				dirFiles[filePath] = struct{}{}
+			}
+			t.mu.Unlock()
+		}
+	}
+}
+
+func (t *logTailer) Stop(filePath string) {
+	filePath = strings.TrimSpace(filePath)
+	if filePath == "" {
+		return
+	}
+	filePath = filepath.Clean(filePath)
+
+	t.mu.Lock()
+	watcher, isDir := t.dirWatchers[filePath]
+	files := t.dirFiles[filePath]
+	if isDir {
+		delete(t.dirWatchers, filePath)
+		delete(t.dirFiles, filePath)
+	}
+	t.mu.Unlock()
+
+	if isDir {
+		close(watcher.stopCh)
+		for file := range files {
+			t.stopFile(file)
+		}
+		return
+	}
+
+	t.stopFile(filePath)
+}
+
+func (t *logTailer) stopFile(filePath string) {
+	t.mu.Lock()
+	tf, exists := t.tails[filePath]
+	if exists {
+		delete(t.tails, filePath)
+	}
+	t.mu.Unlock()
+
+	if t.onStop != nil {
+		t.onStop(filePath)
+	}
+	if exists {
+		tf.Stop()
+		tf.Cleanup()
+		logx.Infof("停止监听文件: %s", filePath)
+	}
+}
+
+func isLogFileName(name string) bool {
+	return strings.EqualFold(filepath.Ext(name), ".log")
+}
+
+func DefaultScanIntervalSec() int {
+	return defaultScanIntervalSec
+}
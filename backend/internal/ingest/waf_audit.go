@@ -0,0 +1,342 @@
+package ingest
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"logflux/internal/utils/safego"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// 审计事件与访问日志的时间对齐窗口
+	wafAuditLinkWindow = 3 * time.Second
+	// 后台补链：访问日志可能晚于审计日志入库，定期回补最近未关联的事件
+	wafAuditRelinkInterval = 30 * time.Second
+	wafAuditRelinkMaxAge   = 10 * time.Minute
+	wafAuditRelinkBatch    = 500
+	// 策略绑定缓存时间
+	wafAuditPolicyCacheTTL = time.Minute
+)
+
+// WafAuditIngestor 采集 Coraza 审计日志（JSON / Native），并关联到 caddy_logs 访问日志
+type WafAuditIngestor struct {
+	*logTailer
+	db         *gorm.DB
+	native     map[string][]string
+	mu         sync.Mutex
+	relinkOnce sync.Once
+
+	policyMu       sync.Mutex
+	policyLoadedAt time.Time
+	policyResolver *wafAuditPolicyResolver
+}
+
+func NewWafAuditIngestor(db *gorm.DB) *WafAuditIngestor {
+	i := &WafAuditIngestor{
+		db:     db,
+		native: make(map[string][]string),
+	}
+	i.logTailer = newLogTailer(db, "WAF 审计日志", i.tailLine)
+	i.logTailer.onStop = i.dropNative
+	return i
+}
+
+func (i *WafAuditIngestor) StartWithInterval(filePath string, scanIntervalSec int) {
+	filePath = strings.TrimSpace(filePath)
+	if filePath == "" {
+		return
+	}
+	filePath = filepath.Clean(filePath)
+
+	i.relinkOnce.Do(i.startRelink)
+	i.start(filePath, scanIntervalSec)
+}
+
+// tailLine 处理监听到的一行审计日志，Native 事务未结束时不推进游标
+func (i *WafAuditIngestor) tailLine(filePath, line string) bool {
+	complete, err := i.IngestLine(filePath, line)
+	if err != nil {
+		logx.Errorf("WAF 审计日志入库失败: %v", err)
+	}
+	return complete
+}
+
+// dropNative 停止监听文件时丢弃未完成的 Native 事务缓冲
+func (i *WafAuditIngestor) dropNative(filePath string) {
+	i.mu.Lock()
+	delete(i.native, filePath)
+	i.mu.Unlock()
+}
+
+// IngestLine 处理单行审计日志；Native 格式按段缓冲，遇到 Z 段时整体入库。
+// 返回 true 表示当前行之前的内容已完整落库，可以推进采集游标。
+func (i *WafAuditIngestor) IngestLine(filePath, line string) (bool, error) {
+	trimmed := strings.TrimSpace(line)
+
+	i.mu.Lock()
+	buffered, inNative := i.native[filePath]
+	i.mu.Unlock()
+
+	if m := wafAuditNativeBoundary.FindStringSubmatch(trimmed); m != nil {
+		switch m[2] {
+		case "A":
+			i.mu.Lock()
+			i.native[filePath] = []string{line}
+			i.mu.Unlock()
+			return false, nil
+		case "Z":
+			i.mu.Lock()
+			delete(i.native, filePath)
+			i.mu.Unlock()
+			if !inNative {
+				return true, nil
+			}
+			entry, err := ParseWafAuditNative(append(buffered, line))
+			if err != nil {
+				return true, err
+			}
+			return true, i.save(entry)
+		}
+	}
+
+	if inNative {
+		i.mu.Lock()
+		i.native[filePath] = append(buffered, line)
+		i.mu.Unlock()
+		return false, nil
+	}
+
+	// 不完整的 Native 事务片段（如从游标中途恢复）直接跳过
+	if !strings.HasPrefix(trimmed, "{") {
+		return true, nil
+	}
+	entry, err := ParseWafAuditJSON(trimmed)
+	if err != nil {
+		return true, err
+	}
+	return true, i.save(entry)
+}
+
+func (i *WafAuditIngestor) save(entry *model.WafAuditLog) error {
+	if resolver := i.policies(); resolver != nil {
+		entry.PolicyID, entry.PolicyName = resolver.resolve(entry.Host, entry.Uri, entry.Method)
+	}
+	// 灰度实例与生效实例记录的是同一请求，访问日志只关联到生效实例的事件
+	if entry.RolloutID == 0 {
+		if caddyLogID, ok := i.findAccessLog(entry); ok {
+			entry.CaddyLogID = &caddyLogID
+		}
+	}
+	if err := i.db.Create(entry).Error; err != nil {
+		logx.Errorf("写入 WAF 审计日志失败: %v", err)
+		return err
+	}
+	return nil
+}
+
+// findAccessLog 按事务 ID、时间与客户端 IP 匹配对应的访问日志
+func (i *WafAuditIngestor) findAccessLog(entry *model.WafAuditLog) (uint, bool) {
+	start := entry.LogTime.Add(-wafAuditLinkWindow)
+	end := entry.LogTime.Add(wafAuditLinkWindow)
+	unlinked := "NOT EXISTS (SELECT 1 FROM waf_audit_logs w WHERE w.caddy_log_id = caddy_logs.id)"
+
+	var ids []uint
+	if entry.TransactionID != "" {
+		if err := i.db.Model(&model.CaddyLog{}).
+			Where("log_time BETWEEN ? AND ?", start, end).
+			Where("(raw_log->>'uuid' = ? OR raw_log->'request'->'headers'->'X-Request-Id'->>0 = ?)", entry.TransactionID, entry.TransactionID).
+			Limit(1).
+			Pluck("id", &ids).Error; err != nil {
+			logx.Errorf("按事务 ID 关联访问日志失败: %v", err)
+		}
+		if len(ids) > 0 {
+			return ids[0], true
+		}
+	}
+
+	if entry.ClientIP == "" {
+		return 0, false
+	}
+	query := i.db.Model(&model.CaddyLog{}).
+		Where("log_time BETWEEN ? AND ?", start, end).
+		Where("(remote_ip = ? OR client_ip = ?)", entry.ClientIP, entry.ClientIP).
+		Where(unlinked)
+	if entry.Uri != "" {
+		query = query.Where("uri = ?", entry.Uri)
+	}
+	if entry.Status > 0 {
+		query = query.Where("status = ?", entry.Status)
+	}
+	if err := query.
+		Order(clause.Expr{SQL: "ABS(EXTRACT(EPOCH FROM (log_time - ?)))", Vars: []interface{}{entry.LogTime}}).
+		Limit(1).
+		Pluck("id", &ids).Error; err != nil {
+		logx.Errorf("按时间与 IP 关联访问日志失败: %v", err)
+		return 0, false
+	}
+	if len(ids) == 0 {
+		return 0, false
+	}
+	return ids[0], true
+}
+
+func (i *WafAuditIngestor) startRelink() {
+	safego.New(context.Background(), "WAF 审计日志关联").Go(func() {
+		ticker := time.NewTicker(wafAuditRelinkInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			i.relinkPending(time.Now())
+		}
+	})
+}
+
+// relinkPending 为最近尚未关联访问日志的审计事件补充关联
+func (i *WafAuditIngestor) relinkPending(now time.Time) {
+	var pending []model.WafAuditLog
+	if err := i.db.Where("caddy_log_id IS NULL AND rollout_id = 0 AND log_time >= ?", now.Add(-wafAuditRelinkMaxAge)).
+		Order("id asc").
+		Limit(wafAuditRelinkBatch).
+		Find(&pending).Error; err != nil {
+		logx.Errorf("加载待关联 WAF 审计日志失败: %v", err)
+		return
+	}
+
+	for idx := range pending {
+		caddyLogID, ok := i.findAccessLog(&pending[idx])
+		if !ok {
+			continue
+		}
+		if err := i.db.Model(&model.WafAuditLog{}).
+			Where("id = ? AND caddy_log_id IS NULL", pending[idx].ID).
+			Update("caddy_log_id", caddyLogID).Error; err != nil {
+			logx.Errorf("更新 WAF 审计日志关联失败: id=%d err=%v", pending[idx].ID, err)
+		}
+	}
+}
+
+func (i *WafAuditIngestor) policies() *wafAuditPolicyResolver {
+	i.policyMu.Lock()
+	defer i.policyMu.Unlock()
+
+	if i.policyResolver != nil && time.Since(i.policyLoadedAt) < wafAuditPolicyCacheTTL {
+		return i.policyResolver
+	}
+
+	var policies []model.WafPolicy
+	if err := i.db.Where("enabled = ?", true).Find(&policies).Error; err != nil {
+		logx.Errorf("加载 WAF 策略失败: %v", err)
+		return i.policyResolver
+	}
+	var bindings []model.WafPolicyBinding
+	if err := i.db.Where("enabled = ?", true).Find(&bindings).Error; err != nil {
+		logx.Errorf("加载 WAF 策略绑定失败: %v", err)
+		return i.policyResolver
+	}
+
+	i.policyResolver = newWafAuditPolicyResolver(policies, bindings)
+	i.policyLoadedAt = time.Now()
+	return i.policyResolver
+}
+
+// wafAuditPolicyResolver 按作用域绑定确定审计事件所属策略：route > site > global，同级按优先级升序
+type wafAuditPolicyResolver struct {
+	names         map[uint]string
+	bindings      []model.WafPolicyBinding
+	defaultPolicy uint
+}
+
+func newWafAuditPolicyResolver(policies []model.WafPolicy, bindings []model.WafPolicyBinding) *wafAuditPolicyResolver {
+	resolver := &wafAuditPolicyResolver{names: make(map[uint]string, len(policies))}
+	for _, policy := range policies {
+		resolver.names[policy.ID] = policy.Name
+		if policy.IsDefault && resolver.defaultPolicy == 0 {
+			resolver.defaultPolicy = policy.ID
+		}
+	}
+	for _, binding := range bindings {
+		if _, ok := resolver.names[binding.PolicyID]; ok {
+			resolver.bindings = append(resolver.bindings, binding)
+		}
+	}
+	sort.SliceStable(resolver.bindings, func(a, b int) bool {
+		ra, rb := wafScopeRank(resolver.bindings[a].ScopeType), wafScopeRank(resolver.bindings[b].ScopeType)
+		if ra != rb {
+			return ra < rb
+		}
+		return resolver.bindings[a].Priority < resolver.bindings[b].Priority
+	})
+	return resolver
+}
+
+func (r *wafAuditPolicyResolver) resolve(host, uri, method string) (uint, string) {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if h, _, ok := strings.Cut(host, ":"); ok {
+		host = h
+	}
+	path := uri
+	if p, _, ok := strings.Cut(uri, "?"); ok {
+		path = p
+	}
+	method = strings.ToUpper(strings.TrimSpace(method))
+
+	for _, binding := range r.bindings {
+		bindingHost := strings.ToLower(strings.TrimSpace(binding.Host))
+		switch wafScopeRank(binding.ScopeType) {
+		case 0:
+			if bindingHost != "" && bindingHost != host {
+				continue
+			}
+			if !wafScopePathMatch(binding.Path, path) {
+				continue
+			}
+			if m := strings.ToUpper(strings.TrimSpace(binding.Method)); m != "" && m != method {
+				continue
+			}
+		case 1:
+			if bindingHost == "" || bindingHost != host {
+				continue
+			}
+		}
+		return binding.PolicyID, r.names[binding.PolicyID]
+	}
+
+	if r.defaultPolicy != 0 {
+		return r.defaultPolicy, r.names[r.defaultPolicy]
+	}
+	return 0, ""
+}
+
+func wafScopeRank(scopeType string) int {
+	switch strings.ToLower(strings.TrimSpace(scopeType)) {
+	case "route":
+		return 0
+	case "site":
+		return 1
+	default:
+		return 2
+	}
+}
+
+// wafScopePathMatch 与策略统计的路径匹配保持一致：完全相等或以 path/ 为前缀
+func wafScopePathMatch(scopePath, path string) bool {
+	scopePath = strings.TrimSpace(scopePath)
+	if scopePath == "" {
+		return true
+	}
+	if !strings.HasPrefix(scopePath, "/") {
+		scopePath = "/" + scopePath
+	}
+	if scopePath == "/" {
+		return strings.HasPrefix(path, "/")
+	}
+	return path == scopePath || strings.HasPrefix(path, scopePath+"/")
+}
@@ -0,0 +1,393 @@
+package ingest
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"logflux/model"
+)
+
+// Coraza 审计日志解析，支持 SecAuditLogFormat JSON（单行）与 Native（分段多行）两种格式。
+
+var (
+	wafAuditNativeBoundary = regexp.MustCompile(`^--([0-9A-Za-z]+)-([A-Z])--$`)
+	wafAuditNativeField    = regexp.MustCompile(`\[(\w+) "((?:[^"\\]|\\.)*)"\]`)
+	wafAuditMatchedVar     = regexp.MustCompile(`found within ([A-Z_]+(?::[^:\s]+)?)`)
+	wafAuditTotalScore     = regexp.MustCompile(`Total Score: (\d+)`)
+	wafAuditInboundScore   = regexp.MustCompile(`Inbound Scores?: blocking=(\d+)`)
+	wafAuditOutboundScore  = regexp.MustCompile(`Outbound Scores?: blocking=(\d+)`)
+)
+
+// CRS 评分汇总规则：949110 入站阈值、959100 出站阈值、980130/980170 汇总报告
+const (
+	crsInboundBlockingRuleID  = 949110
+	crsOutboundBlockingRuleID = 959100
+)
+
+var wafAuditSeverityNames = []string{"emergency", "alert", "critical", "error", "warning", "notice", "info", "debug"}
+
+// ParseWafAuditJSON 解析 Coraza JSON 审计日志行
+func ParseWafAuditJSON(line string) (*model.WafAuditLog, error) {
+	decoder := json.NewDecoder(strings.NewReader(line))
+	decoder.UseNumber()
+
+	var data map[string]any
+	if err := decoder.Decode(&data); err != nil {
+		return nil, fmt.Errorf("解析 WAF 审计日志失败: %w", err)
+	}
+	tx, ok := data["transaction"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("WAF 审计日志缺少 transaction 字段")
+	}
+
+	entry := &model.WafAuditLog{
+		TransactionID: asString(tx["id"]),
+		ClientIP:      asString(tx["client_ip"]),
+		RawLog:        line,
+	}
+	entry.LogTime = parseWafAuditJSONTime(tx)
+
+	if req, ok := tx["request"].(map[string]any); ok {
+		entry.Method = asString(req["method"])
+		entry.Uri = asString(req["uri"])
+		entry.Host = wafAuditHeaderValue(req["headers"], "Host")
+	}
+	if resp, ok := tx["response"].(map[string]any); ok {
+		entry.Status = int(asFloat(resp["status"]))
+	}
+	interrupted, _ := tx["is_interrupted"].(bool)
+
+	// 灰度实例通过 SecComponentSignature 标识，出现在 producer.rulesets 与消息的 actionset 中
+	signatures := make([]string, 0)
+	if producer, ok := tx["producer"].(map[string]any); ok {
+		signatures = append(signatures, asStringSlice(producer["rulesets"])...)
+	}
+
+	messages := make([]model.WafAuditMessage, 0)
+	if rawMessages, ok := data["messages"].([]any); ok {
+		for _, raw := range rawMessages {
+			msg, ok := raw.(map[string]any)
+			if !ok {
+				continue
+			}
+			signatures = append(signatures, strings.Fields(asString(msg["actionset"]))...)
+			detail, _ := msg["data"].(map[string]any)
+			item := model.WafAuditMessage{
+				RuleID:   int64(asFloat(detail["id"])),
+				Message:  defaultIfEmpty(asString(detail["msg"]), asString(msg["message"])),
+				Data:     asString(detail["data"]),
+				Severity: normalizeWafAuditSeverity(detail["severity"]),
+				Tags:     asStringSlice(detail["tags"]),
+			}
+			item.Variable = matchedVariable(item.Data)
+			messages = append(messages, item)
+		}
+	}
+
+	entry.RolloutID = wafAuditRolloutID(signatures)
+	finalizeWafAuditEntry(entry, messages, interrupted)
+	return entry, nil
+}
+
+// wafAuditRolloutID 从组件签名中解析灰度 ID
+func wafAuditRolloutID(signatures []string) uint {
+	for _, signature := range signatures {
+		raw, ok := strings.CutPrefix(strings.TrimSpace(signature), model.WafRolloutSignaturePrefix)
+		if !ok {
+			continue
+		}
+		if id, err := strconv.ParseUint(raw, 10, 64); err == nil && id > 0 {
+			return uint(id)
+		}
+	}
+	return 0
+}
+
+// ParseWafAuditNative 解析 Coraza Native 格式的单个审计事务（A..Z 各段）
+func ParseWafAuditNative(lines []string) (*model.WafAuditLog, error) {
+	sections := make(map[string][]string)
+	current := ""
+	for _, line := range lines {
+		trimmed := strings.TrimRight(line, "\r")
+		if m := wafAuditNativeBoundary.FindStringSubmatch(strings.TrimSpace(trimmed)); m != nil {
+			current = m[2]
+			continue
+		}
+		if current == "" || strings.TrimSpace(trimmed) == "" {
+			continue
+		}
+		sections[current] = append(sections[current], trimmed)
+	}
+	if len(sections["A"]) == 0 {
+		return nil, fmt.Errorf("WAF 审计日志缺少 A 段")
+	}
+
+	entry := &model.WafAuditLog{
+		RawLog: strings.Join(lines, "\n"),
+	}
+
+	// A: [timestamp] transaction_id client_ip client_port server_ip server_port
+	header := sections["A"][0]
+	if end := strings.Index(header, "]"); strings.HasPrefix(header, "[") && end > 0 {
+		entry.LogTime = parseWafAuditNativeTime(header[1:end])
+		header = header[end+1:]
+	}
+	if entry.LogTime.IsZero() {
+		entry.LogTime = time.Now()
+	}
+	fields := strings.Fields(header)
+	if len(fields) > 0 {
+		entry.TransactionID = fields[0]
+	}
+	if len(fields) > 1 {
+		entry.ClientIP = fields[1]
+	}
+
+	// B: 请求行与请求头
+	if request := sections["B"]; len(request) > 0 {
+		parts := strings.Fields(request[0])
+		if len(parts) >= 2 {
+			entry.Method = parts[0]
+			entry.Uri = parts[1]
+		}
+		for _, headerLine := range request[1:] {
+			name, value, ok := strings.Cut(headerLine, ":")
+			if ok && strings.EqualFold(strings.TrimSpace(name), "Host") {
+				entry.Host = strings.TrimSpace(value)
+				break
+			}
+		}
+	}
+
+	// F: 响应状态行
+	if response := sections["F"]; len(response) > 0 {
+		parts := strings.Fields(response[0])
+		if len(parts) >= 2 {
+			entry.Status, _ = strconv.Atoi(parts[1])
+		}
+	}
+
+	// H: 规则命中消息
+	interrupted := false
+	messages := make([]model.WafAuditMessage, 0)
+	for _, line := range sections["H"] {
+		values := make(map[string][]string)
+		for _, m := range wafAuditNativeField.FindAllStringSubmatch(line, -1) {
+			values[m[1]] = append(values[m[1]], strings.ReplaceAll(m[2], `\"`, `"`))
+		}
+		if len(values["id"]) == 0 {
+			if strings.Contains(line, "Access denied") || strings.Contains(strings.ToLower(line), "interrupt") {
+				interrupted = true
+			}
+			continue
+		}
+		ruleID, _ := strconv.ParseInt(values["id"][0], 10, 64)
+		item := model.WafAuditMessage{
+			RuleID:   ruleID,
+			Message:  firstString(values["msg"]),
+			Data:     firstString(values["data"]),
+			Severity: normalizeWafAuditSeverity(firstString(values["severity"])),
+			Tags:     values["tag"],
+		}
+		item.Variable = matchedVariable(item.Data)
+		messages = append(messages, item)
+	}
+
+	finalizeWafAuditEntry(entry, messages, interrupted)
+	return entry, nil
+}
+
+// finalizeWafAuditEntry 汇总规则、标签、变量、评分与处置动作
+func finalizeWafAuditEntry(entry *model.WafAuditLog, messages []model.WafAuditMessage, interrupted bool) {
+	if entry.LogTime.IsZero() {
+		entry.LogTime = time.Now()
+	}
+
+	ruleIDs := make([]int64, 0, len(messages))
+	tags := make([]string, 0)
+	variables := make([]string, 0)
+	seenRule := make(map[int64]struct{})
+	seenTag := make(map[string]struct{})
+	seenVar := make(map[string]struct{})
+	highest := len(wafAuditSeverityNames)
+
+	for _, msg := range messages {
+		if msg.RuleID > 0 {
+			if _, ok := seenRule[msg.RuleID]; !ok {
+				seenRule[msg.RuleID] = struct{}{}
+				ruleIDs = append(ruleIDs, msg.RuleID)
+			}
+		}
+		for _, tag := range msg.Tags {
+			if _, ok := seenTag[tag]; !ok && tag != "" {
+				seenTag[tag] = struct{}{}
+				tags = append(tags, tag)
+			}
+		}
+		if msg.Variable != "" {
+			if _, ok := seenVar[msg.Variable]; !ok {
+				seenVar[msg.Variable] = struct{}{}
+				variables = append(variables, msg.Variable)
+			}
+		}
+		for rank, name := range wafAuditSeverityNames {
+			if msg.Severity == name && rank < highest {
+				highest = rank
+			}
+		}
+
+		text := msg.Message + " " + msg.Data
+		if m := wafAuditInboundScore.FindStringSubmatch(text); m != nil {
+			entry.InboundAnomalyScore = max(entry.InboundAnomalyScore, atoi(m[1]))
+		}
+		if m := wafAuditOutboundScore.FindStringSubmatch(text); m != nil {
+			entry.OutboundAnomalyScore = max(entry.OutboundAnomalyScore, atoi(m[1]))
+		}
+		if m := wafAuditTotalScore.FindStringSubmatch(text); m != nil {
+			switch msg.RuleID {
+			case crsInboundBlockingRuleID:
+				entry.InboundAnomalyScore = max(entry.InboundAnomalyScore, atoi(m[1]))
+			case crsOutboundBlockingRuleID:
+				entry.OutboundAnomalyScore = max(entry.OutboundAnomalyScore, atoi(m[1]))
+			}
+		}
+	}
+	sort.Slice(ruleIDs, func(a, b int) bool { return ruleIDs[a] < ruleIDs[b] })
+
+	entry.RuleIDs = model.Int64Array(ruleIDs)
+	entry.Tags = model.StringArray(tags)
+	entry.MatchedVariables = model.StringArray(variables)
+	if highest < len(wafAuditSeverityNames) {
+		entry.Severity = wafAuditSeverityNames[highest]
+	}
+
+	raw, err := json.Marshal(messages)
+	if err != nil {
+		raw = []byte("[]")
+	}
+	entry.Messages = string(raw)
+
+	if interrupted || isWafBlockedStatus(entry.Status) {
+		entry.Action = model.WafAuditActionBlocked
+	} else {
+		entry.Action = model.WafAuditActionDetected
+	}
+}
+
+func parseWafAuditJSONTime(tx map[string]any) time.Time {
+	if v, ok := tx["unix_timestamp"].(json.Number); ok {
+		if n, err := v.Int64(); err == nil && n > 0 {
+			// Coraza 输出纳秒时间戳
+			if n > 1e15 {
+				return time.Unix(0, n)
+			}
+			return time.Unix(n, 0)
+		}
+	}
+	for _, key := range []string{"timestamp", "time_stamp"} {
+		if ts := asString(tx[key]); ts != "" {
+			if t := parseWafAuditNativeTime(ts); !t.IsZero() {
+				return t
+			}
+		}
+	}
+	return time.Now()
+}
+
+func parseWafAuditNativeTime(ts string) time.Time {
+	layouts := []string{
+		"02/Jan/2006:15:04:05 -0700",
+		"2006/01/02 15:04:05",
+		"2006/01/02 15:04:05.000000",
+		time.RFC3339Nano,
+	}
+	ts = strings.TrimSpace(ts)
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, ts, time.Local); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func normalizeWafAuditSeverity(value any) string {
+	switch v := value.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil && n >= 0 && int(n) < len(wafAuditSeverityNames) {
+			return wafAuditSeverityNames[n]
+		}
+	case string:
+		s := strings.ToLower(strings.TrimSpace(v))
+		if n, err := strconv.Atoi(s); err == nil && n >= 0 && n < len(wafAuditSeverityNames) {
+			return wafAuditSeverityNames[n]
+		}
+		return s
+	}
+	return ""
+}
+
+func wafAuditHeaderValue(headers any, key string) string {
+	m, ok := headers.(map[string]any)
+	if !ok {
+		return ""
+	}
+	for name, val := range m {
+		if !strings.EqualFold(name, key) {
+			continue
+		}
+		if list := asStringSlice(val); len(list) > 0 {
+			return list[0]
+		}
+		return asString(val)
+	}
+	return ""
+}
+
+func matchedVariable(data string) string {
+	if m := wafAuditMatchedVar.FindStringSubmatch(data); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+func isWafBlockedStatus(status int) bool {
+	return status == 403 || status == 406 || status == 429
+}
+
+func asStringSlice(value any) []string {
+	list, ok := value.([]any)
+	if !ok {
+		return nil
+	}
+	result := make([]string, 0, len(list))
+	for _, item := range list {
+		if s := asString(item); s != "" {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func firstString(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func defaultIfEmpty(value, fallback string) string {
+	if strings.TrimSpace(value) == "" {
+		return fallback
+	}
+	return value
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
@@ -0,0 +1,131 @@
+package ingest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"logflux/model"
+)
+
+func TestParseWafAuditJSON(t *testing.T) {
+	line := `{"transaction":{"timestamp":"2026/03/11 08:00:00","unix_timestamp":1773216000000000000,"id":"tx-1","client_ip":"203.0.113.9","client_port":51000,` +
+		`"request":{"method":"GET","uri":"/search?q=<script>","headers":{"Host":["example.com"]}},"response":{"status":403},"is_interrupted":true},` +
+		`"messages":[` +
+		`{"message":"XSS Attack Detected via libinjection","data":{"id":941100,"msg":"XSS Attack Detected via libinjection","data":"Matched Data: XSS data found within ARGS:q: <script>","severity":2,"tags":["application-multi","attack-xss"]}},` +
+		`{"message":"Inbound Anomaly Score Exceeded","data":{"id":949110,"msg":"Inbound Anomaly Score Exceeded (Total Score: 5)","severity":0,"tags":["anomaly-evaluation"]}}` +
+		`]}`
+
+	entry, err := ParseWafAuditJSON(line)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	if entry.TransactionID != "tx-1" || entry.ClientIP != "203.0.113.9" || entry.Host != "example.com" || entry.Uri != "/search?q=<script>" || entry.Status != 403 {
+		t.Fatalf("unexpected transaction fields: %+v", entry)
+	}
+	if entry.LogTime.Unix() != 1773216000 {
+		t.Fatalf("unexpected log time: %v", entry.LogTime)
+	}
+	if !reflect.DeepEqual([]int64(entry.RuleIDs), []int64{941100, 949110}) {
+		t.Fatalf("unexpected rule ids: %v", entry.RuleIDs)
+	}
+	if !reflect.DeepEqual([]string(entry.MatchedVariables), []string{"ARGS:q"}) {
+		t.Fatalf("unexpected matched variables: %v", entry.MatchedVariables)
+	}
+	if len(entry.Tags) != 3 || entry.Severity != "emergency" {
+		t.Fatalf("unexpected tags/severity: %v %s", entry.Tags, entry.Severity)
+	}
+	if entry.InboundAnomalyScore != 5 || entry.Action != model.WafAuditActionBlocked {
+		t.Fatalf("unexpected score/action: %d %s", entry.InboundAnomalyScore, entry.Action)
+	}
+	if !strings.Contains(entry.Messages, `"ruleId":941100`) {
+		t.Fatalf("messages not serialized: %s", entry.Messages)
+	}
+}
+
+func TestParseWafAuditJSONCanaryRollout(t *testing.T) {
+	line := `{"transaction":{"id":"tx-2","client_ip":"203.0.113.9","request":{"method":"GET","uri":"/","headers":{"Host":["example.com"]}},` +
+		`"response":{"status":200},"is_interrupted":false,"producer":{"connector":"coraza-caddy","rulesets":["OWASP_CRS/4.0.0","logflux-canary-12"]}},` +
+		`"messages":[{"message":"Inbound Anomaly Score Exceeded","actionset":"OWASP_CRS/4.0.0 logflux-canary-12","data":{"id":949110,"msg":"Inbound Anomaly Score Exceeded (Total Score: 5)"}}]}`
+
+	entry, err := ParseWafAuditJSON(line)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if entry.RolloutID != 12 || entry.Action != model.WafAuditActionDetected {
+		t.Fatalf("unexpected canary fields: rollout=%d action=%s", entry.RolloutID, entry.Action)
+	}
+
+	if got := wafAuditRolloutID([]string{"OWASP_CRS/4.0.0", "logflux-canary-x"}); got != 0 {
+		t.Fatalf("expected no rollout id, got %d", got)
+	}
+}
+
+func TestParseWafAuditNative(t *testing.T) {
+	lines := []string{
+		"--abc123-A--",
+		"[11/Mar/2026:08:00:00 +0000] tx-2 198.51.100.4 40000 10.0.0.1 80",
+		"--abc123-B--",
+		"POST /login HTTP/1.1",
+		"Host: shop.example.com",
+		"--abc123-F--",
+		"HTTP/1.1 200",
+		"--abc123-H--",
+		`Coraza: Warning. SQL Injection Attack [file "REQUEST-942-APPLICATION-ATTACK-SQLI.conf"] [id "942100"] [msg "SQL Injection Attack Detected via libinjection"] [data "Matched Data: s&sos found within ARGS:user: admin' or 1=1"] [severity "critical"] [tag "attack-sqli"] [tag "paranoia-level/1"]`,
+		`Coraza: Warning. [id "980170"] [msg "Anomaly Scores: (Inbound Scores: blocking=5, detection=5, per_pl=5-0-0-0, threshold=10) - (Outbound Scores: blocking=0, detection=0, per_pl=0-0-0-0, threshold=8)"]`,
+		"--abc123-Z--",
+	}
+
+	entry, err := ParseWafAuditNative(lines)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if entry.TransactionID != "tx-2" || entry.ClientIP != "198.51.100.4" || entry.Method != "POST" || entry.Uri != "/login" || entry.Host != "shop.example.com" || entry.Status != 200 {
+		t.Fatalf("unexpected transaction fields: %+v", entry)
+	}
+	if !reflect.DeepEqual([]int64(entry.RuleIDs), []int64{942100, 980170}) {
+		t.Fatalf("unexpected rule ids: %v", entry.RuleIDs)
+	}
+	if !reflect.DeepEqual([]string(entry.MatchedVariables), []string{"ARGS:user"}) {
+		t.Fatalf("unexpected matched variables: %v", entry.MatchedVariables)
+	}
+	if entry.InboundAnomalyScore != 5 || entry.Severity != "critical" || entry.Action != model.WafAuditActionDetected {
+		t.Fatalf("unexpected score/severity/action: %d %s %s", entry.InboundAnomalyScore, entry.Severity, entry.Action)
+	}
+
+	if _, err := ParseWafAuditNative([]string{"--abc-B--", "GET / HTTP/1.1"}); err == nil {
+		t.Fatalf("expected error when section A is missing")
+	}
+}
+
+func TestWafAuditPolicyResolver(t *testing.T) {
+	policies := []model.WafPolicy{
+		{ID: 1, Name: "default", IsDefault: true},
+		{ID: 2, Name: "shop"},
+		{ID: 3, Name: "shop-admin"},
+	}
+	bindings := []model.WafPolicyBinding{
+		{PolicyID: 1, ScopeType: "global", Priority: 100},
+		{PolicyID: 2, ScopeType: "site", Host: "shop.example.com", Priority: 100},
+		{PolicyID: 3, ScopeType: "route", Host: "shop.example.com", Path: "/admin", Priority: 50},
+		{PolicyID: 99, ScopeType: "site", Host: "orphan.example.com", Priority: 1},
+	}
+	resolver := newWafAuditPolicyResolver(policies, bindings)
+
+	cases := []struct {
+		host, uri string
+		want      string
+	}{
+		{"shop.example.com", "/admin/users?id=1", "shop-admin"},
+		{"SHOP.example.com:443", "/cart", "shop"},
+		{"shop.example.com", "/administrator", "shop"},
+		{"other.example.com", "/admin", "default"},
+		{"orphan.example.com", "/", "default"},
+	}
+	for _, tc := range cases {
+		if _, name := resolver.resolve(tc.host, tc.uri, "GET"); name != tc.want {
+			t.Fatalf("resolve(%s, %s) = %s, want %s", tc.host, tc.uri, name, tc.want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package caddy
+
+import (
+	"context"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type AbortWafPolicyRolloutLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewAbortWafPolicyRolloutLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AbortWafPolicyRolloutLogic {
+	return &AbortWafPolicyRolloutLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// AbortWafPolicyRollout 终止灰度，仅保留已发布版本
+func (l *AbortWafPolicyRolloutLogic) AbortWafPolicyRollout(req *types.WafPolicyRolloutActionReq) (resp *types.WafPolicyRolloutItem, err error) {
+	defer func() {
+		err = localizeWafPolicyError(err)
+	}()
+	if req == nil {
+		req = &types.WafPolicyRolloutActionReq{}
+	}
+
+	rollout, err := NewWafRolloutService(l.ctx, l.svcCtx).Abort(req.ID, currentOperatorFromContext(l.ctx), req.Message)
+	if err != nil {
+		return nil, err
+	}
+
+	l.Infof("WAF 策略灰度已终止: rollout=%d policy=%d", rollout.ID, rollout.PolicyID)
+	item := toWafPolicyRolloutItem(rollout, "")
+	return &item, nil
+}
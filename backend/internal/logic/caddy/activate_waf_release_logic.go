@@ -37,6 +37,7 @@ func (l *ActivateWafReleaseLogic) ActivateWafRelease(req *types.WafReleaseActiva
 	}
 
 	job := helper.startJob(release.SourceID, release.ID, "activate", "manual")
+	helper.attachJobRuleDiff(job, &release)
 
 	if err := helper.activateRelease(&release); err != nil {
 		helper.markReleaseFailed(&release, err.Error())
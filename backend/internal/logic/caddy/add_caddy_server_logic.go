@@ -3,6 +3,7 @@ package caddy
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"logflux/internal/notification"
@@ -30,15 +31,16 @@ func NewAddCaddyServerLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Ad
 
 func (l *AddCaddyServerLogic) AddCaddyServer(req *types.CaddyServerReq) (resp *types.BaseResp, err error) {
 	server := &model.CaddyServer{
-		Name:      req.Name,
-		Url:       req.Url,
-		Token:     req.Token,
-		Type:      req.Type,
-		Username:  req.Username,
-		Password:  req.Password,
-		Modules:   "{}",
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		Name:          req.Name,
+		Url:           req.Url,
+		Token:         req.Token,
+		Type:          req.Type,
+		Username:      req.Username,
+		Password:      req.Password,
+		CaddyfilePath: strings.TrimSpace(req.CaddyfilePath),
+		Modules:       "{}",
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
 	}
 
 	if err := l.svcCtx.DB.WithContext(l.ctx).Create(server).Error; err != nil {
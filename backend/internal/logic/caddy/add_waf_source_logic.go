@@ -55,7 +55,7 @@ func (l *AddWafSourceLogic) AddWafSource(req *types.WafSourceReq) (resp *types.B
 	}
 
 	sourceURL := strings.TrimSpace(req.Url)
-	if mode == wafModeRemote && sourceURL == "" {
+	if isWafRemoteMode(mode) && sourceURL == "" {
 		return nil, fmt.Errorf("远程源 URL 不能为空")
 	}
 
@@ -72,20 +72,23 @@ func (l *AddWafSourceLogic) AddWafSource(req *types.WafSourceReq) (resp *types.B
 	}
 
 	source := &model.WafSource{
-		Name:         name,
-		Kind:         kind,
-		Mode:         mode,
-		URL:          sourceURL,
-		ChecksumURL:  strings.TrimSpace(req.ChecksumUrl),
-		ProxyURL:     strings.TrimSpace(req.ProxyUrl),
-		AuthType:     authType,
-		AuthSecret:   strings.TrimSpace(req.AuthSecret),
-		Schedule:     strings.TrimSpace(req.Schedule),
-		Enabled:      true,
-		AutoCheck:    true,
-		AutoDownload: true,
-		AutoActivate: false,
-		Meta:         meta,
+		Name:          name,
+		Kind:          kind,
+		Mode:          mode,
+		URL:           sourceURL,
+		ChecksumURL:   strings.TrimSpace(req.ChecksumUrl),
+		ProxyURL:      strings.TrimSpace(req.ProxyUrl),
+		AuthType:      authType,
+		AuthSecret:    strings.TrimSpace(req.AuthSecret),
+		SignatureType: req.SignatureType,
+		SignatureURL:  req.SignatureUrl,
+		PublicKeys:    req.PublicKeys,
+		Schedule:      strings.TrimSpace(req.Schedule),
+		Enabled:       true,
+		AutoCheck:     true,
+		AutoDownload:  true,
+		AutoActivate:  false,
+		Meta:          meta,
 	}
 	if kind == wafKindCorazaEngine {
 		source.AutoActivate = false
@@ -106,6 +109,9 @@ func (l *AddWafSourceLogic) AddWafSource(req *types.WafSourceReq) (resp *types.B
 	if kind == wafKindCorazaEngine {
 		source.AutoActivate = false
 	}
+	if err := normalizeWafSourceTrust(source); err != nil {
+		return nil, err
+	}
 
 	if err := helper.svcCtx.DB.WithContext(helper.ctx).Create(source).Error; err != nil {
 		if strings.Contains(strings.ToLower(err.Error()), "duplicate key") {
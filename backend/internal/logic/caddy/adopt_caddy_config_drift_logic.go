@@ -0,0 +1,32 @@
+package caddy
+
+import (
+	"context"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type AdoptCaddyConfigDriftLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewAdoptCaddyConfigDriftLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AdoptCaddyConfigDriftLogic {
+	return &AdoptCaddyConfigDriftLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *AdoptCaddyConfigDriftLogic) AdoptCaddyConfigDrift(req *types.IDReq) (resp *types.BaseResp, err error) {
+	drift, err := NewCaddyDriftService(l.ctx, l.svcCtx).Adopt(req.ID, currentOperatorFromContext(l.ctx))
+	if err != nil {
+		return nil, err
+	}
+	return &types.BaseResp{Code: 200, Msg: drift.Message}, nil
+}
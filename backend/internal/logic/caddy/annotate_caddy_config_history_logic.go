@@ -0,0 +1,54 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type AnnotateCaddyConfigHistoryLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewAnnotateCaddyConfigHistoryLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AnnotateCaddyConfigHistoryLogic {
+	return &AnnotateCaddyConfigHistoryLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *AnnotateCaddyConfigHistoryLogic) AnnotateCaddyConfigHistory(req *types.CaddyConfigHistoryAnnotateReq) (resp *types.BaseResp, err error) {
+	db := l.svcCtx.DB.WithContext(l.ctx)
+	var history model.CaddyConfigHistory
+	if err := db.Where("id = ? AND server_id = ?", req.HistoryId, req.ServerId).First(&history).Error; err != nil {
+		return nil, fmt.Errorf("历史记录不存在")
+	}
+
+	updates := map[string]interface{}{
+		"message":    strings.TrimSpace(req.Message),
+		"known_good": req.KnownGood,
+	}
+	if req.KnownGood != history.KnownGood {
+		if req.KnownGood {
+			updates["tagged_by"] = currentOperatorFromContext(l.ctx)
+			updates["tagged_at"] = time.Now()
+		} else {
+			updates["tagged_by"] = ""
+			updates["tagged_at"] = nil
+		}
+	}
+	if err := db.Model(&history).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("更新历史记录失败: %w", err)
+	}
+	return &types.BaseResp{Code: 200, Msg: "成功"}, nil
+}
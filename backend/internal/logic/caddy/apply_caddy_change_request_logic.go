@@ -0,0 +1,37 @@
+package caddy
+
+import (
+	"context"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ApplyCaddyChangeRequestLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewApplyCaddyChangeRequestLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ApplyCaddyChangeRequestLogic {
+	return &ApplyCaddyChangeRequestLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ApplyCaddyChangeRequestLogic) ApplyCaddyChangeRequest(req *types.CaddyChangeRequestActionReq) (resp *types.CaddyChangeRequestItem, err error) {
+	actor, err := loadCaddyChangeActor(l.ctx, l.svcCtx.DB)
+	if err != nil {
+		return nil, err
+	}
+	request, err := NewCaddyChangeRequestService(l.ctx, l.svcCtx).Apply(actor, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	item := toCaddyChangeRequestItem(*request)
+	return &item, nil
+}
@@ -26,6 +26,9 @@ func NewApplyWafIntegrationLogic(ctx context.Context, svcCtx *svc.ServiceContext
 }
 
 func (l *ApplyWafIntegrationLogic) ApplyWafIntegration(req *types.WafIntegrationApplyReq) (resp *types.WafIntegrationApplyResp, err error) {
+	if err := ensureDirectCaddyChangeAllowed(l.svcCtx); err != nil {
+		return nil, err
+	}
 	if req == nil {
 		return nil, fmt.Errorf("WAF 集成参数不合法")
 	}
@@ -35,7 +38,7 @@ func (l *ApplyWafIntegrationLogic) ApplyWafIntegration(req *types.WafIntegration
 		return nil, err
 	}
 
-	applyService := newCaddyConfigApplyService(l.svcCtx, l.Logger)
+	applyService := newCaddyConfigApplyService(l.svcCtx, l.Logger).withNote(currentOperatorFromContext(l.ctx), "")
 	config, modules, err := applyService.loadCurrent(server)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,132 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type ApplyWafPolicyFalsePositiveFeedbackSuggestionLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewApplyWafPolicyFalsePositiveFeedbackSuggestionLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ApplyWafPolicyFalsePositiveFeedbackSuggestionLogic {
+	return &ApplyWafPolicyFalsePositiveFeedbackSuggestionLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ApplyWafPolicyFalsePositiveFeedbackSuggestion 审批通过后创建排除规则，并与误报反馈双向关联
+func (l *ApplyWafPolicyFalsePositiveFeedbackSuggestionLogic) ApplyWafPolicyFalsePositiveFeedbackSuggestion(req *types.WafPolicyFalsePositiveFeedbackApplyReq) (resp *types.WafPolicyFalsePositiveFeedbackApplyResp, err error) {
+	defer func() {
+		err = localizeWafPolicyError(err)
+	}()
+
+	if req == nil || req.ID == 0 {
+		return nil, fmt.Errorf("误报反馈 ID 不能为空")
+	}
+
+	db := l.svcCtx.DB.WithContext(l.ctx)
+	var feedback model.WafPolicyFalsePositiveFeedback
+	if err := db.First(&feedback, req.ID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("未找到误报反馈记录")
+		}
+		return nil, fmt.Errorf("查询误报反馈失败: %w", err)
+	}
+	if feedback.ExclusionID != nil {
+		return nil, fmt.Errorf("该误报反馈已关联排除规则 #%d", *feedback.ExclusionID)
+	}
+
+	var exclusion model.WafRuleExclusion
+	if strings.TrimSpace(req.RemoveValue) == "" {
+		suggestion, err := suggestWafFeedbackExclusion(db, &feedback)
+		if err != nil {
+			return nil, err
+		}
+		exclusion = suggestion.Exclusion
+	} else {
+		policyID := req.PolicyId
+		if policyID == 0 {
+			policyID = feedback.PolicyID
+		}
+		exclusion = model.WafRuleExclusion{
+			PolicyID:     policyID,
+			Name:         strings.TrimSpace(req.Name),
+			Description:  strings.TrimSpace(req.Description),
+			Enabled:      true,
+			ScopeType:    req.ScopeType,
+			Host:         req.Host,
+			Path:         req.Path,
+			Method:       req.Method,
+			RemoveType:   req.RemoveType,
+			RemoveValue:  req.RemoveValue,
+			RemoveTarget: req.RemoveTarget,
+		}
+		if exclusion.Name == "" {
+			exclusion.Name = fmt.Sprintf("误报反馈 #%d", feedback.ID)
+		}
+	}
+
+	if err := validatePolicyIDExists(db, exclusion.PolicyID); err != nil {
+		return nil, err
+	}
+	exclusion.ScopeType, exclusion.Host, exclusion.Path, exclusion.Method, err = normalizeAndValidateExclusionScopeFields(
+		exclusion.ScopeType, exclusion.Host, exclusion.Path, exclusion.Method,
+	)
+	if err != nil {
+		return nil, err
+	}
+	exclusion.RemoveType, exclusion.RemoveValue, exclusion.RemoveTarget, err = normalizeAndValidateExclusionRemoveFields(
+		exclusion.RemoveType, exclusion.RemoveValue, exclusion.RemoveTarget,
+	)
+	if err != nil {
+		return nil, err
+	}
+	feedbackID := feedback.ID
+	exclusion.FeedbackID = &feedbackID
+
+	processNote := strings.TrimSpace(req.ProcessNote)
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&exclusion).Error; err != nil {
+			return fmt.Errorf("创建策略排除规则失败: %w", err)
+		}
+		if processNote == "" {
+			processNote = fmt.Sprintf("已生成排除规则 #%d", exclusion.ID)
+		}
+		now := time.Now()
+		result := tx.Model(&model.WafPolicyFalsePositiveFeedback{}).
+			Where("id = ? AND exclusion_id IS NULL", feedback.ID).
+			Updates(map[string]interface{}{
+				"exclusion_id":    exclusion.ID,
+				"feedback_status": wafFeedbackStatusResolved,
+				"process_note":    processNote,
+				"processed_by":    currentOperatorFromContext(l.ctx),
+				"processed_at":    &now,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("更新误报反馈状态失败: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("该误报反馈已被其他操作关联排除规则")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.WafPolicyFalsePositiveFeedbackApplyResp{ExclusionId: exclusion.ID}, nil
+}
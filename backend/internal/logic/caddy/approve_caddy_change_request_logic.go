@@ -0,0 +1,37 @@
+package caddy
+
+import (
+	"context"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ApproveCaddyChangeRequestLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewApproveCaddyChangeRequestLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ApproveCaddyChangeRequestLogic {
+	return &ApproveCaddyChangeRequestLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ApproveCaddyChangeRequestLogic) ApproveCaddyChangeRequest(req *types.CaddyChangeRequestActionReq) (resp *types.CaddyChangeRequestItem, err error) {
+	actor, err := loadCaddyChangeActor(l.ctx, l.svcCtx.DB)
+	if err != nil {
+		return nil, err
+	}
+	request, err := NewCaddyChangeRequestService(l.ctx, l.svcCtx).Approve(actor, req.ID, req.Comment, req.ScheduledAt)
+	if err != nil {
+		return nil, err
+	}
+	item := toCaddyChangeRequestItem(*request)
+	return &item, nil
+}
@@ -0,0 +1,316 @@
+package caddy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"logflux/internal/caddyfile"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"gorm.io/gorm"
+)
+
+const (
+	caddyVerifyMaxWindowSeconds    = 300
+	caddyVerifyMaxProbeTimeout     = 60
+	caddyVerifyDefaultProbeTimeout = 5
+	caddyVerifyMaxBodyBytes        = 1 << 20
+
+	caddyVerifyStatusPending = "pending"
+	caddyVerifyStatusPassed  = "passed"
+	caddyVerifyStatusFailed  = "failed"
+)
+
+// caddyApplyVerifyPlan 节点启用的校验设置与探测项
+type caddyApplyVerifyPlan struct {
+	Setting model.CaddyApplyVerifySetting
+	Probes  []model.CaddyHealthProbe
+}
+
+// loadCaddyApplyVerifyPlan 未配置或未启用校验时返回 nil
+func loadCaddyApplyVerifyPlan(db *gorm.DB, serverID uint) (*caddyApplyVerifyPlan, error) {
+	var setting model.CaddyApplyVerifySetting
+	err := db.Where("server_id = ?", serverID).First(&setting).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询配置校验设置失败: %w", err)
+	}
+	if !setting.Enabled {
+		return nil, nil
+	}
+
+	plan := &caddyApplyVerifyPlan{Setting: setting}
+	if err := db.Where("server_id = ? AND enabled = ?", serverID, true).Order("id asc").Find(&plan.Probes).Error; err != nil {
+		return nil, fmt.Errorf("查询健康探测失败: %w", err)
+	}
+	if len(plan.Probes) == 0 && setting.ErrorWindowSeconds <= 0 {
+		return nil, nil
+	}
+	return plan, nil
+}
+
+// caddyApplyVerifyReport 一次校验的结果
+type caddyApplyVerifyReport struct {
+	Probes       []types.CaddyHealthProbeResult
+	ErrorChecked bool
+	Requests     int64
+	Errors       int64
+	ErrorRate    float64
+	Passed       bool
+	Message      string
+}
+
+func (r *caddyApplyVerifyReport) toResp() *types.CaddyApplyVerifyResultResp {
+	return &types.CaddyApplyVerifyResultResp{
+		Passed:       r.Passed,
+		Message:      r.Message,
+		Probes:       r.Probes,
+		ErrorChecked: r.ErrorChecked,
+		Requests:     r.Requests,
+		Errors:       r.Errors,
+		ErrorRate:    r.ErrorRate,
+	}
+}
+
+// caddyApplyVerifier 配置加载后先执行 HTTP 探测，再观察窗口期内新写入 caddy_logs 的 5xx 占比
+type caddyApplyVerifier struct {
+	db     *gorm.DB
+	client *http.Client
+	now    func() time.Time
+	sleep  func(time.Duration)
+}
+
+func newCaddyApplyVerifier(db *gorm.DB) *caddyApplyVerifier {
+	return &caddyApplyVerifier{
+		db: db,
+		client: &http.Client{
+			// 探测结果以首个响应为准，不跟随跳转
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		now:   time.Now,
+		sleep: time.Sleep,
+	}
+}
+
+// verify 校验 config 加载后的状态；since 为加载时间，wait 为 true 时等待观察窗口结束再统计错误率
+func (v *caddyApplyVerifier) verify(plan *caddyApplyVerifyPlan, config string, since time.Time, wait bool) *caddyApplyVerifyReport {
+	report := &caddyApplyVerifyReport{Passed: true, Probes: make([]types.CaddyHealthProbeResult, 0, len(plan.Probes))}
+	var failures []string
+	for _, probe := range plan.Probes {
+		result := v.runProbe(probe)
+		report.Probes = append(report.Probes, result)
+		if !result.Passed {
+			failures = append(failures, fmt.Sprintf("%s: %s", result.Name, result.Message))
+		}
+	}
+	if len(failures) > 0 {
+		report.Passed = false
+		report.Message = "健康探测失败 " + strings.Join(failures, "；")
+		return report
+	}
+
+	setting := plan.Setting
+	if setting.ErrorWindowSeconds > 0 {
+		window := time.Duration(setting.ErrorWindowSeconds) * time.Second
+		if !wait {
+			since = v.now().Add(-window)
+		} else if remaining := since.Add(window).Sub(v.now()); remaining > 0 {
+			v.sleep(remaining)
+		}
+		if err := v.checkErrorRate(report, setting, caddyVerifyHostsFromConfig(config), since); err != nil {
+			report.Passed = false
+			report.Message = err.Error()
+			return report
+		}
+	}
+
+	if report.Passed {
+		report.Message = "校验通过"
+		if report.ErrorChecked {
+			report.Message += fmt.Sprintf("（%d 个请求，5xx 占比 %.1f%%）", report.Requests, report.ErrorRate*100)
+		}
+	}
+	return report
+}
+
+func (v *caddyApplyVerifier) runProbe(probe model.CaddyHealthProbe) types.CaddyHealthProbeResult {
+	result := types.CaddyHealthProbeResult{Name: probe.Name, Url: probe.URL}
+	timeout := probe.TimeoutSeconds
+	if timeout <= 0 {
+		timeout = caddyVerifyDefaultProbeTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	method := strings.ToUpper(strings.TrimSpace(probe.Method))
+	if method == "" {
+		method = http.MethodGet
+	}
+	req, err := http.NewRequestWithContext(ctx, method, probe.URL, nil)
+	if err != nil {
+		result.Message = fmt.Sprintf("请求无效: %v", err)
+		return result
+	}
+	if host := strings.TrimSpace(probe.Host); host != "" {
+		req.Host = host
+	}
+
+	start := v.now()
+	resp, err := v.client.Do(req)
+	result.DurationMs = v.now().Sub(start).Milliseconds()
+	if err != nil {
+		result.Message = fmt.Sprintf("请求失败: %v", err)
+		return result
+	}
+	defer resp.Body.Close()
+	result.Status = resp.StatusCode
+
+	if probe.ExpectStatus > 0 && resp.StatusCode != probe.ExpectStatus {
+		result.Message = fmt.Sprintf("状态码 %d，期望 %d", resp.StatusCode, probe.ExpectStatus)
+		return result
+	}
+	if probe.ExpectStatus <= 0 && (resp.StatusCode < 200 || resp.StatusCode >= 400) {
+		result.Message = fmt.Sprintf("状态码 %d，期望 2xx/3xx", resp.StatusCode)
+		return result
+	}
+	if pattern := strings.TrimSpace(probe.BodyPattern); pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			result.Message = fmt.Sprintf("响应体正则无效: %v", err)
+			return result
+		}
+		body, err := io.ReadAll(io.LimitReader(resp.Body, caddyVerifyMaxBodyBytes))
+		if err != nil {
+			result.Message = fmt.Sprintf("读取响应体失败: %v", err)
+			return result
+		}
+		if !re.Match(body) {
+			result.Message = "响应体不匹配 " + pattern
+			return result
+		}
+	}
+
+	result.Passed = true
+	result.Message = "正常"
+	return result
+}
+
+// checkErrorRate 统计该节点 since 之后写入的日志，样本数不足 MinRequests 时不判定
+func (v *caddyApplyVerifier) checkErrorRate(report *caddyApplyVerifyReport, setting model.CaddyApplyVerifySetting, hosts []string, since time.Time) error {
+	query := func() *gorm.DB {
+		db := v.db.Model(&model.CaddyLog{}).Where("server_id = ? AND log_time >= ?", setting.ServerID, since)
+		if len(hosts) > 0 {
+			db = db.Where("host IN ?", hosts)
+		}
+		return db
+	}
+	if err := query().Count(&report.Requests).Error; err != nil {
+		return fmt.Errorf("统计请求日志失败: %w", err)
+	}
+	if err := query().Where("status >= ?", 500).Count(&report.Errors).Error; err != nil {
+		return fmt.Errorf("统计错误日志失败: %w", err)
+	}
+	if report.Requests > 0 {
+		report.ErrorRate = float64(report.Errors) / float64(report.Requests)
+	}
+	if report.Requests < int64(setting.MinRequests) || report.Requests == 0 {
+		return nil
+	}
+	report.ErrorChecked = true
+	if report.ErrorRate > setting.MaxErrorRate {
+		return fmt.Errorf("加载后 %d 秒内 5xx 占比 %.1f%%（%d/%d），超过阈值 %.1f%%",
+			setting.ErrorWindowSeconds, report.ErrorRate*100, report.Errors, report.Requests, setting.MaxErrorRate*100)
+	}
+	return nil
+}
+
+// caddyVerifyHostsFromConfig 提取站点域名用于过滤日志；存在通配或仅端口的站点时返回 nil，统计全部日志
+func caddyVerifyHostsFromConfig(config string) []string {
+	doc, err := caddyfile.Parse(config)
+	if err != nil {
+		return nil
+	}
+	seen := map[string]bool{}
+	hosts := make([]string, 0)
+	for _, site := range doc.Sites() {
+		for _, address := range caddyfile.SiteAddresses(site) {
+			host := strings.ToLower(strings.TrimSpace(address))
+			host = strings.TrimPrefix(strings.TrimPrefix(host, "https://"), "http://")
+			if i := strings.IndexByte(host, '/'); i >= 0 {
+				host = host[:i]
+			}
+			if h, _, err := net.SplitHostPort(host); err == nil {
+				host = h
+			}
+			if host == "" || strings.ContainsAny(host, "*{") {
+				return nil
+			}
+			if !seen[host] {
+				seen[host] = true
+				hosts = append(hosts, host)
+			}
+		}
+	}
+	return hosts
+}
+
+// normalizeCaddyHealthProbes 校验并规范化探测项
+func normalizeCaddyHealthProbes(serverID uint, items []types.CaddyHealthProbeItem) ([]model.CaddyHealthProbe, error) {
+	probes := make([]model.CaddyHealthProbe, 0, len(items))
+	for i, item := range items {
+		name := strings.TrimSpace(item.Name)
+		if name == "" {
+			name = fmt.Sprintf("探测 %d", i+1)
+		}
+		url := strings.TrimSpace(item.Url)
+		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+			return nil, fmt.Errorf("%s 的 URL 需以 http:// 或 https:// 开头", name)
+		}
+		method := strings.ToUpper(strings.TrimSpace(item.Method))
+		switch method {
+		case "":
+			method = http.MethodGet
+		case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodOptions:
+		default:
+			return nil, fmt.Errorf("%s 的请求方法不支持: %s", name, method)
+		}
+		if item.ExpectStatus != 0 && (item.ExpectStatus < 100 || item.ExpectStatus > 599) {
+			return nil, fmt.Errorf("%s 的期望状态码无效: %d", name, item.ExpectStatus)
+		}
+		pattern := strings.TrimSpace(item.BodyPattern)
+		if pattern != "" {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return nil, fmt.Errorf("%s 的响应体正则无效: %v", name, err)
+			}
+		}
+		timeout := item.TimeoutSeconds
+		if timeout <= 0 {
+			timeout = caddyVerifyDefaultProbeTimeout
+		}
+		if timeout > caddyVerifyMaxProbeTimeout {
+			timeout = caddyVerifyMaxProbeTimeout
+		}
+		probes = append(probes, model.CaddyHealthProbe{
+			ServerID:       serverID,
+			Name:           name,
+			URL:            url,
+			Method:         method,
+			Host:           strings.TrimSpace(item.Host),
+			ExpectStatus:   item.ExpectStatus,
+			BodyPattern:    pattern,
+			TimeoutSeconds: timeout,
+			Enabled:        item.Enabled,
+		})
+	}
+	return probes, nil
+}
@@ -0,0 +1,209 @@
+package caddy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestCaddyApplyVerifierRunProbe(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/healthz":
+			if r.Host != "app.example.com" {
+				w.WriteHeader(http.StatusMisdirectedRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"status":"ok"}`))
+		case "/old":
+			http.Redirect(w, r, "/new", http.StatusFound)
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer upstream.Close()
+
+	verifier := newCaddyApplyVerifier(nil)
+	cases := []struct {
+		name   string
+		probe  model.CaddyHealthProbe
+		passed bool
+		status int
+	}{
+		{"host and body", model.CaddyHealthProbe{URL: upstream.URL + "/healthz", Host: "app.example.com", ExpectStatus: 200, BodyPattern: `"status":\s*"ok"`}, true, 200},
+		{"body mismatch", model.CaddyHealthProbe{URL: upstream.URL + "/healthz", Host: "app.example.com", BodyPattern: "degraded"}, false, 200},
+		{"wrong host", model.CaddyHealthProbe{URL: upstream.URL + "/healthz", ExpectStatus: 200}, false, 421},
+		{"redirect not followed", model.CaddyHealthProbe{URL: upstream.URL + "/old"}, true, 302},
+		{"bad gateway", model.CaddyHealthProbe{URL: upstream.URL + "/api"}, false, 502},
+		{"unreachable", model.CaddyHealthProbe{URL: "http://127.0.0.1:1/", TimeoutSeconds: 1}, false, 0},
+	}
+	for _, tc := range cases {
+		result := verifier.runProbe(tc.probe)
+		if result.Passed != tc.passed || result.Status != tc.status {
+			t.Fatalf("%s: unexpected probe result %+v", tc.name, result)
+		}
+	}
+}
+
+func TestCaddyApplyVerifierErrorRate(t *testing.T) {
+	db, mock, cleanup := newWafMirrorMockDB(t)
+	defer cleanup()
+
+	loadedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	var slept time.Duration
+	verifier := newCaddyApplyVerifier(db)
+	verifier.now = func() time.Time { return loadedAt.Add(5 * time.Second) }
+	verifier.sleep = func(d time.Duration) { slept += d }
+
+	plan := &caddyApplyVerifyPlan{Setting: model.CaddyApplyVerifySetting{ServerID: 7, ErrorWindowSeconds: 30, MaxErrorRate: 0.2, MinRequests: 10}}
+	config := "app.example.com, https://www.example.com:8443 {\n\treverse_proxy app:8080\n}\n"
+
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "caddy_logs" WHERE \(server_id = \$1 AND log_time >= \$2\) AND host IN \(\$3,\$4\)`).
+		WithArgs(7, loadedAt, "app.example.com", "www.example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(40))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "caddy_logs" WHERE .*status >= \$5`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(12))
+
+	report := verifier.verify(plan, config, loadedAt, true)
+	if report.Passed || !report.ErrorChecked || report.Requests != 40 || report.Errors != 12 {
+		t.Fatalf("expected error rate failure, got %+v", report)
+	}
+	if !strings.Contains(report.Message, "5xx 占比 30.0%（12/40），超过阈值 20.0%") {
+		t.Fatalf("unexpected message: %s", report.Message)
+	}
+	if slept != 25*time.Second {
+		t.Fatalf("expected to wait for the rest of the window, slept %s", slept)
+	}
+
+	// 样本不足时不判定
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "caddy_logs"`).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "caddy_logs"`).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	report = verifier.verify(plan, config, loadedAt, false)
+	if !report.Passed || report.ErrorChecked {
+		t.Fatalf("expected pass with too few samples, got %+v", report)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet sql expectations: %v", err)
+	}
+}
+
+func TestCaddyVerifyHostsFromConfig(t *testing.T) {
+	hosts := caddyVerifyHostsFromConfig("a.example.com:443, http://B.example.com/path {\n}\n\na.example.com {\n}\n")
+	if !reflect.DeepEqual(hosts, []string{"a.example.com", "b.example.com"}) {
+		t.Fatalf("unexpected hosts: %v", hosts)
+	}
+	if hosts := caddyVerifyHostsFromConfig("*.example.com {\n}\n"); hosts != nil {
+		t.Fatalf("wildcard sites should disable host filter: %v", hosts)
+	}
+	if hosts := caddyVerifyHostsFromConfig(":80 {\n}\n"); hosts != nil {
+		t.Fatalf("port-only sites should disable host filter: %v", hosts)
+	}
+}
+
+func TestNormalizeCaddyHealthProbes(t *testing.T) {
+	probes, err := normalizeCaddyHealthProbes(3, []types.CaddyHealthProbeItem{
+		{Url: " https://app.example.com/healthz ", Method: "head", TimeoutSeconds: 600, Enabled: true},
+	})
+	if err != nil {
+		t.Fatalf("normalize failed: %v", err)
+	}
+	probe := probes[0]
+	if probe.ServerID != 3 || probe.Name != "探测 1" || probe.URL != "https://app.example.com/healthz" ||
+		probe.Method != http.MethodHead || probe.TimeoutSeconds != caddyVerifyMaxProbeTimeout || !probe.Enabled {
+		t.Fatalf("unexpected probe: %+v", probe)
+	}
+
+	invalid := []types.CaddyHealthProbeItem{
+		{Name: "relative", Url: "/healthz"},
+		{Name: "method", Url: "http://a", Method: "DELETE"},
+		{Name: "status", Url: "http://a", ExpectStatus: 700},
+		{Name: "pattern", Url: "http://a", BodyPattern: "("},
+	}
+	for _, item := range invalid {
+		if _, err := normalizeCaddyHealthProbes(3, []types.CaddyHealthProbeItem{item}); err == nil || !strings.Contains(err.Error(), item.Name) {
+			t.Fatalf("expected validation error for %s, got %v", item.Name, err)
+		}
+	}
+}
+
+func TestCaddyConfigApplyRevertsWhenVerifyFails(t *testing.T) {
+	const previousConfig = "app.example.com {\n\treverse_proxy app:8080\n}\n"
+	const brokenConfig = "app.example.com {\n\treverse_proxy app:9999\n}\n"
+
+	var loads []string
+	admin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.URL.Path == "/load" {
+			loads = append(loads, string(body))
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer admin.Close()
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer upstream.Close()
+
+	db, mock, cleanup := newWafMirrorMockDB(t)
+	defer cleanup()
+	mock.ExpectQuery(`SELECT \* FROM "caddy_apply_verify_settings"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "server_id", "enabled", "error_window_seconds", "max_error_rate", "min_requests"}).AddRow(1, 1, true, 0, 0.5, 20))
+	mock.ExpectQuery(`SELECT \* FROM "caddy_health_probes"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "server_id", "name", "url", "method", "expect_status", "timeout_seconds", "enabled"}).
+			AddRow(1, 1, "首页", upstream.URL, "GET", 200, 2, true))
+	// 加载成功后先落库并记录待校验的历史
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "caddy_servers"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO "caddy_config_history"`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 1, "update", hashConfig(brokenConfig), brokenConfig, sqlmock.AnyArg(), "ui", "system", "", false, "", nil, caddyVerifyStatusPending, sqlmock.AnyArg(), nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
+	mock.ExpectCommit()
+	// 后台校验失败：确认没有更新的加载后回滚到上一个历史版本
+	mock.ExpectQuery(`SELECT \* FROM "caddy_config_history" WHERE server_id = \$1 ORDER BY id desc`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "server_id", "config"}).AddRow(10, 1, brokenConfig))
+	mock.ExpectQuery(`SELECT \* FROM "caddy_config_history" WHERE server_id = \$1 AND id < \$2 AND COALESCE\(verify_status, ''\) <> \$3 ORDER BY id desc`).
+		WithArgs(1, 10, caddyVerifyStatusFailed, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "server_id", "config", "modules"}).AddRow(9, 1, previousConfig, "{}"))
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "caddy_servers" SET "config"=\$1,"modules"=\$2`).
+		WithArgs(previousConfig, "{}", sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// 回滚写入 auto_revert 历史，最新历史与运行中的配置一致
+	mock.ExpectQuery(`INSERT INTO "caddy_config_history"`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 1, caddyAutoRevertHistoryAction, hashConfig(previousConfig), previousConfig, "{}", caddyHistorySourceRollback, "system", sqlmock.AnyArg(), false, "", nil, "", "", nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11))
+	mock.ExpectCommit()
+	mock.ExpectExec(`UPDATE "caddy_config_history" SET "verified_at"=\$1,"verify_message"=\$2,"verify_status"=\$3,"updated_at"=\$4 WHERE id = \$5`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), caddyVerifyStatusFailed, sqlmock.AnyArg(), 10).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	var background []string
+	service := newCaddyConfigApplyService(&svc.ServiceContext{DB: db}, nil)
+	service.background = func(name string, job func()) {
+		background = append(background, name)
+		job()
+	}
+	server := &model.CaddyServer{ID: 1, Name: "local-default", Url: admin.URL, Config: previousConfig}
+	if err := service.apply(server, brokenConfig, "", "update"); err != nil {
+		t.Fatalf("apply should return once loaded, got %v", err)
+	}
+	if len(background) != 1 {
+		t.Fatalf("expected verify to run in background, got %v", background)
+	}
+	if len(loads) != 2 || loads[0] != brokenConfig || loads[1] != previousConfig {
+		t.Fatalf("expected load of new config then previous history, got %q", loads)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet sql expectations: %v", err)
+	}
+}
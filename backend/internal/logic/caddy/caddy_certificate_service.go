@@ -0,0 +1,609 @@
+package caddy
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"logflux/internal/caddyfile"
+	"logflux/internal/notification"
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	caddyCertSourceSite = "site"
+	caddyCertSourcePKI  = "pki"
+
+	caddyCertStatusUnknown  = "unknown"
+	caddyCertStatusValid    = "valid"
+	caddyCertStatusExpiring = "expiring"
+	caddyCertStatusExpired  = "expired"
+	caddyCertStatusError    = "error"
+
+	caddyCertProbeTimeout     = 5 * time.Second
+	caddyCertRenewalLookback  = 7 * 24 * time.Hour
+	caddyCertRenewalLogLimit  = 5000
+	caddyCertDefaultHTTPSPort = "443"
+)
+
+// caddyCertPKIAuthorities 巡检的 PKI CA，local 为 Caddy 内置 CA（tls internal 使用）
+var caddyCertPKIAuthorities = []string{"local"}
+
+var defaultCaddyCertWarnDays = []int{30, 7, 1}
+
+// caddyCertTarget 配置中启用 HTTPS 的站点地址
+type caddyCertTarget struct {
+	Host string
+	Port string
+}
+
+// caddyRenewalState 从运行日志解析出的某个域名最近一次签发/续期结果，Error 为空表示已成功
+type caddyRenewalState struct {
+	Error string
+	At    time.Time
+}
+
+// caddyPKICA /pki/ca/<id> 的响应
+type caddyPKICA struct {
+	ID                      string `json:"id"`
+	Name                    string `json:"name"`
+	RootCertificate         string `json:"root_certificate"`
+	IntermediateCertificate string `json:"intermediate_certificate"`
+}
+
+// CaddyCertificateService 汇总 Caddy 节点的证书清单：配置中的站点地址、PKI 根/中间证书、
+// 可选的 TLS 握手探测结果与 caddy_runtime 日志中的续期失败，并在到期前按阈值发送通知
+type CaddyCertificateService struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	logger logx.Logger
+
+	now   func() time.Time
+	dial  func(ctx context.Context, network, addr string) (net.Conn, error)
+	roots *x509.CertPool // 为空时使用系统根证书
+}
+
+func NewCaddyCertificateService(ctx context.Context, svcCtx *svc.ServiceContext) *CaddyCertificateService {
+	dialer := &net.Dialer{Timeout: caddyCertProbeTimeout}
+	return &CaddyCertificateService{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		logger: logx.WithContext(ctx),
+		now:    time.Now,
+		dial:   dialer.DialContext,
+	}
+}
+
+// CheckAll 巡检全部节点，是否做握手探测由 Caddy.CertProbe 决定；单个节点失败不影响其他节点
+func (s *CaddyCertificateService) CheckAll() error {
+	var servers []model.CaddyServer
+	if err := s.svcCtx.DB.WithContext(s.ctx).Order("id asc").Find(&servers).Error; err != nil {
+		return fmt.Errorf("查询 Caddy 服务器失败: %w", err)
+	}
+	probe := s.svcCtx.Config.Caddy.CertProbe
+	for i := range servers {
+		if _, err := s.Check(&servers[i], probe); err != nil {
+			s.logger.Errorf("巡检 Caddy 证书失败: server=%s err=%v", servers[i].Name, err)
+		}
+	}
+	return nil
+}
+
+// Check 刷新单个节点的证书清单并返回最新结果
+func (s *CaddyCertificateService) Check(server *model.CaddyServer, probe bool) ([]model.CaddyCertificate, error) {
+	db := s.svcCtx.DB.WithContext(s.ctx)
+	var existing []model.CaddyCertificate
+	if err := db.Where("server_id = ?", server.ID).Find(&existing).Error; err != nil {
+		return nil, fmt.Errorf("查询证书清单失败: %w", err)
+	}
+	byHost := make(map[string]*model.CaddyCertificate, len(existing))
+	for i := range existing {
+		byHost[existing[i].Host] = &existing[i]
+	}
+	lookup := func(host, source string) *model.CaddyCertificate {
+		if cert, ok := byHost[host]; ok {
+			return cert
+		}
+		cert := &model.CaddyCertificate{ServerID: server.ID, Host: host, Source: source, Status: caddyCertStatusUnknown}
+		byHost[host] = cert
+		return cert
+	}
+
+	renewals, err := s.loadRenewalStates(server)
+	if err != nil {
+		s.logger.Errorf("解析证书续期日志失败: %v", err)
+	}
+
+	keep := make([]string, 0)
+	certs := make([]*model.CaddyCertificate, 0)
+	renewalFailed := make([]*model.CaddyCertificate, 0)
+	for _, target := range caddyCertTargetsFromConfig(server.Config) {
+		cert := lookup(target.Host, caddyCertSourceSite)
+		if probe && !strings.Contains(target.Host, "*") {
+			s.probe(server, cert, target)
+		}
+		if state, ok := renewals[target.Host]; ok {
+			if state.Error == "" {
+				cert.RenewalError = ""
+				cert.RenewalErrorAt = nil
+			} else {
+				if cert.RenewalError == "" {
+					renewalFailed = append(renewalFailed, cert)
+				}
+				at := state.At
+				cert.RenewalError = state.Error
+				cert.RenewalErrorAt = &at
+			}
+		}
+		keep = append(keep, target.Host)
+		certs = append(certs, cert)
+	}
+
+	for _, id := range caddyCertPKIAuthorities {
+		pkiCerts, err := s.collectPKI(server, id, lookup)
+		if err != nil {
+			// 节点不可达时保留上次结果，仅记录错误
+			s.logger.Errorf("获取 Caddy PKI 证书失败: server=%s ca=%s err=%v", server.Name, id, err)
+			for host, cert := range byHost {
+				if cert.Source == caddyCertSourcePKI && strings.HasPrefix(host, "pki:"+id+"/") && cert.ID > 0 {
+					cert.ProbeError = err.Error()
+					keep = append(keep, host)
+					certs = append(certs, cert)
+				}
+			}
+			continue
+		}
+		for _, cert := range pkiCerts {
+			keep = append(keep, cert.Host)
+			certs = append(certs, cert)
+		}
+	}
+
+	now := s.now()
+	warnDays := normalizeCaddyCertWarnDays(s.svcCtx.Config.Caddy.CertWarnDays)
+	for _, cert := range certs {
+		cert.Status = caddyCertificateStatus(cert, now, warnDays)
+		notify := false
+		if cert.NotAfter != nil && (caddyCertWatchExpiry(cert) || !now.Before(*cert.NotAfter)) {
+			if days, ok := caddyCertNotifyThreshold(*cert.NotAfter, now, warnDays, cert.NotifiedDays); ok {
+				cert.NotifiedDays = &days
+				notify = true
+			}
+		}
+		if err := db.Save(cert).Error; err != nil {
+			return nil, fmt.Errorf("保存证书记录失败: %w", err)
+		}
+		if notify {
+			s.notifyExpiry(server, cert, *cert.NotifiedDays, now)
+		}
+	}
+	for _, cert := range renewalFailed {
+		s.notifyRenewalFailure(server, cert)
+	}
+
+	stale := db.Where("server_id = ?", server.ID)
+	if len(keep) > 0 {
+		stale = stale.Where("host NOT IN ?", keep)
+	}
+	if err := stale.Delete(&model.CaddyCertificate{}).Error; err != nil {
+		return nil, fmt.Errorf("清理证书记录失败: %w", err)
+	}
+
+	result := make([]model.CaddyCertificate, 0, len(certs))
+	for _, cert := range certs {
+		result = append(result, *cert)
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Host < result[j].Host })
+	return result, nil
+}
+
+// probe 对站点做 TLS 握手探测；连接地址取管理接口的主机名，SNI 为站点域名
+func (s *CaddyCertificateService) probe(server *model.CaddyServer, cert *model.CaddyCertificate, target caddyCertTarget) {
+	now := s.now()
+	cert.LastProbedAt = &now
+	addr := net.JoinHostPort(caddyAdminHost(server.Url), target.Port)
+	chain, verifyErr, err := probeCaddyCertificate(s.ctx, s.dial, addr, target.Host, s.roots)
+	if err != nil {
+		cert.ProbeError = err.Error()
+		return
+	}
+	cert.ProbeError = ""
+	fillCaddyCertificate(cert, chain[0])
+	cert.VerifyError = verifyErr
+}
+
+// collectPKI 读取 PKI CA 的根证书与中间证书；CA 不存在时返回空列表
+func (s *CaddyCertificateService) collectPKI(server *model.CaddyServer, id string, lookup func(host, source string) *model.CaddyCertificate) ([]*model.CaddyCertificate, error) {
+	status, body, err := getCaddyAdmin(server, "/pki/ca/"+url.PathEscape(id))
+	if err != nil {
+		if status == http.StatusNotFound || status == http.StatusBadRequest {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var ca caddyPKICA
+	if err := json.Unmarshal(body, &ca); err != nil {
+		return nil, fmt.Errorf("解析 PKI 响应失败: %w", err)
+	}
+
+	certs := make([]*model.CaddyCertificate, 0, 2)
+	for _, item := range []struct{ name, pem string }{
+		{"root", ca.RootCertificate},
+		{"intermediate", ca.IntermediateCertificate},
+	} {
+		leaf, err := parseFirstPEMCertificate(item.pem)
+		if err != nil || leaf == nil {
+			continue
+		}
+		cert := lookup("pki:"+id+"/"+item.name, caddyCertSourcePKI)
+		cert.ProbeError = ""
+		cert.VerifyError = ""
+		fillCaddyCertificate(cert, leaf)
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+// loadRenewalStates 读取该节点日志源中近期的 tls.* 日志，得到每个域名最近一次签发/续期结果
+func (s *CaddyCertificateService) loadRenewalStates(server *model.CaddyServer) (map[string]caddyRenewalState, error) {
+	paths, err := s.runtimeLogPaths(server)
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return map[string]caddyRenewalState{}, nil
+	}
+	var logs []model.SystemLog
+	err = s.svcCtx.DB.WithContext(s.ctx).
+		Select("log_time", "level", "message", "extra_data").
+		Where("source = ? AND file_path IN ? AND log_time >= ? AND extra_data->>'logger' LIKE ?", "caddy_runtime", paths, s.now().Add(-caddyCertRenewalLookback), "tls%").
+		Order("log_time desc").
+		Limit(caddyCertRenewalLogLimit).
+		Find(&logs).Error
+	if err != nil {
+		return nil, err
+	}
+	return parseCaddyRenewalEvents(logs), nil
+}
+
+// runtimeLogPaths 返回归属该节点的日志源路径；只有一个节点时，未关联节点的日志源也视为该节点的
+func (s *CaddyCertificateService) runtimeLogPaths(server *model.CaddyServer) ([]string, error) {
+	db := s.svcCtx.DB.WithContext(s.ctx)
+	var servers int64
+	if err := db.Model(&model.CaddyServer{}).Count(&servers).Error; err != nil {
+		return nil, fmt.Errorf("查询 Caddy 服务器失败: %w", err)
+	}
+	serverIDs := []uint{server.ID}
+	if servers <= 1 {
+		serverIDs = append(serverIDs, 0)
+	}
+	var paths []string
+	if err := db.Model(&model.LogSource{}).Where("server_id IN ?", serverIDs).Pluck("path", &paths).Error; err != nil {
+		return nil, fmt.Errorf("查询日志源失败: %w", err)
+	}
+	return paths, nil
+}
+
+func (s *CaddyCertificateService) notifyExpiry(server *model.CaddyServer, cert *model.CaddyCertificate, days int, now time.Time) {
+	eventType := notification.EventCaddyCertificateExpiring
+	level := notification.LevelWarning
+	title := "Caddy 证书即将到期"
+	message := fmt.Sprintf("节点 %s 的证书 %s 将于 %s 到期（剩余 %d 天）", server.Name, cert.Host, formatNullableTime(cert.NotAfter), caddyCertDaysLeft(*cert.NotAfter, now))
+	if days == 0 {
+		eventType = notification.EventCaddyCertificateExpired
+		level = notification.LevelCritical
+		title = "Caddy 证书已过期"
+		message = fmt.Sprintf("节点 %s 的证书 %s 已于 %s 过期", server.Name, cert.Host, formatNullableTime(cert.NotAfter))
+	}
+	if cert.RenewalError != "" {
+		message += "，最近一次续期失败：" + cert.RenewalError
+	}
+	notifyWafEventAsync(s.svcCtx.NotificationMgr, s.logger, eventType, level, title, message,
+		map[string]interface{}{
+			"serverId":   server.ID,
+			"serverName": server.Name,
+			"host":       cert.Host,
+			"issuer":     cert.Issuer,
+			"notAfter":   formatNullableTime(cert.NotAfter),
+			"threshold":  days,
+		})
+}
+
+func (s *CaddyCertificateService) notifyRenewalFailure(server *model.CaddyServer, cert *model.CaddyCertificate) {
+	notifyWafEventAsync(s.svcCtx.NotificationMgr, s.logger,
+		notification.EventCaddyCertificateRenewalFailed,
+		notification.LevelError,
+		"Caddy 证书续期失败",
+		fmt.Sprintf("节点 %s 的证书 %s 签发/续期失败：%s", server.Name, cert.Host, cert.RenewalError),
+		map[string]interface{}{
+			"serverId":   server.ID,
+			"serverName": server.Name,
+			"host":       cert.Host,
+			"error":      cert.RenewalError,
+			"notAfter":   formatNullableTime(cert.NotAfter),
+		})
+}
+
+// caddyCertTargetsFromConfig 提取配置中启用 HTTPS 的站点地址，跳过 http://、:80、仅端口与含占位符的地址
+func caddyCertTargetsFromConfig(config string) []caddyCertTarget {
+	if strings.TrimSpace(config) == "" {
+		return nil
+	}
+	doc, err := caddyfile.Parse(config)
+	if err != nil {
+		return nil
+	}
+	seen := map[string]bool{}
+	targets := make([]caddyCertTarget, 0)
+	for _, site := range doc.Sites() {
+		for _, address := range caddyfile.SiteAddresses(site) {
+			host := strings.ToLower(strings.TrimSpace(address))
+			if strings.HasPrefix(host, "http://") {
+				continue
+			}
+			host = strings.TrimPrefix(host, "https://")
+			if i := strings.IndexByte(host, '/'); i >= 0 {
+				host = host[:i]
+			}
+			port := caddyCertDefaultHTTPSPort
+			if h, p, err := net.SplitHostPort(host); err == nil {
+				host, port = h, p
+			}
+			if host == "" || port == "80" || strings.Contains(host, "{") {
+				continue
+			}
+			if !seen[host] {
+				seen[host] = true
+				targets = append(targets, caddyCertTarget{Host: host, Port: port})
+			}
+		}
+	}
+	return targets
+}
+
+// probeCaddyCertificate 完成 TLS 握手并返回对端证书链；链与主机名校验结果单独返回，
+// 以便记录已过期或自签名证书的详情
+func probeCaddyCertificate(ctx context.Context, dial func(ctx context.Context, network, addr string) (net.Conn, error), addr, serverName string, roots *x509.CertPool) ([]*x509.Certificate, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, caddyCertProbeTimeout)
+	defer cancel()
+	raw, err := dial(ctx, "tcp", addr)
+	if err != nil {
+		return nil, "", fmt.Errorf("连接 %s 失败: %w", addr, err)
+	}
+	// 需要读取无效证书的详情，校验在握手完成后单独进行
+	conn := tls.Client(raw, &tls.Config{ServerName: serverName, InsecureSkipVerify: true})
+	defer conn.Close()
+	if err := conn.HandshakeContext(ctx); err != nil {
+		return nil, "", fmt.Errorf("TLS 握手失败: %w", err)
+	}
+	chain := conn.ConnectionState().PeerCertificates
+	if len(chain) == 0 {
+		return nil, "", fmt.Errorf("对端未返回证书")
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+	verifyErr := ""
+	if _, err := chain[0].Verify(x509.VerifyOptions{DNSName: serverName, Roots: roots, Intermediates: intermediates}); err != nil {
+		verifyErr = err.Error()
+	}
+	return chain, verifyErr, nil
+}
+
+// parseCaddyRenewalEvents 按时间顺序回放 tls.* 日志：带 error 的 warn/error 记为失败，
+// “successfully” 的 info 日志清除失败
+func parseCaddyRenewalEvents(logs []model.SystemLog) map[string]caddyRenewalState {
+	ordered := make([]model.SystemLog, len(logs))
+	copy(ordered, logs)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].LogTime.Before(ordered[j].LogTime) })
+
+	states := map[string]caddyRenewalState{}
+	for _, entry := range ordered {
+		var extra map[string]any
+		if err := json.Unmarshal([]byte(entry.ExtraData), &extra); err != nil {
+			continue
+		}
+		if logger, _ := extra["logger"].(string); !strings.HasPrefix(logger, "tls") {
+			continue
+		}
+		identifiers := caddyRenewalIdentifiers(extra)
+		if len(identifiers) == 0 {
+			continue
+		}
+		level := strings.ToLower(entry.Level)
+		message := strings.TrimSpace(entry.Message)
+		var state caddyRenewalState
+		switch {
+		case level == "info" && strings.Contains(strings.ToLower(message), "successfully"):
+			state = caddyRenewalState{At: entry.LogTime}
+		case level == "error" || level == "warn":
+			errText, _ := extra["error"].(string)
+			if errText == "" {
+				continue
+			}
+			state = caddyRenewalState{Error: truncateWafFeedbackText(message+": "+errText, 1000), At: entry.LogTime}
+		default:
+			continue
+		}
+		for _, identifier := range identifiers {
+			states[identifier] = state
+		}
+	}
+	return states
+}
+
+func caddyRenewalIdentifiers(extra map[string]any) []string {
+	identifiers := make([]string, 0, 1)
+	if value, ok := extra["identifier"].(string); ok && value != "" {
+		identifiers = append(identifiers, strings.ToLower(value))
+	}
+	if values, ok := extra["identifiers"].([]any); ok {
+		for _, value := range values {
+			if text, ok := value.(string); ok && text != "" {
+				identifiers = append(identifiers, strings.ToLower(text))
+			}
+		}
+	}
+	return identifiers
+}
+
+// normalizeCaddyCertWarnDays 去重并升序排列提醒阈值，未配置时使用默认值
+func normalizeCaddyCertWarnDays(values []int) []int {
+	seen := map[int]bool{}
+	days := make([]int, 0, len(values))
+	for _, value := range values {
+		if value > 0 && !seen[value] {
+			seen[value] = true
+			days = append(days, value)
+		}
+	}
+	if len(days) == 0 {
+		days = append(days, defaultCaddyCertWarnDays...)
+	}
+	sort.Ints(days)
+	return days
+}
+
+// caddyCertNotifyThreshold 返回当前命中的最小阈值（已过期为 0）；该阈值或更小阈值已通知过时不再提醒
+func caddyCertNotifyThreshold(notAfter, now time.Time, warnDays []int, notified *int) (int, bool) {
+	threshold := -1
+	if !now.Before(notAfter) {
+		threshold = 0
+	} else {
+		left := notAfter.Sub(now)
+		for _, days := range warnDays {
+			if left <= time.Duration(days)*24*time.Hour {
+				threshold = days
+				break
+			}
+		}
+	}
+	if threshold < 0 {
+		return 0, false
+	}
+	if notified != nil && *notified <= threshold {
+		return threshold, false
+	}
+	return threshold, true
+}
+
+func caddyCertificateStatus(cert *model.CaddyCertificate, now time.Time, warnDays []int) string {
+	if cert.NotAfter == nil {
+		if cert.ProbeError != "" {
+			return caddyCertStatusError
+		}
+		return caddyCertStatusUnknown
+	}
+	if !now.Before(*cert.NotAfter) {
+		return caddyCertStatusExpired
+	}
+	if caddyCertWatchExpiry(cert) && cert.NotAfter.Sub(now) <= time.Duration(warnDays[len(warnDays)-1])*24*time.Hour {
+		return caddyCertStatusExpiring
+	}
+	return caddyCertStatusValid
+}
+
+// caddyCertWatchExpiry 是否按阈值提醒到期；PKI 中间证书有效期仅数天且由 Caddy 自动轮换，只关注是否已过期
+func caddyCertWatchExpiry(cert *model.CaddyCertificate) bool {
+	return cert.Source != caddyCertSourcePKI || strings.HasSuffix(cert.Host, "/root")
+}
+
+func caddyCertDaysLeft(notAfter, now time.Time) int {
+	if !now.Before(notAfter) {
+		return 0
+	}
+	return int(notAfter.Sub(now).Hours() / 24)
+}
+
+// fillCaddyCertificate 写入证书详情；指纹变化（已续期或更换）时重置提醒记录
+func fillCaddyCertificate(cert *model.CaddyCertificate, leaf *x509.Certificate) {
+	sum := sha256.Sum256(leaf.Raw)
+	fingerprint := hex.EncodeToString(sum[:])
+	if cert.Fingerprint != fingerprint {
+		cert.NotifiedDays = nil
+	}
+	cert.Fingerprint = fingerprint
+	cert.Subject = caddyCertName(leaf.Subject.CommonName, leaf.Subject.String())
+	cert.Issuer = caddyCertName(leaf.Issuer.CommonName, leaf.Issuer.String())
+	sans := make([]string, 0, len(leaf.DNSNames)+len(leaf.IPAddresses))
+	sans = append(sans, leaf.DNSNames...)
+	for _, ip := range leaf.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	cert.SANs = sans
+	cert.SerialNumber = leaf.SerialNumber.Text(16)
+	notBefore, notAfter := leaf.NotBefore, leaf.NotAfter
+	cert.NotBefore = &notBefore
+	cert.NotAfter = &notAfter
+}
+
+func caddyCertName(commonName, full string) string {
+	if commonName != "" {
+		return truncateWafFeedbackText(commonName, 255)
+	}
+	return truncateWafFeedbackText(full, 255)
+}
+
+func parseFirstPEMCertificate(text string) (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(text))
+	if block == nil {
+		return nil, nil
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// caddyAdminHost 握手探测的连接主机，取管理接口地址的主机名
+func caddyAdminHost(adminURL string) string {
+	parsed, err := url.Parse(strings.TrimSpace(adminURL))
+	if err != nil || parsed.Hostname() == "" {
+		return "127.0.0.1"
+	}
+	return parsed.Hostname()
+}
+
+func toCaddyCertificateItem(cert *model.CaddyCertificate, serverName string, now time.Time) types.CaddyCertificateItem {
+	item := types.CaddyCertificateItem{
+		ID:             cert.ID,
+		ServerId:       cert.ServerID,
+		ServerName:     serverName,
+		Host:           cert.Host,
+		Source:         cert.Source,
+		Subject:        cert.Subject,
+		Issuer:         cert.Issuer,
+		Sans:           []string(cert.SANs),
+		SerialNumber:   cert.SerialNumber,
+		Fingerprint:    cert.Fingerprint,
+		NotBefore:      formatNullableTime(cert.NotBefore),
+		NotAfter:       formatNullableTime(cert.NotAfter),
+		Status:         cert.Status,
+		VerifyError:    cert.VerifyError,
+		ProbeError:     cert.ProbeError,
+		LastProbedAt:   formatNullableTime(cert.LastProbedAt),
+		RenewalError:   cert.RenewalError,
+		RenewalErrorAt: formatNullableTime(cert.RenewalErrorAt),
+		UpdatedAt:      formatTime(cert.UpdatedAt),
+	}
+	if item.Sans == nil {
+		item.Sans = []string{}
+	}
+	if cert.NotAfter != nil {
+		item.DaysLeft = caddyCertDaysLeft(*cert.NotAfter, now)
+	}
+	return item
+}
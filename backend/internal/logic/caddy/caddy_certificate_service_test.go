@@ -0,0 +1,171 @@
+package caddy
+
+import (
+	"context"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"logflux/internal/svc"
+	"logflux/model"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestCaddyCertTargetsFromConfig(t *testing.T) {
+	config := `{
+	admin :2019
+}
+
+example.com, www.example.com:8443 {
+	respond "ok"
+}
+
+http://plain.example.com, :80 {
+	respond "plain"
+}
+
+https://*.apps.example.com/api {
+	respond "wildcard"
+}
+
+{$SITE_HOST} {
+	respond "env"
+}
+`
+	targets := caddyCertTargetsFromConfig(config)
+	got := make([]string, 0, len(targets))
+	for _, target := range targets {
+		got = append(got, target.Host+":"+target.Port)
+	}
+	want := "example.com:443 www.example.com:8443 *.apps.example.com:443"
+	if strings.Join(got, " ") != want {
+		t.Fatalf("expected %q, got %q", want, strings.Join(got, " "))
+	}
+}
+
+func TestParseCaddyRenewalEvents(t *testing.T) {
+	base := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	logs := []model.SystemLog{
+		{
+			LogTime:   base.Add(2 * time.Hour),
+			Level:     "info",
+			Message:   "certificate obtained successfully",
+			ExtraData: `{"logger":"tls.obtain","identifier":"ok.example.com"}`,
+		},
+		{
+			LogTime:   base,
+			Level:     "error",
+			Message:   "could not get certificate from issuer",
+			ExtraData: `{"logger":"tls.obtain","identifier":"ok.example.com","error":"HTTP 429 urn:ietf:params:acme:error:rateLimited"}`,
+		},
+		{
+			LogTime:   base.Add(time.Hour),
+			Level:     "error",
+			Message:   "will retry",
+			ExtraData: `{"logger":"tls.renew","identifier":"Bad.Example.com","error":"no solvers available"}`,
+		},
+		{
+			LogTime:   base.Add(time.Hour),
+			Level:     "error",
+			Message:   "handler error",
+			ExtraData: `{"logger":"http.log.error","identifier":"other.example.com","error":"boom"}`,
+		},
+	}
+
+	states := parseCaddyRenewalEvents(logs)
+	if state, ok := states["ok.example.com"]; !ok || state.Error != "" {
+		t.Fatalf("expected later success to clear ok.example.com, got %+v", state)
+	}
+	bad := states["bad.example.com"]
+	if bad.Error != "will retry: no solvers available" || !bad.At.Equal(base.Add(time.Hour)) {
+		t.Fatalf("unexpected renewal state for bad.example.com: %+v", bad)
+	}
+	if _, ok := states["other.example.com"]; ok {
+		t.Fatalf("non-tls logger should be ignored")
+	}
+}
+
+func TestLoadRenewalStatesReadsOnlyServerLogSources(t *testing.T) {
+	db, mock, cleanup := newWafMirrorMockDB(t)
+	defer cleanup()
+	service := NewCaddyCertificateService(context.Background(), &svc.ServiceContext{DB: db})
+	at := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return at }
+
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "caddy_servers"`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery(`SELECT "path" FROM "log_sources" WHERE server_id IN \(\$1\)`).
+		WithArgs(uint(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"path"}).AddRow("/var/log/caddy/edge-3.log"))
+	mock.ExpectQuery(`SELECT "log_time","level","message","extra_data" FROM "system_logs" WHERE source = \$1 AND file_path IN \(\$2\)`).
+		WithArgs("caddy_runtime", "/var/log/caddy/edge-3.log", sqlmock.AnyArg(), "tls%", caddyCertRenewalLogLimit).
+		WillReturnRows(sqlmock.NewRows([]string{"log_time", "level", "message", "extra_data"}).
+			AddRow(at, "error", "will retry", `{"logger":"tls.renew","identifier":"edge.example.com","error":"no solvers available"}`))
+
+	states, err := service.loadRenewalStates(&model.CaddyServer{ID: 3})
+	if err != nil {
+		t.Fatalf("load renewal states failed: %v", err)
+	}
+	if states["edge.example.com"].Error == "" {
+		t.Fatalf("expected renewal failure for edge.example.com, got %+v", states)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet sql expectations: %v", err)
+	}
+}
+
+func TestCaddyCertNotifyThreshold(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	warnDays := normalizeCaddyCertWarnDays([]int{7, 30, 0, 7, 1})
+	intPtr := func(v int) *int { return &v }
+
+	cases := []struct {
+		name     string
+		notAfter time.Time
+		notified *int
+		want     int
+		notify   bool
+	}{
+		{"outside window", now.AddDate(0, 0, 45), nil, 0, false},
+		{"first threshold", now.AddDate(0, 0, 20), nil, 30, true},
+		{"already notified", now.AddDate(0, 0, 20), intPtr(30), 30, false},
+		{"next threshold", now.AddDate(0, 0, 5), intPtr(30), 7, true},
+		{"skipped to last", now.Add(12 * time.Hour), nil, 1, true},
+		{"expired", now.Add(-time.Minute), intPtr(1), 0, true},
+		{"expired notified", now.Add(-time.Minute), intPtr(0), 0, false},
+	}
+	for _, tc := range cases {
+		got, notify := caddyCertNotifyThreshold(tc.notAfter, now, warnDays, tc.notified)
+		if notify != tc.notify || (notify && got != tc.want) {
+			t.Fatalf("%s: expected (%d, %v), got (%d, %v)", tc.name, tc.want, tc.notify, got, notify)
+		}
+	}
+}
+
+func TestProbeCaddyCertificate(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	dialer := &net.Dialer{Timeout: time.Second}
+	addr := server.Listener.Addr().String()
+
+	chain, verifyErr, err := probeCaddyCertificate(context.Background(), dialer.DialContext, addr, "example.com", roots)
+	if err != nil || verifyErr != "" {
+		t.Fatalf("expected trusted handshake, got verify=%q err=%v", verifyErr, err)
+	}
+	cert := &model.CaddyCertificate{}
+	fillCaddyCertificate(cert, chain[0])
+	if cert.NotAfter == nil || cert.Fingerprint == "" || !strings.Contains(strings.Join(cert.SANs, ","), "example.com") {
+		t.Fatalf("unexpected certificate details: %+v", cert)
+	}
+
+	if _, verifyErr, err = probeCaddyCertificate(context.Background(), dialer.DialContext, addr, "mismatch.test", roots); err != nil || verifyErr == "" {
+		t.Fatalf("expected hostname verify error, got verify=%q err=%v", verifyErr, err)
+	}
+}
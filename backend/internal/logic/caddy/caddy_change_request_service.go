@@ -0,0 +1,669 @@
+package caddy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"logflux/internal/notification"
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/internal/utils"
+	"logflux/internal/utils/safego"
+	"logflux/internal/utils/textdiff"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+const (
+	caddyChangeKindConfig    = "caddy_config"
+	caddyChangeKindWafPolicy = "waf_policy"
+
+	caddyChangeStatusDraft     = "draft"
+	caddyChangeStatusPending   = "pending"
+	caddyChangeStatusRejected  = "rejected"
+	caddyChangeStatusCancelled = "cancelled"
+	caddyChangeStatusScheduled = "scheduled"
+	caddyChangeStatusApplying  = "applying"
+	caddyChangeStatusApplied   = "applied"
+	caddyChangeStatusFailed    = "failed"
+
+	caddyChangeActionComment = "comment"
+	caddyChangeActionSubmit  = "submit"
+	caddyChangeActionApprove = "approve"
+	caddyChangeActionReject  = "reject"
+	caddyChangeActionCancel  = "cancel"
+	caddyChangeActionApply   = "apply"
+	caddyChangeActionFail    = "fail"
+
+	// caddyChangeRequestHistoryAction 变更申请应用时写入 caddy_config_history 的动作
+	caddyChangeRequestHistoryAction = "change_request"
+	caddyChangeApproverRole         = "approver"
+)
+
+// caddyChangeActor 当前操作用户；operator 与其他配置历史一致使用用户 ID
+type caddyChangeActor struct {
+	ID    string
+	Name  string
+	Roles []string
+}
+
+func (a *caddyChangeActor) hasRole(names ...string) bool {
+	for _, role := range a.Roles {
+		for _, name := range names {
+			if strings.TrimSpace(role) == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func loadCaddyChangeActor(ctx context.Context, db *gorm.DB) (*caddyChangeActor, error) {
+	operator := currentOperatorFromContext(ctx)
+	actor := &caddyChangeActor{ID: operator, Name: operator}
+	userID, err := strconv.ParseUint(operator, 10, 64)
+	if err != nil {
+		return actor, nil
+	}
+	var user model.User
+	if err := db.WithContext(ctx).Select("id", "username", "roles").First(&user, userID).Error; err != nil {
+		return nil, fmt.Errorf("查询当前用户失败: %w", err)
+	}
+	actor.Name = user.Username
+	actor.Roles = user.Roles
+	return actor, nil
+}
+
+// ensureDirectCaddyChangeAllowed 启用变更审批后，直接修改配置的入口需改走变更申请
+func ensureDirectCaddyChangeAllowed(svcCtx *svc.ServiceContext) error {
+	if svcCtx != nil && svcCtx.Config.Caddy.RequireChangeApproval {
+		return fmt.Errorf("已启用变更审批，请通过变更申请提交并经审批后应用")
+	}
+	return nil
+}
+
+// CaddyChangeRequestService 变更申请：草稿 → 待审批 → 批准（立即或定时应用）/ 驳回；
+// 审批人须具备 approver 或 admin 角色且不能是申请人
+type CaddyChangeRequestService struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	logger logx.Logger
+	now    func() time.Time
+	// background 执行批准后的立即应用，为空时使用 safego 协程
+	background func(name string, job func())
+}
+
+func NewCaddyChangeRequestService(ctx context.Context, svcCtx *svc.ServiceContext) *CaddyChangeRequestService {
+	return &CaddyChangeRequestService{ctx: ctx, svcCtx: svcCtx, logger: logx.WithContext(ctx), now: time.Now}
+}
+
+func (s *CaddyChangeRequestService) db() *gorm.DB {
+	return s.svcCtx.DB.WithContext(s.ctx)
+}
+
+// caddyChangeDraft 创建或编辑申请时提交的内容
+type caddyChangeDraft struct {
+	ServerID    uint
+	PolicyID    uint
+	Title       string
+	Description string
+	Config      string
+	Modules     string
+	ScheduledAt string
+	Submit      bool
+}
+
+func (s *CaddyChangeRequestService) Create(actor *caddyChangeActor, kind string, draft caddyChangeDraft) (*model.CaddyChangeRequest, error) {
+	kind = strings.TrimSpace(kind)
+	if kind != caddyChangeKindConfig && kind != caddyChangeKindWafPolicy {
+		return nil, fmt.Errorf("变更类型无效: %s", kind)
+	}
+	request := &model.CaddyChangeRequest{
+		Kind:       kind,
+		Status:     caddyChangeStatusDraft,
+		Author:     actor.ID,
+		AuthorName: actor.Name,
+	}
+	if err := s.fill(request, draft); err != nil {
+		return nil, err
+	}
+	if err := s.db().Create(request).Error; err != nil {
+		return nil, fmt.Errorf("创建变更申请失败: %w", err)
+	}
+	if draft.Submit {
+		return s.Submit(actor, request.ID, "")
+	}
+	return request, nil
+}
+
+// Update 仅申请人可编辑草稿或被驳回的申请，编辑后回到草稿
+func (s *CaddyChangeRequestService) Update(actor *caddyChangeActor, id uint, draft caddyChangeDraft) (*model.CaddyChangeRequest, error) {
+	request, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+	if request.Author != actor.ID {
+		return nil, fmt.Errorf("只能编辑自己提交的变更申请")
+	}
+	if request.Status != caddyChangeStatusDraft && request.Status != caddyChangeStatusRejected {
+		return nil, fmt.Errorf("仅草稿或已驳回的变更申请可以编辑")
+	}
+	if err := s.fill(request, draft); err != nil {
+		return nil, err
+	}
+	request.Status = caddyChangeStatusDraft
+	request.Reviewer, request.ReviewerName, request.ReviewedAt = "", "", nil
+	if err := s.db().Save(request).Error; err != nil {
+		return nil, fmt.Errorf("保存变更申请失败: %w", err)
+	}
+	if draft.Submit {
+		return s.Submit(actor, request.ID, "")
+	}
+	return request, nil
+}
+
+func (s *CaddyChangeRequestService) fill(request *model.CaddyChangeRequest, draft caddyChangeDraft) error {
+	request.Title = strings.TrimSpace(draft.Title)
+	if request.Title == "" {
+		return fmt.Errorf("变更标题不能为空")
+	}
+	if len([]rune(request.Title)) > 200 {
+		return fmt.Errorf("变更标题不能超过 200 个字符")
+	}
+	request.Description = strings.TrimSpace(draft.Description)
+	scheduledAt, err := utils.ParseOptionalTime(draft.ScheduledAt)
+	if err != nil {
+		return fmt.Errorf("应用时间格式不合法: %w", err)
+	}
+	request.ScheduledAt = scheduledAt
+
+	switch request.Kind {
+	case caddyChangeKindConfig:
+		if draft.ServerID == 0 {
+			return fmt.Errorf("请选择 Caddy 服务器")
+		}
+		if strings.TrimSpace(draft.Config) == "" {
+			return fmt.Errorf("提议的配置不能为空")
+		}
+		request.ServerID = draft.ServerID
+		request.PolicyID = 0
+		request.Config = draft.Config
+		request.Modules = draft.Modules
+	case caddyChangeKindWafPolicy:
+		if draft.PolicyID == 0 {
+			return fmt.Errorf("请选择 WAF 策略")
+		}
+		request.PolicyID = draft.PolicyID
+	}
+	return s.prepare(request)
+}
+
+// prepare 基于节点当前保存的配置生成候选配置、哈希与 diff
+func (s *CaddyChangeRequestService) prepare(request *model.CaddyChangeRequest) error {
+	var server *model.CaddyServer
+	switch request.Kind {
+	case caddyChangeKindWafPolicy:
+		candidate, err := NewPolicyPublishService(s.ctx, s.svcCtx).BuildPublishCandidate(request.PolicyID)
+		if err != nil {
+			return err
+		}
+		server = candidate.Server
+		request.ServerID = server.ID
+		request.Config = candidate.CandidateConfig
+		request.Modules = candidate.LastGoodModules
+	default:
+		var current model.CaddyServer
+		if err := s.db().First(&current, request.ServerID).Error; err != nil {
+			return fmt.Errorf("服务器不存在")
+		}
+		server = &current
+		if strings.TrimSpace(request.Modules) == "" {
+			request.Modules = server.Modules
+		}
+	}
+	request.Modules = normalizeCaddyModulesJSON(request.Modules)
+
+	diff := textdiff.Unified("当前配置", "变更申请", server.Config, request.Config, caddyConfigDiffContextLines)
+	if diff.Identical() {
+		return fmt.Errorf("提议的配置与节点当前配置一致，无需变更")
+	}
+	request.BaseHash = hashConfig(server.Config)
+	request.ProposedHash = hashConfig(request.Config)
+	request.Diff = diff.Unified
+	request.Added = diff.Added
+	request.Removed = diff.Removed
+	return nil
+}
+
+// Submit 提交审批；重新生成 diff，确保审批人看到的是相对最新配置的变更
+func (s *CaddyChangeRequestService) Submit(actor *caddyChangeActor, id uint, comment string) (*model.CaddyChangeRequest, error) {
+	request, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+	if request.Author != actor.ID {
+		return nil, fmt.Errorf("只能提交自己的变更申请")
+	}
+	if request.Status != caddyChangeStatusDraft && request.Status != caddyChangeStatusRejected {
+		return nil, fmt.Errorf("当前状态不能提交审批")
+	}
+	if err := s.prepare(request); err != nil {
+		return nil, err
+	}
+	now := s.now()
+	request.Status = caddyChangeStatusPending
+	request.SubmittedAt = &now
+	request.Reviewer, request.ReviewerName, request.ReviewedAt = "", "", nil
+	if err := s.db().Save(request).Error; err != nil {
+		return nil, fmt.Errorf("提交变更申请失败: %w", err)
+	}
+	s.record(request.ID, caddyChangeActionSubmit, actor, comment)
+	s.notify(request, notification.EventCaddyChangeRequestSubmitted, notification.LevelInfo, "变更申请待审批",
+		fmt.Sprintf("%s 提交了变更申请 #%d「%s」（+%d -%d），等待审批", actor.Name, request.ID, request.Title, request.Added, request.Removed))
+	return request, nil
+}
+
+func (s *CaddyChangeRequestService) Comment(actor *caddyChangeActor, id uint, content string) error {
+	if _, err := s.load(id); err != nil {
+		return err
+	}
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return fmt.Errorf("评论内容不能为空")
+	}
+	if err := s.db().Create(&model.CaddyChangeRequestComment{
+		RequestID:  id,
+		Action:     caddyChangeActionComment,
+		Author:     actor.ID,
+		AuthorName: actor.Name,
+		Content:    content,
+	}).Error; err != nil {
+		return fmt.Errorf("保存评论失败: %w", err)
+	}
+	return nil
+}
+
+// Approve 批准后有未来的应用时间则进入定时队列，否则立即应用；应用失败不影响审批结果
+func (s *CaddyChangeRequestService) Approve(actor *caddyChangeActor, id uint, comment, scheduledAt string) (*model.CaddyChangeRequest, error) {
+	request, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.hasRole(caddyChangeApproverRole, "admin") {
+		return nil, fmt.Errorf("需要审批人角色才能批准变更申请")
+	}
+	if request.Author == actor.ID {
+		return nil, fmt.Errorf("不能审批自己提交的变更申请")
+	}
+	if request.Status != caddyChangeStatusPending {
+		return nil, fmt.Errorf("仅待审批的变更申请可以批准")
+	}
+	if strings.TrimSpace(scheduledAt) != "" {
+		override, err := utils.ParseOptionalTime(scheduledAt)
+		if err != nil {
+			return nil, fmt.Errorf("应用时间格式不合法: %w", err)
+		}
+		request.ScheduledAt = override
+	}
+
+	now := s.now()
+	next := caddyChangeStatusApplying
+	if request.ScheduledAt != nil && request.ScheduledAt.After(now) {
+		next = caddyChangeStatusScheduled
+	}
+	result := s.db().Model(&model.CaddyChangeRequest{}).
+		Where("id = ? AND status = ?", request.ID, caddyChangeStatusPending).
+		Updates(map[string]interface{}{
+			"status":        next,
+			"reviewer":      actor.ID,
+			"reviewer_name": actor.Name,
+			"reviewed_at":   now,
+			"scheduled_at":  request.ScheduledAt,
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("更新变更申请失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("变更申请状态已变化，请刷新后重试")
+	}
+	request.Status = next
+	request.Reviewer = actor.ID
+	request.ReviewerName = actor.Name
+	request.ReviewedAt = &now
+	s.record(request.ID, caddyChangeActionApprove, actor, comment)
+
+	message := fmt.Sprintf("%s 批准了变更申请 #%d「%s」，即将应用", actor.Name, request.ID, request.Title)
+	if next == caddyChangeStatusScheduled {
+		message = fmt.Sprintf("%s 批准了变更申请 #%d「%s」，将于 %s 应用", actor.Name, request.ID, request.Title, formatNullableTime(request.ScheduledAt))
+	}
+	s.notify(request, notification.EventCaddyChangeRequestApproved, notification.LevelInfo, "变更申请已批准", message)
+
+	if next == caddyChangeStatusApplying {
+		// 应用在后台执行，不阻塞审批请求；结果写回申请并通知
+		applying := *request
+		detached := *s
+		detached.ctx = context.Background()
+		s.runBackground("应用已批准的变更申请", func() {
+			_ = detached.execute(&applying, actor)
+		})
+	}
+	return request, nil
+}
+
+func (s *CaddyChangeRequestService) runBackground(name string, job func()) {
+	if s.background != nil {
+		s.background(name, job)
+		return
+	}
+	safego.New(context.Background(), name).Go(job)
+}
+
+func (s *CaddyChangeRequestService) Reject(actor *caddyChangeActor, id uint, comment string) (*model.CaddyChangeRequest, error) {
+	request, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.hasRole(caddyChangeApproverRole, "admin") {
+		return nil, fmt.Errorf("需要审批人角色才能驳回变更申请")
+	}
+	if request.Author == actor.ID {
+		return nil, fmt.Errorf("不能审批自己提交的变更申请，如需撤回请取消")
+	}
+	if strings.TrimSpace(comment) == "" {
+		return nil, fmt.Errorf("请填写驳回原因")
+	}
+	now := s.now()
+	if err := s.transition(request, []string{caddyChangeStatusPending}, caddyChangeStatusRejected, map[string]interface{}{
+		"reviewer":      actor.ID,
+		"reviewer_name": actor.Name,
+		"reviewed_at":   now,
+	}); err != nil {
+		return nil, err
+	}
+	request.Reviewer = actor.ID
+	request.ReviewerName = actor.Name
+	request.ReviewedAt = &now
+	s.record(request.ID, caddyChangeActionReject, actor, comment)
+	s.notify(request, notification.EventCaddyChangeRequestRejected, notification.LevelWarning, "变更申请已驳回",
+		fmt.Sprintf("%s 驳回了变更申请 #%d「%s」：%s", actor.Name, request.ID, request.Title, strings.TrimSpace(comment)))
+	return request, nil
+}
+
+// Cancel 申请人或管理员可取消未应用的申请
+func (s *CaddyChangeRequestService) Cancel(actor *caddyChangeActor, id uint, comment string) (*model.CaddyChangeRequest, error) {
+	request, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+	if request.Author != actor.ID && !actor.hasRole("admin") {
+		return nil, fmt.Errorf("只能取消自己提交的变更申请")
+	}
+	from := []string{caddyChangeStatusDraft, caddyChangeStatusPending, caddyChangeStatusRejected, caddyChangeStatusScheduled, caddyChangeStatusFailed}
+	if err := s.transition(request, from, caddyChangeStatusCancelled, nil); err != nil {
+		return nil, err
+	}
+	s.record(request.ID, caddyChangeActionCancel, actor, comment)
+	s.notify(request, notification.EventCaddyChangeRequestCancelled, notification.LevelInfo, "变更申请已取消",
+		fmt.Sprintf("%s 取消了变更申请 #%d「%s」", actor.Name, request.ID, request.Title))
+	return request, nil
+}
+
+// Apply 立即应用已定时或应用失败的申请（审批结果保持有效）
+func (s *CaddyChangeRequestService) Apply(actor *caddyChangeActor, id uint) (*model.CaddyChangeRequest, error) {
+	request, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.transition(request, []string{caddyChangeStatusScheduled, caddyChangeStatusFailed}, caddyChangeStatusApplying, nil); err != nil {
+		return nil, err
+	}
+	if err := s.execute(request, actor); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// RunDue 应用到期的定时申请，由调度器周期调用
+func (s *CaddyChangeRequestService) RunDue() error {
+	var due []model.CaddyChangeRequest
+	if err := s.db().Where("status = ? AND scheduled_at <= ?", caddyChangeStatusScheduled, s.now()).
+		Order("scheduled_at asc, id asc").Find(&due).Error; err != nil {
+		return fmt.Errorf("查询到期变更申请失败: %w", err)
+	}
+	system := &caddyChangeActor{ID: "system", Name: "system"}
+	for i := range due {
+		request := &due[i]
+		if err := s.transition(request, []string{caddyChangeStatusScheduled}, caddyChangeStatusApplying, nil); err != nil {
+			continue
+		}
+		if err := s.execute(request, system); err != nil {
+			s.logger.Errorf("定时应用变更申请失败: id=%d err=%v", request.ID, err)
+		}
+	}
+	return nil
+}
+
+// transition 以条件更新切换状态，避免手动应用与定时任务重复执行
+func (s *CaddyChangeRequestService) transition(request *model.CaddyChangeRequest, from []string, to string, extra map[string]interface{}) error {
+	updates := map[string]interface{}{"status": to}
+	for key, value := range extra {
+		updates[key] = value
+	}
+	result := s.db().Model(&model.CaddyChangeRequest{}).Where("id = ? AND status IN ?", request.ID, from).Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("更新变更申请失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("变更申请当前状态为 %s，不能执行该操作", request.Status)
+	}
+	request.Status = to
+	return nil
+}
+
+// execute 通过常规发布流程应用申请（状态需已切换为 applying），结果写回申请并通知
+func (s *CaddyChangeRequestService) execute(request *model.CaddyChangeRequest, actor *caddyChangeActor) error {
+	var applyErr error
+	switch request.Kind {
+	case caddyChangeKindWafPolicy:
+		applyErr = s.applyWafPolicy(request)
+	default:
+		applyErr = s.applyCaddyConfig(request)
+	}
+
+	now := s.now()
+	updates := map[string]interface{}{}
+	if applyErr != nil {
+		request.Status = caddyChangeStatusFailed
+		request.Message = applyErr.Error()
+	} else {
+		request.Status = caddyChangeStatusApplied
+		request.Message = "已应用"
+		request.AppliedAt = &now
+		updates["applied_at"] = now
+		updates["history_id"] = request.HistoryID
+		updates["revision_id"] = request.RevisionID
+	}
+	updates["status"] = request.Status
+	updates["message"] = request.Message
+	if err := s.db().Model(&model.CaddyChangeRequest{}).Where("id = ?", request.ID).Updates(updates).Error; err != nil {
+		s.logger.Errorf("更新变更申请应用结果失败: id=%d err=%v", request.ID, err)
+	}
+
+	if applyErr != nil {
+		s.record(request.ID, caddyChangeActionFail, actor, applyErr.Error())
+		s.notify(request, notification.EventCaddyChangeRequestFailed, notification.LevelError, "变更申请应用失败",
+			fmt.Sprintf("变更申请 #%d「%s」应用失败：%v", request.ID, request.Title, applyErr))
+		return fmt.Errorf("变更申请应用失败: %w", applyErr)
+	}
+	s.record(request.ID, caddyChangeActionApply, actor, "")
+	s.notify(request, notification.EventCaddyChangeRequestApplied, notification.LevelInfo, "变更申请已应用",
+		fmt.Sprintf("变更申请 #%d「%s」已应用（申请人 %s，审批人 %s）", request.ID, request.Title, request.AuthorName, request.ReviewerName))
+	return nil
+}
+
+func (s *CaddyChangeRequestService) applyCaddyConfig(request *model.CaddyChangeRequest) error {
+	var server model.CaddyServer
+	if err := s.db().First(&server, request.ServerID).Error; err != nil {
+		return fmt.Errorf("服务器不存在")
+	}
+	if hashConfig(server.Config) != request.BaseHash {
+		return fmt.Errorf("节点 %s 的配置在申请提交后已被修改，请基于最新配置重新提交", server.Name)
+	}
+	note := fmt.Sprintf("变更申请 #%d：%s（审批人 %s）", request.ID, request.Title, request.ReviewerName)
+	if err := newCaddyConfigApplyService(s.svcCtx, s.logger).withNote(request.Author, note).approvedChange().
+		apply(&server, request.Config, request.Modules, caddyChangeRequestHistoryAction); err != nil {
+		return err
+	}
+	var history model.CaddyConfigHistory
+	if err := s.db().Select("id").Where("server_id = ? AND action = ?", server.ID, caddyChangeRequestHistoryAction).
+		Order("id desc").First(&history).Error; err == nil {
+		request.HistoryID = history.ID
+	}
+	return nil
+}
+
+// applyWafPolicy 重新生成发布候选并与审批时的候选比对，策略或节点配置变化后需重新提交
+func (s *CaddyChangeRequestService) applyWafPolicy(request *model.CaddyChangeRequest) error {
+	publishService := NewPolicyPublishService(s.ctx, s.svcCtx).approvedChange()
+	candidate, err := publishService.BuildPublishCandidate(request.PolicyID)
+	if err != nil {
+		return err
+	}
+	if hashConfig(candidate.CandidateConfig) != request.ProposedHash {
+		return fmt.Errorf("策略或节点配置在申请提交后已变化，请重新提交")
+	}
+	helper := NewWafPolicyNotifyAuditHelper(s.svcCtx, s.logger)
+	if err := publishService.ValidateCandidate(candidate, "publish"); err != nil {
+		return fmt.Errorf("策略发布前校验失败: %w", err)
+	}
+	if err := publishService.LoadCandidate(candidate, "publish"); err != nil {
+		return err
+	}
+	if err := publishService.PersistPublishedCandidate(candidate, request.Author); err != nil {
+		helper.NotifyAutoRollback(fmt.Sprintf("WAF 策略发布落库失败，已自动回滚到 last_good：policy=%s", candidate.Policy.Name), candidate.Policy.ID, candidate.Policy.Name, request.Author)
+		return err
+	}
+	helper.NotifySuccess(
+		notification.EventSecurityWafPolicyPublished,
+		"WAF 策略已发布",
+		fmt.Sprintf("WAF 策略发布成功：policy=%s（变更申请 #%d）", candidate.Policy.Name, request.ID),
+		candidate.Policy.ID,
+		candidate.Policy.Name,
+		request.Author,
+	)
+
+	var revision model.WafPolicyRevision
+	if err := s.db().Select("id").Where("policy_id = ?", request.PolicyID).Order("id desc").First(&revision).Error; err == nil {
+		request.RevisionID = revision.ID
+	}
+	return nil
+}
+
+func (s *CaddyChangeRequestService) load(id uint) (*model.CaddyChangeRequest, error) {
+	var request model.CaddyChangeRequest
+	if err := s.db().First(&request, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("变更申请不存在")
+		}
+		return nil, fmt.Errorf("查询变更申请失败: %w", err)
+	}
+	return &request, nil
+}
+
+// record 写入状态流转记录，失败只记日志
+func (s *CaddyChangeRequestService) record(requestID uint, action string, actor *caddyChangeActor, content string) {
+	if err := s.db().Create(&model.CaddyChangeRequestComment{
+		RequestID:  requestID,
+		Action:     action,
+		Author:     actor.ID,
+		AuthorName: actor.Name,
+		Content:    strings.TrimSpace(content),
+	}).Error; err != nil {
+		s.logger.Errorf("记录变更申请流转失败: id=%d action=%s err=%v", requestID, action, err)
+	}
+}
+
+func (s *CaddyChangeRequestService) notify(request *model.CaddyChangeRequest, event, level, title, message string) {
+	notifyWafEventAsync(s.svcCtx.NotificationMgr, s.logger, event, level, title, message, map[string]interface{}{
+		"requestId":   request.ID,
+		"kind":        request.Kind,
+		"title":       request.Title,
+		"status":      request.Status,
+		"serverId":    request.ServerID,
+		"policyId":    request.PolicyID,
+		"author":      request.AuthorName,
+		"reviewer":    request.ReviewerName,
+		"scheduledAt": formatNullableTime(request.ScheduledAt),
+	})
+}
+
+// toCaddyChangeRequestItem 转换为列表项，名称由调用方按需补充
+func toCaddyChangeRequestItem(request model.CaddyChangeRequest) types.CaddyChangeRequestItem {
+	return types.CaddyChangeRequestItem{
+		ID:           request.ID,
+		Kind:         request.Kind,
+		ServerId:     request.ServerID,
+		PolicyId:     request.PolicyID,
+		Title:        request.Title,
+		Description:  request.Description,
+		Status:       request.Status,
+		Added:        request.Added,
+		Removed:      request.Removed,
+		Author:       request.Author,
+		AuthorName:   request.AuthorName,
+		Reviewer:     request.Reviewer,
+		ReviewerName: request.ReviewerName,
+		SubmittedAt:  formatNullableTime(request.SubmittedAt),
+		ReviewedAt:   formatNullableTime(request.ReviewedAt),
+		ScheduledAt:  formatNullableTime(request.ScheduledAt),
+		AppliedAt:    formatNullableTime(request.AppliedAt),
+		HistoryId:    request.HistoryID,
+		RevisionId:   request.RevisionID,
+		Message:      request.Message,
+		CreatedAt:    formatTime(request.CreatedAt),
+		UpdatedAt:    formatTime(request.UpdatedAt),
+	}
+}
+
+// describeCaddyChangeRequests 批量补充节点与策略名称
+func describeCaddyChangeRequests(db *gorm.DB, requests []model.CaddyChangeRequest) []types.CaddyChangeRequestItem {
+	serverIDs := make([]uint, 0, len(requests))
+	policyIDs := make([]uint, 0, len(requests))
+	for _, request := range requests {
+		serverIDs = append(serverIDs, request.ServerID)
+		if request.PolicyID > 0 {
+			policyIDs = append(policyIDs, request.PolicyID)
+		}
+	}
+	serverNames := map[uint]string{}
+	if len(serverIDs) > 0 {
+		var servers []model.CaddyServer
+		db.Select("id", "name").Where("id IN ?", serverIDs).Find(&servers)
+		for _, server := range servers {
+			serverNames[server.ID] = server.Name
+		}
+	}
+	policyNames := map[uint]string{}
+	if len(policyIDs) > 0 {
+		var policies []model.WafPolicy
+		db.Select("id", "name").Where("id IN ?", policyIDs).Find(&policies)
+		for _, policy := range policies {
+			policyNames[policy.ID] = policy.Name
+		}
+	}
+
+	items := make([]types.CaddyChangeRequestItem, 0, len(requests))
+	for _, request := range requests {
+		item := toCaddyChangeRequestItem(request)
+		item.ServerName = serverNames[request.ServerID]
+		item.PolicyName = policyNames[request.PolicyID]
+		items = append(items, item)
+	}
+	return items
+}
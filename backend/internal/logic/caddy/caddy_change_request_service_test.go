@@ -0,0 +1,242 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"logflux/internal/svc"
+	"logflux/model"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var caddyChangeRequestColumns = []string{"id", "kind", "server_id", "title", "config", "modules", "base_hash", "proposed_hash", "status", "author", "author_name", "scheduled_at"}
+
+func TestCaddyChangeRequestApproveRules(t *testing.T) {
+	db, mock, cleanup := newWafMirrorMockDB(t)
+	defer cleanup()
+	service := NewCaddyChangeRequestService(context.Background(), &svc.ServiceContext{DB: db})
+
+	cases := []struct {
+		name   string
+		actor  *caddyChangeActor
+		status string
+		want   string
+	}{
+		{"self approval", &caddyChangeActor{ID: "1", Name: "alice", Roles: []string{"admin"}}, caddyChangeStatusPending, "不能审批自己提交的变更申请"},
+		{"missing role", &caddyChangeActor{ID: "2", Name: "bob", Roles: []string{"analyst"}}, caddyChangeStatusPending, "需要审批人角色"},
+		{"not pending", &caddyChangeActor{ID: "2", Name: "bob", Roles: []string{"approver"}}, caddyChangeStatusDraft, "仅待审批"},
+	}
+	for _, tc := range cases {
+		mock.ExpectQuery(`SELECT \* FROM "caddy_change_requests"`).
+			WillReturnRows(sqlmock.NewRows(caddyChangeRequestColumns).
+				AddRow(7, caddyChangeKindConfig, 1, "扩容", "a {\n}\n", "{}", "base", "proposed", tc.status, "1", "alice", nil))
+		if _, err := service.Approve(tc.actor, 7, "", ""); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%s: expected %q, got %v", tc.name, tc.want, err)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet sql expectations: %v", err)
+	}
+}
+
+func TestCaddyChangeRequestApproveSchedulesFutureApply(t *testing.T) {
+	db, mock, cleanup := newWafMirrorMockDB(t)
+	defer cleanup()
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	service := NewCaddyChangeRequestService(context.Background(), &svc.ServiceContext{DB: db})
+	service.now = func() time.Time { return now }
+
+	scheduledAt := now.Add(2 * time.Hour)
+	mock.ExpectQuery(`SELECT \* FROM "caddy_change_requests"`).
+		WillReturnRows(sqlmock.NewRows(caddyChangeRequestColumns).
+			AddRow(7, caddyChangeKindConfig, 1, "扩容", "a {\n}\n", "{}", "base", "proposed", caddyChangeStatusPending, "1", "alice", scheduledAt))
+	mock.ExpectExec(`UPDATE "caddy_change_requests" SET .*"status"=\$\d+.* WHERE id = \$\d+ AND status = \$\d+`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO "caddy_change_request_comments"`).
+		WithArgs(sqlmock.AnyArg(), uint(7), caddyChangeActionApprove, "2", "bob", "窗口期内执行").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	approver := &caddyChangeActor{ID: "2", Name: "bob", Roles: []string{"approver"}}
+	request, err := service.Approve(approver, 7, "窗口期内执行", "")
+	if err != nil {
+		t.Fatalf("approve failed: %v", err)
+	}
+	if request.Status != caddyChangeStatusScheduled || request.ReviewerName != "bob" {
+		t.Fatalf("expected scheduled request reviewed by bob, got %+v", request)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet sql expectations: %v", err)
+	}
+}
+
+func TestCaddyChangeRequestApplyFailsWhenBaseChanged(t *testing.T) {
+	db, mock, cleanup := newWafMirrorMockDB(t)
+	defer cleanup()
+	service := NewCaddyChangeRequestService(context.Background(), &svc.ServiceContext{DB: db})
+
+	mock.ExpectQuery(`SELECT \* FROM "caddy_change_requests"`).
+		WillReturnRows(sqlmock.NewRows(caddyChangeRequestColumns).
+			AddRow(7, caddyChangeKindConfig, 1, "扩容", "a {\n}\n", "{}", hashConfig("old {\n}\n"), "proposed", caddyChangeStatusScheduled, "1", "alice", nil))
+	mock.ExpectExec(`UPDATE "caddy_change_requests" SET "status"=\$1,"updated_at"=\$2 WHERE id = \$3 AND status IN \(\$4,\$5\)`).
+		WithArgs(caddyChangeStatusApplying, sqlmock.AnyArg(), uint(7), caddyChangeStatusScheduled, caddyChangeStatusFailed).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT \* FROM "caddy_servers"`).
+		WillReturnRows(caddyServerRows(time.Now(), "http://127.0.0.1:1", "edited-by-someone-else {\n}\n"))
+	mock.ExpectExec(`UPDATE "caddy_change_requests" SET "message"=\$1,"status"=\$2`).
+		WithArgs(sqlmock.AnyArg(), caddyChangeStatusFailed, sqlmock.AnyArg(), uint(7)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO "caddy_change_request_comments"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	_, err := service.Apply(&caddyChangeActor{ID: "1", Name: "alice", Roles: []string{"admin"}}, 7)
+	if err == nil || !strings.Contains(err.Error(), "配置在申请提交后已被修改") {
+		t.Fatalf("expected stale base failure, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet sql expectations: %v", err)
+	}
+}
+
+func TestCaddyChangeRequestApproveAppliesInBackground(t *testing.T) {
+	db, mock, cleanup := newWafMirrorMockDB(t)
+	defer cleanup()
+	service := NewCaddyChangeRequestService(context.Background(), &svc.ServiceContext{DB: db})
+	var jobs []string
+	service.background = func(name string, job func()) { jobs = append(jobs, name) }
+
+	mock.ExpectQuery(`SELECT \* FROM "caddy_change_requests"`).
+		WillReturnRows(sqlmock.NewRows(caddyChangeRequestColumns).
+			AddRow(7, caddyChangeKindConfig, 1, "扩容", "a {\n}\n", "{}", "base", "proposed", caddyChangeStatusPending, "1", "alice", nil))
+	mock.ExpectExec(`UPDATE "caddy_change_requests" SET .*"status"=\$\d+.* WHERE id = \$\d+ AND status = \$\d+`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO "caddy_change_request_comments"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	approver := &caddyChangeActor{ID: "2", Name: "bob", Roles: []string{"approver"}}
+	request, err := service.Approve(approver, 7, "", "")
+	if err != nil {
+		t.Fatalf("approve failed: %v", err)
+	}
+	if request.Status != caddyChangeStatusApplying || len(jobs) != 1 {
+		t.Fatalf("expected approval to return applying and queue one background job, got status=%s jobs=%v", request.Status, jobs)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet sql expectations: %v", err)
+	}
+}
+
+func TestDirectCaddyChangesRequireApprovalExceptExecutor(t *testing.T) {
+	db, mock, cleanup := newWafMirrorMockDB(t)
+	defer cleanup()
+	svcCtx := &svc.ServiceContext{DB: db}
+	svcCtx.Config.Caddy.RequireChangeApproval = true
+	server := &model.CaddyServer{ID: 1, Url: "http://127.0.0.1:1"}
+
+	applyService := newCaddyConfigApplyService(svcCtx, nil)
+	if err := applyService.apply(server, "a {\n}\n", "{}", "update"); err == nil || !strings.Contains(err.Error(), "已启用变更审批") {
+		t.Fatalf("expected direct apply to require approval, got %v", err)
+	}
+	mock.ExpectQuery(`SELECT \* FROM "caddy_apply_verify_settings"`).WillReturnError(fmt.Errorf("数据库不可用"))
+	if err := applyService.approvedChange().apply(server, "a {\n}\n", "{}", caddyChangeRequestHistoryAction); err == nil || strings.Contains(err.Error(), "已启用变更审批") {
+		t.Fatalf("expected change request apply to pass the approval check, got %v", err)
+	}
+
+	publishService := NewPolicyPublishService(context.Background(), svcCtx)
+	candidate := &PolicyPublishCandidate{Server: server, CandidateConfig: "a {\n}\n"}
+	if err := publishService.LoadCandidate(candidate, "rollback"); err == nil || !strings.Contains(err.Error(), "已启用变更审批") {
+		t.Fatalf("expected direct policy load to require approval, got %v", err)
+	}
+}
+
+// newApprovalCaddyAdmin 模拟 Caddy 管理接口，记录 /load 的请求体
+func newApprovalCaddyAdmin(t *testing.T) (*httptest.Server, *[]string) {
+	t.Helper()
+	var loads []string
+	admin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.URL.Path == "/load" {
+			loads = append(loads, string(body))
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(admin.Close)
+	return admin, &loads
+}
+
+func TestWafBanSyncRunsWithChangeApproval(t *testing.T) {
+	admin, loads := newApprovalCaddyAdmin(t)
+	db, mock, cleanup := newWafMirrorMockDB(t)
+	defer cleanup()
+	now := time.Now()
+
+	mock.ExpectQuery(`SELECT \* FROM "waf_bans" WHERE status = \$1 AND expires_at > \$2`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "ip", "host", "status", "expires_at"}).
+			AddRow(1, "203.0.113.7", "", "active", now.Add(time.Hour)))
+	mock.ExpectQuery(`SELECT \* FROM "caddy_servers" WHERE type = \$1`).
+		WillReturnRows(caddyServerRows(now, admin.URL, "app.example.com {\n\treverse_proxy app:8080\n}\n"))
+	mock.ExpectQuery(`SELECT \* FROM "caddy_apply_verify_settings"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "caddy_servers"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO "caddy_config_history"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+
+	svcCtx := &svc.ServiceContext{DB: db}
+	svcCtx.Config.Caddy.RequireChangeApproval = true
+	if err := NewWafBanService(context.Background(), svcCtx).Sync(); err != nil {
+		t.Fatalf("ban sync should not require approval, got %v", err)
+	}
+	if len(*loads) != 1 || !strings.Contains((*loads)[0], "203.0.113.7") {
+		t.Fatalf("expected ban snippet to be loaded, got %q", *loads)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet sql expectations: %v", err)
+	}
+}
+
+func TestWafRolloutAbortRunsWithChangeApproval(t *testing.T) {
+	admin, loads := newApprovalCaddyAdmin(t)
+	db, mock, cleanup := newWafMirrorMockDB(t)
+	defer cleanup()
+	now := time.Now()
+
+	mock.ExpectQuery(`SELECT \* FROM "waf_policy_rollouts"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "policy_id", "baseline_revision_id", "candidate_revision_id", "status"}).
+			AddRow(5, 1, 10, 11, wafRolloutStatusCanary))
+	mock.ExpectQuery(`SELECT \* FROM "waf_policies"`).WillReturnRows(policyRows(now))
+	mock.ExpectQuery(`SELECT \* FROM "waf_policy_revisions"`).WillReturnRows(policyRevisionRows(now))
+	mock.ExpectQuery(`SELECT .* FROM "waf_ip_lists"`).WillReturnRows(policyIPListRows())
+	mock.ExpectQuery(`SELECT .* FROM "waf_rate_limit_zones"`).WillReturnRows(policyRateLimitZoneRows())
+	mock.ExpectQuery(`SELECT \* FROM "caddy_servers" WHERE type = \$1`).
+		WillReturnRows(caddyServerRows(now, admin.URL, testCaddyConfigWithCoraza))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO "caddy_config_history"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec(`UPDATE "caddy_servers"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO "caddy_config_history"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	mock.ExpectExec(`UPDATE "waf_policy_revisions" SET "status"=\$1`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT \* FROM "waf_policy_revisions" WHERE policy_id = \$1 ORDER BY version desc`).WillReturnRows(policyRevisionRows(now))
+	mock.ExpectQuery(`SELECT .* FROM "waf_custom_rules"`).WillReturnRows(policyCustomRuleRows())
+	mock.ExpectQuery(`INSERT INTO "waf_policy_revisions"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(12))
+	mock.ExpectExec(`UPDATE "waf_policy_rollouts" SET`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	svcCtx := &svc.ServiceContext{DB: db}
+	svcCtx.Config.Caddy.RequireChangeApproval = true
+	rollout, err := NewWafRolloutService(context.Background(), svcCtx).Abort(5, "system", "误报率超过阈值")
+	if err != nil {
+		t.Fatalf("rollout abort should not require approval, got %v", err)
+	}
+	if rollout.Status != wafRolloutStatusAborted || len(*loads) != 1 {
+		t.Fatalf("expected aborted rollout with one load, got status=%s loads=%d", rollout.Status, len(*loads))
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet sql expectations: %v", err)
+	}
+}
@@ -2,10 +2,13 @@ package caddy
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
+	"logflux/internal/notification"
 	"logflux/internal/svc"
 	"logflux/internal/utils/safego"
 	"logflux/model"
@@ -15,14 +18,34 @@ import (
 )
 
 type caddyConfigApplyService struct {
-	svcCtx *svc.ServiceContext
-	logger logx.Logger
+	svcCtx   *svc.ServiceContext
+	logger   logx.Logger
+	note     caddyConfigHistoryNote
+	verifier *caddyApplyVerifier
+	// background 执行加载后校验，为空时使用 safego 协程
+	background func(name string, job func())
+	// approved 仅由变更申请执行器设置，跳过变更审批检查
+	approved bool
 }
 
 func newCaddyConfigApplyService(svcCtx *svc.ServiceContext, logger logx.Logger) *caddyConfigApplyService {
 	return &caddyConfigApplyService{svcCtx: svcCtx, logger: logger}
 }
 
+// withNote 返回携带操作人与变更说明的副本，写入配置历史时使用
+func (s *caddyConfigApplyService) withNote(operator, message string) *caddyConfigApplyService {
+	clone := *s
+	clone.note = caddyConfigHistoryNote{Operator: operator, Message: message}
+	return &clone
+}
+
+// approvedChange 返回已通过变更审批的副本，仅供变更申请执行器使用
+func (s *caddyConfigApplyService) approvedChange() *caddyConfigApplyService {
+	clone := *s
+	clone.approved = true
+	return &clone
+}
+
 func (s *caddyConfigApplyService) loadCurrent(server *model.CaddyServer) (string, string, error) {
 	if server == nil {
 		return "", emptyModulesJSON, fmt.Errorf("Caddy 服务器不存在")
@@ -45,12 +68,23 @@ func (s *caddyConfigApplyService) loadCurrent(server *model.CaddyServer) (string
 	return "", normalizeCaddyModulesJSON(server.Modules), fmt.Errorf("Caddy 配置为空，请先保存 Caddy 配置")
 }
 
+// apply 适配并加载配置后立即落库；启用了加载后校验时在后台观察，结果写回本次的配置历史
 func (s *caddyConfigApplyService) apply(server *model.CaddyServer, config, modules, action string) error {
 	if server == nil {
 		return fmt.Errorf("Caddy 服务器不存在")
 	}
+	if !s.approved {
+		if err := ensureDirectCaddyChangeAllowed(s.svcCtx); err != nil {
+			return err
+		}
+	}
 
 	normalizedModules := normalizeCaddyModulesJSON(modules)
+	plan, err := loadCaddyApplyVerifyPlan(s.svcCtx.DB, server.ID)
+	if err != nil {
+		return err
+	}
+	previousConfig := server.Config
 	if err := adaptCaddyfile(server, config); err != nil {
 		if s != nil && s.logger != nil {
 			s.logger.Errorf("Caddy 配置适配失败: %v", err)
@@ -63,21 +97,19 @@ func (s *caddyConfigApplyService) apply(server *model.CaddyServer, config, modul
 		}
 		return err
 	}
+	loadedAt := time.Now()
 
+	history := newCaddyConfigHistory(server.ID, action, config, normalizedModules, s.note)
+	if plan != nil {
+		history.VerifyStatus = caddyVerifyStatusPending
+		history.VerifyMessage = "加载后校验进行中"
+	}
 	if err := s.svcCtx.DB.Transaction(func(tx *gorm.DB) error {
 		server.Config = config
 		server.Modules = normalizedModules
 		if err := tx.Save(server).Error; err != nil {
 			return fmt.Errorf("保存 Caddy 服务器配置失败: %w", err)
 		}
-
-		history := &model.CaddyConfigHistory{
-			ServerID: server.ID,
-			Action:   strings.TrimSpace(action),
-			Hash:     hashConfig(config),
-			Config:   config,
-			Modules:  normalizedModules,
-		}
 		if err := tx.Create(history).Error; err != nil {
 			return fmt.Errorf("创建 Caddy 配置历史失败: %w", err)
 		}
@@ -86,12 +118,126 @@ func (s *caddyConfigApplyService) apply(server *model.CaddyServer, config, modul
 		return err
 	}
 
+	if plan != nil {
+		snapshot := *server
+		s.runBackground("Caddy 配置加载后校验", func() {
+			s.verifyLoaded(&snapshot, plan, history.ID, previousConfig, action, loadedAt)
+		})
+	}
 	safego.New(context.Background(), "应用 Caddy 配置后同步日志源").Go(func() {
 		syncCaddyLogSources(s.svcCtx, server, s.logger)
 	})
 	return nil
 }
 
+func (s *caddyConfigApplyService) runBackground(name string, job func()) {
+	if s.background != nil {
+		s.background(name, job)
+		return
+	}
+	safego.New(context.Background(), name).Go(job)
+}
+
+// verifyLoaded 在后台执行加载后的健康校验，结果写回配置历史；
+// 失败时重新加载上一个历史版本并发送 caddy.config_update_failed 通知
+func (s *caddyConfigApplyService) verifyLoaded(server *model.CaddyServer, plan *caddyApplyVerifyPlan, historyID uint, previousConfig, action string, loadedAt time.Time) {
+	verifier := s.verifier
+	if verifier == nil {
+		verifier = newCaddyApplyVerifier(s.svcCtx.DB)
+	}
+	report := verifier.verify(plan, server.Config, loadedAt, true)
+	if report.Passed {
+		s.finishVerify(historyID, caddyVerifyStatusPassed, report.Message)
+		return
+	}
+
+	revertMessage := "已自动回滚到上一个历史版本"
+	if err := s.revert(server, historyID, previousConfig, report.Message); err != nil {
+		revertMessage = "自动回滚失败: " + err.Error()
+	}
+	if s.logger != nil {
+		s.logger.Errorf("Caddy 配置健康校验未通过: server=%s %s，%s", server.Name, report.Message, revertMessage)
+	}
+	s.finishVerify(historyID, caddyVerifyStatusFailed, report.Message+"；"+revertMessage)
+	notifyWafEventAsync(s.svcCtx.NotificationMgr, s.logger,
+		notification.EventCaddyConfigUpdateFailed,
+		notification.LevelError,
+		"Caddy 配置健康校验未通过",
+		fmt.Sprintf("节点 %s 加载新配置后校验失败：%s；%s", server.Name, report.Message, revertMessage),
+		map[string]interface{}{
+			"serverId":   server.ID,
+			"serverName": server.Name,
+			"historyId":  historyID,
+			"action":     strings.TrimSpace(action),
+			"probes":     report.Probes,
+			"requests":   report.Requests,
+			"errors":     report.Errors,
+			"errorRate":  report.ErrorRate,
+			"reverted":   revertMessage,
+		})
+}
+
+func (s *caddyConfigApplyService) finishVerify(historyID uint, status, message string) {
+	now := time.Now()
+	if err := s.svcCtx.DB.Model(&model.CaddyConfigHistory{}).Where("id = ?", historyID).Updates(map[string]interface{}{
+		"verify_status":  status,
+		"verify_message": message,
+		"verified_at":    &now,
+	}).Error; err != nil && s.logger != nil {
+		s.logger.Errorf("写入 Caddy 配置校验结果失败: history=%d err=%v", historyID, err)
+	}
+}
+
+// revert 重新加载 historyID 之前最近一个未校验失败的历史版本并恢复保存的配置，
+// 没有历史或该版本是运行配置快照时使用加载前保存的配置；观察期间已有更新的配置加载时不回滚。
+// 回滚结果记为一条 auto_revert 历史，使最新历史与运行中的配置保持一致
+func (s *caddyConfigApplyService) revert(server *model.CaddyServer, historyID uint, previousConfig, summary string) error {
+	db := s.svcCtx.DB
+	var latest model.CaddyConfigHistory
+	if err := db.Where("server_id = ?", server.ID).Order("id desc").First(&latest).Error; err != nil {
+		return fmt.Errorf("查询最新历史版本失败: %w", err)
+	}
+	if latest.ID != historyID {
+		return fmt.Errorf("观察期间已加载更新的配置，未自动回滚")
+	}
+
+	target := previousConfig
+	targetModules := server.Modules
+	var previous model.CaddyConfigHistory
+	err := db.Where("server_id = ? AND id < ? AND COALESCE(verify_status, '') <> ?", server.ID, historyID, caddyVerifyStatusFailed).
+		Order("id desc").First(&previous).Error
+	switch {
+	case err == nil && strings.TrimSpace(previous.Config) != "" && !isCaddyJSONSnapshot(previous.Config):
+		target = previous.Config
+		targetModules = previous.Modules
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		return fmt.Errorf("查询上一个历史版本失败: %w", err)
+	}
+	if strings.TrimSpace(target) == "" {
+		return fmt.Errorf("没有可回滚的历史版本")
+	}
+	if err := loadCaddyfile(server, target); err != nil {
+		return err
+	}
+	modules := normalizeCaddyModulesJSON(targetModules)
+	history := newCaddyConfigHistory(server.ID, caddyAutoRevertHistoryAction, target, modules, caddyConfigHistoryNote{
+		Operator: "system",
+		Message:  fmt.Sprintf("历史 #%d 加载后校验未通过，自动回滚：%s", historyID, summary),
+	})
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.CaddyServer{}).Where("id = ?", server.ID).Updates(map[string]interface{}{
+			"config":  target,
+			"modules": modules,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Create(history).Error
+	}); err != nil {
+		return fmt.Errorf("已重新加载上一版，但保存配置失败: %w", err)
+	}
+	return nil
+}
+
 func findPreferredCaddyServer(db *gorm.DB, serverID uint) (*model.CaddyServer, error) {
 	if db == nil {
 		return nil, fmt.Errorf("数据库为空")
@@ -0,0 +1,177 @@
+package caddy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"logflux/internal/types"
+	"logflux/internal/utils/textdiff"
+	"logflux/model"
+
+	"gorm.io/gorm"
+)
+
+const (
+	caddyHistorySourceUI            = "ui"
+	caddyHistorySourceWafPublish    = "waf_publish"
+	caddyHistorySourceRollback      = "rollback"
+	caddyHistorySourceDriftAdopt    = "drift_adopt"
+	caddyHistorySourceDriftReapply  = "drift_reapply"
+	caddyHistorySourceChangeRequest = "change_request"
+	caddyHistorySourceSystem        = "system"
+
+	// caddyAutoRevertHistoryAction 加载后校验失败时自动回滚写入的历史动作
+	caddyAutoRevertHistoryAction = "auto_revert"
+	// caddyDriftSnapshotHistoryAction 采用漂移时找不到一致的 Caddyfile，保存运行配置 JSON 快照的历史动作
+	caddyDriftSnapshotHistoryAction = "drift_snapshot"
+
+	caddyConfigDiffFormatCaddyfile = "caddyfile"
+	caddyConfigDiffFormatJSON      = "json"
+	caddyConfigDiffContextLines    = 3
+)
+
+// caddyConfigHistoryNote 写入配置历史时附带的操作人与变更说明
+type caddyConfigHistoryNote struct {
+	Operator string
+	Message  string
+}
+
+func newCaddyConfigHistory(serverID uint, action, config, modules string, note caddyConfigHistoryNote) *model.CaddyConfigHistory {
+	action = strings.TrimSpace(action)
+	operator := strings.TrimSpace(note.Operator)
+	if operator == "" {
+		operator = "system"
+	}
+	return &model.CaddyConfigHistory{
+		ServerID: serverID,
+		Action:   action,
+		Hash:     hashConfig(config),
+		Config:   config,
+		Modules:  normalizeCaddyModulesJSON(modules),
+		Source:   caddyConfigHistorySource(action),
+		Operator: operator,
+		Message:  strings.TrimSpace(note.Message),
+	}
+}
+
+// caddyConfigHistorySource 由历史动作归类变更来源
+func caddyConfigHistorySource(action string) string {
+	switch action = strings.ToLower(strings.TrimSpace(action)); {
+	case action == "update" || action == caddySiteEditAction:
+		return caddyHistorySourceUI
+	case action == "rollback" || action == "policy_rollback" || action == caddyAutoRevertHistoryAction:
+		return caddyHistorySourceRollback
+	case action == "drift_adopt" || action == caddyDriftSnapshotHistoryAction:
+		return caddyHistorySourceDriftAdopt
+	case action == "drift_reapply":
+		return caddyHistorySourceDriftReapply
+	case action == caddyChangeRequestHistoryAction:
+		return caddyHistorySourceChangeRequest
+	case strings.HasPrefix(action, "policy_"), strings.HasPrefix(action, "simple_waf_"), strings.HasPrefix(action, "waf_"):
+		return caddyHistorySourceWafPublish
+	default:
+		return caddyHistorySourceSystem
+	}
+}
+
+func toCaddyConfigHistoryItem(history model.CaddyConfigHistory) types.CaddyConfigHistoryItem {
+	return types.CaddyConfigHistoryItem{
+		ID:        history.ID,
+		ServerId:  history.ServerID,
+		Action:    history.Action,
+		Hash:      history.Hash,
+		Source:    history.Source,
+		Operator:  history.Operator,
+		Message:   history.Message,
+		KnownGood: history.KnownGood,
+		TaggedBy:  history.TaggedBy,
+		TaggedAt:  formatNullableTime(history.TaggedAt),
+		CreatedAt: history.CreatedAt.Format("2006-01-02 15:04:05"),
+
+		VerifyStatus:  history.VerifyStatus,
+		VerifyMessage: history.VerifyMessage,
+		VerifiedAt:    formatNullableTime(history.VerifiedAt),
+	}
+}
+
+// findKnownGoodCaddyHistory 返回最近一次标记为已知良好、且与当前配置不同的版本
+func findKnownGoodCaddyHistory(db *gorm.DB, serverID uint, excludeHash string) (*model.CaddyConfigHistory, error) {
+	var history model.CaddyConfigHistory
+	query := db.Where("server_id = ? AND known_good = ?", serverID, true)
+	if excludeHash != "" {
+		query = query.Where("hash <> ?", excludeHash)
+	}
+	err := query.Order("id desc").First(&history).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("没有可回滚的已知良好版本，请先在配置历史中标记")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询已知良好版本失败: %w", err)
+	}
+	return &history, nil
+}
+
+// caddyConfigDiffSide 参与比较的一侧：历史版本或当前保存的配置
+type caddyConfigDiffSide struct {
+	ID     uint
+	Label  string
+	Config string
+}
+
+// buildCaddyConfigDiff 生成两份配置的 unified diff；json 格式先经 /adapt 转换再按格式化后的 JSON 比较
+func buildCaddyConfigDiff(server *model.CaddyServer, format string, from, to caddyConfigDiffSide) (*types.CaddyConfigDiffResp, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		format = caddyConfigDiffFormatCaddyfile
+	}
+
+	fromText, toText := from.Config, to.Config
+	switch format {
+	case caddyConfigDiffFormatCaddyfile:
+	case caddyConfigDiffFormatJSON:
+		var err error
+		if fromText, err = adaptCaddyfileIndentedJSON(server, from.Config); err != nil {
+			return nil, fmt.Errorf("%s 适配失败: %w", from.Label, err)
+		}
+		if toText, err = adaptCaddyfileIndentedJSON(server, to.Config); err != nil {
+			return nil, fmt.Errorf("%s 适配失败: %w", to.Label, err)
+		}
+	default:
+		return nil, fmt.Errorf("不支持的对比格式: %s", format)
+	}
+
+	result := textdiff.Unified(from.Label, to.Label, fromText, toText, caddyConfigDiffContextLines)
+	return &types.CaddyConfigDiffResp{
+		Format:    format,
+		FromId:    from.ID,
+		ToId:      to.ID,
+		FromLabel: from.Label,
+		ToLabel:   to.Label,
+		FromHash:  hashConfig(from.Config),
+		ToHash:    hashConfig(to.Config),
+		Diff:      result.Unified,
+		Added:     result.Added,
+		Removed:   result.Removed,
+		Identical: result.Identical(),
+	}, nil
+}
+
+func adaptCaddyfileIndentedJSON(server *model.CaddyServer, config string) (string, error) {
+	var value interface{}
+	var err error
+	if isCaddyJSONSnapshot(config) {
+		value, err = decodeCaddyJSON([]byte(config))
+	} else {
+		value, err = adaptCaddyfileJSON(server, config)
+	}
+	if err != nil {
+		return "", err
+	}
+	raw, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("格式化 JSON 失败: %w", err)
+	}
+	return string(raw), nil
+}
@@ -0,0 +1,91 @@
+package caddy
+
+import (
+	"strings"
+	"testing"
+
+	"logflux/model"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestCaddyConfigHistorySource(t *testing.T) {
+	cases := map[string]string{
+		"update":                  caddyHistorySourceUI,
+		"site_edit":               caddyHistorySourceUI,
+		"rollback":                caddyHistorySourceRollback,
+		"policy_rollback":         caddyHistorySourceRollback,
+		"policy_publish":          caddyHistorySourceWafPublish,
+		"policy_last_good":        caddyHistorySourceWafPublish,
+		"simple_waf_apply":        caddyHistorySourceWafPublish,
+		"waf_integration_enable":  caddyHistorySourceWafPublish,
+		"waf_ban_sync":            caddyHistorySourceWafPublish,
+		"drift_adopt":             caddyHistorySourceDriftAdopt,
+		"drift_reapply":           caddyHistorySourceDriftReapply,
+		"change_request":          caddyHistorySourceChangeRequest,
+		"":                        caddyHistorySourceSystem,
+		"something_else_entirely": caddyHistorySourceSystem,
+	}
+	for action, want := range cases {
+		if got := caddyConfigHistorySource(action); got != want {
+			t.Fatalf("source(%q) = %q, want %q", action, got, want)
+		}
+	}
+
+	history := newCaddyConfigHistory(3, " update ", "example.com {\n}\n", "", caddyConfigHistoryNote{Message: "  调整上游  "})
+	if history.ServerID != 3 || history.Action != "update" || history.Source != caddyHistorySourceUI ||
+		history.Operator != "system" || history.Message != "调整上游" ||
+		history.Modules != emptyModulesJSON || history.Hash != hashConfig("example.com {\n}\n") {
+		t.Fatalf("unexpected history: %+v", history)
+	}
+}
+
+func TestBuildCaddyConfigDiff(t *testing.T) {
+	server := &model.CaddyServer{}
+	from := caddyConfigDiffSide{ID: 1, Label: "#1", Config: "example.com {\n\treverse_proxy app:8080\n}\n"}
+	to := caddyConfigDiffSide{Label: "当前配置", Config: "example.com {\n\tencode gzip\n\treverse_proxy app:9090\n}\n"}
+
+	resp, err := buildCaddyConfigDiff(server, "", from, to)
+	if err != nil {
+		t.Fatalf("build diff failed: %v", err)
+	}
+	if resp.Format != caddyConfigDiffFormatCaddyfile || resp.FromId != 1 || resp.ToId != 0 || resp.Identical {
+		t.Fatalf("unexpected diff meta: %+v", resp)
+	}
+	if resp.Added != 2 || resp.Removed != 1 {
+		t.Fatalf("unexpected counts: +%d -%d", resp.Added, resp.Removed)
+	}
+	if !strings.HasPrefix(resp.Diff, "--- #1\n+++ 当前配置\n@@ -1,3 +1,4 @@\n") ||
+		!strings.Contains(resp.Diff, "-\treverse_proxy app:8080\n+\tencode gzip\n+\treverse_proxy app:9090\n") {
+		t.Fatalf("unexpected diff:\n%s", resp.Diff)
+	}
+
+	same, err := buildCaddyConfigDiff(server, "caddyfile", from, from)
+	if err != nil || !same.Identical || same.Diff != "" {
+		t.Fatalf("identical configs should produce empty diff: %+v err=%v", same, err)
+	}
+	if _, err := buildCaddyConfigDiff(server, "yaml", from, to); err == nil {
+		t.Fatalf("expected unsupported format error")
+	}
+}
+
+func TestFindKnownGoodCaddyHistory(t *testing.T) {
+	db, mock, cleanup := newWafMirrorMockDB(t)
+	defer cleanup()
+
+	mock.ExpectQuery(`SELECT \* FROM "caddy_config_history" WHERE \(server_id = \$1 AND known_good = \$2\) AND hash <> \$3 ORDER BY id desc`).
+		WithArgs(uint(2), true, "current-hash", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "server_id", "hash", "known_good"}).AddRow(7, 2, "good-hash", true))
+	history, err := findKnownGoodCaddyHistory(db, 2, "current-hash")
+	if err != nil || history.ID != 7 || !history.KnownGood {
+		t.Fatalf("unexpected known-good history: %+v err=%v", history, err)
+	}
+
+	mock.ExpectQuery(`SELECT \* FROM "caddy_config_history"`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	if _, err := findKnownGoodCaddyHistory(db, 2, ""); err == nil || !strings.Contains(err.Error(), "没有可回滚的已知良好版本") {
+		t.Fatalf("expected missing known-good error, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet sql expectations: %v", err)
+	}
+}
@@ -0,0 +1,472 @@
+package caddy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"logflux/internal/notification"
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+const (
+	caddyDriftStatusOpen      = "open"
+	caddyDriftStatusAdopted   = "adopted"
+	caddyDriftStatusReapplied = "reapplied"
+	caddyDriftStatusResolved  = "resolved"
+
+	caddyDriftMaxDiffEntries = 200
+)
+
+// caddyJSONDiffEntry 单处 JSON 差异，Path 形如 apps.http.servers.srv0.routes[0]
+type caddyJSONDiffEntry struct {
+	Path   string          `json:"path"`
+	Op     string          `json:"op"` // added | removed | changed
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// CaddyDriftService 比对运行中的 Caddy 配置（/config/）与保存的 Caddyfile 适配结果（/adapt），
+// 发现偏差时记录并通知，支持采用运行配置（一致的 Caddyfile 或运行配置快照）或重新下发保存的配置
+type CaddyDriftService struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	logger logx.Logger
+}
+
+func NewCaddyDriftService(ctx context.Context, svcCtx *svc.ServiceContext) *CaddyDriftService {
+	return &CaddyDriftService{ctx: ctx, svcCtx: svcCtx, logger: logx.WithContext(ctx)}
+}
+
+// CheckAll 检测全部已保存配置的节点，单个节点失败不影响其他节点
+func (s *CaddyDriftService) CheckAll() error {
+	var servers []model.CaddyServer
+	if err := s.svcCtx.DB.WithContext(s.ctx).Where("COALESCE(config, '') <> ''").Order("id asc").Find(&servers).Error; err != nil {
+		return fmt.Errorf("查询 Caddy 服务器失败: %w", err)
+	}
+	for i := range servers {
+		if _, err := s.Check(&servers[i]); err != nil {
+			s.logger.Errorf("检测 Caddy 配置漂移失败: server=%s err=%v", servers[i].Name, err)
+		}
+	}
+	return nil
+}
+
+// Check 检测单个节点，返回当前 open 的偏差记录（无偏差时为 nil）；
+// 同一偏差已保存为运行配置快照时返回该记录，不再重复记录与通知
+func (s *CaddyDriftService) Check(server *model.CaddyServer) (*model.CaddyConfigDrift, error) {
+	if strings.TrimSpace(server.Config) == "" {
+		return nil, fmt.Errorf("Caddy 配置为空，请先保存 Caddy 配置")
+	}
+	expected, err := adaptCaddyfileJSON(server, server.Config)
+	if err != nil {
+		return nil, err
+	}
+	running, err := fetchRunningCaddyJSON(server)
+	if err != nil {
+		return nil, err
+	}
+
+	db := s.svcCtx.DB.WithContext(s.ctx)
+	now := time.Now()
+	storedHash := hashConfig(server.Config)
+	runningHash := hashCaddyJSON(running)
+	entries := diffCaddyJSON(expected, running)
+
+	open, err := findOpenCaddyDrift(db, server.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		if open != nil {
+			open.Status = caddyDriftStatusResolved
+			open.ResolvedAt = &now
+			open.ResolvedBy = "system"
+			open.Message = "运行配置已与保存配置一致"
+			if err := db.Save(open).Error; err != nil {
+				return nil, fmt.Errorf("更新漂移记录失败: %w", err)
+			}
+		}
+		return nil, nil
+	}
+
+	if open != nil && open.StoredHash == storedHash && open.RunningHash == runningHash {
+		if err := db.Model(open).Update("last_seen_at", now).Error; err != nil {
+			return nil, fmt.Errorf("更新漂移记录失败: %w", err)
+		}
+		return open, nil
+	}
+	if open == nil {
+		acknowledged, err := findAcknowledgedCaddyDrift(db, server.ID, storedHash, runningHash)
+		if err != nil {
+			return nil, err
+		}
+		if acknowledged != nil {
+			if err := db.Model(acknowledged).Update("last_seen_at", now).Error; err != nil {
+				return nil, fmt.Errorf("更新漂移记录失败: %w", err)
+			}
+			return acknowledged, nil
+		}
+	}
+
+	diffJSON, _ := json.Marshal(entries)
+	runningJSON, _ := json.Marshal(running)
+	drift := open
+	if drift == nil {
+		drift = &model.CaddyConfigDrift{ServerID: server.ID, Status: caddyDriftStatusOpen}
+	}
+	drift.StoredHash = storedHash
+	drift.RunningHash = runningHash
+	drift.Summary = truncateWafFeedbackText(summarizeCaddyJSONDiff(entries), 255)
+	drift.Diff = string(diffJSON)
+	drift.RunningJSON = string(runningJSON)
+	drift.LastSeenAt = now
+	_, matchErr := s.matchingLocalCaddyfile(server, runningHash)
+	drift.Adoptable = matchErr == nil
+	if err := db.Save(drift).Error; err != nil {
+		return nil, fmt.Errorf("保存漂移记录失败: %w", err)
+	}
+	s.notifyDrift(server, drift)
+	return drift, nil
+}
+
+// Adopt 采用运行配置：检测时节点 Caddyfile 与运行配置一致的，把该 Caddyfile 写回为保存配置；
+// 否则把运行配置 JSON 保存为历史快照，可在配置历史中重新下发，保存的 Caddyfile 不变
+func (s *CaddyDriftService) Adopt(driftID uint, operator string) (*model.CaddyConfigDrift, error) {
+	if err := ensureDirectCaddyChangeAllowed(s.svcCtx); err != nil {
+		return nil, err
+	}
+	drift, server, err := s.loadOpenDrift(driftID)
+	if err != nil {
+		return nil, err
+	}
+	running, err := fetchRunningCaddyJSON(server)
+	if err != nil {
+		return nil, err
+	}
+	if hashCaddyJSON(running) != drift.RunningHash {
+		return nil, fmt.Errorf("运行配置已再次变化，请重新检测后再操作")
+	}
+	if !drift.Adoptable {
+		return drift, s.adoptSnapshot(drift, server, running, operator)
+	}
+
+	candidate, err := s.matchingLocalCaddyfile(server, drift.RunningHash)
+	if err != nil {
+		return nil, fmt.Errorf("无法采用 Caddyfile: %w，请重新检测后再操作", err)
+	}
+	modules := normalizeCaddyModulesJSON(server.Modules)
+	if err := s.svcCtx.DB.WithContext(s.ctx).Transaction(func(tx *gorm.DB) error {
+		server.Config = candidate
+		if err := tx.Save(server).Error; err != nil {
+			return fmt.Errorf("保存 Caddy 服务器配置失败: %w", err)
+		}
+		return tx.Create(newCaddyConfigHistory(server.ID, "drift_adopt", candidate, modules, caddyConfigHistoryNote{
+			Operator: operator,
+			Message:  "采用运行配置以消除漂移 #" + strconv.FormatUint(uint64(drift.ID), 10),
+		})).Error
+	}); err != nil {
+		return nil, err
+	}
+	drift.StoredHash = hashConfig(candidate)
+	drift.Message = "已采用 " + server.CaddyfilePath + " 作为保存配置"
+	return drift, s.resolve(drift, caddyDriftStatusAdopted, operator)
+}
+
+// adoptSnapshot 把运行配置保存为 drift_snapshot 历史，同一偏差之后不再重复告警
+func (s *CaddyDriftService) adoptSnapshot(drift *model.CaddyConfigDrift, server *model.CaddyServer, running interface{}, operator string) error {
+	raw, err := json.MarshalIndent(running, "", "  ")
+	if err != nil {
+		return fmt.Errorf("格式化运行配置失败: %w", err)
+	}
+	history := newCaddyConfigHistory(server.ID, caddyDriftSnapshotHistoryAction, string(raw), server.Modules, caddyConfigHistoryNote{
+		Operator: operator,
+		Message:  "漂移 #" + strconv.FormatUint(uint64(drift.ID), 10) + " 的运行配置快照",
+	})
+	if err := s.svcCtx.DB.WithContext(s.ctx).Create(history).Error; err != nil {
+		return fmt.Errorf("保存运行配置快照失败: %w", err)
+	}
+	drift.Message = fmt.Sprintf("未找到与运行配置一致的 Caddyfile，运行配置已保存为历史 #%d，可在配置历史中重新下发", history.ID)
+	return s.resolve(drift, caddyDriftStatusAdopted, operator)
+}
+
+// Reapply 重新下发保存的 Caddyfile，覆盖运行中的配置
+func (s *CaddyDriftService) Reapply(driftID uint, operator string) (*model.CaddyConfigDrift, error) {
+	drift, server, err := s.loadOpenDrift(driftID)
+	if err != nil {
+		return nil, err
+	}
+	if err := newCaddyConfigApplyService(s.svcCtx, s.logger).withNote(operator, "重新下发保存的配置以消除漂移 #"+strconv.FormatUint(uint64(drift.ID), 10)).apply(server, server.Config, server.Modules, "drift_reapply"); err != nil {
+		return nil, err
+	}
+	drift.Message = "已重新下发保存的配置"
+	return drift, s.resolve(drift, caddyDriftStatusReapplied, operator)
+}
+
+func (s *CaddyDriftService) loadOpenDrift(driftID uint) (*model.CaddyConfigDrift, *model.CaddyServer, error) {
+	var drift model.CaddyConfigDrift
+	if err := s.svcCtx.DB.WithContext(s.ctx).First(&drift, driftID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, fmt.Errorf("漂移记录不存在")
+		}
+		return nil, nil, fmt.Errorf("查询漂移记录失败: %w", err)
+	}
+	if drift.Status != caddyDriftStatusOpen {
+		return nil, nil, fmt.Errorf("漂移记录已处理")
+	}
+	var server model.CaddyServer
+	if err := s.svcCtx.DB.WithContext(s.ctx).First(&server, drift.ServerID).Error; err != nil {
+		return nil, nil, fmt.Errorf("Caddy 服务器不存在")
+	}
+	return &drift, &server, nil
+}
+
+func (s *CaddyDriftService) resolve(drift *model.CaddyConfigDrift, status, operator string) error {
+	now := time.Now()
+	drift.Status = status
+	drift.ResolvedAt = &now
+	drift.ResolvedBy = strings.TrimSpace(operator)
+	if err := s.svcCtx.DB.WithContext(s.ctx).Save(drift).Error; err != nil {
+		return fmt.Errorf("更新漂移记录失败: %w", err)
+	}
+	return nil
+}
+
+// matchingLocalCaddyfile 读取节点配置的 Caddyfile 路径，适配结果与运行配置一致时返回其内容
+func (s *CaddyDriftService) matchingLocalCaddyfile(server *model.CaddyServer, runningHash string) (string, error) {
+	path := strings.TrimSpace(server.CaddyfilePath)
+	if path == "" {
+		return "", fmt.Errorf("节点未配置 Caddyfile 路径")
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("读取 Caddyfile 失败: %w", err)
+	}
+	if strings.TrimSpace(string(raw)) == "" {
+		return "", fmt.Errorf("Caddyfile 为空: %s", path)
+	}
+	adapted, err := adaptCaddyfileJSON(server, string(raw))
+	if err != nil {
+		return "", err
+	}
+	if hashCaddyJSON(adapted) != runningHash {
+		return "", fmt.Errorf("%s 与运行配置不一致", path)
+	}
+	return string(raw), nil
+}
+
+func (s *CaddyDriftService) notifyDrift(server *model.CaddyServer, drift *model.CaddyConfigDrift) {
+	if s.svcCtx.NotificationMgr == nil {
+		return
+	}
+	notifyWafEventAsync(s.svcCtx.NotificationMgr, s.logger,
+		notification.EventCaddyConfigDriftDetected,
+		notification.LevelWarning,
+		"Caddy 配置漂移",
+		fmt.Sprintf("节点 %s 的运行配置与 LogFlux 保存的配置不一致：%s", server.Name, drift.Summary),
+		map[string]interface{}{
+			"serverId":   server.ID,
+			"serverName": server.Name,
+			"driftId":    drift.ID,
+			"summary":    drift.Summary,
+		})
+}
+
+func findOpenCaddyDrift(db *gorm.DB, serverID uint) (*model.CaddyConfigDrift, error) {
+	var drift model.CaddyConfigDrift
+	err := db.Where("server_id = ? AND status = ?", serverID, caddyDriftStatusOpen).Order("id desc").First(&drift).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询漂移记录失败: %w", err)
+	}
+	return &drift, nil
+}
+
+// findAcknowledgedCaddyDrift 返回已保存为运行配置快照、且保存配置与运行配置都未再变化的偏差记录
+func findAcknowledgedCaddyDrift(db *gorm.DB, serverID uint, storedHash, runningHash string) (*model.CaddyConfigDrift, error) {
+	var drift model.CaddyConfigDrift
+	err := db.Where("server_id = ? AND status = ? AND stored_hash = ? AND running_hash = ?", serverID, caddyDriftStatusAdopted, storedHash, runningHash).
+		Order("id desc").First(&drift).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询漂移记录失败: %w", err)
+	}
+	return &drift, nil
+}
+
+// adaptCaddyfileJSON 调用 /adapt 获取 Caddyfile 对应的 JSON 配置
+func adaptCaddyfileJSON(server *model.CaddyServer, config string) (interface{}, error) {
+	_, body, err := postCaddyText(server, "/adapt", "text/caddyfile", config)
+	if err != nil {
+		return nil, fmt.Errorf("适配失败: %w", err)
+	}
+	var adapted struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(body, &adapted); err != nil {
+		return nil, fmt.Errorf("解析适配结果失败: %w", err)
+	}
+	return decodeCaddyJSON(adapted.Result)
+}
+
+func fetchRunningCaddyJSON(server *model.CaddyServer) (interface{}, error) {
+	body, err := getCaddyConfigJSON(server)
+	if err != nil {
+		return nil, err
+	}
+	return decodeCaddyJSON(body)
+}
+
+func decodeCaddyJSON(raw []byte) (interface{}, error) {
+	if len(strings.TrimSpace(string(raw))) == 0 {
+		return nil, nil
+	}
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, fmt.Errorf("解析 Caddy 配置 JSON 失败: %w", err)
+	}
+	return value, nil
+}
+
+// hashCaddyJSON 对规范化后的 JSON 求哈希（encoding/json 输出时对象键有序）
+func hashCaddyJSON(value interface{}) string {
+	raw, _ := json.Marshal(value)
+	return hashConfig(string(raw))
+}
+
+// diffCaddyJSON 递归比较期望配置与运行配置，数组按下标比较
+func diffCaddyJSON(expected, running interface{}) []caddyJSONDiffEntry {
+	entries := make([]caddyJSONDiffEntry, 0)
+	collectCaddyJSONDiff("", expected, running, &entries)
+	return entries
+}
+
+func collectCaddyJSONDiff(path string, before, after interface{}, entries *[]caddyJSONDiffEntry) {
+	if len(*entries) >= caddyDriftMaxDiffEntries {
+		return
+	}
+	beforeMap, beforeIsMap := before.(map[string]interface{})
+	afterMap, afterIsMap := after.(map[string]interface{})
+	if beforeIsMap && afterIsMap {
+		keys := make([]string, 0, len(beforeMap)+len(afterMap))
+		for key := range beforeMap {
+			keys = append(keys, key)
+		}
+		for key := range afterMap {
+			if _, ok := beforeMap[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			beforeValue, inBefore := beforeMap[key]
+			afterValue, inAfter := afterMap[key]
+			switch {
+			case !inBefore:
+				appendCaddyJSONDiff(entries, childPath, "added", nil, afterValue)
+			case !inAfter:
+				appendCaddyJSONDiff(entries, childPath, "removed", beforeValue, nil)
+			default:
+				collectCaddyJSONDiff(childPath, beforeValue, afterValue, entries)
+			}
+		}
+		return
+	}
+
+	beforeList, beforeIsList := before.([]interface{})
+	afterList, afterIsList := after.([]interface{})
+	if beforeIsList && afterIsList {
+		for i := 0; i < len(beforeList) || i < len(afterList); i++ {
+			childPath := path + "[" + strconv.Itoa(i) + "]"
+			switch {
+			case i >= len(beforeList):
+				appendCaddyJSONDiff(entries, childPath, "added", nil, afterList[i])
+			case i >= len(afterList):
+				appendCaddyJSONDiff(entries, childPath, "removed", beforeList[i], nil)
+			default:
+				collectCaddyJSONDiff(childPath, beforeList[i], afterList[i], entries)
+			}
+		}
+		return
+	}
+
+	if hashCaddyJSON(before) != hashCaddyJSON(after) {
+		appendCaddyJSONDiff(entries, path, "changed", before, after)
+	}
+}
+
+func appendCaddyJSONDiff(entries *[]caddyJSONDiffEntry, path, op string, before, after interface{}) {
+	if len(*entries) >= caddyDriftMaxDiffEntries {
+		return
+	}
+	entry := caddyJSONDiffEntry{Path: path, Op: op}
+	if op != "added" {
+		entry.Before, _ = json.Marshal(before)
+	}
+	if op != "removed" {
+		entry.After, _ = json.Marshal(after)
+	}
+	if entry.Path == "" {
+		entry.Path = "$"
+	}
+	*entries = append(*entries, entry)
+}
+
+func summarizeCaddyJSONDiff(entries []caddyJSONDiffEntry) string {
+	counts := map[string]int{}
+	for _, entry := range entries {
+		counts[entry.Op]++
+	}
+	parts := make([]string, 0, 3)
+	for _, item := range []struct{ op, label string }{{"added", "新增"}, {"removed", "删除"}, {"changed", "修改"}} {
+		if counts[item.op] > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d 处", item.label, counts[item.op]))
+		}
+	}
+	summary := strings.Join(parts, "，")
+	if len(entries) >= caddyDriftMaxDiffEntries {
+		summary += "（仅显示前 " + strconv.Itoa(caddyDriftMaxDiffEntries) + " 处）"
+	}
+	if len(entries) > 0 {
+		summary += "，首处 " + entries[0].Path
+	}
+	return summary
+}
+
+func toCaddyConfigDriftItem(drift model.CaddyConfigDrift, serverName string) types.CaddyConfigDriftItem {
+	return types.CaddyConfigDriftItem{
+		ID:          drift.ID,
+		ServerId:    drift.ServerID,
+		ServerName:  serverName,
+		Status:      drift.Status,
+		Summary:     drift.Summary,
+		Diff:        drift.Diff,
+		StoredHash:  drift.StoredHash,
+		RunningHash: drift.RunningHash,
+		Message:     drift.Message,
+		Adoptable:   drift.Adoptable,
+		LastSeenAt:  formatTime(drift.LastSeenAt),
+		ResolvedAt:  formatNullableTime(drift.ResolvedAt),
+		ResolvedBy:  drift.ResolvedBy,
+		CreatedAt:   formatTime(drift.CreatedAt),
+	}
+}
@@ -0,0 +1,122 @@
+package caddy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"logflux/internal/svc"
+	"logflux/model"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestDiffCaddyJSON(t *testing.T) {
+	expected, _ := decodeCaddyJSON([]byte(`{"apps":{"http":{"servers":{"srv0":{"listen":[":443"],"routes":[{"handle":[{"handler":"reverse_proxy"}]}]}}}},"admin":{"listen":"localhost:2019"}}`))
+	running, _ := decodeCaddyJSON([]byte(`{"apps":{"http":{"servers":{"srv0":{"listen":[":8443"],"routes":[{"handle":[{"handler":"reverse_proxy"}]},{"handle":[{"handler":"file_server"}]}]}}},"tls":{}}}`))
+
+	entries := diffCaddyJSON(expected, running)
+	want := []struct{ path, op string }{
+		{"admin", "removed"},
+		{"apps.http.servers.srv0.listen[0]", "changed"},
+		{"apps.http.servers.srv0.routes[1]", "added"},
+		{"apps.tls", "added"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("unexpected diff entries: %+v", entries)
+	}
+	for i, item := range want {
+		if entries[i].Path != item.path || entries[i].Op != item.op {
+			t.Fatalf("entry %d = %s %s, want %s %s", i, entries[i].Path, entries[i].Op, item.path, item.op)
+		}
+	}
+	if string(entries[1].Before) != `":443"` || string(entries[1].After) != `":8443"` {
+		t.Fatalf("unexpected changed values: %s -> %s", entries[1].Before, entries[1].After)
+	}
+	if entries[0].After != nil || entries[2].Before != nil {
+		t.Fatalf("added/removed entries should only carry one side: %+v", entries)
+	}
+
+	if summary := summarizeCaddyJSONDiff(entries); summary != "新增 2 处，删除 1 处，修改 1 处，首处 admin" {
+		t.Fatalf("unexpected summary: %s", summary)
+	}
+	if len(diffCaddyJSON(expected, expected)) != 0 {
+		t.Fatalf("identical configs should not drift")
+	}
+	if hashCaddyJSON(expected) == hashCaddyJSON(running) {
+		t.Fatalf("different configs should hash differently")
+	}
+}
+
+func TestDiffCaddyJSONCapsEntries(t *testing.T) {
+	expected := map[string]interface{}{}
+	running := map[string]interface{}{}
+	for i := 0; i < caddyDriftMaxDiffEntries+50; i++ {
+		running[string(rune('a'+i%26))+string(rune('0'+i/26))] = i
+	}
+
+	entries := diffCaddyJSON(expected, running)
+	if len(entries) != caddyDriftMaxDiffEntries {
+		t.Fatalf("expected diff to be capped at %d, got %d", caddyDriftMaxDiffEntries, len(entries))
+	}
+	if summary := summarizeCaddyJSONDiff(entries); summary != "新增 200 处（仅显示前 200 处），首处 a0" {
+		t.Fatalf("unexpected summary: %s", summary)
+	}
+	if diff := diffCaddyJSON(nil, running); len(diff) != 1 || diff[0].Path != "$" || diff[0].Op != "changed" {
+		t.Fatalf("empty expected config should report root change: %+v", diff)
+	}
+}
+
+func TestMatchingLocalCaddyfileRequiresConfiguredPath(t *testing.T) {
+	service := &CaddyDriftService{}
+	if _, err := service.matchingLocalCaddyfile(&model.CaddyServer{Type: "local"}, "hash"); err == nil || !strings.Contains(err.Error(), "未配置 Caddyfile 路径") {
+		t.Fatalf("expected missing path error, got %v", err)
+	}
+	missing := filepath.Join(t.TempDir(), "Caddyfile")
+	if _, err := service.matchingLocalCaddyfile(&model.CaddyServer{CaddyfilePath: missing}, "hash"); err == nil || !strings.Contains(err.Error(), "读取 Caddyfile 失败") {
+		t.Fatalf("expected read error, got %v", err)
+	}
+}
+
+func TestCaddyDriftAdoptSavesRunningSnapshotWithoutMatchingCaddyfile(t *testing.T) {
+	const runningJSON = `{"apps":{"http":{"servers":{"srv0":{"listen":[":8443"]}}}}}`
+	admin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/config/" {
+			t.Errorf("unexpected admin request %s", r.URL.Path)
+		}
+		_, _ = w.Write([]byte(runningJSON))
+	}))
+	defer admin.Close()
+
+	running, _ := decodeCaddyJSON([]byte(runningJSON))
+	snapshot := "{\n  \"apps\": {\n    \"http\": {\n      \"servers\": {\n        \"srv0\": {\n          \"listen\": [\n            \":8443\"\n          ]\n        }\n      }\n    }\n  }\n}"
+	if !isCaddyJSONSnapshot(snapshot) || isCaddyJSONSnapshot("{\n\tadmin off\n}\n:80 {\n}\n") {
+		t.Fatalf("snapshot detection should only match JSON configs")
+	}
+
+	db, mock, cleanup := newWafMirrorMockDB(t)
+	defer cleanup()
+	mock.ExpectQuery(`SELECT \* FROM "caddy_config_drifts"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "server_id", "status", "running_hash", "adoptable"}).AddRow(5, 1, caddyDriftStatusOpen, hashCaddyJSON(running), false))
+	mock.ExpectQuery(`SELECT \* FROM "caddy_servers"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "url", "config", "modules"}).AddRow(1, "remote", admin.URL, ":80 {\n}\n", "{}"))
+	// 没有一致的 Caddyfile：运行配置保存为快照历史，保存的 Caddyfile 不变
+	mock.ExpectQuery(`INSERT INTO "caddy_config_history"`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 1, caddyDriftSnapshotHistoryAction, hashConfig(snapshot), snapshot, "{}", caddyHistorySourceDriftAdopt, "alice", "漂移 #5 的运行配置快照", false, "", nil, "", "", nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(12))
+	mock.ExpectExec(`UPDATE "caddy_config_drifts"`).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	drift, err := NewCaddyDriftService(context.Background(), &svc.ServiceContext{DB: db}).Adopt(5, "alice")
+	if err != nil {
+		t.Fatalf("adopt should save a snapshot, got %v", err)
+	}
+	if drift.Status != caddyDriftStatusAdopted || !strings.Contains(drift.Message, "历史 #12") {
+		t.Fatalf("unexpected drift after adopt: %+v", drift)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet sql expectations: %v", err)
+	}
+}
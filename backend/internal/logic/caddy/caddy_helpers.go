@@ -41,6 +41,21 @@ func loadCaddyfile(server *model.CaddyServer, config string) error {
 	return nil
 }
 
+// loadCaddyJSON 直接加载 JSON 配置，用于重新下发运行配置快照
+func loadCaddyJSON(server *model.CaddyServer, config string) error {
+	_, _, err := postCaddyText(server, "/load", "application/json", config)
+	if err != nil {
+		return fmt.Errorf("加载失败: %w", err)
+	}
+	return nil
+}
+
+// isCaddyJSONSnapshot 判断历史配置是否为 JSON 格式的运行配置快照（Caddyfile 的全局选项块不是合法 JSON）
+func isCaddyJSONSnapshot(config string) bool {
+	trimmed := strings.TrimSpace(config)
+	return strings.HasPrefix(trimmed, "{") && json.Valid([]byte(trimmed))
+}
+
 func postCaddyText(server *model.CaddyServer, endpoint, contentType, body string) (int, []byte, error) {
 	var lastErr error
 	for attempt := 0; attempt < caddyMaxRetries; attempt++ {
@@ -91,6 +106,28 @@ func getCaddyConfigJSON(server *model.CaddyServer) ([]byte, error) {
 	return io.ReadAll(resp.Body)
 }
 
+// getCaddyAdmin 请求 Caddy 管理接口，非 2xx 时返回状态码与错误
+func getCaddyAdmin(server *model.CaddyServer, endpoint string) (int, []byte, error) {
+	req, err := http.NewRequest("GET", strings.TrimRight(server.Url, "/")+endpoint, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	if server.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+server.Token)
+	}
+	client := &http.Client{Timeout: caddyRequestTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, body, fmt.Errorf("Caddy API 错误: %s", strings.TrimSpace(string(body)))
+	}
+	return resp.StatusCode, body, nil
+}
+
 func hashConfig(config string) string {
 	sum := sha256.Sum256([]byte(config))
 	return hex.EncodeToString(sum[:])
@@ -131,10 +168,11 @@ func syncCaddyLogSources(svcCtx *svc.ServiceContext, server *model.CaddyServer,
 				Type:         "caddy",
 				Enabled:      true,
 				ScanInterval: ingest.DefaultScanIntervalSec(),
+				ServerID:     server.ID,
 			}
 			if err := svcCtx.DB.Create(&newSource).Error; err == nil {
 				logger.Infof("自动添加日志源: %s", path)
-				svcCtx.Ingestor.StartWithInterval(path, newSource.ScanInterval, newSource.Type)
+				svcCtx.Ingestor.StartSource(newSource)
 			}
 			continue
 		}
@@ -142,10 +180,12 @@ func syncCaddyLogSources(svcCtx *svc.ServiceContext, server *model.CaddyServer,
 			logger.Errorf("查询日志源失败: %v", err)
 			continue
 		}
-		if !source.Enabled {
-			svcCtx.DB.Model(&model.LogSource{}).Where("id = ?", source.ID).Update("enabled", true)
+		if !source.Enabled || source.ServerID != server.ID {
+			svcCtx.DB.Model(&model.LogSource{}).Where("id = ?", source.ID).Updates(map[string]interface{}{"enabled": true, "server_id": server.ID})
+			source.Enabled = true
+			source.ServerID = server.ID
 		}
-		svcCtx.Ingestor.StartWithInterval(path, source.ScanInterval, source.Type)
+		svcCtx.Ingestor.StartSource(source)
 	}
 }
 
@@ -0,0 +1,110 @@
+package caddy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"logflux/internal/types"
+	"logflux/model"
+
+	"gorm.io/gorm"
+)
+
+// normalizeCaddyServerGroup 校验分组名称唯一并去重节点 ID，节点必须全部存在
+func normalizeCaddyServerGroup(db *gorm.DB, excludeID uint, name string, serverIDs []int64) (string, model.Int64Array, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil, fmt.Errorf("分组名称不能为空")
+	}
+
+	seen := make(map[int64]struct{}, len(serverIDs))
+	normalized := make(model.Int64Array, 0, len(serverIDs))
+	for _, id := range serverIDs {
+		if id <= 0 {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		normalized = append(normalized, id)
+	}
+	if len(normalized) == 0 {
+		return "", nil, fmt.Errorf("分组至少需要包含一个 Caddy 节点")
+	}
+	sort.Slice(normalized, func(i, j int) bool { return normalized[i] < normalized[j] })
+
+	var count int64
+	query := db.Model(&model.CaddyServerGroup{}).Where("name = ?", name)
+	if excludeID > 0 {
+		query = query.Where("id <> ?", excludeID)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return "", nil, fmt.Errorf("检查分组名称失败: %w", err)
+	}
+	if count > 0 {
+		return "", nil, fmt.Errorf("分组名称已存在: %s", name)
+	}
+
+	var existing int64
+	if err := db.Model(&model.CaddyServer{}).Where("id IN ?", []int64(normalized)).Count(&existing).Error; err != nil {
+		return "", nil, fmt.Errorf("查询 Caddy 服务器失败: %w", err)
+	}
+	if existing != int64(len(normalized)) {
+		return "", nil, fmt.Errorf("分组中包含不存在的 Caddy 节点")
+	}
+	return name, normalized, nil
+}
+
+func toCaddyServerGroupItem(group *model.CaddyServerGroup, serverNames map[uint]string) types.CaddyServerGroupItem {
+	serverIDs := make([]int64, 0, len(group.ServerIDs))
+	names := make([]string, 0, len(group.ServerIDs))
+	for _, id := range group.ServerIDs {
+		serverIDs = append(serverIDs, id)
+		if name, ok := serverNames[uint(id)]; ok {
+			names = append(names, name)
+		}
+	}
+	return types.CaddyServerGroupItem{
+		ID:          group.ID,
+		Name:        group.Name,
+		Description: group.Description,
+		ServerIds:   serverIDs,
+		ServerNames: names,
+		CreatedAt:   formatTime(group.CreatedAt),
+		UpdatedAt:   formatTime(group.UpdatedAt),
+	}
+}
+
+// resolveWafDistributionServers 返回分组内的节点；groupID 为 0 时返回全部节点
+func resolveWafDistributionServers(db *gorm.DB, groupID uint) ([]model.CaddyServer, error) {
+	query := db.Model(&model.CaddyServer{})
+	if groupID > 0 {
+		var group model.CaddyServerGroup
+		if err := db.First(&group, groupID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, fmt.Errorf("节点分组不存在")
+			}
+			return nil, fmt.Errorf("查询节点分组失败: %w", err)
+		}
+		if len(group.ServerIDs) == 0 {
+			return nil, fmt.Errorf("节点分组 %s 未包含任何节点", group.Name)
+		}
+		query = query.Where("id IN ?", []int64(group.ServerIDs))
+	}
+
+	var servers []model.CaddyServer
+	if err := query.Order("id asc").Find(&servers).Error; err != nil {
+		return nil, fmt.Errorf("查询 Caddy 服务器失败: %w", err)
+	}
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("没有可分发的 Caddy 节点")
+	}
+	for _, server := range servers {
+		if strings.TrimSpace(server.Config) == "" {
+			return nil, fmt.Errorf("Caddy 服务器 %s 配置为空，请先保存 Caddy 配置", server.Name)
+		}
+	}
+	return servers, nil
+}
@@ -0,0 +1,237 @@
+package caddy
+
+import (
+	"fmt"
+	"strings"
+
+	"logflux/internal/caddyfile"
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const caddySiteEditAction = "site_edit"
+
+// caddySiteEditor 基于 Caddyfile 语法树按站点/指令编辑配置，未涉及的内容（注释、顺序、格式）原样保留；
+// 写入前比对配置哈希，避免覆盖他人在此期间的修改
+type caddySiteEditor struct {
+	svcCtx   *svc.ServiceContext
+	logger   logx.Logger
+	operator string
+}
+
+func newCaddySiteEditor(svcCtx *svc.ServiceContext, logger logx.Logger, operator string) *caddySiteEditor {
+	return &caddySiteEditor{svcCtx: svcCtx, logger: logger, operator: operator}
+}
+
+func (e *caddySiteEditor) load(serverID uint) (*model.CaddyServer, *caddyfile.Document, string, string, error) {
+	var server model.CaddyServer
+	if err := e.svcCtx.DB.First(&server, serverID).Error; err != nil {
+		return nil, nil, "", "", fmt.Errorf("服务器不存在")
+	}
+	config, modules, err := newCaddyConfigApplyService(e.svcCtx, e.logger).loadCurrent(&server)
+	if err != nil {
+		return nil, nil, "", "", err
+	}
+	doc, err := caddyfile.Parse(config)
+	if err != nil {
+		return nil, nil, "", "", fmt.Errorf("解析 Caddyfile 失败: %w", err)
+	}
+	return &server, doc, config, modules, nil
+}
+
+func (e *caddySiteEditor) list(serverID uint) (*types.CaddySiteListResp, error) {
+	_, doc, config, _, err := e.load(serverID)
+	if err != nil {
+		return nil, err
+	}
+	return &types.CaddySiteListResp{
+		ConfigHash: hashConfig(config),
+		Sites:      toCaddySiteItems(doc),
+	}, nil
+}
+
+// edit 加载当前配置、校验哈希并执行修改；dryRun 时只返回修改后的 Caddyfile
+func (e *caddySiteEditor) edit(serverID uint, configHash string, dryRun bool, mutate func(doc *caddyfile.Document) error) (*types.CaddySiteEditResp, error) {
+	server, doc, config, modules, err := e.load(serverID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCaddyConfigHash(config, configHash); err != nil {
+		return nil, err
+	}
+	if err := mutate(doc); err != nil {
+		return nil, err
+	}
+
+	updated := doc.String()
+	if _, err := caddyfile.Parse(updated); err != nil {
+		return nil, fmt.Errorf("生成的 Caddyfile 无效: %w", err)
+	}
+	resp := &types.CaddySiteEditResp{ConfigHash: hashConfig(updated), Config: updated}
+	if dryRun || updated == config {
+		return resp, nil
+	}
+	if err := newCaddyConfigApplyService(e.svcCtx, e.logger).withNote(e.operator, "").apply(server, updated, modules, caddySiteEditAction); err != nil {
+		return nil, err
+	}
+	resp.Applied = true
+	return resp, nil
+}
+
+func checkCaddyConfigHash(config, configHash string) error {
+	configHash = strings.TrimSpace(configHash)
+	if configHash == "" {
+		return fmt.Errorf("缺少配置哈希，请刷新后重试")
+	}
+	if hashConfig(config) != configHash {
+		return fmt.Errorf("Caddy 配置已被修改，请刷新后重试")
+	}
+	return nil
+}
+
+func caddySiteAt(doc *caddyfile.Document, index int) (*caddyfile.Node, error) {
+	sites := doc.Sites()
+	if index < 0 || index >= len(sites) {
+		return nil, fmt.Errorf("站点序号 %d 不存在", index)
+	}
+	return sites[index], nil
+}
+
+func normalizeCaddySiteAddresses(doc *caddyfile.Document, addresses []string, self *caddyfile.Node) ([]string, error) {
+	normalized := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		if address = strings.TrimSpace(address); address != "" {
+			normalized = append(normalized, address)
+		}
+	}
+	for _, site := range doc.Sites() {
+		if site == self {
+			continue
+		}
+		for _, existing := range caddyfile.SiteAddresses(site) {
+			for _, address := range normalized {
+				if strings.EqualFold(existing, address) {
+					return nil, fmt.Errorf("站点地址已存在: %s", address)
+				}
+			}
+		}
+	}
+	return normalized, nil
+}
+
+func toCaddySiteItems(doc *caddyfile.Document) []types.CaddySiteItem {
+	sites := doc.Sites()
+	items := make([]types.CaddySiteItem, 0, len(sites))
+	for i, site := range sites {
+		routes := caddyfile.SiteRoutes(site)
+		item := types.CaddySiteItem{
+			Index:     i,
+			Addresses: caddyfile.SiteAddresses(site),
+			StartLine: site.StartLine + 1,
+			Routes:    make([]types.CaddyRouteItem, 0, len(routes)),
+		}
+		for _, route := range routes {
+			item.Routes = append(item.Routes, toCaddyRouteItem(route))
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func toCaddyRouteItem(route caddyfile.Route) types.CaddyRouteItem {
+	item := types.CaddyRouteItem{Index: route.Index, Kind: route.Kind, Raw: route.Raw}
+	switch {
+	case route.Matcher != nil:
+		item.Matcher = &types.CaddyRouteMatcher{Name: route.Matcher.Name, Conditions: make([]types.CaddyRouteMatcherCondition, 0, len(route.Matcher.Conditions))}
+		for _, condition := range route.Matcher.Conditions {
+			item.Matcher.Conditions = append(item.Matcher.Conditions, types.CaddyRouteMatcherCondition{Type: condition.Type, Values: condition.Values})
+		}
+	case route.ReverseProxy != nil:
+		item.ReverseProxy = &types.CaddyRouteReverseProxy{
+			Matcher:   route.ReverseProxy.Matcher,
+			Upstreams: route.ReverseProxy.Upstreams,
+			LbPolicy:  route.ReverseProxy.LbPolicy,
+			HealthUri: route.ReverseProxy.HealthURI,
+		}
+	case route.Header != nil:
+		item.Header = &types.CaddyRouteHeader{Matcher: route.Header.Matcher, Ops: make([]types.CaddyRouteHeaderOp, 0, len(route.Header.Ops))}
+		for _, op := range route.Header.Ops {
+			item.Header.Ops = append(item.Header.Ops, types.CaddyRouteHeaderOp{Op: op.Op, Field: op.Field, Value: op.Value, Replace: op.Replace})
+		}
+	case route.Redirect != nil:
+		item.Redirect = &types.CaddyRouteRedirect{Matcher: route.Redirect.Matcher, To: route.Redirect.To, Code: route.Redirect.Code}
+	case route.FileServer != nil:
+		item.FileServer = &types.CaddyRouteFileServer{Matcher: route.FileServer.Matcher, Root: route.FileServer.Root, Browse: route.FileServer.Browse}
+	case route.TLS != nil:
+		item.Tls = &types.CaddyRouteTls{Mode: route.TLS.Mode, Email: route.TLS.Email, CertFile: route.TLS.CertFile, KeyFile: route.TLS.KeyFile}
+	}
+	return item
+}
+
+func fromCaddyRouteItem(item types.CaddyRouteItem) caddyfile.Route {
+	route := caddyfile.Route{Kind: strings.ToLower(strings.TrimSpace(item.Kind)), Raw: item.Raw}
+	if item.Matcher != nil {
+		route.Matcher = &caddyfile.Matcher{Name: strings.TrimSpace(item.Matcher.Name)}
+		for _, condition := range item.Matcher.Conditions {
+			route.Matcher.Conditions = append(route.Matcher.Conditions, caddyfile.MatcherCondition{
+				Type:   strings.TrimSpace(condition.Type),
+				Values: trimNonEmpty(condition.Values),
+			})
+		}
+	}
+	if item.ReverseProxy != nil {
+		route.ReverseProxy = &caddyfile.ReverseProxy{
+			Matcher:   strings.TrimSpace(item.ReverseProxy.Matcher),
+			Upstreams: trimNonEmpty(item.ReverseProxy.Upstreams),
+			LbPolicy:  strings.TrimSpace(item.ReverseProxy.LbPolicy),
+			HealthURI: strings.TrimSpace(item.ReverseProxy.HealthUri),
+		}
+	}
+	if item.Header != nil {
+		route.Header = &caddyfile.Header{Matcher: strings.TrimSpace(item.Header.Matcher)}
+		for _, op := range item.Header.Ops {
+			route.Header.Ops = append(route.Header.Ops, caddyfile.HeaderOp{
+				Op:      strings.TrimSpace(op.Op),
+				Field:   strings.TrimSpace(op.Field),
+				Value:   op.Value,
+				Replace: op.Replace,
+			})
+		}
+	}
+	if item.Redirect != nil {
+		route.Redirect = &caddyfile.Redirect{
+			Matcher: strings.TrimSpace(item.Redirect.Matcher),
+			To:      strings.TrimSpace(item.Redirect.To),
+			Code:    strings.TrimSpace(item.Redirect.Code),
+		}
+	}
+	if item.FileServer != nil {
+		route.FileServer = &caddyfile.FileServer{
+			Matcher: strings.TrimSpace(item.FileServer.Matcher),
+			Root:    strings.TrimSpace(item.FileServer.Root),
+			Browse:  item.FileServer.Browse,
+		}
+	}
+	if item.Tls != nil {
+		route.TLS = &caddyfile.TLS{
+			Mode:     strings.TrimSpace(item.Tls.Mode),
+			Email:    strings.TrimSpace(item.Tls.Email),
+			CertFile: strings.TrimSpace(item.Tls.CertFile),
+			KeyFile:  strings.TrimSpace(item.Tls.KeyFile),
+		}
+	}
+	return route
+}
+
+func trimNonEmpty(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
@@ -0,0 +1,70 @@
+package caddy
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+const siteEditorConfig = "# 主站\nexample.com {\n\t# 后端\n\treverse_proxy 127.0.0.1:8080\n}\n"
+
+func expectSiteEditorServer(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "caddy_servers" WHERE "caddy_servers"."id" = $1`)).
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "config", "modules"}).AddRow(1, "edge-1", siteEditorConfig, "{}"))
+}
+
+func TestCaddySiteEditorListAndDryRun(t *testing.T) {
+	db, mock, cleanup := newWafMirrorMockDB(t)
+	defer cleanup()
+
+	svcCtx := &svc.ServiceContext{DB: db}
+	expectSiteEditorServer(mock)
+	listResp, err := NewListCaddySitesLogic(context.Background(), svcCtx).ListCaddySites(&types.CaddyConfigReq{ServerId: 1})
+	if err != nil {
+		t.Fatalf("ListCaddySites() error = %v", err)
+	}
+	if len(listResp.Sites) != 1 || listResp.Sites[0].Routes[0].ReverseProxy.Upstreams[0] != "127.0.0.1:8080" {
+		t.Fatalf("unexpected sites: %+v", listResp.Sites)
+	}
+
+	expectSiteEditorServer(mock)
+	_, err = NewUpdateCaddyRouteLogic(context.Background(), svcCtx).UpdateCaddyRoute(&types.CaddyRouteUpdateReq{
+		ServerId: 1, ConfigHash: "stale", DryRun: true,
+		Route: types.CaddyRouteItem{Kind: "reverse_proxy", ReverseProxy: &types.CaddyRouteReverseProxy{Upstreams: []string{"127.0.0.1:9090"}}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "已被修改") {
+		t.Fatalf("expected stale hash to be rejected, got %v", err)
+	}
+
+	expectSiteEditorServer(mock)
+	resp, err := NewUpdateCaddyRouteLogic(context.Background(), svcCtx).UpdateCaddyRoute(&types.CaddyRouteUpdateReq{
+		ServerId: 1, ConfigHash: listResp.ConfigHash, DryRun: true,
+		Route: types.CaddyRouteItem{Kind: "reverse_proxy", ReverseProxy: &types.CaddyRouteReverseProxy{Upstreams: []string{"127.0.0.1:9090"}, LbPolicy: "first"}},
+	})
+	if err != nil {
+		t.Fatalf("UpdateCaddyRoute() error = %v", err)
+	}
+	want := "# 主站\nexample.com {\n\t# 后端\n\treverse_proxy 127.0.0.1:9090 {\n\t\tlb_policy first\n\t}\n}\n"
+	if resp.Applied || resp.Config != want || resp.ConfigHash != hashConfig(want) {
+		t.Fatalf("unexpected dry run result: %+v", resp)
+	}
+
+	expectSiteEditorServer(mock)
+	_, err = NewCreateCaddySiteLogic(context.Background(), svcCtx).CreateCaddySite(&types.CaddySiteReq{
+		ServerId: 1, ConfigHash: listResp.ConfigHash, DryRun: true, Addresses: []string{"EXAMPLE.com"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "已存在") {
+		t.Fatalf("expected duplicate address to be rejected, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("sql expectations not met: %v", err)
+	}
+}
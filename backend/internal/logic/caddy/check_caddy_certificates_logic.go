@@ -0,0 +1,45 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CheckCaddyCertificatesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCheckCaddyCertificatesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CheckCaddyCertificatesLogic {
+	return &CheckCaddyCertificatesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CheckCaddyCertificatesLogic) CheckCaddyCertificates(req *types.CaddyCertificateCheckReq) (resp *types.CaddyCertificateListResp, err error) {
+	var server model.CaddyServer
+	if err := l.svcCtx.DB.WithContext(l.ctx).First(&server, req.ServerId).Error; err != nil {
+		return nil, fmt.Errorf("服务器不存在")
+	}
+
+	certs, err := NewCaddyCertificateService(l.ctx, l.svcCtx).Check(&server, req.Probe)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	list := make([]types.CaddyCertificateItem, 0, len(certs))
+	for i := range certs {
+		list = append(list, toCaddyCertificateItem(&certs[i], server.Name, now))
+	}
+	return &types.CaddyCertificateListResp{List: list, Total: int64(len(list))}, nil
+}
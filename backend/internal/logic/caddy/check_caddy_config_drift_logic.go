@@ -0,0 +1,43 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CheckCaddyConfigDriftLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCheckCaddyConfigDriftLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CheckCaddyConfigDriftLogic {
+	return &CheckCaddyConfigDriftLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CheckCaddyConfigDriftLogic) CheckCaddyConfigDrift(req *types.CaddyConfigReq) (resp *types.CaddyConfigDriftCheckResp, err error) {
+	var server model.CaddyServer
+	if err := l.svcCtx.DB.WithContext(l.ctx).First(&server, req.ServerId).Error; err != nil {
+		return nil, fmt.Errorf("服务器不存在")
+	}
+
+	drift, err := NewCaddyDriftService(l.ctx, l.svcCtx).Check(&server)
+	if err != nil {
+		return nil, err
+	}
+	if drift == nil {
+		return &types.CaddyConfigDriftCheckResp{}, nil
+	}
+	item := toCaddyConfigDriftItem(*drift, server.Name)
+	return &types.CaddyConfigDriftCheckResp{Drifted: true, Drift: &item}, nil
+}
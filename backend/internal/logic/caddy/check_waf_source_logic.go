@@ -58,7 +58,7 @@ func (l *CheckWafSourceLogic) CheckWafSource(req *types.WafSourceActionReq) (res
 		return nil, err
 	}
 
-	if source.Mode == wafModeRemote {
+	if isWafRemoteMode(source.Mode) {
 		if strings.TrimSpace(source.URL) == "" {
 			err = fmt.Errorf("远程源 URL 不能为空")
 			helper.updateSourceLastCheck(source.ID, "", err.Error())
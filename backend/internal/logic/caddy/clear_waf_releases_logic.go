@@ -106,6 +106,7 @@ func (l *ClearWafReleasesLogic) ClearWafReleases(req *types.WafReleaseClearReq)
 			l.Logger.Errorf("删除版本存储路径失败: path=%s err=%v", pathValue, removeErr)
 		}
 	}
+	helper.removeReleaseArchives(releaseIDs)
 
 	return &types.BaseResp{Code: 200, Msg: "成功"}, nil
 }
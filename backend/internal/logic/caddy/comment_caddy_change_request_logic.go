@@ -0,0 +1,35 @@
+package caddy
+
+import (
+	"context"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CommentCaddyChangeRequestLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCommentCaddyChangeRequestLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CommentCaddyChangeRequestLogic {
+	return &CommentCaddyChangeRequestLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CommentCaddyChangeRequestLogic) CommentCaddyChangeRequest(req *types.CaddyChangeRequestActionReq) (resp *types.BaseResp, err error) {
+	actor, err := loadCaddyChangeActor(l.ctx, l.svcCtx.DB)
+	if err != nil {
+		return nil, err
+	}
+	if err := NewCaddyChangeRequestService(l.ctx, l.svcCtx).Comment(actor, req.ID, req.Comment); err != nil {
+		return nil, err
+	}
+	return &types.BaseResp{Code: 200, Msg: "成功"}, nil
+}
@@ -0,0 +1,46 @@
+package caddy
+
+import (
+	"context"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CreateCaddyChangeRequestLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCreateCaddyChangeRequestLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateCaddyChangeRequestLogic {
+	return &CreateCaddyChangeRequestLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CreateCaddyChangeRequestLogic) CreateCaddyChangeRequest(req *types.CaddyChangeRequestReq) (resp *types.CaddyChangeRequestItem, err error) {
+	actor, err := loadCaddyChangeActor(l.ctx, l.svcCtx.DB)
+	if err != nil {
+		return nil, err
+	}
+	request, err := NewCaddyChangeRequestService(l.ctx, l.svcCtx).Create(actor, req.Kind, caddyChangeDraft{
+		ServerID:    req.ServerId,
+		PolicyID:    req.PolicyId,
+		Title:       req.Title,
+		Description: req.Description,
+		Config:      req.Config,
+		Modules:     req.Modules,
+		ScheduledAt: req.ScheduledAt,
+		Submit:      req.Submit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	item := toCaddyChangeRequestItem(*request)
+	return &item, nil
+}
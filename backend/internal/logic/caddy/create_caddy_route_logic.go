@@ -0,0 +1,36 @@
+package caddy
+
+import (
+	"context"
+
+	"logflux/internal/caddyfile"
+	"logflux/internal/svc"
+	"logflux/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CreateCaddyRouteLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCreateCaddyRouteLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateCaddyRouteLogic {
+	return &CreateCaddyRouteLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CreateCaddyRouteLogic) CreateCaddyRoute(req *types.CaddyRouteReq) (resp *types.CaddySiteEditResp, err error) {
+	return newCaddySiteEditor(l.svcCtx, l.Logger, currentOperatorFromContext(l.ctx)).edit(req.ServerId, req.ConfigHash, req.DryRun, func(doc *caddyfile.Document) error {
+		site, err := caddySiteAt(doc, req.SiteIndex)
+		if err != nil {
+			return err
+		}
+		_, err = doc.AddRoute(site, req.Index, fromCaddyRouteItem(req.Route))
+		return err
+	})
+}
@@ -0,0 +1,49 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CreateCaddyServerGroupLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCreateCaddyServerGroupLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateCaddyServerGroupLogic {
+	return &CreateCaddyServerGroupLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CreateCaddyServerGroupLogic) CreateCaddyServerGroup(req *types.CaddyServerGroupReq) (resp *types.BaseResp, err error) {
+	if req == nil {
+		return nil, fmt.Errorf("分组参数不合法")
+	}
+	db := l.svcCtx.DB.WithContext(l.ctx)
+	name, serverIDs, err := normalizeCaddyServerGroup(db, 0, req.Name, req.ServerIds)
+	if err != nil {
+		return nil, err
+	}
+
+	group := &model.CaddyServerGroup{
+		Name:        name,
+		Description: strings.TrimSpace(req.Description),
+		ServerIDs:   serverIDs,
+	}
+	if err := db.Create(group).Error; err != nil {
+		return nil, fmt.Errorf("创建节点分组失败: %w", err)
+	}
+
+	return &types.BaseResp{Code: 200, Msg: "成功"}, nil
+}
@@ -0,0 +1,39 @@
+package caddy
+
+import (
+	"context"
+
+	"logflux/internal/caddyfile"
+	"logflux/internal/svc"
+	"logflux/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CreateCaddySiteLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCreateCaddySiteLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateCaddySiteLogic {
+	return &CreateCaddySiteLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CreateCaddySiteLogic) CreateCaddySite(req *types.CaddySiteReq) (resp *types.CaddySiteEditResp, err error) {
+	return newCaddySiteEditor(l.svcCtx, l.Logger, currentOperatorFromContext(l.ctx)).edit(req.ServerId, req.ConfigHash, req.DryRun, func(doc *caddyfile.Document) error {
+		addresses, err := normalizeCaddySiteAddresses(doc, req.Addresses, nil)
+		if err != nil {
+			return err
+		}
+		site, err := caddyfile.NewSite(addresses)
+		if err != nil {
+			return err
+		}
+		return doc.Append(nil, site)
+	})
+}
@@ -0,0 +1,55 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CreateWafBanLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCreateWafBanLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateWafBanLogic {
+	return &CreateWafBanLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// CreateWafBan 手动封禁 IP/CIDR，可直接从访问日志取 IP，立即同步到 Caddy
+func (l *CreateWafBanLogic) CreateWafBan(req *types.WafBanReq) (resp *types.WafBanItem, err error) {
+	defer func() {
+		err = localizeWafPolicyError(err)
+	}()
+
+	if req == nil {
+		return nil, fmt.Errorf("封禁参数不合法")
+	}
+	ip := req.IP
+	reason := strings.TrimSpace(req.Reason)
+	if req.CaddyLogId > 0 {
+		ip, err = resolveWafIPFromCaddyLog(l.svcCtx.DB.WithContext(l.ctx), req.CaddyLogId)
+		if err != nil {
+			return nil, err
+		}
+		if reason == "" {
+			reason = fmt.Sprintf("来自访问日志 #%d", req.CaddyLogId)
+		}
+	}
+
+	ban, err := NewWafBanService(l.ctx, l.svcCtx).Ban(ip, req.Host, req.BanMinutes, reason, currentOperatorFromContext(l.ctx))
+	if err != nil {
+		return nil, err
+	}
+	item := toWafBanItem(ban, "")
+	return &item, nil
+}
@@ -0,0 +1,69 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CreateWafBanRuleLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCreateWafBanRuleLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateWafBanRuleLogic {
+	return &CreateWafBanRuleLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CreateWafBanRuleLogic) CreateWafBanRule(req *types.WafBanRuleReq) (resp *types.BaseResp, err error) {
+	defer func() {
+		err = localizeWafPolicyError(err)
+	}()
+
+	if req == nil {
+		return nil, fmt.Errorf("封禁规则参数不合法")
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("封禁规则名称不能为空")
+	}
+	host, err := normalizeWafBanHost(req.Host)
+	if err != nil {
+		return nil, err
+	}
+	statusCodes, _, err := normalizeWafBanStatusCodes(req.StatusCodes)
+	if err != nil {
+		return nil, err
+	}
+	threshold, windowMinutes, banMinutes, err := normalizeWafBanRuleLimits(req.Threshold, req.WindowMinutes, req.BanMinutes)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := &model.WafBanRule{
+		Name:          name,
+		Description:   strings.TrimSpace(req.Description),
+		Enabled:       req.Enabled,
+		Host:          host,
+		StatusCodes:   statusCodes,
+		Threshold:     threshold,
+		WindowMinutes: windowMinutes,
+		BanMinutes:    banMinutes,
+	}
+	if err := l.svcCtx.DB.WithContext(l.ctx).Create(rule).Error; err != nil {
+		return nil, fmt.Errorf("创建封禁规则失败: %w", err)
+	}
+
+	return &types.BaseResp{Code: 200, Msg: "成功"}, nil
+}
@@ -0,0 +1,72 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CreateWafCustomRuleLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCreateWafCustomRuleLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateWafCustomRuleLogic {
+	return &CreateWafCustomRuleLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CreateWafCustomRuleLogic) CreateWafCustomRule(req *types.WafCustomRuleReq) (resp *types.BaseResp, err error) {
+	defer func() {
+		err = localizeWafPolicyError(err)
+	}()
+
+	if req == nil {
+		return nil, fmt.Errorf("自定义规则参数不合法")
+	}
+	db := l.svcCtx.DB.WithContext(l.ctx)
+	if err := validatePolicyIDExists(db, req.PolicyId); err != nil {
+		return nil, err
+	}
+
+	directive, ruleID, phase, err := normalizeAndValidateCustomRuleDirective(req.Directive, req.Phase)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateCustomRuleIDUnique(db, req.PolicyId, ruleID, 0); err != nil {
+		return nil, err
+	}
+
+	rule := &model.WafCustomRule{
+		PolicyID:    req.PolicyId,
+		Name:        strings.TrimSpace(req.Name),
+		Description: strings.TrimSpace(req.Description),
+		Enabled:     req.Enabled,
+		RuleID:      ruleID,
+		Phase:       phase,
+		Priority:    normalizeCustomRulePriority(req.Priority),
+		Directive:   directive,
+	}
+
+	if err := db.Create(rule).Error; err != nil {
+		return nil, fmt.Errorf("创建自定义规则失败: %w", err)
+	}
+	// enabled 列带数据库默认值 true，创建时 gorm 会跳过零值，禁用状态需单独写入
+	if !rule.Enabled {
+		if err := db.Model(rule).Update("enabled", false).Error; err != nil {
+			return nil, fmt.Errorf("创建自定义规则失败: %w", err)
+		}
+	}
+
+	return &types.BaseResp{Code: 200, Msg: "成功"}, nil
+}
@@ -0,0 +1,104 @@
+package caddy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type CreateWafIPListEntryLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCreateWafIPListEntryLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateWafIPListEntryLogic {
+	return &CreateWafIPListEntryLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// CreateWafIPListEntry 新增名单条目，同一名单内重复的值会更新备注与到期时间；可直接从访问日志取 IP
+func (l *CreateWafIPListEntryLogic) CreateWafIPListEntry(req *types.WafIPListEntryReq) (resp *types.WafIPListEntryItem, err error) {
+	defer func() {
+		err = localizeWafPolicyError(err)
+	}()
+
+	if req == nil || req.ID == 0 {
+		return nil, fmt.Errorf("IP 名单 ID 不能为空")
+	}
+	db := l.svcCtx.DB.WithContext(l.ctx)
+
+	var list model.WafIPList
+	if err := db.First(&list, req.ID).Error; err != nil {
+		return nil, fmt.Errorf("IP 名单不存在")
+	}
+
+	value := req.Value
+	source, err := normalizeWafIPListEntrySource(req.Source)
+	if err != nil {
+		return nil, err
+	}
+	sourceRef := ""
+	if req.CaddyLogId > 0 {
+		value, err = resolveWafIPFromCaddyLog(db, req.CaddyLogId)
+		if err != nil {
+			return nil, err
+		}
+		source = wafIPListEntrySourceCaddyLog
+		sourceRef = strconv.FormatUint(uint64(req.CaddyLogId), 10)
+	}
+
+	kind, normalizedValue, err := normalizeWafIPListEntryValue(req.Kind, value)
+	if err != nil {
+		return nil, err
+	}
+	if kind == wafIPListEntryKindCountry && list.Action != wafIPListActionDeny {
+		return nil, fmt.Errorf("国家/地区条目仅支持拒绝名单")
+	}
+	now := time.Now()
+	expiresAt, err := parseWafIPListEntryExpiresAt(req.ExpiresAt, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var entry model.WafIPListEntry
+	err = db.Where("list_id = ? AND value = ?", list.ID, normalizedValue).First(&entry).Error
+	switch {
+	case err == nil:
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		entry = model.WafIPListEntry{ListID: list.ID, Value: normalizedValue}
+	default:
+		return nil, fmt.Errorf("查询 IP 名单条目失败: %w", err)
+	}
+	entry.Kind = kind
+	entry.Note = strings.TrimSpace(req.Note)
+	entry.Source = source
+	entry.SourceRef = sourceRef
+	entry.ExpiresAt = expiresAt
+	if err := db.Save(&entry).Error; err != nil {
+		return nil, fmt.Errorf("保存 IP 名单条目失败: %w", err)
+	}
+
+	if req.Publish {
+		publishLogic := NewPublishWafPolicyLogic(l.ctx, l.svcCtx)
+		if _, err := publishLogic.PublishWafPolicy(&types.WafPolicyActionReq{ID: list.PolicyID}); err != nil {
+			return nil, fmt.Errorf("名单条目已保存，但发布策略失败: %w", err)
+		}
+	}
+
+	item := toWafIPListEntryItem(&entry, now)
+	return &item, nil
+}
@@ -0,0 +1,66 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CreateWafIPListLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCreateWafIPListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateWafIPListLogic {
+	return &CreateWafIPListLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CreateWafIPListLogic) CreateWafIPList(req *types.WafIPListReq) (resp *types.BaseResp, err error) {
+	defer func() {
+		err = localizeWafPolicyError(err)
+	}()
+
+	if req == nil {
+		return nil, fmt.Errorf("IP 名单参数不合法")
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("IP 名单名称不能为空")
+	}
+	db := l.svcCtx.DB.WithContext(l.ctx)
+	if err := validatePolicyIDExists(db, req.PolicyId); err != nil {
+		return nil, err
+	}
+	if err := validateWafIPListBinding(db, req.PolicyId, req.BindingId); err != nil {
+		return nil, err
+	}
+	action, err := normalizeWafIPListAction(req.Action)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &model.WafIPList{
+		PolicyID:    req.PolicyId,
+		BindingID:   req.BindingId,
+		Name:        name,
+		Description: strings.TrimSpace(req.Description),
+		Action:      action,
+		Enabled:     true,
+	}
+	if err := db.Create(list).Error; err != nil {
+		return nil, fmt.Errorf("创建 IP 名单失败: %w", err)
+	}
+
+	return &types.BaseResp{Code: 200, Msg: "成功"}, nil
+}
@@ -0,0 +1,56 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CreateWafMirrorClientLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCreateWafMirrorClientLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateWafMirrorClientLogic {
+	return &CreateWafMirrorClientLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CreateWafMirrorClientLogic) CreateWafMirrorClient(req *types.WafMirrorClientReq) (resp *types.BaseResp, err error) {
+	if req == nil {
+		return nil, fmt.Errorf("镜像凭据参数不合法")
+	}
+	db := l.svcCtx.DB.WithContext(l.ctx)
+	name, kind, pinnedVersion, err := normalizeWafMirrorClient(db, 0, req.Name, req.Kind, req.PinnedVersion)
+	if err != nil {
+		return nil, err
+	}
+	token, err := generateWafMirrorToken()
+	if err != nil {
+		return nil, err
+	}
+
+	client := &model.WafMirrorClient{
+		Name:          name,
+		Token:         token,
+		Kind:          kind,
+		PinnedVersion: pinnedVersion,
+		Enabled:       true,
+		Description:   strings.TrimSpace(req.Description),
+	}
+	if err := db.Create(client).Error; err != nil {
+		return nil, fmt.Errorf("创建镜像凭据失败: %w", err)
+	}
+
+	return &types.BaseResp{Code: 200, Msg: "成功"}, nil
+}
@@ -0,0 +1,60 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CreateWafRateLimitZoneLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCreateWafRateLimitZoneLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateWafRateLimitZoneLogic {
+	return &CreateWafRateLimitZoneLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CreateWafRateLimitZoneLogic) CreateWafRateLimitZone(req *types.WafRateLimitZoneReq) (resp *types.BaseResp, err error) {
+	defer func() {
+		err = localizeWafPolicyError(err)
+	}()
+
+	if req == nil {
+		return nil, fmt.Errorf("限流区域参数不合法")
+	}
+	db := l.svcCtx.DB.WithContext(l.ctx)
+	zone, _, err := normalizeWafRateLimitZoneInput(db, wafRateLimitZoneInput{
+		PolicyID:      req.PolicyId,
+		BindingID:     req.BindingId,
+		Name:          req.Name,
+		Description:   req.Description,
+		Enabled:       true,
+		KeyType:       req.KeyType,
+		KeyHeader:     req.KeyHeader,
+		Events:        req.Events,
+		WindowSeconds: req.WindowSeconds,
+		Burst:         req.Burst,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureWafRateLimitZoneNameUnique(db, zone.Name, 0); err != nil {
+		return nil, err
+	}
+
+	if err := db.Create(zone).Error; err != nil {
+		return nil, fmt.Errorf("创建限流区域失败: %w", err)
+	}
+
+	return &types.BaseResp{Code: 200, Msg: "成功"}, nil
+}
@@ -42,26 +42,23 @@ func (l *CreateWafRuleExclusionLogic) CreateWafRuleExclusion(req *types.WafRuleE
 	if err != nil {
 		return nil, err
 	}
-	removeType := normalizePolicyRemoveType(req.RemoveType)
-	if err := validatePolicyRemoveType(removeType); err != nil {
+	removeType, removeValue, removeTarget, err := normalizeAndValidateExclusionRemoveFields(req.RemoveType, req.RemoveValue, req.RemoveTarget)
+	if err != nil {
 		return nil, err
 	}
-	removeValue := strings.TrimSpace(req.RemoveValue)
-	if removeValue == "" {
-		return nil, fmt.Errorf("移除值不能为空")
-	}
 
 	exclusion := &model.WafRuleExclusion{
-		PolicyID:    req.PolicyId,
-		Name:        strings.TrimSpace(req.Name),
-		Description: strings.TrimSpace(req.Description),
-		Enabled:     true,
-		ScopeType:   scopeType,
-		Host:        host,
-		Path:        path,
-		Method:      method,
-		RemoveType:  removeType,
-		RemoveValue: removeValue,
+		PolicyID:     req.PolicyId,
+		Name:         strings.TrimSpace(req.Name),
+		Description:  strings.TrimSpace(req.Description),
+		Enabled:      true,
+		ScopeType:    scopeType,
+		Host:         host,
+		Path:         path,
+		Method:       method,
+		RemoveType:   removeType,
+		RemoveValue:  removeValue,
+		RemoveTarget: removeTarget,
 	}
 	if req.Enabled {
 		exclusion.Enabled = true
@@ -0,0 +1,35 @@
+package caddy
+
+import (
+	"context"
+
+	"logflux/internal/caddyfile"
+	"logflux/internal/svc"
+	"logflux/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DeleteCaddyRouteLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDeleteCaddyRouteLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteCaddyRouteLogic {
+	return &DeleteCaddyRouteLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *DeleteCaddyRouteLogic) DeleteCaddyRoute(req *types.CaddyRouteDeleteReq) (resp *types.CaddySiteEditResp, err error) {
+	return newCaddySiteEditor(l.svcCtx, l.Logger, currentOperatorFromContext(l.ctx)).edit(req.ServerId, req.ConfigHash, req.DryRun, func(doc *caddyfile.Document) error {
+		site, err := caddySiteAt(doc, req.SiteIndex)
+		if err != nil {
+			return err
+		}
+		return doc.RemoveRoute(site, req.RouteIndex)
+	})
+}
@@ -0,0 +1,37 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DeleteCaddyServerGroupLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDeleteCaddyServerGroupLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteCaddyServerGroupLogic {
+	return &DeleteCaddyServerGroupLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *DeleteCaddyServerGroupLogic) DeleteCaddyServerGroup(req *types.IDReq) (resp *types.BaseResp, err error) {
+	if req == nil || req.ID == 0 {
+		return nil, fmt.Errorf("分组 ID 不能为空")
+	}
+	if err := l.svcCtx.DB.WithContext(l.ctx).Delete(&model.CaddyServerGroup{}, req.ID).Error; err != nil {
+		return nil, fmt.Errorf("删除节点分组失败: %w", err)
+	}
+
+	return &types.BaseResp{Code: 200, Msg: "成功"}, nil
+}
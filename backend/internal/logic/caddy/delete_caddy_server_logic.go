@@ -8,6 +8,7 @@ import (
 	"logflux/model"
 
 	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
 )
 
 type DeleteCaddyServerLogic struct {
@@ -25,7 +26,15 @@ func NewDeleteCaddyServerLogic(ctx context.Context, svcCtx *svc.ServiceContext)
 }
 
 func (l *DeleteCaddyServerLogic) DeleteCaddyServer(req *types.IDReq) (resp *types.BaseResp, err error) {
-	if err := l.svcCtx.DB.WithContext(l.ctx).Delete(&model.CaddyServer{}, req.ID).Error; err != nil {
+	if err := l.svcCtx.DB.WithContext(l.ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&model.CaddyServer{}, req.ID).Error; err != nil {
+			return err
+		}
+		// 同步移出所属节点分组，避免分发时引用已删除的节点
+		return tx.Model(&model.CaddyServerGroup{}).
+			Where("? = ANY(server_ids)", req.ID).
+			Update("server_ids", gorm.Expr("array_remove(server_ids, ?)", req.ID)).Error
+	}); err != nil {
 		return nil, err
 	}
 
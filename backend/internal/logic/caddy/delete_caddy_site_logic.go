@@ -0,0 +1,36 @@
+package caddy
+
+import (
+	"context"
+
+	"logflux/internal/caddyfile"
+	"logflux/internal/svc"
+	"logflux/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DeleteCaddySiteLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDeleteCaddySiteLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteCaddySiteLogic {
+	return &DeleteCaddySiteLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *DeleteCaddySiteLogic) DeleteCaddySite(req *types.CaddySiteDeleteReq) (resp *types.CaddySiteEditResp, err error) {
+	return newCaddySiteEditor(l.svcCtx, l.Logger, currentOperatorFromContext(l.ctx)).edit(req.ServerId, req.ConfigHash, req.DryRun, func(doc *caddyfile.Document) error {
+		site, err := caddySiteAt(doc, req.SiteIndex)
+		if err != nil {
+			return err
+		}
+		doc.Remove(nil, site)
+		return nil
+	})
+}
@@ -0,0 +1,47 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DeleteWafBanRuleLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDeleteWafBanRuleLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteWafBanRuleLogic {
+	return &DeleteWafBanRuleLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// DeleteWafBanRule 删除规则，已产生的封禁按原到期时间解除并保留审计记录
+func (l *DeleteWafBanRuleLogic) DeleteWafBanRule(req *types.IDReq) (resp *types.BaseResp, err error) {
+	defer func() {
+		err = localizeWafPolicyError(err)
+	}()
+
+	if req == nil || req.ID == 0 {
+		return nil, fmt.Errorf("封禁规则 ID 不能为空")
+	}
+
+	result := l.svcCtx.DB.WithContext(l.ctx).Where("id = ?", req.ID).Delete(&model.WafBanRule{})
+	if result.Error != nil {
+		return nil, fmt.Errorf("删除封禁规则失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("封禁规则不存在")
+	}
+
+	return &types.BaseResp{Code: 200, Msg: "成功"}, nil
+}
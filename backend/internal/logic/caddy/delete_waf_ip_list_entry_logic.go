@@ -0,0 +1,46 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DeleteWafIPListEntryLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDeleteWafIPListEntryLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteWafIPListEntryLogic {
+	return &DeleteWafIPListEntryLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *DeleteWafIPListEntryLogic) DeleteWafIPListEntry(req *types.IDReq) (resp *types.BaseResp, err error) {
+	defer func() {
+		err = localizeWafPolicyError(err)
+	}()
+
+	if req == nil || req.ID == 0 {
+		return nil, fmt.Errorf("IP 名单条目 ID 不能为空")
+	}
+
+	result := l.svcCtx.DB.WithContext(l.ctx).Where("id = ?", req.ID).Delete(&model.WafIPListEntry{})
+	if result.Error != nil {
+		return nil, fmt.Errorf("删除 IP 名单条目失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("IP 名单条目不存在")
+	}
+
+	return &types.BaseResp{Code: 200, Msg: "成功"}, nil
+}
@@ -0,0 +1,55 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type DeleteWafIPListLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDeleteWafIPListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteWafIPListLogic {
+	return &DeleteWafIPListLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *DeleteWafIPListLogic) DeleteWafIPList(req *types.IDReq) (resp *types.BaseResp, err error) {
+	defer func() {
+		err = localizeWafPolicyError(err)
+	}()
+
+	if req == nil || req.ID == 0 {
+		return nil, fmt.Errorf("IP 名单 ID 不能为空")
+	}
+
+	if err := l.svcCtx.DB.WithContext(l.ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ?", req.ID).Delete(&model.WafIPList{})
+		if result.Error != nil {
+			return fmt.Errorf("删除 IP 名单失败: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("IP 名单不存在")
+		}
+		if err := tx.Where("list_id = ?", req.ID).Delete(&model.WafIPListEntry{}).Error; err != nil {
+			return fmt.Errorf("删除 IP 名单条目失败: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return &types.BaseResp{Code: 200, Msg: "成功"}, nil
+}
@@ -0,0 +1,42 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DeleteWafMirrorClientLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDeleteWafMirrorClientLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteWafMirrorClientLogic {
+	return &DeleteWafMirrorClientLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *DeleteWafMirrorClientLogic) DeleteWafMirrorClient(req *types.IDReq) (resp *types.BaseResp, err error) {
+	if req == nil || req.ID == 0 {
+		return nil, fmt.Errorf("镜像凭据 ID 不能为空")
+	}
+
+	result := l.svcCtx.DB.WithContext(l.ctx).Where("id = ?", req.ID).Delete(&model.WafMirrorClient{})
+	if result.Error != nil {
+		return nil, fmt.Errorf("删除镜像凭据失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("镜像凭据不存在")
+	}
+
+	return &types.BaseResp{Code: 200, Msg: "成功"}, nil
+}
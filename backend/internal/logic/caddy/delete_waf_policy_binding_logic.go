@@ -34,6 +34,22 @@ func (l *DeleteWafPolicyBindingLogic) DeleteWafPolicyBinding(req *types.IDReq) (
 		return nil, fmt.Errorf("策略绑定 ID 不能为空")
 	}
 
+	var listCount int64
+	if err := l.svcCtx.DB.WithContext(l.ctx).Model(&model.WafIPList{}).Where("binding_id = ?", req.ID).Count(&listCount).Error; err != nil {
+		return nil, fmt.Errorf("查询绑定关联的 IP 名单失败: %w", err)
+	}
+	if listCount > 0 {
+		return nil, fmt.Errorf("策略绑定仍被 %d 个 IP 名单引用，请先调整名单作用域", listCount)
+	}
+
+	var zoneCount int64
+	if err := l.svcCtx.DB.WithContext(l.ctx).Model(&model.WafRateLimitZone{}).Where("binding_id = ?", req.ID).Count(&zoneCount).Error; err != nil {
+		return nil, fmt.Errorf("查询绑定关联的限流区域失败: %w", err)
+	}
+	if zoneCount > 0 {
+		return nil, fmt.Errorf("策略绑定仍被 %d 个限流区域引用，请先调整区域作用域", zoneCount)
+	}
+
 	result := l.svcCtx.DB.WithContext(l.ctx).Where("id = ?", req.ID).Delete(&model.WafPolicyBinding{})
 	if result.Error != nil {
 		return nil, fmt.Errorf("删除策略绑定失败: %w", result.Error)
@@ -45,6 +45,18 @@ func (l *DeleteWafPolicyLogic) DeleteWafPolicy(req *types.IDReq) (resp *types.Ba
 		if err := tx.Where("policy_id = ?", policy.ID).Delete(&model.WafPolicyRevision{}).Error; err != nil {
 			return fmt.Errorf("删除策略版本失败: %w", err)
 		}
+		if err := tx.Where("policy_id = ?", policy.ID).Delete(&model.WafCustomRule{}).Error; err != nil {
+			return fmt.Errorf("删除策略自定义规则失败: %w", err)
+		}
+		if err := tx.Where("list_id IN (?)", tx.Model(&model.WafIPList{}).Select("id").Where("policy_id = ?", policy.ID)).Delete(&model.WafIPListEntry{}).Error; err != nil {
+			return fmt.Errorf("删除策略 IP 名单条目失败: %w", err)
+		}
+		if err := tx.Where("policy_id = ?", policy.ID).Delete(&model.WafIPList{}).Error; err != nil {
+			return fmt.Errorf("删除策略 IP 名单失败: %w", err)
+		}
+		if err := tx.Where("policy_id = ?", policy.ID).Delete(&model.WafRateLimitZone{}).Error; err != nil {
+			return fmt.Errorf("删除策略限流区域失败: %w", err)
+		}
 
 		if err := tx.Delete(&policy).Error; err != nil {
 			return fmt.Errorf("删除策略失败: %w", err)
@@ -0,0 +1,46 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DeleteWafRateLimitZoneLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDeleteWafRateLimitZoneLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteWafRateLimitZoneLogic {
+	return &DeleteWafRateLimitZoneLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *DeleteWafRateLimitZoneLogic) DeleteWafRateLimitZone(req *types.IDReq) (resp *types.BaseResp, err error) {
+	defer func() {
+		err = localizeWafPolicyError(err)
+	}()
+
+	if req == nil || req.ID == 0 {
+		return nil, fmt.Errorf("限流区域 ID 不能为空")
+	}
+
+	result := l.svcCtx.DB.WithContext(l.ctx).Where("id = ?", req.ID).Delete(&model.WafRateLimitZone{})
+	if result.Error != nil {
+		return nil, fmt.Errorf("删除限流区域失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("限流区域不存在")
+	}
+
+	return &types.BaseResp{Code: 200, Msg: "成功"}, nil
+}
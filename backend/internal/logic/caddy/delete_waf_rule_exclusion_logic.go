@@ -9,6 +9,7 @@ import (
 	"logflux/model"
 
 	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
 )
 
 type DeleteWafRuleExclusionLogic struct {
@@ -34,12 +35,24 @@ func (l *DeleteWafRuleExclusionLogic) DeleteWafRuleExclusion(req *types.IDReq) (
 		return nil, fmt.Errorf("策略排除规则 ID 不能为空")
 	}
 
-	result := l.svcCtx.DB.WithContext(l.ctx).Where("id = ?", req.ID).Delete(&model.WafRuleExclusion{})
-	if result.Error != nil {
-		return nil, fmt.Errorf("删除策略排除规则失败: %w", result.Error)
-	}
-	if result.RowsAffected == 0 {
-		return nil, fmt.Errorf("策略排除规则不存在")
+	err = l.svcCtx.DB.WithContext(l.ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ?", req.ID).Delete(&model.WafRuleExclusion{})
+		if result.Error != nil {
+			return fmt.Errorf("删除策略排除规则失败: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("策略排除规则不存在")
+		}
+		// 解除误报反馈上的关联，便于重新生成建议
+		if err := tx.Model(&model.WafPolicyFalsePositiveFeedback{}).
+			Where("exclusion_id = ?", req.ID).
+			Update("exclusion_id", nil).Error; err != nil {
+			return fmt.Errorf("解除误报反馈关联失败: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return &types.BaseResp{Code: 200, Msg: "成功"}, nil
@@ -0,0 +1,62 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DiffCaddyConfigLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDiffCaddyConfigLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DiffCaddyConfigLogic {
+	return &DiffCaddyConfigLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *DiffCaddyConfigLogic) DiffCaddyConfig(req *types.CaddyConfigDiffReq) (resp *types.CaddyConfigDiffResp, err error) {
+	var server model.CaddyServer
+	if err := l.svcCtx.DB.WithContext(l.ctx).First(&server, req.ServerId).Error; err != nil {
+		return nil, fmt.Errorf("服务器不存在")
+	}
+	if req.FromId == 0 {
+		return nil, fmt.Errorf("请选择要对比的历史版本")
+	}
+
+	from, err := l.loadSide(&server, req.FromId)
+	if err != nil {
+		return nil, err
+	}
+	to, err := l.loadSide(&server, req.ToId)
+	if err != nil {
+		return nil, err
+	}
+	return buildCaddyConfigDiff(&server, req.Format, from, to)
+}
+
+// loadSide historyID 为 0 时取当前保存的配置
+func (l *DiffCaddyConfigLogic) loadSide(server *model.CaddyServer, historyID uint) (caddyConfigDiffSide, error) {
+	if historyID == 0 {
+		config, _, err := newCaddyConfigApplyService(l.svcCtx, l.Logger).loadCurrent(server)
+		if err != nil {
+			return caddyConfigDiffSide{}, err
+		}
+		return caddyConfigDiffSide{Label: "当前配置", Config: config}, nil
+	}
+	var history model.CaddyConfigHistory
+	if err := l.svcCtx.DB.WithContext(l.ctx).Where("id = ? AND server_id = ?", historyID, server.ID).First(&history).Error; err != nil {
+		return caddyConfigDiffSide{}, fmt.Errorf("历史记录 #%d 不存在", historyID)
+	}
+	return caddyConfigDiffSide{ID: history.ID, Label: fmt.Sprintf("#%d", history.ID), Config: history.Config}, nil
+}
@@ -0,0 +1,107 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/internal/utils/safego"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DistributeWafLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDistributeWafLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DistributeWafLogic {
+	return &DistributeWafLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// DistributeWaf 将 WAF 版本或策略分发到节点分组，立即返回聚合任务 ID，节点状态通过部署列表查询
+func (l *DistributeWafLogic) DistributeWaf(req *types.WafDistributeReq) (resp *types.WafDistributeResp, err error) {
+	// 分发没有对应的变更申请，启用审批后在开始前拒绝，避免分发到一半才失败
+	if err := ensureDirectCaddyChangeAllowed(l.svcCtx); err != nil {
+		return nil, err
+	}
+	if req == nil || req.TargetId == 0 {
+		return nil, fmt.Errorf("分发目标不能为空")
+	}
+	targetType, err := normalizeWafDistributeTargetType(req.TargetType)
+	if err != nil {
+		return nil, err
+	}
+	strategy, err := normalizeWafDistributeStrategy(req.Strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	db := l.svcCtx.DB.WithContext(l.ctx)
+	if err := ensureNoRunningWafDistribution(db); err != nil {
+		return nil, err
+	}
+	servers, err := resolveWafDistributionServers(db, req.GroupId)
+	if err != nil {
+		return nil, err
+	}
+
+	// 分发在后台执行，保留操作人等上下文但不随请求结束而取消
+	helper := newWafLogicHelper(context.WithoutCancel(l.ctx), l.svcCtx, l.Logger)
+	spec := wafDistributionSpec{
+		TargetType:    targetType,
+		TargetID:      req.TargetId,
+		GroupID:       req.GroupId,
+		Strategy:      strategy,
+		StopOnFailure: req.StopOnFailure,
+	}
+
+	switch targetType {
+	case wafDistributeTargetRelease:
+		var release model.WafRelease
+		if err := db.First(&release, req.TargetId).Error; err != nil {
+			return nil, fmt.Errorf("版本不存在")
+		}
+		if normalizeWafKind(release.Kind) == wafKindCorazaEngine {
+			return nil, fmt.Errorf("Coraza 引擎不支持在线激活，仅支持版本检查")
+		}
+		if err := helper.ensureStoreDirs(); err != nil {
+			return nil, err
+		}
+
+		spec.SourceID = release.SourceID
+		spec.ReleaseID = release.ID
+		dist, err := helper.startWafDistribution(spec, servers)
+		if err != nil {
+			return nil, err
+		}
+		helper.attachJobRuleDiff(dist.job, &release)
+		safego.New(helper.ctx, "多节点分发 WAF 版本").Go(func() {
+			dist.runRelease(&release)
+		})
+		return &types.WafDistributeResp{JobId: dist.job.ID}, nil
+	default:
+		publish := NewPolicyPublishService(helper.ctx, l.svcCtx)
+		candidates, err := publish.BuildPublishCandidates(req.TargetId, servers)
+		if err != nil {
+			return nil, err
+		}
+
+		dist, err := helper.startWafDistribution(spec, servers)
+		if err != nil {
+			return nil, err
+		}
+		operator := currentOperatorFromContext(l.ctx)
+		safego.New(helper.ctx, "多节点分发 WAF 策略").Go(func() {
+			dist.runPolicy(publish, candidates, operator)
+		})
+		return &types.WafDistributeResp{JobId: dist.job.ID}, nil
+	}
+}
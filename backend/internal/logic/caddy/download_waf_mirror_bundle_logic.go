@@ -0,0 +1,39 @@
+package caddy
+
+import (
+	"context"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DownloadWafMirrorBundleLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDownloadWafMirrorBundleLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DownloadWafMirrorBundleLogic {
+	return &DownloadWafMirrorBundleLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// DownloadWafMirrorBundle 返回签名离线包路径与下载文件名，供下游手工导入
+func (l *DownloadWafMirrorBundleLogic) DownloadWafMirrorBundle(req *types.WafMirrorFileReq) (filePath string, fileName string, err error) {
+	helper := newWafLogicHelper(l.ctx, l.svcCtx, l.Logger)
+	client, release, err := helper.resolveWafMirrorRequest(req.Authorization, req.Kind, req.Version)
+	if err != nil {
+		return "", "", err
+	}
+	bundlePath, err := helper.exportReleaseBundle(release)
+	if err != nil {
+		return "", "", err
+	}
+	helper.touchWafMirrorClient(client, release.Version+"/bundle.tar.gz")
+	return bundlePath, wafBundleDownloadName(release), nil
+}
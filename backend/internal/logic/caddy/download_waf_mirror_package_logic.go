@@ -0,0 +1,39 @@
+package caddy
+
+import (
+	"context"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DownloadWafMirrorPackageLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDownloadWafMirrorPackageLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DownloadWafMirrorPackageLogic {
+	return &DownloadWafMirrorPackageLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// DownloadWafMirrorPackage 返回版本归档路径与下载文件名
+func (l *DownloadWafMirrorPackageLogic) DownloadWafMirrorPackage(req *types.WafMirrorFileReq) (filePath string, fileName string, err error) {
+	helper := newWafLogicHelper(l.ctx, l.svcCtx, l.Logger)
+	client, release, err := helper.resolveWafMirrorRequest(req.Authorization, req.Kind, req.Version)
+	if err != nil {
+		return "", "", err
+	}
+	archive, err := helper.releaseArchive(release)
+	if err != nil {
+		return "", "", err
+	}
+	helper.touchWafMirrorClient(client, release.Version+"/package.tar.gz")
+	return archive.Path, sanitizeToken(release.Version) + ".tar.gz", nil
+}
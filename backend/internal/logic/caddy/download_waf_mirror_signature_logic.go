@@ -0,0 +1,43 @@
+package caddy
+
+import (
+	"context"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DownloadWafMirrorSignatureLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDownloadWafMirrorSignatureLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DownloadWafMirrorSignatureLogic {
+	return &DownloadWafMirrorSignatureLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// DownloadWafMirrorSignature 返回版本归档的 minisign 签名路径与下载文件名
+func (l *DownloadWafMirrorSignatureLogic) DownloadWafMirrorSignature(req *types.WafMirrorFileReq) (filePath string, fileName string, err error) {
+	helper := newWafLogicHelper(l.ctx, l.svcCtx, l.Logger)
+	client, release, err := helper.resolveWafMirrorRequest(req.Authorization, req.Kind, req.Version)
+	if err != nil {
+		return "", "", err
+	}
+	archive, err := helper.releaseArchive(release)
+	if err != nil {
+		return "", "", err
+	}
+	signaturePath, err := helper.releaseArchiveSignature(release, archive)
+	if err != nil {
+		return "", "", err
+	}
+	helper.touchWafMirrorClient(client, release.Version+"/package.tar.gz.minisig")
+	return signaturePath, sanitizeToken(release.Version) + ".tar.gz.minisig", nil
+}
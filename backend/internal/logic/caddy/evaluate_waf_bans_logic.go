@@ -0,0 +1,41 @@
+package caddy
+
+import (
+	"context"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type EvaluateWafBansLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewEvaluateWafBansLogic(ctx context.Context, svcCtx *svc.ServiceContext) *EvaluateWafBansLogic {
+	return &EvaluateWafBansLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// EvaluateWafBans 立即执行封禁规则，并强制将封禁名单与 Caddy 配置对齐
+func (l *EvaluateWafBansLogic) EvaluateWafBans() (resp *types.BaseResp, err error) {
+	defer func() {
+		err = localizeWafPolicyError(err)
+	}()
+
+	service := NewWafBanService(l.ctx, l.svcCtx)
+	if err := service.EvaluateActive(); err != nil {
+		return nil, err
+	}
+	if err := service.Sync(); err != nil {
+		return nil, err
+	}
+
+	return &types.BaseResp{Code: 200, Msg: "成功"}, nil
+}
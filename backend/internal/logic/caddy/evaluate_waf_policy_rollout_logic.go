@@ -0,0 +1,47 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type EvaluateWafPolicyRolloutLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewEvaluateWafPolicyRolloutLogic(ctx context.Context, svcCtx *svc.ServiceContext) *EvaluateWafPolicyRolloutLogic {
+	return &EvaluateWafPolicyRolloutLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// EvaluateWafPolicyRollout 立即重新统计灰度指标
+func (l *EvaluateWafPolicyRolloutLogic) EvaluateWafPolicyRollout(req *types.IDReq) (resp *types.WafPolicyRolloutItem, err error) {
+	defer func() {
+		err = localizeWafPolicyError(err)
+	}()
+	if req == nil || req.ID == 0 {
+		return nil, fmt.Errorf("灰度发布 ID 不能为空")
+	}
+
+	var rollout model.WafPolicyRollout
+	if err := l.svcCtx.DB.WithContext(l.ctx).Preload("Policy").First(&rollout, req.ID).Error; err != nil {
+		return nil, fmt.Errorf("灰度发布不存在")
+	}
+	if err := NewWafRolloutService(l.ctx, l.svcCtx).Evaluate(&rollout); err != nil {
+		return nil, err
+	}
+
+	item := toWafPolicyRolloutItem(&rollout, rollout.Policy.Name)
+	return &item, nil
+}
@@ -0,0 +1,46 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/internal/xerr"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ExportWafReleaseBundleLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewExportWafReleaseBundleLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ExportWafReleaseBundleLogic {
+	return &ExportWafReleaseBundleLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ExportWafReleaseBundle 导出版本的签名离线包，返回文件路径与下载文件名
+func (l *ExportWafReleaseBundleLogic) ExportWafReleaseBundle(req *types.WafReleaseExportReq) (filePath string, fileName string, err error) {
+	helper := newWafLogicHelper(l.ctx, l.svcCtx, l.Logger)
+
+	var release model.WafRelease
+	if err := helper.svcCtx.DB.WithContext(helper.ctx).First(&release, req.ID).Error; err != nil {
+		return "", "", xerr.NewCodeError(xerr.NotFound, "版本不存在")
+	}
+	if release.Status == wafReleaseStatusFailed || release.Status == wafReleaseStatusDownloaded {
+		return "", "", fmt.Errorf("仅已校验的版本可以导出")
+	}
+
+	bundlePath, err := helper.exportReleaseBundle(&release)
+	if err != nil {
+		return "", "", err
+	}
+	return bundlePath, wafBundleDownloadName(&release), nil
+}
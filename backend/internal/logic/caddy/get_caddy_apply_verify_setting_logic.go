@@ -0,0 +1,67 @@
+package caddy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type GetCaddyApplyVerifySettingLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetCaddyApplyVerifySettingLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetCaddyApplyVerifySettingLogic {
+	return &GetCaddyApplyVerifySettingLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetCaddyApplyVerifySettingLogic) GetCaddyApplyVerifySetting(req *types.CaddyConfigReq) (resp *types.CaddyApplyVerifySettingResp, err error) {
+	db := l.svcCtx.DB.WithContext(l.ctx)
+	var server model.CaddyServer
+	if err := db.First(&server, req.ServerId).Error; err != nil {
+		return nil, fmt.Errorf("服务器不存在")
+	}
+
+	setting := model.CaddyApplyVerifySetting{MaxErrorRate: 0.5, MinRequests: 20}
+	if err := db.Where("server_id = ?", server.ID).First(&setting).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("查询配置校验设置失败: %w", err)
+	}
+	var probes []model.CaddyHealthProbe
+	if err := db.Where("server_id = ?", server.ID).Order("id asc").Find(&probes).Error; err != nil {
+		return nil, fmt.Errorf("查询健康探测失败: %w", err)
+	}
+
+	resp = &types.CaddyApplyVerifySettingResp{
+		Enabled:            setting.Enabled,
+		ErrorWindowSeconds: setting.ErrorWindowSeconds,
+		MaxErrorRate:       setting.MaxErrorRate,
+		MinRequests:        setting.MinRequests,
+		Probes:             make([]types.CaddyHealthProbeItem, 0, len(probes)),
+	}
+	for _, probe := range probes {
+		resp.Probes = append(resp.Probes, types.CaddyHealthProbeItem{
+			ID:             probe.ID,
+			Name:           probe.Name,
+			Url:            probe.URL,
+			Method:         probe.Method,
+			Host:           probe.Host,
+			ExpectStatus:   probe.ExpectStatus,
+			BodyPattern:    probe.BodyPattern,
+			TimeoutSeconds: probe.TimeoutSeconds,
+			Enabled:        probe.Enabled,
+		})
+	}
+	return resp, nil
+}
@@ -0,0 +1,74 @@
+package caddy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type GetCaddyChangeRequestLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetCaddyChangeRequestLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetCaddyChangeRequestLogic {
+	return &GetCaddyChangeRequestLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetCaddyChangeRequestLogic) GetCaddyChangeRequest(req *types.IDReq) (resp *types.CaddyChangeRequestDetailResp, err error) {
+	db := l.svcCtx.DB.WithContext(l.ctx)
+	var request model.CaddyChangeRequest
+	if err := db.First(&request, req.ID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("变更申请不存在")
+		}
+		return nil, fmt.Errorf("查询变更申请失败: %w", err)
+	}
+
+	var comments []model.CaddyChangeRequestComment
+	if err := db.Where("request_id = ?", request.ID).Order("id asc").Find(&comments).Error; err != nil {
+		return nil, fmt.Errorf("查询变更申请评论失败: %w", err)
+	}
+	commentItems := make([]types.CaddyChangeRequestCommentItem, 0, len(comments))
+	for _, comment := range comments {
+		commentItems = append(commentItems, types.CaddyChangeRequestCommentItem{
+			ID:         comment.ID,
+			Action:     comment.Action,
+			Author:     comment.Author,
+			AuthorName: comment.AuthorName,
+			Content:    comment.Content,
+			CreatedAt:  formatTime(comment.CreatedAt),
+		})
+	}
+
+	// 尚未应用的申请提示节点配置是否已在提交后被修改
+	stale := false
+	switch request.Status {
+	case caddyChangeStatusPending, caddyChangeStatusScheduled, caddyChangeStatusFailed:
+		var server model.CaddyServer
+		if err := db.Select("id", "config").First(&server, request.ServerID).Error; err == nil {
+			stale = hashConfig(server.Config) != request.BaseHash
+		}
+	}
+
+	return &types.CaddyChangeRequestDetailResp{
+		Request:  describeCaddyChangeRequests(db, []model.CaddyChangeRequest{request})[0],
+		Config:   request.Config,
+		Modules:  request.Modules,
+		Diff:     request.Diff,
+		Stale:    stale,
+		Comments: commentItems,
+	}, nil
+}
@@ -38,6 +38,16 @@ func (l *GetCaddyConfigHistoryDetailLogic) GetCaddyConfigHistoryDetail(req *type
 		Hash:      history.Hash,
 		Config:    history.Config,
 		Modules:   history.Modules,
+		Source:    history.Source,
+		Operator:  history.Operator,
+		Message:   history.Message,
+		KnownGood: history.KnownGood,
+		TaggedBy:  history.TaggedBy,
+		TaggedAt:  formatNullableTime(history.TaggedAt),
 		CreatedAt: history.CreatedAt.Format("2006-01-02 15:04:05"),
+
+		VerifyStatus:  history.VerifyStatus,
+		VerifyMessage: history.VerifyMessage,
+		VerifiedAt:    formatNullableTime(history.VerifiedAt),
 	}, nil
 }
@@ -2,6 +2,7 @@ package caddy
 
 import (
 	"context"
+	"strings"
 
 	"logflux/internal/svc"
 	"logflux/internal/types"
@@ -29,6 +30,12 @@ func (l *GetCaddyConfigHistoryLogic) GetCaddyConfigHistory(req *types.CaddyConfi
 	var total int64
 
 	db := l.svcCtx.DB.WithContext(l.ctx).Model(&model.CaddyConfigHistory{}).Where("server_id = ?", req.ServerId)
+	if source := strings.ToLower(strings.TrimSpace(req.Source)); source != "" {
+		db = db.Where("source = ?", source)
+	}
+	if req.KnownGood {
+		db = db.Where("known_good = ?", true)
+	}
 
 	if err := db.Count(&total).Error; err != nil {
 		return nil, err
@@ -41,13 +48,7 @@ func (l *GetCaddyConfigHistoryLogic) GetCaddyConfigHistory(req *types.CaddyConfi
 
 	list := make([]types.CaddyConfigHistoryItem, 0, len(history))
 	for _, item := range history {
-		list = append(list, types.CaddyConfigHistoryItem{
-			ID:        item.ID,
-			ServerId:  item.ServerID,
-			Action:    item.Action,
-			Hash:      item.Hash,
-			CreatedAt: item.CreatedAt.Format("2006-01-02 15:04:05"),
-		})
+		list = append(list, toCaddyConfigHistoryItem(item))
 	}
 
 	return &types.CaddyConfigHistoryListResp{
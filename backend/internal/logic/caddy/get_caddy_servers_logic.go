@@ -33,11 +33,12 @@ func (l *GetCaddyServersLogic) GetCaddyServers() (resp *types.CaddyServerListRes
 	var list []types.CaddyServerItem
 	for _, s := range servers {
 		list = append(list, types.CaddyServerItem{
-			ID:        s.ID,
-			Name:      s.Name,
-			Url:       s.Url,
-			Type:      s.Type,
-			CreatedAt: s.CreatedAt.Format("2006-01-02 15:04:05"),
+			ID:            s.ID,
+			Name:          s.Name,
+			Url:           s.Url,
+			Type:          s.Type,
+			CaddyfilePath: s.CaddyfilePath,
+			CreatedAt:     s.CreatedAt.Format("2006-01-02 15:04:05"),
 		})
 	}
 
@@ -0,0 +1,39 @@
+package caddy
+
+import (
+	"context"
+	"strings"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetWafBundleKeyLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetWafBundleKeyLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetWafBundleKeyLogic {
+	return &GetWafBundleKeyLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetWafBundleKeyLogic) GetWafBundleKey() (resp *types.WafBundleKeyResp, err error) {
+	helper := newWafLogicHelper(l.ctx, l.svcCtx, l.Logger)
+	signer, err := helper.bundleSigner()
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.WafBundleKeyResp{
+		KeyId:       signer.KeyID(),
+		PublicKey:   signer.PublicKey(),
+		TrustedKeys: strings.TrimSpace(l.svcCtx.Config.Waf.BundleTrustedKeys),
+	}, nil
+}
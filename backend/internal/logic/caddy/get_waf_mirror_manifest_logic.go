@@ -0,0 +1,57 @@
+package caddy
+
+import (
+	"context"
+	"strings"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/internal/waf"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetWafMirrorManifestLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetWafMirrorManifestLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetWafMirrorManifestLogic {
+	return &GetWafMirrorManifestLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetWafMirrorManifestLogic) GetWafMirrorManifest(req *types.WafMirrorManifestReq) (resp *types.WafMirrorManifestResp, err error) {
+	helper := newWafLogicHelper(l.ctx, l.svcCtx, l.Logger)
+	client, release, err := helper.resolveWafMirrorRequest(req.Authorization, req.Kind, req.Version)
+	if err != nil {
+		return nil, err
+	}
+	archive, err := helper.releaseArchive(release)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := helper.bundleSigner()
+	if err != nil {
+		return nil, err
+	}
+	helper.touchWafMirrorClient(client, "manifest")
+
+	packageURL := wafMirrorFileURL(release.Kind, release.Version, "package.tar.gz")
+	return &types.WafMirrorManifestResp{
+		Kind:             normalizeWafKind(release.Kind),
+		Version:          release.Version,
+		Pinned:           strings.TrimSpace(client.PinnedVersion) != "",
+		PackageSha256:    archive.SHA256,
+		PackageSizeBytes: archive.SizeBytes,
+		PackageUrl:       packageURL,
+		SignatureUrl:     packageURL + ".minisig",
+		SignatureType:    waf.SignatureTypeMinisign,
+		BundleUrl:        wafMirrorFileURL(release.Kind, release.Version, "bundle.tar.gz"),
+		KeyId:            signer.KeyID(),
+	}, nil
+}
@@ -0,0 +1,83 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type GetWafPolicyFalsePositiveFeedbackSuggestionLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetWafPolicyFalsePositiveFeedbackSuggestionLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetWafPolicyFalsePositiveFeedbackSuggestionLogic {
+	return &GetWafPolicyFalsePositiveFeedbackSuggestionLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetWafPolicyFalsePositiveFeedbackSuggestionLogic) GetWafPolicyFalsePositiveFeedbackSuggestion(req *types.WafPolicyFalsePositiveFeedbackSuggestionReq) (resp *types.WafPolicyFalsePositiveFeedbackSuggestionResp, err error) {
+	defer func() {
+		err = localizeWafPolicyError(err)
+	}()
+
+	if req == nil || req.ID == 0 {
+		return nil, fmt.Errorf("误报反馈 ID 不能为空")
+	}
+
+	var feedback model.WafPolicyFalsePositiveFeedback
+	if err := l.svcCtx.DB.WithContext(l.ctx).First(&feedback, req.ID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("未找到误报反馈记录")
+		}
+		return nil, fmt.Errorf("查询误报反馈失败: %w", err)
+	}
+
+	suggestion, err := suggestWafFeedbackExclusion(l.svcCtx.DB.WithContext(l.ctx), &feedback)
+	if err != nil {
+		return nil, err
+	}
+
+	exclusionDirectives, err := buildWafRuleExclusionDirectives([]model.WafRuleExclusion{suggestion.Exclusion})
+	if err != nil {
+		return nil, err
+	}
+	preview, err := NewPreviewWafPolicyLogic(l.ctx, l.svcCtx).PreviewWafPolicyWithExclusions(suggestion.Exclusion.PolicyID, suggestion.Exclusion)
+	if err != nil {
+		return nil, err
+	}
+
+	candidate := suggestion.Exclusion
+	return &types.WafPolicyFalsePositiveFeedbackSuggestionResp{
+		FeedbackId:        feedback.ID,
+		ExclusionId:       derefUint(feedback.ExclusionID),
+		MatchedEventCount: suggestion.EventCount,
+		RuleHits:          suggestion.RuleHits,
+		Candidate: types.WafRuleExclusionReq{
+			PolicyId:     candidate.PolicyID,
+			Name:         candidate.Name,
+			Description:  candidate.Description,
+			Enabled:      candidate.Enabled,
+			ScopeType:    candidate.ScopeType,
+			Host:         candidate.Host,
+			Path:         candidate.Path,
+			Method:       candidate.Method,
+			RemoveType:   candidate.RemoveType,
+			RemoveValue:  candidate.RemoveValue,
+			RemoveTarget: candidate.RemoveTarget,
+		},
+		Rationale:           suggestion.Rationale,
+		ExclusionDirectives: exclusionDirectives,
+		Directives:          preview.Directives,
+	}, nil
+}
@@ -72,6 +72,11 @@ func (l *GetWafPolicyStatsLogic) GetWafPolicyStats(req *types.WafPolicyStatsReq)
 			TopHosts:   []types.WafPolicyStatsDimensionItem{},
 			TopPaths:   []types.WafPolicyStatsDimensionItem{},
 			TopMethods: []types.WafPolicyStatsDimensionItem{},
+
+			TopRules:              []types.WafPolicyStatsRuleItem{},
+			TopTags:               []types.WafPolicyStatsDimensionItem{},
+			RuleTrend:             []types.WafPolicyStatsRuleTrendItem{},
+			AnomalyScoreHistogram: buildWafScoreHistogram(nil, 0),
 		}, nil
 	}
 
@@ -145,6 +150,16 @@ func (l *GetWafPolicyStatsLogic) GetWafPolicyStats(req *types.WafPolicyStatsReq)
 		return nil, err
 	}
 
+	policyID := uint(0)
+	if req != nil {
+		policyID = req.PolicyId
+	}
+	threshold := wafRuleStatsThreshold(policies, policyID)
+	ruleStats, err := l.queryWafRuleStats(startTime, endTime, intervalSec, policyID, threshold, topN, drillFilter)
+	if err != nil {
+		return nil, err
+	}
+
 	return &types.WafPolicyStatsResp{
 		Range: types.DashboardRange{
 			StartTime:   formatTime(startTime),
@@ -157,6 +172,12 @@ func (l *GetWafPolicyStatsLogic) GetWafPolicyStats(req *types.WafPolicyStatsReq)
 		TopHosts:   topHosts,
 		TopPaths:   topPaths,
 		TopMethods: topMethods,
+
+		TopRules:              ruleStats.TopRules,
+		TopTags:               ruleStats.TopTags,
+		RuleTrend:             ruleStats.RuleTrend,
+		AnomalyScoreHistogram: ruleStats.Histogram,
+		AnomalyThreshold:      threshold,
 	}, nil
 }
 
@@ -164,6 +185,14 @@ const wafPolicyStatsBlockedStatusSQL = "403,406,429"
 
 var wafPolicyStatsBlockedStatuses = []int{403, 406, 429}
 
+// WafBlockedStatuses 返回 WAF 统计视为拦截的状态码，供报表等其他模块保持同一口径
+func WafBlockedStatuses() []int {
+	return append([]int(nil), wafPolicyStatsBlockedStatuses...)
+}
+
+// wafPolicyStatsRateLimitedStatus 为 rate_limit 区域触发时返回的状态码
+const wafPolicyStatsRateLimitedStatus = 429
+
 const (
 	wafPolicyStatsDimensionEmptyHost = "(empty)"
 	wafPolicyStatsDimensionPathRoot  = "/"
@@ -334,6 +363,12 @@ func (l *GetWafPolicyStatsLogic) queryPolicyStatsItem(
 	}
 	item.SuspectedFalsePositiveCount = suspectedCount
 
+	rateLimitedCount, err := countWafPolicyRateLimited(scoped)
+	if err != nil {
+		return item, err
+	}
+	item.RateLimitedCount = rateLimitedCount
+
 	return item, nil
 }
 
@@ -369,6 +404,12 @@ func (l *GetWafPolicyStatsLogic) queryRangeSummary(startTime, endTime time.Time,
 	}
 	summary.SuspectedFalsePositiveCount = suspectedCount
 
+	rateLimitedCount, err := countWafPolicyRateLimited(base)
+	if err != nil {
+		return summary, err
+	}
+	summary.RateLimitedCount = rateLimitedCount
+
 	return summary, nil
 }
 
@@ -384,9 +425,10 @@ func (l *GetWafPolicyStatsLogic) queryWafPolicyTrend(
 	}
 
 	type trendRow struct {
-		Bucket       int64 `gorm:"column:bucket"`
-		HitCount     int64 `gorm:"column:hit_count"`
-		BlockedCount int64 `gorm:"column:blocked_count"`
+		Bucket           int64 `gorm:"column:bucket"`
+		HitCount         int64 `gorm:"column:hit_count"`
+		BlockedCount     int64 `gorm:"column:blocked_count"`
+		RateLimitedCount int64 `gorm:"column:rate_limited_count"`
 	}
 
 	db := l.svcCtx.DB.WithContext(l.ctx).Model(&model.CaddyLog{}).Where("log_time BETWEEN ? AND ?", startTime, endTime)
@@ -399,8 +441,8 @@ func (l *GetWafPolicyStatsLogic) queryWafPolicyTrend(
 	if err := db.
 		Select(
 			fmt.Sprintf(
-				"floor(extract(epoch from log_time) / %d) * %d AS bucket, COUNT(*) AS hit_count, COALESCE(SUM(CASE WHEN status IN (%s) THEN 1 ELSE 0 END), 0) AS blocked_count",
-				intervalSec, intervalSec, wafPolicyStatsBlockedStatusSQL,
+				"floor(extract(epoch from log_time) / %d) * %d AS bucket, COUNT(*) AS hit_count, COALESCE(SUM(CASE WHEN status IN (%s) THEN 1 ELSE 0 END), 0) AS blocked_count, COALESCE(SUM(CASE WHEN status = %d THEN 1 ELSE 0 END), 0) AS rate_limited_count",
+				intervalSec, intervalSec, wafPolicyStatsBlockedStatusSQL, wafPolicyStatsRateLimitedStatus,
 			),
 		).
 		Group("bucket").
@@ -420,10 +462,11 @@ func (l *GetWafPolicyStatsLogic) queryWafPolicyTrend(
 	for bucket := startBucket; bucket <= endBucket; bucket += int64(intervalSec) {
 		row := bucketMap[bucket]
 		series = append(series, types.WafPolicyStatsTrendItem{
-			Time:         time.Unix(bucket, 0).Format("01-02 15:04"),
-			HitCount:     row.HitCount,
-			BlockedCount: row.BlockedCount,
-			AllowedCount: row.HitCount - row.BlockedCount,
+			Time:             time.Unix(bucket, 0).Format("01-02 15:04"),
+			HitCount:         row.HitCount,
+			BlockedCount:     row.BlockedCount,
+			AllowedCount:     row.HitCount - row.BlockedCount,
+			RateLimitedCount: row.RateLimitedCount,
 		})
 	}
 
@@ -610,6 +653,18 @@ func buildWafPolicyPathMatchCondition(path string) (string, []interface{}) {
 	return "(uri = ? OR uri LIKE ? OR uri LIKE ?)", []interface{}{normalized, normalized + "/%", normalized + "?%"}
 }
 
+// countWafPolicyRateLimited 统计被限流区域拒绝的请求数，使用独立会话避免叠加调用方已有的状态条件
+func countWafPolicyRateLimited(query *gorm.DB) (int64, error) {
+	if query == nil {
+		return 0, nil
+	}
+	var count int64
+	if err := query.Session(&gorm.Session{}).Where("status = ?", wafPolicyStatsRateLimitedStatus).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("统计限流命中数失败: %w", err)
+	}
+	return count, nil
+}
+
 func countWafPolicySuspectedFalsePositives(query *gorm.DB) (int64, error) {
 	if query == nil {
 		return 0, nil
@@ -0,0 +1,72 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetWafReleaseDiffLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetWafReleaseDiffLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetWafReleaseDiffLogic {
+	return &GetWafReleaseDiffLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetWafReleaseDiffLogic) GetWafReleaseDiff(req *types.WafReleaseDiffReq) (resp *types.WafReleaseDiffResp, err error) {
+	helper := newWafLogicHelper(l.ctx, l.svcCtx, l.Logger)
+	db := helper.svcCtx.DB.WithContext(helper.ctx)
+
+	var target model.WafRelease
+	if err := db.First(&target, req.ID).Error; err != nil {
+		return nil, fmt.Errorf("版本不存在")
+	}
+	if normalizeWafKind(target.Kind) == wafKindCorazaEngine {
+		return nil, fmt.Errorf("Coraza 引擎版本不包含规则文件")
+	}
+
+	var base *model.WafRelease
+	if req.BaseId > 0 {
+		var selected model.WafRelease
+		if err := db.First(&selected, req.BaseId).Error; err != nil {
+			return nil, fmt.Errorf("对比版本不存在")
+		}
+		if normalizeWafKind(selected.Kind) != normalizeWafKind(target.Kind) {
+			return nil, fmt.Errorf("只能对比同类型的版本")
+		}
+		base = &selected
+	} else {
+		active, err := findActiveWafRelease(db, target.Kind)
+		if err != nil {
+			return nil, err
+		}
+		if active == nil {
+			return nil, fmt.Errorf("当前没有激活版本，请指定对比版本")
+		}
+		base = active
+	}
+
+	diff, err := helper.diffWafReleases(base, &target)
+	if err != nil {
+		return nil, fmt.Errorf("计算规则差异失败: %w", err)
+	}
+
+	return &types.WafReleaseDiffResp{
+		Summary:  buildWafRuleDiffSummary(base, &target, diff),
+		Added:    toWafRuleDiffRules(diff.Added),
+		Removed:  toWafRuleDiffRules(diff.Removed),
+		Modified: toWafRuleDiffChanges(diff.Modified),
+	}, nil
+}
@@ -0,0 +1,170 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/internal/waf"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ImportWafBundleLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewImportWafBundleLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ImportWafBundleLogic {
+	return &ImportWafBundleLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ImportWafBundle 导入其他实例导出的签名离线包，签名与校验和均通过后才落盘为新版本
+func (l *ImportWafBundleLogic) ImportWafBundle(req *types.WafBundleImportReq) (resp *types.BaseResp, err error) {
+	helper := newWafLogicHelper(l.ctx, l.svcCtx, l.Logger)
+
+	if err := helper.ensureStoreDirs(); err != nil {
+		return nil, err
+	}
+
+	tempPath, _ := l.ctx.Value(wafUploadTempPathCtxKey).(string)
+	tempPath = strings.TrimSpace(tempPath)
+	if tempPath == "" {
+		return nil, fmt.Errorf("上传文件不能为空")
+	}
+	safePath, safeErr := helper.ensurePathInWorkDir(tempPath)
+	if safeErr != nil {
+		return nil, safeErr
+	}
+	tempPath = safePath
+	defer func() {
+		_ = os.Remove(tempPath)
+	}()
+
+	fileName, _ := l.ctx.Value(wafUploadFileNameCtxKey).(string)
+	if strings.TrimSpace(fileName) == "" {
+		fileName = basenameSafe(tempPath)
+	}
+
+	trustedKeys, err := helper.wafBundleTrustedKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	job := helper.startJob(0, 0, "import", "upload")
+	openDir := helper.store.StagePath(fmt.Sprintf("bundle_%d", time.Now().UnixNano()))
+	defer func() {
+		_ = os.RemoveAll(openDir)
+	}()
+	bundle, err := waf.OpenBundle(tempPath, openDir, trustedKeys, helper.svcCtx.Config.Waf.MaxPackageBytes)
+	if err != nil {
+		helper.finishJob(job, wafJobStatusFailed, err.Error(), 0)
+		return nil, err
+	}
+
+	kind := normalizeWafKind(bundle.Manifest.Kind)
+	if kind != wafKindCRS {
+		err = fmt.Errorf("离线包规则类型不支持: %s", bundle.Manifest.Kind)
+		helper.finishJob(job, wafJobStatusFailed, err.Error(), 0)
+		return nil, err
+	}
+	version := sanitizeToken(bundle.Manifest.Version)
+
+	existingRelease, err := findLatestReleaseByKindAndVersion(helper.svcCtx.DB.WithContext(helper.ctx), kind, version)
+	if err != nil {
+		helper.finishJob(job, wafJobStatusFailed, err.Error(), 0)
+		return nil, err
+	}
+	if existingRelease != nil && helper.canReuseRelease(existingRelease) {
+		helper.finishJob(job, wafJobStatusSuccess, "版本已存在，复用已有版本", existingRelease.ID)
+		if req.ActivateNow {
+			activateLogic := NewActivateWafReleaseLogic(l.ctx, l.svcCtx)
+			if _, activateErr := activateLogic.ActivateWafRelease(&types.WafReleaseActivateReq{ID: existingRelease.ID}); activateErr != nil {
+				return nil, activateErr
+			}
+		}
+		return &types.BaseResp{Code: 200, Msg: "成功"}, nil
+	}
+
+	verifyResult, err := waf.VerifyPackage(bundle.PackagePath, waf.VerifyOptions{
+		AllowedExt:      []string{".tar.gz"},
+		MaxPackageBytes: helper.svcCtx.Config.Waf.MaxPackageBytes,
+		ExpectedSHA256:  bundle.Manifest.PackageSHA256,
+	})
+	if err != nil {
+		helper.finishJob(job, wafJobStatusFailed, err.Error(), 0)
+		return nil, err
+	}
+
+	packageName := fmt.Sprintf("bundle_%s_%d%s", version, time.Now().UnixNano(), verifyResult.Ext)
+	packagePath := helper.store.PackagePath(packageName)
+	if err := os.Rename(bundle.PackagePath, packagePath); err != nil {
+		helper.finishJob(job, wafJobStatusFailed, fmt.Sprintf("移动包文件失败: %v", err), 0)
+		return nil, fmt.Errorf("移动包文件失败: %w", err)
+	}
+
+	releaseDir := helper.store.ReleaseDir(version)
+	if err := os.MkdirAll(releaseDir, 0o755); err != nil {
+		helper.finishJob(job, wafJobStatusFailed, fmt.Sprintf("创建版本目录失败: %v", err), 0)
+		return nil, fmt.Errorf("创建版本目录失败: %w", err)
+	}
+	if _, err := waf.ExtractPackage(packagePath, releaseDir, waf.ExtractOptions{
+		MaxFiles:      helper.svcCtx.Config.Waf.ExtractMaxFiles,
+		MaxTotalBytes: helper.svcCtx.Config.Waf.ExtractMaxTotalBytes,
+	}); err != nil {
+		_ = os.RemoveAll(releaseDir)
+		helper.finishJob(job, wafJobStatusFailed, err.Error(), 0)
+		return nil, err
+	}
+
+	release := &model.WafRelease{
+		SourceID:     0,
+		Kind:         kind,
+		Version:      version,
+		ArtifactType: artifactTypeFromExt(verifyResult.Ext),
+		Checksum:     verifyResult.SHA256,
+		SizeBytes:    verifyResult.SizeBytes,
+		StoragePath:  filepath.Clean(releaseDir),
+		Status:       wafReleaseStatusVerified,
+	}
+	meta := wafReleaseSignatureMeta(bundle.Signature)
+	if meta == nil {
+		meta = model.JSONMap{}
+	}
+	meta["importedFrom"] = "bundle"
+	meta["originFileName"] = basenameSafe(fileName)
+	meta["bundleExportedBy"] = bundle.Manifest.ExportedBy
+	meta["bundleExportedAt"] = bundle.Manifest.ExportedAt
+	if originChecksum := strings.TrimSpace(bundle.Manifest.OriginChecksum); originChecksum != "" {
+		meta["originChecksum"] = originChecksum
+	}
+	release.Meta = helper.attachReleaseRuleDiff(meta, release)
+
+	if err := helper.svcCtx.DB.WithContext(helper.ctx).Create(release).Error; err != nil {
+		helper.finishJob(job, wafJobStatusFailed, fmt.Sprintf("创建版本失败: %v", err), 0)
+		return nil, fmt.Errorf("创建版本失败: %w", err)
+	}
+	helper.applyReleaseRetention(release.Kind)
+
+	helper.finishJob(job, wafJobStatusSuccess, "导入成功", release.ID)
+
+	if req.ActivateNow {
+		activateLogic := NewActivateWafReleaseLogic(l.ctx, l.svcCtx)
+		if _, activateErr := activateLogic.ActivateWafRelease(&types.WafReleaseActivateReq{ID: release.ID}); activateErr != nil {
+			return nil, activateErr
+		}
+	}
+
+	return &types.BaseResp{Code: 200, Msg: "成功"}, nil
+}
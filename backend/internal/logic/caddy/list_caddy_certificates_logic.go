@@ -0,0 +1,88 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListCaddyCertificatesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListCaddyCertificatesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListCaddyCertificatesLogic {
+	return &ListCaddyCertificatesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ListCaddyCertificatesLogic) ListCaddyCertificates(req *types.CaddyCertificateListReq) (resp *types.CaddyCertificateListResp, err error) {
+	if req == nil {
+		req = &types.CaddyCertificateListReq{}
+	}
+
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	now := time.Now()
+	db := l.svcCtx.DB.WithContext(l.ctx).Model(&model.CaddyCertificate{})
+	if req.ServerId > 0 {
+		db = db.Where("server_id = ?", req.ServerId)
+	}
+	if status := strings.ToLower(strings.TrimSpace(req.Status)); status != "" {
+		db = db.Where("status = ?", status)
+	}
+	if keyword := strings.ToLower(strings.TrimSpace(req.Keyword)); keyword != "" {
+		db = db.Where("host LIKE ?", "%"+keyword+"%")
+	}
+	if req.ExpiringWithinDays > 0 {
+		db = db.Where("not_after IS NOT NULL AND not_after <= ?", now.AddDate(0, 0, req.ExpiringWithinDays))
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("统计证书失败: %w", err)
+	}
+
+	var certs []model.CaddyCertificate
+	offset := (page - 1) * pageSize
+	if err := db.Order("not_after IS NULL, not_after asc, id asc").Limit(pageSize).Offset(offset).Find(&certs).Error; err != nil {
+		return nil, fmt.Errorf("查询证书失败: %w", err)
+	}
+
+	serverNames := map[uint]string{}
+	serverIDs := make([]uint, 0, len(certs))
+	for _, cert := range certs {
+		serverIDs = append(serverIDs, cert.ServerID)
+	}
+	if len(serverIDs) > 0 {
+		var servers []model.CaddyServer
+		l.svcCtx.DB.WithContext(l.ctx).Select("id", "name").Where("id IN ?", serverIDs).Find(&servers)
+		for _, server := range servers {
+			serverNames[server.ID] = server.Name
+		}
+	}
+
+	list := make([]types.CaddyCertificateItem, 0, len(certs))
+	for i := range certs {
+		list = append(list, toCaddyCertificateItem(&certs[i], serverNames[certs[i].ServerID], now))
+	}
+	return &types.CaddyCertificateListResp{List: list, Total: total}, nil
+}
@@ -0,0 +1,69 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListCaddyChangeRequestsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListCaddyChangeRequestsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListCaddyChangeRequestsLogic {
+	return &ListCaddyChangeRequestsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ListCaddyChangeRequestsLogic) ListCaddyChangeRequests(req *types.CaddyChangeRequestListReq) (resp *types.CaddyChangeRequestListResp, err error) {
+	if req == nil {
+		req = &types.CaddyChangeRequestListReq{}
+	}
+
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	db := l.svcCtx.DB.WithContext(l.ctx).Model(&model.CaddyChangeRequest{})
+	if req.ServerId > 0 {
+		db = db.Where("server_id = ?", req.ServerId)
+	}
+	if status := strings.ToLower(strings.TrimSpace(req.Status)); status != "" {
+		db = db.Where("status = ?", status)
+	}
+	if kind := strings.ToLower(strings.TrimSpace(req.Kind)); kind != "" {
+		db = db.Where("kind = ?", kind)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("统计变更申请失败: %w", err)
+	}
+
+	var requests []model.CaddyChangeRequest
+	offset := (page - 1) * pageSize
+	if err := db.Omit("config", "modules", "diff").Order("id desc").Limit(pageSize).Offset(offset).Find(&requests).Error; err != nil {
+		return nil, fmt.Errorf("查询变更申请失败: %w", err)
+	}
+
+	return &types.CaddyChangeRequestListResp{
+		List:  describeCaddyChangeRequests(l.svcCtx.DB.WithContext(l.ctx), requests),
+		Total: total,
+	}, nil
+}
@@ -0,0 +1,82 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListCaddyConfigDriftsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListCaddyConfigDriftsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListCaddyConfigDriftsLogic {
+	return &ListCaddyConfigDriftsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ListCaddyConfigDriftsLogic) ListCaddyConfigDrifts(req *types.CaddyConfigDriftListReq) (resp *types.CaddyConfigDriftListResp, err error) {
+	if req == nil {
+		req = &types.CaddyConfigDriftListReq{}
+	}
+
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	db := l.svcCtx.DB.WithContext(l.ctx).Model(&model.CaddyConfigDrift{})
+	if req.ServerId > 0 {
+		db = db.Where("server_id = ?", req.ServerId)
+	}
+	if status := strings.ToLower(strings.TrimSpace(req.Status)); status != "" {
+		db = db.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("统计漂移记录失败: %w", err)
+	}
+
+	var drifts []model.CaddyConfigDrift
+	offset := (page - 1) * pageSize
+	if err := db.Order("id desc").Limit(pageSize).Offset(offset).Find(&drifts).Error; err != nil {
+		return nil, fmt.Errorf("查询漂移记录失败: %w", err)
+	}
+
+	serverIDs := make([]uint, 0, len(drifts))
+	for _, drift := range drifts {
+		serverIDs = append(serverIDs, drift.ServerID)
+	}
+	serverNames := make(map[uint]string, len(serverIDs))
+	if len(serverIDs) > 0 {
+		var servers []model.CaddyServer
+		if err := l.svcCtx.DB.WithContext(l.ctx).Select("id", "name").Where("id IN ?", serverIDs).Find(&servers).Error; err != nil {
+			return nil, fmt.Errorf("查询 Caddy 服务器失败: %w", err)
+		}
+		for _, server := range servers {
+			serverNames[server.ID] = server.Name
+		}
+	}
+
+	items := make([]types.CaddyConfigDriftItem, 0, len(drifts))
+	for _, drift := range drifts {
+		items = append(items, toCaddyConfigDriftItem(drift, serverNames[drift.ServerID]))
+	}
+	return &types.CaddyConfigDriftListResp{List: items, Total: total}, nil
+}
@@ -0,0 +1,50 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListCaddyServerGroupsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListCaddyServerGroupsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListCaddyServerGroupsLogic {
+	return &ListCaddyServerGroupsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ListCaddyServerGroupsLogic) ListCaddyServerGroups() (resp *types.CaddyServerGroupListResp, err error) {
+	db := l.svcCtx.DB.WithContext(l.ctx)
+
+	var groups []model.CaddyServerGroup
+	if err := db.Order("id asc").Find(&groups).Error; err != nil {
+		return nil, fmt.Errorf("查询节点分组失败: %w", err)
+	}
+
+	var servers []model.CaddyServer
+	if err := db.Select("id", "name").Find(&servers).Error; err != nil {
+		return nil, fmt.Errorf("查询 Caddy 服务器失败: %w", err)
+	}
+	serverNames := make(map[uint]string, len(servers))
+	for _, server := range servers {
+		serverNames[server.ID] = server.Name
+	}
+
+	items := make([]types.CaddyServerGroupItem, 0, len(groups))
+	for i := range groups {
+		items = append(items, toCaddyServerGroupItem(&groups[i], serverNames))
+	}
+	return &types.CaddyServerGroupListResp{List: items}, nil
+}
@@ -0,0 +1,28 @@
+package caddy
+
+import (
+	"context"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListCaddySitesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListCaddySitesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListCaddySitesLogic {
+	return &ListCaddySitesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ListCaddySitesLogic) ListCaddySites(req *types.CaddyConfigReq) (resp *types.CaddySiteListResp, err error) {
+	return newCaddySiteEditor(l.svcCtx, l.Logger, currentOperatorFromContext(l.ctx)).list(req.ServerId)
+}
@@ -0,0 +1,67 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListWafBanRulesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListWafBanRulesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListWafBanRulesLogic {
+	return &ListWafBanRulesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ListWafBanRulesLogic) ListWafBanRules(req *types.WafBanRuleListReq) (resp *types.WafBanRuleListResp, err error) {
+	defer func() {
+		err = localizeWafPolicyError(err)
+	}()
+	if req == nil {
+		req = &types.WafBanRuleListReq{}
+	}
+
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	db := l.svcCtx.DB.WithContext(l.ctx).Model(&model.WafBanRule{})
+	if keyword := strings.TrimSpace(req.Name); keyword != "" {
+		db = db.Where("name ILIKE ?", "%"+keyword+"%")
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("统计封禁规则失败: %w", err)
+	}
+
+	var rules []model.WafBanRule
+	offset := (page - 1) * pageSize
+	if err := db.Order("id asc").Limit(pageSize).Offset(offset).Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("查询封禁规则失败: %w", err)
+	}
+
+	items := make([]types.WafBanRuleItem, 0, len(rules))
+	for i := range rules {
+		items = append(items, toWafBanRuleItem(&rules[i]))
+	}
+	return &types.WafBanRuleListResp{List: items, Total: total}, nil
+}
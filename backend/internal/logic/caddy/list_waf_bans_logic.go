@@ -0,0 +1,90 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListWafBansLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListWafBansLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListWafBansLogic {
+	return &ListWafBansLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ListWafBansLogic) ListWafBans(req *types.WafBanListReq) (resp *types.WafBanListResp, err error) {
+	defer func() {
+		err = localizeWafPolicyError(err)
+	}()
+	if req == nil {
+		req = &types.WafBanListReq{}
+	}
+
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	db := l.svcCtx.DB.WithContext(l.ctx).Model(&model.WafBan{})
+	if req.RuleId > 0 {
+		db = db.Where("rule_id = ?", req.RuleId)
+	}
+	if status := strings.ToLower(strings.TrimSpace(req.Status)); status != "" {
+		db = db.Where("status = ?", status)
+	}
+	if ip := strings.TrimSpace(req.IP); ip != "" {
+		db = db.Where("ip LIKE ?", ip+"%")
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("统计封禁记录失败: %w", err)
+	}
+
+	var bans []model.WafBan
+	offset := (page - 1) * pageSize
+	if err := db.Order("id desc").Limit(pageSize).Offset(offset).Find(&bans).Error; err != nil {
+		return nil, fmt.Errorf("查询封禁记录失败: %w", err)
+	}
+
+	ruleNames := make(map[uint]string)
+	ruleIDs := make([]uint, 0)
+	for _, ban := range bans {
+		if ban.RuleID > 0 {
+			ruleIDs = append(ruleIDs, ban.RuleID)
+		}
+	}
+	if len(ruleIDs) > 0 {
+		var rules []model.WafBanRule
+		if err := l.svcCtx.DB.WithContext(l.ctx).Select("id, name").Where("id IN ?", ruleIDs).Find(&rules).Error; err != nil {
+			return nil, fmt.Errorf("查询封禁规则失败: %w", err)
+		}
+		for _, rule := range rules {
+			ruleNames[rule.ID] = rule.Name
+		}
+	}
+
+	items := make([]types.WafBanItem, 0, len(bans))
+	for i := range bans {
+		items = append(items, toWafBanItem(&bans[i], ruleNames[bans[i].RuleID]))
+	}
+	return &types.WafBanListResp{List: items, Total: total}, nil
+}
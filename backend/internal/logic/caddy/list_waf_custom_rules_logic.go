@@ -0,0 +1,83 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListWafCustomRulesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListWafCustomRulesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListWafCustomRulesLogic {
+	return &ListWafCustomRulesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ListWafCustomRulesLogic) ListWafCustomRules(req *types.WafCustomRuleListReq) (resp *types.WafCustomRuleListResp, err error) {
+	defer func() {
+		err = localizeWafPolicyError(err)
+	}()
+	if req == nil {
+		req = &types.WafCustomRuleListReq{}
+	}
+
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	db := l.svcCtx.DB.WithContext(l.ctx).Model(&model.WafCustomRule{})
+	if req.PolicyId > 0 {
+		db = db.Where("policy_id = ?", req.PolicyId)
+	}
+	if keyword := strings.TrimSpace(req.Name); keyword != "" {
+		db = db.Where("name ILIKE ?", "%"+keyword+"%")
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("统计自定义规则失败: %w", err)
+	}
+
+	var rules []model.WafCustomRule
+	offset := (page - 1) * pageSize
+	if err := db.Order("policy_id asc, priority asc, rule_id asc").Limit(pageSize).Offset(offset).Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("查询自定义规则失败: %w", err)
+	}
+
+	items := make([]types.WafCustomRuleItem, 0, len(rules))
+	for _, rule := range rules {
+		items = append(items, types.WafCustomRuleItem{
+			ID:          rule.ID,
+			PolicyId:    rule.PolicyID,
+			Name:        rule.Name,
+			Description: rule.Description,
+			Enabled:     rule.Enabled,
+			RuleId:      rule.RuleID,
+			Phase:       rule.Phase,
+			Priority:    rule.Priority,
+			Directive:   rule.Directive,
+			CreatedAt:   formatTime(rule.CreatedAt),
+			UpdatedAt:   formatTime(rule.UpdatedAt),
+		})
+	}
+
+	return &types.WafCustomRuleListResp{List: items, Total: total}, nil
+}
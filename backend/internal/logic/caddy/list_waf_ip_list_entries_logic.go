@@ -0,0 +1,69 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListWafIPListEntriesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListWafIPListEntriesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListWafIPListEntriesLogic {
+	return &ListWafIPListEntriesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ListWafIPListEntriesLogic) ListWafIPListEntries(req *types.WafIPListEntryListReq) (resp *types.WafIPListEntryListResp, err error) {
+	defer func() {
+		err = localizeWafPolicyError(err)
+	}()
+	if req == nil || req.ID == 0 {
+		return nil, fmt.Errorf("IP 名单 ID 不能为空")
+	}
+
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	db := l.svcCtx.DB.WithContext(l.ctx).Model(&model.WafIPListEntry{}).Where("list_id = ?", req.ID)
+	if keyword := strings.TrimSpace(req.Keyword); keyword != "" {
+		db = db.Where("value ILIKE ? OR note ILIKE ?", "%"+keyword+"%", "%"+keyword+"%")
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("统计 IP 名单条目失败: %w", err)
+	}
+
+	var entries []model.WafIPListEntry
+	offset := (page - 1) * pageSize
+	if err := db.Order("id desc").Limit(pageSize).Offset(offset).Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("查询 IP 名单条目失败: %w", err)
+	}
+
+	now := time.Now()
+	items := make([]types.WafIPListEntryItem, 0, len(entries))
+	for i := range entries {
+		items = append(items, toWafIPListEntryItem(&entries[i], now))
+	}
+	return &types.WafIPListEntryListResp{List: items, Total: total}, nil
+}
@@ -0,0 +1,111 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListWafIPListsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListWafIPListsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListWafIPListsLogic {
+	return &ListWafIPListsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ListWafIPListsLogic) ListWafIPLists(req *types.WafIPListListReq) (resp *types.WafIPListListResp, err error) {
+	defer func() {
+		err = localizeWafPolicyError(err)
+	}()
+	if req == nil {
+		req = &types.WafIPListListReq{}
+	}
+
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	db := l.svcCtx.DB.WithContext(l.ctx).Model(&model.WafIPList{})
+	if req.PolicyId > 0 {
+		db = db.Where("policy_id = ?", req.PolicyId)
+	}
+	if action := strings.ToLower(strings.TrimSpace(req.Action)); action != "" {
+		db = db.Where("action = ?", action)
+	}
+	if keyword := strings.TrimSpace(req.Name); keyword != "" {
+		db = db.Where("name ILIKE ?", "%"+keyword+"%")
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("统计 IP 名单失败: %w", err)
+	}
+
+	var lists []model.WafIPList
+	offset := (page - 1) * pageSize
+	if err := db.Order("policy_id asc, id asc").Limit(pageSize).Offset(offset).Find(&lists).Error; err != nil {
+		return nil, fmt.Errorf("查询 IP 名单失败: %w", err)
+	}
+
+	type entryCount struct {
+		ListID uint
+		Total  int64
+		Active int64
+	}
+	counts := make(map[uint]entryCount, len(lists))
+	if len(lists) > 0 {
+		listIDs := make([]uint, 0, len(lists))
+		for _, list := range lists {
+			listIDs = append(listIDs, list.ID)
+		}
+		var rows []entryCount
+		if err := l.svcCtx.DB.WithContext(l.ctx).Model(&model.WafIPListEntry{}).
+			Select("list_id, COUNT(*) AS total, COUNT(*) FILTER (WHERE expires_at IS NULL OR expires_at > ?) AS active", time.Now()).
+			Where("list_id IN ?", listIDs).
+			Group("list_id").
+			Scan(&rows).Error; err != nil {
+			return nil, fmt.Errorf("统计 IP 名单条目失败: %w", err)
+		}
+		for _, row := range rows {
+			counts[row.ListID] = row
+		}
+	}
+
+	items := make([]types.WafIPListItem, 0, len(lists))
+	for _, list := range lists {
+		items = append(items, types.WafIPListItem{
+			ID:               list.ID,
+			PolicyId:         list.PolicyID,
+			BindingId:        list.BindingID,
+			Name:             list.Name,
+			Description:      list.Description,
+			Action:           list.Action,
+			Enabled:          list.Enabled,
+			EntryCount:       counts[list.ID].Total,
+			ActiveEntryCount: counts[list.ID].Active,
+			CreatedAt:        formatTime(list.CreatedAt),
+			UpdatedAt:        formatTime(list.UpdatedAt),
+		})
+	}
+
+	return &types.WafIPListListResp{List: items, Total: total}, nil
+}
@@ -70,6 +70,7 @@ func (l *ListWafJobsLogic) ListWafJobs(req *types.WafJobListReq) (resp *types.Wa
 			Operator:    mapJobOperator(job.Operator, operatorNameMap),
 			Status:      job.Status,
 			Message:     job.Message,
+			RuleDiff:    wafRuleDiffSummaryFromMeta(job.Meta),
 			StartedAt:   formatNullableTime(job.StartedAt),
 			FinishedAt:  formatNullableTime(job.FinishedAt),
 			CreatedAt:   formatTime(job.CreatedAt),
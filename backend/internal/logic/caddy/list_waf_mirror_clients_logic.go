@@ -0,0 +1,65 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListWafMirrorClientsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListWafMirrorClientsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListWafMirrorClientsLogic {
+	return &ListWafMirrorClientsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ListWafMirrorClientsLogic) ListWafMirrorClients(req *types.WafMirrorClientListReq) (resp *types.WafMirrorClientListResp, err error) {
+	if req == nil {
+		req = &types.WafMirrorClientListReq{}
+	}
+
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	db := l.svcCtx.DB.WithContext(l.ctx).Model(&model.WafMirrorClient{})
+	if keyword := strings.TrimSpace(req.Keyword); keyword != "" {
+		db = db.Where("name ILIKE ? OR description ILIKE ?", "%"+keyword+"%", "%"+keyword+"%")
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("统计镜像凭据失败: %w", err)
+	}
+
+	var clients []model.WafMirrorClient
+	offset := (page - 1) * pageSize
+	if err := db.Order("id asc").Limit(pageSize).Offset(offset).Find(&clients).Error; err != nil {
+		return nil, fmt.Errorf("查询镜像凭据失败: %w", err)
+	}
+
+	items := make([]types.WafMirrorClientItem, 0, len(clients))
+	for i := range clients {
+		items = append(items, toWafMirrorClientItem(&clients[i]))
+	}
+
+	return &types.WafMirrorClientListResp{List: items, Total: total}, nil
+}
@@ -162,6 +162,7 @@ func (l *ListWafPolicyFalsePositiveFeedbacksLogic) ListWafPolicyFalsePositiveFee
 			ProcessNote:    item.ProcessNote,
 			ProcessedBy:    item.ProcessedBy,
 			ProcessedAt:    formatNullableTime(item.ProcessedAt),
+			ExclusionId:    derefUint(item.ExclusionID),
 			CreatedAt:      formatTime(item.CreatedAt),
 		})
 	}
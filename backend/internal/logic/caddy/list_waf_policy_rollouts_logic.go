@@ -0,0 +1,69 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListWafPolicyRolloutsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListWafPolicyRolloutsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListWafPolicyRolloutsLogic {
+	return &ListWafPolicyRolloutsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ListWafPolicyRolloutsLogic) ListWafPolicyRollouts(req *types.WafPolicyRolloutListReq) (resp *types.WafPolicyRolloutListResp, err error) {
+	defer func() {
+		err = localizeWafPolicyError(err)
+	}()
+	if req == nil {
+		req = &types.WafPolicyRolloutListReq{}
+	}
+
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	db := l.svcCtx.DB.WithContext(l.ctx).Model(&model.WafPolicyRollout{})
+	if req.PolicyId > 0 {
+		db = db.Where("policy_id = ?", req.PolicyId)
+	}
+	if status := normalizeWafRolloutStatus(req.Status); status != "" {
+		db = db.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("统计灰度发布失败: %w", err)
+	}
+
+	var rollouts []model.WafPolicyRollout
+	offset := (page - 1) * pageSize
+	if err := db.Preload("Policy").Order("id desc").Limit(pageSize).Offset(offset).Find(&rollouts).Error; err != nil {
+		return nil, fmt.Errorf("查询灰度发布失败: %w", err)
+	}
+
+	items := make([]types.WafPolicyRolloutItem, 0, len(rollouts))
+	for i := range rollouts {
+		items = append(items, toWafPolicyRolloutItem(&rollouts[i], rollouts[i].Policy.Name))
+	}
+	return &types.WafPolicyRolloutListResp{List: items, Total: total}, nil
+}
@@ -0,0 +1,71 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListWafRateLimitZonesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListWafRateLimitZonesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListWafRateLimitZonesLogic {
+	return &ListWafRateLimitZonesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ListWafRateLimitZonesLogic) ListWafRateLimitZones(req *types.WafRateLimitZoneListReq) (resp *types.WafRateLimitZoneListResp, err error) {
+	defer func() {
+		err = localizeWafPolicyError(err)
+	}()
+	if req == nil {
+		req = &types.WafRateLimitZoneListReq{}
+	}
+
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	db := l.svcCtx.DB.WithContext(l.ctx).Model(&model.WafRateLimitZone{})
+	if req.PolicyId > 0 {
+		db = db.Where("policy_id = ?", req.PolicyId)
+	}
+	if keyword := strings.TrimSpace(req.Name); keyword != "" {
+		db = db.Where("name ILIKE ?", "%"+keyword+"%")
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("统计限流区域失败: %w", err)
+	}
+
+	var zones []model.WafRateLimitZone
+	offset := (page - 1) * pageSize
+	if err := db.Order("policy_id asc, id asc").Limit(pageSize).Offset(offset).Find(&zones).Error; err != nil {
+		return nil, fmt.Errorf("查询限流区域失败: %w", err)
+	}
+
+	items := make([]types.WafRateLimitZoneItem, 0, len(zones))
+	for i := range zones {
+		items = append(items, toWafRateLimitZoneItem(&zones[i]))
+	}
+
+	return &types.WafRateLimitZoneListResp{List: items, Total: total}, nil
+}
@@ -77,6 +77,7 @@ func (l *ListWafReleasesLogic) ListWafReleases(req *types.WafReleaseListReq) (re
 			SizeBytes:    release.SizeBytes,
 			StoragePath:  release.StoragePath,
 			Status:       release.Status,
+			RuleDiff:     wafRuleDiffSummaryFromMeta(release.Meta),
 			CreatedAt:    formatTime(release.CreatedAt),
 			UpdatedAt:    formatTime(release.UpdatedAt),
 		})
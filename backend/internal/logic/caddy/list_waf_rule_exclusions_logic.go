@@ -72,19 +72,21 @@ func (l *ListWafRuleExclusionsLogic) ListWafRuleExclusions(req *types.WafRuleExc
 	items := make([]types.WafRuleExclusionItem, 0, len(exclusions))
 	for _, exclusion := range exclusions {
 		items = append(items, types.WafRuleExclusionItem{
-			ID:          exclusion.ID,
-			PolicyId:    exclusion.PolicyID,
-			Name:        exclusion.Name,
-			Description: exclusion.Description,
-			Enabled:     exclusion.Enabled,
-			ScopeType:   exclusion.ScopeType,
-			Host:        exclusion.Host,
-			Path:        exclusion.Path,
-			Method:      exclusion.Method,
-			RemoveType:  exclusion.RemoveType,
-			RemoveValue: exclusion.RemoveValue,
-			CreatedAt:   formatTime(exclusion.CreatedAt),
-			UpdatedAt:   formatTime(exclusion.UpdatedAt),
+			ID:           exclusion.ID,
+			PolicyId:     exclusion.PolicyID,
+			Name:         exclusion.Name,
+			Description:  exclusion.Description,
+			Enabled:      exclusion.Enabled,
+			ScopeType:    exclusion.ScopeType,
+			Host:         exclusion.Host,
+			Path:         exclusion.Path,
+			Method:       exclusion.Method,
+			RemoveType:   exclusion.RemoveType,
+			RemoveValue:  exclusion.RemoveValue,
+			RemoveTarget: exclusion.RemoveTarget,
+			FeedbackId:   derefUint(exclusion.FeedbackID),
+			CreatedAt:    formatTime(exclusion.CreatedAt),
+			UpdatedAt:    formatTime(exclusion.UpdatedAt),
 		})
 	}
 
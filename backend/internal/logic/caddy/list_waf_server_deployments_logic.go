@@ -0,0 +1,86 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListWafServerDeploymentsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListWafServerDeploymentsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListWafServerDeploymentsLogic {
+	return &ListWafServerDeploymentsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ListWafServerDeploymentsLogic) ListWafServerDeployments(req *types.WafServerDeploymentListReq) (resp *types.WafServerDeploymentListResp, err error) {
+	if req == nil {
+		req = &types.WafServerDeploymentListReq{}
+	}
+
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	db := l.svcCtx.DB.WithContext(l.ctx).Model(&model.WafServerDeployment{})
+	if req.JobId > 0 {
+		db = db.Where("job_id = ?", req.JobId)
+	}
+	if targetType := strings.ToLower(strings.TrimSpace(req.TargetType)); targetType != "" {
+		db = db.Where("target_type = ?", targetType)
+	}
+	if req.ServerId > 0 {
+		db = db.Where("server_id = ?", req.ServerId)
+	}
+	if status := strings.ToLower(strings.TrimSpace(req.Status)); status != "" {
+		db = db.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("统计节点部署记录失败: %w", err)
+	}
+
+	var deployments []model.WafServerDeployment
+	offset := (page - 1) * pageSize
+	if err := db.Order("job_id desc, id asc").Limit(pageSize).Offset(offset).Find(&deployments).Error; err != nil {
+		return nil, fmt.Errorf("查询节点部署记录失败: %w", err)
+	}
+
+	items := make([]types.WafServerDeploymentItem, 0, len(deployments))
+	for _, deployment := range deployments {
+		items = append(items, types.WafServerDeploymentItem{
+			ID:         deployment.ID,
+			JobId:      deployment.JobID,
+			TargetType: deployment.TargetType,
+			TargetId:   deployment.TargetID,
+			ServerId:   deployment.ServerID,
+			ServerName: deployment.ServerName,
+			Status:     deployment.Status,
+			Message:    deployment.Message,
+			StartedAt:  formatNullableTime(deployment.StartedAt),
+			FinishedAt: formatNullableTime(deployment.FinishedAt),
+			CreatedAt:  formatTime(deployment.CreatedAt),
+		})
+	}
+
+	return &types.WafServerDeploymentListResp{List: items, Total: total}, nil
+}
@@ -82,23 +82,26 @@ func (l *ListWafSourcesLogic) ListWafSources(req *types.WafSourceListReq) (resp
 	items := make([]types.WafSourceItem, 0, len(sources))
 	for _, source := range sources {
 		items = append(items, types.WafSourceItem{
-			ID:           source.ID,
-			Name:         source.Name,
-			Kind:         source.Kind,
-			Mode:         source.Mode,
-			Url:          source.URL,
-			ChecksumUrl:  source.ChecksumURL,
-			ProxyUrl:     source.ProxyURL,
-			AuthType:     source.AuthType,
-			Schedule:     source.Schedule,
-			Enabled:      source.Enabled,
-			AutoCheck:    source.AutoCheck,
-			AutoDownload: source.AutoDownload,
-			AutoActivate: source.AutoActivate,
-			LastRelease:  source.LastRelease,
-			LastError:    source.LastError,
-			CreatedAt:    formatTime(source.CreatedAt),
-			UpdatedAt:    formatTime(source.UpdatedAt),
+			ID:            source.ID,
+			Name:          source.Name,
+			Kind:          source.Kind,
+			Mode:          source.Mode,
+			Url:           source.URL,
+			ChecksumUrl:   source.ChecksumURL,
+			ProxyUrl:      source.ProxyURL,
+			AuthType:      source.AuthType,
+			SignatureType: source.SignatureType,
+			SignatureUrl:  source.SignatureURL,
+			PublicKeys:    source.PublicKeys,
+			Schedule:      source.Schedule,
+			Enabled:       source.Enabled,
+			AutoCheck:     source.AutoCheck,
+			AutoDownload:  source.AutoDownload,
+			AutoActivate:  source.AutoActivate,
+			LastRelease:   source.LastRelease,
+			LastError:     source.LastError,
+			CreatedAt:     formatTime(source.CreatedAt),
+			UpdatedAt:     formatTime(source.UpdatedAt),
 		})
 	}
 
@@ -23,15 +23,75 @@ type managedCaddyfileOptions struct {
 	WafAuditLog   string
 	WafEnabled    bool
 	Directives    string
+	Canary        *managedWafCanary
+	GeoDeny       []managedWafGeoRule
+	RateLimits    []managedRateLimitZone
+	BanSnippet    string // 自动封禁片段，由封禁同步单独维护，重新渲染时原样保留
 }
 
-func buildPolicyCandidateCaddyConfig(currentConfig, directives string, wafEnabled bool) (string, error) {
+// managedWafGeoRule 国家/地区拒绝规则，Expression 为基于 geoip2 占位符的 CEL 表达式
+type managedWafGeoRule struct {
+	Name       string
+	Expression string
+}
+
+// managedRateLimitZone caddy-ratelimit 区域，Match 为 match 块内的匹配器行，为空表示匹配全部请求
+type managedRateLimitZone struct {
+	Name   string
+	Match  []string
+	Key    string
+	Events int64
+	Window string
+}
+
+// managedPolicyExtras 随策略一同渲染、仅托管 Caddyfile 支持的附加配置
+type managedPolicyExtras struct {
+	GeoDeny    []managedWafGeoRule
+	RateLimits []managedRateLimitZone
+}
+
+func (extras managedPolicyExtras) unsupportedReason() string {
+	switch {
+	case len(extras.GeoDeny) > 0:
+		return "国家/地区名单"
+	case len(extras.RateLimits) > 0:
+		return "限流区域"
+	default:
+		return ""
+	}
+}
+
+// managedWafCanary 灰度实例：仅对 Expression 命中的请求以 DetectionOnly 运行候选指令
+type managedWafCanary struct {
+	Expression string
+	Directives string
+}
+
+func buildPolicyCandidateCaddyConfig(currentConfig, directives string, wafEnabled bool, extras managedPolicyExtras) (string, error) {
 	if shouldRenderManagedCaddyfile(currentConfig) {
-		return renderManagedCaddyfile(defaultManagedCaddyfileOptions(currentConfig, directives, wafEnabled))
+		options := defaultManagedCaddyfileOptions(currentConfig, directives, wafEnabled)
+		options.GeoDeny = extras.GeoDeny
+		options.RateLimits = extras.RateLimits
+		return renderManagedCaddyfile(options)
+	}
+	if reason := extras.unsupportedReason(); reason != "" {
+		return "", fmt.Errorf("当前 Caddy 配置非 LogFlux 托管，暂不支持%s", reason)
 	}
 	return applyWafPolicyToCaddyConfig(currentConfig, directives)
 }
 
+// buildPolicyCanaryCaddyConfig 在已发布指令之外追加灰度实例，仅支持 LogFlux 托管的 Caddyfile
+func buildPolicyCanaryCaddyConfig(currentConfig, baselineDirectives string, canary managedWafCanary, extras managedPolicyExtras) (string, error) {
+	if !shouldRenderManagedCaddyfile(currentConfig) {
+		return "", fmt.Errorf("当前 Caddy 配置非 LogFlux 托管，暂不支持灰度发布")
+	}
+	options := defaultManagedCaddyfileOptions(currentConfig, baselineDirectives, true)
+	options.Canary = &canary
+	options.GeoDeny = extras.GeoDeny
+	options.RateLimits = extras.RateLimits
+	return renderManagedCaddyfile(options)
+}
+
 func shouldRenderManagedCaddyfile(config string) bool {
 	trimmed := strings.TrimSpace(config)
 	if trimmed == "" {
@@ -55,6 +115,7 @@ func defaultManagedCaddyfileOptions(currentConfig, directives string, wafEnabled
 		WafAuditLog:   managedCaddyDefaultWafAuditLog,
 		WafEnabled:    wafEnabled,
 		Directives:    directives,
+		BanSnippet:    extractCaddySnippet(currentConfig, managedWafBanSnippetName),
 	}
 }
 
@@ -91,13 +152,23 @@ func renderManagedCaddyfile(options managedCaddyfileOptions) (string, error) {
 	if options.WafEnabled && strings.TrimSpace(options.Directives) == "" {
 		return "", fmt.Errorf("WAF 策略指令为空")
 	}
+	if options.Canary != nil && (!options.WafEnabled || options.Canary.Expression == "" || options.Canary.Directives == "") {
+		return "", fmt.Errorf("WAF 灰度配置无效")
+	}
 
 	var builder strings.Builder
 	builder.WriteString(logfluxManagedCaddyfileMarker + "\n")
 	builder.WriteString("{\n")
 	builder.WriteString("  admin :2019\n")
+	if len(options.GeoDeny) > 0 {
+		builder.WriteString("  order geoip2_vars first\n")
+	}
 	if options.WafEnabled {
-		builder.WriteString("  order coraza_waf first\n")
+		builder.WriteString("  " + corazaOrderLine + "\n")
+		builder.WriteString("  " + wafClientIPOrderLine + "\n")
+	}
+	if len(options.RateLimits) > 0 {
+		builder.WriteString("  order rate_limit before basicauth\n")
 	}
 	builder.WriteString("}\n\n")
 
@@ -105,11 +176,31 @@ func renderManagedCaddyfile(options managedCaddyfileOptions) (string, error) {
 		builder.WriteString(renderManagedWafSnippet(options.Directives, options.WafAuditLog))
 		builder.WriteString("\n")
 	}
+	if options.Canary != nil {
+		builder.WriteString(renderManagedWafCanarySnippet(*options.Canary, options.WafAuditLog))
+		builder.WriteString("\n")
+	}
+	if options.BanSnippet != "" {
+		builder.WriteString(options.BanSnippet + "\n\n")
+	}
 
 	builder.WriteString(options.SiteAddress + " {\n")
+	if options.BanSnippet != "" {
+		builder.WriteString("  import " + managedWafBanSnippetName + "\n")
+	}
+	if len(options.GeoDeny) > 0 {
+		builder.WriteString(renderManagedGeoDenyRules(options.GeoDeny))
+	}
+	if options.Canary != nil {
+		builder.WriteString("  import waf_canary\n")
+	}
 	if options.WafEnabled {
 		builder.WriteString("  import waf_protect\n\n")
 	}
+	if len(options.RateLimits) > 0 {
+		builder.WriteString(renderManagedRateLimits(options.RateLimits, "  "))
+		builder.WriteString("\n")
+	}
 	builder.WriteString("  log {\n")
 	builder.WriteString(fmt.Sprintf("    output file %s\n", options.AccessLogPath))
 	builder.WriteString("    format json\n")
@@ -153,12 +244,20 @@ func normalizeManagedCaddyfileOptions(options managedCaddyfileOptions) managedCa
 		options.WafAuditLog = managedCaddyDefaultWafAuditLog
 	}
 	options.Directives = strings.TrimSpace(options.Directives)
+	options.BanSnippet = strings.TrimSpace(options.BanSnippet)
+	if options.Canary != nil {
+		options.Canary = &managedWafCanary{
+			Expression: strings.TrimSpace(options.Canary.Expression),
+			Directives: strings.TrimSpace(options.Canary.Directives),
+		}
+	}
 	return options
 }
 
 func renderManagedWafSnippet(directives, auditLogPath string) string {
 	return strings.Join([]string{
 		"(waf_protect) {",
+		"  " + wafClientIPHeaderLine,
 		"  coraza_waf {",
 		"    load_owasp_crs",
 		"    directives `",
@@ -169,6 +268,55 @@ func renderManagedWafSnippet(directives, auditLogPath string) string {
 	}, "\n") + "\n"
 }
 
+// renderManagedGeoDenyRules 国家/地区拒绝在 WAF 之前由 Caddy 直接响应 403
+func renderManagedGeoDenyRules(rules []managedWafGeoRule) string {
+	var builder strings.Builder
+	builder.WriteString("  geoip2_vars strict\n")
+	for _, rule := range rules {
+		builder.WriteString(fmt.Sprintf("  @%s expression `%s`\n", rule.Name, rule.Expression))
+		builder.WriteString(fmt.Sprintf("  respond @%s 403\n", rule.Name))
+	}
+	builder.WriteString("\n")
+	return builder.String()
+}
+
+// renderManagedRateLimits 渲染 caddy-ratelimit 指令，被限流的请求由模块直接返回 429
+func renderManagedRateLimits(zones []managedRateLimitZone, indent string) string {
+	var builder strings.Builder
+	builder.WriteString(indent + "rate_limit {\n")
+	for _, zone := range zones {
+		builder.WriteString(fmt.Sprintf("%s  zone %s {\n", indent, zone.Name))
+		if len(zone.Match) > 0 {
+			builder.WriteString(indent + "    match {\n")
+			for _, matcher := range zone.Match {
+				builder.WriteString(indent + "      " + matcher + "\n")
+			}
+			builder.WriteString(indent + "    }\n")
+		}
+		builder.WriteString(fmt.Sprintf("%s    key %s\n", indent, zone.Key))
+		builder.WriteString(fmt.Sprintf("%s    events %d\n", indent, zone.Events))
+		builder.WriteString(fmt.Sprintf("%s    window %s\n", indent, zone.Window))
+		builder.WriteString(indent + "  }\n")
+	}
+	builder.WriteString(indent + "}\n")
+	return builder.String()
+}
+
+func renderManagedWafCanarySnippet(canary managedWafCanary, auditLogPath string) string {
+	return strings.Join([]string{
+		"(waf_canary) {",
+		"  " + wafClientIPHeaderLine,
+		"  @waf_canary expression `" + canary.Expression + "`",
+		"  coraza_waf @waf_canary {",
+		"    load_owasp_crs",
+		"    directives `",
+		indentManagedCorazaDirectives(composeManagedCorazaDirectives(canary.Directives, auditLogPath)),
+		"    `",
+		"  }",
+		"}",
+	}, "\n") + "\n"
+}
+
 func composeManagedCorazaDirectives(directives, auditLogPath string) string {
 	lines := []string{
 		"Include @coraza.conf-recommended",
@@ -176,22 +324,67 @@ func composeManagedCorazaDirectives(directives, auditLogPath string) string {
 		"",
 	}
 
+	policyLines, postCRSLines := splitCorazaPolicyDirectives(directives)
+	lines = append(lines, policyLines...)
+
+	if !containsDirectiveName(lines, "SecAuditLog") {
+		lines = append(lines, fmt.Sprintf("SecAuditLog %s", strings.TrimSpace(auditLogPath)))
+	}
+	lines = append(lines, "", "Include @owasp_crs/*.conf")
+	if len(postCRSLines) > 0 {
+		lines = append(lines, "")
+		lines = append(lines, postCRSLines...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// splitCorazaPolicyDirectives 拆分策略指令并跳过基础 Include 与 SecAuditLog；
+// 删除/修改已加载规则的指令只对已存在的规则生效，单独返回以放在 CRS 规则之后
+func splitCorazaPolicyDirectives(directives string) ([]string, []string) {
+	lines := make([]string, 0)
+	postCRSLines := make([]string, 0)
 	for _, line := range strings.Split(strings.TrimSpace(directives), "\n") {
 		trimmed := strings.TrimSpace(line)
-		if trimmed == "" {
+		if trimmed == "" || isManagedCorazaBaseDirective(trimmed) {
 			continue
 		}
-		if isManagedCorazaBaseDirective(trimmed) {
+		if isManagedCorazaPostCRSDirective(trimmed) {
+			postCRSLines = append(postCRSLines, trimmed)
 			continue
 		}
 		lines = append(lines, trimmed)
 	}
+	return lines, postCRSLines
+}
 
-	if !containsDirectiveName(lines, "SecAuditLog") {
-		lines = append(lines, fmt.Sprintf("SecAuditLog %s", strings.TrimSpace(auditLogPath)))
+// composeUnmanagedCorazaDirectives 非托管配置保留原指令块中的基础 Include 与 SecAuditLog，
+// 按托管配置的顺序排列：策略指令在 CRS 规则之前，删除/修改已加载规则的指令在其之后
+func composeUnmanagedCorazaDirectives(existing, directives string) []string {
+	lines := make([]string, 0)
+	crsIncludes := make([]string, 0)
+	for _, line := range strings.Split(existing, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if !isManagedCorazaBaseDirective(trimmed) {
+			continue
+		}
+		if strings.HasPrefix(strings.ToLower(trimmed), "include @owasp_crs/") {
+			crsIncludes = append(crsIncludes, trimmed)
+			continue
+		}
+		lines = append(lines, trimmed)
 	}
-	lines = append(lines, "", "Include @owasp_crs/*.conf")
-	return strings.Join(lines, "\n")
+
+	policyLines, postCRSLines := splitCorazaPolicyDirectives(directives)
+	lines = append(lines, policyLines...)
+	lines = append(lines, crsIncludes...)
+	return append(lines, postCRSLines...)
+}
+
+func isManagedCorazaPostCRSDirective(line string) bool {
+	normalized := strings.ToLower(strings.TrimSpace(line))
+	return strings.HasPrefix(normalized, "secruleremoveby") ||
+		strings.HasPrefix(normalized, "secruleupdatetargetby") ||
+		strings.HasPrefix(normalized, "secruleupdateactionby")
 }
 
 func isManagedCorazaBaseDirective(line string) bool {
@@ -34,12 +34,21 @@ func (l *PreviewWafPolicyLogic) PreviewWafPolicy(req *types.WafPolicyActionReq)
 		return nil, fmt.Errorf("策略 ID 不能为空")
 	}
 
+	return l.PreviewWafPolicyWithExclusions(req.ID)
+}
+
+// PreviewWafPolicyWithExclusions 预览策略指令，extra 为尚未保存的候选排除规则
+func (l *PreviewWafPolicyLogic) PreviewWafPolicyWithExclusions(policyID uint, extra ...model.WafRuleExclusion) (resp *types.WafPolicyPreviewResp, err error) {
+	defer func() {
+		err = localizeWafPolicyError(err)
+	}()
+
 	var policy model.WafPolicy
-	if err := l.svcCtx.DB.WithContext(l.ctx).First(&policy, req.ID).Error; err != nil {
+	if err := l.svcCtx.DB.WithContext(l.ctx).First(&policy, policyID).Error; err != nil {
 		return nil, fmt.Errorf("策略不存在")
 	}
 
-	directives, err := buildPolicyDirectivesWithExclusions(l.svcCtx.DB.WithContext(l.ctx), &policy)
+	directives, err := buildPolicyDirectivesWithExclusions(l.svcCtx.DB.WithContext(l.ctx), &policy, extra...)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,46 @@
+package caddy
+
+import (
+	"context"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type PromoteWafPolicyRolloutLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewPromoteWafPolicyRolloutLogic(ctx context.Context, svcCtx *svc.ServiceContext) *PromoteWafPolicyRolloutLogic {
+	return &PromoteWafPolicyRolloutLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PromoteWafPolicyRollout 将灰度候选版本发布为生效版本
+func (l *PromoteWafPolicyRolloutLogic) PromoteWafPolicyRollout(req *types.WafPolicyRolloutActionReq) (resp *types.WafPolicyRolloutItem, err error) {
+	defer func() {
+		err = localizeWafPolicyError(err)
+	}()
+	if err := ensureDirectCaddyChangeAllowed(l.svcCtx); err != nil {
+		return nil, err
+	}
+	if req == nil {
+		req = &types.WafPolicyRolloutActionReq{}
+	}
+
+	rollout, err := NewWafRolloutService(l.ctx, l.svcCtx).Promote(req.ID, currentOperatorFromContext(l.ctx), req.Message, false)
+	if err != nil {
+		return nil, err
+	}
+
+	l.Infof("WAF 策略灰度已转正: rollout=%d policy=%d", rollout.ID, rollout.PolicyID)
+	item := toWafPolicyRolloutItem(rollout, "")
+	return &item, nil
+}
@@ -27,6 +27,10 @@ func NewPublishWafPolicyLogic(ctx context.Context, svcCtx *svc.ServiceContext) *
 }
 
 func (l *PublishWafPolicyLogic) PublishWafPolicy(req *types.WafPolicyActionReq) (resp *types.BaseResp, err error) {
+	if err := ensureDirectCaddyChangeAllowed(l.svcCtx); err != nil {
+		return nil, err
+	}
+
 	policyID := uint(0)
 	policyName := ""
 	operator := currentOperatorFromContext(l.ctx)
@@ -95,6 +95,8 @@ func TestPublishWafPolicyLoadFailedRollbackToLastGood(t *testing.T) {
 			"scope_type", "host", "path", "method", "priority", "count",
 		}),
 	)
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "waf_policy_rollouts"`).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`SELECT .* FROM "waf_custom_rules"`).WillReturnRows(policyCustomRuleRows())
 	mock.ExpectQuery(`SELECT .* FROM "waf_rule_exclusions"`).WillReturnRows(
 		sqlmock.NewRows([]string{
 			"id", "created_at", "updated_at",
@@ -103,6 +105,9 @@ func TestPublishWafPolicyLoadFailedRollbackToLastGood(t *testing.T) {
 			"remove_type", "remove_value",
 		}),
 	)
+	mock.ExpectQuery(`SELECT .* FROM "waf_ip_lists"`).WillReturnRows(policyIPListRows())
+	mock.ExpectQuery(`SELECT .* FROM "waf_ip_lists"`).WillReturnRows(policyIPListRows())
+	mock.ExpectQuery(`SELECT .* FROM "waf_rate_limit_zones"`).WillReturnRows(policyRateLimitZoneRows())
 	mock.ExpectQuery(`SELECT .* FROM "caddy_servers"`).WillReturnRows(
 		sqlmock.NewRows([]string{
 			"id", "created_at", "updated_at",
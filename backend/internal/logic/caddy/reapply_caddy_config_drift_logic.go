@@ -0,0 +1,31 @@
+package caddy
+
+import (
+	"context"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ReapplyCaddyConfigDriftLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewReapplyCaddyConfigDriftLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ReapplyCaddyConfigDriftLogic {
+	return &ReapplyCaddyConfigDriftLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ReapplyCaddyConfigDriftLogic) ReapplyCaddyConfigDrift(req *types.IDReq) (resp *types.BaseResp, err error) {
+	if _, err := NewCaddyDriftService(l.ctx, l.svcCtx).Reapply(req.ID, currentOperatorFromContext(l.ctx)); err != nil {
+		return nil, err
+	}
+	return &types.BaseResp{Code: 200, Msg: "成功"}, nil
+}
@@ -0,0 +1,37 @@
+package caddy
+
+import (
+	"context"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type RejectCaddyChangeRequestLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewRejectCaddyChangeRequestLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RejectCaddyChangeRequestLogic {
+	return &RejectCaddyChangeRequestLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *RejectCaddyChangeRequestLogic) RejectCaddyChangeRequest(req *types.CaddyChangeRequestActionReq) (resp *types.CaddyChangeRequestItem, err error) {
+	actor, err := loadCaddyChangeActor(l.ctx, l.svcCtx.DB)
+	if err != nil {
+		return nil, err
+	}
+	request, err := NewCaddyChangeRequestService(l.ctx, l.svcCtx).Reject(actor, req.ID, req.Comment)
+	if err != nil {
+		return nil, err
+	}
+	item := toCaddyChangeRequestItem(*request)
+	return &item, nil
+}
@@ -0,0 +1,124 @@
+package caddy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/internal/waf"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type ReplayWafPolicyLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewReplayWafPolicyLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ReplayWafPolicyLogic {
+	return &ReplayWafPolicyLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ReplayWafPolicy 在嵌入式 Coraza 中分别加载当前已发布版本与候选指令，回放同一批请求并对比拦截结果
+func (l *ReplayWafPolicyLogic) ReplayWafPolicy(req *types.WafPolicyReplayReq) (resp *types.WafPolicyReplayResp, err error) {
+	defer func() {
+		err = localizeWafPolicyError(err)
+	}()
+
+	if req == nil || req.ID == 0 {
+		return nil, fmt.Errorf("策略 ID 不能为空")
+	}
+
+	startedAt := time.Now()
+	db := l.svcCtx.DB.WithContext(l.ctx)
+
+	var policy model.WafPolicy
+	if err := db.First(&policy, req.ID).Error; err != nil {
+		return nil, fmt.Errorf("策略不存在")
+	}
+
+	sampleSize := normalizeWafReplaySampleSize(req.SampleSize)
+	var requests []waf.ReplayRequest
+	switch normalizeWafReplaySource(req.Source) {
+	case wafReplaySourceLogs:
+		requests, err = loadWafReplayLogSample(db, normalizeWafReplayHours(req.Hours), sampleSize, req.Host)
+		if err != nil {
+			return nil, err
+		}
+	case wafReplaySourceUpload:
+		corpus, _ := l.ctx.Value(wafReplayCorpusCtxKey{}).([]byte)
+		if len(corpus) == 0 {
+			return nil, fmt.Errorf("上传回放语料不能为空")
+		}
+		requests, err = waf.ParseReplayCorpus(corpus, req.CorpusFormat, sampleSize)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("回放来源不支持: %s", req.Source)
+	}
+
+	warnings := make([]string, 0)
+	layout, crsVersion, crsWarning := loadWafReplayCRSLayout(db)
+	if crsWarning != "" {
+		warnings = append(warnings, crsWarning)
+	}
+
+	candidateDirectives, err := buildPolicyDirectivesWithExclusions(db, &policy)
+	if err != nil {
+		return nil, err
+	}
+	candidateEngine, err := buildWafReplayEngine(candidateDirectives, layout)
+	if err != nil {
+		return nil, fmt.Errorf("候选策略加载失败: %w", err)
+	}
+
+	var (
+		baselineEngine     *waf.ReplayEngine
+		baselineRevisionID uint
+	)
+	var revision model.WafPolicyRevision
+	revisionErr := db.Where("policy_id = ? AND status = ?", policy.ID, wafPolicyStatusPublished).Order("id desc").First(&revision).Error
+	switch {
+	case revisionErr == nil:
+		baselineRevisionID = revision.ID
+		baselineEngine, err = buildWafReplayEngine(revision.DirectivesSnapshot, layout)
+		if err != nil {
+			return nil, fmt.Errorf("已发布版本 #%d 加载失败: %w", revision.ID, err)
+		}
+	case errors.Is(revisionErr, gorm.ErrRecordNotFound):
+		warnings = append(warnings, "策略尚无已发布版本，基线按全部放行计算")
+	default:
+		return nil, fmt.Errorf("查询已发布版本失败: %w", revisionErr)
+	}
+
+	outcomes := make([]wafReplayOutcome, 0, len(requests))
+	for _, request := range requests {
+		outcome := wafReplayOutcome{Request: request, Candidate: candidateEngine.Evaluate(request)}
+		if baselineEngine != nil {
+			outcome.Baseline = baselineEngine.Evaluate(request)
+		}
+		outcomes = append(outcomes, outcome)
+	}
+	if len(requests) == 0 {
+		warnings = append(warnings, "没有可回放的请求")
+	}
+
+	resp = summarizeWafReplayOutcomes(outcomes)
+	resp.PolicyId = policy.ID
+	resp.BaselineRevisionId = baselineRevisionID
+	resp.CrsVersion = crsVersion
+	resp.Warnings = warnings
+	resp.DurationMs = time.Since(startedAt).Milliseconds()
+	return resp, nil
+}
@@ -0,0 +1,40 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type RevokeWafBanLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewRevokeWafBanLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RevokeWafBanLogic {
+	return &RevokeWafBanLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *RevokeWafBanLogic) RevokeWafBan(req *types.IDReq) (resp *types.BaseResp, err error) {
+	defer func() {
+		err = localizeWafPolicyError(err)
+	}()
+
+	if req == nil || req.ID == 0 {
+		return nil, fmt.Errorf("封禁记录 ID 不能为空")
+	}
+	if err := NewWafBanService(l.ctx, l.svcCtx).Revoke(req.ID, currentOperatorFromContext(l.ctx)); err != nil {
+		return nil, err
+	}
+
+	return &types.BaseResp{Code: 200, Msg: "成功"}, nil
+}
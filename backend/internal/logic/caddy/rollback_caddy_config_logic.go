@@ -3,6 +3,7 @@ package caddy
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"logflux/internal/svc"
 	"logflux/internal/types"
@@ -28,14 +29,21 @@ func NewRollbackCaddyConfigLogic(ctx context.Context, svcCtx *svc.ServiceContext
 }
 
 func (l *RollbackCaddyConfigLogic) RollbackCaddyConfig(req *types.CaddyConfigRollbackReq) (resp *types.BaseResp, err error) {
+	if err := ensureDirectCaddyChangeAllowed(l.svcCtx); err != nil {
+		return nil, err
+	}
 	var server model.CaddyServer
 	if err := l.svcCtx.DB.WithContext(l.ctx).First(&server, req.ServerId).Error; err != nil {
 		return nil, fmt.Errorf("服务器不存在")
 	}
 
-	var history model.CaddyConfigHistory
-	if err := l.svcCtx.DB.WithContext(l.ctx).Where("id = ? AND server_id = ?", req.HistoryId, req.ServerId).First(&history).Error; err != nil {
-		return nil, fmt.Errorf("历史记录不存在")
+	history, err := l.resolveTarget(&server, req.HistoryId)
+	if err != nil {
+		return nil, err
+	}
+
+	if isCaddyJSONSnapshot(history.Config) {
+		return l.reapplySnapshot(&server, history, req.Message)
 	}
 
 	if err := adaptCaddyfile(&server, history.Config); err != nil {
@@ -51,13 +59,14 @@ func (l *RollbackCaddyConfigLogic) RollbackCaddyConfig(req *types.CaddyConfigRol
 		if err := tx.Save(&server).Error; err != nil {
 			return err
 		}
-		record := &model.CaddyConfigHistory{
-			ServerID: server.ID,
-			Action:   "rollback",
-			Hash:     hashConfig(history.Config),
-			Config:   history.Config,
-			Modules:  history.Modules,
+		message := strings.TrimSpace(req.Message)
+		if message == "" {
+			message = fmt.Sprintf("回滚到版本 #%d", history.ID)
 		}
+		record := newCaddyConfigHistory(server.ID, "rollback", history.Config, history.Modules, caddyConfigHistoryNote{
+			Operator: currentOperatorFromContext(l.ctx),
+			Message:  message,
+		})
 		return tx.Create(record).Error
 	}); err != nil {
 		return nil, fmt.Errorf("保存配置到数据库失败")
@@ -69,6 +78,41 @@ func (l *RollbackCaddyConfigLogic) RollbackCaddyConfig(req *types.CaddyConfigRol
 
 	return &types.BaseResp{
 		Code: 200,
-		Msg:  "成功",
+		Msg:  fmt.Sprintf("已回滚到版本 #%d", history.ID),
 	}, nil
 }
+
+// reapplySnapshot 重新下发运行配置快照（drift_snapshot），保存的 Caddyfile 不变，之后的漂移检测仍以其为准
+func (l *RollbackCaddyConfigLogic) reapplySnapshot(server *model.CaddyServer, history *model.CaddyConfigHistory, message string) (*types.BaseResp, error) {
+	if err := loadCaddyJSON(server, history.Config); err != nil {
+		return nil, fmt.Errorf("Caddy API 错误: %v", err)
+	}
+	message = strings.TrimSpace(message)
+	if message == "" {
+		message = fmt.Sprintf("重新下发运行配置快照 #%d，保存的 Caddyfile 未变更", history.ID)
+	}
+	record := newCaddyConfigHistory(server.ID, "rollback", history.Config, history.Modules, caddyConfigHistoryNote{
+		Operator: currentOperatorFromContext(l.ctx),
+		Message:  message,
+	})
+	if err := l.svcCtx.DB.WithContext(l.ctx).Create(record).Error; err != nil {
+		return nil, fmt.Errorf("保存配置历史失败")
+	}
+	return &types.BaseResp{
+		Code: 200,
+		Msg:  fmt.Sprintf("已重新下发运行配置快照 #%d", history.ID),
+	}, nil
+}
+
+// resolveTarget 未指定历史版本时回滚到最近的已知良好版本
+func (l *RollbackCaddyConfigLogic) resolveTarget(server *model.CaddyServer, historyID uint) (*model.CaddyConfigHistory, error) {
+	db := l.svcCtx.DB.WithContext(l.ctx)
+	if historyID == 0 {
+		return findKnownGoodCaddyHistory(db, server.ID, hashConfig(server.Config))
+	}
+	var history model.CaddyConfigHistory
+	if err := db.Where("id = ? AND server_id = ?", historyID, server.ID).First(&history).Error; err != nil {
+		return nil, fmt.Errorf("历史记录不存在")
+	}
+	return &history, nil
+}
@@ -0,0 +1,47 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type RotateWafMirrorClientLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewRotateWafMirrorClientLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RotateWafMirrorClientLogic {
+	return &RotateWafMirrorClientLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// RotateWafMirrorClient 重新生成凭据，旧凭据立即失效
+func (l *RotateWafMirrorClientLogic) RotateWafMirrorClient(req *types.IDReq) (resp *types.BaseResp, err error) {
+	if req == nil || req.ID == 0 {
+		return nil, fmt.Errorf("镜像凭据 ID 不能为空")
+	}
+	token, err := generateWafMirrorToken()
+	if err != nil {
+		return nil, err
+	}
+
+	result := l.svcCtx.DB.WithContext(l.ctx).Model(&model.WafMirrorClient{}).Where("id = ?", req.ID).Update("token", token)
+	if result.Error != nil {
+		return nil, fmt.Errorf("重置镜像凭据失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("镜像凭据不存在")
+	}
+
+	return &types.BaseResp{Code: 200, Msg: "成功"}, nil
+}
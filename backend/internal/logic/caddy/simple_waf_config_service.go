@@ -121,6 +121,9 @@ func (s *simpleWafConfigService) Preview(req *types.SimpleWafConfigUpdateReq) (*
 }
 
 func (s *simpleWafConfigService) Apply(req *types.SimpleWafConfigUpdateReq) (*types.SimpleWafConfigResp, error) {
+	if err := ensureDirectCaddyChangeAllowed(s.svcCtx); err != nil {
+		return nil, err
+	}
 	candidate, err := s.buildCandidate(req)
 	if err != nil {
 		return nil, err
@@ -277,7 +280,7 @@ func (s *simpleWafConfigService) persistAppliedCandidate(candidate *simpleWafCan
 		if err := tx.Save(candidate.Policy).Error; err != nil {
 			return fmt.Errorf("保存简单 WAF 策略失败: %w", err)
 		}
-		if err := createCaddyPolicyHistory(tx, candidate.Server.ID, "simple_waf_last_good", candidate.LastGood, candidate.LastModules); err != nil {
+		if err := createCaddyPolicyHistory(tx, candidate.Server.ID, "simple_waf_last_good", candidate.LastGood, candidate.LastModules, currentOperatorFromContext(s.ctx)); err != nil {
 			return err
 		}
 		if err := tx.Model(&model.CaddyServer{}).
@@ -288,7 +291,7 @@ func (s *simpleWafConfigService) persistAppliedCandidate(candidate *simpleWafCan
 			}).Error; err != nil {
 			return fmt.Errorf("保存 Caddy 服务器配置失败: %w", err)
 		}
-		if err := createCaddyPolicyHistory(tx, candidate.Server.ID, "simple_waf_apply", candidate.Config, candidate.Modules); err != nil {
+		if err := createCaddyPolicyHistory(tx, candidate.Server.ID, "simple_waf_apply", candidate.Config, candidate.Modules, currentOperatorFromContext(s.ctx)); err != nil {
 			return err
 		}
 		revision, err := createPolicyRevision(tx, candidate.Policy, wafPolicyStatusPublished, candidate.Directives, "simple waf apply", currentOperatorFromContext(s.ctx))
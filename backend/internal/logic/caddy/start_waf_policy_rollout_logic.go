@@ -0,0 +1,55 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type StartWafPolicyRolloutLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewStartWafPolicyRolloutLogic(ctx context.Context, svcCtx *svc.ServiceContext) *StartWafPolicyRolloutLogic {
+	return &StartWafPolicyRolloutLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// StartWafPolicyRollout 候选指令以 DetectionOnly 灰度运行于选定绑定作用域，已发布版本继续拦截
+func (l *StartWafPolicyRolloutLogic) StartWafPolicyRollout(req *types.WafPolicyRolloutStartReq) (resp *types.WafPolicyRolloutItem, err error) {
+	defer func() {
+		err = localizeWafPolicyError(err)
+	}()
+	if err := ensureDirectCaddyChangeAllowed(l.svcCtx); err != nil {
+		return nil, err
+	}
+	if req == nil || req.ID == 0 {
+		return nil, fmt.Errorf("策略 ID 不能为空")
+	}
+
+	service := NewWafRolloutService(l.ctx, l.svcCtx)
+	rollout, err := service.Start(WafRolloutStartParams{
+		PolicyID:             req.ID,
+		BindingIDs:           req.BindingIds,
+		AutoPromote:          req.AutoPromote,
+		MaxFalsePositiveRate: req.MaxFalsePositiveRate,
+		ObserveHours:         req.ObserveHours,
+		Message:              req.Message,
+	}, currentOperatorFromContext(l.ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	l.Infof("WAF 策略灰度发布已开始: rollout=%d policy=%d bindings=%v", rollout.ID, rollout.PolicyID, req.BindingIds)
+	item := toWafPolicyRolloutItem(rollout, "")
+	return &item, nil
+}
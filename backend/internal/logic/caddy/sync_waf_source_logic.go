@@ -47,7 +47,7 @@ func (l *SyncWafSourceLogic) SyncWafSource(req *types.WafSourceSyncReq) (resp *t
 	if normalizeWafKind(source.Kind) == wafKindCorazaEngine {
 		return nil, fmt.Errorf("Coraza 引擎更新源无需手工同步，请直接使用引擎版本检查")
 	}
-	if source.Mode != wafModeRemote {
+	if !isWafRemoteMode(source.Mode) {
 		return nil, fmt.Errorf("源模式不是远程模式")
 	}
 	if strings.TrimSpace(source.URL) == "" {
@@ -61,7 +61,7 @@ func (l *SyncWafSourceLogic) SyncWafSource(req *types.WafSourceSyncReq) (resp *t
 
 	downloadURL := strings.TrimSpace(source.URL)
 	version := deriveVersionFromURL(downloadURL)
-	if normalizeWafKind(source.Kind) == wafKindCRS {
+	if normalizeWafKind(source.Kind) == wafKindCRS && source.Mode != wafModeMirror {
 		resolvedURL, resolvedVersion := helper.resolveCRSSyncTarget(&source)
 		if strings.TrimSpace(resolvedURL) != "" {
 			downloadURL = strings.TrimSpace(resolvedURL)
@@ -72,24 +72,32 @@ func (l *SyncWafSourceLogic) SyncWafSource(req *types.WafSourceSyncReq) (resp *t
 	}
 
 	job := helper.startJob(source.ID, 0, "download", "manual")
+	expectedSHA256 := ""
+	if source.Mode == wafModeMirror {
+		mirrorManifest, err := l.fetchMirrorManifest(helper, &source, fetchTimeoutSec)
+		if err != nil {
+			helper.updateSourceLastCheck(source.ID, "", err.Error())
+			helper.finishJob(job, wafJobStatusFailed, err.Error(), 0)
+			return nil, err
+		}
+		downloadURL = mirrorManifest.PackageURL
+		version = sanitizeToken(mirrorManifest.Version)
+		expectedSHA256 = mirrorManifest.PackageSHA256
+		// 未单独配置签名地址、且签名类型与上游清单一致时使用清单给出的签名地址
+		if strings.TrimSpace(source.SignatureURL) == "" && mirrorManifestSignatureMatches(mirrorManifest, source.SignatureType) {
+			source.SignatureURL = mirrorManifest.SignatureURL
+		}
+	}
 	existingRelease, err := findLatestReleaseByKindAndVersion(helper.svcCtx.DB.WithContext(helper.ctx), source.Kind, version)
 	if err != nil {
 		helper.updateSourceLastCheck(source.ID, version, err.Error())
 		helper.finishJob(job, wafJobStatusFailed, err.Error(), 0)
 		return nil, err
 	}
-	if existingRelease != nil && helper.canReuseRelease(existingRelease) {
-		helper.updateSourceLastCheck(source.ID, existingRelease.Version, "")
-		helper.finishJob(job, wafJobStatusSuccess, "版本已存在，复用已有版本", existingRelease.ID)
-
-		if normalizeWafKind(source.Kind) != wafKindCorazaEngine && (req.ActivateNow || source.AutoActivate) {
-			activateLogic := NewActivateWafReleaseLogic(l.ctx, l.svcCtx)
-			if _, activateErr := activateLogic.ActivateWafRelease(&types.WafReleaseActivateReq{ID: existingRelease.ID}); activateErr != nil {
-				return nil, activateErr
-			}
-		}
-
-		return &types.BaseResp{Code: 200, Msg: "成功"}, nil
+	// 源要求签名时，只有由源当前受信公钥校验过签名的已有版本可以直接复用，否则先下载并校验签名
+	reusableRelease := existingRelease != nil && helper.canReuseRelease(existingRelease)
+	if reusableRelease && wafReleaseSignatureSatisfied(existingRelease, source.SignatureType, source.PublicKeys) {
+		return l.reuseRelease(helper, req, &source, existingRelease, job)
 	}
 
 	ext := detectPackageExt(downloadURL)
@@ -131,9 +139,26 @@ func (l *SyncWafSourceLogic) SyncWafSource(req *types.WafSourceSyncReq) (resp *t
 		return nil, normalizedErr
 	}
 
+	var signatureOptions *waf.SignatureOptions
+	if source.SignatureType != "" && source.SignatureType != waf.SignatureTypeNone {
+		signature, err := l.fetchSignature(helper, &source, downloadURL, version, fetchResult.SavedPath, fetchTimeoutSec)
+		if err != nil {
+			helper.updateSourceLastCheck(source.ID, "", err.Error())
+			helper.finishJob(job, wafJobStatusFailed, err.Error(), 0)
+			return nil, err
+		}
+		signatureOptions = &waf.SignatureOptions{
+			Type:       source.SignatureType,
+			Signature:  signature,
+			PublicKeys: source.PublicKeys,
+		}
+	}
+
 	verifyResult, err := waf.VerifyPackage(fetchResult.SavedPath, waf.VerifyOptions{
 		AllowedExt:      []string{".tar.gz", ".zip"},
 		MaxPackageBytes: helper.svcCtx.Config.Waf.MaxPackageBytes,
+		ExpectedSHA256:  expectedSHA256,
+		Signature:       signatureOptions,
 	})
 	if err != nil {
 		helper.updateSourceLastCheck(source.ID, "", err.Error())
@@ -141,6 +166,28 @@ func (l *SyncWafSourceLogic) SyncWafSource(req *types.WafSourceSyncReq) (resp *t
 		return nil, err
 	}
 
+	if reusableRelease {
+		if !strings.EqualFold(existingRelease.Checksum, verifyResult.SHA256) {
+			err := fmt.Errorf("版本 %s 已存在但与签名校验通过的包校验和不一致，拒绝复用", existingRelease.Version)
+			helper.updateSourceLastCheck(source.ID, "", err.Error())
+			helper.finishJob(job, wafJobStatusFailed, err.Error(), 0)
+			return nil, err
+		}
+		meta := model.JSONMap{}
+		for key, value := range existingRelease.Meta {
+			meta[key] = value
+		}
+		for key, value := range wafReleaseSignatureMeta(verifyResult.Signature) {
+			meta[key] = value
+		}
+		if err := helper.svcCtx.DB.WithContext(helper.ctx).Model(existingRelease).Update("meta", meta).Error; err != nil {
+			helper.updateSourceLastCheck(source.ID, "", err.Error())
+			helper.finishJob(job, wafJobStatusFailed, fmt.Sprintf("更新版本签名信息失败: %v", err), 0)
+			return nil, fmt.Errorf("更新版本签名信息失败: %w", err)
+		}
+		return l.reuseRelease(helper, req, &source, existingRelease, job)
+	}
+
 	packageName := fmt.Sprintf("%s_%s%s", sanitizeToken(source.Name), sanitizeToken(version), verifyResult.Ext)
 	packagePath := helper.store.PackagePath(packageName)
 	if err := os.Rename(fetchResult.SavedPath, packagePath); err != nil {
@@ -177,6 +224,7 @@ func (l *SyncWafSourceLogic) SyncWafSource(req *types.WafSourceSyncReq) (resp *t
 		StoragePath:  filepath.Clean(releaseDir),
 		Status:       wafReleaseStatusVerified,
 	}
+	release.Meta = helper.attachReleaseRuleDiff(wafReleaseSignatureMeta(verifyResult.Signature), release)
 
 	if err := helper.svcCtx.DB.WithContext(helper.ctx).Create(release).Error; err != nil {
 		helper.updateSourceLastCheck(source.ID, "", err.Error())
@@ -198,6 +246,133 @@ func (l *SyncWafSourceLogic) SyncWafSource(req *types.WafSourceSyncReq) (resp *t
 	return &types.BaseResp{Code: 200, Msg: "成功"}, nil
 }
 
+// reuseRelease 复用同版本的已有版本记录，按需激活
+func (l *SyncWafSourceLogic) reuseRelease(helper *wafLogicHelper, req *types.WafSourceSyncReq, source *model.WafSource, release *model.WafRelease, job *model.WafUpdateJob) (*types.BaseResp, error) {
+	helper.updateSourceLastCheck(source.ID, release.Version, "")
+	helper.finishJob(job, wafJobStatusSuccess, "版本已存在，复用已有版本", release.ID)
+
+	if normalizeWafKind(source.Kind) != wafKindCorazaEngine && (req.ActivateNow || source.AutoActivate) {
+		activateLogic := NewActivateWafReleaseLogic(l.ctx, l.svcCtx)
+		if _, activateErr := activateLogic.ActivateWafRelease(&types.WafReleaseActivateReq{ID: release.ID}); activateErr != nil {
+			return nil, activateErr
+		}
+	}
+
+	return &types.BaseResp{Code: 200, Msg: "成功"}, nil
+}
+
+// wafReleaseSignatureSatisfied 源未要求签名，或已有版本记录了同类型的签名校验结果且签名公钥仍在源的受信公钥中；
+// 公钥轮换或移除后，旧公钥校验过的版本需要重新下载校验
+func wafReleaseSignatureSatisfied(release *model.WafRelease, signatureType, publicKeys string) bool {
+	normalized, err := waf.NormalizeSignatureType(signatureType)
+	if err != nil {
+		return false
+	}
+	if normalized == waf.SignatureTypeNone {
+		return true
+	}
+	if release == nil {
+		return false
+	}
+	verifiedType, _ := release.Meta["signatureType"].(string)
+	verifiedAt, _ := release.Meta["signatureVerifiedAt"].(string)
+	keyID, _ := release.Meta["signatureKeyId"].(string)
+	if verifiedType != normalized || strings.TrimSpace(verifiedAt) == "" || strings.TrimSpace(keyID) == "" {
+		return false
+	}
+	trustedIDs, err := waf.TrustedKeyIDs(normalized, publicKeys)
+	if err != nil {
+		return false
+	}
+	for _, trustedID := range trustedIDs {
+		if strings.EqualFold(trustedID, keyID) {
+			return true
+		}
+	}
+	return false
+}
+
+// mirrorManifestSignatureMatches 清单签名类型为空时按旧版镜像视为 minisign
+func mirrorManifestSignatureMatches(manifest *waf.MirrorManifest, signatureType string) bool {
+	if manifest == nil || strings.TrimSpace(manifest.SignatureURL) == "" {
+		return false
+	}
+	sourceType, err := waf.NormalizeSignatureType(signatureType)
+	if err != nil || sourceType == waf.SignatureTypeNone {
+		return false
+	}
+	manifestType, err := waf.NormalizeSignatureType(manifest.SignatureType)
+	if err != nil {
+		return false
+	}
+	if manifestType == waf.SignatureTypeNone {
+		manifestType = waf.SignatureTypeMinisign
+	}
+	return manifestType == sourceType
+}
+
+// fetchSignature 下载与包对应的分离签名，签名与包走相同的域名白名单与认证配置
+func (l *SyncWafSourceLogic) fetchSignature(helper *wafLogicHelper, source *model.WafSource, downloadURL, version, packagePath string, timeoutSec int) ([]byte, error) {
+	signatureURL := waf.DefaultSignatureURL(source.SignatureURL, downloadURL, version, source.SignatureType)
+	if signatureURL == "" {
+		return nil, fmt.Errorf("签名地址为空")
+	}
+	signaturePath := packagePath + ".sig"
+	defer func() {
+		_ = os.Remove(signaturePath)
+	}()
+
+	options := waf.FetchOptions{
+		AllowedDomains: helper.svcCtx.Config.Waf.AllowedDomains,
+		AuthType:       source.AuthType,
+		AuthSecret:     source.AuthSecret,
+		ProxyURL:       source.ProxyURL,
+		TimeoutSec:     timeoutSec,
+		MaxBytes:       waf.MaxSignatureBytes,
+	}
+	hasProxy := strings.TrimSpace(source.ProxyURL) != ""
+	_, err := waf.FetchPackage(signatureURL, signaturePath, options)
+	if err != nil && hasProxy {
+		options.ProxyURL = ""
+		_, err = waf.FetchPackage(signatureURL, signaturePath, options)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("下载签名失败: %w", normalizeWafSyncFetchError(err, hasProxy))
+	}
+
+	signature, err := os.ReadFile(signaturePath)
+	if err != nil {
+		return nil, fmt.Errorf("读取签名失败: %w", err)
+	}
+	return signature, nil
+}
+
+// fetchMirrorManifest 从上游 LogFlux 镜像获取当前可用（或固定）的版本清单
+func (l *SyncWafSourceLogic) fetchMirrorManifest(helper *wafLogicHelper, source *model.WafSource, timeoutSec int) (*waf.MirrorManifest, error) {
+	manifestURL := strings.TrimSpace(source.URL)
+	tempPath := helper.store.StagePath(fmt.Sprintf("%s_%d_manifest.json", sanitizeToken(source.Name), time.Now().UnixNano()))
+	options := waf.FetchOptions{
+		AllowedDomains: helper.svcCtx.Config.Waf.AllowedDomains,
+		AuthType:       source.AuthType,
+		AuthSecret:     source.AuthSecret,
+		ProxyURL:       source.ProxyURL,
+		TimeoutSec:     timeoutSec,
+	}
+	hasProxy := strings.TrimSpace(source.ProxyURL) != ""
+	manifest, err := waf.FetchMirrorManifest(manifestURL, tempPath, options)
+	if err != nil && hasProxy {
+		options.ProxyURL = ""
+		manifest, err = waf.FetchMirrorManifest(manifestURL, tempPath, options)
+	}
+	if err != nil {
+		return nil, normalizeWafSyncFetchError(err, hasProxy)
+	}
+	if manifest.Kind != "" && normalizeWafKind(manifest.Kind) != normalizeWafKind(source.Kind) {
+		return nil, fmt.Errorf("镜像规则类型 %s 与源类型 %s 不一致", manifest.Kind, source.Kind)
+	}
+	return manifest, nil
+}
+
 func normalizeWafSyncFetchError(fetchErr error, hasProxy bool) error {
 	if fetchErr == nil {
 		return nil
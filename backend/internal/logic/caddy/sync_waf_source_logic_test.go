@@ -3,6 +3,7 @@ package caddy
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -14,6 +15,8 @@ import (
 	"logflux/internal/config"
 	"logflux/internal/svc"
 	"logflux/internal/types"
+	"logflux/internal/waf"
+	"logflux/model"
 )
 
 func TestSyncWafSourceGuardFailures(t *testing.T) {
@@ -154,6 +157,57 @@ func TestNormalizeWafSyncFetchError(t *testing.T) {
 	}
 }
 
+func TestWafReleaseSignatureSatisfied(t *testing.T) {
+	trusted, err := waf.LoadOrCreateBundleSigner(filepath.Join(t.TempDir(), "trusted.json"))
+	if err != nil {
+		t.Fatalf("create signer: %v", err)
+	}
+	rotated, err := waf.LoadOrCreateBundleSigner(filepath.Join(t.TempDir(), "rotated.json"))
+	if err != nil {
+		t.Fatalf("create signer: %v", err)
+	}
+	verified := &model.WafRelease{Meta: model.JSONMap{"signatureType": "minisign", "signatureKeyId": trusted.KeyID(), "signatureVerifiedAt": "2026-03-01 10:00:00"}}
+	withoutKeyID := &model.WafRelease{Meta: model.JSONMap{"signatureType": "minisign", "signatureVerifiedAt": "2026-03-01 10:00:00"}}
+	unsigned := &model.WafRelease{Meta: model.JSONMap{"ruleDiff": "x"}}
+
+	cases := []struct {
+		name          string
+		release       *model.WafRelease
+		signatureType string
+		publicKeys    string
+		want          bool
+	}{
+		{"no signature required", unsigned, "none", "", true},
+		{"empty type means none", unsigned, "", "", true},
+		{"unsigned release", unsigned, "minisign", trusted.PublicKey(), false},
+		{"verified by trusted key", verified, "minisign", trusted.PublicKey(), true},
+		{"verified by one of several keys", verified, "minisign", rotated.PublicKey() + "\n" + trusted.PublicKey(), true},
+		{"key rotated away", verified, "minisign", rotated.PublicKey(), false},
+		{"missing key id", withoutKeyID, "minisign", trusted.PublicKey(), false},
+		{"verified other type", verified, "gpg", trusted.PublicKey(), false},
+		{"invalid type", verified, "x509", trusted.PublicKey(), false},
+	}
+	for _, tc := range cases {
+		if got := wafReleaseSignatureSatisfied(tc.release, tc.signatureType, tc.publicKeys); got != tc.want {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestMirrorManifestSignatureMatches(t *testing.T) {
+	legacy := &waf.MirrorManifest{SignatureURL: "https://mirror.example.com/package.tar.gz.minisig"}
+	if !mirrorManifestSignatureMatches(legacy, "minisign") {
+		t.Fatalf("expected legacy manifest to be treated as minisign")
+	}
+	if mirrorManifestSignatureMatches(legacy, "gpg") || mirrorManifestSignatureMatches(legacy, "none") {
+		t.Fatalf("expected mismatched signature types to keep the source signature url")
+	}
+	cosign := &waf.MirrorManifest{SignatureURL: "https://mirror.example.com/package.tar.gz.sig", SignatureType: "cosign"}
+	if !mirrorManifestSignatureMatches(cosign, "cosign") || mirrorManifestSignatureMatches(cosign, "minisign") {
+		t.Fatalf("unexpected match result for cosign manifest")
+	}
+}
+
 func newSyncSourceMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock, func()) {
 	t.Helper()
 
@@ -0,0 +1,44 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type TestCaddyApplyVerifyLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewTestCaddyApplyVerifyLogic(ctx context.Context, svcCtx *svc.ServiceContext) *TestCaddyApplyVerifyLogic {
+	return &TestCaddyApplyVerifyLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// TestCaddyApplyVerify 对当前运行的配置立即执行一次校验，错误率统计最近一个观察窗口，不会触发回滚
+func (l *TestCaddyApplyVerifyLogic) TestCaddyApplyVerify(req *types.CaddyConfigReq) (resp *types.CaddyApplyVerifyResultResp, err error) {
+	db := l.svcCtx.DB.WithContext(l.ctx)
+	var server model.CaddyServer
+	if err := db.First(&server, req.ServerId).Error; err != nil {
+		return nil, fmt.Errorf("服务器不存在")
+	}
+	plan, err := loadCaddyApplyVerifyPlan(db, server.ID)
+	if err != nil {
+		return nil, err
+	}
+	if plan == nil {
+		return nil, fmt.Errorf("未启用配置校验或没有可执行的检查项")
+	}
+	return newCaddyApplyVerifier(db).verify(plan, server.Config, time.Now(), false).toResp(), nil
+}
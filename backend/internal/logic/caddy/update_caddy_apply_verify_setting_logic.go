@@ -0,0 +1,84 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type UpdateCaddyApplyVerifySettingLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUpdateCaddyApplyVerifySettingLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateCaddyApplyVerifySettingLogic {
+	return &UpdateCaddyApplyVerifySettingLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UpdateCaddyApplyVerifySettingLogic) UpdateCaddyApplyVerifySetting(req *types.CaddyApplyVerifySettingReq) (resp *types.BaseResp, err error) {
+	db := l.svcCtx.DB.WithContext(l.ctx)
+	var server model.CaddyServer
+	if err := db.First(&server, req.ServerId).Error; err != nil {
+		return nil, fmt.Errorf("服务器不存在")
+	}
+
+	if req.ErrorWindowSeconds < 0 || req.ErrorWindowSeconds > caddyVerifyMaxWindowSeconds {
+		return nil, fmt.Errorf("错误率观察窗口需在 0-%d 秒之间", caddyVerifyMaxWindowSeconds)
+	}
+	maxErrorRate := req.MaxErrorRate
+	if maxErrorRate <= 0 {
+		maxErrorRate = 0.5
+	}
+	if maxErrorRate > 1 {
+		return nil, fmt.Errorf("5xx 占比阈值需在 0-1 之间")
+	}
+	minRequests := req.MinRequests
+	if minRequests <= 0 {
+		minRequests = 20
+	}
+	probes, err := normalizeCaddyHealthProbes(server.ID, req.Probes)
+	if err != nil {
+		return nil, err
+	}
+
+	setting := model.CaddyApplyVerifySetting{
+		ServerID:           server.ID,
+		Enabled:            req.Enabled,
+		ErrorWindowSeconds: req.ErrorWindowSeconds,
+		MaxErrorRate:       maxErrorRate,
+		MinRequests:        minRequests,
+	}
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "server_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"enabled", "error_window_seconds", "max_error_rate", "min_requests", "updated_at"}),
+		}).Create(&setting).Error; err != nil {
+			return fmt.Errorf("保存配置校验设置失败: %w", err)
+		}
+		// 探测项整体替换
+		if err := tx.Where("server_id = ?", server.ID).Delete(&model.CaddyHealthProbe{}).Error; err != nil {
+			return fmt.Errorf("清理健康探测失败: %w", err)
+		}
+		if len(probes) > 0 {
+			if err := tx.Create(&probes).Error; err != nil {
+				return fmt.Errorf("保存健康探测失败: %w", err)
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return &types.BaseResp{Code: 200, Msg: "成功"}, nil
+}
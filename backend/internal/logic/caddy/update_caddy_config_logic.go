@@ -29,11 +29,14 @@ func NewUpdateCaddyConfigLogic(ctx context.Context, svcCtx *svc.ServiceContext)
 }
 
 func (l *UpdateCaddyConfigLogic) UpdateCaddyConfig(req *types.CaddyConfigUpdateReq) (resp *types.BaseResp, err error) {
+	if err := ensureDirectCaddyChangeAllowed(l.svcCtx); err != nil {
+		return nil, err
+	}
 	var server model.CaddyServer
 	if err := l.svcCtx.DB.WithContext(l.ctx).First(&server, req.ServerId).Error; err != nil {
 		return nil, fmt.Errorf("服务器不存在")
 	}
-	applyService := newCaddyConfigApplyService(l.svcCtx, l.Logger)
+	applyService := newCaddyConfigApplyService(l.svcCtx, l.Logger).withNote(currentOperatorFromContext(l.ctx), req.Message)
 
 	modulesPayload := strings.TrimSpace(req.Modules)
 	if modulesPayload == "" {
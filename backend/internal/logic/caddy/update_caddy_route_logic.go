@@ -0,0 +1,35 @@
+package caddy
+
+import (
+	"context"
+
+	"logflux/internal/caddyfile"
+	"logflux/internal/svc"
+	"logflux/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UpdateCaddyRouteLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUpdateCaddyRouteLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateCaddyRouteLogic {
+	return &UpdateCaddyRouteLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UpdateCaddyRouteLogic) UpdateCaddyRoute(req *types.CaddyRouteUpdateReq) (resp *types.CaddySiteEditResp, err error) {
+	return newCaddySiteEditor(l.svcCtx, l.Logger, currentOperatorFromContext(l.ctx)).edit(req.ServerId, req.ConfigHash, req.DryRun, func(doc *caddyfile.Document) error {
+		site, err := caddySiteAt(doc, req.SiteIndex)
+		if err != nil {
+			return err
+		}
+		return doc.UpdateRoute(site, req.RouteIndex, fromCaddyRouteItem(req.Route))
+	})
+}
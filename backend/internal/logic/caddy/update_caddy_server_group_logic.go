@@ -0,0 +1,53 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UpdateCaddyServerGroupLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUpdateCaddyServerGroupLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateCaddyServerGroupLogic {
+	return &UpdateCaddyServerGroupLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UpdateCaddyServerGroupLogic) UpdateCaddyServerGroup(req *types.CaddyServerGroupUpdateReq) (resp *types.BaseResp, err error) {
+	if req == nil || req.ID == 0 {
+		return nil, fmt.Errorf("分组 ID 不能为空")
+	}
+	db := l.svcCtx.DB.WithContext(l.ctx)
+
+	var group model.CaddyServerGroup
+	if err := db.First(&group, req.ID).Error; err != nil {
+		return nil, fmt.Errorf("节点分组不存在")
+	}
+	name, serverIDs, err := normalizeCaddyServerGroup(db, group.ID, req.Name, req.ServerIds)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Model(&group).Updates(map[string]interface{}{
+		"name":        name,
+		"description": strings.TrimSpace(req.Description),
+		"server_ids":  serverIDs,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("更新节点分组失败: %w", err)
+	}
+
+	return &types.BaseResp{Code: 200, Msg: "成功"}, nil
+}
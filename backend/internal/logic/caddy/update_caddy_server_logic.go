@@ -3,6 +3,7 @@ package caddy
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"logflux/internal/notification"
@@ -40,6 +41,7 @@ func (l *UpdateCaddyServerLogic) UpdateCaddyServer(req *types.UpdateCaddyServerR
 	server.Type = req.Type
 	server.Username = req.Username
 	server.Password = req.Password
+	server.CaddyfilePath = strings.TrimSpace(req.CaddyfilePath)
 	server.UpdatedAt = time.Now()
 
 	if err := l.svcCtx.DB.WithContext(l.ctx).Save(&server).Error; err != nil {
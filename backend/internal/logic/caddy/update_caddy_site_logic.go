@@ -0,0 +1,39 @@
+package caddy
+
+import (
+	"context"
+
+	"logflux/internal/caddyfile"
+	"logflux/internal/svc"
+	"logflux/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UpdateCaddySiteLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUpdateCaddySiteLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateCaddySiteLogic {
+	return &UpdateCaddySiteLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UpdateCaddySiteLogic) UpdateCaddySite(req *types.CaddySiteUpdateReq) (resp *types.CaddySiteEditResp, err error) {
+	return newCaddySiteEditor(l.svcCtx, l.Logger, currentOperatorFromContext(l.ctx)).edit(req.ServerId, req.ConfigHash, req.DryRun, func(doc *caddyfile.Document) error {
+		site, err := caddySiteAt(doc, req.SiteIndex)
+		if err != nil {
+			return err
+		}
+		addresses, err := normalizeCaddySiteAddresses(doc, req.Addresses, site)
+		if err != nil {
+			return err
+		}
+		return caddyfile.SetSiteAddresses(site, addresses)
+	})
+}
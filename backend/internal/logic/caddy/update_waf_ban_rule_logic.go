@@ -0,0 +1,73 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UpdateWafBanRuleLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUpdateWafBanRuleLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateWafBanRuleLogic {
+	return &UpdateWafBanRuleLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UpdateWafBanRuleLogic) UpdateWafBanRule(req *types.WafBanRuleUpdateReq) (resp *types.BaseResp, err error) {
+	defer func() {
+		err = localizeWafPolicyError(err)
+	}()
+
+	if req == nil || req.ID == 0 {
+		return nil, fmt.Errorf("封禁规则 ID 不能为空")
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("封禁规则名称不能为空")
+	}
+	host, err := normalizeWafBanHost(req.Host)
+	if err != nil {
+		return nil, err
+	}
+	statusCodes, _, err := normalizeWafBanStatusCodes(req.StatusCodes)
+	if err != nil {
+		return nil, err
+	}
+	threshold, windowMinutes, banMinutes, err := normalizeWafBanRuleLimits(req.Threshold, req.WindowMinutes, req.BanMinutes)
+	if err != nil {
+		return nil, err
+	}
+
+	db := l.svcCtx.DB.WithContext(l.ctx)
+	var rule model.WafBanRule
+	if err := db.First(&rule, req.ID).Error; err != nil {
+		return nil, fmt.Errorf("封禁规则不存在")
+	}
+
+	rule.Name = name
+	rule.Description = strings.TrimSpace(req.Description)
+	rule.Enabled = req.Enabled
+	rule.Host = host
+	rule.StatusCodes = statusCodes
+	rule.Threshold = threshold
+	rule.WindowMinutes = windowMinutes
+	rule.BanMinutes = banMinutes
+	if err := db.Save(&rule).Error; err != nil {
+		return nil, fmt.Errorf("更新封禁规则失败: %w", err)
+	}
+
+	return &types.BaseResp{Code: 200, Msg: "成功"}, nil
+}
@@ -0,0 +1,69 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UpdateWafCustomRuleLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUpdateWafCustomRuleLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateWafCustomRuleLogic {
+	return &UpdateWafCustomRuleLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UpdateWafCustomRuleLogic) UpdateWafCustomRule(req *types.WafCustomRuleUpdateReq) (resp *types.BaseResp, err error) {
+	defer func() {
+		err = localizeWafPolicyError(err)
+	}()
+
+	if req == nil || req.ID == 0 {
+		return nil, fmt.Errorf("自定义规则 ID 不能为空")
+	}
+	db := l.svcCtx.DB.WithContext(l.ctx)
+	if err := validatePolicyIDExists(db, req.PolicyId); err != nil {
+		return nil, err
+	}
+
+	var rule model.WafCustomRule
+	if err := db.First(&rule, req.ID).Error; err != nil {
+		return nil, fmt.Errorf("自定义规则不存在")
+	}
+
+	directive, ruleID, phase, err := normalizeAndValidateCustomRuleDirective(req.Directive, req.Phase)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateCustomRuleIDUnique(db, req.PolicyId, ruleID, rule.ID); err != nil {
+		return nil, err
+	}
+
+	rule.PolicyID = req.PolicyId
+	rule.Name = strings.TrimSpace(req.Name)
+	rule.Description = strings.TrimSpace(req.Description)
+	rule.Enabled = req.Enabled
+	rule.RuleID = ruleID
+	rule.Phase = phase
+	rule.Priority = normalizeCustomRulePriority(req.Priority)
+	rule.Directive = directive
+
+	if err := db.Save(&rule).Error; err != nil {
+		return nil, fmt.Errorf("更新自定义规则失败: %w", err)
+	}
+
+	return &types.BaseResp{Code: 200, Msg: "成功"}, nil
+}
@@ -0,0 +1,79 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UpdateWafIPListLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUpdateWafIPListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateWafIPListLogic {
+	return &UpdateWafIPListLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UpdateWafIPListLogic) UpdateWafIPList(req *types.WafIPListUpdateReq) (resp *types.BaseResp, err error) {
+	defer func() {
+		err = localizeWafPolicyError(err)
+	}()
+
+	if req == nil || req.ID == 0 {
+		return nil, fmt.Errorf("IP 名单 ID 不能为空")
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("IP 名单名称不能为空")
+	}
+	db := l.svcCtx.DB.WithContext(l.ctx)
+	if err := validatePolicyIDExists(db, req.PolicyId); err != nil {
+		return nil, err
+	}
+	if err := validateWafIPListBinding(db, req.PolicyId, req.BindingId); err != nil {
+		return nil, err
+	}
+	action, err := normalizeWafIPListAction(req.Action)
+	if err != nil {
+		return nil, err
+	}
+
+	var list model.WafIPList
+	if err := db.First(&list, req.ID).Error; err != nil {
+		return nil, fmt.Errorf("IP 名单不存在")
+	}
+	if action == wafIPListActionAllow {
+		var countryCount int64
+		if err := db.Model(&model.WafIPListEntry{}).Where("list_id = ? AND kind = ?", list.ID, wafIPListEntryKindCountry).Count(&countryCount).Error; err != nil {
+			return nil, fmt.Errorf("查询 IP 名单条目失败: %w", err)
+		}
+		if countryCount > 0 {
+			return nil, fmt.Errorf("名单包含国家/地区条目，不能改为允许名单")
+		}
+	}
+
+	list.PolicyID = req.PolicyId
+	list.BindingID = req.BindingId
+	list.Name = name
+	list.Description = strings.TrimSpace(req.Description)
+	list.Action = action
+	list.Enabled = req.Enabled
+
+	if err := db.Save(&list).Error; err != nil {
+		return nil, fmt.Errorf("更新 IP 名单失败: %w", err)
+	}
+
+	return &types.BaseResp{Code: 200, Msg: "成功"}, nil
+}
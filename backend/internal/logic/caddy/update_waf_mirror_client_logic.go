@@ -0,0 +1,54 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UpdateWafMirrorClientLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUpdateWafMirrorClientLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateWafMirrorClientLogic {
+	return &UpdateWafMirrorClientLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UpdateWafMirrorClientLogic) UpdateWafMirrorClient(req *types.WafMirrorClientUpdateReq) (resp *types.BaseResp, err error) {
+	if req == nil || req.ID == 0 {
+		return nil, fmt.Errorf("镜像凭据 ID 不能为空")
+	}
+	db := l.svcCtx.DB.WithContext(l.ctx)
+
+	var client model.WafMirrorClient
+	if err := db.First(&client, req.ID).Error; err != nil {
+		return nil, fmt.Errorf("镜像凭据不存在")
+	}
+	name, kind, pinnedVersion, err := normalizeWafMirrorClient(db, client.ID, req.Name, req.Kind, req.PinnedVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	client.Name = name
+	client.Kind = kind
+	client.PinnedVersion = pinnedVersion
+	client.Enabled = req.Enabled
+	client.Description = strings.TrimSpace(req.Description)
+	if err := db.Save(&client).Error; err != nil {
+		return nil, fmt.Errorf("更新镜像凭据失败: %w", err)
+	}
+
+	return &types.BaseResp{Code: 200, Msg: "成功"}, nil
+}
@@ -0,0 +1,68 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UpdateWafRateLimitZoneLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUpdateWafRateLimitZoneLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateWafRateLimitZoneLogic {
+	return &UpdateWafRateLimitZoneLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UpdateWafRateLimitZoneLogic) UpdateWafRateLimitZone(req *types.WafRateLimitZoneUpdateReq) (resp *types.BaseResp, err error) {
+	defer func() {
+		err = localizeWafPolicyError(err)
+	}()
+
+	if req == nil || req.ID == 0 {
+		return nil, fmt.Errorf("限流区域 ID 不能为空")
+	}
+	db := l.svcCtx.DB.WithContext(l.ctx)
+
+	var existing model.WafRateLimitZone
+	if err := db.First(&existing, req.ID).Error; err != nil {
+		return nil, fmt.Errorf("限流区域不存在")
+	}
+	zone, _, err := normalizeWafRateLimitZoneInput(db, wafRateLimitZoneInput{
+		PolicyID:      req.PolicyId,
+		BindingID:     req.BindingId,
+		Name:          req.Name,
+		Description:   req.Description,
+		Enabled:       req.Enabled,
+		KeyType:       req.KeyType,
+		KeyHeader:     req.KeyHeader,
+		Events:        req.Events,
+		WindowSeconds: req.WindowSeconds,
+		Burst:         req.Burst,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureWafRateLimitZoneNameUnique(db, zone.Name, existing.ID); err != nil {
+		return nil, err
+	}
+
+	zone.ID = existing.ID
+	zone.CreatedAt = existing.CreatedAt
+	if err := db.Save(zone).Error; err != nil {
+		return nil, fmt.Errorf("更新限流区域失败: %w", err)
+	}
+
+	return &types.BaseResp{Code: 200, Msg: "成功"}, nil
+}
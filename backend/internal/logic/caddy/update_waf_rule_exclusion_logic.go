@@ -47,14 +47,10 @@ func (l *UpdateWafRuleExclusionLogic) UpdateWafRuleExclusion(req *types.WafRuleE
 	if err != nil {
 		return nil, err
 	}
-	removeType := normalizePolicyRemoveType(req.RemoveType)
-	if err := validatePolicyRemoveType(removeType); err != nil {
+	removeType, removeValue, removeTarget, err := normalizeAndValidateExclusionRemoveFields(req.RemoveType, req.RemoveValue, req.RemoveTarget)
+	if err != nil {
 		return nil, err
 	}
-	removeValue := strings.TrimSpace(req.RemoveValue)
-	if removeValue == "" {
-		return nil, fmt.Errorf("移除值不能为空")
-	}
 
 	exclusion.PolicyID = req.PolicyId
 	exclusion.Name = strings.TrimSpace(req.Name)
@@ -66,6 +62,7 @@ func (l *UpdateWafRuleExclusionLogic) UpdateWafRuleExclusion(req *types.WafRuleE
 	exclusion.Method = method
 	exclusion.RemoveType = removeType
 	exclusion.RemoveValue = removeValue
+	exclusion.RemoveTarget = removeTarget
 
 	if err := l.svcCtx.DB.WithContext(l.ctx).Save(&exclusion).Error; err != nil {
 		return nil, fmt.Errorf("更新策略排除规则失败: %w", err)
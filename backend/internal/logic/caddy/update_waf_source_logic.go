@@ -79,6 +79,16 @@ func (l *UpdateWafSourceLogic) UpdateWafSource(req *types.WafSourceUpdateReq) (r
 	if strings.TrimSpace(req.AuthSecret) != "" {
 		source.AuthSecret = strings.TrimSpace(req.AuthSecret)
 	}
+	if strings.TrimSpace(req.SignatureType) != "" {
+		source.SignatureType = req.SignatureType
+	}
+	source.SignatureURL = req.SignatureUrl
+	if strings.TrimSpace(req.PublicKeys) != "" {
+		source.PublicKeys = req.PublicKeys
+	}
+	if err := normalizeWafSourceTrust(&source); err != nil {
+		return nil, err
+	}
 	if strings.TrimSpace(req.Schedule) != "" {
 		source.Schedule = strings.TrimSpace(req.Schedule)
 	}
@@ -106,7 +116,7 @@ func (l *UpdateWafSourceLogic) UpdateWafSource(req *types.WafSourceUpdateReq) (r
 		source.AutoActivate = false
 	}
 
-	if source.Mode == wafModeRemote && strings.TrimSpace(source.URL) == "" {
+	if isWafRemoteMode(source.Mode) && strings.TrimSpace(source.URL) == "" {
 		return nil, fmt.Errorf("远程源 URL 不能为空")
 	}
 
@@ -130,8 +130,8 @@ func (l *UploadWafPackageLogic) UploadWafPackage(req *types.WafUploadReq) (resp
 		SizeBytes:    verifyResult.SizeBytes,
 		StoragePath:  filepath.Clean(releaseDir),
 		Status:       wafReleaseStatusVerified,
-		Meta:         model.JSONMap{"originFileName": basenameSafe(fileName)},
 	}
+	release.Meta = helper.attachReleaseRuleDiff(model.JSONMap{"originFileName": basenameSafe(fileName)}, release)
 
 	if err := helper.svcCtx.DB.WithContext(helper.ctx).Create(release).Error; err != nil {
 		helper.finishJob(job, wafJobStatusFailed, fmt.Sprintf("创建版本失败: %v", err), 0)
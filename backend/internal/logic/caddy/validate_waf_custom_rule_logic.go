@@ -0,0 +1,43 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ValidateWafCustomRuleLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewValidateWafCustomRuleLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ValidateWafCustomRuleLogic {
+	return &ValidateWafCustomRuleLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ValidateWafCustomRule 仅做语法与 id/phase 校验，不落库
+func (l *ValidateWafCustomRuleLogic) ValidateWafCustomRule(req *types.WafCustomRuleValidateReq) (resp *types.WafCustomRuleValidateResp, err error) {
+	defer func() {
+		err = localizeWafPolicyError(err)
+	}()
+
+	if req == nil {
+		return nil, fmt.Errorf("自定义规则参数不合法")
+	}
+
+	directive, ruleID, phase, err := normalizeAndValidateCustomRuleDirective(req.Directive, req.Phase)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.WafCustomRuleValidateResp{RuleId: ruleID, Phase: phase, Directive: directive}, nil
+}
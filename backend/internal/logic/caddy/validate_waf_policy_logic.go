@@ -44,12 +44,17 @@ func (l *ValidateWafPolicyLogic) ValidateWafPolicy(req *types.WafPolicyActionReq
 		return nil, err
 	}
 
+	extras, err := loadWafPolicyCaddyExtras(l.svcCtx.DB.WithContext(l.ctx), policy.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	server, err := findPrimaryCaddyServer(l.svcCtx.DB.WithContext(l.ctx))
 	if err != nil {
 		return nil, err
 	}
 
-	candidateConfig, err := buildPolicyCandidateCaddyConfig(server.Config, directives, policy.Enabled)
+	candidateConfig, err := buildPolicyCandidateCaddyConfig(server.Config, directives, policy.Enabled, extras)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,66 @@
+package caddy
+
+import (
+	"context"
+	"fmt"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ValidateWafRateLimitZoneLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewValidateWafRateLimitZoneLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ValidateWafRateLimitZoneLogic {
+	return &ValidateWafRateLimitZoneLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ValidateWafRateLimitZone 以仅包含该区域的最小配置调用 /adapt，确认语法正确且 Caddy 已安装 rate_limit 模块
+func (l *ValidateWafRateLimitZoneLogic) ValidateWafRateLimitZone(req *types.WafRateLimitZoneReq) (resp *types.BaseResp, err error) {
+	defer func() {
+		err = localizeWafPolicyError(err)
+	}()
+
+	if req == nil {
+		return nil, fmt.Errorf("限流区域参数不合法")
+	}
+	db := l.svcCtx.DB.WithContext(l.ctx)
+	zone, binding, err := normalizeWafRateLimitZoneInput(db, wafRateLimitZoneInput{
+		PolicyID:      req.PolicyId,
+		BindingID:     req.BindingId,
+		Name:          req.Name,
+		Description:   req.Description,
+		Enabled:       true,
+		KeyType:       req.KeyType,
+		KeyHeader:     req.KeyHeader,
+		Events:        req.Events,
+		WindowSeconds: req.WindowSeconds,
+		Burst:         req.Burst,
+	})
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := buildManagedRateLimitZone(zone, binding)
+	if err != nil {
+		return nil, err
+	}
+
+	server, err := findPrimaryCaddyServer(db)
+	if err != nil {
+		return nil, err
+	}
+	if err := adaptCaddyfile(server, renderWafRateLimitValidationCaddyfile([]managedRateLimitZone{*rendered})); err != nil {
+		return nil, fmt.Errorf("限流区域校验失败，请确认 Caddy 已安装 rate_limit 模块: %w", err)
+	}
+
+	return &types.BaseResp{Code: 200, Msg: "校验通过"}, nil
+}
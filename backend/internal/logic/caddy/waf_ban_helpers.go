@@ -0,0 +1,380 @@
+package caddy
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"logflux/internal/types"
+	"logflux/model"
+
+	"gorm.io/gorm"
+)
+
+const (
+	managedWafBanSnippetName = "waf_bans"
+	wafBanImportLine         = "import " + managedWafBanSnippetName
+
+	wafBanDefaultStatusCodes   = "401,403,429"
+	wafBanDefaultThreshold     = 20
+	wafBanDefaultWindowMinutes = 5
+	wafBanDefaultBanMinutes    = 60
+	wafBanMaxWindowMinutes     = 24 * 60
+	wafBanMaxBanMinutes        = 30 * 24 * 60
+
+	// 单条规则每轮最多封禁的 IP 数，避免异常流量下片段无限膨胀
+	wafBanMaxCandidatesPerRule = 200
+
+	wafBanOperatorSystem = "system"
+)
+
+// normalizeWafBanStatusCodes 规范化逗号分隔的状态码，返回去重排序后的文本与数值
+func normalizeWafBanStatusCodes(raw string) (string, []int, error) {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" {
+		trimmed = wafBanDefaultStatusCodes
+	}
+	seen := make(map[int]struct{})
+	codes := make([]int, 0)
+	for _, part := range strings.Split(trimmed, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		code, err := strconv.Atoi(part)
+		if err != nil || code < 400 || code > 599 {
+			return "", nil, fmt.Errorf("状态码无效: %s", part)
+		}
+		if _, ok := seen[code]; ok {
+			continue
+		}
+		seen[code] = struct{}{}
+		codes = append(codes, code)
+	}
+	if len(codes) == 0 {
+		return "", nil, fmt.Errorf("状态码不能为空")
+	}
+	sort.Ints(codes)
+	parts := make([]string, 0, len(codes))
+	for _, code := range codes {
+		parts = append(parts, strconv.Itoa(code))
+	}
+	return strings.Join(parts, ","), codes, nil
+}
+
+func normalizeWafBanRuleLimits(threshold, windowMinutes, banMinutes int64) (int64, int64, int64, error) {
+	if threshold == 0 {
+		threshold = wafBanDefaultThreshold
+	}
+	if windowMinutes == 0 {
+		windowMinutes = wafBanDefaultWindowMinutes
+	}
+	if banMinutes == 0 {
+		banMinutes = wafBanDefaultBanMinutes
+	}
+	if threshold < 1 {
+		return 0, 0, 0, fmt.Errorf("触发阈值必须大于 0")
+	}
+	if windowMinutes < 1 || windowMinutes > wafBanMaxWindowMinutes {
+		return 0, 0, 0, fmt.Errorf("统计窗口必须在 1-%d 分钟之间", wafBanMaxWindowMinutes)
+	}
+	if err := validateWafBanMinutes(banMinutes); err != nil {
+		return 0, 0, 0, err
+	}
+	return threshold, windowMinutes, banMinutes, nil
+}
+
+func validateWafBanMinutes(minutes int64) error {
+	if minutes < 1 || minutes > wafBanMaxBanMinutes {
+		return fmt.Errorf("封禁时长必须在 1-%d 分钟之间", wafBanMaxBanMinutes)
+	}
+	return nil
+}
+
+// normalizeWafBanHost 站点会直接写入 Caddyfile 匹配器，只允许主机名字符
+func normalizeWafBanHost(host string) (string, error) {
+	normalized := normalizePolicyScopeHost(host)
+	if normalized == "" {
+		return "", nil
+	}
+	for _, ch := range normalized {
+		if !(ch >= 'a' && ch <= 'z' || ch >= '0' && ch <= '9' || strings.ContainsRune(".-:*[]", ch)) {
+			return "", fmt.Errorf("站点格式不合法: %s", host)
+		}
+	}
+	return normalized, nil
+}
+
+// renderWafBanSnippet 按站点分组渲染封禁片段，使用 client_ip 以兼容可信代理；无生效封禁时返回空串
+func renderWafBanSnippet(bans []model.WafBan) string {
+	groups := make(map[string]map[string]struct{})
+	for _, ban := range bans {
+		ip := strings.TrimSpace(ban.IP)
+		if ip == "" {
+			continue
+		}
+		if groups[ban.Host] == nil {
+			groups[ban.Host] = make(map[string]struct{})
+		}
+		groups[ban.Host][ip] = struct{}{}
+	}
+	if len(groups) == 0 {
+		return ""
+	}
+
+	hosts := make([]string, 0, len(groups))
+	for host := range groups {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	lines := []string{"(" + managedWafBanSnippetName + ") {"}
+	for idx, host := range hosts {
+		ips := make([]string, 0, len(groups[host]))
+		for ip := range groups[host] {
+			ips = append(ips, ip)
+		}
+		sort.Strings(ips)
+
+		name := "waf_ban_all"
+		if host != "" {
+			name = fmt.Sprintf("waf_ban_%d", idx)
+			lines = append(lines,
+				fmt.Sprintf("  @%s {", name),
+				"    host "+host,
+				"    client_ip "+strings.Join(ips, " "),
+				"  }",
+			)
+		} else {
+			lines = append(lines, fmt.Sprintf("  @%s client_ip %s", name, strings.Join(ips, " ")))
+		}
+		lines = append(lines, fmt.Sprintf("  respond @%s 403", name))
+	}
+	lines = append(lines, "}")
+	return strings.Join(lines, "\n")
+}
+
+// extractCaddySnippet 返回指定片段的原文，不存在或配置无法解析时返回空串
+func extractCaddySnippet(config, name string) string {
+	blocks, lines, err := parseTopLevelCaddyBlocks(config)
+	if err != nil {
+		return ""
+	}
+	for _, block := range blocks {
+		if block.Kind == "snippet" && block.Name == name {
+			return strings.TrimSpace(strings.ReplaceAll(joinBlockLines(lines, block), "\r\n", "\n"))
+		}
+	}
+	return ""
+}
+
+// applyWafBanSnippet 原地替换封禁片段并确保每个站点导入；snippet 为空时移除片段与导入，
+// 其余配置保持不变，因此无需重新发布 WAF 策略
+func applyWafBanSnippet(config, snippet string) (string, bool, error) {
+	blocks, lines, err := parseTopLevelCaddyBlocks(config)
+	if err != nil {
+		return "", false, err
+	}
+	newline := detectCaddyNewline(config)
+
+	stripped := make([]string, 0, len(lines))
+	skipBlank := false
+	for idx, line := range lines {
+		trimmed := strings.TrimSpace(strings.TrimRight(line, "\r\n"))
+		if lineInCaddySnippet(blocks, idx, managedWafBanSnippetName) {
+			// 片段后的空行一并移除，避免反复同步累积空行
+			skipBlank = true
+			continue
+		}
+		if skipBlank && trimmed == "" {
+			skipBlank = false
+			continue
+		}
+		skipBlank = false
+		if lineInCaddySite(blocks, idx) && trimmed == wafBanImportLine {
+			continue
+		}
+		stripped = append(stripped, line)
+	}
+	next := strings.Join(stripped, "")
+
+	snippet = strings.TrimSpace(snippet)
+	if snippet != "" {
+		blocks, lines, err = parseTopLevelCaddyBlocks(next)
+		if err != nil {
+			return "", false, err
+		}
+		siteIndexes := make([]int, 0)
+		for i, block := range blocks {
+			if block.Kind == "site" {
+				siteIndexes = append(siteIndexes, i)
+			}
+		}
+		if len(siteIndexes) == 0 {
+			return "", false, fmt.Errorf("Caddy 配置中没有站点，无法应用封禁名单")
+		}
+		// 先自后向前插入导入行，再在首个站点前插入片段，保证行号有效
+		for i := len(siteIndexes) - 1; i >= 0; i-- {
+			block := blocks[siteIndexes[i]]
+			indent := detectBlockChildIndent(lines, block, "  ")
+			lines = insertLines(lines, block.OpenLine+1, []string{indent + wafBanImportLine + newline})
+		}
+		snippetLines := splitLinesKeepEndings(strings.ReplaceAll(snippet, "\n", newline) + newline + newline)
+		lines = insertLines(lines, blocks[siteIndexes[0]].StartLine, snippetLines)
+		next = strings.Join(lines, "")
+	}
+
+	return next, next != config, nil
+}
+
+func lineInCaddySnippet(blocks []caddyTopLevelBlock, idx int, name string) bool {
+	for _, block := range blocks {
+		if block.Kind == "snippet" && block.Name == name && idx >= block.StartLine && idx <= block.EndLine {
+			return true
+		}
+	}
+	return false
+}
+
+func lineInCaddySite(blocks []caddyTopLevelBlock, idx int) bool {
+	for _, block := range blocks {
+		if block.Kind == "site" && idx > block.OpenLine && idx < block.EndLine {
+			return true
+		}
+	}
+	return false
+}
+
+type wafBanCandidate struct {
+	IP   string
+	Hits int64
+}
+
+// queryWafBanCandidates 统计窗口内命中状态码次数达到阈值的客户端 IP，优先使用 client_ip。
+// 封禁片段以 403 响应被封禁的请求，封禁期间（含已到期或撤销的封禁）的请求不计入，
+// 否则客户端持续重试会让封禁在到期后立即重新生效
+func queryWafBanCandidates(db *gorm.DB, rule *model.WafBanRule, now time.Time) ([]wafBanCandidate, error) {
+	_, codes, err := normalizeWafBanStatusCodes(rule.StatusCodes)
+	if err != nil {
+		return nil, err
+	}
+	ipExpr := "COALESCE(NULLIF(caddy_logs.client_ip, ''), caddy_logs.remote_ip)"
+	query := db.Model(&model.CaddyLog{}).
+		Select(ipExpr+" AS ip, COUNT(*) AS hits").
+		Where("caddy_logs.log_time >= ? AND caddy_logs.status IN ?", now.Add(-time.Duration(rule.WindowMinutes)*time.Minute), codes).
+		Where("NOT EXISTS (SELECT 1 FROM waf_bans WHERE waf_bans.ip = " + ipExpr +
+			" AND (waf_bans.host = '' OR waf_bans.host = caddy_logs.host)" +
+			" AND waf_bans.created_at <= caddy_logs.log_time" +
+			" AND caddy_logs.log_time < COALESCE(waf_bans.revoked_at, waf_bans.expires_at))")
+	if rule.Host != "" {
+		query = query.Where("caddy_logs.host = ?", rule.Host)
+	}
+
+	var candidates []wafBanCandidate
+	if err := query.
+		Group(ipExpr).
+		Having("COUNT(*) >= ?", rule.Threshold).
+		Order("hits desc").
+		Limit(wafBanMaxCandidatesPerRule).
+		Scan(&candidates).Error; err != nil {
+		return nil, fmt.Errorf("统计封禁候选 IP 失败: %w", err)
+	}
+	return candidates, nil
+}
+
+// loadWafBanExemptions 已启用允许名单中的 IP/CIDR 不参与自动封禁
+func loadWafBanExemptions(db *gorm.DB, now time.Time) ([]*net.IPNet, error) {
+	var values []string
+	if err := db.Model(&model.WafIPListEntry{}).
+		Joins("JOIN waf_ip_lists ON waf_ip_lists.id = waf_ip_list_entries.list_id").
+		Where("waf_ip_lists.enabled = ? AND waf_ip_lists.action = ?", true, wafIPListActionAllow).
+		Where("waf_ip_list_entries.kind = ?", wafIPListEntryKindIP).
+		Where("waf_ip_list_entries.expires_at IS NULL OR waf_ip_list_entries.expires_at > ?", now).
+		Pluck("waf_ip_list_entries.value", &values).Error; err != nil {
+		return nil, fmt.Errorf("查询允许名单失败: %w", err)
+	}
+	networks := make([]*net.IPNet, 0, len(values))
+	for _, value := range values {
+		if network := parseWafIPNetwork(value); network != nil {
+			networks = append(networks, network)
+		}
+	}
+	return networks, nil
+}
+
+func parseWafIPNetwork(value string) *net.IPNet {
+	if strings.Contains(value, "/") {
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil
+		}
+		return network
+	}
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return nil
+	}
+	bits := 128
+	if ip.To4() != nil {
+		ip = ip.To4()
+		bits = 32
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}
+}
+
+func isWafBanExempt(ip string, exemptions []*net.IPNet) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return true
+	}
+	for _, network := range exemptions {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+func toWafBanRuleItem(rule *model.WafBanRule) types.WafBanRuleItem {
+	item := types.WafBanRuleItem{
+		ID:            rule.ID,
+		Name:          rule.Name,
+		Description:   rule.Description,
+		Enabled:       rule.Enabled,
+		Host:          rule.Host,
+		StatusCodes:   rule.StatusCodes,
+		Threshold:     rule.Threshold,
+		WindowMinutes: rule.WindowMinutes,
+		BanMinutes:    rule.BanMinutes,
+		CreatedAt:     formatTime(rule.CreatedAt),
+		UpdatedAt:     formatTime(rule.UpdatedAt),
+	}
+	if rule.LastEvaluatedAt != nil {
+		item.LastEvaluatedAt = formatTime(*rule.LastEvaluatedAt)
+	}
+	return item
+}
+
+func toWafBanItem(ban *model.WafBan, ruleName string) types.WafBanItem {
+	item := types.WafBanItem{
+		ID:        ban.ID,
+		RuleId:    ban.RuleID,
+		RuleName:  ruleName,
+		IP:        ban.IP,
+		Host:      ban.Host,
+		HitCount:  ban.HitCount,
+		Reason:    ban.Reason,
+		Status:    ban.Status,
+		ExpiresAt: formatTime(ban.ExpiresAt),
+		Operator:  ban.Operator,
+		CreatedAt: formatTime(ban.CreatedAt),
+		UpdatedAt: formatTime(ban.UpdatedAt),
+	}
+	if ban.RevokedAt != nil {
+		item.RevokedAt = formatTime(*ban.RevokedAt)
+	}
+	return item
+}
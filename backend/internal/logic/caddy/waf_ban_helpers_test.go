@@ -0,0 +1,120 @@
+package caddy
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"logflux/model"
+)
+
+func TestNormalizeWafBanStatusCodes(t *testing.T) {
+	text, codes, err := normalizeWafBanStatusCodes(" 429, 401,403,401 ")
+	if err != nil {
+		t.Fatalf("normalizeWafBanStatusCodes() error = %v", err)
+	}
+	if text != "401,403,429" || len(codes) != 3 {
+		t.Fatalf("unexpected status codes: %q %v", text, codes)
+	}
+	if text, _, _ := normalizeWafBanStatusCodes(""); text != wafBanDefaultStatusCodes {
+		t.Fatalf("expected default status codes, got %q", text)
+	}
+	for _, invalid := range []string{"200", "abc", ",,"} {
+		if _, _, err := normalizeWafBanStatusCodes(invalid); err == nil {
+			t.Fatalf("expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestRenderWafBanSnippet(t *testing.T) {
+	snippet := renderWafBanSnippet([]model.WafBan{
+		{IP: "10.0.0.2"},
+		{IP: "10.0.0.1"},
+		{IP: "10.0.0.1"},
+		{IP: "192.168.1.0/24", Host: "admin.example.com"},
+	})
+	expected := strings.Join([]string{
+		"(waf_bans) {",
+		"  @waf_ban_all client_ip 10.0.0.1 10.0.0.2",
+		"  respond @waf_ban_all 403",
+		"  @waf_ban_1 {",
+		"    host admin.example.com",
+		"    client_ip 192.168.1.0/24",
+		"  }",
+		"  respond @waf_ban_1 403",
+		"}",
+	}, "\n")
+	if snippet != expected {
+		t.Fatalf("unexpected snippet:\n%s", snippet)
+	}
+	if renderWafBanSnippet(nil) != "" {
+		t.Fatalf("expected empty snippet without bans")
+	}
+}
+
+func TestApplyWafBanSnippet(t *testing.T) {
+	config := "{\n  admin :2019\n}\n\nexample.com {\n  reverse_proxy localhost:8080\n}\n\napi.example.com {\n  respond \"ok\"\n}\n"
+	snippet := renderWafBanSnippet([]model.WafBan{{IP: "10.0.0.1"}})
+
+	applied, changed, err := applyWafBanSnippet(config, snippet)
+	if err != nil || !changed {
+		t.Fatalf("applyWafBanSnippet() changed=%v err=%v", changed, err)
+	}
+	if !strings.Contains(applied, snippet+"\n\nexample.com {\n  import waf_bans\n") {
+		t.Fatalf("expected snippet before first site:\n%s", applied)
+	}
+	if strings.Count(applied, "import waf_bans") != 2 {
+		t.Fatalf("expected every site to import bans:\n%s", applied)
+	}
+
+	again, changed, err := applyWafBanSnippet(applied, snippet)
+	if err != nil || changed || again != applied {
+		t.Fatalf("expected re-applying the same snippet to be a no-op, changed=%v err=%v:\n%s", changed, err, again)
+	}
+
+	updated, _, err := applyWafBanSnippet(applied, renderWafBanSnippet([]model.WafBan{{IP: "10.0.0.9"}}))
+	if err != nil || strings.Contains(updated, "10.0.0.1") || strings.Count(updated, "(waf_bans)") != 1 {
+		t.Fatalf("expected snippet to be replaced in place, err=%v:\n%s", err, updated)
+	}
+
+	removed, changed, err := applyWafBanSnippet(applied, "")
+	if err != nil || !changed || removed != config {
+		t.Fatalf("expected removing bans to restore original config, err=%v:\n%s", err, removed)
+	}
+
+	if _, _, err := applyWafBanSnippet("{\n  admin :2019\n}\n", snippet); err == nil {
+		t.Fatalf("expected config without sites to be rejected")
+	}
+}
+
+func TestManagedCaddyfileKeepsWafBanSnippet(t *testing.T) {
+	base, err := buildPolicyCandidateCaddyConfig("", "SecRuleEngine On", true, managedPolicyExtras{})
+	if err != nil {
+		t.Fatalf("buildPolicyCandidateCaddyConfig() error = %v", err)
+	}
+	snippet := renderWafBanSnippet([]model.WafBan{{IP: "10.0.0.1"}})
+	withBans, _, err := applyWafBanSnippet(base, snippet)
+	if err != nil {
+		t.Fatalf("applyWafBanSnippet() error = %v", err)
+	}
+
+	republished, err := buildPolicyCandidateCaddyConfig(withBans, "SecRuleEngine DetectionOnly", true, managedPolicyExtras{})
+	if err != nil {
+		t.Fatalf("buildPolicyCandidateCaddyConfig() error = %v", err)
+	}
+	if !strings.Contains(republished, snippet) || strings.Count(republished, "import waf_bans") != 1 {
+		t.Fatalf("expected republish to keep ban snippet:\n%s", republished)
+	}
+	if _, changed, _ := applyWafBanSnippet(republished, snippet); changed {
+		t.Fatalf("expected rendered managed config to match ban sync output:\n%s", republished)
+	}
+}
+
+func TestIsWafBanExempt(t *testing.T) {
+	exemptions := []*net.IPNet{parseWafIPNetwork("192.168.0.0/16"), parseWafIPNetwork("10.0.0.1")}
+	for ip, want := range map[string]bool{"192.168.3.4": true, "10.0.0.1": true, "10.0.0.2": false, "not-an-ip": true} {
+		if got := isWafBanExempt(ip, exemptions); got != want {
+			t.Fatalf("isWafBanExempt(%q) = %v, want %v", ip, got, want)
+		}
+	}
+}
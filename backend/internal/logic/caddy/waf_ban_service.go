@@ -0,0 +1,266 @@
+package caddy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"logflux/internal/notification"
+	"logflux/internal/svc"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+// WafBanService 维护自动封禁：按规则统计访问日志生成临时封禁，到期自动解除，
+// 封禁名单以独立片段写入 Caddy 配置，不触发 WAF 策略重新发布
+type WafBanService struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	logger logx.Logger
+}
+
+func NewWafBanService(ctx context.Context, svcCtx *svc.ServiceContext) *WafBanService {
+	return &WafBanService{ctx: ctx, svcCtx: svcCtx, logger: logx.WithContext(ctx)}
+}
+
+// EvaluateActive 解除到期封禁并执行全部启用规则，封禁名单有变化时同步到 Caddy
+func (s *WafBanService) EvaluateActive() error {
+	if s == nil || s.svcCtx == nil || s.svcCtx.DB == nil {
+		return fmt.Errorf("数据库为空")
+	}
+	db := s.svcCtx.DB.WithContext(s.ctx)
+	now := time.Now()
+
+	expired, err := s.expire(db, now)
+	if err != nil {
+		return err
+	}
+
+	var rules []model.WafBanRule
+	if err := db.Where("enabled = ?", true).Order("id asc").Find(&rules).Error; err != nil {
+		return fmt.Errorf("查询封禁规则失败: %w", err)
+	}
+	exemptions, err := loadWafBanExemptions(db, now)
+	if err != nil {
+		return err
+	}
+
+	created := 0
+	for i := range rules {
+		count, err := s.evaluateRule(db, &rules[i], exemptions, now)
+		if err != nil {
+			s.logger.Errorf("执行封禁规则失败: ruleID=%d err=%v", rules[i].ID, err)
+			continue
+		}
+		created += count
+	}
+
+	if expired == 0 && created == 0 {
+		return nil
+	}
+	return s.Sync()
+}
+
+// Ban 手动封禁，同一 IP 与站点已有生效封禁时延长到期时间
+func (s *WafBanService) Ban(ip, host string, minutes int64, reason, operator string) (*model.WafBan, error) {
+	if s == nil || s.svcCtx == nil || s.svcCtx.DB == nil {
+		return nil, fmt.Errorf("数据库为空")
+	}
+	_, normalizedIP, err := normalizeWafIPListEntryValue(wafIPListEntryKindIP, ip)
+	if err != nil {
+		return nil, err
+	}
+	normalizedHost, err := normalizeWafBanHost(host)
+	if err != nil {
+		return nil, err
+	}
+	if minutes == 0 {
+		minutes = wafBanDefaultBanMinutes
+	}
+	if err := validateWafBanMinutes(minutes); err != nil {
+		return nil, err
+	}
+	exemptions, err := loadWafBanExemptions(s.svcCtx.DB.WithContext(s.ctx), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if isWafBanExempt(strings.SplitN(normalizedIP, "/", 2)[0], exemptions) {
+		return nil, fmt.Errorf("该 IP 位于允许名单中，不能封禁")
+	}
+
+	ban, _, err := s.upsertBan(s.svcCtx.DB.WithContext(s.ctx), &model.WafBan{
+		IP:        normalizedIP,
+		Host:      normalizedHost,
+		Reason:    reason,
+		ExpiresAt: time.Now().Add(time.Duration(minutes) * time.Minute),
+		Operator:  operator,
+	}, true)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Sync(); err != nil {
+		return ban, fmt.Errorf("封禁已记录，但同步到 Caddy 失败: %w", err)
+	}
+	return ban, nil
+}
+
+// Revoke 提前解除封禁，记录保留为审计轨迹
+func (s *WafBanService) Revoke(id uint, operator string) error {
+	if s == nil || s.svcCtx == nil || s.svcCtx.DB == nil {
+		return fmt.Errorf("数据库为空")
+	}
+	db := s.svcCtx.DB.WithContext(s.ctx)
+
+	var ban model.WafBan
+	if err := db.First(&ban, id).Error; err != nil {
+		return fmt.Errorf("封禁记录不存在")
+	}
+	if ban.Status != model.WafBanStatusActive {
+		return fmt.Errorf("封禁已失效，无需解除")
+	}
+	now := time.Now()
+	if err := db.Model(&model.WafBan{}).Where("id = ?", ban.ID).Updates(map[string]interface{}{
+		"status":     model.WafBanStatusRevoked,
+		"revoked_at": now,
+		"operator":   operator,
+	}).Error; err != nil {
+		return fmt.Errorf("解除封禁失败: %w", err)
+	}
+	if err := s.Sync(); err != nil {
+		return fmt.Errorf("封禁已解除，但同步到 Caddy 失败: %w", err)
+	}
+	return nil
+}
+
+// Sync 将生效中的封禁渲染为片段写入主 Caddy 服务器，配置未变化时不重新加载
+func (s *WafBanService) Sync() error {
+	if s == nil || s.svcCtx == nil || s.svcCtx.DB == nil {
+		return fmt.Errorf("数据库为空")
+	}
+	db := s.svcCtx.DB.WithContext(s.ctx)
+
+	var bans []model.WafBan
+	if err := db.Where("status = ? AND expires_at > ?", model.WafBanStatusActive, time.Now()).Order("id asc").Find(&bans).Error; err != nil {
+		return fmt.Errorf("查询生效封禁失败: %w", err)
+	}
+
+	server, err := findPrimaryCaddyServer(db)
+	if err != nil {
+		return err
+	}
+	// 封禁名单由规则自动维护，同步不走变更审批
+	applyService := newCaddyConfigApplyService(s.svcCtx, s.logger).withNote(currentOperatorFromContext(s.ctx), "同步 WAF 封禁名单").approvedChange()
+	currentConfig, modules, err := applyService.loadCurrent(server)
+	if err != nil {
+		return err
+	}
+	nextConfig, changed, err := applyWafBanSnippet(currentConfig, renderWafBanSnippet(bans))
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return nil
+	}
+	return applyService.apply(server, nextConfig, modules, "waf_ban_sync")
+}
+
+func (s *WafBanService) expire(db *gorm.DB, now time.Time) (int64, error) {
+	result := db.Model(&model.WafBan{}).
+		Where("status = ? AND expires_at <= ?", model.WafBanStatusActive, now).
+		Update("status", model.WafBanStatusExpired)
+	if result.Error != nil {
+		return 0, fmt.Errorf("解除到期封禁失败: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+func (s *WafBanService) evaluateRule(db *gorm.DB, rule *model.WafBanRule, exemptions []*net.IPNet, now time.Time) (int, error) {
+	candidates, err := queryWafBanCandidates(db, rule, now)
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for _, candidate := range candidates {
+		_, ip, err := normalizeWafIPListEntryValue(wafIPListEntryKindIP, candidate.IP)
+		if err != nil || isWafBanExempt(ip, exemptions) {
+			continue
+		}
+		ban, isNew, err := s.upsertBan(db, &model.WafBan{
+			RuleID:    rule.ID,
+			IP:        ip,
+			Host:      rule.Host,
+			HitCount:  candidate.Hits,
+			Reason:    fmt.Sprintf("规则「%s」：%d 分钟内 %d 次请求命中状态码 %s", rule.Name, rule.WindowMinutes, candidate.Hits, rule.StatusCodes),
+			ExpiresAt: now.Add(time.Duration(rule.BanMinutes) * time.Minute),
+			Operator:  wafBanOperatorSystem,
+		}, false)
+		if err != nil {
+			return created, err
+		}
+		if isNew {
+			created++
+			s.notifyBan(ban, rule)
+		}
+	}
+
+	if err := db.Model(&model.WafBanRule{}).Where("id = ?", rule.ID).Update("last_evaluated_at", now).Error; err != nil {
+		return created, fmt.Errorf("更新封禁规则评估时间失败: %w", err)
+	}
+	return created, nil
+}
+
+// upsertBan 同一 IP 与站点仅保留一条生效封禁；已存在时仅在 extend 为 true（手动封禁）时延长到期时间，
+// 规则评估不延长生效中的封禁
+func (s *WafBanService) upsertBan(db *gorm.DB, ban *model.WafBan, extend bool) (*model.WafBan, bool, error) {
+	var existing model.WafBan
+	err := db.Where("status = ? AND ip = ? AND host = ?", model.WafBanStatusActive, ban.IP, ban.Host).First(&existing).Error
+	switch {
+	case err == nil:
+		if !extend || !ban.ExpiresAt.After(existing.ExpiresAt) {
+			return &existing, false, nil
+		}
+		if err := db.Model(&model.WafBan{}).Where("id = ?", existing.ID).Update("expires_at", ban.ExpiresAt).Error; err != nil {
+			return nil, false, fmt.Errorf("更新封禁失败: %w", err)
+		}
+		existing.ExpiresAt = ban.ExpiresAt
+		return &existing, false, nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		ban.Status = model.WafBanStatusActive
+		if err := db.Create(ban).Error; err != nil {
+			return nil, false, fmt.Errorf("创建封禁失败: %w", err)
+		}
+		return ban, true, nil
+	default:
+		return nil, false, fmt.Errorf("查询封禁失败: %w", err)
+	}
+}
+
+func (s *WafBanService) notifyBan(ban *model.WafBan, rule *model.WafBanRule) {
+	host := ban.Host
+	if host == "" {
+		host = "全部站点"
+	}
+	notifyWafPolicyEventAsync(
+		s.svcCtx,
+		s.logger,
+		notification.EventSecurityBruteForce,
+		notification.LevelWarning,
+		"自动封禁 IP",
+		fmt.Sprintf("%s 在 %s 触发规则「%s」，已封禁至 %s", ban.IP, host, rule.Name, formatTime(ban.ExpiresAt)),
+		map[string]interface{}{
+			"banId":     ban.ID,
+			"ruleId":    rule.ID,
+			"ruleName":  rule.Name,
+			"ip":        ban.IP,
+			"host":      ban.Host,
+			"hitCount":  ban.HitCount,
+			"expiresAt": formatTime(ban.ExpiresAt),
+		},
+	)
+}
@@ -0,0 +1,60 @@
+package caddy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"logflux/internal/svc"
+	"logflux/model"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestQueryWafBanCandidatesSkipsRequestsDuringBans(t *testing.T) {
+	db, mock, cleanup := newWafMirrorMockDB(t)
+	defer cleanup()
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`FROM "caddy_logs" WHERE \(caddy_logs.log_time >= \$1 AND caddy_logs.status IN \(\$2,\$3\)\) `+
+		`AND \(NOT EXISTS \(SELECT 1 FROM waf_bans WHERE waf_bans.ip = COALESCE\(NULLIF\(caddy_logs.client_ip, ''\), caddy_logs.remote_ip\) `+
+		`AND \(waf_bans.host = '' OR waf_bans.host = caddy_logs.host\) AND waf_bans.created_at <= caddy_logs.log_time `+
+		`AND caddy_logs.log_time < COALESCE\(waf_bans.revoked_at, waf_bans.expires_at\)\)\) AND caddy_logs.host = \$4 GROUP BY`).
+		WithArgs(now.Add(-5*time.Minute), 401, 403, "app.example.com", int64(20), wafBanMaxCandidatesPerRule).
+		WillReturnRows(sqlmock.NewRows([]string{"ip", "hits"}).AddRow("203.0.113.7", 25))
+
+	candidates, err := queryWafBanCandidates(db, &model.WafBanRule{
+		Host: "app.example.com", StatusCodes: "401,403", Threshold: 20, WindowMinutes: 5,
+	}, now)
+	if err != nil {
+		t.Fatalf("query candidates failed: %v", err)
+	}
+	if len(candidates) != 1 || candidates[0].IP != "203.0.113.7" || candidates[0].Hits != 25 {
+		t.Fatalf("unexpected candidates: %+v", candidates)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet sql expectations: %v", err)
+	}
+}
+
+func TestUpsertBanKeepsActiveBanExpiryOnEvaluation(t *testing.T) {
+	db, mock, cleanup := newWafMirrorMockDB(t)
+	defer cleanup()
+	service := NewWafBanService(context.Background(), &svc.ServiceContext{DB: db})
+	expiresAt := time.Date(2026, 3, 1, 11, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`SELECT \* FROM "waf_bans" WHERE status = \$1 AND ip = \$2 AND host = \$3`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "ip", "host", "status", "expires_at"}).
+			AddRow(3, "203.0.113.7", "", model.WafBanStatusActive, expiresAt))
+
+	ban, isNew, err := service.upsertBan(db, &model.WafBan{IP: "203.0.113.7", ExpiresAt: expiresAt.Add(time.Hour)}, false)
+	if err != nil {
+		t.Fatalf("upsert failed: %v", err)
+	}
+	if isNew || !ban.ExpiresAt.Equal(expiresAt) {
+		t.Fatalf("expected existing ban to keep its expiry, got new=%v expires=%s", isNew, ban.ExpiresAt)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet sql expectations: %v", err)
+	}
+}
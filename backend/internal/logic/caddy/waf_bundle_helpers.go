@@ -0,0 +1,333 @@
+package caddy
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"logflux/internal/types"
+	"logflux/internal/waf"
+	"logflux/internal/xerr"
+	"logflux/model"
+
+	"gorm.io/gorm"
+)
+
+const wafMirrorPathPrefix = "/api/waf/mirror/"
+
+// wafBundleMetaKeys 导出到离线包清单中的版本元数据，规则差异等与本实例相关的字段不随包流转
+var wafBundleMetaKeys = []string{"signatureType", "signatureKeyId", "signer", "signatureVerifiedAt", "originFileName"}
+
+// wafReleaseArchive 版本目录重新打包后的缓存归档，按版本 id 命名，避免同名版本重建后复用旧归档
+type wafReleaseArchive struct {
+	Path      string
+	SHA256    string
+	SizeBytes int64
+}
+
+func (helper *wafLogicHelper) bundleSigner() (*waf.BundleSigner, error) {
+	if err := helper.ensureStoreDirs(); err != nil {
+		return nil, err
+	}
+	return waf.LoadOrCreateBundleSigner(helper.store.SigningKeyPath())
+}
+
+func releaseArchiveName(release *model.WafRelease) string {
+	return fmt.Sprintf("release_%d.tar.gz", release.ID)
+}
+
+// releaseArchive 返回版本的归档，首次访问时由已解压目录打包生成
+func (helper *wafLogicHelper) releaseArchive(release *model.WafRelease) (*wafReleaseArchive, error) {
+	if err := helper.ensureStoreDirs(); err != nil {
+		return nil, err
+	}
+	releaseDir, err := helper.releaseRuleDir(release)
+	if err != nil {
+		return nil, err
+	}
+
+	archivePath := helper.store.BundlePath(releaseArchiveName(release))
+	if _, statErr := os.Stat(archivePath); statErr == nil {
+		verifyResult, err := waf.VerifyPackage(archivePath, waf.VerifyOptions{
+			AllowedExt:      []string{".tar.gz"},
+			MaxPackageBytes: helper.svcCtx.Config.Waf.MaxPackageBytes,
+		})
+		if err == nil {
+			return &wafReleaseArchive{Path: archivePath, SHA256: verifyResult.SHA256, SizeBytes: verifyResult.SizeBytes}, nil
+		}
+		helper.logger.Errorf("版本归档缓存无效，重新打包: release=%d err=%v", release.ID, err)
+		_ = os.Remove(archivePath + ".minisig")
+	}
+
+	sha, sizeBytes, err := waf.PackDirectory(releaseDir, archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("打包版本 %s 失败: %w", release.Version, err)
+	}
+	_ = os.Remove(archivePath + ".minisig")
+	return &wafReleaseArchive{Path: archivePath, SHA256: sha, SizeBytes: sizeBytes}, nil
+}
+
+// releaseArchiveSignature 返回归档的 minisign 签名，下游可用本实例公钥校验
+func (helper *wafLogicHelper) releaseArchiveSignature(release *model.WafRelease, archive *wafReleaseArchive) (string, error) {
+	signaturePath := archive.Path + ".minisig"
+	if _, err := os.Stat(signaturePath); err == nil {
+		return signaturePath, nil
+	}
+	signer, err := helper.bundleSigner()
+	if err != nil {
+		return "", err
+	}
+	signature, err := signer.SignFile(archive.Path, fmt.Sprintf("timestamp:%d\tkind:%s\tversion:%s", time.Now().Unix(), release.Kind, release.Version))
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(signaturePath, signature, 0o644); err != nil {
+		return "", fmt.Errorf("保存归档签名失败: %w", err)
+	}
+	return signaturePath, nil
+}
+
+// exportReleaseBundle 生成版本的签名离线包，每次导出都重新签名
+func (helper *wafLogicHelper) exportReleaseBundle(release *model.WafRelease) (string, error) {
+	if normalizeWafKind(release.Kind) == wafKindCorazaEngine {
+		return "", fmt.Errorf("Coraza 引擎版本不支持导出")
+	}
+	archive, err := helper.releaseArchive(release)
+	if err != nil {
+		return "", err
+	}
+	signer, err := helper.bundleSigner()
+	if err != nil {
+		return "", err
+	}
+
+	exportedBy, _ := os.Hostname()
+	manifest := waf.BundleManifest{
+		Kind:             normalizeWafKind(release.Kind),
+		Version:          release.Version,
+		PackageSHA256:    archive.SHA256,
+		PackageSizeBytes: archive.SizeBytes,
+		OriginChecksum:   release.Checksum,
+		ExportedAt:       formatTime(time.Now()),
+		ExportedBy:       exportedBy,
+		Meta:             wafBundleExportMeta(release.Meta),
+	}
+	bundlePath := helper.store.BundlePath(fmt.Sprintf("release_%d.bundle.tar.gz", release.ID))
+	if err := waf.WriteBundle(bundlePath, manifest, archive.Path, signer); err != nil {
+		return "", err
+	}
+	return bundlePath, nil
+}
+
+func wafBundleExportMeta(meta model.JSONMap) map[string]interface{} {
+	result := make(map[string]interface{})
+	for _, key := range wafBundleMetaKeys {
+		if value, ok := meta[key]; ok {
+			result[key] = value
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+func wafBundleDownloadName(release *model.WafRelease) string {
+	return fmt.Sprintf("logflux-waf-%s-%s.bundle.tar.gz", normalizeWafKind(release.Kind), sanitizeToken(release.Version))
+}
+
+// removeReleaseArchives 清理版本删除后遗留的归档、签名与离线包缓存
+func (helper *wafLogicHelper) removeReleaseArchives(releaseIDs []uint) {
+	for _, releaseID := range releaseIDs {
+		archivePath := helper.store.BundlePath(releaseArchiveName(&model.WafRelease{ID: releaseID}))
+		for _, pathValue := range []string{archivePath, archivePath + ".minisig", helper.store.BundlePath(fmt.Sprintf("release_%d.bundle.tar.gz", releaseID))} {
+			if err := os.Remove(pathValue); err != nil && !errors.Is(err, os.ErrNotExist) {
+				helper.logger.Errorf("删除版本归档缓存失败: path=%s err=%v", pathValue, err)
+			}
+		}
+	}
+}
+
+// wafBundleTrustedKeys 合并配置、管理员在已启用 minisign 更新源上保存的公钥与本实例自身的公钥，
+// 本实例导出的包始终可以回导；导入请求本身不能扩充受信公钥
+func (helper *wafLogicHelper) wafBundleTrustedKeys() (string, error) {
+	keys := make([]string, 0, 3)
+	if configured := strings.TrimSpace(helper.svcCtx.Config.Waf.BundleTrustedKeys); configured != "" {
+		keys = append(keys, configured)
+	}
+	var sourceKeys []string
+	if err := helper.svcCtx.DB.WithContext(helper.ctx).Model(&model.WafSource{}).
+		Where("enabled = ? AND signature_type = ? AND COALESCE(public_keys, '') <> ''", true, waf.SignatureTypeMinisign).
+		Order("id asc").
+		Pluck("public_keys", &sourceKeys).Error; err != nil {
+		return "", fmt.Errorf("查询受信公钥失败: %w", err)
+	}
+	for _, sourceKey := range sourceKeys {
+		if trimmed := strings.TrimSpace(sourceKey); trimmed != "" {
+			keys = append(keys, trimmed)
+		}
+	}
+	signer, err := helper.bundleSigner()
+	if err != nil {
+		return "", err
+	}
+	keys = append(keys, signer.PublicKey())
+	return strings.Join(keys, "\n"), nil
+}
+
+func wafMirrorManifestURL(kind string) string {
+	return wafMirrorPathPrefix + normalizeWafKind(kind) + "/manifest"
+}
+
+func wafMirrorFileURL(kind, version, fileName string) string {
+	return fmt.Sprintf("%s%s/%s/%s", wafMirrorPathPrefix, normalizeWafKind(kind), version, fileName)
+}
+
+// authenticateWafMirrorClient 校验 Authorization: Bearer <token>，凭据需启用且与请求的规则类型一致
+func authenticateWafMirrorClient(db *gorm.DB, authorization, kind string) (*model.WafMirrorClient, error) {
+	token := strings.TrimSpace(authorization)
+	if len(token) > len("Bearer ") && strings.EqualFold(token[:len("Bearer ")], "Bearer ") {
+		token = strings.TrimSpace(token[len("Bearer "):])
+	} else {
+		token = ""
+	}
+	if token == "" {
+		return nil, xerr.NewCodeError(xerr.Unauthorized, "缺少镜像凭据")
+	}
+
+	var client model.WafMirrorClient
+	if err := db.Where("token = ? AND enabled = ?", token, true).First(&client).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, xerr.NewCodeError(xerr.Unauthorized, "镜像凭据无效或已禁用")
+		}
+		return nil, err
+	}
+	if normalizeWafKind(client.Kind) != normalizeWafKind(kind) {
+		return nil, xerr.NewCodeError(xerr.Forbidden, "镜像凭据不允许访问该规则类型")
+	}
+	return &client, nil
+}
+
+// resolveWafMirrorRelease 固定版本优先；未固定时按请求版本，否则使用当前激活版本
+func resolveWafMirrorRelease(db *gorm.DB, client *model.WafMirrorClient, requestedVersion string) (*model.WafRelease, error) {
+	version := strings.TrimSpace(requestedVersion)
+	pinned := strings.TrimSpace(client.PinnedVersion)
+	if pinned != "" {
+		if version != "" && version != pinned {
+			return nil, xerr.NewCodeError(xerr.Forbidden, fmt.Sprintf("镜像凭据已固定到版本 %s", pinned))
+		}
+		version = pinned
+	}
+
+	if version == "" {
+		release, err := findActiveWafRelease(db, client.Kind)
+		if err != nil {
+			return nil, err
+		}
+		if release == nil {
+			return nil, xerr.NewCodeError(xerr.NotFound, "镜像暂无激活版本")
+		}
+		return release, nil
+	}
+
+	var release model.WafRelease
+	err := db.Where("kind = ? AND version = ? AND status IN ?", normalizeWafKind(client.Kind), version,
+		[]string{wafReleaseStatusVerified, wafReleaseStatusActive, wafReleaseStatusRolledBack}).
+		Order("id desc").
+		First(&release).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, xerr.NewCodeError(xerr.NotFound, fmt.Sprintf("镜像中不存在版本 %s", version))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询镜像版本失败: %w", err)
+	}
+	return &release, nil
+}
+
+// resolveWafMirrorRequest 完成镜像请求的凭据校验与版本解析
+func (helper *wafLogicHelper) resolveWafMirrorRequest(authorization, kind, version string) (*model.WafMirrorClient, *model.WafRelease, error) {
+	db := helper.svcCtx.DB.WithContext(helper.ctx)
+	client, err := authenticateWafMirrorClient(db, authorization, kind)
+	if err != nil {
+		return nil, nil, err
+	}
+	release, err := resolveWafMirrorRelease(db, client, version)
+	if err != nil {
+		return nil, nil, err
+	}
+	return client, release, nil
+}
+
+func (helper *wafLogicHelper) touchWafMirrorClient(client *model.WafMirrorClient, servedFile string) {
+	clientIP, _ := helper.ctx.Value(wafMirrorClientIPCtxKey).(string)
+	now := time.Now()
+	if err := helper.svcCtx.DB.WithContext(helper.ctx).Model(&model.WafMirrorClient{}).
+		Where("id = ?", client.ID).
+		Updates(map[string]interface{}{
+			"last_used_at":     &now,
+			"last_client_ip":   strings.TrimSpace(clientIP),
+			"last_served_file": servedFile,
+		}).Error; err != nil {
+		helper.logger.Errorf("更新镜像凭据使用记录失败: %v", err)
+	}
+}
+
+// generateWafMirrorToken 生成 48 位十六进制随机凭据
+func generateWafMirrorToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成镜像凭据失败: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// normalizeWafMirrorClient 校验凭据名称与规则类型，镜像仅分发 CRS 规则
+func normalizeWafMirrorClient(db *gorm.DB, excludeID uint, name, kind, pinnedVersion string) (string, string, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", "", "", fmt.Errorf("镜像凭据名称不能为空")
+	}
+	kind = normalizeWafKind(kind)
+	if kind != wafKindCRS {
+		return "", "", "", fmt.Errorf("镜像仅支持 CRS 规则")
+	}
+	pinnedVersion = strings.TrimSpace(pinnedVersion)
+	if pinnedVersion != "" {
+		pinnedVersion = sanitizeToken(pinnedVersion)
+	}
+
+	var count int64
+	query := db.Model(&model.WafMirrorClient{}).Where("name = ?", name)
+	if excludeID > 0 {
+		query = query.Where("id <> ?", excludeID)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return "", "", "", fmt.Errorf("检查镜像凭据名称失败: %w", err)
+	}
+	if count > 0 {
+		return "", "", "", fmt.Errorf("镜像凭据名称已存在: %s", name)
+	}
+	return name, kind, pinnedVersion, nil
+}
+
+func toWafMirrorClientItem(client *model.WafMirrorClient) types.WafMirrorClientItem {
+	return types.WafMirrorClientItem{
+		ID:             client.ID,
+		Name:           client.Name,
+		Token:          client.Token,
+		Kind:           client.Kind,
+		PinnedVersion:  client.PinnedVersion,
+		Enabled:        client.Enabled,
+		Description:    client.Description,
+		ManifestUrl:    wafMirrorManifestURL(client.Kind),
+		LastUsedAt:     formatNullableTime(client.LastUsedAt),
+		LastClientIp:   client.LastClientIP,
+		LastServedFile: client.LastServedFile,
+		CreatedAt:      formatTime(client.CreatedAt),
+		UpdatedAt:      formatTime(client.UpdatedAt),
+	}
+}
@@ -0,0 +1,104 @@
+package caddy
+
+import (
+	"regexp"
+	"testing"
+
+	"logflux/internal/xerr"
+	"logflux/model"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestAuthenticateWafMirrorClient(t *testing.T) {
+	db, mock, cleanup := newWafMirrorMockDB(t)
+	defer cleanup()
+
+	if _, err := authenticateWafMirrorClient(db, "", "crs"); xerr.CodeFromError(err) != xerr.Unauthorized {
+		t.Fatalf("expected unauthorized without token, got %v", err)
+	}
+	if _, err := authenticateWafMirrorClient(db, "Basic abc", "crs"); xerr.CodeFromError(err) != xerr.Unauthorized {
+		t.Fatalf("expected unauthorized for non-bearer auth, got %v", err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "waf_mirror_clients" WHERE token = $1 AND enabled = $2`)).
+		WithArgs("secret", true, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "token", "kind", "enabled"}).AddRow(1, "edge", "secret", "crs", true))
+	client, err := authenticateWafMirrorClient(db, "bearer secret", "CRS")
+	if err != nil || client.Name != "edge" {
+		t.Fatalf("expected client, got %+v err=%v", client, err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "waf_mirror_clients" WHERE token = $1 AND enabled = $2`)).
+		WithArgs("secret", true, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "token", "kind", "enabled"}).AddRow(1, "edge", "secret", "crs", true))
+	if _, err := authenticateWafMirrorClient(db, "Bearer secret", "coraza_engine"); xerr.CodeFromError(err) != xerr.Forbidden {
+		t.Fatalf("expected forbidden for other kind, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("sql expectations not met: %v", err)
+	}
+}
+
+func TestResolveWafMirrorReleaseHonorsPinnedVersion(t *testing.T) {
+	db, mock, cleanup := newWafMirrorMockDB(t)
+	defer cleanup()
+
+	pinned := &model.WafMirrorClient{Kind: "crs", PinnedVersion: "v4.5.0"}
+	if _, err := resolveWafMirrorRelease(db, pinned, "v4.7.0"); xerr.CodeFromError(err) != xerr.Forbidden {
+		t.Fatalf("expected forbidden for version outside pin, got %v", err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "waf_releases" WHERE kind = $1 AND version = $2 AND status IN ($3,$4,$5)`)).
+		WithArgs("crs", "v4.5.0", wafReleaseStatusVerified, wafReleaseStatusActive, wafReleaseStatusRolledBack, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "kind", "version", "status"}).AddRow(7, "crs", "v4.5.0", wafReleaseStatusVerified))
+	release, err := resolveWafMirrorRelease(db, pinned, "")
+	if err != nil || release.ID != 7 {
+		t.Fatalf("expected pinned release, got %+v err=%v", release, err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "waf_releases" WHERE kind = $1 AND status = $2`)).
+		WithArgs("crs", wafReleaseStatusActive, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	if _, err := resolveWafMirrorRelease(db, &model.WafMirrorClient{Kind: "crs"}, ""); xerr.CodeFromError(err) != xerr.NotFound {
+		t.Fatalf("expected not found without active release, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("sql expectations not met: %v", err)
+	}
+}
+
+func TestIsWafRemoteMode(t *testing.T) {
+	for mode, expected := range map[string]bool{"": true, "remote": true, "Mirror": true, "manual": false} {
+		if got := isWafRemoteMode(mode); got != expected {
+			t.Fatalf("isWafRemoteMode(%q) = %v, want %v", mode, got, expected)
+		}
+	}
+	if err := validateWafMode("mirror"); err != nil {
+		t.Fatalf("expected mirror mode to be valid: %v", err)
+	}
+}
+
+func newWafMirrorMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock, func()) {
+	t.Helper()
+
+	sqldb, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+
+	gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: sqldb}), &gorm.Config{SkipDefaultTransaction: true})
+	if err != nil {
+		_ = sqldb.Close()
+		t.Fatalf("failed to open gorm: %v", err)
+	}
+
+	cleanup := func() {
+		_ = sqldb.Close()
+	}
+	return gdb, mock, cleanup
+}
@@ -0,0 +1,208 @@
+package caddy
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"logflux/internal/waf"
+	"logflux/model"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// 自定义规则 ID 区间，避开 CRS（900000-999999）与系统内置 SecAction
+	wafCustomRuleIDMin int64 = 10000
+	wafCustomRuleIDMax int64 = 99999
+
+	wafCustomRuleDefaultPhase    int64 = 2
+	wafCustomRuleDefaultPriority int64 = 100
+	wafCustomRuleMaxDirectiveLen       = 8192
+)
+
+var (
+	regexCustomRuleID    = regexp.MustCompile(`(?i)(?:^|["',\s])id\s*:\s*'?([0-9]+)`)
+	regexCustomRulePhase = regexp.MustCompile(`(?i)(?:^|["',\s])phase\s*:\s*'?([a-z0-9]+)`)
+)
+
+var wafCustomRulePhaseAliases = map[string]int64{
+	"request":  2,
+	"response": 4,
+	"logging":  5,
+}
+
+// normalizeAndValidateCustomRuleDirective 校验单条自定义规则并解析出 rule id 与 phase；
+// expectedPhase 为 0 时以指令中的 phase 为准
+func normalizeAndValidateCustomRuleDirective(directive string, expectedPhase int64) (string, int64, int64, error) {
+	normalized := strings.TrimSpace(strings.ReplaceAll(directive, "\r\n", "\n"))
+	if normalized == "" {
+		return "", 0, 0, fmt.Errorf("自定义规则内容不能为空")
+	}
+	if len(normalized) > wafCustomRuleMaxDirectiveLen {
+		return "", 0, 0, fmt.Errorf("自定义规则内容过长（最多 %d 字符）", wafCustomRuleMaxDirectiveLen)
+	}
+	// 指令最终嵌入 Caddyfile 的反引号字符串中
+	if strings.Contains(normalized, "`") {
+		return "", 0, 0, fmt.Errorf("自定义规则不能包含反引号")
+	}
+
+	for _, statement := range splitSecLangStatements(normalized) {
+		keyword := strings.ToLower(strings.Fields(statement)[0])
+		if keyword != "secrule" && keyword != "secaction" {
+			return "", 0, 0, fmt.Errorf("自定义规则仅支持 SecRule/SecAction 指令: %s", strings.Fields(statement)[0])
+		}
+	}
+
+	idMatches := regexCustomRuleID.FindAllStringSubmatch(normalized, -1)
+	if len(idMatches) == 0 {
+		return "", 0, 0, fmt.Errorf("自定义规则必须声明 id 动作")
+	}
+	if len(idMatches) > 1 {
+		return "", 0, 0, fmt.Errorf("每条自定义规则只能声明一个 id（链式规则仅首条声明 id）")
+	}
+	ruleID, err := strconv.ParseInt(idMatches[0][1], 10, 64)
+	if err != nil || ruleID < wafCustomRuleIDMin || ruleID > wafCustomRuleIDMax {
+		return "", 0, 0, fmt.Errorf("自定义规则 id 必须在 %d-%d 之间", wafCustomRuleIDMin, wafCustomRuleIDMax)
+	}
+
+	phase := wafCustomRuleDefaultPhase
+	if phaseMatch := regexCustomRulePhase.FindStringSubmatch(normalized); len(phaseMatch) == 2 {
+		parsedPhase, ok := parseCustomRulePhase(phaseMatch[1])
+		if !ok {
+			return "", 0, 0, fmt.Errorf("自定义规则 phase 无效: %s", phaseMatch[1])
+		}
+		phase = parsedPhase
+	}
+	if expectedPhase != 0 && expectedPhase != phase {
+		return "", 0, 0, fmt.Errorf("自定义规则 phase 与指令不一致: 期望 %d，指令为 %d", expectedPhase, phase)
+	}
+
+	if err := waf.ValidateSecLang(normalized); err != nil {
+		return "", 0, 0, err
+	}
+	return normalized, ruleID, phase, nil
+}
+
+func parseCustomRulePhase(raw string) (int64, bool) {
+	value := strings.ToLower(strings.TrimSpace(raw))
+	if alias, ok := wafCustomRulePhaseAliases[value]; ok {
+		return alias, true
+	}
+	phase, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || phase < 1 || phase > 5 {
+		return 0, false
+	}
+	return phase, true
+}
+
+// splitSecLangStatements 合并反斜杠续行并去掉注释，返回逻辑指令列表
+func splitSecLangStatements(directives string) []string {
+	statements := make([]string, 0)
+	var current strings.Builder
+	for _, line := range strings.Split(directives, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if current.Len() == 0 && (trimmed == "" || strings.HasPrefix(trimmed, "#")) {
+			continue
+		}
+		if strings.HasSuffix(trimmed, "\\") {
+			current.WriteString(strings.TrimSuffix(trimmed, "\\"))
+			current.WriteString(" ")
+			continue
+		}
+		current.WriteString(trimmed)
+		if statement := strings.TrimSpace(current.String()); statement != "" {
+			statements = append(statements, statement)
+		}
+		current.Reset()
+	}
+	if statement := strings.TrimSpace(current.String()); statement != "" {
+		statements = append(statements, statement)
+	}
+	return statements
+}
+
+func normalizeCustomRulePriority(priority int64) int64 {
+	if priority <= 0 {
+		return wafCustomRuleDefaultPriority
+	}
+	return priority
+}
+
+func validateCustomRuleIDUnique(db *gorm.DB, policyID uint, ruleID int64, excludeID uint) error {
+	var count int64
+	query := db.Model(&model.WafCustomRule{}).Where("policy_id = ? AND rule_id = ?", policyID, ruleID)
+	if excludeID > 0 {
+		query = query.Where("id <> ?", excludeID)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return fmt.Errorf("校验自定义规则 id 失败: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("策略内已存在 id 为 %d 的自定义规则", ruleID)
+	}
+	return nil
+}
+
+func loadEnabledWafCustomRules(db *gorm.DB, policyID uint) ([]model.WafCustomRule, error) {
+	var rules []model.WafCustomRule
+	if err := db.Where("policy_id = ? AND enabled = ?", policyID, true).Order("priority asc, rule_id asc").Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("查询策略自定义规则失败: %w", err)
+	}
+	return rules, nil
+}
+
+// buildWafCustomRuleDirectives 按 priority、rule id 排序渲染已启用的自定义规则
+func buildWafCustomRuleDirectives(rules []model.WafCustomRule) string {
+	enabled := make([]model.WafCustomRule, 0, len(rules))
+	for _, rule := range rules {
+		if rule.Enabled && strings.TrimSpace(rule.Directive) != "" {
+			enabled = append(enabled, rule)
+		}
+	}
+	sort.SliceStable(enabled, func(i, j int) bool {
+		if enabled[i].Priority != enabled[j].Priority {
+			return enabled[i].Priority < enabled[j].Priority
+		}
+		return enabled[i].RuleID < enabled[j].RuleID
+	})
+
+	blocks := make([]string, 0, len(enabled))
+	for _, rule := range enabled {
+		blocks = append(blocks, strings.TrimSpace(rule.Directive))
+	}
+	return strings.Join(blocks, "\n")
+}
+
+type wafCustomRuleSnapshot struct {
+	RuleID    int64  `json:"ruleId"`
+	Name      string `json:"name"`
+	Phase     int64  `json:"phase"`
+	Priority  int64  `json:"priority"`
+	Directive string `json:"directive"`
+}
+
+// buildWafCustomRulesSnapshot 生成写入策略版本的自定义规则快照，无规则时为空串
+func buildWafCustomRulesSnapshot(rules []model.WafCustomRule) (string, error) {
+	if len(rules) == 0 {
+		return "", nil
+	}
+	snapshot := make([]wafCustomRuleSnapshot, 0, len(rules))
+	for _, rule := range rules {
+		snapshot = append(snapshot, wafCustomRuleSnapshot{
+			RuleID:    rule.RuleID,
+			Name:      rule.Name,
+			Phase:     rule.Phase,
+			Priority:  rule.Priority,
+			Directive: rule.Directive,
+		})
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return "", fmt.Errorf("生成自定义规则快照失败: %w", err)
+	}
+	return string(data), nil
+}
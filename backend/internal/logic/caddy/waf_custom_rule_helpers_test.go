@@ -0,0 +1,72 @@
+package caddy
+
+import (
+	"strings"
+	"testing"
+
+	"logflux/model"
+)
+
+func TestNormalizeAndValidateCustomRuleDirective(t *testing.T) {
+	directive, ruleID, phase, err := normalizeAndValidateCustomRuleDirective(
+		"SecRule REMOTE_ADDR \"@ipMatch 203.0.113.0/24\" \"id:10020,phase:request,deny,status:403,chain\"\r\n  SecRule REQUEST_URI \"@beginsWith /admin\" \"t:none\"\r\n",
+		0,
+	)
+	if err != nil {
+		t.Fatalf("normalizeAndValidateCustomRuleDirective() error = %v", err)
+	}
+	if ruleID != 10020 || phase != 2 || strings.Contains(directive, "\r") {
+		t.Fatalf("unexpected result: id=%d phase=%d directive=%q", ruleID, phase, directive)
+	}
+
+	if _, _, phase, err := normalizeAndValidateCustomRuleDirective(`SecAction "id:10021,pass,nolog,setvar:tx.rate_marker=1"`, 0); err != nil || phase != 2 {
+		t.Fatalf("expected default phase 2, got phase=%d err=%v", phase, err)
+	}
+
+	invalid := map[string]string{
+		"missing id":       `SecRule REQUEST_URI "@rx /x" "phase:1,deny"`,
+		"id out of range":  `SecRule REQUEST_URI "@rx /x" "id:942100,phase:1,deny"`,
+		"multiple ids":     "SecRule ARGS \"@rx a\" \"id:10001,phase:1,deny\"\nSecRule ARGS \"@rx b\" \"id:10002,phase:1,deny\"",
+		"engine directive": "SecRuleEngine Off\nSecAction \"id:10003,phase:1,pass\"",
+		"backtick":         "SecRule ARGS \"@rx `\" \"id:10004,phase:1,deny\"",
+		"syntax":           `SecRule ARGS "@noSuchOperator x" "id:10005,phase:1,deny"`,
+		"bad phase":        `SecRule ARGS "@rx a" "id:10006,phase:9,deny"`,
+	}
+	for name, raw := range invalid {
+		if _, _, _, err := normalizeAndValidateCustomRuleDirective(raw, 0); err == nil {
+			t.Fatalf("expected error for %s", name)
+		}
+	}
+
+	if _, _, _, err := normalizeAndValidateCustomRuleDirective(`SecRule ARGS "@rx a" "id:10007,phase:1,deny"`, 2); err == nil {
+		t.Fatalf("expected phase mismatch error")
+	}
+}
+
+func TestBuildWafCustomRuleDirectivesOrder(t *testing.T) {
+	rules := []model.WafCustomRule{
+		{RuleID: 10030, Priority: 200, Enabled: true, Directive: `SecAction "id:10030,phase:1,pass"`},
+		{RuleID: 10020, Priority: 100, Enabled: true, Directive: `SecAction "id:10020,phase:1,pass"`},
+		{RuleID: 10010, Priority: 200, Enabled: true, Directive: `SecAction "id:10010,phase:1,pass"`},
+		{RuleID: 10040, Priority: 1, Enabled: false, Directive: `SecAction "id:10040,phase:1,pass"`},
+	}
+
+	directives := buildWafCustomRuleDirectives(rules)
+	lines := strings.Split(directives, "\n")
+	if len(lines) != 3 || !strings.Contains(lines[0], "10020") || !strings.Contains(lines[1], "10010") || !strings.Contains(lines[2], "10030") {
+		t.Fatalf("unexpected custom rule order: %s", directives)
+	}
+
+	policy := &model.WafPolicy{
+		EngineMode: "on", AuditEngine: "relevantonly", AuditLogFormat: "json",
+		RequestBodyLimit: 10485760, RequestBodyNoFilesLimit: 1048576,
+		CrsTemplate: "balanced", CrsParanoiaLevel: 2, CrsInboundAnomalyThreshold: 5, CrsOutboundAnomalyThreshold: 4,
+	}
+	built, err := buildWafPolicyDirectives(policy, rules...)
+	if err != nil {
+		t.Fatalf("buildWafPolicyDirectives() error = %v", err)
+	}
+	if !strings.HasSuffix(built, directives) {
+		t.Fatalf("expected custom rules appended after base directives: %s", built)
+	}
+}
@@ -0,0 +1,336 @@
+package caddy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"logflux/internal/notification"
+	"logflux/internal/utils/safego"
+	"logflux/internal/waf"
+	"logflux/model"
+
+	"gorm.io/gorm"
+)
+
+const (
+	wafDistributeTargetRelease      = "release"
+	wafDistributeTargetPolicy       = "policy"
+	wafDistributeStrategySequential = "sequential"
+	wafDistributeStrategyParallel   = "parallel"
+	wafDeploymentStatusPending      = "pending"
+	wafDeploymentStatusRunning      = "running"
+)
+
+func normalizeWafDistributeTargetType(targetType string) (string, error) {
+	switch normalized := strings.ToLower(strings.TrimSpace(targetType)); normalized {
+	case wafDistributeTargetRelease, wafDistributeTargetPolicy:
+		return normalized, nil
+	default:
+		return "", fmt.Errorf("分发目标类型仅支持 release 或 policy")
+	}
+}
+
+func normalizeWafDistributeStrategy(strategy string) (string, error) {
+	switch normalized := strings.ToLower(strings.TrimSpace(strategy)); normalized {
+	case "", wafDistributeStrategySequential:
+		return wafDistributeStrategySequential, nil
+	case wafDistributeStrategyParallel:
+		return normalized, nil
+	default:
+		return "", fmt.Errorf("分发策略仅支持 sequential 或 parallel")
+	}
+}
+
+func ensureNoRunningWafDistribution(db *gorm.DB) error {
+	var count int64
+	if err := db.Model(&model.WafUpdateJob{}).
+		Where("action = ? AND status = ?", "distribute", wafJobStatusRunning).
+		Count(&count).Error; err != nil {
+		return fmt.Errorf("查询分发任务失败: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("存在进行中的分发任务，请等待完成后再试")
+	}
+	return nil
+}
+
+// wafDistribution 一次多节点分发：聚合任务记录在 WafUpdateJob，逐节点状态记录在 WafServerDeployment
+type wafDistribution struct {
+	helper      *wafLogicHelper
+	job         *model.WafUpdateJob
+	targetType  string
+	targetID    uint
+	servers     []model.CaddyServer
+	deployments []model.WafServerDeployment
+	options     waf.DeployOptions
+}
+
+type wafDistributionSpec struct {
+	TargetType    string
+	TargetID      uint
+	SourceID      uint
+	ReleaseID     uint
+	GroupID       uint
+	Strategy      string
+	StopOnFailure bool
+}
+
+func (helper *wafLogicHelper) startWafDistribution(spec wafDistributionSpec, servers []model.CaddyServer) (*wafDistribution, error) {
+	job := helper.startJob(spec.SourceID, spec.ReleaseID, "distribute", "manual")
+	if job == nil {
+		return nil, fmt.Errorf("创建分发任务失败")
+	}
+
+	job.Meta = model.JSONMap{
+		"targetType":    spec.TargetType,
+		"targetId":      spec.TargetID,
+		"groupId":       spec.GroupID,
+		"strategy":      spec.Strategy,
+		"stopOnFailure": spec.StopOnFailure,
+		"serverCount":   len(servers),
+	}
+	if err := helper.svcCtx.DB.WithContext(helper.ctx).Model(job).Update("meta", job.Meta).Error; err != nil {
+		helper.logger.Errorf("写入分发任务参数失败: %v", err)
+	}
+
+	deployments := make([]model.WafServerDeployment, 0, len(servers))
+	for _, server := range servers {
+		deployments = append(deployments, model.WafServerDeployment{
+			JobID:      job.ID,
+			TargetType: spec.TargetType,
+			TargetID:   spec.TargetID,
+			ServerID:   server.ID,
+			ServerName: server.Name,
+			Status:     wafDeploymentStatusPending,
+		})
+	}
+	if err := helper.svcCtx.DB.WithContext(helper.ctx).Create(&deployments).Error; err != nil {
+		helper.finishJob(job, wafJobStatusFailed, "创建节点部署记录失败: "+err.Error(), spec.ReleaseID)
+		return nil, fmt.Errorf("创建节点部署记录失败: %w", err)
+	}
+
+	dist := &wafDistribution{
+		helper:      helper,
+		job:         job,
+		targetType:  spec.TargetType,
+		targetID:    spec.TargetID,
+		servers:     servers,
+		deployments: deployments,
+		options: waf.DeployOptions{
+			Parallel:      spec.Strategy == wafDistributeStrategyParallel,
+			StopOnFailure: spec.StopOnFailure,
+		},
+	}
+	dist.options.OnResult = func(index int, result waf.DeployResult) {
+		dist.record(index, result.Status, result.Err)
+	}
+	return dist, nil
+}
+
+func (dist *wafDistribution) markRunning() {
+	now := time.Now()
+	if err := dist.helper.svcCtx.DB.WithContext(dist.helper.ctx).Model(&model.WafServerDeployment{}).
+		Where("job_id = ?", dist.job.ID).
+		Updates(map[string]interface{}{"status": wafDeploymentStatusRunning, "started_at": &now}).Error; err != nil {
+		dist.helper.logger.Errorf("更新节点部署状态失败: %v", err)
+	}
+}
+
+func (dist *wafDistribution) record(index int, status string, err error) {
+	if index < 0 || index >= len(dist.deployments) {
+		return
+	}
+	now := time.Now()
+	message := ""
+	if err != nil {
+		message = localizeWafJobMessage(err.Error())
+	}
+	if updateErr := dist.helper.svcCtx.DB.WithContext(dist.helper.ctx).Model(&model.WafServerDeployment{}).
+		Where("id = ?", dist.deployments[index].ID).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"message":     message,
+			"finished_at": &now,
+		}).Error; updateErr != nil {
+		dist.helper.logger.Errorf("更新节点部署状态失败: server=%s err=%v", dist.deployments[index].ServerName, updateErr)
+	}
+}
+
+func (dist *wafDistribution) finish(results []waf.DeployResult, runErr error, releaseID uint) {
+	counts := map[string]int{}
+	for _, result := range results {
+		counts[result.Status]++
+	}
+	summary := fmt.Sprintf("共 %d 个节点：成功 %d，失败 %d，跳过 %d，回滚 %d",
+		len(dist.servers),
+		counts[waf.DeployStatusSuccess],
+		counts[waf.DeployStatusFailed],
+		counts[waf.DeployStatusSkipped],
+		counts[waf.DeployStatusRolledBack],
+	)
+	if runErr != nil {
+		dist.helper.finishJob(dist.job, wafJobStatusFailed, summary+"；"+runErr.Error(), releaseID)
+		return
+	}
+	dist.helper.finishJob(dist.job, wafJobStatusSuccess, summary, releaseID)
+}
+
+// runRelease 按策略逐节点切换各自的 current-<serverID> 链接，并加载按该链接渲染的配置
+func (dist *wafDistribution) runRelease(release *model.WafRelease) {
+	dist.markRunning()
+
+	sharedLink := dist.helper.store.CurrentLinkPath()
+	targets := make([]waf.DeployTarget, len(dist.servers))
+	for index := range dist.servers {
+		server := &dist.servers[index]
+		targets[index] = waf.DeployTarget{
+			ServerID: server.ID,
+			Config:   server.Config,
+			Loader:   &wafCaddyLoader{server: server},
+			Render: func(currentLink string) string {
+				return renderWafServerCurrentLink(server.Config, sharedLink, currentLink)
+			},
+		}
+	}
+
+	activator := &waf.Activator{Store: dist.helper.store}
+	results, err := activator.DeployVersion(release.Version, targets, dist.options)
+
+	succeeded := 0
+	for _, result := range results {
+		if result.Status == waf.DeployStatusSuccess {
+			succeeded++
+		}
+	}
+	if succeeded > 0 {
+		if markErr := dist.helper.markReleaseActive(release); markErr != nil {
+			dist.finish(results, fmt.Errorf("标记激活状态失败: %w", markErr), release.ID)
+			return
+		}
+	} else if err != nil {
+		dist.helper.markReleaseFailed(release, err.Error())
+	}
+	dist.finish(results, err, release.ID)
+}
+
+// renderWafServerCurrentLink 把配置中引用共享 current 链接的路径改写为节点自身的链接
+func renderWafServerCurrentLink(config, sharedLink, serverLink string) string {
+	if strings.TrimSpace(sharedLink) == "" || sharedLink == serverLink {
+		return config
+	}
+	return strings.ReplaceAll(config, sharedLink+"/", serverLink+"/")
+}
+
+// runPolicy 复用单节点发布的校验、加载与 last_good 自动回滚流程，全部节点处理完后统一落库
+func (dist *wafDistribution) runPolicy(publish *PolicyPublishService, candidates []*PolicyPublishCandidate, operator string) {
+	dist.markRunning()
+
+	results, err := dist.publishPolicyCandidates(publish, candidates, operator)
+	dist.finish(results, err, 0)
+
+	policy := candidates[0].Policy
+	notifier := NewWafPolicyNotifyAuditHelper(dist.helper.svcCtx, dist.helper.logger)
+	if err != nil {
+		_ = notifier.NotifyFailure(notification.EventSecurityWafPolicyPublishFailed, "WAF 策略分发失败", policy.ID, policy.Name, operator, err)
+		return
+	}
+	notifier.NotifySuccess(
+		notification.EventSecurityWafPolicyPublished,
+		"WAF 策略已分发",
+		fmt.Sprintf("WAF 策略分发成功：policy=%s servers=%d", policy.Name, len(candidates)),
+		policy.ID,
+		policy.Name,
+		operator,
+	)
+}
+
+func (dist *wafDistribution) publishPolicyCandidates(publish *PolicyPublishService, candidates []*PolicyPublishCandidate, operator string) ([]waf.DeployResult, error) {
+	results := make([]waf.DeployResult, len(candidates))
+	report := func(index int, status string, err error) {
+		results[index] = waf.DeployResult{Status: status, Err: err}
+		dist.record(index, status, err)
+	}
+
+	all := make([]int, len(candidates))
+	for index := range candidates {
+		all[index] = index
+	}
+
+	validateErrs := waf.RunDeploySteps(all, dist.options, func(index int) error {
+		return publish.ValidateCandidate(candidates[index], "publish")
+	})
+	loadable := make([]int, 0, len(candidates))
+	failed := 0
+	for position, index := range all {
+		switch err := validateErrs[position]; {
+		case err == nil:
+			loadable = append(loadable, index)
+		case errors.Is(err, waf.ErrDeploySkipped):
+			report(index, waf.DeployStatusSkipped, err)
+		default:
+			failed++
+			report(index, waf.DeployStatusFailed, err)
+		}
+	}
+	if failed > 0 && dist.options.StopOnFailure || len(loadable) == 0 {
+		for _, index := range loadable {
+			report(index, waf.DeployStatusSkipped, waf.ErrDeploySkipped)
+		}
+		return results, fmt.Errorf("%d 个节点校验失败，已终止分发", failed)
+	}
+
+	// LoadCandidate 失败时已将该节点回滚到 last_good
+	loadErrs := waf.RunDeploySteps(loadable, dist.options, func(index int) error {
+		return publish.LoadCandidate(candidates[index], "publish")
+	})
+	succeeded := make([]int, 0, len(loadable))
+	for position, index := range loadable {
+		switch err := loadErrs[position]; {
+		case err == nil:
+			succeeded = append(succeeded, index)
+		case errors.Is(err, waf.ErrDeploySkipped):
+			report(index, waf.DeployStatusSkipped, err)
+		default:
+			failed++
+			report(index, waf.DeployStatusFailed, err)
+		}
+	}
+	if failed > 0 && dist.options.StopOnFailure || len(succeeded) == 0 {
+		for _, index := range succeeded {
+			candidate := candidates[index]
+			if rollbackErr := rollbackPolicyConfigToLastGood(candidate.Server, candidate.LastGoodConfig); rollbackErr != nil {
+				report(index, waf.DeployStatusFailed, fmt.Errorf("回滚到 last_good 失败: %w", rollbackErr))
+				continue
+			}
+			report(index, waf.DeployStatusRolledBack, fmt.Errorf("其他节点失败，已回滚到 last_good"))
+		}
+		return results, fmt.Errorf("%d 个节点发布失败，已回滚到 last_good", failed)
+	}
+
+	published := make([]*PolicyPublishCandidate, 0, len(succeeded))
+	for _, index := range succeeded {
+		published = append(published, candidates[index])
+	}
+	// 落库失败时 PersistPublishedCandidates 会将所有已加载节点回滚到 last_good
+	if err := publish.PersistPublishedCandidates(published, operator); err != nil {
+		for _, index := range succeeded {
+			report(index, waf.DeployStatusRolledBack, err)
+		}
+		return results, err
+	}
+
+	for _, index := range succeeded {
+		report(index, waf.DeployStatusSuccess, nil)
+		server := candidates[index].Server
+		safego.New(context.Background(), "分发 WAF 策略后同步日志源").Go(func() {
+			syncCaddyLogSources(dist.helper.svcCtx, server, dist.helper.logger)
+		})
+	}
+	if failed > 0 {
+		return results, fmt.Errorf("%d/%d 个节点发布失败", failed, len(candidates))
+	}
+	return results, nil
+}
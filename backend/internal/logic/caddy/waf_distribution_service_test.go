@@ -0,0 +1,92 @@
+package caddy
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestNormalizeWafDistributeOptions(t *testing.T) {
+	if strategy, err := normalizeWafDistributeStrategy(""); err != nil || strategy != wafDistributeStrategySequential {
+		t.Fatalf("expected sequential by default, got %q err=%v", strategy, err)
+	}
+	if strategy, err := normalizeWafDistributeStrategy(" Parallel "); err != nil || strategy != wafDistributeStrategyParallel {
+		t.Fatalf("expected parallel, got %q err=%v", strategy, err)
+	}
+	if _, err := normalizeWafDistributeStrategy("canary"); err == nil {
+		t.Fatalf("expected unknown strategy to be rejected")
+	}
+	if targetType, err := normalizeWafDistributeTargetType("POLICY"); err != nil || targetType != wafDistributeTargetPolicy {
+		t.Fatalf("expected policy target, got %q err=%v", targetType, err)
+	}
+	if _, err := normalizeWafDistributeTargetType("rule"); err == nil {
+		t.Fatalf("expected unknown target type to be rejected")
+	}
+}
+
+func TestResolveWafDistributionServersByGroup(t *testing.T) {
+	db, mock, cleanup := newWafMirrorMockDB(t)
+	defer cleanup()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "caddy_server_groups" WHERE "caddy_server_groups"."id" = $1`)).
+		WithArgs(3, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "server_ids"}).AddRow(3, "edge", "{1,2}"))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "caddy_servers" WHERE id IN ($1,$2) ORDER BY id asc`)).
+		WithArgs(int64(1), int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "config"}).
+			AddRow(1, "edge-1", ":80 {\n}\n").
+			AddRow(2, "edge-2", ":80 {\n}\n"))
+
+	servers, err := resolveWafDistributionServers(db, 3)
+	if err != nil || len(servers) != 2 || servers[1].Name != "edge-2" {
+		t.Fatalf("unexpected servers: %+v err=%v", servers, err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "caddy_servers" ORDER BY id asc`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "config"}).
+			AddRow(1, "edge-1", ":80 {\n}\n").
+			AddRow(2, "edge-2", ""))
+	if _, err := resolveWafDistributionServers(db, 0); err == nil || !strings.Contains(err.Error(), "edge-2") {
+		t.Fatalf("expected empty config to be rejected, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("sql expectations not met: %v", err)
+	}
+}
+
+func TestNormalizeCaddyServerGroupRejectsUnknownServers(t *testing.T) {
+	db, mock, cleanup := newWafMirrorMockDB(t)
+	defer cleanup()
+
+	if _, _, err := normalizeCaddyServerGroup(db, 0, "edge", []int64{0, -1}); err == nil {
+		t.Fatalf("expected empty server list to be rejected")
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "caddy_server_groups" WHERE name = $1`)).
+		WithArgs("edge").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "caddy_servers" WHERE id IN ($1,$2)`)).
+		WithArgs(int64(2), int64(5)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	if _, _, err := normalizeCaddyServerGroup(db, 0, " edge ", []int64{5, 2, 5}); err == nil || !strings.Contains(err.Error(), "不存在") {
+		t.Fatalf("expected unknown server to be rejected, got %v", err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "caddy_server_groups" WHERE name = $1`)).
+		WithArgs("edge").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "caddy_servers" WHERE id IN ($1,$2)`)).
+		WithArgs(int64(2), int64(5)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	name, serverIDs, err := normalizeCaddyServerGroup(db, 0, "edge", []int64{5, 2})
+	if err != nil || name != "edge" || len(serverIDs) != 2 || serverIDs[0] != 2 {
+		t.Fatalf("unexpected normalized group: %s %v err=%v", name, serverIDs, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("sql expectations not met: %v", err)
+	}
+}
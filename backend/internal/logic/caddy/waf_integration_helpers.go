@@ -3,9 +3,17 @@ package caddy
 import (
 	"fmt"
 	"strings"
+
+	"logflux/internal/caddyfile"
 )
 
-const wafProtectImportLine = "import waf_protect"
+const (
+	wafProtectImportLine = "import waf_protect"
+	corazaOrderLine      = "order coraza_waf first"
+	// IP 名单按 Caddy 解析出的客户端 IP 匹配，写入请求头的 request_header 需先于 coraza_waf 执行
+	wafClientIPOrderLine  = "order request_header before coraza_waf"
+	wafClientIPHeaderLine = "request_header " + wafClientIPHeader + " {client_ip}"
+)
 
 type caddyTopLevelBlock struct {
 	Kind      string
@@ -13,6 +21,7 @@ type caddyTopLevelBlock struct {
 	Address   string
 	Header    string
 	StartLine int
+	OpenLine  int
 	EndLine   int
 }
 
@@ -38,7 +47,7 @@ func inspectWafIntegration(config string) (*wafIntegrationSnapshot, error) {
 	for _, block := range blocks {
 		switch block.Kind {
 		case "options":
-			if blockContainsExactLine(lines, block, "order coraza_waf first") {
+			if blockContainsExactLine(lines, block, corazaOrderLine) && blockContainsExactLine(lines, block, wafClientIPOrderLine) {
 				snapshot.OrderReady = true
 			}
 		case "snippet":
@@ -46,7 +55,7 @@ func inspectWafIntegration(config string) (*wafIntegrationSnapshot, error) {
 				continue
 			}
 			content := joinBlockLines(lines, block)
-			if strings.Contains(content, "coraza_waf") {
+			if strings.Contains(content, "coraza_waf") && blockContainsExactLine(lines, block, wafClientIPHeaderLine) {
 				snapshot.SnippetReady = true
 			}
 			if strings.Contains(content, "directives `") {
@@ -75,7 +84,7 @@ func buildWafIntegrationStatusMessage(snapshot *wafIntegrationSnapshot) string {
 		return "未识别到可接入的站点块，请先保存完整 Caddy 配置"
 	}
 	if !snapshot.OrderReady {
-		return "尚未注入全局 order coraza_waf first"
+		return "尚未注入全局 order coraza_waf first 与 order request_header before coraza_waf"
 	}
 	if !snapshot.SnippetReady {
 		return "尚未注入 waf_protect 片段"
@@ -100,18 +109,28 @@ func ensureCorazaOrder(config string) (string, bool, error) {
 		if block.Kind != "options" {
 			continue
 		}
-		if blockContainsExactLine(lines, block, "order coraza_waf first") {
+		indent := detectBlockChildIndent(lines, block, "  ")
+		insertAt := block.OpenLine + 1
+		insert := make([]string, 0, 2)
+		if idx := blockLineIndex(lines, block, corazaOrderLine); idx >= 0 {
+			insertAt = idx + 1
+		} else {
+			insert = append(insert, indent+corazaOrderLine+newline)
+		}
+		if !blockContainsExactLine(lines, block, wafClientIPOrderLine) {
+			insert = append(insert, indent+wafClientIPOrderLine+newline)
+		}
+		if len(insert) == 0 {
 			return config, false, nil
 		}
-		indent := detectBlockChildIndent(lines, block, "  ")
-		insertLine := indent + "order coraza_waf first" + newline
-		updated := insertLines(lines, block.StartLine+1, []string{insertLine})
+		updated := insertLines(lines, insertAt, insert)
 		return strings.Join(updated, ""), true, nil
 	}
 
 	prefix := []string{
 		"{" + newline,
-		"  order coraza_waf first" + newline,
+		"  " + corazaOrderLine + newline,
+		"  " + wafClientIPOrderLine + newline,
 		"}" + newline,
 	}
 	if strings.TrimSpace(config) != "" {
@@ -135,7 +154,12 @@ func ensureWafProtectSnippet(config string) (string, bool, error) {
 		}
 		content := joinBlockLines(lines, block)
 		if strings.Contains(content, "coraza_waf") && strings.Contains(content, "directives `") {
-			return config, false, nil
+			if blockContainsExactLine(lines, block, wafClientIPHeaderLine) {
+				return config, false, nil
+			}
+			indent := detectBlockChildIndent(lines, block, "  ")
+			updated := insertLines(lines, block.OpenLine+1, []string{indent + wafClientIPHeaderLine + newline})
+			return strings.Join(updated, ""), true, nil
 		}
 		updated := replaceLineRange(lines, block.StartLine, block.EndLine, templateLines)
 		return strings.Join(updated, ""), true, nil
@@ -166,7 +190,7 @@ func ensureSiteImport(config, siteAddress string) (string, bool, error) {
 	}
 	indent := detectBlockChildIndent(lines, *block, "  ")
 	newline := detectCaddyNewline(config)
-	updated := insertLines(lines, block.StartLine+1, []string{indent + wafProtectImportLine + newline})
+	updated := insertLines(lines, block.OpenLine+1, []string{indent + wafProtectImportLine + newline})
 	return strings.Join(updated, ""), true, nil
 }
 
@@ -179,7 +203,7 @@ func removeSiteImport(config, siteAddress string) (string, bool, error) {
 	removed := false
 	nextLines := make([]string, 0, len(lines))
 	for idx, line := range lines {
-		if idx > block.StartLine && idx <= block.EndLine && strings.TrimSpace(strings.TrimRight(line, "\r\n")) == wafProtectImportLine {
+		if idx > block.OpenLine && idx <= block.EndLine && strings.TrimSpace(strings.TrimRight(line, "\r\n")) == wafProtectImportLine {
 			removed = true
 			continue
 		}
@@ -206,101 +230,64 @@ func findSiteBlock(config, siteAddress string) (*caddyTopLevelBlock, []string, e
 	return nil, nil, fmt.Errorf("站点不存在: %s", target)
 }
 
+// parseTopLevelCaddyBlocks 基于 Caddyfile 语法树定位顶层块，行号供按行编辑的辅助函数使用；
+// 单行块（例如 "a.com { respond ok }"）没有可插入的内部行，不参与按行编辑
 func parseTopLevelCaddyBlocks(config string) ([]caddyTopLevelBlock, []string, error) {
+	doc, err := caddyfile.Parse(config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Caddy 配置结构无效: %w", err)
+	}
 	lines := splitLinesKeepEndings(config)
 	blocks := make([]caddyTopLevelBlock, 0)
-	depth := 0
-	inBacktick := false
-	current := (*caddyTopLevelBlock)(nil)
-
-	for idx, line := range lines {
-		trimmed := strings.TrimSpace(strings.TrimRight(line, "\r\n"))
-		if depth == 0 && !inBacktick && current == nil && isTopLevelBlockHeader(trimmed) {
-			current = &caddyTopLevelBlock{
-				Header:    trimmed,
-				StartLine: idx,
-			}
-			classifyTopLevelBlock(current)
-		}
-
-		updateCaddyParseState(line, &depth, &inBacktick)
-
-		if current != nil && depth == 0 && !inBacktick {
-			current.EndLine = idx
-			blocks = append(blocks, *current)
-			current = nil
+	for _, node := range doc.Nodes {
+		if node.Block == nil || node.OpenLine == node.EndLine {
+			continue
 		}
-	}
-
-	if current != nil || depth != 0 || inBacktick {
-		return nil, nil, fmt.Errorf("Caddy 配置结构无效")
+		blocks = append(blocks, newCaddyTopLevelBlock(node))
 	}
 	return blocks, lines, nil
 }
 
-func isTopLevelBlockHeader(trimmed string) bool {
-	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
-		return false
+func newCaddyTopLevelBlock(node *caddyfile.Node) caddyTopLevelBlock {
+	block := caddyTopLevelBlock{
+		Header:    strings.TrimSpace(strings.Join(append(rawTokens(node), "{"), " ")),
+		StartLine: node.StartLine,
+		OpenLine:  node.OpenLine,
+		EndLine:   node.EndLine,
 	}
-	return strings.HasSuffix(trimmed, "{")
-}
-
-func classifyTopLevelBlock(block *caddyTopLevelBlock) {
-	if block == nil {
-		return
-	}
-	header := strings.TrimSpace(block.Header)
+	name := node.Name()
 	switch {
-	case header == "{":
+	case len(node.Tokens) == 0:
 		block.Kind = "options"
-	case strings.HasPrefix(header, "("):
+	case strings.HasPrefix(name, "("):
 		block.Kind = "snippet"
-		if end := strings.Index(header, ")"); end > 1 {
-			block.Name = strings.TrimSpace(header[1:end])
-		}
+		block.Name = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(name, "("), ")"))
 	default:
 		block.Kind = "site"
-		block.Address = strings.TrimSpace(strings.TrimSuffix(header, "{"))
+		block.Address = strings.Join(rawTokens(node), " ")
 	}
+	return block
 }
 
-func updateCaddyParseState(line string, depth *int, inBacktick *bool) {
-	if depth == nil || inBacktick == nil {
-		return
-	}
-	for idx := 0; idx < len(line); idx++ {
-		ch := line[idx]
-		if *inBacktick {
-			if ch == '`' {
-				*inBacktick = false
-			}
-			continue
-		}
-		if ch == '#' {
-			break
-		}
-		if ch == '`' {
-			*inBacktick = true
-			continue
-		}
-		switch ch {
-		case '{':
-			*depth = *depth + 1
-		case '}':
-			if *depth > 0 {
-				*depth = *depth - 1
-			}
-		}
+func rawTokens(node *caddyfile.Node) []string {
+	values := make([]string, 0, len(node.Tokens))
+	for _, token := range node.Tokens {
+		values = append(values, token.Raw)
 	}
+	return values
 }
 
 func blockContainsExactLine(lines []string, block caddyTopLevelBlock, target string) bool {
-	for idx := block.StartLine + 1; idx < block.EndLine; idx++ {
+	return blockLineIndex(lines, block, target) >= 0
+}
+
+func blockLineIndex(lines []string, block caddyTopLevelBlock, target string) int {
+	for idx := block.OpenLine + 1; idx < block.EndLine; idx++ {
 		if strings.TrimSpace(strings.TrimRight(lines[idx], "\r\n")) == target {
-			return true
+			return idx
 		}
 	}
-	return false
+	return -1
 }
 
 func joinBlockLines(lines []string, block caddyTopLevelBlock) string {
@@ -311,7 +298,7 @@ func joinBlockLines(lines []string, block caddyTopLevelBlock) string {
 }
 
 func detectBlockChildIndent(lines []string, block caddyTopLevelBlock, fallback string) string {
-	for idx := block.StartLine + 1; idx < block.EndLine; idx++ {
+	for idx := block.OpenLine + 1; idx < block.EndLine; idx++ {
 		trimmed := strings.TrimSpace(strings.TrimRight(lines[idx], "\r\n"))
 		if trimmed == "" || trimmed == "}" {
 			continue
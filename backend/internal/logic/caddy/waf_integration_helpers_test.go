@@ -26,8 +26,8 @@ func TestEnsureCorazaOrderAndSnippet(t *testing.T) {
 	if !changed {
 		t.Fatalf("expected order change")
 	}
-	if !strings.Contains(config, "order coraza_waf first") {
-		t.Fatalf("expected coraza order in config")
+	if !strings.Contains(config, "order coraza_waf first\n  order request_header before coraza_waf\n") {
+		t.Fatalf("expected coraza and client ip header order in config:\n%s", config)
 	}
 
 	config, changed, err = ensureWafProtectSnippet(config)
@@ -85,3 +85,50 @@ func TestEnsureAndRemoveSiteImport(t *testing.T) {
 		t.Fatalf("expected import removed from example.com block")
 	}
 }
+
+func TestEnsureSiteImportHandlesQuotedBracesAndMultilineAddress(t *testing.T) {
+	config := "a.example.com,\n    b.example.com {\n  respond \"{\" 200\n  respond /doc <<EOF\n  }\n  EOF\n}\n\nexample.com {\n  reverse_proxy 127.0.0.1:8080\n}\n"
+
+	updated, changed, err := ensureSiteImport(config, "example.com")
+	if err != nil || !changed {
+		t.Fatalf("ensureSiteImport() changed=%v err=%v", changed, err)
+	}
+	if !strings.Contains(updated, "example.com {\n  import waf_protect\n  reverse_proxy") {
+		t.Fatalf("expected import inserted into example.com block:\n%s", updated)
+	}
+
+	updated, changed, err = ensureSiteImport(updated, "a.example.com, b.example.com")
+	if err != nil || !changed {
+		t.Fatalf("ensureSiteImport() multiline changed=%v err=%v", changed, err)
+	}
+	if !strings.Contains(updated, "    b.example.com {\n  import waf_protect\n  respond \"{\" 200\n") {
+		t.Fatalf("expected import inserted after the opening brace:\n%s", updated)
+	}
+}
+
+func TestEnsureWafClientIPHeaderOnExistingIntegration(t *testing.T) {
+	config := "{\n  admin :2019\n  order coraza_waf first\n}\n\n(waf_protect) {\n  coraza_waf {\n    directives `\n      SecRuleEngine On\n    `\n  }\n}\n\nexample.com {\n  import waf_protect\n}\n"
+
+	config, changed, err := ensureCorazaOrder(config)
+	if err != nil || !changed {
+		t.Fatalf("expected client ip order to be added, changed=%v err=%v", changed, err)
+	}
+	config, changed, err = ensureWafProtectSnippet(config)
+	if err != nil || !changed {
+		t.Fatalf("expected client ip header to be added, changed=%v err=%v", changed, err)
+	}
+	if !strings.Contains(config, "(waf_protect) {\n  request_header X-Logflux-Client-Ip {client_ip}\n  coraza_waf {") {
+		t.Fatalf("unexpected snippet:\n%s", config)
+	}
+
+	snapshot, err := inspectWafIntegration(config)
+	if err != nil {
+		t.Fatalf("inspectWafIntegration() error = %v", err)
+	}
+	if !snapshot.OrderReady || !snapshot.SnippetReady {
+		t.Fatalf("unexpected snapshot: %+v", snapshot)
+	}
+	if _, changed, _ = ensureCorazaOrder(config); changed {
+		t.Fatalf("expected order to be idempotent")
+	}
+}
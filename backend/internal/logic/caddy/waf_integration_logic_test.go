@@ -23,7 +23,7 @@ func TestGetWafIntegrationStatus(t *testing.T) {
 	defer cleanup()
 
 	now := time.Now()
-	config := "{\n  admin :2019\n  order coraza_waf first\n}\n\n" + renderWafProtectSnippet("\n") + "\nexample.com {\n  import waf_protect\n  reverse_proxy localhost:8080\n}\n"
+	config := "{\n  admin :2019\n  order coraza_waf first\n  order request_header before coraza_waf\n}\n\n" + renderWafProtectSnippet("\n") + "\nexample.com {\n  import waf_protect\n  reverse_proxy localhost:8080\n}\n"
 	mock.ExpectQuery(`SELECT .* FROM "caddy_servers"`).WillReturnRows(caddyServerRows(now, "http://127.0.0.1:2019", config))
 
 	logic := NewGetWafIntegrationStatusLogic(context.Background(), &svc.ServiceContext{DB: gdb})
@@ -85,6 +85,7 @@ func TestApplyWafIntegrationPersist(t *testing.T) {
 
 	now := time.Now()
 	mock.ExpectQuery(`SELECT .* FROM "caddy_servers"`).WillReturnRows(caddyServerRows(now, caddyMock.URL, integrationBaseConfig))
+	mock.ExpectQuery(`SELECT .* FROM "caddy_apply_verify_settings"`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
 	mock.ExpectBegin()
 	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "caddy_servers" SET`)).WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "caddy_config_history"`)).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
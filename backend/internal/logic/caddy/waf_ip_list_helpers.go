@@ -0,0 +1,388 @@
+package caddy
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"logflux/internal/types"
+	"logflux/internal/utils"
+	"logflux/model"
+
+	"gorm.io/gorm"
+)
+
+const (
+	wafIPListActionAllow = "allow"
+	wafIPListActionDeny  = "deny"
+
+	wafIPListEntryKindIP      = "ip"
+	wafIPListEntryKindCountry = "country"
+
+	wafIPListEntrySourceManual   = "manual"
+	wafIPListEntrySourceCaddyLog = "caddy_log"
+	wafIPListEntrySourceDash     = "dashboard"
+
+	// IP 名单规则 ID 区间，位于自定义规则（10000 起）之前
+	wafIPListRuleIDMin int64 = 9000
+	wafIPListRuleIDMax int64 = 9999
+
+	// wafClientIPHeader 由 Caddy 在 coraza_waf 之前写入按可信代理解析后的客户端 IP，
+	// 会覆盖请求自带的同名头；Coraza 的 REMOTE_ADDR 只是直连对端地址
+	wafClientIPHeader = "X-Logflux-Client-Ip"
+)
+
+var regexWafCountryCode = regexp.MustCompile(`^[A-Z]{2}$`)
+
+func normalizeWafIPListAction(action string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(action))
+	switch normalized {
+	case "":
+		return wafIPListActionDeny, nil
+	case wafIPListActionAllow, wafIPListActionDeny:
+		return normalized, nil
+	default:
+		return "", fmt.Errorf("IP 名单动作无效: %s", action)
+	}
+}
+
+func normalizeWafIPListEntrySource(source string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(source))
+	switch normalized {
+	case "":
+		return wafIPListEntrySourceManual, nil
+	case wafIPListEntrySourceManual, wafIPListEntrySourceCaddyLog, wafIPListEntrySourceDash:
+		return normalized, nil
+	default:
+		return "", fmt.Errorf("名单条目来源无效: %s", source)
+	}
+}
+
+// normalizeWafIPListEntryValue 规范化条目：IP 去掉多余写法，CIDR 取网络地址，国家代码转大写
+func normalizeWafIPListEntryValue(kind, value string) (string, string, error) {
+	normalizedKind := strings.ToLower(strings.TrimSpace(kind))
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return "", "", fmt.Errorf("名单条目不能为空")
+	}
+
+	switch normalizedKind {
+	case "", wafIPListEntryKindIP:
+		if strings.Contains(trimmed, "/") {
+			_, network, err := net.ParseCIDR(trimmed)
+			if err != nil {
+				return "", "", fmt.Errorf("CIDR 格式不合法: %s", trimmed)
+			}
+			return wafIPListEntryKindIP, network.String(), nil
+		}
+		ip := net.ParseIP(trimmed)
+		if ip == nil {
+			return "", "", fmt.Errorf("IP 格式不合法: %s", trimmed)
+		}
+		return wafIPListEntryKindIP, ip.String(), nil
+	case wafIPListEntryKindCountry:
+		code := strings.ToUpper(trimmed)
+		if !regexWafCountryCode.MatchString(code) {
+			return "", "", fmt.Errorf("国家/地区代码需为 ISO 3166-1 两位字母: %s", trimmed)
+		}
+		return wafIPListEntryKindCountry, code, nil
+	default:
+		return "", "", fmt.Errorf("名单条目类型无效: %s", kind)
+	}
+}
+
+func parseWafIPListEntryExpiresAt(raw string, now time.Time) (*time.Time, error) {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" {
+		return nil, nil
+	}
+	value, err := utils.ParseOptionalTime(trimmed)
+	if err != nil {
+		return nil, fmt.Errorf("到期时间格式不合法: %w", err)
+	}
+	if value != nil && !value.After(now) {
+		return nil, fmt.Errorf("到期时间必须晚于当前时间")
+	}
+	return value, nil
+}
+
+func validateWafIPListBinding(db *gorm.DB, policyID, bindingID uint) error {
+	if bindingID == 0 {
+		return nil
+	}
+	var binding model.WafPolicyBinding
+	if err := db.First(&binding, bindingID).Error; err != nil {
+		return fmt.Errorf("策略绑定不存在")
+	}
+	if binding.PolicyID != policyID {
+		return fmt.Errorf("策略绑定不属于当前策略")
+	}
+	return nil
+}
+
+// resolveWafIPFromCaddyLog 从访问日志中取客户端 IP，优先使用 client_ip
+func resolveWafIPFromCaddyLog(db *gorm.DB, caddyLogID uint) (string, error) {
+	var item model.CaddyLog
+	if err := db.Select("id, remote_ip, client_ip").First(&item, caddyLogID).Error; err != nil {
+		return "", fmt.Errorf("访问日志不存在")
+	}
+	if ip := strings.TrimSpace(item.ClientIP); ip != "" {
+		return ip, nil
+	}
+	if ip := strings.TrimSpace(item.RemoteIP); ip != "" {
+		return ip, nil
+	}
+	return "", fmt.Errorf("访问日志缺少客户端 IP")
+}
+
+type wafIPListRenderSet struct {
+	Lists    []model.WafIPList
+	Entries  map[uint][]model.WafIPListEntry
+	Bindings map[uint]model.WafPolicyBinding
+}
+
+// loadWafIPListRenderSet 读取策略下已启用名单及其未过期条目；kind 为空时读取全部类型
+func loadWafIPListRenderSet(db *gorm.DB, policyID uint, kind string, now time.Time) (*wafIPListRenderSet, error) {
+	set := &wafIPListRenderSet{
+		Entries:  make(map[uint][]model.WafIPListEntry),
+		Bindings: make(map[uint]model.WafPolicyBinding),
+	}
+
+	listQuery := db.Where("policy_id = ? AND enabled = ?", policyID, true)
+	if kind != "" {
+		listQuery = listQuery.Where(
+			"EXISTS (SELECT 1 FROM waf_ip_list_entries e WHERE e.list_id = waf_ip_lists.id AND e.kind = ? AND (e.expires_at IS NULL OR e.expires_at > ?))",
+			kind, now,
+		)
+	}
+	if err := listQuery.Order("id asc").Find(&set.Lists).Error; err != nil {
+		return nil, fmt.Errorf("查询 IP 名单失败: %w", err)
+	}
+	if len(set.Lists) == 0 {
+		return set, nil
+	}
+
+	listIDs := make([]uint, 0, len(set.Lists))
+	bindingIDs := make([]uint, 0)
+	for _, list := range set.Lists {
+		listIDs = append(listIDs, list.ID)
+		if list.BindingID > 0 {
+			bindingIDs = append(bindingIDs, list.BindingID)
+		}
+	}
+
+	entryQuery := db.Where("list_id IN ? AND (expires_at IS NULL OR expires_at > ?)", listIDs, now)
+	if kind != "" {
+		entryQuery = entryQuery.Where("kind = ?", kind)
+	}
+	var entries []model.WafIPListEntry
+	if err := entryQuery.Order("id asc").Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("查询 IP 名单条目失败: %w", err)
+	}
+	for _, entry := range entries {
+		set.Entries[entry.ListID] = append(set.Entries[entry.ListID], entry)
+	}
+
+	if len(bindingIDs) > 0 {
+		var bindings []model.WafPolicyBinding
+		if err := db.Where("id IN ?", bindingIDs).Find(&bindings).Error; err != nil {
+			return nil, fmt.Errorf("查询 IP 名单绑定失败: %w", err)
+		}
+		for _, binding := range bindings {
+			set.Bindings[binding.ID] = binding
+		}
+	}
+	return set, nil
+}
+
+// buildWafIPListDirectives 将 IP/CIDR 条目渲染为 phase:1 的 @ipMatch 规则：允许名单先于拒绝名单，
+// 绑定作用域通过 chain 限定，带到期时间的条目额外校验 TIME_EPOCH，过期后无需重新发布即失效
+func buildWafIPListDirectives(set *wafIPListRenderSet) (string, error) {
+	if set == nil || len(set.Lists) == 0 {
+		return "", nil
+	}
+
+	lists := append([]model.WafIPList(nil), set.Lists...)
+	sort.SliceStable(lists, func(i, j int) bool {
+		return lists[i].Action == wafIPListActionAllow && lists[j].Action != wafIPListActionAllow
+	})
+
+	ruleID := wafIPListRuleIDMin
+	lines := make([]string, 0)
+	for _, list := range lists {
+		scope := make([]wafDirectiveMatcher, 0, 3)
+		if list.BindingID > 0 {
+			binding, ok := set.Bindings[list.BindingID]
+			if !ok || !binding.Enabled {
+				continue
+			}
+			scope = wafBindingScopeMatchers(&binding)
+		}
+
+		// 按到期时间分组，0 表示永久
+		groups := make(map[int64][]string)
+		for _, entry := range set.Entries[list.ID] {
+			if entry.Kind != wafIPListEntryKindIP {
+				continue
+			}
+			var expiresAt int64
+			if entry.ExpiresAt != nil {
+				expiresAt = entry.ExpiresAt.Unix()
+			}
+			groups[expiresAt] = append(groups[expiresAt], entry.Value)
+		}
+		expiries := make([]int64, 0, len(groups))
+		for expiresAt := range groups {
+			expiries = append(expiries, expiresAt)
+		}
+		sort.Slice(expiries, func(i, j int) bool { return expiries[i] < expiries[j] })
+
+		for _, expiresAt := range expiries {
+			if ruleID > wafIPListRuleIDMax {
+				return "", fmt.Errorf("IP 名单规则数量超出上限")
+			}
+			matchers := append([]wafDirectiveMatcher(nil), scope...)
+			if expiresAt > 0 {
+				matchers = append(matchers, wafDirectiveMatcher{Variable: "TIME_EPOCH", Operator: "@lt", Value: fmt.Sprintf("%d", expiresAt)})
+			}
+			matchers = append(matchers, wafDirectiveMatcher{Variable: "REQUEST_HEADERS:" + wafClientIPHeader, Operator: "@ipMatch", Value: strings.Join(groups[expiresAt], ",")})
+
+			var headActions, tailActions []string
+			switch list.Action {
+			case wafIPListActionAllow:
+				headActions = []string{fmt.Sprintf("id:%d", ruleID), "phase:1", "pass", "nolog", "t:none"}
+				tailActions = []string{"ctl:ruleEngine=Off"}
+			default:
+				headActions = []string{fmt.Sprintf("id:%d", ruleID), "phase:1", "deny", "status:403", "log", "t:none", fmt.Sprintf("msg:'LogFlux IP deny list #%d'", list.ID)}
+			}
+			lines = append(lines, renderWafChainedRule(matchers, headActions, tailActions)...)
+			ruleID++
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+type wafDirectiveMatcher struct {
+	Variable string
+	Operator string
+	Value    string
+}
+
+func wafBindingScopeMatchers(binding *model.WafPolicyBinding) []wafDirectiveMatcher {
+	matchers := make([]wafDirectiveMatcher, 0, 3)
+	switch normalizePolicyScopeType(binding.ScopeType) {
+	case wafPolicyScopeTypeSite:
+		if host := normalizePolicyScopeHost(binding.Host); host != "" {
+			matchers = append(matchers, wafDirectiveMatcher{Variable: "REQUEST_HEADERS:Host", Operator: "@streq", Value: host})
+		}
+	case wafPolicyScopeTypeRoute:
+		if host := normalizePolicyScopeHost(binding.Host); host != "" {
+			matchers = append(matchers, wafDirectiveMatcher{Variable: "REQUEST_HEADERS:Host", Operator: "@streq", Value: host})
+		}
+		if path := normalizePolicyScopePath(binding.Path); path != "" {
+			matchers = append(matchers, wafDirectiveMatcher{Variable: "REQUEST_URI", Operator: "@beginsWith", Value: path})
+		}
+		if method := normalizePolicyHTTPMethod(binding.Method); method != "" {
+			matchers = append(matchers, wafDirectiveMatcher{Variable: "REQUEST_METHOD", Operator: "@streq", Value: method})
+		}
+	}
+	return matchers
+}
+
+// renderWafChainedRule 渲染链式规则：id/phase/阻断动作放在首条，tailActions 放在末条
+func renderWafChainedRule(matchers []wafDirectiveMatcher, headActions, tailActions []string) []string {
+	lines := make([]string, 0, len(matchers))
+	for idx, matcher := range matchers {
+		actions := []string{}
+		if idx == 0 {
+			actions = append(actions, headActions...)
+		}
+		if idx < len(matchers)-1 {
+			actions = append(actions, "chain")
+		} else {
+			actions = append(actions, tailActions...)
+		}
+		line := fmt.Sprintf(`SecRule %s "%s %s"`, matcher.Variable, matcher.Operator, strings.ReplaceAll(matcher.Value, `"`, `\"`))
+		if len(actions) > 0 {
+			line += fmt.Sprintf(` "%s"`, strings.Join(actions, ","))
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// buildWafGeoDenyRules 将拒绝名单中的国家/地区条目转换为 Caddy 匹配器（依赖 geoip2 模块提供的占位符）
+func buildWafGeoDenyRules(set *wafIPListRenderSet) ([]managedWafGeoRule, error) {
+	if set == nil {
+		return nil, nil
+	}
+	rules := make([]managedWafGeoRule, 0)
+	for _, list := range set.Lists {
+		if list.Action != wafIPListActionDeny {
+			continue
+		}
+		countries := make([]string, 0)
+		for _, entry := range set.Entries[list.ID] {
+			if entry.Kind == wafIPListEntryKindCountry {
+				countries = append(countries, fmt.Sprintf("'%s'", entry.Value))
+			}
+		}
+		if len(countries) == 0 {
+			continue
+		}
+
+		expression := fmt.Sprintf("{geoip2.country_code} in [%s]", strings.Join(countries, ", "))
+		if list.BindingID > 0 {
+			binding, ok := set.Bindings[list.BindingID]
+			if !ok || !binding.Enabled {
+				continue
+			}
+			scope, err := buildWafCanaryExpression([]model.WafPolicyBinding{binding})
+			if err != nil {
+				return nil, err
+			}
+			if scope != "true" {
+				expression += " && (" + scope + ")"
+			}
+		}
+		rules = append(rules, managedWafGeoRule{
+			Name:       fmt.Sprintf("waf_geo_deny_%d", list.ID),
+			Expression: expression,
+		})
+	}
+	return rules, nil
+}
+
+// loadWafGeoDenyRules 读取策略的国家/地区拒绝规则，无国家条目时仅一次查询
+func loadWafGeoDenyRules(db *gorm.DB, policyID uint) ([]managedWafGeoRule, error) {
+	if db == nil || policyID == 0 {
+		return nil, nil
+	}
+	set, err := loadWafIPListRenderSet(db, policyID, wafIPListEntryKindCountry, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return buildWafGeoDenyRules(set)
+}
+
+func toWafIPListEntryItem(entry *model.WafIPListEntry, now time.Time) types.WafIPListEntryItem {
+	item := types.WafIPListEntryItem{
+		ID:        entry.ID,
+		ListId:    entry.ListID,
+		Kind:      entry.Kind,
+		Value:     entry.Value,
+		Note:      entry.Note,
+		Source:    entry.Source,
+		SourceRef: entry.SourceRef,
+		CreatedAt: formatTime(entry.CreatedAt),
+		UpdatedAt: formatTime(entry.UpdatedAt),
+	}
+	if entry.ExpiresAt != nil {
+		item.ExpiresAt = formatTime(*entry.ExpiresAt)
+		item.Expired = !entry.ExpiresAt.After(now)
+	}
+	return item
+}
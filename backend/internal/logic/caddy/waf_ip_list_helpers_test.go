@@ -0,0 +1,127 @@
+package caddy
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"logflux/internal/waf"
+	"logflux/model"
+)
+
+func TestNormalizeWafIPListEntryValue(t *testing.T) {
+	cases := []struct {
+		kind, value, wantKind, wantValue string
+	}{
+		{"", " 10.0.0.1 ", wafIPListEntryKindIP, "10.0.0.1"},
+		{"ip", "192.168.1.77/24", wafIPListEntryKindIP, "192.168.1.0/24"},
+		{"IP", "2001:db8::1", wafIPListEntryKindIP, "2001:db8::1"},
+		{"country", "cn", wafIPListEntryKindCountry, "CN"},
+	}
+	for _, tc := range cases {
+		kind, value, err := normalizeWafIPListEntryValue(tc.kind, tc.value)
+		if err != nil {
+			t.Fatalf("normalizeWafIPListEntryValue(%q, %q) error = %v", tc.kind, tc.value, err)
+		}
+		if kind != tc.wantKind || value != tc.wantValue {
+			t.Fatalf("normalizeWafIPListEntryValue(%q, %q) = %q, %q", tc.kind, tc.value, kind, value)
+		}
+	}
+
+	for _, invalid := range [][2]string{{"ip", "10.0.0"}, {"ip", "10.0.0.0/33"}, {"country", "CHN"}, {"asn", "13335"}, {"ip", " "}} {
+		if _, _, err := normalizeWafIPListEntryValue(invalid[0], invalid[1]); err == nil {
+			t.Fatalf("expected %v to be rejected", invalid)
+		}
+	}
+}
+
+func TestBuildWafIPListDirectives(t *testing.T) {
+	expiresAt := time.Unix(1900000000, 0)
+	set := &wafIPListRenderSet{
+		Lists: []model.WafIPList{
+			{ID: 1, PolicyID: 1, Action: wafIPListActionDeny, Enabled: true},
+			{ID: 2, PolicyID: 1, BindingID: 5, Action: wafIPListActionAllow, Enabled: true},
+		},
+		Entries: map[uint][]model.WafIPListEntry{
+			1: {
+				{ListID: 1, Kind: wafIPListEntryKindIP, Value: "10.0.0.1"},
+				{ListID: 1, Kind: wafIPListEntryKindIP, Value: "10.1.0.0/16"},
+				{ListID: 1, Kind: wafIPListEntryKindIP, Value: "10.2.0.1", ExpiresAt: &expiresAt},
+				{ListID: 1, Kind: wafIPListEntryKindCountry, Value: "CN"},
+			},
+			2: {{ListID: 2, Kind: wafIPListEntryKindIP, Value: "192.168.0.0/24"}},
+		},
+		Bindings: map[uint]model.WafPolicyBinding{
+			5: {ID: 5, PolicyID: 1, ScopeType: wafPolicyScopeTypeSite, Host: "admin.example.com", Enabled: true},
+		},
+	}
+
+	directives, err := buildWafIPListDirectives(set)
+	if err != nil {
+		t.Fatalf("buildWafIPListDirectives() error = %v", err)
+	}
+	lines := strings.Split(directives, "\n")
+	expected := []string{
+		`SecRule REQUEST_HEADERS:Host "@streq admin.example.com" "id:9000,phase:1,pass,nolog,t:none,chain"`,
+		`SecRule REQUEST_HEADERS:X-Logflux-Client-Ip "@ipMatch 192.168.0.0/24" "ctl:ruleEngine=Off"`,
+		`SecRule REQUEST_HEADERS:X-Logflux-Client-Ip "@ipMatch 10.0.0.1,10.1.0.0/16" "id:9001,phase:1,deny,status:403,log,t:none,msg:'LogFlux IP deny list #1'"`,
+		`SecRule TIME_EPOCH "@lt 1900000000" "id:9002,phase:1,deny,status:403,log,t:none,msg:'LogFlux IP deny list #1',chain"`,
+		`SecRule REQUEST_HEADERS:X-Logflux-Client-Ip "@ipMatch 10.2.0.1"`,
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("unexpected directives:\n%s", directives)
+	}
+	for idx := range expected {
+		if lines[idx] != expected[idx] {
+			t.Fatalf("line %d:\n got %s\nwant %s", idx, lines[idx], expected[idx])
+		}
+	}
+	if strings.Contains(directives, "CN") {
+		t.Fatalf("country entries should not be rendered as SecLang:\n%s", directives)
+	}
+	if err := waf.ValidateSecLang(directives); err != nil {
+		t.Fatalf("ip list directives should be valid SecLang: %v", err)
+	}
+}
+
+func TestBuildWafGeoDenyRules(t *testing.T) {
+	set := &wafIPListRenderSet{
+		Lists: []model.WafIPList{
+			{ID: 3, Action: wafIPListActionDeny, Enabled: true},
+			{ID: 4, BindingID: 6, Action: wafIPListActionDeny, Enabled: true},
+		},
+		Entries: map[uint][]model.WafIPListEntry{
+			3: {{Kind: wafIPListEntryKindCountry, Value: "CN"}, {Kind: wafIPListEntryKindCountry, Value: "RU"}},
+			4: {{Kind: wafIPListEntryKindCountry, Value: "KP"}},
+		},
+		Bindings: map[uint]model.WafPolicyBinding{
+			6: {ID: 6, ScopeType: wafPolicyScopeTypeRoute, Path: "/admin", Enabled: true},
+		},
+	}
+	rules, err := buildWafGeoDenyRules(set)
+	if err != nil {
+		t.Fatalf("buildWafGeoDenyRules() error = %v", err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("expected 2 geo rules, got %+v", rules)
+	}
+	if rules[0].Name != "waf_geo_deny_3" || rules[0].Expression != "{geoip2.country_code} in ['CN', 'RU']" {
+		t.Fatalf("unexpected global geo rule: %+v", rules[0])
+	}
+	if rules[1].Expression != "{geoip2.country_code} in ['KP'] && (path('/admin', '/admin/*'))" {
+		t.Fatalf("unexpected scoped geo rule: %+v", rules[1])
+	}
+
+	config, err := buildPolicyCandidateCaddyConfig("", "SecRuleEngine On", true, managedPolicyExtras{GeoDeny: rules})
+	if err != nil {
+		t.Fatalf("buildPolicyCandidateCaddyConfig() error = %v", err)
+	}
+	for _, want := range []string{"order geoip2_vars first", "geoip2_vars strict", "@waf_geo_deny_3 expression `{geoip2.country_code} in ['CN', 'RU']`", "respond @waf_geo_deny_3 403"} {
+		if !strings.Contains(config, want) {
+			t.Fatalf("expected %q in config:\n%s", want, config)
+		}
+	}
+	if _, err := buildPolicyCandidateCaddyConfig(":8080 {\n  respond \"ok\"\n}\n", "SecRuleEngine On", true, managedPolicyExtras{GeoDeny: rules}); err == nil {
+		t.Fatalf("expected unmanaged Caddyfile with geo rules to be rejected")
+	}
+}
@@ -29,6 +29,7 @@ const (
 
 	wafModeRemote = "remote"
 	wafModeManual = "manual"
+	wafModeMirror = "mirror"
 
 	wafAuthNone  = "none"
 	wafAuthToken = "token"
@@ -49,6 +50,7 @@ const (
 	wafSourceBoolMaskCtxKey = "waf_source_bool_mask"
 	wafPolicyBoolMaskCtxKey = "waf_policy_bool_mask"
 	wafJobTriggerModeCtxKey = "waf_job_trigger_mode"
+	wafMirrorClientIPCtxKey = "waf_mirror_client_ip"
 )
 
 type wafLogicHelper struct {
@@ -117,13 +119,23 @@ func normalizeWafMode(mode string) string {
 
 func validateWafMode(mode string) error {
 	switch normalizeWafMode(mode) {
-	case wafModeRemote, wafModeManual:
+	case wafModeRemote, wafModeManual, wafModeMirror:
 		return nil
 	default:
 		return fmt.Errorf("模式无效: %s", mode)
 	}
 }
 
+// isWafRemoteMode 远程与镜像模式都需要从 URL 拉取，共用 URL、代理与认证校验
+func isWafRemoteMode(mode string) bool {
+	switch normalizeWafMode(mode) {
+	case wafModeRemote, wafModeMirror:
+		return true
+	default:
+		return false
+	}
+}
+
 func normalizeWafAuthType(authType string) string {
 	normalized := strings.ToLower(strings.TrimSpace(authType))
 	if normalized == "" {
@@ -141,6 +153,35 @@ func validateWafAuthType(authType string) error {
 	}
 }
 
+// normalizeWafSourceTrust 规范化源的信任配置，启用签名校验时要求公钥可解析
+func normalizeWafSourceTrust(source *model.WafSource) error {
+	signatureType, err := waf.NormalizeSignatureType(source.SignatureType)
+	if err != nil {
+		return err
+	}
+	source.SignatureType = signatureType
+	source.SignatureURL = strings.TrimSpace(source.SignatureURL)
+	source.PublicKeys = strings.TrimSpace(source.PublicKeys)
+	return waf.ValidateTrustedKeys(signatureType, source.PublicKeys)
+}
+
+// wafReleaseSignatureMeta 将签名校验结果写入版本元数据，未校验签名时返回空
+func wafReleaseSignatureMeta(signature *waf.SignatureResult) model.JSONMap {
+	if signature == nil {
+		return nil
+	}
+	meta := model.JSONMap{
+		"signatureType":       signature.Type,
+		"signatureKeyId":      signature.KeyID,
+		"signer":              signature.Signer,
+		"signatureVerifiedAt": formatTime(time.Now()),
+	}
+	if signature.Comment != "" {
+		meta["signatureComment"] = signature.Comment
+	}
+	return meta
+}
+
 func parseMetaJSON(raw string) (model.JSONMap, error) {
 	trimmed := strings.TrimSpace(raw)
 	if trimmed == "" {
@@ -168,6 +209,13 @@ func formatNullableTime(value *time.Time) string {
 	return formatTime(*value)
 }
 
+func derefUint(value *uint) uint {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
+
 func (helper *wafLogicHelper) startJob(sourceID, releaseID uint, action, triggerMode string) *model.WafUpdateJob {
 	now := time.Now()
 	job := &model.WafUpdateJob{
@@ -37,6 +37,9 @@ func buildWafPolicyRevisionChangeSummary(current *model.WafPolicyRevision, previ
 		if !reflect.DeepEqual(previous.ConfigSnapshot, current.ConfigSnapshot) {
 			changeParts = append(changeParts, "扩展配置")
 		}
+		if strings.TrimSpace(previous.CustomRulesSnapshot) != strings.TrimSpace(current.CustomRulesSnapshot) {
+			changeParts = append(changeParts, "自定义规则")
+		}
 		if strings.TrimSpace(previous.Status) != strings.TrimSpace(current.Status) {
 			changeParts = append(changeParts, "版本状态")
 		}
@@ -70,6 +73,14 @@ func summarizeWafPolicyRevisionMessage(message string) string {
 		return "发布策略"
 	case "rollback policy":
 		return "回滚策略"
+	case "start canary rollout":
+		return "开始灰度发布"
+	case "promote canary rollout":
+		return "灰度转正"
+	case "auto promote canary rollout":
+		return "灰度自动转正"
+	case "abort canary rollout":
+		return "终止灰度发布"
 	case "init default policy":
 		return "初始化默认策略"
 	default:
@@ -222,7 +222,8 @@ func ensurePolicyCRSTuning(policy *model.WafPolicy) error {
 	return nil
 }
 
-func buildWafPolicyDirectives(policy *model.WafPolicy) (string, error) {
+// buildWafPolicyDirectives 生成策略基础指令，customRules 中已启用的自定义规则按顺序追加在末尾
+func buildWafPolicyDirectives(policy *model.WafPolicy, customRules ...model.WafCustomRule) (string, error) {
 	if policy == nil {
 		return "", fmt.Errorf("策略为空")
 	}
@@ -282,6 +283,10 @@ func buildWafPolicyDirectives(policy *model.WafPolicy) (string, error) {
 		fmt.Sprintf(`SecAction "id:900100,phase:1,pass,nolog,t:none,setvar:tx.outbound_anomaly_score_threshold=%d"`, policy.CrsOutboundAnomalyThreshold),
 	)
 
+	if customDirectives := buildWafCustomRuleDirectives(customRules); customDirectives != "" {
+		lines = append(lines, customDirectives)
+	}
+
 	return strings.Join(lines, "\n"), nil
 }
 
@@ -317,9 +322,9 @@ func applyWafPolicyToCaddyConfig(caddyConfig, directives string) (string, error)
 	indent := leadingWhitespace(directiveLine)
 	innerIndent := indent + "  "
 
-	lines := strings.Split(rawDirectives, "\n")
+	lines := composeUnmanagedCorazaDirectives(caddyConfig[startTickIndex+1:endTickIndex], rawDirectives)
 	for i := range lines {
-		lines[i] = innerIndent + strings.TrimSpace(lines[i])
+		lines[i] = innerIndent + lines[i]
 	}
 	rendered := strings.Join(lines, "\n")
 
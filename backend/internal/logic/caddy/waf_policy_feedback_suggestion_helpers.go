@@ -0,0 +1,326 @@
+package caddy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"logflux/internal/types"
+	"logflux/model"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// 关联审计事件的时间窗口：反馈创建前 7 天至创建后 1 天
+	wafFeedbackSuggestionLookback  = 7 * 24 * time.Hour
+	wafFeedbackSuggestionLookahead = 24 * time.Hour
+	wafFeedbackSuggestionMaxEvents = 500
+	// 返回给前端的候选规则数
+	wafFeedbackSuggestionMaxRuleHits = 10
+)
+
+type wafFeedbackSuggestion struct {
+	Exclusion  model.WafRuleExclusion
+	RuleHits   []types.WafPolicyFalsePositiveFeedbackRuleHit
+	EventCount int64
+	Rationale  string
+}
+
+// queryWafFeedbackAuditEvents 按反馈的策略、host、path、method、状态码与时间窗口关联审计事件
+func queryWafFeedbackAuditEvents(db *gorm.DB, feedback *model.WafPolicyFalsePositiveFeedback) ([]model.WafAuditLog, error) {
+	if db == nil || feedback == nil {
+		return nil, fmt.Errorf("误报反馈上下文无效")
+	}
+
+	anchor := feedback.CreatedAt
+	if anchor.IsZero() {
+		anchor = time.Now()
+	}
+	query := db.Model(&model.WafAuditLog{}).
+		Where("log_time BETWEEN ? AND ?", anchor.Add(-wafFeedbackSuggestionLookback), anchor.Add(wafFeedbackSuggestionLookahead)).
+		Where("cardinality(rule_ids) > 0 AND rollout_id = 0")
+	if feedback.PolicyID > 0 {
+		query = query.Where("policy_id = ?", feedback.PolicyID)
+	}
+	if host := normalizePolicyScopeHost(feedback.Host); host != "" {
+		query = query.Where("lower(split_part(host, ':', 1)) = ?", stripWafFeedbackHostPort(host))
+	}
+	if path := wafFeedbackScopePath(feedback); path != "" {
+		query = query.Where("starts_with(uri, ?)", path)
+	}
+	if method := normalizePolicyHTTPMethod(feedback.Method); method != "" {
+		query = query.Where("method = ?", method)
+	}
+	if feedback.Status > 0 {
+		query = query.Where("status = ?", feedback.Status)
+	}
+
+	var events []model.WafAuditLog
+	if err := query.Order("log_time desc").Limit(wafFeedbackSuggestionMaxEvents).Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("查询误报关联审计事件失败: %w", err)
+	}
+	return events, nil
+}
+
+// suggestWafFeedbackExclusion 关联审计事件并生成候选排除规则
+func suggestWafFeedbackExclusion(db *gorm.DB, feedback *model.WafPolicyFalsePositiveFeedback) (*wafFeedbackSuggestion, error) {
+	events, err := queryWafFeedbackAuditEvents(db, feedback)
+	if err != nil {
+		return nil, err
+	}
+	suggestion, err := buildWafFeedbackSuggestion(feedback, events)
+	if err != nil {
+		return nil, err
+	}
+	if suggestion.Exclusion.PolicyID == 0 {
+		var policy model.WafPolicy
+		if err := db.Where("is_default = ?", true).Order("id asc").First(&policy).Error; err != nil {
+			return nil, fmt.Errorf("无法确定排除规则所属策略，请在反馈中指定策略")
+		}
+		suggestion.Exclusion.PolicyID = policy.ID
+	}
+	return suggestion, nil
+}
+
+// buildWafFeedbackSuggestion 从关联的审计事件中推导最窄的排除规则：
+// 命中最多的规则若始终只检查同一个参数，则只移除该检查目标，否则在反馈作用域内移除整条规则
+func buildWafFeedbackSuggestion(feedback *model.WafPolicyFalsePositiveFeedback, events []model.WafAuditLog) (*wafFeedbackSuggestion, error) {
+	if feedback == nil {
+		return nil, fmt.Errorf("误报反馈上下文无效")
+	}
+	if len(events) == 0 {
+		return nil, fmt.Errorf("未找到与该误报反馈匹配的 WAF 审计事件")
+	}
+
+	type ruleAggregate struct {
+		ruleID        int64
+		message       string
+		hitCount      int64
+		variableHits  int64
+		variables     map[string]struct{}
+		variableOrder []string
+	}
+	aggregates := map[int64]*ruleAggregate{}
+	policyHits := map[uint]int64{}
+	hosts := map[string]struct{}{}
+	paths := map[string]struct{}{}
+	methods := map[string]struct{}{}
+
+	for _, event := range events {
+		policyHits[event.PolicyID]++
+		hosts[stripWafFeedbackHostPort(normalizePolicyScopeHost(event.Host))] = struct{}{}
+		paths[wafFeedbackURIPath(event.Uri)] = struct{}{}
+		methods[normalizePolicyHTTPMethod(event.Method)] = struct{}{}
+
+		var messages []model.WafAuditMessage
+		if strings.TrimSpace(event.Messages) != "" {
+			_ = json.Unmarshal([]byte(event.Messages), &messages)
+		}
+
+		seen := map[int64]bool{}
+		for _, ruleID := range event.RuleIDs {
+			if isWafEvaluationRule(ruleID) || seen[ruleID] {
+				continue
+			}
+			seen[ruleID] = true
+
+			aggregate := aggregates[ruleID]
+			if aggregate == nil {
+				aggregate = &ruleAggregate{ruleID: ruleID, variables: map[string]struct{}{}}
+				aggregates[ruleID] = aggregate
+			}
+			aggregate.hitCount++
+
+			hasVariable := false
+			for _, message := range messages {
+				if message.RuleID != ruleID {
+					continue
+				}
+				if aggregate.message == "" {
+					aggregate.message = message.Message
+				}
+				variable := strings.TrimSpace(message.Variable)
+				if variable == "" {
+					continue
+				}
+				hasVariable = true
+				if _, ok := aggregate.variables[variable]; !ok {
+					aggregate.variables[variable] = struct{}{}
+					aggregate.variableOrder = append(aggregate.variableOrder, variable)
+				}
+			}
+			if hasVariable {
+				aggregate.variableHits++
+			}
+		}
+	}
+
+	if len(aggregates) == 0 {
+		return nil, fmt.Errorf("关联的审计事件中没有可排除的规则")
+	}
+
+	ranked := make([]*ruleAggregate, 0, len(aggregates))
+	for _, aggregate := range aggregates {
+		ranked = append(ranked, aggregate)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].hitCount != ranked[j].hitCount {
+			return ranked[i].hitCount > ranked[j].hitCount
+		}
+		return ranked[i].ruleID < ranked[j].ruleID
+	})
+
+	ruleHits := make([]types.WafPolicyFalsePositiveFeedbackRuleHit, 0, min(len(ranked), wafFeedbackSuggestionMaxRuleHits))
+	for _, aggregate := range ranked {
+		if len(ruleHits) >= wafFeedbackSuggestionMaxRuleHits {
+			break
+		}
+		variables := aggregate.variableOrder
+		if variables == nil {
+			variables = []string{}
+		}
+		ruleHits = append(ruleHits, types.WafPolicyFalsePositiveFeedbackRuleHit{
+			RuleId:    aggregate.ruleID,
+			Message:   aggregate.message,
+			HitCount:  aggregate.hitCount,
+			Variables: variables,
+		})
+	}
+
+	policyID := feedback.PolicyID
+	if policyID == 0 {
+		var bestHits int64
+		for candidateID, hits := range policyHits {
+			if candidateID == 0 {
+				continue
+			}
+			if hits > bestHits || (hits == bestHits && candidateID < policyID) {
+				policyID, bestHits = candidateID, hits
+			}
+		}
+	}
+
+	host := stripWafFeedbackHostPort(normalizePolicyScopeHost(feedback.Host))
+	if host == "" {
+		host = singleWafFeedbackValue(hosts)
+	}
+	path := wafFeedbackScopePath(feedback)
+	if path == "" {
+		path = singleWafFeedbackValue(paths)
+	}
+	method := normalizePolicyHTTPMethod(feedback.Method)
+	if method == "" {
+		method = singleWafFeedbackValue(methods)
+	}
+
+	scopeType := wafPolicyScopeTypeGlobal
+	switch {
+	case path != "":
+		scopeType = wafPolicyScopeTypeRoute
+	case host != "":
+		scopeType = wafPolicyScopeTypeSite
+	}
+	scopeType, host, path, method, err := normalizeAndValidateExclusionScopeFields(scopeType, host, path, method)
+	if err != nil {
+		return nil, err
+	}
+
+	top := ranked[0]
+	exclusion := model.WafRuleExclusion{
+		PolicyID:    policyID,
+		Name:        fmt.Sprintf("误报反馈 #%d", feedback.ID),
+		Description: truncateWafFeedbackText(feedback.Reason, 255),
+		Enabled:     true,
+		ScopeType:   scopeType,
+		Host:        host,
+		Path:        path,
+		Method:      method,
+		RemoveType:  wafPolicyRemoveTypeID,
+		RemoveValue: fmt.Sprintf("%d", top.ruleID),
+	}
+
+	rationale := fmt.Sprintf("关联 %d 条审计事件，规则 %d 命中 %d 次", len(events), top.ruleID, top.hitCount)
+	target := ""
+	if len(top.variableOrder) == 1 && top.variableHits == top.hitCount {
+		target, _ = normalizeWafExclusionTarget(top.variableOrder[0])
+	}
+	if target != "" {
+		exclusion.RemoveType = wafPolicyRemoveTypeTargetID
+		exclusion.RemoveTarget = target
+		rationale += fmt.Sprintf("，且每次均只检查 %s，建议仅对该目标移除规则", target)
+	} else {
+		rationale += "，检查目标不唯一或不支持按目标移除，建议在作用域内移除该规则"
+	}
+	if len(ranked) > 1 {
+		rationale += fmt.Sprintf("；另有 %d 条规则同时命中，可按需追加排除", len(ranked)-1)
+	}
+
+	return &wafFeedbackSuggestion{
+		Exclusion:  exclusion,
+		RuleHits:   ruleHits,
+		EventCount: int64(len(events)),
+		Rationale:  rationale,
+	}, nil
+}
+
+// CRS 评分汇总类规则（949/959 阈值判定、980 报告）不作为排除对象
+func isWafEvaluationRule(ruleID int64) bool {
+	switch ruleID / 1000 {
+	case 949, 959, 980:
+		return true
+	default:
+		return false
+	}
+}
+
+// wafFeedbackScopePath 优先使用反馈填写的 path，否则取样本 URI 的路径部分
+func wafFeedbackScopePath(feedback *model.WafPolicyFalsePositiveFeedback) string {
+	if path := normalizePolicyScopePath(feedback.Path); path != "" {
+		return path
+	}
+	sample := strings.TrimSpace(feedback.SampleURI)
+	if sample == "" {
+		return ""
+	}
+	if parsed, err := url.Parse(sample); err == nil && parsed.Path != "" {
+		return normalizePolicyScopePath(parsed.Path)
+	}
+	return wafFeedbackURIPath(sample)
+}
+
+func wafFeedbackURIPath(uri string) string {
+	path, _, _ := strings.Cut(strings.TrimSpace(uri), "?")
+	return normalizePolicyScopePath(path)
+}
+
+func stripWafFeedbackHostPort(host string) string {
+	if strings.HasPrefix(host, "[") {
+		return host
+	}
+	name, _, _ := strings.Cut(host, ":")
+	return name
+}
+
+// singleWafFeedbackValue 仅当所有事件取值一致时返回该值
+func singleWafFeedbackValue(values map[string]struct{}) string {
+	if len(values) != 1 {
+		return ""
+	}
+	for value := range values {
+		return value
+	}
+	return ""
+}
+
+func truncateWafFeedbackText(text string, limit int) string {
+	trimmed := strings.TrimSpace(text)
+	runes := []rune(trimmed)
+	if len(runes) <= limit {
+		return trimmed
+	}
+	return string(runes[:limit])
+}
@@ -0,0 +1,89 @@
+package caddy
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"logflux/model"
+)
+
+func TestBuildWafFeedbackSuggestionSingleTarget(t *testing.T) {
+	feedback := &model.WafPolicyFalsePositiveFeedback{
+		ID:        7,
+		PolicyID:  3,
+		Host:      "Blog.example.com",
+		SampleURI: "/api/posts?draft=1",
+		Reason:    "富文本内容被误判为 SQL 注入",
+		CreatedAt: time.Now(),
+	}
+	events := []model.WafAuditLog{
+		{
+			PolicyID: 3, Host: "blog.example.com:443", Uri: "/api/posts", Method: "POST",
+			RuleIDs:  model.Int64Array{942100, 949110},
+			Messages: `[{"ruleId":942100,"message":"SQL Injection Attack Detected via libinjection","variable":"ARGS:content"},{"ruleId":949110,"message":"Inbound Anomaly Score Exceeded"}]`,
+		},
+		{
+			PolicyID: 3, Host: "blog.example.com", Uri: "/api/posts?id=2", Method: "POST",
+			RuleIDs:  model.Int64Array{942100, 941100},
+			Messages: `[{"ruleId":942100,"message":"SQL Injection Attack Detected via libinjection","variable":"ARGS:content"},{"ruleId":941100,"message":"XSS","variable":"ARGS:title"}]`,
+		},
+	}
+
+	suggestion, err := buildWafFeedbackSuggestion(feedback, events)
+	if err != nil {
+		t.Fatalf("buildWafFeedbackSuggestion() error = %v", err)
+	}
+
+	exclusion := suggestion.Exclusion
+	if exclusion.PolicyID != 3 || exclusion.ScopeType != "route" || exclusion.Host != "blog.example.com" || exclusion.Path != "/api/posts" || exclusion.Method != "POST" {
+		t.Fatalf("unexpected scope: %+v", exclusion)
+	}
+	if exclusion.RemoveType != "target_id" || exclusion.RemoveValue != "942100" || exclusion.RemoveTarget != "ARGS:content" {
+		t.Fatalf("unexpected remove fields: %+v", exclusion)
+	}
+	if suggestion.EventCount != 2 || len(suggestion.RuleHits) != 2 || suggestion.RuleHits[0].RuleId != 942100 || suggestion.RuleHits[0].HitCount != 2 {
+		t.Fatalf("unexpected rule hits: %+v", suggestion.RuleHits)
+	}
+
+	directives, err := buildWafRuleExclusionDirectives([]model.WafRuleExclusion{exclusion})
+	if err != nil {
+		t.Fatalf("buildWafRuleExclusionDirectives() error = %v", err)
+	}
+	if !strings.Contains(directives, "ctl:ruleRemoveTargetById=942100;ARGS:content") {
+		t.Fatalf("expected target removal directive, got: %s", directives)
+	}
+}
+
+func TestBuildWafFeedbackSuggestionFallsBackToRuleID(t *testing.T) {
+	feedback := &model.WafPolicyFalsePositiveFeedback{ID: 8, Host: "shop.example.com"}
+	events := []model.WafAuditLog{
+		{
+			PolicyID: 5, Host: "shop.example.com", Uri: "/search?q=a", Method: "GET",
+			RuleIDs:  model.Int64Array{932100},
+			Messages: `[{"ruleId":932100,"variable":"ARGS:q"}]`,
+		},
+		{
+			PolicyID: 5, Host: "shop.example.com", Uri: "/cart", Method: "GET",
+			RuleIDs:  model.Int64Array{932100},
+			Messages: `[{"ruleId":932100,"variable":"REQUEST_COOKIES:session"}]`,
+		},
+	}
+
+	suggestion, err := buildWafFeedbackSuggestion(feedback, events)
+	if err != nil {
+		t.Fatalf("buildWafFeedbackSuggestion() error = %v", err)
+	}
+
+	exclusion := suggestion.Exclusion
+	if exclusion.PolicyID != 5 || exclusion.ScopeType != "site" || exclusion.Host != "shop.example.com" || exclusion.Path != "" {
+		t.Fatalf("unexpected scope: %+v", exclusion)
+	}
+	if exclusion.RemoveType != "id" || exclusion.RemoveValue != "932100" || exclusion.RemoveTarget != "" {
+		t.Fatalf("unexpected remove fields: %+v", exclusion)
+	}
+
+	if _, err := buildWafFeedbackSuggestion(feedback, nil); err == nil {
+		t.Fatalf("expected error when no audit events matched")
+	}
+}
@@ -25,7 +25,14 @@ func TestPreviewWafPolicySuccess(t *testing.T) {
 
 	now := time.Now()
 	mock.ExpectQuery(`SELECT .* FROM "waf_policies"`).WillReturnRows(policyRows(now))
+	mock.ExpectQuery(`SELECT .* FROM "waf_custom_rules"`).WillReturnRows(policyCustomRuleRows().AddRow(
+		uint(3), now, now,
+		uint(1), "block wp-admin", "", true,
+		int64(10001), int64(1), int64(100),
+		`SecRule REQUEST_URI "@beginsWith /wp-admin" "id:10001,phase:1,deny,status:403"`,
+	))
 	mock.ExpectQuery(`SELECT .* FROM "waf_rule_exclusions"`).WillReturnRows(policyExclusionRows())
+	mock.ExpectQuery(`SELECT .* FROM "waf_ip_lists"`).WillReturnRows(policyIPListRows())
 
 	logic := NewPreviewWafPolicyLogic(context.Background(), &svc.ServiceContext{DB: gdb})
 	resp, err := logic.PreviewWafPolicy(&types.WafPolicyActionReq{ID: 1})
@@ -35,7 +42,7 @@ func TestPreviewWafPolicySuccess(t *testing.T) {
 	if resp == nil || strings.TrimSpace(resp.Directives) == "" {
 		t.Fatalf("expected non-empty directives")
 	}
-	if !strings.Contains(resp.Directives, "SecRuleEngine On") {
+	if !strings.Contains(resp.Directives, "SecRuleEngine On") || !strings.Contains(resp.Directives, "id:10001,phase:1") {
 		t.Fatalf("unexpected directives content: %s", resp.Directives)
 	}
 	if err := mock.ExpectationsWereMet(); err != nil {
@@ -59,7 +66,11 @@ func TestValidateWafPolicySuccess(t *testing.T) {
 
 	now := time.Now()
 	mock.ExpectQuery(`SELECT .* FROM "waf_policies"`).WillReturnRows(policyRows(now))
+	mock.ExpectQuery(`SELECT .* FROM "waf_custom_rules"`).WillReturnRows(policyCustomRuleRows())
 	mock.ExpectQuery(`SELECT .* FROM "waf_rule_exclusions"`).WillReturnRows(policyExclusionRows())
+	mock.ExpectQuery(`SELECT .* FROM "waf_ip_lists"`).WillReturnRows(policyIPListRows())
+	mock.ExpectQuery(`SELECT .* FROM "waf_ip_lists"`).WillReturnRows(policyIPListRows())
+	mock.ExpectQuery(`SELECT .* FROM "waf_rate_limit_zones"`).WillReturnRows(policyRateLimitZoneRows())
 	mock.ExpectQuery(`SELECT .* FROM "caddy_servers"`).WillReturnRows(caddyServerRows(now, caddyMock.URL, testCaddyConfigWithCoraza))
 
 	logic := NewValidateWafPolicyLogic(context.Background(), &svc.ServiceContext{DB: gdb})
@@ -97,7 +108,12 @@ func TestPublishWafPolicyValidateFailedLocalized(t *testing.T) {
 	now := time.Now()
 	mock.ExpectQuery(`SELECT .* FROM "waf_policies"`).WillReturnRows(policyRows(now))
 	mock.ExpectQuery(`SELECT .* FROM "waf_policy_bindings"`).WillReturnRows(policyBindingConflictRows())
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "waf_policy_rollouts"`).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`SELECT .* FROM "waf_custom_rules"`).WillReturnRows(policyCustomRuleRows())
 	mock.ExpectQuery(`SELECT .* FROM "waf_rule_exclusions"`).WillReturnRows(policyExclusionRows())
+	mock.ExpectQuery(`SELECT .* FROM "waf_ip_lists"`).WillReturnRows(policyIPListRows())
+	mock.ExpectQuery(`SELECT .* FROM "waf_ip_lists"`).WillReturnRows(policyIPListRows())
+	mock.ExpectQuery(`SELECT .* FROM "waf_rate_limit_zones"`).WillReturnRows(policyRateLimitZoneRows())
 	mock.ExpectQuery(`SELECT .* FROM "caddy_servers"`).WillReturnRows(caddyServerRows(now, caddyMock.URL, testCaddyConfigWithCoraza))
 
 	logic := NewPublishWafPolicyLogic(context.Background(), &svc.ServiceContext{DB: gdb})
@@ -129,6 +145,9 @@ func TestRollbackWafPolicyValidateFailedLocalized(t *testing.T) {
 	now := time.Now()
 	mock.ExpectQuery(`SELECT .* FROM "waf_policy_revisions"`).WillReturnRows(policyRevisionRows(now))
 	mock.ExpectQuery(`SELECT .* FROM "waf_policies"`).WillReturnRows(policyRows(now))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "waf_policy_rollouts"`).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`SELECT .* FROM "waf_ip_lists"`).WillReturnRows(policyIPListRows())
+	mock.ExpectQuery(`SELECT .* FROM "waf_rate_limit_zones"`).WillReturnRows(policyRateLimitZoneRows())
 	mock.ExpectQuery(`SELECT .* FROM "caddy_servers"`).WillReturnRows(caddyServerRows(now, caddyMock.URL, testCaddyConfigWithCoraza))
 
 	logic := NewRollbackWafPolicyLogic(context.Background(), &svc.ServiceContext{DB: gdb})
@@ -218,6 +237,30 @@ func policyExclusionRows() *sqlmock.Rows {
 	})
 }
 
+func policyRateLimitZoneRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{
+		"id", "created_at", "updated_at",
+		"policy_id", "binding_id", "name", "description", "enabled",
+		"key_type", "key_header", "events", "window_seconds", "burst",
+	})
+}
+
+func policyIPListRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{
+		"id", "created_at", "updated_at",
+		"policy_id", "binding_id", "name", "description", "action", "enabled",
+	})
+}
+
+func policyCustomRuleRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{
+		"id", "created_at", "updated_at",
+		"policy_id", "name", "description", "enabled",
+		"rule_id", "phase", "priority",
+		"directive",
+	})
+}
+
 func policyBindingConflictRows() *sqlmock.Rows {
 	return sqlmock.NewRows([]string{
 		"scope_type", "host", "path", "method", "priority", "count",
@@ -300,14 +300,24 @@ func createPolicyRevision(tx *gorm.DB, policy *model.WafPolicy, status, directiv
 		return nil, fmt.Errorf("查询最新策略版本失败: %w", err)
 	}
 
+	customRules, err := loadEnabledWafCustomRules(tx, policy.ID)
+	if err != nil {
+		return nil, err
+	}
+	customRulesSnapshot, err := buildWafCustomRulesSnapshot(customRules)
+	if err != nil {
+		return nil, err
+	}
+
 	revision := &model.WafPolicyRevision{
-		PolicyID:           policy.ID,
-		Version:            nextVersion,
-		Status:             strings.TrimSpace(status),
-		ConfigSnapshot:     policy.Config,
-		DirectivesSnapshot: strings.TrimSpace(directives),
-		Operator:           strings.TrimSpace(operator),
-		Message:            strings.TrimSpace(message),
+		PolicyID:            policy.ID,
+		Version:             nextVersion,
+		Status:              strings.TrimSpace(status),
+		ConfigSnapshot:      policy.Config,
+		DirectivesSnapshot:  strings.TrimSpace(directives),
+		CustomRulesSnapshot: customRulesSnapshot,
+		Operator:            strings.TrimSpace(operator),
+		Message:             strings.TrimSpace(message),
 	}
 	if revision.Status == "" {
 		revision.Status = wafPolicyStatusDraft
@@ -380,18 +390,12 @@ func normalizeCaddyModulesJSON(modules string) string {
 	return trimmed
 }
 
-func createCaddyPolicyHistory(tx *gorm.DB, serverID uint, action, config, modules string) error {
+func createCaddyPolicyHistory(tx *gorm.DB, serverID uint, action, config, modules, operator string) error {
 	if tx == nil {
 		return fmt.Errorf("数据库为空")
 	}
 
-	history := &model.CaddyConfigHistory{
-		ServerID: serverID,
-		Action:   strings.TrimSpace(action),
-		Hash:     hashConfig(config),
-		Config:   config,
-		Modules:  normalizeCaddyModulesJSON(modules),
-	}
+	history := newCaddyConfigHistory(serverID, action, config, modules, caddyConfigHistoryNote{Operator: operator})
 	if err := tx.Create(history).Error; err != nil {
 		return fmt.Errorf("创建 Caddy 配置历史失败: %w", err)
 	}
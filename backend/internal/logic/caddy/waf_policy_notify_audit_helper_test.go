@@ -29,6 +29,9 @@ func (r *notifyRecorder) ReloadTemplates() error
 func (r *notifyRecorder) SendToChannel(context.Context, uint, *notification.Event) error {
 	return nil
 }
+func (r *notifyRecorder) SendToChannelWithTemplate(context.Context, uint, string, *notification.Event) error {
+	return nil
+}
 
 func TestWafPolicyNotifyAuditHelperNotifyFailure(t *testing.T) {
 	recorder := &notifyRecorder{events: make(chan *notification.Event, 1)}
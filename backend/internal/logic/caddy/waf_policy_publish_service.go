@@ -23,13 +23,63 @@ type PolicyPublishCandidate struct {
 type PolicyPublishService struct {
 	ctx    context.Context
 	svcCtx *svc.ServiceContext
+	// approved 仅由变更申请执行器设置，跳过变更审批检查
+	approved bool
 }
 
 func NewPolicyPublishService(ctx context.Context, svcCtx *svc.ServiceContext) *PolicyPublishService {
 	return &PolicyPublishService{ctx: ctx, svcCtx: svcCtx}
 }
 
+// approvedChange 返回已通过变更审批的副本，仅供变更申请执行器使用
+func (s *PolicyPublishService) approvedChange() *PolicyPublishService {
+	clone := *s
+	clone.approved = true
+	return &clone
+}
+
 func (s *PolicyPublishService) BuildPublishCandidate(policyID uint) (*PolicyPublishCandidate, error) {
+	plan, err := s.buildPublishPlan(policyID)
+	if err != nil {
+		return nil, err
+	}
+
+	server, err := findPrimaryCaddyServer(s.svcCtx.DB.WithContext(s.ctx))
+	if err != nil {
+		return nil, err
+	}
+	return plan.candidateFor(server)
+}
+
+// BuildPublishCandidates 为每个目标节点基于其自身配置生成发布候选，用于多节点分发
+func (s *PolicyPublishService) BuildPublishCandidates(policyID uint, servers []model.CaddyServer) ([]*PolicyPublishCandidate, error) {
+	plan, err := s.buildPublishPlan(policyID)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]*PolicyPublishCandidate, 0, len(servers))
+	for index := range servers {
+		server := &servers[index]
+		if strings.TrimSpace(server.Config) == "" {
+			return nil, fmt.Errorf("Caddy 服务器 %s 配置为空，请先保存 Caddy 配置", server.Name)
+		}
+		candidate, err := plan.candidateFor(server)
+		if err != nil {
+			return nil, fmt.Errorf("Caddy 服务器 %s: %w", server.Name, err)
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates, nil
+}
+
+type policyPublishPlan struct {
+	policy     *model.WafPolicy
+	directives string
+	extras     managedPolicyExtras
+}
+
+func (s *PolicyPublishService) buildPublishPlan(policyID uint) (*policyPublishPlan, error) {
 	if s == nil || s.svcCtx == nil || s.svcCtx.DB == nil {
 		return nil, fmt.Errorf("数据库为空")
 	}
@@ -45,25 +95,31 @@ func (s *PolicyPublishService) BuildPublishCandidate(policyID uint) (*PolicyPubl
 	if err := ensureNoPolicyBindingConflicts(s.svcCtx.DB.WithContext(s.ctx)); err != nil {
 		return nil, err
 	}
+	if err := ensureNoActiveWafRollout(s.svcCtx.DB.WithContext(s.ctx)); err != nil {
+		return nil, err
+	}
 
 	directives, err := buildPolicyDirectivesWithExclusions(s.svcCtx.DB.WithContext(s.ctx), &policy)
 	if err != nil {
 		return nil, err
 	}
 
-	server, err := findPrimaryCaddyServer(s.svcCtx.DB.WithContext(s.ctx))
+	extras, err := loadWafPolicyCaddyExtras(s.svcCtx.DB.WithContext(s.ctx), policy.ID)
 	if err != nil {
 		return nil, err
 	}
+	return &policyPublishPlan{policy: &policy, directives: directives, extras: extras}, nil
+}
 
-	candidateConfig, err := buildPolicyCandidateCaddyConfig(server.Config, directives, policy.Enabled)
+func (plan *policyPublishPlan) candidateFor(server *model.CaddyServer) (*PolicyPublishCandidate, error) {
+	candidateConfig, err := buildPolicyCandidateCaddyConfig(server.Config, plan.directives, plan.policy.Enabled, plan.extras)
 	if err != nil {
 		return nil, err
 	}
 
 	return &PolicyPublishCandidate{
-		Policy:          &policy,
-		Directives:      directives,
+		Policy:          plan.policy,
+		Directives:      plan.directives,
 		Server:          server,
 		CandidateConfig: candidateConfig,
 		LastGoodConfig:  server.Config,
@@ -91,6 +147,9 @@ func (s *PolicyPublishService) BuildRollbackCandidate(revisionID uint) (*PolicyP
 	if err := s.svcCtx.DB.WithContext(s.ctx).First(&policy, revision.PolicyID).Error; err != nil {
 		return nil, nil, fmt.Errorf("策略不存在")
 	}
+	if err := ensureNoActiveWafRollout(s.svcCtx.DB.WithContext(s.ctx)); err != nil {
+		return nil, nil, err
+	}
 
 	directives := revision.DirectivesSnapshot
 	if directives == "" {
@@ -101,12 +160,17 @@ func (s *PolicyPublishService) BuildRollbackCandidate(revisionID uint) (*PolicyP
 		directives = builtDirectives
 	}
 
+	extras, err := loadWafPolicyCaddyExtras(s.svcCtx.DB.WithContext(s.ctx), policy.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	server, err := findPrimaryCaddyServer(s.svcCtx.DB.WithContext(s.ctx))
 	if err != nil {
 		return nil, nil, err
 	}
 
-	candidateConfig, err := buildPolicyCandidateCaddyConfig(server.Config, directives, policy.Enabled)
+	candidateConfig, err := buildPolicyCandidateCaddyConfig(server.Config, directives, policy.Enabled, extras)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -135,6 +199,11 @@ func (s *PolicyPublishService) LoadCandidate(candidate *PolicyPublishCandidate,
 	if candidate == nil || candidate.Server == nil {
 		return fmt.Errorf("Caddy 服务器不存在")
 	}
+	if !s.approved {
+		if err := ensureDirectCaddyChangeAllowed(s.svcCtx); err != nil {
+			return err
+		}
+	}
 	if err := loadCaddyfile(candidate.Server, candidate.CandidateConfig); err != nil {
 		if rollbackErr := rollbackPolicyConfigToLastGood(candidate.Server, candidate.LastGoodConfig); rollbackErr != nil {
 			return fmt.Errorf("策略%s失败: %v，回滚到 last_good 失败: %v", wafPolicyActionName(action), err, rollbackErr)
@@ -145,34 +214,52 @@ func (s *PolicyPublishService) LoadCandidate(candidate *PolicyPublishCandidate,
 }
 
 func (s *PolicyPublishService) PersistPublishedCandidate(candidate *PolicyPublishCandidate, operator string) error {
-	if candidate == nil || candidate.Policy == nil || candidate.Server == nil {
+	return s.PersistPublishedCandidates([]*PolicyPublishCandidate{candidate}, operator)
+}
+
+// PersistPublishedCandidates 在同一事务中保存各节点配置并生成一次策略版本，失败时全部回滚到 last_good
+func (s *PolicyPublishService) PersistPublishedCandidates(candidates []*PolicyPublishCandidate, operator string) error {
+	if len(candidates) == 0 {
 		return fmt.Errorf("发布候选配置无效")
 	}
-	modules := normalizeCaddyModulesJSON(candidate.Server.Modules)
+	for _, candidate := range candidates {
+		if candidate == nil || candidate.Policy == nil || candidate.Server == nil {
+			return fmt.Errorf("发布候选配置无效")
+		}
+	}
 
 	if err := s.svcCtx.DB.WithContext(s.ctx).Transaction(func(tx *gorm.DB) error {
-		if err := createCaddyPolicyHistory(tx, candidate.Server.ID, "policy_last_good", candidate.LastGoodConfig, candidate.LastGoodModules); err != nil {
-			return err
-		}
-		if err := tx.Model(&model.CaddyServer{}).
-			Where("id = ?", candidate.Server.ID).
-			Updates(map[string]interface{}{
-				"config":  candidate.CandidateConfig,
-				"modules": modules,
-			}).Error; err != nil {
-			return fmt.Errorf("保存 Caddy 服务器配置失败: %w", err)
+		for _, candidate := range candidates {
+			modules := normalizeCaddyModulesJSON(candidate.Server.Modules)
+			if err := createCaddyPolicyHistory(tx, candidate.Server.ID, "policy_last_good", candidate.LastGoodConfig, candidate.LastGoodModules, operator); err != nil {
+				return err
+			}
+			if err := tx.Model(&model.CaddyServer{}).
+				Where("id = ?", candidate.Server.ID).
+				Updates(map[string]interface{}{
+					"config":  candidate.CandidateConfig,
+					"modules": modules,
+				}).Error; err != nil {
+				return fmt.Errorf("保存 Caddy 服务器配置失败: %w", err)
+			}
+			if err := createCaddyPolicyHistory(tx, candidate.Server.ID, "policy_publish", candidate.CandidateConfig, modules, operator); err != nil {
+				return err
+			}
 		}
-		if err := createCaddyPolicyHistory(tx, candidate.Server.ID, "policy_publish", candidate.CandidateConfig, modules); err != nil {
-			return err
-		}
-		revision, err := createPolicyRevision(tx, candidate.Policy, wafPolicyStatusPublished, candidate.Directives, "publish policy", operator)
+		revision, err := createPolicyRevision(tx, candidates[0].Policy, wafPolicyStatusPublished, candidates[0].Directives, "publish policy", operator)
 		if err != nil {
 			return err
 		}
-		return markPolicyRevisionsRolledBack(tx, candidate.Policy.ID, revision.ID)
+		return markPolicyRevisionsRolledBack(tx, candidates[0].Policy.ID, revision.ID)
 	}); err != nil {
-		if rollbackErr := rollbackPolicyConfigToLastGood(candidate.Server, candidate.LastGoodConfig); rollbackErr != nil {
-			return fmt.Errorf("策略发布持久化失败: %v，回滚到 last_good 失败: %v", err, rollbackErr)
+		var rollbackErrs []string
+		for _, candidate := range candidates {
+			if rollbackErr := rollbackPolicyConfigToLastGood(candidate.Server, candidate.LastGoodConfig); rollbackErr != nil {
+				rollbackErrs = append(rollbackErrs, rollbackErr.Error())
+			}
+		}
+		if len(rollbackErrs) > 0 {
+			return fmt.Errorf("策略发布持久化失败: %v，回滚到 last_good 失败: %s", err, strings.Join(rollbackErrs, "; "))
 		}
 		return fmt.Errorf("策略发布持久化失败: %w", err)
 	}
@@ -187,7 +274,7 @@ func (s *PolicyPublishService) PersistRolledBackCandidate(candidate *PolicyPubli
 	modules := normalizeCaddyModulesJSON(candidate.Server.Modules)
 
 	if err := s.svcCtx.DB.WithContext(s.ctx).Transaction(func(tx *gorm.DB) error {
-		if err := createCaddyPolicyHistory(tx, candidate.Server.ID, "policy_last_good", candidate.LastGoodConfig, candidate.LastGoodModules); err != nil {
+		if err := createCaddyPolicyHistory(tx, candidate.Server.ID, "policy_last_good", candidate.LastGoodConfig, candidate.LastGoodModules, operator); err != nil {
 			return err
 		}
 		if err := tx.Model(&model.CaddyServer{}).
@@ -198,7 +285,7 @@ func (s *PolicyPublishService) PersistRolledBackCandidate(candidate *PolicyPubli
 			}).Error; err != nil {
 			return fmt.Errorf("保存 Caddy 服务器配置失败: %w", err)
 		}
-		if err := createCaddyPolicyHistory(tx, candidate.Server.ID, "policy_rollback", candidate.CandidateConfig, modules); err != nil {
+		if err := createCaddyPolicyHistory(tx, candidate.Server.ID, "policy_rollback", candidate.CandidateConfig, modules, operator); err != nil {
 			return err
 		}
 		if err := markPolicyRevisionsRolledBack(tx, revision.PolicyID, revision.ID); err != nil {
@@ -231,6 +318,12 @@ func wafPolicyActionName(action string) string {
 		return "发布"
 	case "rollback":
 		return "回滚"
+	case "canary":
+		return "灰度"
+	case "promote":
+		return "灰度转正"
+	case "abort":
+		return "灰度终止"
 	default:
 		trimmed := strings.TrimSpace(action)
 		if trimmed == "" {
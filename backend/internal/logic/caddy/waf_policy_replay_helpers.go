@@ -0,0 +1,216 @@
+package caddy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"logflux/internal/types"
+	"logflux/internal/waf"
+	"logflux/model"
+
+	"gorm.io/gorm"
+)
+
+const (
+	wafReplaySourceLogs   = "logs"
+	wafReplaySourceUpload = "upload"
+
+	wafReplayDefaultSampleSize = 500
+	wafReplayMaxSampleSize     = 5000
+	wafReplayDefaultHours      = 24
+	wafReplayMaxHours          = 24 * 30
+	// 响应中逐条列出的差异请求上限，计数不受影响
+	wafReplayMaxDiffItems = 200
+)
+
+// wafReplayCorpusCtxKey 上传语料在 handler 与 logic 之间传递使用的 context key
+type wafReplayCorpusCtxKey struct{}
+
+// WithWafReplayCorpus 将 handler 读取的回放语料放入上下文
+func WithWafReplayCorpus(ctx context.Context, corpus []byte) context.Context {
+	return context.WithValue(ctx, wafReplayCorpusCtxKey{}, corpus)
+}
+
+type wafReplayOutcome struct {
+	Request   waf.ReplayRequest
+	Baseline  waf.ReplayVerdict
+	Candidate waf.ReplayVerdict
+}
+
+func normalizeWafReplaySource(source string) string {
+	normalized := strings.ToLower(strings.TrimSpace(source))
+	if normalized == "" {
+		return wafReplaySourceLogs
+	}
+	return normalized
+}
+
+func normalizeWafReplaySampleSize(size int) int {
+	if size <= 0 {
+		return wafReplayDefaultSampleSize
+	}
+	return min(size, wafReplayMaxSampleSize)
+}
+
+func normalizeWafReplayHours(hours int) int {
+	if hours <= 0 {
+		return wafReplayDefaultHours
+	}
+	return min(hours, wafReplayMaxHours)
+}
+
+// loadWafReplayCRSLayout 定位当前激活的 CRS 版本；未激活时返回 nil 与提示信息
+func loadWafReplayCRSLayout(db *gorm.DB) (*waf.CRSLayout, string, string) {
+	var release model.WafRelease
+	if err := db.Where("kind = ? AND status = ?", wafKindCRS, wafReleaseStatusActive).Order("id desc").First(&release).Error; err != nil {
+		return nil, "", "未找到已激活的 CRS 版本，回放仅包含策略与排除规则指令"
+	}
+	layout, err := waf.LocateCRSLayout(release.StoragePath)
+	if err != nil {
+		return nil, release.Version, fmt.Sprintf("CRS 版本 %s 文件不可用（%v），回放仅包含策略与排除规则指令", release.Version, err)
+	}
+	return layout, release.Version, ""
+}
+
+// buildWafReplayEngine 按 Caddy 托管片段的顺序组装指令，再替换为本地 CRS 文件。
+// 回放评估的是“开启拦截后会拦截哪些请求”：DetectionOnly 下 Coraza 不会中断请求，
+// 因此统一按 SecRuleEngine On 评估，只有显式关闭（Off）的策略保持关闭
+func buildWafReplayEngine(directives string, layout *waf.CRSLayout) (*waf.ReplayEngine, error) {
+	composed := waf.ResolveReplayIncludes(composeManagedCorazaDirectives(directives, "/dev/null"), layout)
+	if !wafReplayRuleEngineOff(directives) {
+		composed += "\nSecRuleEngine On"
+	}
+	return waf.NewReplayEngine(composed)
+}
+
+// wafReplayRuleEngineOff 策略指令中最后一条 SecRuleEngine 是否为 Off
+func wafReplayRuleEngineOff(directives string) bool {
+	off := false
+	for _, line := range strings.Split(directives, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && strings.EqualFold(fields[0], "SecRuleEngine") {
+			off = strings.EqualFold(fields[1], "Off")
+		}
+	}
+	return off
+}
+
+// loadWafReplayLogSample 读取最近的访问日志作为回放语料；请求头取自原始日志，日志中不含请求体
+func loadWafReplayLogSample(db *gorm.DB, hours, sampleSize int, host string) ([]waf.ReplayRequest, error) {
+	query := db.Model(&model.CaddyLog{}).
+		Select("id, host, method, uri, proto, user_agent, remote_ip, client_ip, raw_log").
+		Where("log_time >= ?", time.Now().Add(-time.Duration(hours)*time.Hour))
+	if normalizedHost := normalizePolicyScopeHost(host); normalizedHost != "" {
+		query = query.Where("host = ?", normalizedHost)
+	}
+
+	var logs []model.CaddyLog
+	if err := query.Order("log_time desc").Limit(sampleSize).Find(&logs).Error; err != nil {
+		return nil, fmt.Errorf("查询回放访问日志失败: %w", err)
+	}
+
+	requests := make([]waf.ReplayRequest, 0, len(logs))
+	for _, item := range logs {
+		requests = append(requests, wafReplayRequestFromCaddyLog(item))
+	}
+	return requests, nil
+}
+
+func wafReplayRequestFromCaddyLog(item model.CaddyLog) waf.ReplayRequest {
+	request := waf.ReplayRequest{
+		Ref:        fmt.Sprintf("log#%d", item.ID),
+		Method:     item.Method,
+		Host:       item.Host,
+		URI:        item.Uri,
+		Proto:      item.Proto,
+		RemoteAddr: item.ClientIP,
+	}
+	if request.RemoteAddr == "" {
+		request.RemoteAddr = item.RemoteIP
+	}
+
+	var raw struct {
+		Request struct {
+			Headers map[string][]string `json:"headers"`
+		} `json:"request"`
+	}
+	if strings.TrimSpace(item.RawLog) != "" && json.Unmarshal([]byte(item.RawLog), &raw) == nil {
+		request.Headers = raw.Request.Headers
+	}
+	if len(request.Headers) == 0 && item.UserAgent != "" {
+		request.Headers = map[string][]string{"User-Agent": {item.UserAgent}}
+	}
+	// 日志记录的是原始请求头，回放时补上 Caddy 写入的客户端 IP 头，使 IP 名单规则生效
+	if request.RemoteAddr != "" {
+		headers := make(map[string][]string, len(request.Headers)+1)
+		for key, values := range request.Headers {
+			if !strings.EqualFold(key, wafClientIPHeader) {
+				headers[key] = values
+			}
+		}
+		headers[wafClientIPHeader] = []string{request.RemoteAddr}
+		request.Headers = headers
+	}
+	return request
+}
+
+// summarizeWafReplayOutcomes 对比基线与候选的拦截结果，统计新增拦截与解除拦截
+func summarizeWafReplayOutcomes(outcomes []wafReplayOutcome) *types.WafPolicyReplayResp {
+	resp := &types.WafPolicyReplayResp{
+		Total:          int64(len(outcomes)),
+		NewlyBlocked:   []types.WafPolicyReplayItem{},
+		NewlyUnblocked: []types.WafPolicyReplayItem{},
+	}
+	for _, outcome := range outcomes {
+		if outcome.Baseline.Blocked {
+			resp.BaselineBlocked++
+		}
+		if outcome.Candidate.Blocked {
+			resp.CandidateBlocked++
+		}
+
+		switch {
+		case outcome.Candidate.Blocked && !outcome.Baseline.Blocked:
+			resp.NewlyBlockedCount++
+			if len(resp.NewlyBlocked) < wafReplayMaxDiffItems {
+				resp.NewlyBlocked = append(resp.NewlyBlocked, toWafReplayItem(outcome))
+			}
+		case outcome.Baseline.Blocked && !outcome.Candidate.Blocked:
+			resp.NewlyUnblockedCount++
+			if len(resp.NewlyUnblocked) < wafReplayMaxDiffItems {
+				resp.NewlyUnblocked = append(resp.NewlyUnblocked, toWafReplayItem(outcome))
+			}
+		}
+	}
+	return resp
+}
+
+func toWafReplayItem(outcome wafReplayOutcome) types.WafPolicyReplayItem {
+	interruptRuleID := outcome.Candidate.InterruptRuleID
+	status := outcome.Candidate.Status
+	if !outcome.Candidate.Blocked {
+		interruptRuleID = outcome.Baseline.InterruptRuleID
+		status = outcome.Baseline.Status
+	}
+	return types.WafPolicyReplayItem{
+		Ref:              outcome.Request.Ref,
+		Method:           outcome.Request.Method,
+		Host:             outcome.Request.Host,
+		Uri:              outcome.Request.URI,
+		Status:           status,
+		InterruptRuleId:  int64(interruptRuleID),
+		BaselineRuleIds:  toInt64RuleIDs(outcome.Baseline.MatchedRuleIDs),
+		CandidateRuleIds: toInt64RuleIDs(outcome.Candidate.MatchedRuleIDs),
+	}
+}
+
+func toInt64RuleIDs(ids []int) []int64 {
+	result := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, int64(id))
+	}
+	return result
+}
@@ -0,0 +1,77 @@
+package caddy
+
+import (
+	"reflect"
+	"testing"
+
+	"logflux/internal/waf"
+	"logflux/model"
+)
+
+func TestWafReplayRequestFromCaddyLog(t *testing.T) {
+	request := wafReplayRequestFromCaddyLog(model.CaddyLog{
+		ID: 12, Host: "app.example.com", Method: "GET", Uri: "/search?q=1", Proto: "HTTP/2",
+		RemoteIP: "10.0.0.1", ClientIP: "203.0.113.5", UserAgent: "curl/8",
+		RawLog: `{"request":{"headers":{"User-Agent":["Mozilla/5.0"],"Accept":["*/*"]}}}`,
+	})
+	if request.Ref != "log#12" || request.RemoteAddr != "203.0.113.5" || request.URI != "/search?q=1" {
+		t.Fatalf("unexpected replay request: %+v", request)
+	}
+	if !reflect.DeepEqual(request.Headers["User-Agent"], []string{"Mozilla/5.0"}) || len(request.Headers) != 3 {
+		t.Fatalf("expected headers from raw log, got %v", request.Headers)
+	}
+	if !reflect.DeepEqual(request.Headers[wafClientIPHeader], []string{"203.0.113.5"}) {
+		t.Fatalf("expected client ip header for IP list rules, got %v", request.Headers)
+	}
+
+	request = wafReplayRequestFromCaddyLog(model.CaddyLog{ID: 13, RemoteIP: "10.0.0.1", UserAgent: "curl/8"})
+	if request.RemoteAddr != "10.0.0.1" || !reflect.DeepEqual(request.Headers["User-Agent"], []string{"curl/8"}) {
+		t.Fatalf("expected remote ip and user agent fallback, got %+v", request)
+	}
+}
+
+func TestSummarizeWafReplayOutcomes(t *testing.T) {
+	blocked := waf.ReplayVerdict{Blocked: true, Status: 403, InterruptRuleID: 949110, MatchedRuleIDs: []int{942100, 949110}}
+	passed := waf.ReplayVerdict{MatchedRuleIDs: []int{942100}}
+
+	resp := summarizeWafReplayOutcomes([]wafReplayOutcome{
+		{Request: waf.ReplayRequest{Ref: "a"}, Baseline: passed, Candidate: blocked},
+		{Request: waf.ReplayRequest{Ref: "b"}, Baseline: blocked, Candidate: passed},
+		{Request: waf.ReplayRequest{Ref: "c"}, Baseline: blocked, Candidate: blocked},
+		{Request: waf.ReplayRequest{Ref: "d"}},
+	})
+
+	if resp.Total != 4 || resp.BaselineBlocked != 2 || resp.CandidateBlocked != 2 {
+		t.Fatalf("unexpected totals: %+v", resp)
+	}
+	if resp.NewlyBlockedCount != 1 || resp.NewlyBlocked[0].Ref != "a" || resp.NewlyBlocked[0].InterruptRuleId != 949110 {
+		t.Fatalf("unexpected newly blocked: %+v", resp.NewlyBlocked)
+	}
+	if resp.NewlyUnblockedCount != 1 || resp.NewlyUnblocked[0].Ref != "b" || resp.NewlyUnblocked[0].Status != 403 {
+		t.Fatalf("unexpected newly unblocked: %+v", resp.NewlyUnblocked)
+	}
+	if !reflect.DeepEqual(resp.NewlyUnblocked[0].CandidateRuleIds, []int64{942100}) {
+		t.Fatalf("unexpected candidate rule ids: %v", resp.NewlyUnblocked[0].CandidateRuleIds)
+	}
+}
+
+func TestBuildWafReplayEngineBlocksForDetectionOnlyPolicy(t *testing.T) {
+	rule := `SecRule ARGS:q "@contains attack" "id:10001,phase:1,deny,status:403,log"`
+	request := waf.ReplayRequest{Method: "GET", Host: "app.example.com", URI: "/search?q=attack"}
+
+	engine, err := buildWafReplayEngine("SecRuleEngine DetectionOnly\n"+rule, nil)
+	if err != nil {
+		t.Fatalf("buildWafReplayEngine error: %v", err)
+	}
+	if verdict := engine.Evaluate(request); !verdict.Blocked || verdict.InterruptRuleID != 10001 {
+		t.Fatalf("expected DetectionOnly policy to be replayed as blocking, got %+v", verdict)
+	}
+
+	engine, err = buildWafReplayEngine("SecRuleEngine Off\n"+rule, nil)
+	if err != nil {
+		t.Fatalf("buildWafReplayEngine error: %v", err)
+	}
+	if verdict := engine.Evaluate(request); verdict.Blocked {
+		t.Fatalf("expected disabled policy to stay off, got %+v", verdict)
+	}
+}
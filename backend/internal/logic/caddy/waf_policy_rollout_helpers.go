@@ -0,0 +1,249 @@
+package caddy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"logflux/internal/types"
+	"logflux/model"
+
+	"gorm.io/gorm"
+)
+
+const (
+	wafRolloutStatusCanary   = "canary"
+	wafRolloutStatusPromoted = "promoted"
+	wafRolloutStatusAborted  = "aborted"
+
+	// 灰度阶段写入的策略版本状态
+	wafPolicyStatusCanary = "canary"
+
+	wafRolloutDefaultMaxFalsePositiveRate = 1.0
+	wafRolloutDefaultObserveHours         = 24
+	wafRolloutMaxObserveHours             = 24 * 30
+
+	// CRS 异常评分拦截规则，灰度实例命中即视为“本应拦截”
+	wafRolloutInboundBlockingRuleID  = 949110
+	wafRolloutOutboundBlockingRuleID = 959100
+)
+
+func normalizeWafRolloutStatus(status string) string {
+	return strings.ToLower(strings.TrimSpace(status))
+}
+
+func normalizeWafRolloutMaxFalsePositiveRate(rate float64) (float64, error) {
+	if rate == 0 {
+		return wafRolloutDefaultMaxFalsePositiveRate, nil
+	}
+	if rate < 0 || rate > 100 {
+		return 0, fmt.Errorf("误报率阈值必须在 0-100 之间")
+	}
+	return rate, nil
+}
+
+func normalizeWafRolloutObserveHours(hours int64) (int64, error) {
+	if hours == 0 {
+		return wafRolloutDefaultObserveHours, nil
+	}
+	if hours < 0 || hours > wafRolloutMaxObserveHours {
+		return 0, fmt.Errorf("观察时长必须在 1-%d 小时之间", wafRolloutMaxObserveHours)
+	}
+	return hours, nil
+}
+
+// buildWafCanaryDirectives 将候选指令改写为灰度实例：强制 DetectionOnly 与 JSON 审计，
+// 并写入组件签名以便审计日志区分灰度事件
+func buildWafCanaryDirectives(directives string, rolloutID uint) string {
+	lines := make([]string, 0)
+	for _, line := range strings.Split(strings.TrimSpace(directives), "\n") {
+		trimmed := strings.TrimSpace(line)
+		fields := strings.Fields(trimmed)
+		if len(fields) > 0 {
+			switch strings.ToLower(fields[0]) {
+			case "secruleengine", "secauditengine", "secauditlogformat", "seccomponentsignature":
+				continue
+			}
+		}
+		lines = append(lines, trimmed)
+	}
+
+	lines = append(lines,
+		"",
+		"# LogFlux canary rollout",
+		"SecRuleEngine DetectionOnly",
+		"SecAuditEngine RelevantOnly",
+		"SecAuditLogFormat JSON",
+		fmt.Sprintf("SecComponentSignature \"%s%d\"", model.WafRolloutSignaturePrefix, rolloutID),
+	)
+	return strings.Join(lines, "\n")
+}
+
+// buildWafCanaryExpression 将绑定作用域转换为 Caddy CEL 表达式，路径按前缀匹配，与统计口径一致
+func buildWafCanaryExpression(bindings []model.WafPolicyBinding) (string, error) {
+	if len(bindings) == 0 {
+		return "", fmt.Errorf("灰度作用域不能为空")
+	}
+
+	clauses := make([]string, 0, len(bindings))
+	for _, binding := range bindings {
+		parts := make([]string, 0, 3)
+		host, path, method := "", "", ""
+		switch normalizePolicyScopeType(binding.ScopeType) {
+		case wafPolicyScopeTypeGlobal:
+			return "true", nil
+		case wafPolicyScopeTypeSite:
+			host = normalizePolicyScopeHost(binding.Host)
+		case wafPolicyScopeTypeRoute:
+			host = normalizePolicyScopeHost(binding.Host)
+			path = normalizePolicyScopePath(binding.Path)
+			method = normalizePolicyHTTPMethod(binding.Method)
+		default:
+			return "", fmt.Errorf("绑定 #%d 作用域类型无效", binding.ID)
+		}
+		if strings.ContainsAny(host+path+method, "'\"`\\") {
+			return "", fmt.Errorf("绑定 #%d 作用域包含不支持的字符", binding.ID)
+		}
+
+		if host != "" {
+			parts = append(parts, fmt.Sprintf("host('%s')", host))
+		}
+		if path != "" && path != "/" {
+			parts = append(parts, fmt.Sprintf("path('%s', '%s/*')", path, strings.TrimSuffix(path, "/")))
+		}
+		if method != "" {
+			parts = append(parts, fmt.Sprintf("method('%s')", method))
+		}
+		switch len(parts) {
+		case 0:
+			return "true", nil
+		case 1:
+			clauses = append(clauses, parts[0])
+		default:
+			clauses = append(clauses, "("+strings.Join(parts, " && ")+")")
+		}
+	}
+	return strings.Join(clauses, " || "), nil
+}
+
+func loadWafRolloutBindings(db *gorm.DB, policyID uint, bindingIDs []int64) ([]model.WafPolicyBinding, error) {
+	if len(bindingIDs) == 0 {
+		return nil, fmt.Errorf("请选择灰度绑定")
+	}
+	var bindings []model.WafPolicyBinding
+	if err := db.Where("id IN ?", bindingIDs).Order("priority asc, id asc").Find(&bindings).Error; err != nil {
+		return nil, fmt.Errorf("查询灰度绑定失败: %w", err)
+	}
+	if len(bindings) != len(uniqueInt64s(bindingIDs)) {
+		return nil, fmt.Errorf("灰度绑定不存在")
+	}
+	for _, binding := range bindings {
+		if binding.PolicyID != policyID {
+			return nil, fmt.Errorf("绑定 #%d 不属于当前策略", binding.ID)
+		}
+	}
+	return bindings, nil
+}
+
+func uniqueInt64s(values []int64) []int64 {
+	seen := make(map[int64]struct{}, len(values))
+	result := make([]int64, 0, len(values))
+	for _, value := range values {
+		if _, ok := seen[value]; ok {
+			continue
+		}
+		seen[value] = struct{}{}
+		result = append(result, value)
+	}
+	return result
+}
+
+func ensureNoActiveWafRollout(db *gorm.DB) error {
+	var count int64
+	if err := db.Model(&model.WafPolicyRollout{}).Where("status = ?", wafRolloutStatusCanary).Count(&count).Error; err != nil {
+		return fmt.Errorf("查询灰度发布失败: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("存在进行中的灰度发布，请先转正或终止")
+	}
+	return nil
+}
+
+type wafRolloutMetrics struct {
+	RequestCount      int64
+	WouldBlockCount   int64
+	NewlyBlockedCount int64
+	FalsePositiveRate float64
+}
+
+// queryWafRolloutMetrics 统计灰度作用域内的请求量与灰度实例的拦截判定；
+// 灰度实例的审计状态反映最终响应，action 仍为 detected 说明已发布版本放行了该请求
+func queryWafRolloutMetrics(db *gorm.DB, rollout *model.WafPolicyRollout, bindings []model.WafPolicyBinding) (*wafRolloutMetrics, error) {
+	metrics := &wafRolloutMetrics{}
+
+	requestQuery := applyWafPolicyBindingScopeQuery(db.Model(&model.CaddyLog{}).Where("log_time >= ?", rollout.StartedAt), bindings)
+	if err := requestQuery.Count(&metrics.RequestCount).Error; err != nil {
+		return nil, fmt.Errorf("统计灰度请求量失败: %w", err)
+	}
+
+	wouldBlock := func() *gorm.DB {
+		return db.Model(&model.WafAuditLog{}).
+			Where("rollout_id = ? AND log_time >= ?", rollout.ID, rollout.StartedAt).
+			Where("(? = ANY(rule_ids) OR ? = ANY(rule_ids))", wafRolloutInboundBlockingRuleID, wafRolloutOutboundBlockingRuleID)
+	}
+	if err := wouldBlock().Count(&metrics.WouldBlockCount).Error; err != nil {
+		return nil, fmt.Errorf("统计灰度拦截量失败: %w", err)
+	}
+	if err := wouldBlock().Where("action = ?", model.WafAuditActionDetected).Count(&metrics.NewlyBlockedCount).Error; err != nil {
+		return nil, fmt.Errorf("统计灰度新增拦截失败: %w", err)
+	}
+
+	if metrics.RequestCount > 0 {
+		metrics.FalsePositiveRate = float64(metrics.NewlyBlockedCount) * 100 / float64(metrics.RequestCount)
+	}
+	return metrics, nil
+}
+
+// shouldAutoPromoteWafRollout 观察期满、期间有流量且误报率不高于阈值时自动转正
+func shouldAutoPromoteWafRollout(rollout *model.WafPolicyRollout, now time.Time) bool {
+	if rollout == nil || !rollout.AutoPromote || normalizeWafRolloutStatus(rollout.Status) != wafRolloutStatusCanary {
+		return false
+	}
+	if now.Sub(rollout.StartedAt) < time.Duration(rollout.ObserveHours)*time.Hour {
+		return false
+	}
+	return rollout.RequestCount > 0 && rollout.FalsePositiveRate <= rollout.MaxFalsePositiveRate
+}
+
+func toWafPolicyRolloutItem(rollout *model.WafPolicyRollout, policyName string) types.WafPolicyRolloutItem {
+	bindingIDs := make([]int64, 0, len(rollout.BindingIDs))
+	bindingIDs = append(bindingIDs, rollout.BindingIDs...)
+	item := types.WafPolicyRolloutItem{
+		ID:                   rollout.ID,
+		PolicyId:             rollout.PolicyID,
+		PolicyName:           policyName,
+		BaselineRevisionId:   rollout.BaselineRevisionID,
+		CandidateRevisionId:  rollout.CandidateRevisionID,
+		BindingIds:           bindingIDs,
+		Status:               rollout.Status,
+		AutoPromote:          rollout.AutoPromote,
+		MaxFalsePositiveRate: rollout.MaxFalsePositiveRate,
+		ObserveHours:         rollout.ObserveHours,
+		RequestCount:         rollout.RequestCount,
+		WouldBlockCount:      rollout.WouldBlockCount,
+		NewlyBlockedCount:    rollout.NewlyBlockedCount,
+		FalsePositiveRate:    rollout.FalsePositiveRate,
+		StartedAt:            formatTime(rollout.StartedAt),
+		Operator:             rollout.Operator,
+		Message:              rollout.Message,
+		CreatedAt:            formatTime(rollout.CreatedAt),
+		UpdatedAt:            formatTime(rollout.UpdatedAt),
+	}
+	if rollout.EvaluatedAt != nil {
+		item.EvaluatedAt = formatTime(*rollout.EvaluatedAt)
+	}
+	if rollout.FinishedAt != nil {
+		item.FinishedAt = formatTime(*rollout.FinishedAt)
+	}
+	return item
+}
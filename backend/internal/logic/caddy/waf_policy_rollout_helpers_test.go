@@ -0,0 +1,106 @@
+package caddy
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"logflux/internal/waf"
+	"logflux/model"
+)
+
+func TestBuildWafCanaryDirectives(t *testing.T) {
+	directives := buildWafCanaryDirectives("SecRuleEngine On\nSecAuditEngine Off\nSecAuditLogFormat Native\nSecRequestBodyAccess On", 7)
+
+	for _, expected := range []string{"SecRuleEngine DetectionOnly", "SecAuditEngine RelevantOnly", "SecAuditLogFormat JSON", `SecComponentSignature "logflux-canary-7"`, "SecRequestBodyAccess On"} {
+		if !strings.Contains(directives, expected) {
+			t.Fatalf("expected %q in canary directives:\n%s", expected, directives)
+		}
+	}
+	for _, unexpected := range []string{"SecRuleEngine On", "SecAuditEngine Off", "SecAuditLogFormat Native"} {
+		if strings.Contains(directives, unexpected) {
+			t.Fatalf("unexpected %q in canary directives:\n%s", unexpected, directives)
+		}
+	}
+	if err := waf.ValidateSecLang(directives); err != nil {
+		t.Fatalf("canary directives should be valid SecLang: %v", err)
+	}
+}
+
+func TestBuildWafCanaryExpression(t *testing.T) {
+	expression, err := buildWafCanaryExpression([]model.WafPolicyBinding{
+		{ID: 1, ScopeType: wafPolicyScopeTypeSite, Host: "Example.com"},
+		{ID: 2, ScopeType: wafPolicyScopeTypeRoute, Host: "api.example.com", Path: "/v1/", Method: "post"},
+	})
+	if err != nil {
+		t.Fatalf("buildWafCanaryExpression() error = %v", err)
+	}
+	expected := "host('example.com') || (host('api.example.com') && path('/v1/', '/v1/*') && method('POST'))"
+	if expression != expected {
+		t.Fatalf("unexpected expression:\n got %s\nwant %s", expression, expected)
+	}
+
+	if expression, err := buildWafCanaryExpression([]model.WafPolicyBinding{
+		{ID: 1, ScopeType: wafPolicyScopeTypeRoute, Path: "/admin"},
+		{ID: 2, ScopeType: wafPolicyScopeTypeGlobal},
+	}); err != nil || expression != "true" {
+		t.Fatalf("expected global scope to match all, got %q err=%v", expression, err)
+	}
+
+	if _, err := buildWafCanaryExpression([]model.WafPolicyBinding{{ID: 3, ScopeType: wafPolicyScopeTypeRoute, Path: "/a')||true||('"}}); err == nil {
+		t.Fatalf("expected quote in scope to be rejected")
+	}
+	if _, err := buildWafCanaryExpression(nil); err == nil {
+		t.Fatalf("expected empty scope to be rejected")
+	}
+}
+
+func TestShouldAutoPromoteWafRollout(t *testing.T) {
+	now := time.Now()
+	rollout := &model.WafPolicyRollout{
+		Status:               wafRolloutStatusCanary,
+		AutoPromote:          true,
+		MaxFalsePositiveRate: 1,
+		ObserveHours:         24,
+		StartedAt:            now.Add(-25 * time.Hour),
+		RequestCount:         1000,
+		FalsePositiveRate:    0.5,
+	}
+	if !shouldAutoPromoteWafRollout(rollout, now) {
+		t.Fatalf("expected rollout to auto promote")
+	}
+
+	cases := map[string]func(r *model.WafPolicyRollout){
+		"manual":         func(r *model.WafPolicyRollout) { r.AutoPromote = false },
+		"observing":      func(r *model.WafPolicyRollout) { r.StartedAt = now.Add(-2 * time.Hour) },
+		"no traffic":     func(r *model.WafPolicyRollout) { r.RequestCount = 0 },
+		"too many fp":    func(r *model.WafPolicyRollout) { r.FalsePositiveRate = 1.5 },
+		"already closed": func(r *model.WafPolicyRollout) { r.Status = wafRolloutStatusAborted },
+	}
+	for name, mutate := range cases {
+		candidate := *rollout
+		mutate(&candidate)
+		if shouldAutoPromoteWafRollout(&candidate, now) {
+			t.Fatalf("expected no auto promotion for %s", name)
+		}
+	}
+}
+
+func TestRenderManagedCaddyfileWithCanary(t *testing.T) {
+	config, err := buildPolicyCanaryCaddyConfig("", "SecRuleEngine On", managedWafCanary{
+		Expression: "host('example.com')",
+		Directives: buildWafCanaryDirectives("SecRuleEngine On", 3),
+	}, managedPolicyExtras{})
+	if err != nil {
+		t.Fatalf("buildPolicyCanaryCaddyConfig() error = %v", err)
+	}
+	for _, expected := range []string{"(waf_canary) {", "@waf_canary expression `host('example.com')`", "coraza_waf @waf_canary {", "import waf_canary\n  import waf_protect"} {
+		if !strings.Contains(config, expected) {
+			t.Fatalf("expected %q in config:\n%s", expected, config)
+		}
+	}
+
+	if _, err := buildPolicyCanaryCaddyConfig(":8080 {\n  respond \"ok\"\n}\n", "SecRuleEngine On", managedWafCanary{Expression: "true", Directives: "SecRuleEngine DetectionOnly"}, managedPolicyExtras{}); err == nil {
+		t.Fatalf("expected unmanaged Caddyfile to be rejected")
+	}
+}
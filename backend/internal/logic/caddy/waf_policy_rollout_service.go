@@ -0,0 +1,398 @@
+package caddy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"logflux/internal/svc"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type WafRolloutStartParams struct {
+	PolicyID             uint
+	BindingIDs           []int64
+	AutoPromote          bool
+	MaxFalsePositiveRate float64
+	ObserveHours         int64
+	Message              string
+}
+
+// WafRolloutService 负责灰度发布的部署、评估、转正与终止
+type WafRolloutService struct {
+	ctx     context.Context
+	svcCtx  *svc.ServiceContext
+	publish *PolicyPublishService
+}
+
+func NewWafRolloutService(ctx context.Context, svcCtx *svc.ServiceContext) *WafRolloutService {
+	return &WafRolloutService{ctx: ctx, svcCtx: svcCtx, publish: NewPolicyPublishService(ctx, svcCtx)}
+}
+
+func (s *WafRolloutService) db() *gorm.DB {
+	return s.svcCtx.DB.WithContext(s.ctx)
+}
+
+// Start 部署灰度实例：已发布版本继续拦截，候选指令在选定绑定作用域以 DetectionOnly 运行
+func (s *WafRolloutService) Start(params WafRolloutStartParams, operator string) (*model.WafPolicyRollout, error) {
+	if s == nil || s.svcCtx == nil || s.svcCtx.DB == nil {
+		return nil, fmt.Errorf("数据库为空")
+	}
+	if params.PolicyID == 0 {
+		return nil, fmt.Errorf("策略 ID 不能为空")
+	}
+	maxRate, err := normalizeWafRolloutMaxFalsePositiveRate(params.MaxFalsePositiveRate)
+	if err != nil {
+		return nil, err
+	}
+	observeHours, err := normalizeWafRolloutObserveHours(params.ObserveHours)
+	if err != nil {
+		return nil, err
+	}
+
+	db := s.db()
+	var policy model.WafPolicy
+	if err := db.First(&policy, params.PolicyID).Error; err != nil {
+		return nil, fmt.Errorf("策略不存在")
+	}
+	if !policy.Enabled {
+		return nil, fmt.Errorf("策略未启用，无法灰度发布")
+	}
+	if err := ensureNoActiveWafRollout(db); err != nil {
+		return nil, err
+	}
+
+	bindingIDs := uniqueInt64s(params.BindingIDs)
+	bindings, err := loadWafRolloutBindings(db, policy.ID, bindingIDs)
+	if err != nil {
+		return nil, err
+	}
+	expression, err := buildWafCanaryExpression(bindings)
+	if err != nil {
+		return nil, err
+	}
+
+	var baseline model.WafPolicyRevision
+	if err := db.Where("policy_id = ? AND status = ?", policy.ID, wafPolicyStatusPublished).Order("id desc").First(&baseline).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("策略尚未发布，请先直接发布")
+		}
+		return nil, fmt.Errorf("查询已发布版本失败: %w", err)
+	}
+
+	candidateDirectives, err := buildPolicyDirectivesWithExclusions(db, &policy)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(candidateDirectives) == strings.TrimSpace(baseline.DirectivesSnapshot) {
+		return nil, fmt.Errorf("候选指令与已发布版本一致，无需灰度")
+	}
+
+	extras, err := loadWafPolicyCaddyExtras(db, policy.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	server, err := findPrimaryCaddyServer(db)
+	if err != nil {
+		return nil, err
+	}
+
+	// 先落库拿到灰度 ID，组件签名依赖该 ID
+	rollout := &model.WafPolicyRollout{
+		PolicyID:             policy.ID,
+		BaselineRevisionID:   baseline.ID,
+		BindingIDs:           model.Int64Array(bindingIDs),
+		Status:               wafRolloutStatusCanary,
+		AutoPromote:          params.AutoPromote,
+		MaxFalsePositiveRate: maxRate,
+		ObserveHours:         observeHours,
+		StartedAt:            time.Now(),
+		Operator:             strings.TrimSpace(operator),
+		Message:              strings.TrimSpace(params.Message),
+	}
+	if err := db.Create(rollout).Error; err != nil {
+		return nil, fmt.Errorf("创建灰度发布失败: %w", err)
+	}
+	discard := func() {
+		if err := s.db().Delete(&model.WafPolicyRollout{}, rollout.ID).Error; err != nil {
+			logx.Errorf("清理失败的灰度发布记录失败: id=%d err=%v", rollout.ID, err)
+		}
+	}
+
+	candidateConfig, err := buildPolicyCanaryCaddyConfig(server.Config, baseline.DirectivesSnapshot, managedWafCanary{
+		Expression: expression,
+		Directives: buildWafCanaryDirectives(candidateDirectives, rollout.ID),
+	}, extras)
+	if err != nil {
+		discard()
+		return nil, err
+	}
+	candidate := &PolicyPublishCandidate{
+		Policy:          &policy,
+		Directives:      candidateDirectives,
+		Server:          server,
+		CandidateConfig: candidateConfig,
+		LastGoodConfig:  server.Config,
+		LastGoodModules: normalizeCaddyModulesJSON(server.Modules),
+	}
+	if err := s.publish.ValidateCandidate(candidate, "canary"); err != nil {
+		discard()
+		return nil, err
+	}
+	if err := s.publish.LoadCandidate(candidate, "canary"); err != nil {
+		discard()
+		return nil, err
+	}
+
+	if err := s.persist(candidate, "policy_canary", operator, func(tx *gorm.DB) error {
+		revision, err := createPolicyRevision(tx, &policy, wafPolicyStatusCanary, candidateDirectives, "start canary rollout", operator)
+		if err != nil {
+			return err
+		}
+		rollout.CandidateRevisionID = revision.ID
+		return tx.Model(&model.WafPolicyRollout{}).Where("id = ?", rollout.ID).Update("candidate_revision_id", revision.ID).Error
+	}); err != nil {
+		discard()
+		return nil, err
+	}
+	return rollout, nil
+}
+
+// Promote 将灰度候选版本发布为生效版本，并移除灰度实例
+func (s *WafRolloutService) Promote(rolloutID uint, operator, message string, auto bool) (*model.WafPolicyRollout, error) {
+	rollout, policy, err := s.loadActive(rolloutID)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidateRevision model.WafPolicyRevision
+	if err := s.db().First(&candidateRevision, rollout.CandidateRevisionID).Error; err != nil {
+		return nil, fmt.Errorf("灰度候选版本不存在")
+	}
+	candidate, err := s.buildCandidate(policy, candidateRevision.DirectivesSnapshot)
+	if err != nil {
+		return nil, err
+	}
+	// 自动转正由调度器按灰度开始时设定的条件执行，不再走变更审批
+	publish := s.publish
+	if auto {
+		publish = publish.approvedChange()
+	}
+	if err := publish.ValidateCandidate(candidate, "promote"); err != nil {
+		return nil, err
+	}
+	if err := publish.LoadCandidate(candidate, "promote"); err != nil {
+		return nil, err
+	}
+
+	revisionMessage := "promote canary rollout"
+	if auto {
+		revisionMessage = "auto promote canary rollout"
+	}
+	finishedAt := time.Now()
+	if err := s.persist(candidate, "policy_publish", operator, func(tx *gorm.DB) error {
+		revision, err := createPolicyRevision(tx, policy, wafPolicyStatusPublished, candidate.Directives, revisionMessage, operator)
+		if err != nil {
+			return err
+		}
+		if err := markPolicyRevisionsRolledBack(tx, policy.ID, revision.ID); err != nil {
+			return err
+		}
+		return s.finish(tx, rollout, wafRolloutStatusPromoted, message, finishedAt)
+	}); err != nil {
+		return nil, err
+	}
+	return rollout, nil
+}
+
+// Abort 移除灰度实例，恢复为仅运行已发布版本
+func (s *WafRolloutService) Abort(rolloutID uint, operator, message string) (*model.WafPolicyRollout, error) {
+	rollout, policy, err := s.loadActive(rolloutID)
+	if err != nil {
+		return nil, err
+	}
+
+	var baseline model.WafPolicyRevision
+	if err := s.db().First(&baseline, rollout.BaselineRevisionID).Error; err != nil {
+		return nil, fmt.Errorf("灰度基线版本不存在")
+	}
+	candidate, err := s.buildCandidate(policy, baseline.DirectivesSnapshot)
+	if err != nil {
+		return nil, err
+	}
+	// 终止只恢复已发布版本，不受变更审批限制
+	publish := s.publish.approvedChange()
+	if err := publish.ValidateCandidate(candidate, "abort"); err != nil {
+		return nil, err
+	}
+	if err := publish.LoadCandidate(candidate, "abort"); err != nil {
+		return nil, err
+	}
+
+	finishedAt := time.Now()
+	if err := s.persist(candidate, "policy_rollback", operator, func(tx *gorm.DB) error {
+		if err := tx.Model(&model.WafPolicyRevision{}).
+			Where("id = ?", rollout.CandidateRevisionID).
+			Update("status", wafPolicyStatusRolledBack).Error; err != nil {
+			return fmt.Errorf("更新版本状态失败: %w", err)
+		}
+		if _, err := createPolicyRevision(tx, policy, wafPolicyStatusRolledBack, candidate.Directives, "abort canary rollout", operator); err != nil {
+			return err
+		}
+		return s.finish(tx, rollout, wafRolloutStatusAborted, message, finishedAt)
+	}); err != nil {
+		return nil, err
+	}
+	return rollout, nil
+}
+
+// Evaluate 重新统计灰度指标并写回记录
+func (s *WafRolloutService) Evaluate(rollout *model.WafPolicyRollout) error {
+	if rollout == nil {
+		return fmt.Errorf("灰度发布不存在")
+	}
+	db := s.db()
+	var bindings []model.WafPolicyBinding
+	if err := db.Where("id IN ?", []int64(rollout.BindingIDs)).Find(&bindings).Error; err != nil {
+		return fmt.Errorf("查询灰度绑定失败: %w", err)
+	}
+	metrics, err := queryWafRolloutMetrics(db, rollout, bindings)
+	if err != nil {
+		return err
+	}
+
+	evaluatedAt := time.Now()
+	if err := db.Model(&model.WafPolicyRollout{}).Where("id = ?", rollout.ID).Updates(map[string]interface{}{
+		"request_count":       metrics.RequestCount,
+		"would_block_count":   metrics.WouldBlockCount,
+		"newly_blocked_count": metrics.NewlyBlockedCount,
+		"false_positive_rate": metrics.FalsePositiveRate,
+		"evaluated_at":        evaluatedAt,
+	}).Error; err != nil {
+		return fmt.Errorf("保存灰度评估结果失败: %w", err)
+	}
+	rollout.RequestCount = metrics.RequestCount
+	rollout.WouldBlockCount = metrics.WouldBlockCount
+	rollout.NewlyBlockedCount = metrics.NewlyBlockedCount
+	rollout.FalsePositiveRate = metrics.FalsePositiveRate
+	rollout.EvaluatedAt = &evaluatedAt
+	return nil
+}
+
+// EvaluateActive 评估所有进行中的灰度，满足条件的自动转正
+func (s *WafRolloutService) EvaluateActive() error {
+	var rollouts []model.WafPolicyRollout
+	if err := s.db().Where("status = ?", wafRolloutStatusCanary).Order("id asc").Find(&rollouts).Error; err != nil {
+		return fmt.Errorf("查询进行中的灰度发布失败: %w", err)
+	}
+	for i := range rollouts {
+		rollout := &rollouts[i]
+		if err := s.Evaluate(rollout); err != nil {
+			logx.Errorf("评估灰度发布失败: id=%d err=%v", rollout.ID, err)
+			continue
+		}
+		if !shouldAutoPromoteWafRollout(rollout, time.Now()) {
+			continue
+		}
+		message := fmt.Sprintf("观察 %d 小时，误报率 %.2f%% 不高于阈值 %.2f%%", rollout.ObserveHours, rollout.FalsePositiveRate, rollout.MaxFalsePositiveRate)
+		if _, err := s.Promote(rollout.ID, "system", message, true); err != nil {
+			logx.Errorf("灰度自动转正失败: id=%d err=%v", rollout.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *WafRolloutService) loadActive(rolloutID uint) (*model.WafPolicyRollout, *model.WafPolicy, error) {
+	if s == nil || s.svcCtx == nil || s.svcCtx.DB == nil {
+		return nil, nil, fmt.Errorf("数据库为空")
+	}
+	if rolloutID == 0 {
+		return nil, nil, fmt.Errorf("灰度发布 ID 不能为空")
+	}
+	var rollout model.WafPolicyRollout
+	if err := s.db().First(&rollout, rolloutID).Error; err != nil {
+		return nil, nil, fmt.Errorf("灰度发布不存在")
+	}
+	if normalizeWafRolloutStatus(rollout.Status) != wafRolloutStatusCanary {
+		return nil, nil, fmt.Errorf("灰度发布已结束")
+	}
+	var policy model.WafPolicy
+	if err := s.db().First(&policy, rollout.PolicyID).Error; err != nil {
+		return nil, nil, fmt.Errorf("策略不存在")
+	}
+	return &rollout, &policy, nil
+}
+
+func (s *WafRolloutService) buildCandidate(policy *model.WafPolicy, directives string) (*PolicyPublishCandidate, error) {
+	extras, err := loadWafPolicyCaddyExtras(s.db(), policy.ID)
+	if err != nil {
+		return nil, err
+	}
+	server, err := findPrimaryCaddyServer(s.db())
+	if err != nil {
+		return nil, err
+	}
+	candidateConfig, err := buildPolicyCandidateCaddyConfig(server.Config, directives, policy.Enabled, extras)
+	if err != nil {
+		return nil, err
+	}
+	return &PolicyPublishCandidate{
+		Policy:          policy,
+		Directives:      directives,
+		Server:          server,
+		CandidateConfig: candidateConfig,
+		LastGoodConfig:  server.Config,
+		LastGoodModules: normalizeCaddyModulesJSON(server.Modules),
+	}, nil
+}
+
+// persist 保存已加载的 Caddy 配置与配置历史，并在同一事务内写入灰度相关记录；失败时回滚到 last_good
+func (s *WafRolloutService) persist(candidate *PolicyPublishCandidate, action, operator string, record func(tx *gorm.DB) error) error {
+	modules := normalizeCaddyModulesJSON(candidate.Server.Modules)
+	if err := s.db().Transaction(func(tx *gorm.DB) error {
+		if err := createCaddyPolicyHistory(tx, candidate.Server.ID, "policy_last_good", candidate.LastGoodConfig, candidate.LastGoodModules, operator); err != nil {
+			return err
+		}
+		if err := tx.Model(&model.CaddyServer{}).
+			Where("id = ?", candidate.Server.ID).
+			Updates(map[string]interface{}{
+				"config":  candidate.CandidateConfig,
+				"modules": modules,
+			}).Error; err != nil {
+			return fmt.Errorf("保存 Caddy 服务器配置失败: %w", err)
+		}
+		if err := createCaddyPolicyHistory(tx, candidate.Server.ID, action, candidate.CandidateConfig, modules, operator); err != nil {
+			return err
+		}
+		return record(tx)
+	}); err != nil {
+		if rollbackErr := rollbackPolicyConfigToLastGood(candidate.Server, candidate.LastGoodConfig); rollbackErr != nil {
+			return fmt.Errorf("灰度发布持久化失败: %v，回滚到 last_good 失败: %v", err, rollbackErr)
+		}
+		return fmt.Errorf("灰度发布持久化失败: %w", err)
+	}
+	return nil
+}
+
+func (s *WafRolloutService) finish(tx *gorm.DB, rollout *model.WafPolicyRollout, status, message string, finishedAt time.Time) error {
+	updates := map[string]interface{}{
+		"status":      status,
+		"finished_at": finishedAt,
+	}
+	if trimmed := strings.TrimSpace(message); trimmed != "" {
+		updates["message"] = trimmed
+		rollout.Message = trimmed
+	}
+	if err := tx.Model(&model.WafPolicyRollout{}).Where("id = ?", rollout.ID).Updates(updates).Error; err != nil {
+		return fmt.Errorf("更新灰度发布状态失败: %w", err)
+	}
+	rollout.Status = status
+	rollout.FinishedAt = &finishedAt
+	return nil
+}
@@ -0,0 +1,280 @@
+package caddy
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"logflux/internal/types"
+	"logflux/model"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// 规则趋势只展示命中最多的前几条规则
+	wafRuleStatsTrendRules = 5
+	// 每条规则展示的高频路径数
+	wafRuleStatsTopPaths = 3
+	// 异常评分直方图桶宽与上限（超过上限的归入最后一个桶）
+	wafRuleStatsScoreBucketWidth = 5
+	wafRuleStatsScoreBucketMax   = 50
+)
+
+// CRS 评分汇总类规则（949/959 阈值判定、980 报告）每次拦截都会触发，不参与规则排行
+const wafRuleStatsEvaluationRuleSQL = "rule_id NOT BETWEEN 949000 AND 949999 AND rule_id NOT BETWEEN 959000 AND 959999 AND rule_id NOT BETWEEN 980000 AND 980999"
+
+const wafRuleStatsBlockedSQL = "COALESCE(SUM(CASE WHEN action = 'blocked' THEN 1 ELSE 0 END), 0)"
+
+type wafRuleStats struct {
+	TopRules  []types.WafPolicyStatsRuleItem
+	TopTags   []types.WafPolicyStatsDimensionItem
+	RuleTrend []types.WafPolicyStatsRuleTrendItem
+	Histogram []types.WafPolicyStatsScoreBucket
+}
+
+// queryWafRuleStats 基于 waf_audit_logs 统计规则、标签、规则趋势与异常评分分布
+func (l *GetWafPolicyStatsLogic) queryWafRuleStats(
+	startTime, endTime time.Time,
+	intervalSec int,
+	policyID uint,
+	threshold int64,
+	topN int,
+	drillFilter wafPolicyStatsDrillFilter,
+) (*wafRuleStats, error) {
+	base := func() *gorm.DB {
+		// 灰度实例的事件单独统计，不计入生效策略
+		db := l.svcCtx.DB.WithContext(l.ctx).Model(&model.WafAuditLog{}).Where("log_time BETWEEN ? AND ? AND rollout_id = 0", startTime, endTime)
+		db = applyWafPolicyStatsDrillFilter(db, drillFilter)
+		if policyID > 0 {
+			db = db.Where("policy_id = ?", policyID)
+		}
+		return db
+	}
+
+	stats := &wafRuleStats{
+		TopRules:  []types.WafPolicyStatsRuleItem{},
+		TopTags:   []types.WafPolicyStatsDimensionItem{},
+		RuleTrend: []types.WafPolicyStatsRuleTrendItem{},
+	}
+
+	type ruleRow struct {
+		RuleID       int64 `gorm:"column:rule_id"`
+		HitCount     int64 `gorm:"column:hit_count"`
+		BlockedCount int64 `gorm:"column:blocked_count"`
+	}
+	var ruleRows []ruleRow
+	if err := base().
+		Joins("CROSS JOIN LATERAL unnest(rule_ids) AS r(rule_id)").
+		Where(wafRuleStatsEvaluationRuleSQL).
+		Select("rule_id, COUNT(*) AS hit_count, " + wafRuleStatsBlockedSQL + " AS blocked_count").
+		Group("rule_id").
+		Order("hit_count DESC, rule_id ASC").
+		Limit(topN).
+		Scan(&ruleRows).Error; err != nil {
+		return nil, fmt.Errorf("查询规则命中排行失败: %w", err)
+	}
+
+	ruleIDs := make([]int64, 0, len(ruleRows))
+	for _, row := range ruleRows {
+		ruleIDs = append(ruleIDs, row.RuleID)
+	}
+
+	messages := map[int64]string{}
+	paths := map[int64][]string{}
+	if len(ruleIDs) > 0 {
+		type messageRow struct {
+			RuleID  string `gorm:"column:rule_id"`
+			Message string `gorm:"column:message"`
+		}
+		var messageRows []messageRow
+		if err := base().
+			Joins("CROSS JOIN LATERAL jsonb_array_elements(messages) AS m(item)").
+			Where("(m.item->>'ruleId')::bigint IN ?", ruleIDs).
+			Select("m.item->>'ruleId' AS rule_id, MAX(m.item->>'message') AS message").
+			Group("m.item->>'ruleId'").
+			Scan(&messageRows).Error; err != nil {
+			return nil, fmt.Errorf("查询规则描述失败: %w", err)
+		}
+		for _, row := range messageRows {
+			if id, err := strconv.ParseInt(row.RuleID, 10, 64); err == nil {
+				messages[id] = row.Message
+			}
+		}
+
+		type pathRow struct {
+			RuleID   int64  `gorm:"column:rule_id"`
+			Path     string `gorm:"column:path"`
+			HitCount int64  `gorm:"column:hit_count"`
+		}
+		var pathRows []pathRow
+		if err := base().
+			Joins("CROSS JOIN LATERAL unnest(rule_ids) AS r(rule_id)").
+			Where("rule_id IN ?", ruleIDs).
+			Select("rule_id, COALESCE(NULLIF(split_part(uri, chr(63), 1), ''), '/') AS path, COUNT(*) AS hit_count").
+			Group("rule_id, path").
+			Order("hit_count DESC, path ASC").
+			Scan(&pathRows).Error; err != nil {
+			return nil, fmt.Errorf("查询规则命中路径失败: %w", err)
+		}
+		for _, row := range pathRows {
+			if len(paths[row.RuleID]) < wafRuleStatsTopPaths {
+				paths[row.RuleID] = append(paths[row.RuleID], row.Path)
+			}
+		}
+	}
+
+	for _, row := range ruleRows {
+		topPaths := paths[row.RuleID]
+		if topPaths == nil {
+			topPaths = []string{}
+		}
+		stats.TopRules = append(stats.TopRules, types.WafPolicyStatsRuleItem{
+			RuleId:       row.RuleID,
+			Message:      messages[row.RuleID],
+			HitCount:     row.HitCount,
+			BlockedCount: row.BlockedCount,
+			TopPaths:     topPaths,
+		})
+	}
+
+	var tagRows []wafPolicyDimensionRow
+	if err := base().
+		Joins("CROSS JOIN LATERAL unnest(tags) AS t(tag)").
+		Where("tag <> 'anomaly-evaluation'").
+		Select("tag AS key, COUNT(*) AS hit_count, " + wafRuleStatsBlockedSQL + " AS blocked_count").
+		Group("tag").
+		Order("hit_count DESC, key ASC").
+		Limit(topN).
+		Scan(&tagRows).Error; err != nil {
+		return nil, fmt.Errorf("查询规则标签排行失败: %w", err)
+	}
+	for _, row := range tagRows {
+		stats.TopTags = append(stats.TopTags, types.WafPolicyStatsDimensionItem{
+			Key:          row.Key,
+			HitCount:     row.HitCount,
+			BlockedCount: row.BlockedCount,
+			AllowedCount: row.HitCount - row.BlockedCount,
+			BlockRate:    calcPolicyBlockRate(row.BlockedCount, row.HitCount),
+		})
+	}
+
+	trendIDs := ruleIDs
+	if len(trendIDs) > wafRuleStatsTrendRules {
+		trendIDs = trendIDs[:wafRuleStatsTrendRules]
+	}
+	if len(trendIDs) > 0 {
+		var trendRows []wafRuleTrendRow
+		if err := base().
+			Joins("CROSS JOIN LATERAL unnest(rule_ids) AS r(rule_id)").
+			Where("rule_id IN ?", trendIDs).
+			Select(fmt.Sprintf(
+				"rule_id, floor(extract(epoch from log_time) / %d) * %d AS bucket, COUNT(*) AS hit_count, %s AS blocked_count",
+				intervalSec, intervalSec, wafRuleStatsBlockedSQL,
+			)).
+			Group("rule_id, bucket").
+			Scan(&trendRows).Error; err != nil {
+			return nil, fmt.Errorf("查询规则命中趋势失败: %w", err)
+		}
+		stats.RuleTrend = buildWafRuleTrend(trendIDs, trendRows, startTime, endTime, intervalSec)
+	}
+
+	var scoreRows []wafScoreRow
+	if err := base().
+		Select("inbound_anomaly_score AS score, COUNT(*) AS hit_count, " + wafRuleStatsBlockedSQL + " AS blocked_count").
+		Group("inbound_anomaly_score").
+		Scan(&scoreRows).Error; err != nil {
+		return nil, fmt.Errorf("查询异常评分分布失败: %w", err)
+	}
+	stats.Histogram = buildWafScoreHistogram(scoreRows, threshold)
+
+	return stats, nil
+}
+
+type wafRuleTrendRow struct {
+	RuleID       int64 `gorm:"column:rule_id"`
+	Bucket       int64 `gorm:"column:bucket"`
+	HitCount     int64 `gorm:"column:hit_count"`
+	BlockedCount int64 `gorm:"column:blocked_count"`
+}
+
+type wafScoreRow struct {
+	Score        int   `gorm:"column:score"`
+	HitCount     int64 `gorm:"column:hit_count"`
+	BlockedCount int64 `gorm:"column:blocked_count"`
+}
+
+// buildWafRuleTrend 按规则补齐时间桶，保持与策略趋势相同的时间轴
+func buildWafRuleTrend(ruleIDs []int64, rows []wafRuleTrendRow, startTime, endTime time.Time, intervalSec int) []types.WafPolicyStatsRuleTrendItem {
+	byRule := make(map[int64]map[int64]wafRuleTrendRow, len(ruleIDs))
+	for _, row := range rows {
+		if byRule[row.RuleID] == nil {
+			byRule[row.RuleID] = make(map[int64]wafRuleTrendRow)
+		}
+		byRule[row.RuleID][row.Bucket] = row
+	}
+
+	step := int64(intervalSec)
+	startBucket := (startTime.Unix() / step) * step
+	endBucket := (endTime.Unix() / step) * step
+
+	result := make([]types.WafPolicyStatsRuleTrendItem, 0, len(ruleIDs))
+	for _, ruleID := range ruleIDs {
+		points := make([]types.WafPolicyStatsTrendItem, 0, ((endBucket-startBucket)/step)+1)
+		for bucket := startBucket; bucket <= endBucket; bucket += step {
+			row := byRule[ruleID][bucket]
+			points = append(points, types.WafPolicyStatsTrendItem{
+				Time:         time.Unix(bucket, 0).Format("01-02 15:04"),
+				HitCount:     row.HitCount,
+				BlockedCount: row.BlockedCount,
+				AllowedCount: row.HitCount - row.BlockedCount,
+			})
+		}
+		result = append(result, types.WafPolicyStatsRuleTrendItem{RuleId: ruleID, Points: points})
+	}
+	return result
+}
+
+// buildWafScoreHistogram 将入站异常评分按固定桶宽分组，并标记达到阈值的桶
+func buildWafScoreHistogram(rows []wafScoreRow, threshold int64) []types.WafPolicyStatsScoreBucket {
+	buckets := make([]types.WafPolicyStatsScoreBucket, 0, wafRuleStatsScoreBucketMax/wafRuleStatsScoreBucketWidth+1)
+	for lower := 0; lower < wafRuleStatsScoreBucketMax; lower += wafRuleStatsScoreBucketWidth {
+		upper := lower + wafRuleStatsScoreBucketWidth - 1
+		buckets = append(buckets, types.WafPolicyStatsScoreBucket{
+			Label:          fmt.Sprintf("%d-%d", lower, upper),
+			Min:            lower,
+			Max:            upper,
+			AboveThreshold: threshold > 0 && int64(lower) >= threshold,
+		})
+	}
+	buckets = append(buckets, types.WafPolicyStatsScoreBucket{
+		Label:          fmt.Sprintf("%d+", wafRuleStatsScoreBucketMax),
+		Min:            wafRuleStatsScoreBucketMax,
+		Max:            -1,
+		AboveThreshold: threshold > 0 && int64(wafRuleStatsScoreBucketMax) >= threshold,
+	})
+
+	for _, row := range rows {
+		score := row.Score
+		if score < 0 {
+			score = 0
+		}
+		index := score / wafRuleStatsScoreBucketWidth
+		if index >= len(buckets) {
+			index = len(buckets) - 1
+		}
+		buckets[index].Count += row.HitCount
+		buckets[index].BlockedCount += row.BlockedCount
+	}
+	return buckets
+}
+
+// wafRuleStatsThreshold 选取用于直方图对比的入站阈值：指定策略取自身，否则取默认策略
+func wafRuleStatsThreshold(policies []model.WafPolicy, policyID uint) int64 {
+	for _, policy := range policies {
+		if policyID == 0 || policy.ID == policyID {
+			return policy.CrsInboundAnomalyThreshold
+		}
+	}
+	return 0
+}
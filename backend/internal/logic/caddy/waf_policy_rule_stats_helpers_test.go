@@ -0,0 +1,73 @@
+package caddy
+
+import (
+	"testing"
+	"time"
+
+	"logflux/model"
+)
+
+func TestBuildWafScoreHistogram(t *testing.T) {
+	rows := []wafScoreRow{
+		{Score: 0, HitCount: 4},
+		{Score: 3, HitCount: 1},
+		{Score: 10, HitCount: 6, BlockedCount: 6},
+		{Score: 12, HitCount: 2, BlockedCount: 2},
+		{Score: 75, HitCount: 1, BlockedCount: 1},
+	}
+
+	buckets := buildWafScoreHistogram(rows, 10)
+	if len(buckets) != wafRuleStatsScoreBucketMax/wafRuleStatsScoreBucketWidth+1 {
+		t.Fatalf("unexpected bucket count: %d", len(buckets))
+	}
+	if buckets[0].Label != "0-4" || buckets[0].Count != 5 || buckets[0].AboveThreshold {
+		t.Fatalf("unexpected first bucket: %+v", buckets[0])
+	}
+	if buckets[1].AboveThreshold || !buckets[2].AboveThreshold {
+		t.Fatalf("threshold flag misplaced: %+v %+v", buckets[1], buckets[2])
+	}
+	if buckets[2].Count != 8 || buckets[2].BlockedCount != 8 {
+		t.Fatalf("unexpected 10-14 bucket: %+v", buckets[2])
+	}
+	last := buckets[len(buckets)-1]
+	if last.Label != "50+" || last.Max != -1 || last.Count != 1 {
+		t.Fatalf("unexpected overflow bucket: %+v", last)
+	}
+}
+
+func TestBuildWafRuleTrend(t *testing.T) {
+	start := time.Unix(3600, 0)
+	end := time.Unix(3600+900, 0)
+	rows := []wafRuleTrendRow{
+		{RuleID: 942100, Bucket: 3600 + 300, HitCount: 3, BlockedCount: 1},
+	}
+
+	trend := buildWafRuleTrend([]int64{942100, 941100}, rows, start, end, 300)
+	if len(trend) != 2 || trend[0].RuleId != 942100 || trend[1].RuleId != 941100 {
+		t.Fatalf("unexpected trend rules: %+v", trend)
+	}
+	if len(trend[0].Points) != 4 {
+		t.Fatalf("expected 4 points, got %d", len(trend[0].Points))
+	}
+	if p := trend[0].Points[1]; p.HitCount != 3 || p.BlockedCount != 1 || p.AllowedCount != 2 {
+		t.Fatalf("unexpected bucket point: %+v", p)
+	}
+	for _, p := range trend[1].Points {
+		if p.HitCount != 0 {
+			t.Fatalf("expected empty series for rule without hits: %+v", trend[1])
+		}
+	}
+}
+
+func TestWafRuleStatsThreshold(t *testing.T) {
+	policies := []model.WafPolicy{
+		{ID: 1, IsDefault: true, CrsInboundAnomalyThreshold: 10},
+		{ID: 2, CrsInboundAnomalyThreshold: 5},
+	}
+	if got := wafRuleStatsThreshold(policies, 0); got != 10 {
+		t.Fatalf("expected default policy threshold, got %d", got)
+	}
+	if got := wafRuleStatsThreshold(policies, 2); got != 5 {
+		t.Fatalf("expected selected policy threshold, got %d", got)
+	}
+}
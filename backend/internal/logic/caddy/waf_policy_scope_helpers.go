@@ -2,7 +2,9 @@ package caddy
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
+	"time"
 
 	"logflux/model"
 
@@ -14,8 +16,10 @@ const (
 	wafPolicyScopeTypeSite   = "site"
 	wafPolicyScopeTypeRoute  = "route"
 
-	wafPolicyRemoveTypeID  = "id"
-	wafPolicyRemoveTypeTag = "tag"
+	wafPolicyRemoveTypeID        = "id"
+	wafPolicyRemoveTypeTag       = "tag"
+	wafPolicyRemoveTypeTargetID  = "target_id"
+	wafPolicyRemoveTypeTargetTag = "target_tag"
 
 	wafPolicyBindingDefaultPriority int64 = 100
 	wafPolicyBindingMinPriority     int64 = 1
@@ -49,13 +53,93 @@ func normalizePolicyRemoveType(removeType string) string {
 
 func validatePolicyRemoveType(removeType string) error {
 	switch normalizePolicyRemoveType(removeType) {
-	case wafPolicyRemoveTypeID, wafPolicyRemoveTypeTag:
+	case wafPolicyRemoveTypeID, wafPolicyRemoveTypeTag, wafPolicyRemoveTypeTargetID, wafPolicyRemoveTypeTargetTag:
 		return nil
 	default:
 		return fmt.Errorf("策略移除类型无效: %s", removeType)
 	}
 }
 
+// normalizeAndValidateExclusionRemoveFields 校验移除类型、移除值与检查目标；非目标类型会清空检查目标
+func normalizeAndValidateExclusionRemoveFields(removeType, removeValue, removeTarget string) (string, string, string, error) {
+	normalizedRemoveType := normalizePolicyRemoveType(removeType)
+	if err := validatePolicyRemoveType(normalizedRemoveType); err != nil {
+		return "", "", "", err
+	}
+	normalizedRemoveValue := strings.TrimSpace(removeValue)
+	if normalizedRemoveValue == "" {
+		return "", "", "", fmt.Errorf("移除值不能为空")
+	}
+
+	switch normalizedRemoveType {
+	case wafPolicyRemoveTypeTargetID:
+		if !wafExclusionRuleIDPattern.MatchString(normalizedRemoveValue) {
+			return "", "", "", fmt.Errorf("按目标移除时规则 ID 格式不合法: %s", normalizedRemoveValue)
+		}
+	case wafPolicyRemoveTypeTargetTag:
+		if strings.ContainsAny(normalizedRemoveValue, wafExclusionForbiddenChars) {
+			return "", "", "", fmt.Errorf("按目标移除时标签格式不合法: %s", normalizedRemoveValue)
+		}
+	default:
+		return normalizedRemoveType, normalizedRemoveValue, "", nil
+	}
+
+	normalizedRemoveTarget, err := normalizeWafExclusionTarget(removeTarget)
+	if err != nil {
+		return "", "", "", err
+	}
+	return normalizedRemoveType, normalizedRemoveValue, normalizedRemoveTarget, nil
+}
+
+// 目标排除支持的集合；REQUEST_HEADERS 的键大小写不敏感
+var wafExclusionTargetCollections = map[string]bool{
+	"ARGS":            true,
+	"ARGS_GET":        true,
+	"ARGS_POST":       true,
+	"REQUEST_HEADERS": true,
+	"REQUEST_COOKIES": true,
+}
+
+var wafExclusionRuleIDPattern = regexp.MustCompile(`^\d+(-\d+)?$`)
+
+// 出现在 ctl 动作或指令引号中会破坏语法的字符
+const wafExclusionForbiddenChars = "\"'\n\r\t ,;|"
+
+// normalizeWafExclusionTarget 校验检查目标，格式为 COLLECTION:key 或 COLLECTION:/regex/
+func normalizeWafExclusionTarget(target string) (string, error) {
+	trimmed := strings.TrimSpace(target)
+	if trimmed == "" {
+		return "", fmt.Errorf("按目标移除时必须填写检查目标")
+	}
+	collection, key, ok := strings.Cut(trimmed, ":")
+	collection = strings.ToUpper(strings.TrimSpace(collection))
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return "", fmt.Errorf("检查目标必须指定键名，例如 ARGS:content")
+	}
+	if !wafExclusionTargetCollections[collection] {
+		return "", fmt.Errorf("检查目标集合不支持: %s（仅支持 ARGS、ARGS_GET、ARGS_POST、REQUEST_HEADERS、REQUEST_COOKIES）", collection)
+	}
+	if strings.ContainsAny(key, wafExclusionForbiddenChars) {
+		return "", fmt.Errorf("检查目标键名包含非法字符: %s", key)
+	}
+
+	if strings.HasPrefix(key, "/") {
+		if len(key) < 3 || !strings.HasSuffix(key, "/") {
+			return "", fmt.Errorf("检查目标正则键名需以 / 包裹: %s", key)
+		}
+		if _, err := regexp.Compile(key[1 : len(key)-1]); err != nil {
+			return "", fmt.Errorf("检查目标正则键名无效: %w", err)
+		}
+		return collection + ":" + key, nil
+	}
+
+	if collection == "REQUEST_HEADERS" {
+		key = strings.ToLower(key)
+	}
+	return collection + ":" + key, nil
+}
+
 func normalizePolicyHTTPMethod(method string) string {
 	return strings.ToUpper(strings.TrimSpace(method))
 }
@@ -196,14 +280,14 @@ func buildWafRuleExclusionDirectives(exclusions []model.WafRuleExclusion) (strin
 			continue
 		}
 
-		removeType := normalizePolicyRemoveType(exclusion.RemoveType)
-		if err := validatePolicyRemoveType(removeType); err != nil {
+		removeType, removeValue, removeTarget, err := normalizeAndValidateExclusionRemoveFields(
+			exclusion.RemoveType,
+			exclusion.RemoveValue,
+			exclusion.RemoveTarget,
+		)
+		if err != nil {
 			return "", err
 		}
-		removeValue := strings.TrimSpace(exclusion.RemoveValue)
-		if removeValue == "" {
-			return "", fmt.Errorf("移除值不能为空")
-		}
 
 		scopeType, host, path, method, err := normalizeAndValidateExclusionScopeFields(
 			exclusion.ScopeType,
@@ -217,13 +301,18 @@ func buildWafRuleExclusionDirectives(exclusions []model.WafRuleExclusion) (strin
 
 		switch scopeType {
 		case wafPolicyScopeTypeGlobal:
-			if removeType == wafPolicyRemoveTypeID {
+			switch removeType {
+			case wafPolicyRemoveTypeID:
 				lines = append(lines, fmt.Sprintf("SecRuleRemoveById %s", removeValue))
-			} else {
+			case wafPolicyRemoveTypeTag:
 				lines = append(lines, fmt.Sprintf("SecRuleRemoveByTag %s", removeValue))
+			case wafPolicyRemoveTypeTargetID:
+				lines = append(lines, fmt.Sprintf(`SecRuleUpdateTargetById %s "!%s"`, removeValue, removeTarget))
+			case wafPolicyRemoveTypeTargetTag:
+				lines = append(lines, fmt.Sprintf(`SecRuleUpdateTargetByTag %s "!%s"`, removeValue, removeTarget))
 			}
 		case wafPolicyScopeTypeSite, wafPolicyScopeTypeRoute:
-			scopedLines, err := buildScopedWafRuleExclusionDirectives(ruleID, host, path, method, removeType, removeValue)
+			scopedLines, err := buildScopedWafRuleExclusionDirectives(ruleID, host, path, method, removeType, removeValue, removeTarget)
 			if err != nil {
 				return "", err
 			}
@@ -239,23 +328,17 @@ func buildWafRuleExclusionDirectives(exclusions []model.WafRuleExclusion) (strin
 
 func buildScopedWafRuleExclusionDirectives(
 	ruleID int64,
-	host, path, method, removeType, removeValue string,
+	host, path, method, removeType, removeValue, removeTarget string,
 ) ([]string, error) {
-	type matcher struct {
-		Variable string
-		Operator string
-		Value    string
-	}
-
-	matchers := make([]matcher, 0, 3)
+	matchers := make([]wafDirectiveMatcher, 0, 3)
 	if host != "" {
-		matchers = append(matchers, matcher{Variable: "REQUEST_HEADERS:Host", Operator: "@streq", Value: host})
+		matchers = append(matchers, wafDirectiveMatcher{Variable: "REQUEST_HEADERS:Host", Operator: "@streq", Value: host})
 	}
 	if path != "" {
-		matchers = append(matchers, matcher{Variable: "REQUEST_URI", Operator: "@beginsWith", Value: path})
+		matchers = append(matchers, wafDirectiveMatcher{Variable: "REQUEST_URI", Operator: "@beginsWith", Value: path})
 	}
 	if method != "" {
-		matchers = append(matchers, matcher{Variable: "REQUEST_METHOD", Operator: "@streq", Value: method})
+		matchers = append(matchers, wafDirectiveMatcher{Variable: "REQUEST_METHOD", Operator: "@streq", Value: method})
 	}
 
 	if len(matchers) == 0 {
@@ -263,55 +346,61 @@ func buildScopedWafRuleExclusionDirectives(
 	}
 
 	controlAction := "ctl:ruleRemoveById=" + removeValue
-	if removeType == wafPolicyRemoveTypeTag {
+	switch removeType {
+	case wafPolicyRemoveTypeTag:
 		controlAction = "ctl:ruleRemoveByTag=" + removeValue
+	case wafPolicyRemoveTypeTargetID:
+		controlAction = "ctl:ruleRemoveTargetById=" + removeValue + ";" + removeTarget
+	case wafPolicyRemoveTypeTargetTag:
+		controlAction = "ctl:ruleRemoveTargetByTag=" + removeValue + ";" + removeTarget
 	}
 
-	lines := make([]string, 0, len(matchers))
-	for idx, currentMatcher := range matchers {
-		actions := []string{}
-		if idx == 0 {
-			actions = append(actions, fmt.Sprintf("id:%d", ruleID), "phase:1", "pass", "nolog", "t:none")
-		}
-		if idx < len(matchers)-1 {
-			actions = append(actions, "chain")
-		} else {
-			actions = append(actions, controlAction)
-		}
-		lines = append(lines, fmt.Sprintf(
-			`SecRule %s "%s %s" "%s"`,
-			currentMatcher.Variable,
-			currentMatcher.Operator,
-			strings.ReplaceAll(currentMatcher.Value, `"`, `\"`),
-			strings.Join(actions, ","),
-		))
-	}
-
-	return lines, nil
+	headActions := []string{fmt.Sprintf("id:%d", ruleID), "phase:1", "pass", "nolog", "t:none"}
+	return renderWafChainedRule(matchers, headActions, []string{controlAction}), nil
 }
 
-func buildPolicyDirectivesWithExclusions(db *gorm.DB, policy *model.WafPolicy) (string, error) {
-	baseDirectives, err := buildWafPolicyDirectives(policy)
+// buildPolicyDirectivesWithExclusions 生成策略指令（含已启用的自定义规则），再追加 IP 名单与已启用的排除规则；extra 为尚未落库的候选排除规则（用于预览）
+func buildPolicyDirectivesWithExclusions(db *gorm.DB, policy *model.WafPolicy, extra ...model.WafRuleExclusion) (string, error) {
+	if db == nil || policy == nil || policy.ID == 0 {
+		return buildWafPolicyDirectives(policy)
+	}
+
+	customRules, err := loadEnabledWafCustomRules(db, policy.ID)
 	if err != nil {
 		return "", err
 	}
-	if db == nil || policy == nil || policy.ID == 0 {
-		return baseDirectives, nil
+	baseDirectives, err := buildWafPolicyDirectives(policy, customRules...)
+	if err != nil {
+		return "", err
 	}
 
 	var exclusions []model.WafRuleExclusion
 	if err := db.Where("policy_id = ? AND enabled = ?", policy.ID, true).Order("id asc").Find(&exclusions).Error; err != nil {
 		return "", fmt.Errorf("查询策略排除规则失败: %w", err)
 	}
+	exclusions = append(exclusions, extra...)
 
 	exclusionDirectives, err := buildWafRuleExclusionDirectives(exclusions)
 	if err != nil {
 		return "", err
 	}
-	if strings.TrimSpace(exclusionDirectives) == "" {
-		return baseDirectives, nil
+
+	ipListSet, err := loadWafIPListRenderSet(db, policy.ID, "", time.Now())
+	if err != nil {
+		return "", err
+	}
+	ipListDirectives, err := buildWafIPListDirectives(ipListSet)
+	if err != nil {
+		return "", err
+	}
+
+	blocks := []string{strings.TrimSpace(baseDirectives)}
+	for _, block := range []string{ipListDirectives, exclusionDirectives} {
+		if trimmed := strings.TrimSpace(block); trimmed != "" {
+			blocks = append(blocks, trimmed)
+		}
 	}
-	return strings.TrimSpace(baseDirectives) + "\n" + strings.TrimSpace(exclusionDirectives), nil
+	return strings.Join(blocks, "\n"), nil
 }
 
 func normalizeBindingScopeKey(scopeType, host, path, method string, priority int64) string {
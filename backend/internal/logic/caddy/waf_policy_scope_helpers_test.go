@@ -1,6 +1,8 @@
 package caddy
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -8,6 +10,7 @@ import (
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 
+	"logflux/internal/waf"
 	"logflux/model"
 )
 
@@ -50,6 +53,93 @@ func TestBuildWafRuleExclusionDirectives(t *testing.T) {
 	}
 }
 
+func TestBuildWafRuleExclusionDirectivesTargets(t *testing.T) {
+	exclusions := []model.WafRuleExclusion{
+		{
+			Enabled:      true,
+			ScopeType:    "route",
+			Path:         "/api/posts",
+			Method:       "POST",
+			RemoveType:   "target_id",
+			RemoveValue:  "942100",
+			RemoveTarget: "args:content",
+		},
+		{
+			Enabled:      true,
+			ScopeType:    "site",
+			Host:         "app.example.com",
+			RemoveType:   "target_tag",
+			RemoveValue:  "attack-xss",
+			RemoveTarget: "REQUEST_HEADERS:User-Agent",
+		},
+		{
+			Enabled:      true,
+			ScopeType:    "global",
+			RemoveType:   "target_id",
+			RemoveValue:  "942100-942199",
+			RemoveTarget: `REQUEST_COOKIES:/^json\./`,
+		},
+		{
+			Enabled:      true,
+			ScopeType:    "global",
+			RemoveType:   "target_tag",
+			RemoveValue:  "attack-sqli",
+			RemoveTarget: "ARGS_POST:body",
+		},
+	}
+
+	directives, err := buildWafRuleExclusionDirectives(exclusions)
+	if err != nil {
+		t.Fatalf("buildWafRuleExclusionDirectives() error = %v", err)
+	}
+
+	expectedFragments := []string{
+		`REQUEST_URI "@beginsWith /api/posts"`,
+		"ctl:ruleRemoveTargetById=942100;ARGS:content",
+		`REQUEST_HEADERS:Host "@streq app.example.com"`,
+		"ctl:ruleRemoveTargetByTag=attack-xss;REQUEST_HEADERS:user-agent",
+		`SecRuleUpdateTargetById 942100-942199 "!REQUEST_COOKIES:/^json\./"`,
+		`SecRuleUpdateTargetByTag attack-sqli "!ARGS_POST:body"`,
+	}
+	for _, fragment := range expectedFragments {
+		if !strings.Contains(directives, fragment) {
+			t.Fatalf("expected directives contains %q, got: %s", fragment, directives)
+		}
+	}
+}
+
+func TestNormalizeAndValidateExclusionRemoveFields(t *testing.T) {
+	removeType, removeValue, removeTarget, err := normalizeAndValidateExclusionRemoveFields("tag", "attack-sqli", "ARGS:q")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if removeType != "tag" || removeValue != "attack-sqli" || removeTarget != "" {
+		t.Fatalf("unexpected normalized fields: %s %s %q", removeType, removeValue, removeTarget)
+	}
+
+	invalid := []struct {
+		name        string
+		removeType  string
+		removeValue string
+		target      string
+	}{
+		{"missing target", "target_id", "942100", ""},
+		{"missing key", "target_id", "942100", "ARGS"},
+		{"unsupported collection", "target_id", "942100", "REQUEST_BODY:x"},
+		{"non numeric id", "target_id", "attack-sqli", "ARGS:q"},
+		{"tag with separator", "target_tag", "attack;sqli", "ARGS:q"},
+		{"key with quote", "target_id", "942100", `ARGS:a"b`},
+		{"unterminated regex", "target_tag", "attack-sqli", "ARGS:/^json"},
+		{"invalid regex", "target_tag", "attack-sqli", "ARGS:/(json/"},
+		{"unknown type", "target", "942100", "ARGS:q"},
+	}
+	for _, tc := range invalid {
+		if _, _, _, err := normalizeAndValidateExclusionRemoveFields(tc.removeType, tc.removeValue, tc.target); err == nil {
+			t.Fatalf("%s: expected validation error", tc.name)
+		}
+	}
+}
+
 func TestEnsureNoPolicyBindingConflictsNoConflict(t *testing.T) {
 	db, mock, cleanup := newPolicyScopeMockDB(t)
 	defer cleanup()
@@ -106,3 +196,86 @@ func newPolicyScopeMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock, func()) {
 	}
 	return gdb, mock, cleanup
 }
+
+func TestComposeWafRuleExclusionDirectivesAfterCRS(t *testing.T) {
+	rulesDir := filepath.Join(t.TempDir(), "rules")
+	if err := os.MkdirAll(rulesDir, 0o755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+	setupFile := filepath.Join(filepath.Dir(rulesDir), "crs-setup.conf.example")
+	if err := os.WriteFile(setupFile, []byte("SecRuleEngine On\n"), 0o644); err != nil {
+		t.Fatalf("write setup failed: %v", err)
+	}
+	rules := strings.Join([]string{
+		`SecRule ARGS "@contains attack" "id:942100,phase:2,deny,status:403,log,tag:'attack-sqli'"`,
+		`SecRule REQUEST_HEADERS "@contains evil" "id:941100,phase:1,deny,status:403,log,tag:'attack-xss'"`,
+	}, "\n")
+	if err := os.WriteFile(filepath.Join(rulesDir, "REQUEST-942-TEST.conf"), []byte(rules), 0o644); err != nil {
+		t.Fatalf("write rules failed: %v", err)
+	}
+
+	exclusionDirectives, err := buildWafRuleExclusionDirectives([]model.WafRuleExclusion{
+		{Enabled: true, ScopeType: "global", RemoveType: "target_id", RemoveValue: "942100", RemoveTarget: "ARGS:content"},
+		{Enabled: true, ScopeType: "global", RemoveType: "target_tag", RemoveValue: "attack-xss", RemoveTarget: "REQUEST_HEADERS:User-Agent"},
+	})
+	if err != nil {
+		t.Fatalf("buildWafRuleExclusionDirectives() error = %v", err)
+	}
+
+	composed := composeManagedCorazaDirectives("SecRuleEngine On\n"+exclusionDirectives, "/dev/null")
+	if strings.Index(composed, "SecRuleUpdateTargetById") < strings.Index(composed, "Include @owasp_crs/*.conf") {
+		t.Fatalf("expected target exclusions after CRS include, got: %s", composed)
+	}
+
+	engine, err := buildWafReplayEngine("SecRuleEngine On\n"+exclusionDirectives, &waf.CRSLayout{SetupFile: setupFile, RulesDir: rulesDir})
+	if err != nil {
+		t.Fatalf("buildWafReplayEngine() error = %v", err)
+	}
+	if verdict := engine.Evaluate(waf.ReplayRequest{URI: "/posts?content=attack"}); verdict.Blocked {
+		t.Fatalf("expected excluded ARGS:content to pass, got %+v", verdict)
+	}
+	if verdict := engine.Evaluate(waf.ReplayRequest{URI: "/posts?title=attack"}); !verdict.Blocked || verdict.InterruptRuleID != 942100 {
+		t.Fatalf("expected other args still blocked by 942100, got %+v", verdict)
+	}
+	if verdict := engine.Evaluate(waf.ReplayRequest{URI: "/", Headers: map[string][]string{"User-Agent": {"evil"}}}); verdict.Blocked {
+		t.Fatalf("expected excluded User-Agent to pass, got %+v", verdict)
+	}
+	if verdict := engine.Evaluate(waf.ReplayRequest{URI: "/", Headers: map[string][]string{"Referer": {"evil"}}}); !verdict.Blocked || verdict.InterruptRuleID != 941100 {
+		t.Fatalf("expected other headers still blocked by 941100, got %+v", verdict)
+	}
+}
+
+func TestApplyWafPolicyToUnmanagedConfigEmitsExclusionsAfterCRS(t *testing.T) {
+	current := "example.com {\n  coraza_waf {\n    load_owasp_crs\n    directives `\n      Include @coraza.conf-recommended\n      Include @crs-setup.conf.example\n      SecAuditLog /var/log/caddy/waf.log\n      SecRuleEngine Off\n      Include @owasp_crs/*.conf\n    `\n  }\n}\n"
+	exclusionDirectives, err := buildWafRuleExclusionDirectives([]model.WafRuleExclusion{
+		{Enabled: true, ScopeType: "global", RemoveType: "id", RemoveValue: "920350"},
+		{Enabled: true, ScopeType: "global", RemoveType: "target_id", RemoveValue: "942100", RemoveTarget: "ARGS:content"},
+	})
+	if err != nil {
+		t.Fatalf("buildWafRuleExclusionDirectives() error = %v", err)
+	}
+
+	patched, err := applyWafPolicyToCaddyConfig(current, "SecRuleEngine On\n"+exclusionDirectives)
+	if err != nil {
+		t.Fatalf("applyWafPolicyToCaddyConfig() error = %v", err)
+	}
+	order := []string{
+		"Include @coraza.conf-recommended",
+		"SecAuditLog /var/log/caddy/waf.log",
+		"SecRuleEngine On",
+		"Include @owasp_crs/*.conf",
+		"SecRuleRemoveById 920350",
+		`SecRuleUpdateTargetById 942100 "!ARGS:content"`,
+	}
+	last := -1
+	for _, fragment := range order {
+		index := strings.Index(patched, fragment)
+		if index <= last {
+			t.Fatalf("expected %q after previous directives, got:\n%s", fragment, patched)
+		}
+		last = index
+	}
+	if strings.Contains(patched, "SecRuleEngine Off") {
+		t.Fatalf("expected policy directives to replace the previous ones, got:\n%s", patched)
+	}
+}
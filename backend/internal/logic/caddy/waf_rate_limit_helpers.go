@@ -0,0 +1,298 @@
+package caddy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"logflux/internal/types"
+	"logflux/model"
+
+	"gorm.io/gorm"
+)
+
+const (
+	wafRateLimitKeyIP     = "ip"
+	wafRateLimitKeyHeader = "header"
+	wafRateLimitKeyPath   = "path"
+
+	wafRateLimitMaxWindowSeconds int64 = 24 * 60 * 60
+	wafRateLimitMaxEvents        int64 = 1000000
+
+	wafRateLimitValidateSiteAddress = ":0"
+)
+
+var (
+	regexWafRateLimitZoneName = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+	regexWafRateLimitHeader   = regexp.MustCompile(`^[A-Za-z0-9-]{1,64}$`)
+)
+
+func normalizeWafRateLimitZoneName(name string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(name))
+	if !regexWafRateLimitZoneName.MatchString(normalized) {
+		return "", fmt.Errorf("限流区域名称需以字母开头，仅包含小写字母、数字与下划线，最长 40 个字符")
+	}
+	return normalized, nil
+}
+
+func normalizeWafRateLimitKey(keyType, header string) (string, string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(keyType))
+	switch normalized {
+	case "", wafRateLimitKeyIP:
+		return wafRateLimitKeyIP, "", nil
+	case wafRateLimitKeyPath:
+		return wafRateLimitKeyPath, "", nil
+	case wafRateLimitKeyHeader:
+		trimmed := strings.TrimSpace(header)
+		if !regexWafRateLimitHeader.MatchString(trimmed) {
+			return "", "", fmt.Errorf("限流请求头名称不合法: %s", header)
+		}
+		return wafRateLimitKeyHeader, trimmed, nil
+	default:
+		return "", "", fmt.Errorf("限流键类型无效: %s", keyType)
+	}
+}
+
+func validateWafRateLimitBudget(events, windowSeconds, burst int64) error {
+	if events < 1 || events > wafRateLimitMaxEvents {
+		return fmt.Errorf("窗口内请求数必须在 1-%d 之间", wafRateLimitMaxEvents)
+	}
+	if windowSeconds < 1 || windowSeconds > wafRateLimitMaxWindowSeconds {
+		return fmt.Errorf("限流窗口必须在 1-%d 秒之间", wafRateLimitMaxWindowSeconds)
+	}
+	if burst < 0 || burst > events {
+		return fmt.Errorf("突发余量必须在 0-%d 之间", events)
+	}
+	return nil
+}
+
+// wafRateLimitZoneInput 汇总创建、更新与校验限流区域时的公共入参
+type wafRateLimitZoneInput struct {
+	PolicyID      uint
+	BindingID     uint
+	Name          string
+	Description   string
+	Enabled       bool
+	KeyType       string
+	KeyHeader     string
+	Events        int64
+	WindowSeconds int64
+	Burst         int64
+}
+
+// normalizeWafRateLimitZoneInput 校验入参并返回待保存的区域及其绑定（作用于整个策略时绑定为空）
+func normalizeWafRateLimitZoneInput(db *gorm.DB, input wafRateLimitZoneInput) (*model.WafRateLimitZone, *model.WafPolicyBinding, error) {
+	name, err := normalizeWafRateLimitZoneName(input.Name)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyType, keyHeader, err := normalizeWafRateLimitKey(input.KeyType, input.KeyHeader)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := validateWafRateLimitBudget(input.Events, input.WindowSeconds, input.Burst); err != nil {
+		return nil, nil, err
+	}
+	if err := validatePolicyIDExists(db, input.PolicyID); err != nil {
+		return nil, nil, err
+	}
+	if err := validateWafIPListBinding(db, input.PolicyID, input.BindingID); err != nil {
+		return nil, nil, err
+	}
+
+	var binding *model.WafPolicyBinding
+	if input.BindingID > 0 {
+		var row model.WafPolicyBinding
+		if err := db.First(&row, input.BindingID).Error; err != nil {
+			return nil, nil, fmt.Errorf("策略绑定不存在")
+		}
+		binding = &row
+	}
+	if _, err := wafRateLimitBindingMatchers(binding); err != nil {
+		return nil, nil, err
+	}
+
+	return &model.WafRateLimitZone{
+		PolicyID:      input.PolicyID,
+		BindingID:     input.BindingID,
+		Name:          name,
+		Description:   strings.TrimSpace(input.Description),
+		Enabled:       input.Enabled,
+		KeyType:       keyType,
+		KeyHeader:     keyHeader,
+		Events:        input.Events,
+		WindowSeconds: input.WindowSeconds,
+		Burst:         input.Burst,
+	}, binding, nil
+}
+
+// ensureWafRateLimitZoneNameUnique zone 名称在 Caddy 全局共享，需跨策略唯一
+func ensureWafRateLimitZoneNameUnique(db *gorm.DB, name string, excludeID uint) error {
+	query := db.Model(&model.WafRateLimitZone{}).Where("name = ?", name)
+	if excludeID > 0 {
+		query = query.Where("id <> ?", excludeID)
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return fmt.Errorf("查询限流区域失败: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("限流区域名称已存在: %s", name)
+	}
+	return nil
+}
+
+// wafRateLimitKeyPlaceholder 返回 caddy-ratelimit 的 key 占位符；IP 使用 client_ip 以兼容可信代理
+func wafRateLimitKeyPlaceholder(keyType, header string) string {
+	switch keyType {
+	case wafRateLimitKeyHeader:
+		return "{http.request.header." + header + "}"
+	case wafRateLimitKeyPath:
+		return "{http.request.uri.path}"
+	default:
+		return "{http.vars.client_ip}"
+	}
+}
+
+// wafRateLimitBindingMatchers 将绑定作用域转换为 Caddy 匹配器，路径按前缀匹配，与 WAF 作用域口径一致
+func wafRateLimitBindingMatchers(binding *model.WafPolicyBinding) ([]string, error) {
+	if binding == nil {
+		return nil, nil
+	}
+	host, path, method := "", "", ""
+	switch normalizePolicyScopeType(binding.ScopeType) {
+	case wafPolicyScopeTypeGlobal:
+		return nil, nil
+	case wafPolicyScopeTypeSite:
+		host = normalizePolicyScopeHost(binding.Host)
+	case wafPolicyScopeTypeRoute:
+		host = normalizePolicyScopeHost(binding.Host)
+		path = normalizePolicyScopePath(binding.Path)
+		method = normalizePolicyHTTPMethod(binding.Method)
+	default:
+		return nil, fmt.Errorf("绑定 #%d 作用域类型无效", binding.ID)
+	}
+	if strings.ContainsAny(host+path+method, " \t{}\"`") {
+		return nil, fmt.Errorf("绑定 #%d 作用域包含不支持的字符", binding.ID)
+	}
+
+	matchers := make([]string, 0, 3)
+	if host != "" {
+		matchers = append(matchers, "host "+host)
+	}
+	if path != "" && path != "/" {
+		matchers = append(matchers, fmt.Sprintf("path %s %s/*", path, strings.TrimSuffix(path, "/")))
+	}
+	if method != "" {
+		matchers = append(matchers, "method "+method)
+	}
+	return matchers, nil
+}
+
+// buildManagedRateLimitZone 渲染限流区域；突发余量叠加在窗口请求数之上，即窗口内最多 events+burst 个请求
+func buildManagedRateLimitZone(zone *model.WafRateLimitZone, binding *model.WafPolicyBinding) (*managedRateLimitZone, error) {
+	if zone == nil {
+		return nil, nil
+	}
+	matchers, err := wafRateLimitBindingMatchers(binding)
+	if err != nil {
+		return nil, err
+	}
+	return &managedRateLimitZone{
+		Name:   zone.Name,
+		Match:  matchers,
+		Key:    wafRateLimitKeyPlaceholder(zone.KeyType, zone.KeyHeader),
+		Events: zone.Events + zone.Burst,
+		Window: fmt.Sprintf("%ds", zone.WindowSeconds),
+	}, nil
+}
+
+// loadWafRateLimitZones 读取策略下已启用的限流区域，绑定被禁用或删除的区域跳过
+func loadWafRateLimitZones(db *gorm.DB, policyID uint) ([]managedRateLimitZone, error) {
+	if db == nil || policyID == 0 {
+		return nil, nil
+	}
+	var zones []model.WafRateLimitZone
+	if err := db.Where("policy_id = ? AND enabled = ?", policyID, true).Order("id asc").Find(&zones).Error; err != nil {
+		return nil, fmt.Errorf("查询限流区域失败: %w", err)
+	}
+	if len(zones) == 0 {
+		return nil, nil
+	}
+
+	bindingIDs := make([]uint, 0)
+	for _, zone := range zones {
+		if zone.BindingID > 0 {
+			bindingIDs = append(bindingIDs, zone.BindingID)
+		}
+	}
+	bindings := make(map[uint]model.WafPolicyBinding)
+	if len(bindingIDs) > 0 {
+		var rows []model.WafPolicyBinding
+		if err := db.Where("id IN ?", bindingIDs).Find(&rows).Error; err != nil {
+			return nil, fmt.Errorf("查询限流区域绑定失败: %w", err)
+		}
+		for _, row := range rows {
+			bindings[row.ID] = row
+		}
+	}
+
+	rendered := make([]managedRateLimitZone, 0, len(zones))
+	for i := range zones {
+		var binding *model.WafPolicyBinding
+		if zones[i].BindingID > 0 {
+			row, ok := bindings[zones[i].BindingID]
+			if !ok || !row.Enabled {
+				continue
+			}
+			binding = &row
+		}
+		item, err := buildManagedRateLimitZone(&zones[i], binding)
+		if err != nil {
+			return nil, err
+		}
+		rendered = append(rendered, *item)
+	}
+	return rendered, nil
+}
+
+// loadWafPolicyCaddyExtras 汇总策略在托管 Caddyfile 中的附加配置
+func loadWafPolicyCaddyExtras(db *gorm.DB, policyID uint) (managedPolicyExtras, error) {
+	geoDeny, err := loadWafGeoDenyRules(db, policyID)
+	if err != nil {
+		return managedPolicyExtras{}, err
+	}
+	rateLimits, err := loadWafRateLimitZones(db, policyID)
+	if err != nil {
+		return managedPolicyExtras{}, err
+	}
+	return managedPolicyExtras{GeoDeny: geoDeny, RateLimits: rateLimits}, nil
+}
+
+// renderWafRateLimitValidationCaddyfile 生成只包含限流区域的最小配置，供 /adapt 校验语法与模块是否安装
+func renderWafRateLimitValidationCaddyfile(zones []managedRateLimitZone) string {
+	var builder strings.Builder
+	builder.WriteString("{\n  order rate_limit before basicauth\n}\n\n")
+	builder.WriteString(wafRateLimitValidateSiteAddress + " {\n")
+	builder.WriteString(renderManagedRateLimits(zones, "  "))
+	builder.WriteString("}\n")
+	return builder.String()
+}
+
+func toWafRateLimitZoneItem(zone *model.WafRateLimitZone) types.WafRateLimitZoneItem {
+	return types.WafRateLimitZoneItem{
+		ID:            zone.ID,
+		PolicyId:      zone.PolicyID,
+		BindingId:     zone.BindingID,
+		Name:          zone.Name,
+		Description:   zone.Description,
+		Enabled:       zone.Enabled,
+		KeyType:       zone.KeyType,
+		KeyHeader:     zone.KeyHeader,
+		Events:        zone.Events,
+		WindowSeconds: zone.WindowSeconds,
+		Burst:         zone.Burst,
+		CreatedAt:     formatTime(zone.CreatedAt),
+		UpdatedAt:     formatTime(zone.UpdatedAt),
+	}
+}
@@ -0,0 +1,102 @@
+package caddy
+
+import (
+	"strings"
+	"testing"
+
+	"logflux/model"
+)
+
+func TestNormalizeWafRateLimitInput(t *testing.T) {
+	if name, err := normalizeWafRateLimitZoneName(" Login_API "); err != nil || name != "login_api" {
+		t.Fatalf("normalizeWafRateLimitZoneName() = %q, %v", name, err)
+	}
+	for _, invalid := range []string{"", "1zone", "api-zone", strings.Repeat("a", 41)} {
+		if _, err := normalizeWafRateLimitZoneName(invalid); err == nil {
+			t.Fatalf("expected zone name %q to be rejected", invalid)
+		}
+	}
+
+	keyType, header, err := normalizeWafRateLimitKey("HEADER", " X-Api-Key ")
+	if err != nil || keyType != wafRateLimitKeyHeader || header != "X-Api-Key" {
+		t.Fatalf("normalizeWafRateLimitKey() = %q, %q, %v", keyType, header, err)
+	}
+	if keyType, header, err := normalizeWafRateLimitKey("", "X-Ignored"); err != nil || keyType != wafRateLimitKeyIP || header != "" {
+		t.Fatalf("expected default ip key, got %q, %q, %v", keyType, header, err)
+	}
+	if _, _, err := normalizeWafRateLimitKey("header", "X Api}"); err == nil {
+		t.Fatalf("expected invalid header name to be rejected")
+	}
+	if _, _, err := normalizeWafRateLimitKey("cookie", ""); err == nil {
+		t.Fatalf("expected unknown key type to be rejected")
+	}
+
+	if err := validateWafRateLimitBudget(100, 60, 20); err != nil {
+		t.Fatalf("validateWafRateLimitBudget() error = %v", err)
+	}
+	for _, invalid := range [][3]int64{{0, 60, 0}, {100, 0, 0}, {100, 60, 101}, {100, 60, -1}} {
+		if err := validateWafRateLimitBudget(invalid[0], invalid[1], invalid[2]); err == nil {
+			t.Fatalf("expected budget %v to be rejected", invalid)
+		}
+	}
+}
+
+func TestBuildManagedRateLimitZones(t *testing.T) {
+	zone := &model.WafRateLimitZone{Name: "login", KeyType: wafRateLimitKeyIP, Events: 30, WindowSeconds: 60, Burst: 5}
+	binding := &model.WafPolicyBinding{ID: 7, ScopeType: wafPolicyScopeTypeRoute, Host: "Shop.Example.com", Path: "/login", Method: "post"}
+
+	rendered, err := buildManagedRateLimitZone(zone, binding)
+	if err != nil {
+		t.Fatalf("buildManagedRateLimitZone() error = %v", err)
+	}
+	wantMatch := []string{"host shop.example.com", "path /login /login/*", "method POST"}
+	if strings.Join(rendered.Match, "|") != strings.Join(wantMatch, "|") {
+		t.Fatalf("unexpected matchers: %v", rendered.Match)
+	}
+	if rendered.Key != "{http.vars.client_ip}" || rendered.Events != 35 || rendered.Window != "60s" {
+		t.Fatalf("expected burst as headroom on the window budget, got %+v", rendered)
+	}
+
+	global, err := buildManagedRateLimitZone(&model.WafRateLimitZone{Name: "api", KeyType: wafRateLimitKeyHeader, KeyHeader: "X-Api-Key", Events: 10, WindowSeconds: 1}, nil)
+	if err != nil {
+		t.Fatalf("buildManagedRateLimitZone() error = %v", err)
+	}
+	if len(global.Match) != 0 || global.Key != "{http.request.header.X-Api-Key}" || global.Events != 10 {
+		t.Fatalf("unexpected global zone: %+v", global)
+	}
+}
+
+func TestManagedCaddyfileRendersRateLimits(t *testing.T) {
+	rendered, err := buildManagedRateLimitZone(&model.WafRateLimitZone{Name: "login", Events: 30, WindowSeconds: 60}, &model.WafPolicyBinding{ID: 1, ScopeType: wafPolicyScopeTypeSite, Host: "example.com"})
+	if err != nil {
+		t.Fatalf("buildManagedRateLimitZone() error = %v", err)
+	}
+	zones := []managedRateLimitZone{*rendered}
+
+	config, err := buildPolicyCandidateCaddyConfig("", "SecRuleEngine On", true, managedPolicyExtras{RateLimits: zones})
+	if err != nil {
+		t.Fatalf("buildPolicyCandidateCaddyConfig() error = %v", err)
+	}
+	for _, want := range []string{"order rate_limit before basicauth", "rate_limit {", "zone login {", "host example.com", "key {http.vars.client_ip}", "events 30", "window 60s"} {
+		if !strings.Contains(config, want) {
+			t.Fatalf("expected %q in config:\n%s", want, config)
+		}
+	}
+
+	plain, err := buildPolicyCandidateCaddyConfig("", "SecRuleEngine On", true, managedPolicyExtras{})
+	if err != nil {
+		t.Fatalf("buildPolicyCandidateCaddyConfig() error = %v", err)
+	}
+	if strings.Contains(plain, "rate_limit") {
+		t.Fatalf("expected no rate_limit without zones:\n%s", plain)
+	}
+
+	if _, err := buildPolicyCandidateCaddyConfig(":8080 {\n  respond \"ok\"\n}\n", "SecRuleEngine On", true, managedPolicyExtras{RateLimits: zones}); err == nil {
+		t.Fatalf("expected unmanaged Caddyfile with rate limits to be rejected")
+	}
+
+	validation := renderWafRateLimitValidationCaddyfile(zones)
+	if !strings.HasPrefix(validation, "{\n  order rate_limit before basicauth\n}\n") || !strings.Contains(validation, ":0 {\n  rate_limit {") {
+		t.Fatalf("unexpected validation Caddyfile:\n%s", validation)
+	}
+}
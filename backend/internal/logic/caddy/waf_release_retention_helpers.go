@@ -113,6 +113,7 @@ func (helper *wafLogicHelper) pruneOldReleases(kind string, keepCount int) error
 			helper.logger.Errorf("删除保留版本路径失败: path=%s err=%v", pathValue, removeErr)
 		}
 	}
+	helper.removeReleaseArchives(deleteIDs)
 	return nil
 }
 
@@ -126,6 +127,7 @@ func (helper *wafLogicHelper) collectPinnedReleasePathSet() map[string]bool {
 		helper.store.CurrentLinkPath(),
 		helper.store.LastGoodLinkPath(),
 	}
+	linkPaths = append(linkPaths, helper.store.ServerLinkPaths()...)
 	for _, linkPath := range linkPaths {
 		targetPath, err := helper.store.LinkTarget(linkPath)
 		if err != nil {
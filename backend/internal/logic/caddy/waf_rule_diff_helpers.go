@@ -0,0 +1,225 @@
+package caddy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"logflux/internal/types"
+	"logflux/internal/waf"
+	"logflux/model"
+
+	"gorm.io/gorm"
+)
+
+const (
+	wafRuleDiffMetaKey = "ruleDiff"
+	// 任务元数据中每类变更最多记录的规则 id 数，完整差异通过差异接口查看
+	wafRuleDiffMaxJobIDs = 200
+)
+
+func findActiveWafRelease(db *gorm.DB, kind string) (*model.WafRelease, error) {
+	var release model.WafRelease
+	err := db.Where("kind = ? AND status = ?", normalizeWafKind(kind), wafReleaseStatusActive).
+		Order("updated_at desc, id desc").
+		First(&release).Error
+	if err == nil {
+		return &release, nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return nil, fmt.Errorf("查询激活版本失败: %w", err)
+}
+
+func (helper *wafLogicHelper) releaseRuleDir(release *model.WafRelease) (string, error) {
+	releaseDir, err := helper.ensurePathInWorkDir(helper.store.ReleaseDir(release.Version))
+	if err != nil {
+		return "", err
+	}
+	stat, err := os.Stat(releaseDir)
+	if err != nil || !stat.IsDir() {
+		return "", fmt.Errorf("版本 %s 的规则目录不存在", release.Version)
+	}
+	return releaseDir, nil
+}
+
+// diffWafReleases 解析两个已解压版本的 .conf 规则并按规则 id 比较
+func (helper *wafLogicHelper) diffWafReleases(base, target *model.WafRelease) (*waf.RuleSetDiff, error) {
+	if base == nil || target == nil {
+		return nil, fmt.Errorf("版本为空")
+	}
+	baseDir, err := helper.releaseRuleDir(base)
+	if err != nil {
+		return nil, err
+	}
+	targetDir, err := helper.releaseRuleDir(target)
+	if err != nil {
+		return nil, err
+	}
+	return waf.DiffRuleDirs(baseDir, targetDir)
+}
+
+func buildWafRuleDiffSummary(base, target *model.WafRelease, diff *waf.RuleSetDiff) types.WafRuleDiffSummary {
+	return types.WafRuleDiffSummary{
+		BaseReleaseId:   base.ID,
+		BaseVersion:     base.Version,
+		TargetVersion:   target.Version,
+		BaseRuleCount:   diff.BaseRuleCount,
+		TargetRuleCount: diff.TargetRuleCount,
+		Added:           len(diff.Added),
+		Removed:         len(diff.Removed),
+		Modified:        len(diff.Modified),
+		ParanoiaChanged: diff.ParanoiaChangedCount(),
+		Summary:         diff.Summary(),
+	}
+}
+
+// wafRuleDiffSummaryMap 转为可写入 jsonb 元数据的结构，extra 用于附加规则 id 明细
+func wafRuleDiffSummaryMap(summary types.WafRuleDiffSummary, extra map[string]interface{}) map[string]interface{} {
+	encoded, _ := json.Marshal(summary)
+	result := make(map[string]interface{})
+	_ = json.Unmarshal(encoded, &result)
+	for key, value := range extra {
+		result[key] = value
+	}
+	return result
+}
+
+func wafRuleDiffSummaryFromMeta(meta model.JSONMap) *types.WafRuleDiffSummary {
+	raw, ok := meta[wafRuleDiffMetaKey]
+	if !ok || raw == nil {
+		return nil
+	}
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var summary types.WafRuleDiffSummary
+	if err := json.Unmarshal(encoded, &summary); err != nil || summary.Summary == "" {
+		return nil
+	}
+	return &summary
+}
+
+// attachReleaseRuleDiff 新版本入库前与当前激活版本比较，失败时只记录日志不阻断同步
+func (helper *wafLogicHelper) attachReleaseRuleDiff(meta model.JSONMap, target *model.WafRelease) model.JSONMap {
+	if target == nil || normalizeWafKind(target.Kind) == wafKindCorazaEngine {
+		return meta
+	}
+	base, err := findActiveWafRelease(helper.svcCtx.DB.WithContext(helper.ctx), target.Kind)
+	if err != nil || base == nil || base.Version == target.Version {
+		if err != nil {
+			helper.logger.Errorf("计算规则差异失败: %v", err)
+		}
+		return meta
+	}
+	diff, err := helper.diffWafReleases(base, target)
+	if err != nil {
+		helper.logger.Errorf("计算规则差异失败: version=%s base=%s err=%v", target.Version, base.Version, err)
+		return meta
+	}
+	if meta == nil {
+		meta = model.JSONMap{}
+	}
+	meta[wafRuleDiffMetaKey] = wafRuleDiffSummaryMap(buildWafRuleDiffSummary(base, target, diff), nil)
+	return meta
+}
+
+// attachJobRuleDiff 激活前将与当前激活版本的差异写入任务元数据，供任务列表与通知引用
+func (helper *wafLogicHelper) attachJobRuleDiff(job *model.WafUpdateJob, target *model.WafRelease) {
+	if job == nil || target == nil {
+		return
+	}
+	base, err := findActiveWafRelease(helper.svcCtx.DB.WithContext(helper.ctx), target.Kind)
+	if err != nil || base == nil || base.ID == target.ID {
+		if err != nil {
+			helper.logger.Errorf("计算规则差异失败: %v", err)
+		}
+		return
+	}
+	diff, err := helper.diffWafReleases(base, target)
+	if err != nil {
+		helper.logger.Errorf("计算规则差异失败: version=%s base=%s err=%v", target.Version, base.Version, err)
+		return
+	}
+
+	addedIDs := make([]int64, 0, len(diff.Added))
+	for _, rule := range diff.Added {
+		addedIDs = append(addedIDs, rule.ID)
+	}
+	removedIDs := make([]int64, 0, len(diff.Removed))
+	for _, rule := range diff.Removed {
+		removedIDs = append(removedIDs, rule.ID)
+	}
+	modifiedIDs := make([]int64, 0, len(diff.Modified))
+	for _, change := range diff.Modified {
+		modifiedIDs = append(modifiedIDs, change.ID)
+	}
+	truncated := len(addedIDs) > wafRuleDiffMaxJobIDs || len(removedIDs) > wafRuleDiffMaxJobIDs || len(modifiedIDs) > wafRuleDiffMaxJobIDs
+
+	if job.Meta == nil {
+		job.Meta = model.JSONMap{}
+	}
+	job.Meta[wafRuleDiffMetaKey] = wafRuleDiffSummaryMap(buildWafRuleDiffSummary(base, target, diff), map[string]interface{}{
+		"addedIds":    truncateRuleIDs(addedIDs),
+		"removedIds":  truncateRuleIDs(removedIDs),
+		"modifiedIds": truncateRuleIDs(modifiedIDs),
+		"truncated":   truncated,
+	})
+	if err := helper.svcCtx.DB.WithContext(helper.ctx).Model(job).Update("meta", job.Meta).Error; err != nil {
+		helper.logger.Errorf("写入任务规则差异失败: %v", err)
+	}
+}
+
+func truncateRuleIDs(ids []int64) []int64 {
+	if len(ids) > wafRuleDiffMaxJobIDs {
+		return ids[:wafRuleDiffMaxJobIDs]
+	}
+	return ids
+}
+
+func wafRuleDiffURL(releaseID, baseReleaseID uint) string {
+	return fmt.Sprintf("/api/caddy/waf/release/%d/diff?baseId=%d", releaseID, baseReleaseID)
+}
+
+func toWafRuleDiffRules(rules []waf.RuleInfo) []types.WafRuleDiffRule {
+	items := make([]types.WafRuleDiffRule, 0, len(rules))
+	for _, rule := range rules {
+		items = append(items, types.WafRuleDiffRule{
+			ID:            rule.ID,
+			File:          rule.File,
+			Msg:           rule.Msg,
+			ParanoiaLevel: rule.ParanoiaLevel,
+			Tags:          nonNilStrings(rule.Tags),
+		})
+	}
+	return items
+}
+
+func toWafRuleDiffChanges(changes []waf.RuleChange) []types.WafRuleDiffChange {
+	items := make([]types.WafRuleDiffChange, 0, len(changes))
+	for _, change := range changes {
+		items = append(items, types.WafRuleDiffChange{
+			ID:             change.ID,
+			File:           change.File,
+			Msg:            change.Msg,
+			Changes:        nonNilStrings(change.Changes),
+			ParanoiaBefore: change.ParanoiaBefore,
+			ParanoiaAfter:  change.ParanoiaAfter,
+			TagsAdded:      nonNilStrings(change.TagsAdded),
+			TagsRemoved:    nonNilStrings(change.TagsRemoved),
+			ActionsAdded:   nonNilStrings(change.ActionsAdded),
+			ActionsRemoved: nonNilStrings(change.ActionsRemoved),
+		})
+	}
+	return items
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,45 @@
+package caddy
+
+import (
+	"encoding/json"
+	"testing"
+
+	"logflux/internal/waf"
+	"logflux/model"
+)
+
+func TestWafRuleDiffMetaRoundTrip(t *testing.T) {
+	base := &model.WafRelease{ID: 3, Version: "v4.0.0"}
+	target := &model.WafRelease{ID: 5, Version: "v4.1.0"}
+	diff := waf.DiffRuleSets(
+		map[int64]waf.RuleInfo{1: {ID: 1, ParanoiaLevel: 1}, 2: {ID: 2}},
+		map[int64]waf.RuleInfo{1: {ID: 1, ParanoiaLevel: 2}, 3: {ID: 3}},
+	)
+
+	summaryMap := wafRuleDiffSummaryMap(buildWafRuleDiffSummary(base, target, diff), map[string]interface{}{"addedIds": []int64{3}})
+	// 模拟 jsonb 读回后数字变为 float64
+	encoded, _ := json.Marshal(model.JSONMap{wafRuleDiffMetaKey: summaryMap})
+	var meta model.JSONMap
+	if err := json.Unmarshal(encoded, &meta); err != nil {
+		t.Fatalf("unmarshal meta failed: %v", err)
+	}
+
+	summary := wafRuleDiffSummaryFromMeta(meta)
+	if summary == nil {
+		t.Fatalf("expected summary from meta")
+	}
+	if summary.BaseReleaseId != 3 || summary.BaseVersion != "v4.0.0" || summary.TargetVersion != "v4.1.0" ||
+		summary.Added != 1 || summary.Removed != 1 || summary.Modified != 1 || summary.ParanoiaChanged != 1 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if summary.Summary != "新增 1 条、移除 1 条、修改 1 条规则，其中 1 条调整了 Paranoia Level" {
+		t.Fatalf("unexpected summary text: %s", summary.Summary)
+	}
+
+	if wafRuleDiffSummaryFromMeta(nil) != nil || wafRuleDiffSummaryFromMeta(model.JSONMap{"originFileName": "a.zip"}) != nil {
+		t.Fatalf("expected nil summary without rule diff meta")
+	}
+	if url := wafRuleDiffURL(5, 3); url != "/api/caddy/waf/release/5/diff?baseId=3" {
+		t.Fatalf("unexpected diff url: %s", url)
+	}
+}
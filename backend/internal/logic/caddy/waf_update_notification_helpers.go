@@ -46,7 +46,15 @@ func (helper *wafLogicHelper) notifyWafUpdateJobEvent(job *model.WafUpdateJob, s
 		payload["releaseVersion"] = releaseVersion
 	}
 
-	notifyWafEventAsync(helper.svcCtx.NotificationMgr, helper.logger, eventType, eventLevel, eventTitle, buildWafUpdateEventMessage(normalizedAction, message), payload)
+	eventMessage := buildWafUpdateEventMessage(normalizedAction, message)
+	if ruleDiff := wafRuleDiffSummaryFromMeta(job.Meta); ruleDiff != nil && effectiveReleaseID > 0 {
+		payload["ruleDiffSummary"] = ruleDiff.Summary
+		payload["baseVersion"] = ruleDiff.BaseVersion
+		payload["link"] = wafRuleDiffURL(effectiveReleaseID, ruleDiff.BaseReleaseId)
+		eventMessage += "；规则变更（对比 " + ruleDiff.BaseVersion + "）：" + ruleDiff.Summary
+	}
+
+	notifyWafEventAsync(helper.svcCtx.NotificationMgr, helper.logger, eventType, eventLevel, eventTitle, eventMessage, payload)
 }
 
 func resolveWafUpdateEventMeta(action, status string) (eventType, level, title string) {
@@ -79,16 +87,24 @@ func resolveWafUpdateEventMeta(action, status string) (eventType, level, title s
 		if status == wafJobStatusFailed {
 			return notification.EventSecurityWafReleaseRollbackFailed, notification.LevelError, "WAF 版本回滚失败"
 		}
+	case "distribute":
+		if status == wafJobStatusSuccess {
+			return notification.EventSecurityWafDistributeSuccess, notification.LevelInfo, "WAF 多节点分发成功"
+		}
+		if status == wafJobStatusFailed {
+			return notification.EventSecurityWafDistributeFailed, notification.LevelError, "WAF 多节点分发失败"
+		}
 	}
 	return "", "", ""
 }
 
 func buildWafUpdateEventMessage(action, message string) string {
 	actionName := map[string]string{
-		"check":    "检查",
-		"download": "同步",
-		"activate": "激活",
-		"rollback": "回滚",
+		"check":      "检查",
+		"download":   "同步",
+		"activate":   "激活",
+		"rollback":   "回滚",
+		"distribute": "分发",
 	}[action]
 	if actionName == "" {
 		actionName = "任务"
@@ -0,0 +1,52 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"logflux/internal/service"
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type AckEscalationLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewAckEscalationLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AckEscalationLogic {
+	return &AckEscalationLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// AckEscalation 确认告警，停止后续升级步骤
+func (l *AckEscalationLogic) AckEscalation(req *types.IDReq) (resp *types.BaseResp, err error) {
+	now := time.Now()
+	res := l.svcCtx.DB.WithContext(l.ctx).Model(&model.NotificationEscalation{}).
+		Where("id = ? AND status IN ?", req.ID, []string{model.EscalationStatusActive, model.EscalationStatusExhausted}).
+		Updates(map[string]interface{}{
+			"status":          model.EscalationStatusAcknowledged,
+			"next_step_at":    nil,
+			"acknowledged_at": &now,
+			"acknowledged_by": service.CurrentUsername(l.ctx, l.svcCtx),
+		})
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, fmt.Errorf("升级实例不存在或已确认/解决")
+	}
+
+	return &types.BaseResp{
+		Code: 200,
+		Msg:  "成功",
+	}, nil
+}
@@ -0,0 +1,54 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CreateEscalationPolicyLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCreateEscalationPolicyLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateEscalationPolicyLogic {
+	return &CreateEscalationPolicyLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CreateEscalationPolicyLogic) CreateEscalationPolicy(req *types.EscalationPolicyReq) (resp *types.BaseResp, err error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("升级策略名称不能为空")
+	}
+
+	steps, err := normalizeEscalationSteps(req.Steps)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &model.NotificationEscalationPolicy{
+		Name:        name,
+		Enabled:     req.Enabled,
+		Steps:       steps,
+		Description: req.Description,
+	}
+	if err := l.svcCtx.DB.WithContext(l.ctx).Create(policy).Error; err != nil {
+		return nil, err
+	}
+
+	return &types.BaseResp{
+		Code: 200,
+		Msg:  "成功",
+	}, nil
+}
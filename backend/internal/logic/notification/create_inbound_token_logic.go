@@ -0,0 +1,68 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CreateInboundTokenLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCreateInboundTokenLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateInboundTokenLogic {
+	return &CreateInboundTokenLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CreateInboundTokenLogic) CreateInboundToken(req *types.InboundTokenReq) (resp *types.BaseResp, err error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("令牌名称不能为空")
+	}
+	format, err := normalizeInboundFormat(req.Format)
+	if err != nil {
+		return nil, err
+	}
+	mapping, err := parseInboundMappingConfig(req.Mapping)
+	if err != nil {
+		return nil, err
+	}
+	if req.RateLimit < 0 {
+		return nil, fmt.Errorf("限流值不能为负数")
+	}
+	secret, err := generateInboundToken()
+	if err != nil {
+		return nil, err
+	}
+
+	token := &model.NotificationInboundToken{
+		Name:        name,
+		Token:       secret,
+		Enabled:     req.Enabled,
+		Description: req.Description,
+		Format:      format,
+		EventType:   strings.TrimSpace(req.EventType),
+		Mapping:     mapping,
+		RateLimit:   req.RateLimit,
+	}
+	if err := l.svcCtx.DB.WithContext(l.ctx).Create(token).Error; err != nil {
+		return nil, err
+	}
+
+	return &types.BaseResp{
+		Code: 200,
+		Msg:  "成功",
+	}, nil
+}
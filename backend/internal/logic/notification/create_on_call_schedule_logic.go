@@ -0,0 +1,59 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CreateOnCallScheduleLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCreateOnCallScheduleLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateOnCallScheduleLogic {
+	return &CreateOnCallScheduleLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CreateOnCallScheduleLogic) CreateOnCallSchedule(req *types.OnCallScheduleReq) (resp *types.BaseResp, err error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("值班表名称不能为空")
+	}
+
+	rotationStart, err := parseRotationStart(req.RotationStart)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateRotation(req.RotationDays, req.Participants); err != nil {
+		return nil, err
+	}
+
+	schedule := &model.NotificationOnCallSchedule{
+		Name:          name,
+		Enabled:       req.Enabled,
+		Description:   req.Description,
+		RotationStart: rotationStart,
+		RotationDays:  req.RotationDays,
+		Participants:  model.Int64Array(req.Participants),
+	}
+	if err := l.svcCtx.DB.WithContext(l.ctx).Create(schedule).Error; err != nil {
+		return nil, err
+	}
+
+	return &types.BaseResp{
+		Code: 200,
+		Msg:  "成功",
+	}, nil
+}
@@ -0,0 +1,74 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CreateReportLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCreateReportLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateReportLogic {
+	return &CreateReportLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CreateReportLogic) CreateReport(req *types.ReportReq) (resp *types.BaseResp, err error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("报表名称不能为空")
+	}
+	period, err := normalizeReportPeriod(req.Period)
+	if err != nil {
+		return nil, err
+	}
+	schedule := strings.TrimSpace(req.Schedule)
+	if err := validateReportSchedule(schedule); err != nil {
+		return nil, err
+	}
+	if len(req.ChannelIDs) == 0 {
+		return nil, fmt.Errorf("报表至少需要一个接收渠道")
+	}
+
+	report := &model.NotificationReport{
+		Name:             name,
+		Enabled:          req.Enabled,
+		Description:      req.Description,
+		Period:           period,
+		Schedule:         schedule,
+		Hosts:            normalizeReportHosts(req.Hosts),
+		PolicyIDs:        model.Int64Array(req.PolicyIDs),
+		ChannelIDs:       model.Int64Array(req.ChannelIDs),
+		HTMLTemplate:     strings.TrimSpace(req.HTMLTemplate),
+		MarkdownTemplate: strings.TrimSpace(req.MarkdownTemplate),
+		TopN:             req.TopN,
+	}
+	if report.PolicyIDs == nil {
+		report.PolicyIDs = model.Int64Array{}
+	}
+	if err := l.svcCtx.DB.WithContext(l.ctx).Create(report).Error; err != nil {
+		return nil, err
+	}
+
+	if err := reloadReportSchedule(l.svcCtx, report.ID); err != nil {
+		l.Errorf("同步报表调度失败: reportID=%d err=%v", report.ID, err)
+	}
+
+	return &types.BaseResp{
+		Code: 200,
+		Msg:  "成功",
+	}, nil
+}
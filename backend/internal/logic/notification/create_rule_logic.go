@@ -44,6 +44,9 @@ func (l *CreateRuleLogic) CreateRule(req *types.RuleReq) (resp *types.BaseResp,
 		SilenceDuration: req.SilenceDuration,
 		Description:     req.Description,
 	}
+	if req.EscalationPolicyID > 0 {
+		rule.EscalationPolicyID = &req.EscalationPolicyID
+	}
 
 	if err := l.svcCtx.DB.WithContext(l.ctx).Create(rule).Error; err != nil {
 		return nil, err
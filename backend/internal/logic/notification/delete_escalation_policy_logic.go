@@ -0,0 +1,47 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DeleteEscalationPolicyLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDeleteEscalationPolicyLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteEscalationPolicyLogic {
+	return &DeleteEscalationPolicyLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *DeleteEscalationPolicyLogic) DeleteEscalationPolicy(req *types.IDReq) (resp *types.BaseResp, err error) {
+	var refCount int64
+	if err := l.svcCtx.DB.WithContext(l.ctx).Model(&model.NotificationRule{}).
+		Where("escalation_policy_id = ?", req.ID).
+		Count(&refCount).Error; err != nil {
+		return nil, err
+	}
+	if refCount > 0 {
+		return nil, fmt.Errorf("升级策略仍被 %d 条通知规则引用", refCount)
+	}
+
+	if err := l.svcCtx.DB.WithContext(l.ctx).Delete(&model.NotificationEscalationPolicy{}, req.ID).Error; err != nil {
+		return nil, err
+	}
+
+	return &types.BaseResp{
+		Code: 200,
+		Msg:  "成功",
+	}, nil
+}
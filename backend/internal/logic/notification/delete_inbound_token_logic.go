@@ -0,0 +1,36 @@
+package notification
+
+import (
+	"context"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DeleteInboundTokenLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDeleteInboundTokenLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteInboundTokenLogic {
+	return &DeleteInboundTokenLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *DeleteInboundTokenLogic) DeleteInboundToken(req *types.IDReq) (resp *types.BaseResp, err error) {
+	if err := l.svcCtx.DB.WithContext(l.ctx).Delete(&model.NotificationInboundToken{}, req.ID).Error; err != nil {
+		return nil, err
+	}
+
+	return &types.BaseResp{
+		Code: 200,
+		Msg:  "成功",
+	}, nil
+}
@@ -0,0 +1,36 @@
+package notification
+
+import (
+	"context"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DeleteOnCallScheduleLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDeleteOnCallScheduleLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteOnCallScheduleLogic {
+	return &DeleteOnCallScheduleLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *DeleteOnCallScheduleLogic) DeleteOnCallSchedule(req *types.IDReq) (resp *types.BaseResp, err error) {
+	if err := l.svcCtx.DB.WithContext(l.ctx).Delete(&model.NotificationOnCallSchedule{}, req.ID).Error; err != nil {
+		return nil, err
+	}
+
+	return &types.BaseResp{
+		Code: 200,
+		Msg:  "成功",
+	}, nil
+}
@@ -0,0 +1,40 @@
+package notification
+
+import (
+	"context"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DeleteReportLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDeleteReportLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteReportLogic {
+	return &DeleteReportLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *DeleteReportLogic) DeleteReport(req *types.IDReq) (resp *types.BaseResp, err error) {
+	if l.svcCtx.ReportScheduler != nil {
+		l.svcCtx.ReportScheduler.RemoveReport(req.ID)
+	}
+
+	if err := l.svcCtx.DB.WithContext(l.ctx).Delete(&model.NotificationReport{}, req.ID).Error; err != nil {
+		return nil, err
+	}
+
+	return &types.BaseResp{
+		Code: 200,
+		Msg:  "成功",
+	}, nil
+}
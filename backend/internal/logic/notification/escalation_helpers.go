@@ -0,0 +1,77 @@
+package notification
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"logflux/internal/types"
+	"logflux/model"
+)
+
+const escalationTimeLayout = "2006-01-02 15:04:05"
+
+// normalizeEscalationSteps 校验并转换升级步骤
+func normalizeEscalationSteps(items []types.EscalationStepItem) (model.EscalationSteps, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("升级策略至少需要一个步骤")
+	}
+
+	steps := make(model.EscalationSteps, 0, len(items))
+	for i, item := range items {
+		if len(item.ChannelIDs) == 0 && len(item.ScheduleIDs) == 0 {
+			return nil, fmt.Errorf("第 %d 步未配置通知渠道或值班表", i+1)
+		}
+		delay := item.DelayMinutes
+		if i == 0 {
+			delay = 0
+		} else if delay <= 0 {
+			return nil, fmt.Errorf("第 %d 步的等待时间必须大于 0 分钟", i+1)
+		}
+		steps = append(steps, model.EscalationStep{
+			DelayMinutes: delay,
+			ChannelIDs:   item.ChannelIDs,
+			ScheduleIDs:  item.ScheduleIDs,
+		})
+	}
+
+	return steps, nil
+}
+
+func toEscalationStepItems(steps model.EscalationSteps) []types.EscalationStepItem {
+	items := make([]types.EscalationStepItem, 0, len(steps))
+	for _, step := range steps {
+		items = append(items, types.EscalationStepItem{
+			DelayMinutes: step.DelayMinutes,
+			ChannelIDs:   step.ChannelIDs,
+			ScheduleIDs:  step.ScheduleIDs,
+		})
+	}
+	return items
+}
+
+// parseRotationStart 解析值班轮换起点（本地时区）
+func parseRotationStart(value string) (time.Time, error) {
+	t, err := time.ParseInLocation(escalationTimeLayout, strings.TrimSpace(value), time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("轮换起点格式无效，应为 %s", escalationTimeLayout)
+	}
+	return t, nil
+}
+
+func validateRotation(days int, participants []int64) error {
+	if days <= 0 {
+		return fmt.Errorf("轮换周期必须大于 0 天")
+	}
+	if len(participants) == 0 {
+		return fmt.Errorf("值班表至少需要一名参与者")
+	}
+	return nil
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(escalationTimeLayout)
+}
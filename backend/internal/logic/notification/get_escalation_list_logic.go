@@ -0,0 +1,70 @@
+package notification
+
+import (
+	"context"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetEscalationListLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetEscalationListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetEscalationListLogic {
+	return &GetEscalationListLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetEscalationListLogic) GetEscalationList(req *types.EscalationListReq) (resp *types.EscalationListResp, err error) {
+	db := l.svcCtx.DB.WithContext(l.ctx).Model(&model.NotificationEscalation{})
+	if req.Status != "" {
+		db = db.Where("status = ?", req.Status)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	var escalations []model.NotificationEscalation
+	offset := (req.Page - 1) * req.PageSize
+	if err := db.Order("id desc").Limit(req.PageSize).Offset(offset).Find(&escalations).Error; err != nil {
+		return nil, err
+	}
+
+	list := make([]types.EscalationItem, 0, len(escalations))
+	for _, e := range escalations {
+		item := types.EscalationItem{
+			ID:             e.ID,
+			PolicyID:       e.PolicyID,
+			EventType:      e.EventType,
+			Title:          e.EventTitle,
+			Level:          e.EventLevel,
+			Status:         e.Status,
+			CurrentStep:    e.CurrentStep + 1,
+			NextStepAt:     formatOptionalTime(e.NextStepAt),
+			AcknowledgedAt: formatOptionalTime(e.AcknowledgedAt),
+			AcknowledgedBy: e.AcknowledgedBy,
+			ResolvedAt:     formatOptionalTime(e.ResolvedAt),
+			CreatedAt:      e.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
+		if e.RuleID != nil {
+			item.RuleID = *e.RuleID
+		}
+		list = append(list, item)
+	}
+
+	return &types.EscalationListResp{
+		List:  list,
+		Total: total,
+	}, nil
+}
@@ -0,0 +1,49 @@
+package notification
+
+import (
+	"context"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetEscalationPolicyListLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetEscalationPolicyListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetEscalationPolicyListLogic {
+	return &GetEscalationPolicyListLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetEscalationPolicyListLogic) GetEscalationPolicyList() (resp *types.EscalationPolicyListResp, err error) {
+	var policies []model.NotificationEscalationPolicy
+	if err := l.svcCtx.DB.WithContext(l.ctx).Order("id asc").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+
+	list := make([]types.EscalationPolicyItem, 0, len(policies))
+	for _, p := range policies {
+		list = append(list, types.EscalationPolicyItem{
+			ID:          p.ID,
+			Name:        p.Name,
+			Enabled:     p.Enabled,
+			Steps:       toEscalationStepItems(p.Steps),
+			Description: p.Description,
+			CreatedAt:   p.CreatedAt.Format("2006-01-02 15:04:05"),
+			UpdatedAt:   p.UpdatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	return &types.EscalationPolicyListResp{
+		List: list,
+	}, nil
+}
@@ -0,0 +1,41 @@
+package notification
+
+import (
+	"context"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetInboundTokenListLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetInboundTokenListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetInboundTokenListLogic {
+	return &GetInboundTokenListLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetInboundTokenListLogic) GetInboundTokenList() (resp *types.InboundTokenListResp, err error) {
+	var tokens []model.NotificationInboundToken
+	if err := l.svcCtx.DB.WithContext(l.ctx).Order("id asc").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+
+	list := make([]types.InboundTokenItem, 0, len(tokens))
+	for i := range tokens {
+		list = append(list, toInboundTokenItem(&tokens[i]))
+	}
+
+	return &types.InboundTokenListResp{
+		List: list,
+	}, nil
+}
@@ -64,6 +64,9 @@ func (l *GetNotificationLogsLogic) GetNotificationLogs(req *types.LogListReq) (r
 	if req.JobStatus != "" {
 		db = db.Where("notification_jobs.status = ?", req.JobStatus)
 	}
+	if req.EscalationID != 0 {
+		db = db.Where("notification_logs.escalation_id = ?", req.EscalationID)
+	}
 
 	// 分页
 	var total int64
@@ -111,6 +114,10 @@ func (l *GetNotificationLogsLogic) GetNotificationLogs(req *types.LogListReq) (r
 		if log.ChannelID != nil {
 			item.ChannelID = uint(*log.ChannelID)
 		}
+		if log.EscalationID != nil {
+			item.EscalationID = *log.EscalationID
+			item.EscalationStep = log.EscalationStep + 1
+		}
 		if log.SentAt != nil {
 			item.SentAt = log.SentAt.Format("2006-01-02 15:04:05")
 		}
@@ -0,0 +1,79 @@
+package notification
+
+import (
+	"context"
+	"time"
+
+	"logflux/internal/notification"
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetOnCallScheduleListLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetOnCallScheduleListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetOnCallScheduleListLogic {
+	return &GetOnCallScheduleListLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetOnCallScheduleListLogic) GetOnCallScheduleList() (resp *types.OnCallScheduleListResp, err error) {
+	var schedules []model.NotificationOnCallSchedule
+	if err := l.svcCtx.DB.WithContext(l.ctx).Order("id asc").Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	onCallUserIDs := make(map[uint]uint, len(schedules))
+	userIDs := make([]uint, 0, len(schedules))
+	for i := range schedules {
+		if userID, ok := notification.OnCallUserID(&schedules[i], now); ok {
+			onCallUserIDs[schedules[i].ID] = userID
+			userIDs = append(userIDs, userID)
+		}
+	}
+
+	usernames := make(map[uint]string, len(userIDs))
+	if len(userIDs) > 0 {
+		var users []model.User
+		if err := l.svcCtx.DB.WithContext(l.ctx).Select("id", "username").Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+			l.Logger.Errorf("查询值班用户失败: %v", err)
+		}
+		for _, user := range users {
+			usernames[user.ID] = user.Username
+		}
+	}
+
+	list := make([]types.OnCallScheduleItem, 0, len(schedules))
+	for _, s := range schedules {
+		item := types.OnCallScheduleItem{
+			ID:            s.ID,
+			Name:          s.Name,
+			Enabled:       s.Enabled,
+			RotationStart: s.RotationStart.Format(escalationTimeLayout),
+			RotationDays:  s.RotationDays,
+			Participants:  []int64(s.Participants),
+			Description:   s.Description,
+			CreatedAt:     s.CreatedAt.Format("2006-01-02 15:04:05"),
+			UpdatedAt:     s.UpdatedAt.Format("2006-01-02 15:04:05"),
+		}
+		if userID, ok := onCallUserIDs[s.ID]; ok {
+			item.CurrentOnCallUserID = userID
+			item.CurrentOnCallUser = usernames[userID]
+		}
+		list = append(list, item)
+	}
+
+	return &types.OnCallScheduleListResp{
+		List: list,
+	}, nil
+}
@@ -0,0 +1,41 @@
+package notification
+
+import (
+	"context"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetReportListLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetReportListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetReportListLogic {
+	return &GetReportListLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetReportListLogic) GetReportList() (resp *types.ReportListResp, err error) {
+	var reports []model.NotificationReport
+	if err := l.svcCtx.DB.WithContext(l.ctx).Order("id asc").Find(&reports).Error; err != nil {
+		return nil, err
+	}
+
+	list := make([]types.ReportItem, 0, len(reports))
+	for i := range reports {
+		list = append(list, toReportItem(&reports[i]))
+	}
+
+	return &types.ReportListResp{
+		List: list,
+	}, nil
+}
@@ -35,7 +35,7 @@ func (l *GetRuleListLogic) GetRuleList() (resp *types.RuleListResp, err error) {
 	for _, r := range rules {
 		conditionBytes, _ := json.Marshal(r.Condition)
 
-		list = append(list, types.RuleItem{
+		item := types.RuleItem{
 			ID:              r.ID,
 			Name:            r.Name,
 			Enabled:         r.Enabled,
@@ -48,7 +48,11 @@ func (l *GetRuleListLogic) GetRuleList() (resp *types.RuleListResp, err error) {
 			Description:     r.Description,
 			CreatedAt:       r.CreatedAt.Format("2006-01-02 15:04:05"),
 			UpdatedAt:       r.UpdatedAt.Format("2006-01-02 15:04:05"),
-		})
+		}
+		if r.EscalationPolicyID != nil {
+			item.EscalationPolicyID = *r.EscalationPolicyID
+		}
+		list = append(list, item)
 	}
 
 	return &types.RuleListResp{
@@ -0,0 +1,60 @@
+package notification
+
+import (
+	"context"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetUserContactListLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetUserContactListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetUserContactListLogic {
+	return &GetUserContactListLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetUserContactList 列出所有用户及其联系渠道（未配置的用户返回空渠道）
+func (l *GetUserContactListLogic) GetUserContactList() (resp *types.UserContactListResp, err error) {
+	var users []model.User
+	if err := l.svcCtx.DB.WithContext(l.ctx).Select("id", "username").Order("id asc").Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	var contacts []model.NotificationUserContact
+	if err := l.svcCtx.DB.WithContext(l.ctx).Find(&contacts).Error; err != nil {
+		return nil, err
+	}
+	contactMap := make(map[uint]model.NotificationUserContact, len(contacts))
+	for _, c := range contacts {
+		contactMap[c.UserID] = c
+	}
+
+	list := make([]types.UserContactItem, 0, len(users))
+	for _, u := range users {
+		item := types.UserContactItem{
+			UserID:     u.ID,
+			Username:   u.Username,
+			ChannelIDs: []int64{},
+		}
+		if c, ok := contactMap[u.ID]; ok {
+			item.ChannelIDs = []int64(c.ChannelIDs)
+			item.UpdatedAt = c.UpdatedAt.Format("2006-01-02 15:04:05")
+		}
+		list = append(list, item)
+	}
+
+	return &types.UserContactListResp{
+		List: list,
+	}, nil
+}
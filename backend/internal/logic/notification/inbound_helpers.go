@@ -0,0 +1,120 @@
+package notification
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"logflux/internal/notification"
+	"logflux/internal/types"
+	"logflux/model"
+)
+
+// inboundAlertPath 入站告警接收路径前缀，完整地址为 <prefix><token>
+const inboundAlertPath = "/api/notification/inbound/"
+
+// inboundClientRateLimit 查库前按来源地址限流的每分钟请求数，防止随机令牌绕过令牌限流反复查询数据库
+const inboundClientRateLimit = 600
+
+var (
+	// inboundRateLimiter 进程内共享的入站令牌限流器，限额取自令牌配置
+	inboundRateLimiter = notification.NewInboundRateLimiter(time.Minute)
+	// inboundClientRateLimiter 进程内共享的入站来源地址限流器
+	inboundClientRateLimiter = notification.NewInboundRateLimiter(time.Minute)
+)
+
+// inboundAlertBodyCtxKey 在上下文中传递入站告警原始负载
+type inboundAlertBodyCtxKey struct{}
+
+// inboundAlertClientIPCtxKey 在上下文中传递入站告警来源地址
+type inboundAlertClientIPCtxKey struct{}
+
+// WithInboundAlertBody 将 handler 读取的告警负载放入上下文，供接收逻辑解析
+func WithInboundAlertBody(ctx context.Context, body []byte) context.Context {
+	return context.WithValue(ctx, inboundAlertBodyCtxKey{}, body)
+}
+
+// WithInboundAlertClientIP 将请求来源地址放入上下文，供接收逻辑在查库前限流
+func WithInboundAlertClientIP(ctx context.Context, clientIP string) context.Context {
+	return context.WithValue(ctx, inboundAlertClientIPCtxKey{}, clientIP)
+}
+
+// generateInboundToken 生成 48 位十六进制随机令牌
+func generateInboundToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成入站令牌失败: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func normalizeInboundFormat(format string) (string, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	switch format {
+	case notification.InboundFormatAlertmanager, notification.InboundFormatGrafana, notification.InboundFormatGeneric:
+		return format, nil
+	default:
+		return "", fmt.Errorf("不支持的入站告警格式: %s", format)
+	}
+}
+
+// parseInboundMappingConfig 校验映射 JSON 并转换为 JSONMap 存储
+func parseInboundMappingConfig(raw string) (model.JSONMap, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var mapping notification.InboundMapping
+	if err := json.Unmarshal([]byte(raw), &mapping); err != nil {
+		return nil, fmt.Errorf("字段映射格式无效: %w", err)
+	}
+	var config map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &config); err != nil {
+		return nil, fmt.Errorf("字段映射格式无效: %w", err)
+	}
+	return model.JSONMap(config), nil
+}
+
+func inboundMappingFromConfig(config model.JSONMap) notification.InboundMapping {
+	var mapping notification.InboundMapping
+	if len(config) == 0 {
+		return mapping
+	}
+	raw, err := json.Marshal(config)
+	if err != nil {
+		return mapping
+	}
+	_ = json.Unmarshal(raw, &mapping)
+	return mapping
+}
+
+func toInboundTokenItem(token *model.NotificationInboundToken) types.InboundTokenItem {
+	mapping := ""
+	if len(token.Mapping) > 0 {
+		raw, _ := json.Marshal(token.Mapping)
+		mapping = string(raw)
+	}
+	lastUsedAt := ""
+	if token.LastUsedAt != nil {
+		lastUsedAt = token.LastUsedAt.Format(time.DateTime)
+	}
+
+	return types.InboundTokenItem{
+		ID:          token.ID,
+		Name:        token.Name,
+		Token:       token.Token,
+		URL:         inboundAlertPath + token.Token,
+		Enabled:     token.Enabled,
+		Format:      token.Format,
+		EventType:   token.EventType,
+		Mapping:     mapping,
+		RateLimit:   token.RateLimit,
+		Description: token.Description,
+		LastUsedAt:  lastUsedAt,
+		CreatedAt:   token.CreatedAt.Format(time.DateTime),
+		UpdatedAt:   token.UpdatedAt.Format(time.DateTime),
+	}
+}
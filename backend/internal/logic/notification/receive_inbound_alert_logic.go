@@ -0,0 +1,95 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"logflux/internal/notification"
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/internal/xerr"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type ReceiveInboundAlertLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewReceiveInboundAlertLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ReceiveInboundAlertLogic {
+	return &ReceiveInboundAlertLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ReceiveInboundAlert 校验令牌与限流后，将外部告警转换为通知事件并分发
+func (l *ReceiveInboundAlertLogic) ReceiveInboundAlert(req *types.InboundAlertReq) (resp *types.InboundAlertResp, err error) {
+	// 查库前先按来源地址限流，再对已记录限额的令牌限流，避免随机或超限的令牌反复查询数据库
+	now := time.Now()
+	clientIP, _ := l.ctx.Value(inboundAlertClientIPCtxKey{}).(string)
+	if !inboundClientRateLimiter.Allow(clientIP, inboundClientRateLimit, now) {
+		return nil, xerr.NewEnumError(xerr.TooManyRequests)
+	}
+	allowed, known := inboundRateLimiter.AllowKnown(req.Token, now)
+	if !allowed {
+		return nil, xerr.NewEnumError(xerr.TooManyRequests)
+	}
+
+	var token model.NotificationInboundToken
+	if err := l.svcCtx.DB.WithContext(l.ctx).
+		Where("token = ? AND enabled = ?", req.Token, true).
+		First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, xerr.NewCodeError(xerr.Unauthorized, "入站令牌无效或已禁用")
+		}
+		return nil, err
+	}
+
+	// 首次出现的令牌按其配置的限额建桶并计入本次请求；已有计数桶时同步最新限额
+	if !known {
+		if !inboundRateLimiter.Allow(token.Token, token.RateLimit, now) {
+			return nil, xerr.NewEnumError(xerr.TooManyRequests)
+		}
+	} else {
+		inboundRateLimiter.SetLimit(token.Token, token.RateLimit)
+	}
+
+	mgr := l.svcCtx.NotificationMgr
+	if mgr == nil {
+		return nil, xerr.NewSystemErrorWith("通知管理器未初始化")
+	}
+
+	body, _ := l.ctx.Value(inboundAlertBodyCtxKey{}).([]byte)
+	events, err := notification.ParseInboundEvents(token.Format, body, token.EventType, inboundMappingFromConfig(token.Mapping))
+	if err != nil {
+		return nil, xerr.NewBusinessErrorWith(err.Error())
+	}
+
+	accepted := 0
+	for _, event := range events {
+		event.WithData("inbound_token", token.Name)
+		if err := mgr.Notify(l.ctx, event); err != nil {
+			l.Errorf("入站告警分发失败: token=%s type=%s err=%v", token.Name, event.Type, err)
+			continue
+		}
+		accepted++
+	}
+
+	usedAt := time.Now()
+	if err := l.svcCtx.DB.WithContext(l.ctx).Model(&model.NotificationInboundToken{}).
+		Where("id = ?", token.ID).
+		Update("last_used_at", &usedAt).Error; err != nil {
+		l.Errorf("更新入站令牌使用时间失败: tokenID=%d err=%v", token.ID, err)
+	}
+
+	return &types.InboundAlertResp{
+		Accepted: accepted,
+	}, nil
+}
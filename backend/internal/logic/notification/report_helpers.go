@@ -0,0 +1,362 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	caddylogic "logflux/internal/logic/caddy"
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/robfig/cron/v3"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultReportHTMLTemplate     = "default_report_email"
+	defaultReportMarkdownTemplate = "default_report_markdown"
+)
+
+// reportTimeLayout 报表数据与报表列表中的时间格式
+const reportTimeLayout = "2006-01-02 15:04:05"
+
+// reportBlockedStatuses 取自 WAF 策略统计，拦截口径保持一致
+var reportBlockedStatuses = caddylogic.WafBlockedStatuses()
+
+var reportCronParser = cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// ReportData 报表模板数据，模板中通过 {{with index .Data "report"}} 访问
+type ReportData struct {
+	Name         string              `json:"name"`
+	Period       string              `json:"period"`
+	Start        string              `json:"start"`
+	End          string              `json:"end"`
+	PrevStart    string              `json:"prev_start"`
+	PrevEnd      string              `json:"prev_end"`
+	HostScope    string              `json:"host_scope"`
+	Metrics      []ReportMetric      `json:"metrics"`
+	TopErrors    []ReportTopItem     `json:"top_errors"`
+	TopAttacked  []ReportTopItem     `json:"top_attacked"`
+	PolicyBlocks []ReportPolicyBlock `json:"policy_blocks"`
+}
+
+// ReportMetric 环比指标
+type ReportMetric struct {
+	Name     string `json:"name"`
+	Current  int64  `json:"current"`
+	Previous int64  `json:"previous"`
+	Delta    string `json:"delta"`
+}
+
+// ReportTopItem 排行项
+type ReportTopItem struct {
+	Status int    `json:"status,omitempty"`
+	Uri    string `json:"uri"`
+	Count  int64  `json:"count"`
+}
+
+// ReportPolicyBlock 单个 WAF 策略的拦截数
+type ReportPolicyBlock struct {
+	Policy   string `json:"policy"`
+	Current  int64  `json:"current"`
+	Previous int64  `json:"previous"`
+	Delta    string `json:"delta"`
+}
+
+type reportSummaryRow struct {
+	Requests int64
+	UniqueIP int64
+	Blocked  int64
+	Error4xx int64
+	Error5xx int64
+	Bytes    int64
+}
+
+func normalizeReportPeriod(period string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(period)) {
+	case model.ReportPeriodDaily:
+		return model.ReportPeriodDaily, nil
+	case model.ReportPeriodWeekly:
+		return model.ReportPeriodWeekly, nil
+	case model.ReportPeriodMonthly:
+		return model.ReportPeriodMonthly, nil
+	default:
+		return "", fmt.Errorf("报表周期无效，仅支持 daily/weekly/monthly")
+	}
+}
+
+func validateReportSchedule(schedule string) error {
+	if schedule == "" {
+		return nil
+	}
+	if _, err := reportCronParser.Parse(schedule); err != nil {
+		return fmt.Errorf("报表发送时间表达式无效: %v", err)
+	}
+	return nil
+}
+
+func normalizeReportHosts(hosts []string) model.StringArray {
+	result := make(model.StringArray, 0, len(hosts))
+	seen := make(map[string]bool)
+	for _, host := range hosts {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if host == "" || seen[host] {
+			continue
+		}
+		seen[host] = true
+		result = append(result, host)
+	}
+	return result
+}
+
+// reportWindow 计算上一个完整周期及其之前一个周期（用于环比）
+// daily: 昨天；weekly: 上周一至周日；monthly: 上个自然月。
+func reportWindow(period string, now time.Time) (start, end, prevStart, prevEnd time.Time) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch period {
+	case model.ReportPeriodWeekly:
+		offset := (int(today.Weekday()) + 6) % 7 // 距本周一的天数
+		end = today.AddDate(0, 0, -offset)
+		start = end.AddDate(0, 0, -7)
+		prevStart = start.AddDate(0, 0, -7)
+	case model.ReportPeriodMonthly:
+		end = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		start = end.AddDate(0, -1, 0)
+		prevStart = start.AddDate(0, -1, 0)
+	default:
+		end = today
+		start = end.AddDate(0, 0, -1)
+		prevStart = start.AddDate(0, 0, -1)
+	}
+	return start, end, prevStart, start
+}
+
+// formatReportDelta 格式化环比变化
+func formatReportDelta(current, previous int64) string {
+	if previous == 0 {
+		if current == 0 {
+			return "0%"
+		}
+		return "new"
+	}
+	return fmt.Sprintf("%+.1f%%", float64(current-previous)/float64(previous)*100)
+}
+
+func reportPeriodLabel(period string) string {
+	switch period {
+	case model.ReportPeriodWeekly:
+		return "周报"
+	case model.ReportPeriodMonthly:
+		return "月报"
+	default:
+		return "日报"
+	}
+}
+
+// buildReportData 统计报表数据：流量概况、Top 错误、Top 被攻击路径、各策略拦截数及环比
+func buildReportData(ctx context.Context, svcCtx *svc.ServiceContext, report *model.NotificationReport, now time.Time) (*ReportData, error) {
+	start, end, prevStart, prevEnd := reportWindow(report.Period, now)
+	topN := report.TopN
+	if topN <= 0 {
+		topN = 10
+	}
+
+	scoped := func(from, to time.Time) *gorm.DB {
+		db := svcCtx.DB.WithContext(ctx).Model(&model.CaddyLog{}).Where("log_time >= ? AND log_time < ?", from, to)
+		if len(report.Hosts) > 0 {
+			db = db.Where("LOWER(host) IN ?", []string(report.Hosts))
+		}
+		return db
+	}
+
+	current, err := queryReportSummary(scoped(start, end))
+	if err != nil {
+		return nil, err
+	}
+	previous, err := queryReportSummary(scoped(prevStart, prevEnd))
+	if err != nil {
+		return nil, err
+	}
+
+	data := &ReportData{
+		Name:      report.Name,
+		Period:    report.Period,
+		Start:     start.Format(reportTimeLayout),
+		End:       end.Format(reportTimeLayout),
+		PrevStart: prevStart.Format(reportTimeLayout),
+		PrevEnd:   prevEnd.Format(reportTimeLayout),
+		HostScope: "all",
+	}
+	if len(report.Hosts) > 0 {
+		data.HostScope = strings.Join(report.Hosts, ", ")
+	}
+
+	metric := func(name string, cur, prev int64) ReportMetric {
+		return ReportMetric{Name: name, Current: cur, Previous: prev, Delta: formatReportDelta(cur, prev)}
+	}
+	data.Metrics = []ReportMetric{
+		metric("Requests", current.Requests, previous.Requests),
+		metric("Unique IPs", current.UniqueIP, previous.UniqueIP),
+		metric("Blocked", current.Blocked, previous.Blocked),
+		metric("4xx", current.Error4xx, previous.Error4xx),
+		metric("5xx", current.Error5xx, previous.Error5xx),
+		metric("Bytes", current.Bytes, previous.Bytes),
+	}
+
+	data.TopErrors = make([]ReportTopItem, 0, topN)
+	if err := scoped(start, end).
+		Select("status, uri, COUNT(*) AS count").
+		Where("status >= 400 AND status NOT IN ?", reportBlockedStatuses).
+		Group("status, uri").
+		Order("count DESC, status ASC").
+		Limit(topN).
+		Scan(&data.TopErrors).Error; err != nil {
+		return nil, fmt.Errorf("统计 Top 错误失败: %w", err)
+	}
+
+	data.TopAttacked = make([]ReportTopItem, 0, topN)
+	if err := scoped(start, end).
+		Select("uri, COUNT(*) AS count").
+		Where("status IN ?", reportBlockedStatuses).
+		Group("uri").
+		Order("count DESC, uri ASC").
+		Limit(topN).
+		Scan(&data.TopAttacked).Error; err != nil {
+		return nil, fmt.Errorf("统计 Top 被攻击路径失败: %w", err)
+	}
+
+	data.PolicyBlocks, err = queryReportPolicyBlocks(ctx, svcCtx, report.PolicyIDs, report.Hosts, start, end, prevStart, prevEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func queryReportSummary(db *gorm.DB) (reportSummaryRow, error) {
+	var row reportSummaryRow
+	err := db.Select(
+		`COUNT(*) AS requests,
+		 COUNT(DISTINCT NULLIF(remote_ip, '')) AS unique_ip,
+		 COUNT(*) FILTER (WHERE status IN ?) AS blocked,
+		 COUNT(*) FILTER (WHERE status >= 400 AND status < 500) AS error4xx,
+		 COUNT(*) FILTER (WHERE status >= 500) AS error5xx,
+		 COALESCE(SUM(size), 0) AS bytes`,
+		reportBlockedStatuses,
+	).Scan(&row).Error
+	if err != nil {
+		return row, fmt.Errorf("统计流量概况失败: %w", err)
+	}
+	return row, nil
+}
+
+// queryReportPolicyBlocks 复用 WAF 策略统计（含作用域匹配）计算各策略的拦截数；
+// 报表限定了站点时按站点分别统计后累加，与流量指标的站点范围一致
+func queryReportPolicyBlocks(ctx context.Context, svcCtx *svc.ServiceContext, policyIDs model.Int64Array, hosts model.StringArray, start, end, prevStart, prevEnd time.Time) ([]ReportPolicyBlock, error) {
+	scopes := []string(hosts)
+	if len(scopes) == 0 {
+		scopes = []string{""}
+	}
+	stats := func(from, to time.Time) (map[uint]int64, error) {
+		counts := make(map[uint]int64)
+		for _, host := range scopes {
+			// 统计逻辑的时间范围为闭区间，结束时间回退一秒避免与下一周期重叠
+			resp, err := caddylogic.NewGetWafPolicyStatsLogic(ctx, svcCtx).GetWafPolicyStats(&types.WafPolicyStatsReq{
+				StartTime:   from.Format(time.RFC3339),
+				EndTime:     to.Add(-time.Second).Format(time.RFC3339),
+				IntervalSec: 86400,
+				Host:        host,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("统计策略拦截数失败: %w", err)
+			}
+			for _, item := range resp.List {
+				counts[item.PolicyId] += item.BlockedCount
+			}
+		}
+		return counts, nil
+	}
+
+	current, err := stats(start, end)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := stats(prevStart, prevEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[uint]bool, len(policyIDs))
+	for _, id := range policyIDs {
+		wanted[uint(id)] = true
+	}
+
+	var policies []model.WafPolicy
+	if err := svcCtx.DB.WithContext(ctx).Where("enabled = ?", true).Order("is_default desc, id asc").Find(&policies).Error; err != nil {
+		return nil, fmt.Errorf("查询 WAF 策略失败: %w", err)
+	}
+
+	blocks := make([]ReportPolicyBlock, 0, len(policies))
+	for _, policy := range policies {
+		if len(wanted) > 0 && !wanted[policy.ID] {
+			continue
+		}
+		cur := current[policy.ID]
+		prev := previous[policy.ID]
+		blocks = append(blocks, ReportPolicyBlock{
+			Policy:   policy.Name,
+			Current:  cur,
+			Previous: prev,
+			Delta:    formatReportDelta(cur, prev),
+		})
+	}
+	return blocks, nil
+}
+
+func toReportItem(report *model.NotificationReport) types.ReportItem {
+	item := types.ReportItem{
+		ID:               report.ID,
+		Name:             report.Name,
+		Enabled:          report.Enabled,
+		Period:           report.Period,
+		Schedule:         report.CronSchedule(),
+		Hosts:            []string(report.Hosts),
+		PolicyIDs:        []int64(report.PolicyIDs),
+		ChannelIDs:       []int64(report.ChannelIDs),
+		HTMLTemplate:     report.HTMLTemplate,
+		MarkdownTemplate: report.MarkdownTemplate,
+		TopN:             report.TopN,
+		Description:      report.Description,
+		LastRunAt:        formatOptionalTime(report.LastRunAt),
+		LastStatus:       report.LastStatus,
+		LastError:        report.LastError,
+		CreatedAt:        report.CreatedAt.Format(reportTimeLayout),
+		UpdatedAt:        report.UpdatedAt.Format(reportTimeLayout),
+	}
+	if item.Hosts == nil {
+		item.Hosts = []string{}
+	}
+	if item.PolicyIDs == nil {
+		item.PolicyIDs = []int64{}
+	}
+	if item.ChannelIDs == nil {
+		item.ChannelIDs = []int64{}
+	}
+	if report.Enabled {
+		if schedule, err := reportCronParser.Parse(report.CronSchedule()); err == nil {
+			item.NextRunAt = schedule.Next(time.Now()).Format(reportTimeLayout)
+		}
+	}
+	return item
+}
+
+// reloadReportSchedule 同步调度器中的报表任务
+func reloadReportSchedule(svcCtx *svc.ServiceContext, reportID uint) error {
+	if svcCtx.ReportScheduler == nil {
+		return nil
+	}
+	return svcCtx.ReportScheduler.ReloadReport(reportID)
+}
@@ -0,0 +1,80 @@
+package notification
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"logflux/internal/notification"
+	"logflux/internal/notification/template"
+	"logflux/model"
+)
+
+func TestReportWindow(t *testing.T) {
+	// 2026-03-11 是周三
+	now := time.Date(2026, 3, 11, 8, 0, 0, 0, time.UTC)
+	day := func(month time.Month, d int) time.Time { return time.Date(2026, month, d, 0, 0, 0, 0, time.UTC) }
+
+	cases := []struct {
+		period                         string
+		start, end, prevStart, prevEnd time.Time
+	}{
+		{model.ReportPeriodDaily, day(3, 10), day(3, 11), day(3, 9), day(3, 10)},
+		{model.ReportPeriodWeekly, day(3, 2), day(3, 9), day(2, 23), day(3, 2)},
+		{model.ReportPeriodMonthly, day(2, 1), day(3, 1), day(1, 1), day(2, 1)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.period, func(t *testing.T) {
+			start, end, prevStart, prevEnd := reportWindow(tc.period, now)
+			if !start.Equal(tc.start) || !end.Equal(tc.end) || !prevStart.Equal(tc.prevStart) || !prevEnd.Equal(tc.prevEnd) {
+				t.Fatalf("reportWindow(%s) = %v %v %v %v", tc.period, start, end, prevStart, prevEnd)
+			}
+		})
+	}
+}
+
+func TestFormatReportDelta(t *testing.T) {
+	cases := map[string][2]int64{
+		"+50.0%": {150, 100},
+		"-25.0%": {75, 100},
+		"0%":     {0, 0},
+		"new":    {10, 0},
+	}
+	for want, in := range cases {
+		if got := formatReportDelta(in[0], in[1]); got != want {
+			t.Fatalf("formatReportDelta(%d, %d) = %q, want %q", in[0], in[1], got, want)
+		}
+	}
+}
+
+func TestDefaultReportTemplatesRender(t *testing.T) {
+	data := &ReportData{
+		Name:      "weekly",
+		Start:     "2026-03-02 00:00:00",
+		End:       "2026-03-09 00:00:00",
+		HostScope: "example.com",
+		Metrics:   []ReportMetric{{Name: "Requests", Current: 120, Previous: 100, Delta: "+20.0%"}},
+		TopErrors: []ReportTopItem{{Status: 502, Uri: "/api/upstream", Count: 7}},
+		TopAttacked: []ReportTopItem{
+			{Uri: "/wp-login.php", Count: 42},
+		},
+		PolicyBlocks: []ReportPolicyBlock{{Policy: "default", Current: 42, Previous: 30, Delta: "+40.0%"}},
+	}
+	event := notification.NewEvent(notification.EventReportScheduled, notification.LevelInfo, "weekly（周报）", "").
+		WithData("report", data)
+
+	for _, def := range template.GetDefaultTemplates() {
+		if def.Name != defaultReportMarkdownTemplate && def.Name != defaultReportHTMLTemplate {
+			continue
+		}
+		rendered, err := template.RenderContent(def.Format, def.Content, event)
+		if err != nil {
+			t.Fatalf("render %s: %v", def.Name, err)
+		}
+		for _, want := range []string{"Requests", "20.0%", "/api/upstream", "/wp-login.php", "default"} {
+			if !strings.Contains(rendered, want) {
+				t.Fatalf("%s missing %q:\n%s", def.Name, want, rendered)
+			}
+		}
+	}
+}
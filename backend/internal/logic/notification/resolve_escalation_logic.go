@@ -0,0 +1,59 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"logflux/internal/service"
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ResolveEscalationLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewResolveEscalationLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ResolveEscalationLogic {
+	return &ResolveEscalationLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ResolveEscalation 标记告警已解决；未确认的实例同时记录确认人
+func (l *ResolveEscalationLogic) ResolveEscalation(req *types.IDReq) (resp *types.BaseResp, err error) {
+	var escalation model.NotificationEscalation
+	if err := l.svcCtx.DB.WithContext(l.ctx).First(&escalation, req.ID).Error; err != nil {
+		return nil, err
+	}
+	if escalation.Status == model.EscalationStatusResolved {
+		return nil, fmt.Errorf("升级实例已解决")
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":       model.EscalationStatusResolved,
+		"next_step_at": nil,
+		"resolved_at":  &now,
+	}
+	if escalation.AcknowledgedAt == nil {
+		updates["acknowledged_at"] = &now
+		updates["acknowledged_by"] = service.CurrentUsername(l.ctx, l.svcCtx)
+	}
+
+	if err := l.svcCtx.DB.WithContext(l.ctx).Model(&escalation).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+
+	return &types.BaseResp{
+		Code: 200,
+		Msg:  "成功",
+	}, nil
+}
@@ -0,0 +1,46 @@
+package notification
+
+import (
+	"context"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type RotateInboundTokenLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewRotateInboundTokenLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RotateInboundTokenLogic {
+	return &RotateInboundTokenLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// RotateInboundToken 重新生成令牌，旧地址立即失效
+func (l *RotateInboundTokenLogic) RotateInboundToken(req *types.IDReq) (resp *types.BaseResp, err error) {
+	var token model.NotificationInboundToken
+	if err := l.svcCtx.DB.WithContext(l.ctx).First(&token, req.ID).Error; err != nil {
+		return nil, err
+	}
+
+	secret, err := generateInboundToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := l.svcCtx.DB.WithContext(l.ctx).Model(&token).Update("token", secret).Error; err != nil {
+		return nil, err
+	}
+
+	return &types.BaseResp{
+		Code: 200,
+		Msg:  "成功",
+	}, nil
+}
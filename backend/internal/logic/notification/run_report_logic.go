@@ -0,0 +1,126 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"logflux/internal/notification"
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/internal/xerr"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type RunReportLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewRunReportLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RunReportLogic {
+	return &RunReportLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// RunReport 立即生成报表并发送到全部接收渠道（定时调度同样走此入口）
+func (l *RunReportLogic) RunReport(req *types.IDReq) (resp *types.BaseResp, err error) {
+	var report model.NotificationReport
+	if err := l.svcCtx.DB.WithContext(l.ctx).First(&report, req.ID).Error; err != nil {
+		return nil, err
+	}
+
+	mgr := l.svcCtx.NotificationMgr
+	if mgr == nil {
+		return nil, xerr.NewSystemErrorWith("通知管理器未初始化")
+	}
+
+	data, err := buildReportData(l.ctx, l.svcCtx, &report, time.Now())
+	if err != nil {
+		l.recordRun(&report, model.ReportStatusFailed, err.Error())
+		return nil, err
+	}
+
+	var channels []model.NotificationChannel
+	if err := l.svcCtx.DB.WithContext(l.ctx).Where("id IN ?", []int64(report.ChannelIDs)).Find(&channels).Error; err != nil {
+		return nil, err
+	}
+	if len(channels) == 0 {
+		l.recordRun(&report, model.ReportStatusFailed, "报表没有可用的接收渠道")
+		return nil, fmt.Errorf("报表没有可用的接收渠道")
+	}
+
+	event := notification.NewEvent(
+		notification.EventReportScheduled,
+		notification.LevelInfo,
+		fmt.Sprintf("%s（%s）", report.Name, reportPeriodLabel(report.Period)),
+		reportSummaryMessage(data),
+	)
+	event.WithData("report", data)
+
+	failures := make([]string, 0)
+	for _, channel := range channels {
+		templateName := report.MarkdownTemplate
+		if templateName == "" {
+			templateName = defaultReportMarkdownTemplate
+		}
+		if channel.Type == model.ChannelTypeEmail {
+			templateName = report.HTMLTemplate
+			if templateName == "" {
+				templateName = defaultReportHTMLTemplate
+			}
+		}
+
+		sendCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		err := mgr.SendToChannelWithTemplate(sendCtx, channel.ID, templateName, event)
+		cancel()
+		if err != nil {
+			l.Errorf("发送报表失败: report=%s channel=%s err=%v", report.Name, channel.Name, err)
+			failures = append(failures, fmt.Sprintf("%s: %v", channel.Name, err))
+		}
+	}
+
+	switch {
+	case len(failures) == 0:
+		l.recordRun(&report, model.ReportStatusSuccess, "")
+	case len(failures) < len(channels):
+		l.recordRun(&report, model.ReportStatusPartial, strings.Join(failures, "; "))
+	default:
+		l.recordRun(&report, model.ReportStatusFailed, strings.Join(failures, "; "))
+		return nil, fmt.Errorf("报表发送失败: %s", strings.Join(failures, "; "))
+	}
+
+	return &types.BaseResp{
+		Code: 200,
+		Msg:  "成功",
+	}, nil
+}
+
+func (l *RunReportLogic) recordRun(report *model.NotificationReport, status, errMsg string) {
+	now := time.Now()
+	if err := l.svcCtx.DB.WithContext(l.ctx).Model(&model.NotificationReport{}).
+		Where("id = ?", report.ID).
+		Updates(map[string]interface{}{
+			"last_run_at": &now,
+			"last_status": status,
+			"last_error":  errMsg,
+		}).Error; err != nil {
+		l.Errorf("更新报表执行结果失败: reportID=%d err=%v", report.ID, err)
+	}
+}
+
+// reportSummaryMessage 纯文本摘要，供不使用模板内容的渠道回退展示
+func reportSummaryMessage(data *ReportData) string {
+	lines := make([]string, 0, len(data.Metrics)+1)
+	lines = append(lines, fmt.Sprintf("%s ~ %s", data.Start, data.End))
+	for _, metric := range data.Metrics {
+		lines = append(lines, fmt.Sprintf("%s: %d (%s)", metric.Name, metric.Current, metric.Delta))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,56 @@
+package notification
+
+import (
+	"context"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UpdateEscalationPolicyLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUpdateEscalationPolicyLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateEscalationPolicyLogic {
+	return &UpdateEscalationPolicyLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UpdateEscalationPolicyLogic) UpdateEscalationPolicy(req *types.EscalationPolicyUpdateReq) (resp *types.BaseResp, err error) {
+	var policy model.NotificationEscalationPolicy
+	if err := l.svcCtx.DB.WithContext(l.ctx).First(&policy, req.ID).Error; err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" {
+		policy.Name = req.Name
+	}
+	policy.Enabled = req.Enabled
+	if req.Steps != nil {
+		steps, err := normalizeEscalationSteps(req.Steps)
+		if err != nil {
+			return nil, err
+		}
+		policy.Steps = steps
+	}
+	if req.Description != "" {
+		policy.Description = req.Description
+	}
+
+	if err := l.svcCtx.DB.WithContext(l.ctx).Save(&policy).Error; err != nil {
+		return nil, err
+	}
+
+	return &types.BaseResp{
+		Code: 200,
+		Msg:  "成功",
+	}, nil
+}
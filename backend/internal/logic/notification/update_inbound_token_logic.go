@@ -0,0 +1,73 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UpdateInboundTokenLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUpdateInboundTokenLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateInboundTokenLogic {
+	return &UpdateInboundTokenLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UpdateInboundTokenLogic) UpdateInboundToken(req *types.InboundTokenUpdateReq) (resp *types.BaseResp, err error) {
+	var token model.NotificationInboundToken
+	if err := l.svcCtx.DB.WithContext(l.ctx).First(&token, req.ID).Error; err != nil {
+		return nil, err
+	}
+
+	if name := strings.TrimSpace(req.Name); name != "" {
+		token.Name = name
+	}
+	if req.Format != "" {
+		format, err := normalizeInboundFormat(req.Format)
+		if err != nil {
+			return nil, err
+		}
+		token.Format = format
+	}
+	if req.EventType != "" {
+		token.EventType = strings.TrimSpace(req.EventType)
+	}
+	if req.Mapping != "" {
+		mapping, err := parseInboundMappingConfig(req.Mapping)
+		if err != nil {
+			return nil, err
+		}
+		token.Mapping = mapping
+	}
+	if req.RateLimit >= 0 {
+		token.RateLimit = req.RateLimit
+	} else if req.RateLimit != -1 {
+		return nil, fmt.Errorf("限流值不能为负数")
+	}
+	if req.Description != "" {
+		token.Description = req.Description
+	}
+	token.Enabled = req.Enabled
+
+	if err := l.svcCtx.DB.WithContext(l.ctx).Save(&token).Error; err != nil {
+		return nil, err
+	}
+
+	return &types.BaseResp{
+		Code: 200,
+		Msg:  "成功",
+	}, nil
+}
@@ -0,0 +1,65 @@
+package notification
+
+import (
+	"context"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UpdateOnCallScheduleLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUpdateOnCallScheduleLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateOnCallScheduleLogic {
+	return &UpdateOnCallScheduleLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UpdateOnCallScheduleLogic) UpdateOnCallSchedule(req *types.OnCallScheduleUpdateReq) (resp *types.BaseResp, err error) {
+	var schedule model.NotificationOnCallSchedule
+	if err := l.svcCtx.DB.WithContext(l.ctx).First(&schedule, req.ID).Error; err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" {
+		schedule.Name = req.Name
+	}
+	schedule.Enabled = req.Enabled
+	if req.RotationStart != "" {
+		rotationStart, err := parseRotationStart(req.RotationStart)
+		if err != nil {
+			return nil, err
+		}
+		schedule.RotationStart = rotationStart
+	}
+	if req.RotationDays > 0 {
+		schedule.RotationDays = req.RotationDays
+	}
+	if req.Participants != nil {
+		schedule.Participants = model.Int64Array(req.Participants)
+	}
+	if req.Description != "" {
+		schedule.Description = req.Description
+	}
+	if err := validateRotation(schedule.RotationDays, schedule.Participants); err != nil {
+		return nil, err
+	}
+
+	if err := l.svcCtx.DB.WithContext(l.ctx).Save(&schedule).Error; err != nil {
+		return nil, err
+	}
+
+	return &types.BaseResp{
+		Code: 200,
+		Msg:  "成功",
+	}, nil
+}
@@ -0,0 +1,89 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UpdateReportLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUpdateReportLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateReportLogic {
+	return &UpdateReportLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UpdateReportLogic) UpdateReport(req *types.ReportUpdateReq) (resp *types.BaseResp, err error) {
+	var report model.NotificationReport
+	if err := l.svcCtx.DB.WithContext(l.ctx).First(&report, req.ID).Error; err != nil {
+		return nil, err
+	}
+
+	if name := strings.TrimSpace(req.Name); name != "" {
+		report.Name = name
+	}
+	report.Enabled = req.Enabled
+	if req.Period != "" {
+		period, err := normalizeReportPeriod(req.Period)
+		if err != nil {
+			return nil, err
+		}
+		report.Period = period
+	}
+	if schedule := strings.TrimSpace(req.Schedule); schedule != "" {
+		if err := validateReportSchedule(schedule); err != nil {
+			return nil, err
+		}
+		report.Schedule = schedule
+	}
+	if req.Hosts != nil {
+		report.Hosts = normalizeReportHosts(req.Hosts)
+	}
+	if req.PolicyIDs != nil {
+		report.PolicyIDs = model.Int64Array(req.PolicyIDs)
+	}
+	if req.ChannelIDs != nil {
+		if len(req.ChannelIDs) == 0 {
+			return nil, fmt.Errorf("报表至少需要一个接收渠道")
+		}
+		report.ChannelIDs = model.Int64Array(req.ChannelIDs)
+	}
+	if req.HTMLTemplate != "" {
+		report.HTMLTemplate = strings.TrimSpace(req.HTMLTemplate)
+	}
+	if req.MarkdownTemplate != "" {
+		report.MarkdownTemplate = strings.TrimSpace(req.MarkdownTemplate)
+	}
+	if req.TopN > 0 {
+		report.TopN = req.TopN
+	}
+	if req.Description != "" {
+		report.Description = req.Description
+	}
+
+	if err := l.svcCtx.DB.WithContext(l.ctx).Save(&report).Error; err != nil {
+		return nil, err
+	}
+
+	if err := reloadReportSchedule(l.svcCtx, report.ID); err != nil {
+		l.Errorf("同步报表调度失败: reportID=%d err=%v", report.ID, err)
+	}
+
+	return &types.BaseResp{
+		Code: 200,
+		Msg:  "成功",
+	}, nil
+}
@@ -58,6 +58,11 @@ func (l *UpdateRuleLogic) UpdateRule(req *types.RuleUpdateReq) (resp *types.Base
 	if req.Description != "" {
 		rule.Description = req.Description
 	}
+	if req.EscalationPolicyID > 0 {
+		rule.EscalationPolicyID = &req.EscalationPolicyID
+	} else {
+		rule.EscalationPolicyID = nil
+	}
 
 	if err := l.svcCtx.DB.WithContext(l.ctx).Save(&rule).Error; err != nil {
 		return nil, err
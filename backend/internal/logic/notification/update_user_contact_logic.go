@@ -0,0 +1,47 @@
+package notification
+
+import (
+	"context"
+
+	"logflux/internal/svc"
+	"logflux/internal/types"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UpdateUserContactLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUpdateUserContactLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateUserContactLogic {
+	return &UpdateUserContactLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UpdateUserContactLogic) UpdateUserContact(req *types.UserContactReq) (resp *types.BaseResp, err error) {
+	var user model.User
+	if err := l.svcCtx.DB.WithContext(l.ctx).Select("id").First(&user, req.UserID).Error; err != nil {
+		return nil, err
+	}
+
+	contact := model.NotificationUserContact{UserID: user.ID}
+	if err := l.svcCtx.DB.WithContext(l.ctx).Where("user_id = ?", user.ID).FirstOrInit(&contact).Error; err != nil {
+		return nil, err
+	}
+	contact.ChannelIDs = model.Int64Array(req.ChannelIDs)
+
+	if err := l.svcCtx.DB.WithContext(l.ctx).Save(&contact).Error; err != nil {
+		return nil, err
+	}
+
+	return &types.BaseResp{
+		Code: 200,
+		Msg:  "成功",
+	}, nil
+}
@@ -132,8 +132,10 @@ func permissionRuleFor(method, path string) permissionRule {
 		return permissionRule{permissions: []string{"logs"}}
 	case path == "/api/source" && method == http.MethodGet:
 		return permissionRule{permissions: []string{"logs"}}
-	case strings.HasPrefix(path, "/api/caddy/server") && method == http.MethodGet:
-		return permissionRule{roles: []string{"admin", "analyst"}}
+	case (strings.HasPrefix(path, "/api/caddy/server") || path == "/api/caddy/certificate") && method == http.MethodGet:
+		return permissionRule{roles: []string{"admin", "analyst", "approver"}}
+	case isChangeReviewRoute(method, path):
+		return permissionRule{roles: []string{"admin", "approver"}}
 	case strings.HasPrefix(path, "/api/caddy/"):
 		return permissionRule{roles: []string{"admin"}}
 	case strings.HasPrefix(path, "/api/user"):
@@ -153,6 +155,18 @@ func permissionRuleFor(method, path string) permissionRule {
 	}
 }
 
+// isChangeReviewRoute 审批人可查看、评论、批准或驳回变更申请，提交与应用仍需管理员
+func isChangeReviewRoute(method, path string) bool {
+	if !strings.HasPrefix(path, "/api/caddy/change-request") {
+		return false
+	}
+	if method == http.MethodGet {
+		return true
+	}
+	return method == http.MethodPost &&
+		(strings.HasSuffix(path, "/comment") || strings.HasSuffix(path, "/approve") || strings.HasSuffix(path, "/reject"))
+}
+
 func isAuthOnlyRoute(method, path string) bool {
 	if method == http.MethodGet && (path == "/api/user/info" || path == "/api/route/getUserRoutes" || path == "/api/notification/unread") {
 		return true
@@ -0,0 +1,424 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"logflux/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	defaultDigestTemplate = "default_digest"
+	// digestBatchLimit 单次摘要最多合并的任务数，超出部分留待下一轮
+	digestBatchLimit = 500
+)
+
+// EventNotificationDigest 摘要消息的事件类型
+const EventNotificationDigest = "notification.digest"
+
+type digestPolicy struct {
+	Enabled        bool
+	Interval       time.Duration
+	Template       string
+	BypassCritical bool
+	MaxItems       int
+}
+
+func defaultDigestPolicy() digestPolicy {
+	return digestPolicy{
+		Enabled:        false,
+		Interval:       5 * time.Minute,
+		Template:       defaultDigestTemplate,
+		BypassCritical: true,
+		MaxItems:       10,
+	}
+}
+
+// parseDigestPolicy 解析渠道配置中的 digest 段
+// 例如 {"digest": {"enabled": true, "interval": "10m", "bypassCritical": true, "maxItems": 20}}
+func parseDigestPolicy(config model.JSONMap) digestPolicy {
+	policy := defaultDigestPolicy()
+	if config == nil {
+		return policy
+	}
+
+	digestMap, ok := config["digest"].(map[string]interface{})
+	if !ok || digestMap == nil {
+		return policy
+	}
+
+	if v, ok := digestMap["enabled"].(bool); ok {
+		policy.Enabled = v
+	}
+	if v, ok := digestMap["interval"].(string); ok {
+		if d, err := time.ParseDuration(v); err == nil {
+			policy.Interval = d
+		}
+	}
+	if v, ok := digestMap["template"].(string); ok && strings.TrimSpace(v) != "" {
+		policy.Template = strings.TrimSpace(v)
+	}
+	if v, ok := digestMap["bypassCritical"].(bool); ok {
+		policy.BypassCritical = v
+	}
+	if v, ok := digestMap["maxItems"]; ok {
+		switch n := v.(type) {
+		case float64:
+			policy.MaxItems = int(n)
+		case int:
+			policy.MaxItems = n
+		}
+	}
+
+	if policy.Interval < time.Minute {
+		policy.Interval = time.Minute
+	}
+	if policy.MaxItems <= 0 {
+		policy.MaxItems = 10
+	}
+
+	return policy
+}
+
+// collects 判断任务是否应进入摘要而非立即发送
+// 升级链路的任务需要及时触达值班人，始终直接发送。
+func (p digestPolicy) collects(job *model.NotificationJob) bool {
+	if !p.Enabled || job.EscalationID != nil {
+		return false
+	}
+	if p.BypassCritical && job.EventLevel == LevelCritical {
+		return false
+	}
+	return true
+}
+
+// holdForDigest 将已领取的任务转入 digesting，等待扫描器合并发送
+func (m *Manager) holdForDigest(ctx context.Context, job *model.NotificationJob) {
+	if err := m.db.WithContext(ctx).Model(&model.NotificationJob{}).
+		Where("id = ? AND status = ?", job.ID, model.NotificationJobStatusProcessing).
+		Updates(map[string]interface{}{
+			"status": model.NotificationJobStatusDigesting,
+		}).Error; err != nil {
+		m.logger.Errorf("任务转入摘要失败: jobID=%d err=%v", job.ID, err)
+	}
+}
+
+// flushDigests 检查各渠道积攒的摘要任务，到期后合并为一条消息发送
+func (m *Manager) flushDigests(ctx context.Context) {
+	type digestBucket struct {
+		ChannelID uint
+		Oldest    time.Time
+	}
+
+	var buckets []digestBucket
+	err := m.db.WithContext(ctx).
+		Session(&gorm.Session{Logger: logger.Default.LogMode(logger.Silent)}).
+		Model(&model.NotificationJob{}).
+		Select("channel_id, MIN(created_at) AS oldest").
+		Where("status = ? AND next_run_at <= ?", model.NotificationJobStatusDigesting, time.Now()).
+		Group("channel_id").
+		Scan(&buckets).Error
+	if err != nil {
+		m.logger.Errorf("扫描摘要任务失败: %v", err)
+		return
+	}
+
+	for _, bucket := range buckets {
+		m.flushChannelDigest(ctx, bucket.ChannelID, bucket.Oldest)
+	}
+}
+
+func (m *Manager) flushChannelDigest(ctx context.Context, channelID uint, oldest time.Time) {
+	var channel model.NotificationChannel
+	if err := m.db.WithContext(ctx).First(&channel, channelID).Error; err != nil {
+		m.failDigestJobs(ctx, channelID, nil, fmt.Sprintf("通知渠道不存在: %v", err), 0)
+		return
+	}
+
+	policy := parseDigestPolicy(channel.Config)
+	if !policy.Enabled {
+		// 摘要已关闭：积压任务回到队列逐条发送
+		m.db.WithContext(ctx).Model(&model.NotificationJob{}).
+			Where("channel_id = ? AND status = ?", channelID, model.NotificationJobStatusDigesting).
+			Updates(map[string]interface{}{
+				"status":      model.NotificationJobStatusQueued,
+				"next_run_at": time.Now(),
+			})
+		return
+	}
+	if time.Since(oldest) < policy.Interval {
+		return
+	}
+
+	var jobs []model.NotificationJob
+	if err := m.db.WithContext(ctx).
+		Where("channel_id = ? AND status = ? AND next_run_at <= ?", channelID, model.NotificationJobStatusDigesting, time.Now()).
+		Order("id asc").
+		Limit(digestBatchLimit).
+		Find(&jobs).Error; err != nil {
+		m.logger.Errorf("加载摘要任务失败: channelID=%d err=%v", channelID, err)
+		return
+	}
+	if len(jobs) == 0 {
+		return
+	}
+
+	// Claim: digesting -> processing，只发送本次实际领取到的任务
+	now := time.Now()
+	jobs, err := m.claimDigestJobs(ctx, jobs, now)
+	if err != nil {
+		m.logger.Errorf("领取摘要任务失败: channelID=%d err=%v", channelID, err)
+		return
+	}
+	if len(jobs) == 0 {
+		return
+	}
+	jobIDs := digestJobIDs(jobs)
+
+	provider, ok := m.providers[channel.Type]
+	if !ok {
+		m.retryDigest(ctx, jobs, &channel, fmt.Sprintf("通知提供者不存在: %s", channel.Type))
+		return
+	}
+
+	event := buildDigestEvent(jobs, policy, now)
+	if m.templateMgr != nil {
+		_ = m.templateMgr.LoadTemplates()
+		if content, err := m.templateMgr.Render(policy.Template, event); err == nil {
+			event.Data["rendered_content"] = content
+		} else {
+			m.logger.Errorf("渲染摘要模板失败: name=%s err=%v", policy.Template, err)
+		}
+	}
+
+	if err := provider.Send(ctx, map[string]interface{}(channel.Config), event); err != nil {
+		m.retryDigest(ctx, jobs, &channel, err.Error())
+		return
+	}
+
+	sentAt := time.Now()
+	m.db.WithContext(ctx).Model(&model.NotificationLog{}).
+		Where("id IN ?", digestLogIDs(jobs)).
+		Updates(map[string]interface{}{
+			"status":        model.NotificationStatusSuccess,
+			"error_message": "",
+			"sent_at":       &sentAt,
+		})
+	m.db.WithContext(ctx).Model(&model.NotificationJob{}).
+		Where("id IN ?", jobIDs).
+		Updates(map[string]interface{}{
+			"status": model.NotificationJobStatusSucceeded,
+		})
+}
+
+// claimDigestJobs 将 digesting 任务改为 processing，按 RETURNING 的 id 过滤出本次领取成功的任务；
+// 其他 worker 已领取的任务不会在这里重复发送
+func (m *Manager) claimDigestJobs(ctx context.Context, jobs []model.NotificationJob, now time.Time) ([]model.NotificationJob, error) {
+	var rows []model.NotificationJob
+	if err := m.db.WithContext(ctx).Model(&rows).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}}}).
+		Where("id IN ? AND status = ?", digestJobIDs(jobs), model.NotificationJobStatusDigesting).
+		Updates(map[string]interface{}{
+			"status":          model.NotificationJobStatusProcessing,
+			"last_attempt_at": now,
+		}).Error; err != nil {
+		return nil, err
+	}
+	claimedIDs := make(map[uint]struct{}, len(rows))
+	for _, row := range rows {
+		claimedIDs[row.ID] = struct{}{}
+	}
+	claimed := make([]model.NotificationJob, 0, len(rows))
+	for _, job := range jobs {
+		if _, ok := claimedIDs[job.ID]; ok {
+			claimed = append(claimed, job)
+		}
+	}
+	return claimed, nil
+}
+
+// retryDigest 摘要发送失败：整批任务回到 digesting，按渠道重试策略退避后由扫描器重试；超出重试次数后整批失败
+func (m *Manager) retryDigest(ctx context.Context, jobs []model.NotificationJob, channel *model.NotificationChannel, errMsg string) {
+	attempt := 0
+	for _, job := range jobs {
+		if job.RetryCount > attempt {
+			attempt = job.RetryCount
+		}
+	}
+	attempt++
+
+	policy := parseRetryPolicy(channel.Config)
+	if attempt >= policy.MaxAttempts {
+		m.failDigestJobs(ctx, channel.ID, jobs, errMsg, attempt)
+		return
+	}
+
+	m.db.WithContext(ctx).Model(&model.NotificationLog{}).
+		Where("id IN ?", digestLogIDs(jobs)).
+		Updates(map[string]interface{}{
+			"error_message": errMsg,
+		})
+	m.db.WithContext(ctx).Model(&model.NotificationJob{}).
+		Where("id IN ?", digestJobIDs(jobs)).
+		Updates(map[string]interface{}{
+			"status":      model.NotificationJobStatusDigesting,
+			"retry_count": attempt,
+			"next_run_at": time.Now().Add(policy.nextDelay(attempt)),
+			"last_error":  errMsg,
+		})
+}
+
+// failDigestJobs 将摘要任务及其日志标记为失败；jobs 为空时作用于该渠道全部积压任务
+func (m *Manager) failDigestJobs(ctx context.Context, channelID uint, jobs []model.NotificationJob, errMsg string, attempt int) {
+	jobQuery := m.db.WithContext(ctx).Model(&model.NotificationJob{})
+	logQuery := m.db.WithContext(ctx).Model(&model.NotificationLog{})
+	if len(jobs) > 0 {
+		jobQuery = jobQuery.Where("id IN ?", digestJobIDs(jobs))
+		logQuery = logQuery.Where("id IN ?", digestLogIDs(jobs))
+	} else {
+		jobQuery = jobQuery.Where("channel_id = ? AND status = ?", channelID, model.NotificationJobStatusDigesting)
+		logQuery = logQuery.Where("id IN (?)", m.db.Model(&model.NotificationJob{}).
+			Select("log_id").
+			Where("channel_id = ? AND status = ?", channelID, model.NotificationJobStatusDigesting))
+	}
+
+	logQuery.Updates(map[string]interface{}{
+		"status":        model.NotificationStatusFailed,
+		"error_message": errMsg,
+	})
+
+	jobUpdates := map[string]interface{}{
+		"status":     model.NotificationJobStatusFailed,
+		"last_error": errMsg,
+	}
+	if attempt > 0 {
+		jobUpdates["retry_count"] = attempt
+	}
+	jobQuery.Updates(jobUpdates)
+}
+
+// buildDigestEvent 汇总一批任务：按事件类型/级别计数，并按严重程度挑选前 N 条明细
+func buildDigestEvent(jobs []model.NotificationJob, policy digestPolicy, now time.Time) *Event {
+	typeCounts := make(map[string]int)
+	levelCounts := make(map[string]int)
+	level := LevelInfo
+	windowStart := now
+	for _, job := range jobs {
+		typeCounts[job.EventType]++
+		levelCounts[job.EventLevel]++
+		if levelRank(job.EventLevel) > levelRank(level) {
+			level = job.EventLevel
+		}
+		if job.CreatedAt.Before(windowStart) {
+			windowStart = job.CreatedAt
+		}
+	}
+
+	types := make([]string, 0, len(typeCounts))
+	for t := range typeCounts {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if typeCounts[types[i]] != typeCounts[types[j]] {
+			return typeCounts[types[i]] > typeCounts[types[j]]
+		}
+		return types[i] < types[j]
+	})
+
+	byType := make([]map[string]interface{}, 0, len(types))
+	lines := make([]string, 0, len(types))
+	for _, t := range types {
+		byType = append(byType, map[string]interface{}{"type": t, "count": typeCounts[t]})
+		lines = append(lines, fmt.Sprintf("%s: %d", t, typeCounts[t]))
+	}
+
+	ordered := make([]model.NotificationJob, len(jobs))
+	copy(ordered, jobs)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if levelRank(ordered[i].EventLevel) != levelRank(ordered[j].EventLevel) {
+			return levelRank(ordered[i].EventLevel) > levelRank(ordered[j].EventLevel)
+		}
+		return ordered[i].CreatedAt.After(ordered[j].CreatedAt)
+	})
+
+	limit := policy.MaxItems
+	if limit > len(ordered) {
+		limit = len(ordered)
+	}
+	items := make([]map[string]interface{}, 0, limit)
+	for _, job := range ordered[:limit] {
+		item := map[string]interface{}{
+			"type":    job.EventType,
+			"level":   job.EventLevel,
+			"title":   job.EventTitle,
+			"message": job.EventMessage,
+			"time":    job.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
+		if link := digestItemLink(job.EventData); link != "" {
+			item["link"] = link
+		}
+		items = append(items, item)
+	}
+
+	return &Event{
+		Type:    EventNotificationDigest,
+		Level:   level,
+		Title:   fmt.Sprintf("通知摘要：%d 条事件", len(jobs)),
+		Message: strings.Join(lines, "\n"),
+		Data: map[string]interface{}{
+			"total":        len(jobs),
+			"by_type":      byType,
+			"by_level":     levelCounts,
+			"items":        items,
+			"omitted":      len(jobs) - limit,
+			"window_start": windowStart.Format("2006-01-02 15:04:05"),
+			"window_end":   now.Format("2006-01-02 15:04:05"),
+		},
+		Timestamp: now,
+	}
+}
+
+func digestItemLink(data model.JSONMap) string {
+	for _, key := range []string{"link", "url"} {
+		if v, ok := data[key].(string); ok && strings.TrimSpace(v) != "" {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
+func levelRank(level string) int {
+	switch level {
+	case LevelCritical:
+		return 3
+	case LevelError:
+		return 2
+	case LevelWarning:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func digestJobIDs(jobs []model.NotificationJob) []uint {
+	ids := make([]uint, 0, len(jobs))
+	for _, job := range jobs {
+		ids = append(ids, job.ID)
+	}
+	return ids
+}
+
+func digestLogIDs(jobs []model.NotificationJob) []uint {
+	ids := make([]uint, 0, len(jobs))
+	for _, job := range jobs {
+		ids = append(ids, job.LogID)
+	}
+	return ids
+}
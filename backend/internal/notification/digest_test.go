@@ -0,0 +1,185 @@
+package notification
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"logflux/internal/notification/template"
+	"logflux/model"
+)
+
+func TestParseDigestPolicy(t *testing.T) {
+	policy := parseDigestPolicy(model.JSONMap{})
+	if policy.Enabled {
+		t.Fatalf("expected digest disabled by default")
+	}
+
+	policy = parseDigestPolicy(model.JSONMap{
+		"digest": map[string]interface{}{
+			"enabled":        true,
+			"interval":       "15m",
+			"bypassCritical": false,
+			"maxItems":       float64(3),
+		},
+	})
+	if !policy.Enabled || policy.Interval != 15*time.Minute || policy.BypassCritical || policy.MaxItems != 3 {
+		t.Fatalf("unexpected policy: %+v", policy)
+	}
+	if policy.Template != defaultDigestTemplate {
+		t.Fatalf("expected default template, got %q", policy.Template)
+	}
+}
+
+func TestDigestPolicy_Collects(t *testing.T) {
+	escalationID := uint(1)
+	policy := digestPolicy{Enabled: true, BypassCritical: true}
+
+	cases := []struct {
+		name string
+		job  model.NotificationJob
+		want bool
+	}{
+		{name: "warning collected", job: model.NotificationJob{EventLevel: LevelWarning}, want: true},
+		{name: "critical bypasses", job: model.NotificationJob{EventLevel: LevelCritical}, want: false},
+		{name: "escalation bypasses", job: model.NotificationJob{EventLevel: LevelInfo, EscalationID: &escalationID}, want: false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := policy.collects(&tc.job); got != tc.want {
+				t.Fatalf("collects() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	if (digestPolicy{}).collects(&model.NotificationJob{EventLevel: LevelInfo}) {
+		t.Fatalf("disabled policy should not collect")
+	}
+}
+
+func TestBuildDigestEvent(t *testing.T) {
+	base := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	jobs := []model.NotificationJob{
+		{ID: 1, CreatedAt: base, EventType: "log.suspicious_ip", EventLevel: LevelWarning, EventTitle: "IP A"},
+		{ID: 2, CreatedAt: base.Add(time.Minute), EventType: "log.suspicious_ip", EventLevel: LevelWarning, EventTitle: "IP B"},
+		{ID: 3, CreatedAt: base.Add(2 * time.Minute), EventType: "system.error", EventLevel: LevelError, EventTitle: "Boom",
+			EventData: model.JSONMap{"link": "https://logflux.local/logs/3"}},
+	}
+
+	event := buildDigestEvent(jobs, digestPolicy{MaxItems: 2}, base.Add(5*time.Minute))
+
+	if event.Type != EventNotificationDigest || event.Level != LevelError {
+		t.Fatalf("unexpected type/level: %s/%s", event.Type, event.Level)
+	}
+	if event.Data["total"] != 3 || event.Data["omitted"] != 1 {
+		t.Fatalf("unexpected totals: %v omitted=%v", event.Data["total"], event.Data["omitted"])
+	}
+
+	byType := event.Data["by_type"].([]map[string]interface{})
+	if byType[0]["type"] != "log.suspicious_ip" || byType[0]["count"] != 2 {
+		t.Fatalf("expected most frequent type first, got %v", byType)
+	}
+
+	items := event.Data["items"].([]map[string]interface{})
+	if len(items) != 2 || items[0]["title"] != "Boom" || items[0]["link"] != "https://logflux.local/logs/3" {
+		t.Fatalf("expected most severe item first with link, got %v", items)
+	}
+	if items[1]["title"] != "IP B" {
+		t.Fatalf("expected newest warning second, got %v", items[1])
+	}
+
+	var def template.DefaultTemplateDef
+	for _, d := range template.GetDefaultTemplates() {
+		if d.Name == defaultDigestTemplate {
+			def = d
+		}
+	}
+	rendered, err := template.RenderContent(def.Format, def.Content, event)
+	if err != nil {
+		t.Fatalf("render digest template: %v", err)
+	}
+	for _, want := range []string{"log.suspicious_ip: 2", "Boom", "https://logflux.local/logs/3", "and 1 more"} {
+		if !strings.Contains(rendered, want) {
+			t.Fatalf("rendered digest missing %q:\n%s", want, rendered)
+		}
+	}
+}
+
+func TestManager_retryDigest_BacksOff(t *testing.T) {
+	sqldb, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer sqldb.Close()
+
+	gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: sqldb}), &gorm.Config{SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("failed to open gorm: %v", err)
+	}
+
+	channel := &model.NotificationChannel{
+		ID:     3,
+		Config: model.JSONMap{"retry": map[string]interface{}{"maxAttempts": float64(5), "baseDelay": "1m", "jitter": false}},
+	}
+	jobs := []model.NotificationJob{{ID: 10, LogID: 1, RetryCount: 1}, {ID: 11, LogID: 2}}
+
+	mock.ExpectExec("UPDATE \\\"notification_logs\\\"").WillReturnResult(sqlmock.NewResult(0, 2))
+	// 整批回到 digesting，next_run_at 推迟到退避之后，扫描器在此之前不会再次领取
+	mock.ExpectExec("UPDATE \\\"notification_jobs\\\" SET \\\"last_error\\\"=\\$1,\\\"next_run_at\\\"=\\$2,\\\"retry_count\\\"=\\$3,\\\"status\\\"=\\$4").
+		WithArgs("boom", futureTime{after: time.Now().Add(time.Minute)}, 2, model.NotificationJobStatusDigesting, sqlmock.AnyArg(), 10, 11).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	m := &Manager{db: gdb, logger: logx.WithContext(context.Background())}
+	m.retryDigest(context.Background(), jobs, channel, "boom")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("sql expectations not met: %v", err)
+	}
+}
+
+// futureTime 匹配不早于 after 的时间参数
+type futureTime struct {
+	after time.Time
+}
+
+func (f futureTime) Match(v driver.Value) bool {
+	t, ok := v.(time.Time)
+	return ok && !t.Before(f.after)
+}
+
+func TestManager_claimDigestJobs_OnlyReturnsClaimedRows(t *testing.T) {
+	sqldb, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer sqldb.Close()
+
+	gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: sqldb}), &gorm.Config{SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("failed to open gorm: %v", err)
+	}
+
+	// 任务 11 已被其他 worker 领取，UPDATE 只返回任务 10
+	mock.ExpectQuery("UPDATE \\\"notification_jobs\\\" SET \\\"last_attempt_at\\\"=\\$1,\\\"status\\\"=\\$2,.* RETURNING \\\"id\\\"").
+		WithArgs(sqlmock.AnyArg(), model.NotificationJobStatusProcessing, sqlmock.AnyArg(), 10, 11, model.NotificationJobStatusDigesting).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
+
+	m := &Manager{db: gdb, logger: logx.WithContext(context.Background())}
+	claimed, err := m.claimDigestJobs(context.Background(), []model.NotificationJob{{ID: 10, LogID: 1}, {ID: 11, LogID: 2}}, time.Now())
+	if err != nil {
+		t.Fatalf("claimDigestJobs() error = %v", err)
+	}
+	if len(claimed) != 1 || claimed[0].ID != 10 || claimed[0].LogID != 1 {
+		t.Fatalf("expected only job 10 to be claimed, got %+v", claimed)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("sql expectations not met: %v", err)
+	}
+}
@@ -0,0 +1,251 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"logflux/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// OnCallUserID 计算指定时间点的值班用户
+// 按 RotationStart 起每 RotationDays 天轮换一次，早于起点时视为首位值班人。
+func OnCallUserID(schedule *model.NotificationOnCallSchedule, at time.Time) (uint, bool) {
+	if schedule == nil || len(schedule.Participants) == 0 {
+		return 0, false
+	}
+
+	days := schedule.RotationDays
+	if days <= 0 {
+		days = 7
+	}
+	period := time.Duration(days) * 24 * time.Hour
+
+	index := 0
+	if elapsed := at.Sub(schedule.RotationStart); elapsed > 0 {
+		index = int(int64(elapsed/period) % int64(len(schedule.Participants)))
+	}
+
+	return uint(schedule.Participants[index]), true
+}
+
+// startEscalation 为触发的规则创建升级实例并立即执行第一步
+// 升级策略不存在或已禁用时回退为直接通知规则渠道。
+func (m *Manager) startEscalation(ctx context.Context, rule *model.NotificationRule, event *Event) {
+	var policy model.NotificationEscalationPolicy
+	if err := m.db.WithContext(ctx).First(&policy, *rule.EscalationPolicyID).Error; err != nil || !policy.Enabled || len(policy.Steps) == 0 {
+		if err != nil {
+			m.logger.Errorf("加载升级策略失败，回退为规则渠道: rule=%s policyID=%d err=%v", rule.Name, *rule.EscalationPolicyID, err)
+		}
+		m.mu.RLock()
+		channels := make([]*model.NotificationChannel, 0, len(rule.ChannelIDs))
+		for _, channelID := range rule.ChannelIDs {
+			if channel, exists := m.channels[uint(channelID)]; exists {
+				channels = append(channels, channel)
+			}
+		}
+		m.mu.RUnlock()
+		for _, channel := range channels {
+			m.dispatch(m.enqueueJob(ctx, channel, event, rule))
+		}
+		return
+	}
+
+	eventData := map[string]interface{}{}
+	for k, v := range event.Data {
+		eventData[k] = v
+	}
+
+	escalation := &model.NotificationEscalation{
+		PolicyID:     policy.ID,
+		RuleID:       &rule.ID,
+		EventType:    event.Type,
+		EventLevel:   event.Level,
+		EventTitle:   event.Title,
+		EventMessage: event.Message,
+		EventData:    model.JSONMap(eventData),
+		TemplateName: rule.Template,
+		Status:       model.EscalationStatusActive,
+		CurrentStep:  0,
+	}
+	if err := m.db.WithContext(ctx).Create(escalation).Error; err != nil {
+		m.logger.Errorf("创建升级实例失败: rule=%s err=%v", rule.Name, err)
+		return
+	}
+
+	if !m.claimEscalationStep(ctx, escalation.ID, 0, 0, &policy) {
+		return
+	}
+	m.runEscalationStep(ctx, escalation, &policy, rule, event)
+}
+
+// claimEscalationStep 以 status 与 current_step 做条件更新领取步骤，同时写入下一步的触发时间。
+// 先落库再发送：已确认的实例不会再推进，发送途中进程退出也不会遗留无 next_step_at 的实例
+func (m *Manager) claimEscalationStep(ctx context.Context, escalationID uint, fromStep, step int, policy *model.NotificationEscalationPolicy) bool {
+	updates := map[string]interface{}{
+		"current_step": step,
+	}
+	nextIndex := step + 1
+	if nextIndex < len(policy.Steps) {
+		nextStepAt := time.Now().Add(time.Duration(policy.Steps[nextIndex].DelayMinutes) * time.Minute)
+		updates["next_step_at"] = &nextStepAt
+	} else {
+		updates["next_step_at"] = nil
+		updates["status"] = model.EscalationStatusExhausted
+	}
+
+	res := m.db.WithContext(ctx).Model(&model.NotificationEscalation{}).
+		Where("id = ? AND status = ? AND current_step = ?", escalationID, model.EscalationStatusActive, fromStep).
+		Updates(updates)
+	if res.Error != nil {
+		m.logger.Errorf("领取升级步骤失败: escalationID=%d step=%d err=%v", escalationID, step, res.Error)
+		return false
+	}
+	return res.RowsAffected > 0
+}
+
+// runEscalationStep 将已领取步骤的通知入队
+func (m *Manager) runEscalationStep(ctx context.Context, escalation *model.NotificationEscalation, policy *model.NotificationEscalationPolicy, rule *model.NotificationRule, event *Event) {
+	step := policy.Steps[escalation.CurrentStep]
+	channels := m.resolveEscalationChannels(ctx, step)
+	if len(channels) == 0 {
+		m.logger.Infof("升级步骤没有可用的通知渠道: escalationID=%d step=%d", escalation.ID, escalation.CurrentStep)
+	}
+
+	for _, channel := range channels {
+		m.dispatch(m.enqueueEscalationJob(ctx, channel, event, rule, escalation.ID, escalation.CurrentStep))
+	}
+}
+
+// resolveEscalationChannels 解析步骤渠道：直接渠道 + 值班人的联系渠道
+func (m *Manager) resolveEscalationChannels(ctx context.Context, step model.EscalationStep) []*model.NotificationChannel {
+	channelIDs := make([]int64, 0, len(step.ChannelIDs))
+	channelIDs = append(channelIDs, step.ChannelIDs...)
+
+	if len(step.ScheduleIDs) > 0 {
+		var schedules []model.NotificationOnCallSchedule
+		if err := m.db.WithContext(ctx).Where("id IN ? AND enabled = ?", []int64(step.ScheduleIDs), true).Find(&schedules).Error; err != nil {
+			m.logger.Errorf("加载值班表失败: %v", err)
+		}
+
+		now := time.Now()
+		userIDs := make([]uint, 0, len(schedules))
+		for i := range schedules {
+			if userID, ok := OnCallUserID(&schedules[i], now); ok {
+				userIDs = append(userIDs, userID)
+			}
+		}
+
+		if len(userIDs) > 0 {
+			var contacts []model.NotificationUserContact
+			if err := m.db.WithContext(ctx).Where("user_id IN ?", userIDs).Find(&contacts).Error; err != nil {
+				m.logger.Errorf("加载值班人联系渠道失败: %v", err)
+			}
+			for _, contact := range contacts {
+				channelIDs = append(channelIDs, contact.ChannelIDs...)
+			}
+		}
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	seen := make(map[uint]bool)
+	channels := make([]*model.NotificationChannel, 0, len(channelIDs))
+	for _, id := range channelIDs {
+		channelID := uint(id)
+		if seen[channelID] {
+			continue
+		}
+		seen[channelID] = true
+		if channel, exists := m.channels[channelID]; exists {
+			channels = append(channels, channel)
+		}
+	}
+	return channels
+}
+
+// dispatchDueEscalations 推进到期且仍未确认的升级实例
+func (m *Manager) dispatchDueEscalations(ctx context.Context) {
+	var escalations []model.NotificationEscalation
+	err := m.db.WithContext(ctx).
+		Session(&gorm.Session{Logger: logger.Default.LogMode(logger.Silent)}).
+		Where("status = ? AND next_step_at <= ?", model.EscalationStatusActive, time.Now()).
+		Order("id asc").
+		Limit(100).
+		Find(&escalations).Error
+	if err != nil {
+		m.logger.Errorf("扫描升级实例失败: %v", err)
+		return
+	}
+
+	for i := range escalations {
+		m.advanceEscalation(ctx, &escalations[i])
+	}
+}
+
+// advanceEscalation 领取并执行升级实例的下一步
+func (m *Manager) advanceEscalation(ctx context.Context, escalation *model.NotificationEscalation) {
+	nextStep := escalation.CurrentStep + 1
+	var policy model.NotificationEscalationPolicy
+	if err := m.db.WithContext(ctx).First(&policy, escalation.PolicyID).Error; err != nil {
+		m.closeEscalation(ctx, escalation.ID, fmt.Sprintf("升级策略不存在: %v", err))
+		return
+	}
+	if !policy.Enabled || nextStep >= len(policy.Steps) {
+		m.closeEscalation(ctx, escalation.ID, "升级策略已禁用或没有更多步骤")
+		return
+	}
+	if !m.claimEscalationStep(ctx, escalation.ID, escalation.CurrentStep, nextStep, &policy) {
+		return
+	}
+	escalation.CurrentStep = nextStep
+
+	var rule *model.NotificationRule
+	if escalation.RuleID != nil {
+		var loaded model.NotificationRule
+		if err := m.db.WithContext(ctx).First(&loaded, *escalation.RuleID).Error; err == nil {
+			rule = &loaded
+		}
+	}
+
+	eventData := map[string]interface{}{}
+	for k, v := range escalation.EventData {
+		eventData[k] = v
+	}
+	event := &Event{
+		Type:      escalation.EventType,
+		Level:     escalation.EventLevel,
+		Title:     escalation.EventTitle,
+		Message:   escalation.EventMessage,
+		Data:      eventData,
+		Timestamp: time.Now(),
+	}
+
+	m.runEscalationStep(ctx, escalation, &policy, rule, event)
+}
+
+// closeEscalation 将无法继续推进的升级实例标记为结束
+func (m *Manager) closeEscalation(ctx context.Context, escalationID uint, reason string) {
+	m.logger.Infof("升级实例结束: escalationID=%d reason=%s", escalationID, reason)
+	m.db.WithContext(ctx).Model(&model.NotificationEscalation{}).
+		Where("id = ? AND status = ?", escalationID, model.EscalationStatusActive).
+		Updates(map[string]interface{}{
+			"status":       model.EscalationStatusExhausted,
+			"next_step_at": nil,
+		})
+}
+
+// dispatch 尝试低延迟派发；队列满时依赖扫描器补投递
+func (m *Manager) dispatch(jobID uint) {
+	if jobID == 0 {
+		return
+	}
+	select {
+	case m.workCh <- jobID:
+	default:
+	}
+}
@@ -0,0 +1,96 @@
+package notification
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"logflux/model"
+)
+
+func TestOnCallUserID_WeeklyRotation(t *testing.T) {
+	start := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC) // Monday 09:00
+	schedule := &model.NotificationOnCallSchedule{
+		RotationStart: start,
+		RotationDays:  7,
+		Participants:  model.Int64Array{11, 22, 33},
+	}
+
+	cases := []struct {
+		name string
+		at   time.Time
+		want uint
+	}{
+		{name: "before start uses first participant", at: start.Add(-time.Hour), want: 11},
+		{name: "first week", at: start.Add(3 * 24 * time.Hour), want: 11},
+		{name: "handoff boundary", at: start.Add(7 * 24 * time.Hour), want: 22},
+		{name: "third week", at: start.Add(15 * 24 * time.Hour), want: 33},
+		{name: "wraps around", at: start.Add(21 * 24 * time.Hour), want: 11},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := OnCallUserID(schedule, tc.at)
+			if !ok {
+				t.Fatalf("expected on-call user")
+			}
+			if got != tc.want {
+				t.Fatalf("OnCallUserID() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestOnCallUserID_EmptyParticipants(t *testing.T) {
+	if _, ok := OnCallUserID(&model.NotificationOnCallSchedule{RotationDays: 7}, time.Now()); ok {
+		t.Fatalf("expected no on-call user for empty schedule")
+	}
+	if _, ok := OnCallUserID(nil, time.Now()); ok {
+		t.Fatalf("expected no on-call user for nil schedule")
+	}
+}
+
+func TestManager_advanceEscalation_SkipsWhenAlreadyClaimed(t *testing.T) {
+	sqldb, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer sqldb.Close()
+
+	gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: sqldb}), &gorm.Config{SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("failed to open gorm: %v", err)
+	}
+
+	// 被其他扫描或确认操作抢先：按 status 与 current_step 条件领取影响 0 行，不应入队
+	mock.ExpectQuery("SELECT \\* FROM \\\"notification_escalation_policies\\\"").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "enabled", "steps"}).
+			AddRow(1, "oncall", true, `[{"delay_minutes":0,"channel_ids":[1]},{"delay_minutes":10,"channel_ids":[2]}]`))
+	mock.ExpectExec("UPDATE \\\"notification_escalations\\\" SET .* WHERE id = \\$\\d+ AND status = \\$\\d+ AND current_step = \\$\\d+").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	m := &Manager{
+		db:     gdb,
+		logger: logx.WithContext(context.Background()),
+		workCh: make(chan uint, 1),
+	}
+
+	m.advanceEscalation(context.Background(), &model.NotificationEscalation{
+		ID:          7,
+		PolicyID:    1,
+		Status:      model.EscalationStatusActive,
+		CurrentStep: 0,
+	})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("sql expectations not met: %v", err)
+	}
+	if len(m.workCh) != 0 {
+		t.Fatalf("expected no jobs dispatched")
+	}
+}
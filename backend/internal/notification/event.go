@@ -91,6 +91,23 @@ const (
 	EventCaddyConfigUpdateFailed  = "caddy.config_update_failed"
 	EventCaddyConfigUpdateSuccess = "caddy.config_update_success"
 	EventCaddyLogSourceDiscovered = "caddy.log_source_discovered"
+	EventCaddyConfigDriftDetected = "caddy.config_drift_detected"
+
+	// Caddy 变更申请事件
+	EventCaddyChangeRequestSubmitted = "caddy.change_request_submitted"
+	EventCaddyChangeRequestApproved  = "caddy.change_request_approved"
+	EventCaddyChangeRequestRejected  = "caddy.change_request_rejected"
+	EventCaddyChangeRequestCancelled = "caddy.change_request_cancelled"
+	EventCaddyChangeRequestApplied   = "caddy.change_request_applied"
+	EventCaddyChangeRequestFailed    = "caddy.change_request_failed"
+
+	// Caddy 证书事件
+	EventCaddyCertificateExpiring      = "caddy.certificate_expiring"
+	EventCaddyCertificateExpired       = "caddy.certificate_expired"
+	EventCaddyCertificateRenewalFailed = "caddy.certificate_renewal_failed"
+
+	// 报表事件
+	EventReportScheduled = "report.scheduled"
 
 	// 安全事件
 	EventSecurityLoginFailed               = "security.login_failed"
@@ -110,4 +127,6 @@ const (
 	EventSecurityWafReleaseActivateFailed  = "security.waf_release_activate_failed"
 	EventSecurityWafReleaseRollbackSuccess = "security.waf_release_rollback_success"
 	EventSecurityWafReleaseRollbackFailed  = "security.waf_release_rollback_failed"
+	EventSecurityWafDistributeSuccess      = "security.waf_distribute_success"
+	EventSecurityWafDistributeFailed       = "security.waf_distribute_failed"
 )
@@ -0,0 +1,377 @@
+package notification
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 入站告警格式
+const (
+	InboundFormatAlertmanager = "alertmanager"
+	InboundFormatGrafana      = "grafana"
+	InboundFormatGeneric      = "generic"
+)
+
+// InboundMapping 通用 JSON 的字段映射（点号分隔路径，如 "alert.severity"）
+type InboundMapping struct {
+	Type     string            `json:"type,omitempty"`
+	Level    string            `json:"level,omitempty"`
+	Title    string            `json:"title,omitempty"`
+	Message  string            `json:"message,omitempty"`
+	Link     string            `json:"link,omitempty"`
+	LevelMap map[string]string `json:"levelMap,omitempty"` // 源级别 -> info/warning/error/critical
+}
+
+// ParseInboundEvents 将外部告警负载转换为通知事件
+// eventType 为空时按格式使用默认事件类型；通用格式的 type 映射优先。
+func ParseInboundEvents(format string, body []byte, eventType string, mapping InboundMapping) ([]*Event, error) {
+	var payload interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("解析告警负载失败: %w", err)
+	}
+
+	switch format {
+	case InboundFormatAlertmanager:
+		obj, ok := payload.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("Alertmanager 负载必须为 JSON 对象")
+		}
+		return parseAlertmanagerPayload(obj, defaultString(eventType, "inbound.alertmanager"), InboundFormatAlertmanager)
+	case InboundFormatGrafana:
+		obj, ok := payload.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("Grafana 负载必须为 JSON 对象")
+		}
+		if _, unified := obj["alerts"]; unified {
+			return parseAlertmanagerPayload(obj, defaultString(eventType, "inbound.grafana"), InboundFormatGrafana)
+		}
+		return []*Event{parseLegacyGrafanaPayload(obj, defaultString(eventType, "inbound.grafana"))}, nil
+	case InboundFormatGeneric:
+		items, ok := payload.([]interface{})
+		if !ok {
+			items = []interface{}{payload}
+		}
+		events := make([]*Event, 0, len(items))
+		for _, item := range items {
+			obj, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("通用告警负载必须为 JSON 对象或对象数组")
+			}
+			events = append(events, parseGenericPayload(obj, defaultString(eventType, "inbound.generic"), mapping))
+		}
+		return events, nil
+	default:
+		return nil, fmt.Errorf("不支持的入站告警格式: %s", format)
+	}
+}
+
+// parseAlertmanagerPayload 解析 Alertmanager / Grafana 统一告警负载，每条 alert 生成一个事件
+func parseAlertmanagerPayload(obj map[string]interface{}, eventType, source string) ([]*Event, error) {
+	alerts, ok := obj["alerts"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("告警负载缺少 alerts 数组")
+	}
+
+	commonLabels, _ := obj["commonLabels"].(map[string]interface{})
+	commonAnnotations, _ := obj["commonAnnotations"].(map[string]interface{})
+
+	events := make([]*Event, 0, len(alerts))
+	for _, raw := range alerts {
+		alert, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		labels := mergeStringMaps(commonLabels, asMap(alert["labels"]))
+		annotations := mergeStringMaps(commonAnnotations, asMap(alert["annotations"]))
+		status := strings.ToLower(stringValue(alert["status"]))
+		if status == "" {
+			status = strings.ToLower(stringValue(obj["status"]))
+		}
+		resolved := status == "resolved"
+
+		name := stringValue(labels["alertname"])
+		if name == "" {
+			name = defaultString(stringValue(obj["title"]), "alert")
+		}
+
+		level := normalizeInboundLevel(stringValue(labels["severity"]), nil)
+		if resolved {
+			level = LevelInfo
+		}
+
+		message := stringValue(annotations["summary"])
+		if message == "" {
+			message = stringValue(annotations["description"])
+		}
+		if message == "" {
+			message = stringValue(obj["message"])
+		}
+
+		link := stringValue(alert["generatorURL"])
+		if dashboard := stringValue(alert["dashboardURL"]); dashboard != "" {
+			link = dashboard
+		}
+
+		event := NewEvent(eventType, level, fmt.Sprintf("[%s] %s", strings.ToUpper(defaultString(status, "firing")), name), message)
+		event.WithDataMap(map[string]interface{}{
+			"source":      source,
+			"status":      status,
+			"resolved":    resolved,
+			"labels":      labels,
+			"annotations": annotations,
+			"starts_at":   stringValue(alert["startsAt"]),
+			"ends_at":     stringValue(alert["endsAt"]),
+			"dedup_key":   stringValue(alert["fingerprint"]),
+			"link":        link,
+		})
+		if values, ok := alert["values"]; ok {
+			event.WithData("values", values)
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// parseLegacyGrafanaPayload 解析 Grafana 旧版告警负载（state/ruleName/evalMatches）
+func parseLegacyGrafanaPayload(obj map[string]interface{}, eventType string) *Event {
+	state := strings.ToLower(stringValue(obj["state"]))
+	resolved := state == "ok"
+
+	level := LevelWarning
+	switch state {
+	case "ok":
+		level = LevelInfo
+	case "no_data":
+		level = LevelWarning
+	case "alerting":
+		level = LevelError
+	}
+
+	title := stringValue(obj["title"])
+	if title == "" {
+		title = stringValue(obj["ruleName"])
+	}
+
+	event := NewEvent(eventType, level, title, stringValue(obj["message"]))
+	event.WithDataMap(map[string]interface{}{
+		"source":       InboundFormatGrafana,
+		"status":       state,
+		"resolved":     resolved,
+		"rule_name":    stringValue(obj["ruleName"]),
+		"tags":         obj["tags"],
+		"eval_matches": obj["evalMatches"],
+		"dedup_key":    fmt.Sprintf("grafana-%v", obj["ruleId"]),
+		"link":         stringValue(obj["ruleUrl"]),
+	})
+	return event
+}
+
+// parseGenericPayload 按映射解析任意 JSON 对象，原始负载放在 data.payload 中
+func parseGenericPayload(obj map[string]interface{}, eventType string, mapping InboundMapping) *Event {
+	if mapping.Type != "" {
+		if v := stringValue(lookupPath(obj, mapping.Type)); v != "" {
+			eventType = v
+		}
+	}
+
+	level := normalizeInboundLevel(stringValue(lookupPath(obj, defaultString(mapping.Level, "level"))), mapping.LevelMap)
+	title := stringValue(lookupPath(obj, defaultString(mapping.Title, "title")))
+	if title == "" {
+		title = eventType
+	}
+	message := stringValue(lookupPath(obj, defaultString(mapping.Message, "message")))
+
+	event := NewEvent(eventType, level, title, message)
+	event.WithDataMap(map[string]interface{}{
+		"source":  InboundFormatGeneric,
+		"payload": obj,
+	})
+	if link := stringValue(lookupPath(obj, defaultString(mapping.Link, "link"))); link != "" {
+		event.WithData("link", link)
+	}
+	return event
+}
+
+// normalizeInboundLevel 将外部级别映射为内部级别，无法识别时按 warning 处理
+func normalizeInboundLevel(level string, levelMap map[string]string) string {
+	level = strings.ToLower(strings.TrimSpace(level))
+	if mapped, ok := levelMap[level]; ok {
+		level = strings.ToLower(strings.TrimSpace(mapped))
+	}
+
+	switch level {
+	case LevelInfo, "information", "informational", "ok", "none", "low":
+		return LevelInfo
+	case LevelWarning, "warn", "medium", "minor":
+		return LevelWarning
+	case LevelError, "err", "high", "major":
+		return LevelError
+	case LevelCritical, "crit", "fatal", "emergency", "page", "disaster":
+		return LevelCritical
+	default:
+		return LevelWarning
+	}
+}
+
+func lookupPath(obj map[string]interface{}, path string) interface{} {
+	var current interface{} = obj
+	for _, key := range strings.Split(path, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = m[key]
+	}
+	return current
+}
+
+func stringValue(v interface{}) string {
+	switch s := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(s)
+	case float64, bool, json.Number:
+		return fmt.Sprintf("%v", s)
+	default:
+		return ""
+	}
+}
+
+func asMap(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
+
+func mergeStringMaps(base, override map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		merged[k] = v
+	}
+	return merged
+}
+
+func defaultString(value, fallback string) string {
+	if strings.TrimSpace(value) == "" {
+		return fallback
+	}
+	return value
+}
+
+// InboundRateLimiter 按令牌的固定窗口限流（单实例内存计数）
+type InboundRateLimiter struct {
+	mu        sync.Mutex
+	window    time.Duration
+	buckets   map[string]*inboundBucket
+	lastSweep time.Time
+}
+
+type inboundBucket struct {
+	start    time.Time
+	count    int
+	limit    int
+	lastSeen time.Time
+}
+
+func NewInboundRateLimiter(window time.Duration) *InboundRateLimiter {
+	return &InboundRateLimiter{
+		window:  window,
+		buckets: make(map[string]*inboundBucket),
+	}
+}
+
+// Allow 判断 key 在当前窗口内是否仍未超过 limit；limit <= 0 表示不限流
+func (l *InboundRateLimiter) Allow(key string, limit int, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.evictIdle(now)
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &inboundBucket{start: now}
+		l.buckets[key] = bucket
+	}
+	bucket.limit = limit
+	bucket.lastSeen = now
+	if limit <= 0 {
+		return true
+	}
+
+	if now.Sub(bucket.start) >= l.window {
+		bucket.start = now
+		bucket.count = 0
+	}
+	if bucket.count >= limit {
+		return false
+	}
+	bucket.count++
+	return true
+}
+
+// AllowKnown 仅对已有计数桶的 key 判断是否仍未超过记录的限额，不为未见过的 key 创建计数桶；
+// known 为 false 表示 key 尚无记录，调用方应在确认限额后再调用 Allow
+func (l *InboundRateLimiter) AllowKnown(key string, now time.Time) (allowed, known bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.evictIdle(now)
+	bucket, ok := l.buckets[key]
+	if !ok {
+		return true, false
+	}
+	bucket.lastSeen = now
+	if bucket.limit <= 0 {
+		return true, true
+	}
+	if now.Sub(bucket.start) >= l.window {
+		bucket.start = now
+		bucket.count = 0
+	}
+	if bucket.count >= bucket.limit {
+		return false, true
+	}
+	bucket.count++
+	return true, true
+}
+
+// Limit 返回 key 最近一次记录的限额；未见过的 key 返回 fallback
+func (l *InboundRateLimiter) Limit(key string, fallback int) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if bucket, ok := l.buckets[key]; ok {
+		return bucket.limit
+	}
+	return fallback
+}
+
+// SetLimit 更新 key 的限额，供查询到令牌配置后修正下一次请求使用的限额
+func (l *InboundRateLimiter) SetLimit(key string, limit int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if bucket, ok := l.buckets[key]; ok {
+		bucket.limit = limit
+	}
+}
+
+// evictIdle 每个窗口最多清理一次，移除超过一个窗口未再出现的 key，避免随机令牌撑大内存
+func (l *InboundRateLimiter) evictIdle(now time.Time) {
+	if now.Sub(l.lastSweep) < l.window {
+		return
+	}
+	l.lastSweep = now
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.lastSeen) >= l.window {
+			delete(l.buckets, key)
+		}
+	}
+}
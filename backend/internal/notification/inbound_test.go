@@ -0,0 +1,167 @@
+package notification
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseInboundAlertmanager(t *testing.T) {
+	body := []byte(`{
+		"status": "firing",
+		"commonLabels": {"job": "node"},
+		"alerts": [
+			{"status": "firing", "labels": {"alertname": "HighLoad", "severity": "critical"},
+			 "annotations": {"summary": "load > 10"}, "fingerprint": "abc", "generatorURL": "http://prom/graph"},
+			{"status": "resolved", "labels": {"alertname": "DiskFull", "severity": "warning"},
+			 "annotations": {"description": "disk ok"}}
+		]
+	}`)
+
+	events, err := ParseInboundEvents(InboundFormatAlertmanager, body, "", InboundMapping{})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+
+	first := events[0]
+	if first.Type != "inbound.alertmanager" || first.Level != LevelCritical || first.Title != "[FIRING] HighLoad" || first.Message != "load > 10" {
+		t.Fatalf("unexpected first event: %+v", first)
+	}
+	if first.Data["dedup_key"] != "abc" || first.Data["link"] != "http://prom/graph" {
+		t.Fatalf("unexpected first event data: %+v", first.Data)
+	}
+	if labels := first.Data["labels"].(map[string]interface{}); labels["job"] != "node" {
+		t.Fatalf("expected common labels merged, got %+v", labels)
+	}
+
+	second := events[1]
+	if second.Level != LevelInfo || second.Data["resolved"] != true || second.Message != "disk ok" {
+		t.Fatalf("unexpected resolved event: %+v", second)
+	}
+}
+
+func TestParseInboundLegacyGrafana(t *testing.T) {
+	body := []byte(`{"title": "[Alerting] CPU", "ruleId": 7, "ruleName": "CPU", "state": "alerting", "message": "cpu high", "ruleUrl": "http://grafana/d/1"}`)
+
+	events, err := ParseInboundEvents(InboundFormatGrafana, body, "ops.grafana", InboundMapping{})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+	event := events[0]
+	if event.Type != "ops.grafana" || event.Level != LevelError || event.Title != "[Alerting] CPU" {
+		t.Fatalf("unexpected event: %+v", event)
+	}
+	if event.Data["dedup_key"] != "grafana-7" || event.Data["link"] != "http://grafana/d/1" {
+		t.Fatalf("unexpected event data: %+v", event.Data)
+	}
+}
+
+func TestParseInboundGenericMapping(t *testing.T) {
+	body := []byte(`[{"alert": {"kind": "backup.failed", "sev": "P1", "name": "nightly backup", "detail": "exit 1"}}]`)
+	mapping := InboundMapping{
+		Type:     "alert.kind",
+		Level:    "alert.sev",
+		Title:    "alert.name",
+		Message:  "alert.detail",
+		LevelMap: map[string]string{"p1": "critical"},
+	}
+
+	events, err := ParseInboundEvents(InboundFormatGeneric, body, "", mapping)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+	event := events[0]
+	if event.Type != "backup.failed" || event.Level != LevelCritical || event.Title != "nightly backup" || event.Message != "exit 1" {
+		t.Fatalf("unexpected event: %+v", event)
+	}
+	if _, ok := event.Data["payload"].(map[string]interface{}); !ok {
+		t.Fatalf("expected original payload in data, got %+v", event.Data)
+	}
+
+	if _, err := ParseInboundEvents(InboundFormatGeneric, []byte(`"text"`), "", mapping); err == nil {
+		t.Fatalf("expected error for non-object payload")
+	}
+	if _, err := ParseInboundEvents("unknown", []byte(`{}`), "", mapping); err == nil {
+		t.Fatalf("expected error for unknown format")
+	}
+}
+
+func TestInboundRateLimiter(t *testing.T) {
+	limiter := NewInboundRateLimiter(time.Minute)
+	now := time.Date(2026, 3, 11, 8, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 2; i++ {
+		if !limiter.Allow("a", 2, now) {
+			t.Fatalf("request %d should be allowed", i+1)
+		}
+	}
+	if limiter.Allow("a", 2, now.Add(30*time.Second)) {
+		t.Fatalf("third request within window should be rejected")
+	}
+	if !limiter.Allow("b", 2, now) {
+		t.Fatalf("other key should have its own bucket")
+	}
+	if !limiter.Allow("a", 2, now.Add(time.Minute)) {
+		t.Fatalf("new window should reset the counter")
+	}
+	if !limiter.Allow("a", 0, now) {
+		t.Fatalf("zero limit should not throttle")
+	}
+}
+
+func TestInboundRateLimiterLimitAndEviction(t *testing.T) {
+	limiter := NewInboundRateLimiter(time.Minute)
+	now := time.Date(2026, 3, 11, 8, 0, 0, 0, time.UTC)
+
+	if got := limiter.Limit("unknown", 60); got != 60 {
+		t.Fatalf("unknown key should use fallback, got %d", got)
+	}
+	limiter.Allow("a", 60, now)
+	limiter.SetLimit("a", 1)
+	if got := limiter.Limit("a", 60); got != 1 {
+		t.Fatalf("expected recorded limit 1, got %d", got)
+	}
+	if limiter.Allow("a", limiter.Limit("a", 60), now.Add(time.Second)) {
+		t.Fatalf("recorded limit should throttle the next request")
+	}
+
+	limiter.Allow("b", 5, now.Add(30*time.Second))
+	limiter.Allow("b", 5, now.Add(90*time.Second))
+	if _, ok := limiter.buckets["a"]; ok {
+		t.Fatalf("idle key should be evicted")
+	}
+	if _, ok := limiter.buckets["b"]; !ok {
+		t.Fatalf("active key should be kept")
+	}
+}
+
+func TestInboundRateLimiterAllowKnown(t *testing.T) {
+	limiter := NewInboundRateLimiter(time.Minute)
+	now := time.Date(2026, 3, 11, 8, 0, 0, 0, time.UTC)
+
+	if allowed, known := limiter.AllowKnown("random", now); !allowed || known {
+		t.Fatalf("unknown key should pass without being recorded, got allowed=%v known=%v", allowed, known)
+	}
+	if _, ok := limiter.buckets["random"]; ok {
+		t.Fatalf("unknown key should not create a bucket")
+	}
+
+	limiter.Allow("a", 2, now)
+	if allowed, known := limiter.AllowKnown("a", now.Add(time.Second)); !allowed || !known {
+		t.Fatalf("second request should be allowed, got allowed=%v known=%v", allowed, known)
+	}
+	if allowed, _ := limiter.AllowKnown("a", now.Add(2*time.Second)); allowed {
+		t.Fatalf("recorded limit should throttle before lookup")
+	}
+	if allowed, _ := limiter.AllowKnown("a", now.Add(time.Minute)); !allowed {
+		t.Fatalf("new window should reset the counter")
+	}
+}
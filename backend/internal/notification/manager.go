@@ -75,9 +75,17 @@ func (m *Manager) RegisterProvider(provider NotificationProvider) error {
 
 // SendToChannel 直接向指定渠道发送通知，不依赖事件订阅规则
 func (m *Manager) SendToChannel(ctx context.Context, channelID uint, event *Event) error {
+	return m.SendToChannelWithTemplate(ctx, channelID, "", event)
+}
+
+// SendToChannelWithTemplate 使用指定模板直接向渠道发送通知
+func (m *Manager) SendToChannelWithTemplate(ctx context.Context, channelID uint, templateName string, event *Event) error {
 	m.mu.RLock()
 	channel, exists := m.channels[channelID]
-	provider := m.providers[channel.Type]
+	var provider NotificationProvider
+	if exists && channel != nil {
+		provider = m.providers[channel.Type]
+	}
 	m.mu.RUnlock()
 
 	if !exists || channel == nil {
@@ -89,12 +97,18 @@ func (m *Manager) SendToChannel(ctx context.Context, channelID uint, event *Even
 	}
 
 	if m.templateMgr != nil {
-		templateName := m.determineTemplateName(channel, nil)
+		if templateName == "" {
+			templateName = m.determineTemplateName(channel, nil)
+		}
 		if content, err := m.templateMgr.Render(templateName, event); err == nil {
-			if event.Data == nil {
-				event.Data = make(map[string]interface{})
+			// 同一事件会按渠道逐个发送，复制一份 event data，避免各渠道的渲染结果互相覆盖
+			channelEvent := *event
+			channelEvent.Data = make(map[string]interface{}, len(event.Data)+1)
+			for k, v := range event.Data {
+				channelEvent.Data[k] = v
 			}
-			event.Data["rendered_content"] = content
+			channelEvent.Data["rendered_content"] = content
+			event = &channelEvent
 		} else {
 			m.logger.Errorf("渲染模板失败: name=%s err=%v", templateName, err)
 		}
@@ -226,9 +240,14 @@ func (m *Manager) Notify(ctx context.Context, event *Event) error {
 	// 1. 评估告警规则
 	triggeredRules := m.evaluateRules(ctx, event)
 
-	// 2. 从规则中收集渠道 ID
+	// 2. 从规则中收集渠道 ID（配置了升级策略的规则由升级链路负责通知）
 	ruleChannelIDs := make(map[uint]bool)
+	var escalationRules []*model.NotificationRule
 	for _, rule := range triggeredRules {
+		if rule.EscalationPolicyID != nil {
+			escalationRules = append(escalationRules, rule)
+			continue
+		}
 		for _, channelID := range rule.ChannelIDs {
 			ruleChannelIDs[uint(channelID)] = true
 		}
@@ -254,6 +273,11 @@ func (m *Manager) Notify(ctx context.Context, event *Event) error {
 		m.updateRuleTriggerStatus(ctx, rule)
 	}
 
+	// 启动升级链路（第一步立即入队，后续步骤由扫描器推进）
+	for _, rule := range escalationRules {
+		m.startEscalation(ctx, rule, event)
+	}
+
 	if len(channelsToNotify) == 0 {
 		m.logger.Infof("事件没有匹配的通知渠道: %s", event.Type)
 		return nil
@@ -264,6 +288,9 @@ func (m *Manager) Notify(ctx context.Context, event *Event) error {
 		// 查找对应的规则 (用于模板渲染)
 		var rule *model.NotificationRule
 		for _, r := range triggeredRules {
+			if r.EscalationPolicyID != nil {
+				continue
+			}
 			for _, cid := range r.ChannelIDs {
 				if uint(cid) == channel.ID {
 					rule = r
@@ -481,6 +508,11 @@ func (m *Manager) determineTemplateName(channel *model.NotificationChannel, rule
 }
 
 func (m *Manager) enqueueJob(ctx context.Context, channel *model.NotificationChannel, event *Event, rule *model.NotificationRule) uint {
+	return m.enqueueEscalationJob(ctx, channel, event, rule, 0, 0)
+}
+
+// enqueueEscalationJob 入队通知任务；escalationID 非 0 时记录所属升级链路及步骤
+func (m *Manager) enqueueEscalationJob(ctx context.Context, channel *model.NotificationChannel, event *Event, rule *model.NotificationRule, escalationID uint, escalationStep int) uint {
 	// 渲染通知内容（在入队时渲染，避免 worker 发送时再依赖共享 event.Data）
 	templateName := m.determineTemplateName(channel, rule)
 	content := ""
@@ -509,6 +541,10 @@ func (m *Manager) enqueueJob(ctx context.Context, channel *model.NotificationCha
 	if content != "" {
 		eventData["rendered_content"] = content
 	}
+	if escalationID > 0 {
+		eventData["escalation_id"] = escalationID
+		eventData["escalation_step"] = escalationStep + 1
+	}
 
 	// 创建通知日志
 	log := &model.NotificationLog{
@@ -520,6 +556,10 @@ func (m *Manager) enqueueJob(ctx context.Context, channel *model.NotificationCha
 	if rule != nil {
 		log.RuleID = &rule.ID
 	}
+	if escalationID > 0 {
+		log.EscalationID = &escalationID
+		log.EscalationStep = escalationStep
+	}
 	if err := m.db.WithContext(ctx).Create(log).Error; err != nil {
 		m.logger.Errorf("创建通知日志失败: %v", err)
 		return 0
@@ -540,6 +580,10 @@ func (m *Manager) enqueueJob(ctx context.Context, channel *model.NotificationCha
 		RetryCount:   0,
 		NextRunAt:    time.Now(),
 	}
+	if escalationID > 0 {
+		job.EscalationID = &escalationID
+		job.EscalationStep = escalationStep
+	}
 	if err := m.db.WithContext(ctx).Create(job).Error; err != nil {
 		m.logger.Errorf("创建通知任务失败: %v", err)
 		// 同步标记 log 失败（避免 UI 长期 pending）
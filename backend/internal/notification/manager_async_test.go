@@ -65,6 +65,8 @@ func TestManager_Notify_EnqueuesJobsAndReturns(t *testing.T) {
 			sqlmock.AnyArg(),
 			sqlmock.AnyArg(),
 			sqlmock.AnyArg(),
+			sqlmock.AnyArg(),
+			sqlmock.AnyArg(),
 		).WillReturnRows(
 		sqlmock.NewRows([]string{"id"}).AddRow(1),
 	)
@@ -88,6 +90,8 @@ func TestManager_Notify_EnqueuesJobsAndReturns(t *testing.T) {
 			sqlmock.AnyArg(),
 			sqlmock.AnyArg(),
 			sqlmock.AnyArg(),
+			sqlmock.AnyArg(),
+			sqlmock.AnyArg(),
 		).WillReturnRows(
 		sqlmock.NewRows([]string{"id"}).AddRow(10),
 	)
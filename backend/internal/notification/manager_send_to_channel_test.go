@@ -80,4 +80,7 @@ func TestManagerSendToChannel(t *testing.T) {
 	if provider.lastConfig["url"] != "https://example.com" {
 		t.Fatalf("provider config url = %v", provider.lastConfig["url"])
 	}
+	if _, ok := event.Data["rendered_content"]; ok {
+		t.Fatal("expected caller event data to stay untouched")
+	}
 }
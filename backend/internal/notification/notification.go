@@ -32,4 +32,8 @@ type NotificationManager interface {
 
 	// SendToChannel 直接向指定渠道发送通知
 	SendToChannel(ctx context.Context, channelID uint, event *Event) error
+
+	// SendToChannelWithTemplate 使用指定模板直接向渠道发送通知
+	// templateName 为空时按渠道类型选择默认模板
+	SendToChannelWithTemplate(ctx context.Context, channelID uint, templateName string, event *Event) error
 }
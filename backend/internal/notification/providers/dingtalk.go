@@ -0,0 +1,154 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"logflux/internal/notification"
+	"logflux/model"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DingTalkProvider 钉钉自定义机器人通知提供者
+type DingTalkProvider struct {
+	client *http.Client
+}
+
+// NewDingTalkProvider 创建钉钉通知提供者
+func NewDingTalkProvider() *DingTalkProvider {
+	return &DingTalkProvider{
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Send 发送通知
+func (d *DingTalkProvider) Send(ctx context.Context, config map[string]interface{}, event *notification.Event) error {
+	dingConfig := &model.DingTalkConfig{}
+	if err := mapToStruct(config, dingConfig); err != nil {
+		return fmt.Errorf("钉钉配置无效: %w", err)
+	}
+
+	requestURL, err := signDingTalkURL(dingConfig.WebhookURL, dingConfig.Secret, time.Now())
+	if err != nil {
+		return err
+	}
+
+	payload := map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]string{
+			"title": event.Title,
+			"text":  formatDingTalkMessage(event),
+		},
+		"at": map[string]interface{}{
+			"atMobiles": dingConfig.AtMobiles,
+			"isAtAll":   dingConfig.AtAll,
+		},
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("序列化钉钉消息失败: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, requestURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送钉钉请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("钉钉 Webhook 返回状态: %d，响应: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &result); err == nil && result.ErrCode != 0 {
+			return fmt.Errorf("钉钉 API 错误: errcode=%d errmsg=%s", result.ErrCode, result.ErrMsg)
+		}
+	}
+
+	return nil
+}
+
+// Validate 验证配置
+func (d *DingTalkProvider) Validate(config map[string]interface{}) error {
+	dingConfig := &model.DingTalkConfig{}
+	if err := mapToStruct(config, dingConfig); err != nil {
+		return fmt.Errorf("钉钉配置无效: %w", err)
+	}
+
+	if strings.TrimSpace(dingConfig.WebhookURL) == "" {
+		return fmt.Errorf("回调 URL 不能为空")
+	}
+
+	if !isValidURL(dingConfig.WebhookURL) {
+		return fmt.Errorf("回调 URL 无效: %s", dingConfig.WebhookURL)
+	}
+
+	return nil
+}
+
+// Type 返回提供者类型
+func (d *DingTalkProvider) Type() string {
+	return model.ChannelTypeDingTalk
+}
+
+// signDingTalkURL 按钉钉加签规则追加 timestamp/sign 参数
+// sign = urlencode(base64(hmac_sha256(secret, timestamp + "\n" + secret)))，timestamp 为毫秒。
+func signDingTalkURL(webhookURL, secret string, now time.Time) (string, error) {
+	secret = strings.TrimSpace(secret)
+	if secret == "" {
+		return webhookURL, nil
+	}
+
+	parsed, err := url.Parse(webhookURL)
+	if err != nil {
+		return "", fmt.Errorf("回调 URL 无效: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(now.UnixMilli(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "\n" + secret))
+	sign := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	query := parsed.Query()
+	query.Set("timestamp", timestamp)
+	query.Set("sign", sign)
+	parsed.RawQuery = query.Encode()
+	return parsed.String(), nil
+}
+
+func formatDingTalkMessage(event *notification.Event) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("### [%s] %s\n\n", strings.ToUpper(event.Level), event.Title))
+	builder.WriteString(event.Message)
+	builder.WriteString("\n\n")
+	builder.WriteString(fmt.Sprintf("> 事件: %s\n\n", event.Type))
+	builder.WriteString(fmt.Sprintf("> 时间: %s", event.Timestamp.Format(time.DateTime)))
+
+	if renderedContent, ok := event.Data["rendered_content"].(string); ok && strings.TrimSpace(renderedContent) != "" {
+		builder.WriteString("\n\n")
+		builder.WriteString(renderedContent)
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,125 @@
+package providers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"logflux/internal/notification"
+	"logflux/model"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDingTalkProvider_Validate(t *testing.T) {
+	provider := NewDingTalkProvider()
+
+	tests := []struct {
+		name    string
+		config  map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name: "valid config with secret",
+			config: map[string]interface{}{
+				"webhook_url": "https://oapi.dingtalk.com/robot/send?access_token=test",
+				"secret":      "SECtest",
+			},
+			wantErr: false,
+		},
+		{
+			name:    "missing webhook_url",
+			config:  map[string]interface{}{},
+			wantErr: true,
+		},
+		{
+			name: "invalid webhook_url",
+			config: map[string]interface{}{
+				"webhook_url": "oapi.dingtalk.com/robot/send",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := provider.Validate(testCase.config)
+			if (err != nil) != testCase.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, testCase.wantErr)
+			}
+		})
+	}
+}
+
+func TestDingTalkProvider_SendSigned(t *testing.T) {
+	const secret = "SECtest-secret"
+	var receivedBody map[string]interface{}
+	var timestamp, sign, token string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timestamp = r.URL.Query().Get("timestamp")
+		sign = r.URL.Query().Get("sign")
+		token = r.URL.Query().Get("access_token")
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&receivedBody); err != nil {
+			t.Fatalf("decode request body: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer server.Close()
+
+	provider := NewDingTalkProvider()
+	err := provider.Send(context.Background(), map[string]interface{}{
+		"webhook_url": server.URL + "/robot/send?access_token=abc",
+		"secret":      secret,
+	}, &notification.Event{
+		Type:      "system.test",
+		Level:     notification.LevelWarning,
+		Title:     "Disk",
+		Message:   "disk almost full",
+		Timestamp: time.Unix(1700000000, 0),
+		Data:      map[string]interface{}{},
+	})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if token != "abc" {
+		t.Fatalf("access_token = %q, want %q", token, "abc")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "\n" + secret))
+	if want := base64.StdEncoding.EncodeToString(mac.Sum(nil)); sign != want {
+		t.Fatalf("sign = %q, want %q", sign, want)
+	}
+
+	if got := receivedBody["msgtype"]; got != "markdown" {
+		t.Fatalf("msgtype = %v, want markdown", got)
+	}
+}
+
+func TestDingTalkProvider_SendAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"errcode":310000,"errmsg":"sign not match"}`))
+	}))
+	defer server.Close()
+
+	provider := NewDingTalkProvider()
+	err := provider.Send(context.Background(), map[string]interface{}{
+		"webhook_url": server.URL,
+	}, notification.NewEvent("system.test", notification.LevelInfo, "t", "m"))
+	if err == nil {
+		t.Fatalf("expected error for non-zero errcode")
+	}
+}
+
+func TestDingTalkProvider_Type(t *testing.T) {
+	provider := NewDingTalkProvider()
+	if provider.Type() != model.ChannelTypeDingTalk {
+		t.Fatalf("Type() = %v, want %v", provider.Type(), model.ChannelTypeDingTalk)
+	}
+}
@@ -0,0 +1,164 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"logflux/internal/notification"
+	"logflux/model"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FeishuProvider 飞书/Lark 自定义机器人通知提供者
+type FeishuProvider struct {
+	client *http.Client
+}
+
+// NewFeishuProvider 创建飞书通知提供者
+func NewFeishuProvider() *FeishuProvider {
+	return &FeishuProvider{
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Send 发送通知
+func (f *FeishuProvider) Send(ctx context.Context, config map[string]interface{}, event *notification.Event) error {
+	feishuConfig := &model.FeishuConfig{}
+	if err := mapToStruct(config, feishuConfig); err != nil {
+		return fmt.Errorf("飞书配置无效: %w", err)
+	}
+
+	payload := buildFeishuPayload(event)
+	if secret := strings.TrimSpace(feishuConfig.Secret); secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		payload["timestamp"] = timestamp
+		payload["sign"] = signFeishu(secret, timestamp)
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("序列化飞书消息失败: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, feishuConfig.WebhookURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送飞书请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("飞书 Webhook 返回状态: %d，响应: %s", resp.StatusCode, string(body))
+	}
+
+	// 新版接口返回 code/msg，旧版返回 StatusCode/StatusMessage
+	var result struct {
+		Code          int    `json:"code"`
+		Msg           string `json:"msg"`
+		StatusCode    int    `json:"StatusCode"`
+		StatusMessage string `json:"StatusMessage"`
+	}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &result); err == nil {
+			if result.Code != 0 {
+				return fmt.Errorf("飞书 API 错误: code=%d msg=%s", result.Code, result.Msg)
+			}
+			if result.StatusCode != 0 {
+				return fmt.Errorf("飞书 API 错误: code=%d msg=%s", result.StatusCode, result.StatusMessage)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Validate 验证配置
+func (f *FeishuProvider) Validate(config map[string]interface{}) error {
+	feishuConfig := &model.FeishuConfig{}
+	if err := mapToStruct(config, feishuConfig); err != nil {
+		return fmt.Errorf("飞书配置无效: %w", err)
+	}
+
+	if strings.TrimSpace(feishuConfig.WebhookURL) == "" {
+		return fmt.Errorf("回调 URL 不能为空")
+	}
+
+	if !isValidURL(feishuConfig.WebhookURL) {
+		return fmt.Errorf("回调 URL 无效: %s", feishuConfig.WebhookURL)
+	}
+
+	return nil
+}
+
+// Type 返回提供者类型
+func (f *FeishuProvider) Type() string {
+	return model.ChannelTypeFeishu
+}
+
+// signFeishu 按飞书签名校验规则生成签名
+// 以 timestamp + "\n" + secret 作为 HMAC-SHA256 密钥，对空串签名后 base64，timestamp 为秒。
+func signFeishu(secret, timestamp string) string {
+	mac := hmac.New(sha256.New, []byte(timestamp+"\n"+secret))
+	mac.Write([]byte{})
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func buildFeishuPayload(event *notification.Event) map[string]interface{} {
+	template := "blue"
+	switch event.Level {
+	case notification.LevelCritical, notification.LevelError:
+		template = "red"
+	case notification.LevelWarning:
+		template = "orange"
+	}
+
+	content := event.Message
+	if renderedContent, ok := event.Data["rendered_content"].(string); ok && strings.TrimSpace(renderedContent) != "" {
+		content = renderedContent
+	}
+
+	return map[string]interface{}{
+		"msg_type": "interactive",
+		"card": map[string]interface{}{
+			"header": map[string]interface{}{
+				"title": map[string]string{
+					"tag":     "plain_text",
+					"content": fmt.Sprintf("[%s] %s", strings.ToUpper(event.Level), event.Title),
+				},
+				"template": template,
+			},
+			"elements": []interface{}{
+				map[string]interface{}{
+					"tag": "div",
+					"text": map[string]string{
+						"tag":     "lark_md",
+						"content": content,
+					},
+				},
+				map[string]interface{}{
+					"tag": "note",
+					"elements": []interface{}{
+						map[string]string{
+							"tag":     "plain_text",
+							"content": fmt.Sprintf("事件: %s | 时间: %s", event.Type, event.Timestamp.Format(time.DateTime)),
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,105 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"logflux/internal/notification"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFeishuProvider_Validate(t *testing.T) {
+	provider := NewFeishuProvider()
+
+	tests := []struct {
+		name    string
+		config  map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name: "valid lark config",
+			config: map[string]interface{}{
+				"webhook_url": "https://open.larksuite.com/open-apis/bot/v2/hook/xxx",
+			},
+			wantErr: false,
+		},
+		{
+			name:    "missing webhook_url",
+			config:  map[string]interface{}{"secret": "s"},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := provider.Validate(testCase.config)
+			if (err != nil) != testCase.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, testCase.wantErr)
+			}
+		})
+	}
+}
+
+func TestFeishuProvider_SendSigned(t *testing.T) {
+	const secret = "feishu-secret"
+	var receivedBody map[string]interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&receivedBody); err != nil {
+			t.Fatalf("decode request body: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"code":0,"msg":"success"}`))
+	}))
+	defer server.Close()
+
+	provider := NewFeishuProvider()
+	err := provider.Send(context.Background(), map[string]interface{}{
+		"webhook_url": server.URL,
+		"secret":      secret,
+	}, &notification.Event{
+		Type:      "system.test",
+		Level:     notification.LevelCritical,
+		Title:     "Down",
+		Message:   "upstream down",
+		Timestamp: time.Unix(1700000000, 0),
+		Data:      map[string]interface{}{"rendered_content": "**rendered**"},
+	})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	timestamp, _ := receivedBody["timestamp"].(string)
+	if timestamp == "" {
+		t.Fatalf("expected timestamp in signed payload")
+	}
+	if got := receivedBody["sign"]; got != signFeishu(secret, timestamp) {
+		t.Fatalf("sign = %v, want %v", got, signFeishu(secret, timestamp))
+	}
+	if got := receivedBody["msg_type"]; got != "interactive" {
+		t.Fatalf("msg_type = %v, want interactive", got)
+	}
+
+	card := receivedBody["card"].(map[string]interface{})
+	header := card["header"].(map[string]interface{})
+	if got := header["template"]; got != "red" {
+		t.Fatalf("header template = %v, want red", got)
+	}
+}
+
+func TestFeishuProvider_SendAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"code":19021,"msg":"sign match fail or timestamp is not within one hour from current time"}`))
+	}))
+	defer server.Close()
+
+	provider := NewFeishuProvider()
+	err := provider.Send(context.Background(), map[string]interface{}{
+		"webhook_url": server.URL,
+	}, notification.NewEvent("system.test", notification.LevelInfo, "t", "m"))
+	if err == nil {
+		t.Fatalf("expected error for non-zero code")
+	}
+}
@@ -0,0 +1,123 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"logflux/internal/notification"
+	"logflux/model"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// GotifyProvider Gotify 推送通知提供者
+type GotifyProvider struct {
+	client *http.Client
+}
+
+// NewGotifyProvider 创建 Gotify 通知提供者
+func NewGotifyProvider() *GotifyProvider {
+	return &GotifyProvider{
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Send 发送通知
+func (g *GotifyProvider) Send(ctx context.Context, config map[string]interface{}, event *notification.Event) error {
+	gotifyConfig := &model.GotifyConfig{}
+	if err := mapToStruct(config, gotifyConfig); err != nil {
+		return fmt.Errorf("Gotify 配置无效: %w", err)
+	}
+
+	content := event.Message
+	if renderedContent, ok := event.Data["rendered_content"].(string); ok && strings.TrimSpace(renderedContent) != "" {
+		content = renderedContent
+	}
+
+	priority := gotifyConfig.Priority
+	if priority <= 0 {
+		priority = gotifyPriority(event.Level)
+	}
+
+	payload := map[string]interface{}{
+		"title":    event.Title,
+		"message":  content,
+		"priority": priority,
+		"extras": map[string]interface{}{
+			"client::display": map[string]string{
+				"contentType": "text/markdown",
+			},
+		},
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("序列化 Gotify 消息失败: %w", err)
+	}
+
+	messageURL := strings.TrimRight(strings.TrimSpace(gotifyConfig.ServerURL), "/") + "/message"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, messageURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Gotify-Key", gotifyConfig.AppToken)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送 Gotify 请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("Gotify 返回状态: %d，响应: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// Validate 验证配置
+func (g *GotifyProvider) Validate(config map[string]interface{}) error {
+	gotifyConfig := &model.GotifyConfig{}
+	if err := mapToStruct(config, gotifyConfig); err != nil {
+		return fmt.Errorf("Gotify 配置无效: %w", err)
+	}
+
+	if strings.TrimSpace(gotifyConfig.ServerURL) == "" {
+		return fmt.Errorf("服务地址不能为空")
+	}
+	if !isValidURL(gotifyConfig.ServerURL) {
+		return fmt.Errorf("服务地址无效: %s", gotifyConfig.ServerURL)
+	}
+	if strings.TrimSpace(gotifyConfig.AppToken) == "" {
+		return fmt.Errorf("应用令牌不能为空")
+	}
+	if gotifyConfig.Priority < 0 || gotifyConfig.Priority > 10 {
+		return fmt.Errorf("优先级需在 0-10 之间")
+	}
+
+	return nil
+}
+
+// Type 返回提供者类型
+func (g *GotifyProvider) Type() string {
+	return model.ChannelTypeGotify
+}
+
+// gotifyPriority 将事件级别映射为 Gotify 优先级 (0-10)
+func gotifyPriority(level string) int {
+	switch level {
+	case notification.LevelCritical:
+		return 10
+	case notification.LevelError:
+		return 8
+	case notification.LevelWarning:
+		return 5
+	default:
+		return 2
+	}
+}
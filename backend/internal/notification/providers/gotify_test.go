@@ -0,0 +1,99 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"logflux/internal/notification"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGotifyProvider_Validate(t *testing.T) {
+	provider := NewGotifyProvider()
+
+	tests := []struct {
+		name    string
+		config  map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name:    "valid config",
+			config:  map[string]interface{}{"server_url": "https://gotify.example.com", "app_token": "AbC"},
+			wantErr: false,
+		},
+		{
+			name:    "missing token",
+			config:  map[string]interface{}{"server_url": "https://gotify.example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "priority out of range",
+			config:  map[string]interface{}{"server_url": "https://gotify.example.com", "app_token": "AbC", "priority": 11},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := provider.Validate(testCase.config)
+			if (err != nil) != testCase.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, testCase.wantErr)
+			}
+		})
+	}
+}
+
+func TestGotifyProvider_Send(t *testing.T) {
+	var receivedKey, receivedPath string
+	var receivedBody map[string]interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedKey = r.Header.Get("X-Gotify-Key")
+		receivedPath = r.URL.Path
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&receivedBody); err != nil {
+			t.Fatalf("decode request body: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"id":1}`))
+	}))
+	defer server.Close()
+
+	provider := NewGotifyProvider()
+	err := provider.Send(context.Background(), map[string]interface{}{
+		"server_url": server.URL,
+		"app_token":  "AbC",
+	}, notification.NewEvent("system.error", notification.LevelError, "Error", "something broke"))
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if receivedPath != "/message" {
+		t.Fatalf("path = %q, want /message", receivedPath)
+	}
+	if receivedKey != "AbC" {
+		t.Fatalf("X-Gotify-Key = %q, want AbC", receivedKey)
+	}
+	if got := receivedBody["priority"]; got != float64(8) {
+		t.Fatalf("priority = %v, want 8", got)
+	}
+	if got := receivedBody["message"]; got != "something broke" {
+		t.Fatalf("message = %v, want something broke", got)
+	}
+}
+
+func TestGotifyProvider_SendUnauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	provider := NewGotifyProvider()
+	err := provider.Send(context.Background(), map[string]interface{}{
+		"server_url": server.URL,
+		"app_token":  "bad",
+	}, notification.NewEvent("system.error", notification.LevelError, "Error", "m"))
+	if err == nil {
+		t.Fatalf("expected error for 401 response")
+	}
+}
@@ -0,0 +1,115 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"logflux/internal/notification"
+	"logflux/model"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// NtfyProvider ntfy 推送通知提供者
+type NtfyProvider struct {
+	client *http.Client
+}
+
+// NewNtfyProvider 创建 ntfy 通知提供者
+func NewNtfyProvider() *NtfyProvider {
+	return &NtfyProvider{
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Send 发送通知
+func (n *NtfyProvider) Send(ctx context.Context, config map[string]interface{}, event *notification.Event) error {
+	ntfyConfig := &model.NtfyConfig{}
+	if err := mapToStruct(config, ntfyConfig); err != nil {
+		return fmt.Errorf("ntfy 配置无效: %w", err)
+	}
+
+	topicURL := strings.TrimRight(strings.TrimSpace(ntfyConfig.ServerURL), "/") + "/" + strings.Trim(strings.TrimSpace(ntfyConfig.Topic), "/")
+
+	content := event.Message
+	if renderedContent, ok := event.Data["rendered_content"].(string); ok && strings.TrimSpace(renderedContent) != "" {
+		content = renderedContent
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, topicURL, strings.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Title", event.Title)
+	req.Header.Set("Priority", ntfyPriority(event.Level))
+	req.Header.Set("Markdown", "yes")
+
+	tags := []string{event.Level}
+	if ntfyConfig.Tags != "" {
+		tags = append(tags, ntfyConfig.Tags)
+	}
+	req.Header.Set("Tags", strings.Join(tags, ","))
+
+	switch {
+	case ntfyConfig.Token != "":
+		req.Header.Set("Authorization", "Bearer "+ntfyConfig.Token)
+	case ntfyConfig.Username != "":
+		req.SetBasicAuth(ntfyConfig.Username, ntfyConfig.Password)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送 ntfy 请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("ntfy 返回状态: %d，响应: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// Validate 验证配置
+func (n *NtfyProvider) Validate(config map[string]interface{}) error {
+	ntfyConfig := &model.NtfyConfig{}
+	if err := mapToStruct(config, ntfyConfig); err != nil {
+		return fmt.Errorf("ntfy 配置无效: %w", err)
+	}
+
+	if strings.TrimSpace(ntfyConfig.ServerURL) == "" {
+		return fmt.Errorf("服务地址不能为空")
+	}
+	if !isValidURL(ntfyConfig.ServerURL) {
+		return fmt.Errorf("服务地址无效: %s", ntfyConfig.ServerURL)
+	}
+	if strings.Trim(strings.TrimSpace(ntfyConfig.Topic), "/") == "" {
+		return fmt.Errorf("主题不能为空")
+	}
+	if ntfyConfig.Token != "" && ntfyConfig.Username != "" {
+		return fmt.Errorf("访问令牌与用户名密码只能配置其一")
+	}
+
+	return nil
+}
+
+// Type 返回提供者类型
+func (n *NtfyProvider) Type() string {
+	return model.ChannelTypeNtfy
+}
+
+// ntfyPriority 将事件级别映射为 ntfy 优先级 (1-5)
+func ntfyPriority(level string) string {
+	switch level {
+	case notification.LevelCritical:
+		return "5"
+	case notification.LevelError:
+		return "4"
+	case notification.LevelWarning:
+		return "3"
+	default:
+		return "2"
+	}
+}
@@ -0,0 +1,94 @@
+package providers
+
+import (
+	"context"
+	"io"
+	"logflux/internal/notification"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNtfyProvider_Validate(t *testing.T) {
+	provider := NewNtfyProvider()
+
+	tests := []struct {
+		name    string
+		config  map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name:    "valid config",
+			config:  map[string]interface{}{"server_url": "https://ntfy.sh", "topic": "logflux"},
+			wantErr: false,
+		},
+		{
+			name:    "missing topic",
+			config:  map[string]interface{}{"server_url": "https://ntfy.sh"},
+			wantErr: true,
+		},
+		{
+			name: "token and basic auth",
+			config: map[string]interface{}{
+				"server_url": "https://ntfy.sh",
+				"topic":      "logflux",
+				"token":      "tk_x",
+				"username":   "u",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := provider.Validate(testCase.config)
+			if (err != nil) != testCase.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, testCase.wantErr)
+			}
+		})
+	}
+}
+
+func TestNtfyProvider_Send(t *testing.T) {
+	var receivedHeaders http.Header
+	var receivedPath, receivedBody string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedHeaders = r.Header.Clone()
+		receivedPath = r.URL.Path
+		body, _ := io.ReadAll(r.Body)
+		receivedBody = string(body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	provider := NewNtfyProvider()
+	event := notification.NewEvent("security.brute_force", notification.LevelCritical, "Brute force", "fallback")
+	event.Data["rendered_content"] = "rendered body"
+
+	err := provider.Send(context.Background(), map[string]interface{}{
+		"server_url": server.URL + "/",
+		"topic":      "alerts",
+		"token":      "tk_secret",
+		"tags":       "logflux",
+	}, event)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if receivedPath != "/alerts" {
+		t.Fatalf("path = %q, want /alerts", receivedPath)
+	}
+	if receivedBody != "rendered body" {
+		t.Fatalf("body = %q, want rendered body", receivedBody)
+	}
+	if got := receivedHeaders.Get("Priority"); got != "5" {
+		t.Fatalf("Priority = %q, want 5", got)
+	}
+	if got := receivedHeaders.Get("Authorization"); got != "Bearer tk_secret" {
+		t.Fatalf("Authorization = %q, want Bearer tk_secret", got)
+	}
+	if got := receivedHeaders.Get("Title"); got != "Brute force" {
+		t.Fatalf("Title = %q, want Brute force", got)
+	}
+}
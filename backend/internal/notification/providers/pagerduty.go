@@ -0,0 +1,185 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"logflux/internal/notification"
+	"logflux/model"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const defaultPagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDutyProvider PagerDuty Events v2 通知提供者（兼容同协议的告警平台）
+type PagerDutyProvider struct {
+	client *http.Client
+}
+
+// NewPagerDutyProvider 创建 PagerDuty 通知提供者
+func NewPagerDutyProvider() *PagerDutyProvider {
+	return &PagerDutyProvider{
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Send 发送通知
+func (p *PagerDutyProvider) Send(ctx context.Context, config map[string]interface{}, event *notification.Event) error {
+	pdConfig := &model.PagerDutyConfig{}
+	if err := mapToStruct(config, pdConfig); err != nil {
+		return fmt.Errorf("PagerDuty 配置无效: %w", err)
+	}
+
+	eventsURL := strings.TrimSpace(pdConfig.EventsURL)
+	if eventsURL == "" {
+		eventsURL = defaultPagerDutyEventsURL
+	}
+
+	jsonData, err := json.Marshal(buildPagerDutyPayload(pdConfig, event))
+	if err != nil {
+		return fmt.Errorf("序列化 PagerDuty 事件失败: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, eventsURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送 PagerDuty 请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("PagerDuty 返回状态: %d，响应: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// Validate 验证配置
+func (p *PagerDutyProvider) Validate(config map[string]interface{}) error {
+	pdConfig := &model.PagerDutyConfig{}
+	if err := mapToStruct(config, pdConfig); err != nil {
+		return fmt.Errorf("PagerDuty 配置无效: %w", err)
+	}
+
+	if strings.TrimSpace(pdConfig.RoutingKey) == "" {
+		return fmt.Errorf("routing_key 不能为空")
+	}
+
+	if pdConfig.EventsURL != "" && !isValidURL(pdConfig.EventsURL) {
+		return fmt.Errorf("事件接收 URL 无效: %s", pdConfig.EventsURL)
+	}
+
+	return nil
+}
+
+// Type 返回提供者类型
+func (p *PagerDutyProvider) Type() string {
+	return model.ChannelTypePagerDuty
+}
+
+func buildPagerDutyPayload(config *model.PagerDutyConfig, event *notification.Event) map[string]interface{} {
+	action := "trigger"
+	resolveField := strings.TrimSpace(config.ResolveField)
+	if resolveField == "" {
+		resolveField = "resolved"
+	}
+	if resolved, ok := event.Data[resolveField].(bool); ok && resolved {
+		action = "resolve"
+	}
+
+	payload := map[string]interface{}{
+		"routing_key":  config.RoutingKey,
+		"event_action": action,
+		"dedup_key":    resolvePagerDutyDedupKey(config, event),
+	}
+	if action == "resolve" {
+		return payload
+	}
+
+	source := strings.TrimSpace(config.Source)
+	if source == "" {
+		source = "logflux"
+	}
+
+	details := make(map[string]interface{}, len(event.Data))
+	for k, v := range event.Data {
+		if k == "rendered_content" {
+			continue
+		}
+		details[k] = v
+	}
+	if event.Message != "" {
+		details["message"] = event.Message
+	}
+
+	body := map[string]interface{}{
+		"summary":        truncatePagerDutySummary(fmt.Sprintf("[%s] %s", strings.ToUpper(event.Level), event.Title)),
+		"source":         source,
+		"severity":       pagerDutySeverity(event.Level),
+		"timestamp":      event.Timestamp.Format(time.RFC3339),
+		"class":          event.Type,
+		"custom_details": details,
+	}
+	if config.Component != "" {
+		body["component"] = config.Component
+	}
+	if config.Group != "" {
+		body["group"] = config.Group
+	}
+	payload["payload"] = body
+
+	return payload
+}
+
+// resolvePagerDutyDedupKey 优先使用事件数据中的去重键，否则按事件类型+标题生成稳定的键
+func resolvePagerDutyDedupKey(config *model.PagerDutyConfig, event *notification.Event) string {
+	fields := []string{"dedup_key"}
+	if field := strings.TrimSpace(config.DedupKeyField); field != "" {
+		fields = append([]string{field}, fields...)
+	}
+	for _, field := range fields {
+		if value, ok := event.Data[field]; ok && value != nil {
+			if key := strings.TrimSpace(fmt.Sprintf("%v", value)); key != "" {
+				return key
+			}
+		}
+	}
+
+	sum := sha1.Sum([]byte(event.Type + "|" + event.Title))
+	return "logflux-" + hex.EncodeToString(sum[:])
+}
+
+// pagerDutySeverity 将事件级别映射为 PagerDuty severity
+func pagerDutySeverity(level string) string {
+	switch level {
+	case notification.LevelCritical:
+		return "critical"
+	case notification.LevelError:
+		return "error"
+	case notification.LevelWarning:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+// truncatePagerDutySummary 截断摘要（PagerDuty 限制 1024 字符）
+func truncatePagerDutySummary(summary string) string {
+	runes := []rune(summary)
+	if len(runes) <= 1024 {
+		return summary
+	}
+	return string(runes[:1021]) + "..."
+}
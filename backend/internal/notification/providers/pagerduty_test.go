@@ -0,0 +1,104 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"logflux/internal/notification"
+	"logflux/model"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPagerDutyProvider_Validate(t *testing.T) {
+	provider := NewPagerDutyProvider()
+
+	if err := provider.Validate(map[string]interface{}{"routing_key": "R0123"}); err != nil {
+		t.Fatalf("Validate() unexpected error = %v", err)
+	}
+	if err := provider.Validate(map[string]interface{}{}); err == nil {
+		t.Fatalf("Validate() expected error for missing routing_key")
+	}
+	if err := provider.Validate(map[string]interface{}{"routing_key": "R0123", "events_url": "ftp://x"}); err == nil {
+		t.Fatalf("Validate() expected error for invalid events_url")
+	}
+}
+
+func TestPagerDutyProvider_TriggerAndResolve(t *testing.T) {
+	var bodies []map[string]interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode request body: %v", err)
+		}
+		bodies = append(bodies, body)
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"status":"success","message":"Event processed"}`))
+	}))
+	defer server.Close()
+
+	provider := NewPagerDutyProvider()
+	config := map[string]interface{}{
+		"routing_key":     "R0123",
+		"events_url":      server.URL,
+		"dedup_key_field": "host",
+	}
+
+	trigger := &notification.Event{
+		Type:      "log.high_error_rate",
+		Level:     notification.LevelError,
+		Title:     "High error rate",
+		Message:   "5xx > 10%",
+		Timestamp: time.Unix(1700000000, 0),
+		Data:      map[string]interface{}{"host": "api.example.com", "rendered_content": "ignored"},
+	}
+	if err := provider.Send(context.Background(), config, trigger); err != nil {
+		t.Fatalf("Send(trigger) error = %v", err)
+	}
+
+	resolve := &notification.Event{
+		Type:      "log.high_error_rate",
+		Level:     notification.LevelInfo,
+		Title:     "High error rate",
+		Timestamp: time.Unix(1700000600, 0),
+		Data:      map[string]interface{}{"host": "api.example.com", "resolved": true},
+	}
+	if err := provider.Send(context.Background(), config, resolve); err != nil {
+		t.Fatalf("Send(resolve) error = %v", err)
+	}
+
+	if len(bodies) != 2 {
+		t.Fatalf("received %d requests, want 2", len(bodies))
+	}
+
+	if got := bodies[0]["event_action"]; got != "trigger" {
+		t.Fatalf("first event_action = %v, want trigger", got)
+	}
+	payload := bodies[0]["payload"].(map[string]interface{})
+	if got := payload["severity"]; got != "error" {
+		t.Fatalf("severity = %v, want error", got)
+	}
+	details := payload["custom_details"].(map[string]interface{})
+	if _, exists := details["rendered_content"]; exists {
+		t.Fatalf("custom_details should not include rendered_content")
+	}
+
+	if got := bodies[1]["event_action"]; got != "resolve" {
+		t.Fatalf("second event_action = %v, want resolve", got)
+	}
+	if bodies[0]["dedup_key"] != "api.example.com" || bodies[1]["dedup_key"] != "api.example.com" {
+		t.Fatalf("dedup keys = %v / %v, want api.example.com", bodies[0]["dedup_key"], bodies[1]["dedup_key"])
+	}
+}
+
+func TestPagerDutyProvider_DefaultDedupKeyStable(t *testing.T) {
+	event := notification.NewEvent("system.error", notification.LevelCritical, "Boom", "m")
+	first := resolvePagerDutyDedupKey(&model.PagerDutyConfig{}, event)
+	second := resolvePagerDutyDedupKey(&model.PagerDutyConfig{}, event)
+	if first == "" || first != second {
+		t.Fatalf("dedup key should be stable and non-empty: %q / %q", first, second)
+	}
+}
@@ -262,6 +262,107 @@ Message: {{.Message}}
 Details: {{.Data}}
 {{end}}`,
 		},
+		{
+			Name:   "default_digest",
+			Format: "markdown",
+			Content: `**[{{.Level}}] {{.Title}}**
+
+**Window:** {{index .Data "window_start"}} ~ {{index .Data "window_end"}}
+
+**By type:**
+{{range index .Data "by_type"}}- {{.type}}: {{.count}}
+{{end}}
+**Top items:**
+{{range index .Data "items"}}- [{{.level}}] {{.title}} ({{.time}}){{if .link}} {{.link}}{{end}}
+{{end}}{{if gt (index .Data "omitted") 0}}
+... and {{index .Data "omitted"}} more
+{{end}}`,
+		},
+		{
+			Name:   "default_report_markdown",
+			Format: "markdown",
+			Content: `**{{.Title}}**
+{{with index .Data "report"}}
+**Period:** {{.Start}} ~ {{.End}} (vs {{.PrevStart}} ~ {{.PrevEnd}})
+**Hosts:** {{.HostScope}}
+
+**Traffic:**
+{{range .Metrics}}- {{.Name}}: {{.Current}} ({{.Delta}})
+{{end}}
+**Top errors:**
+{{range .TopErrors}}- {{.Status}} {{.Uri}}: {{.Count}}
+{{else}}- none
+{{end}}
+**Top attacked paths:**
+{{range .TopAttacked}}- {{.Uri}}: {{.Count}}
+{{else}}- none
+{{end}}
+**WAF blocks per policy:**
+{{range .PolicyBlocks}}- {{.Policy}}: {{.Current}} ({{.Delta}})
+{{else}}- none
+{{end}}{{end}}`,
+		},
+		{
+			Name:   "default_report_email",
+			Format: "html",
+			Content: `<!DOCTYPE html>
+<html>
+<head>
+<style>
+  body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+  .container { max-width: 720px; margin: 0 auto; padding: 20px; }
+  .header { background-color: #f8f9fa; padding: 15px; border-radius: 5px; margin-bottom: 20px; }
+  table { width: 100%; border-collapse: collapse; margin-bottom: 20px; }
+  th, td { border: 1px solid #dee2e6; padding: 6px 10px; text-align: left; }
+  th { background-color: #f8f9fa; }
+  .footer { font-size: 12px; color: #6c757d; margin-top: 30px; border-top: 1px solid #dee2e6; padding-top: 10px; }
+</style>
+</head>
+<body>
+<div class="container">
+  <div class="header">
+    <h2>{{.Title}}</h2>
+    {{with index .Data "report"}}
+    <p>Period: {{.Start}} ~ {{.End}} (vs {{.PrevStart}} ~ {{.PrevEnd}})</p>
+    <p>Hosts: {{.HostScope}}</p>
+    {{end}}
+  </div>
+  {{with index .Data "report"}}
+  <h3>Traffic</h3>
+  <table>
+    <tr><th>Metric</th><th>Current</th><th>Previous</th><th>Change</th></tr>
+    {{range .Metrics}}<tr><td>{{.Name}}</td><td>{{.Current}}</td><td>{{.Previous}}</td><td>{{.Delta}}</td></tr>
+    {{end}}
+  </table>
+  <h3>Top errors</h3>
+  <table>
+    <tr><th>Status</th><th>Path</th><th>Count</th></tr>
+    {{range .TopErrors}}<tr><td>{{.Status}}</td><td>{{.Uri}}</td><td>{{.Count}}</td></tr>
+    {{else}}<tr><td colspan="3">none</td></tr>
+    {{end}}
+  </table>
+  <h3>Top attacked paths</h3>
+  <table>
+    <tr><th>Path</th><th>Count</th></tr>
+    {{range .TopAttacked}}<tr><td>{{.Uri}}</td><td>{{.Count}}</td></tr>
+    {{else}}<tr><td colspan="2">none</td></tr>
+    {{end}}
+  </table>
+  <h3>WAF blocks per policy</h3>
+  <table>
+    <tr><th>Policy</th><th>Current</th><th>Previous</th><th>Change</th></tr>
+    {{range .PolicyBlocks}}<tr><td>{{.Policy}}</td><td>{{.Current}}</td><td>{{.Previous}}</td><td>{{.Delta}}</td></tr>
+    {{else}}<tr><td colspan="4">none</td></tr>
+    {{end}}
+  </table>
+  {{end}}
+  <div class="footer">
+    <p>Sent by LogFlux Notification System</p>
+  </div>
+</div>
+</body>
+</html>`,
+		},
 	}
 }
 
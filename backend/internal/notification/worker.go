@@ -33,6 +33,8 @@ func (m *Manager) scanLoop(ctx context.Context) {
 			return
 		case <-ticker.C:
 			m.dispatchDueJobs(ctx)
+			m.dispatchDueEscalations(ctx)
+			m.flushDigests(ctx)
 		}
 	}
 }
@@ -108,6 +110,12 @@ func (m *Manager) processJob(ctx context.Context, jobID uint) {
 		return
 	}
 
+	// 摘要模式：暂存任务，由扫描器按周期合并发送
+	if parseDigestPolicy(channel.Config).collects(&job) {
+		m.holdForDigest(ctx, &job)
+		return
+	}
+
 	// 4) Update log to sending
 	m.db.WithContext(ctx).Model(&model.NotificationLog{}).
 		Where("id = ?", job.LogID).
@@ -248,6 +256,30 @@ func parseRetryPolicy(config model.JSONMap) retryPolicy {
 	return policy
 }
 
+// nextDelay 第 attempt 次重试前的等待时间：指数退避，不超过 MaxDelay，开启 Jitter 时取 [0, d]
+func (p retryPolicy) nextDelay(attempt int) time.Duration {
+	delay := float64(p.BaseDelay)
+	for i := 0; i < attempt; i++ {
+		delay *= p.Factor
+		if time.Duration(delay) >= p.MaxDelay {
+			delay = float64(p.MaxDelay)
+			break
+		}
+	}
+
+	d := time.Duration(delay)
+	if d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if p.Jitter {
+		// full jitter: [0, d]
+		if d > 0 {
+			d = time.Duration(rand.Int63n(int64(d) + 1))
+		}
+	}
+	return d
+}
+
 func (m *Manager) scheduleRetry(ctx context.Context, job *model.NotificationJob, channel *model.NotificationChannel, errMsg string) {
 	policy := parseRetryPolicy(channel.Config)
 	nextAttempt := job.RetryCount + 1
@@ -280,26 +312,7 @@ func (m *Manager) scheduleRetry(ctx context.Context, job *model.NotificationJob,
 		})
 
 	// 计算 next_run_at（指数退避）
-	delay := float64(policy.BaseDelay)
-	for i := 0; i < nextAttempt; i++ {
-		delay *= policy.Factor
-		if time.Duration(delay) >= policy.MaxDelay {
-			delay = float64(policy.MaxDelay)
-			break
-		}
-	}
-
-	d := time.Duration(delay)
-	if d > policy.MaxDelay {
-		d = policy.MaxDelay
-	}
-	if policy.Jitter {
-		// full jitter: [0, d]
-		if d > 0 {
-			d = time.Duration(rand.Int63n(int64(d) + 1))
-		}
-	}
-
+	d := policy.nextDelay(nextAttempt)
 	nextRunAt := time.Now().Add(d)
 
 	m.db.WithContext(ctx).Model(&model.NotificationJob{}).
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+
+	"logflux/internal/svc"
 )
 
 func userIDFromContext(ctx context.Context) (uint, error) {
@@ -52,6 +54,19 @@ func userIDFromContext(ctx context.Context) (uint, error) {
 	}
 }
 
+// CurrentUsername 返回当前登录用户名，未登录时为 system，用户已不存在时退回用户 ID
+func CurrentUsername(ctx context.Context, svcCtx *svc.ServiceContext) string {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return "system"
+	}
+	user, err := svcCtx.UserModel.FindByID(ctx, userID)
+	if err != nil {
+		return fmt.Sprintf("%d", userID)
+	}
+	return user.Username
+}
+
 func hasRole(roles []string, target string) bool {
 	for _, role := range roles {
 		if strings.TrimSpace(role) == target {
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -78,9 +79,55 @@ func (s *LogService) GetCaddyLogs(req *types.CaddyLogReq) (*types.CaddyLogResp,
 			RawLog:    logItem.RawLog,
 		})
 	}
+	s.attachWafAudit(list)
 	return &types.CaddyLogResp{List: list, Total: total}, nil
 }
 
+// attachWafAudit 为已关联 WAF 审计事件的访问日志补充命中规则信息
+func (s *LogService) attachWafAudit(list []types.CaddyLogItem) {
+	if len(list) == 0 || s.svcCtx.DB == nil {
+		return
+	}
+	ids := make([]uint, 0, len(list))
+	for _, item := range list {
+		ids = append(ids, item.ID)
+	}
+
+	var audits []model.WafAuditLog
+	if err := s.svcCtx.DB.WithContext(s.ctx).
+		Select("caddy_log_id", "action", "rule_ids", "messages", "inbound_anomaly_score", "policy_name").
+		Where("caddy_log_id IN ?", ids).
+		Find(&audits).Error; err != nil {
+		s.Errorf("查询 WAF 审计关联失败: %v", err)
+		return
+	}
+
+	byLogID := make(map[uint]*model.WafAuditLog, len(audits))
+	for idx := range audits {
+		if audits[idx].CaddyLogID != nil {
+			byLogID[*audits[idx].CaddyLogID] = &audits[idx]
+		}
+	}
+	for idx := range list {
+		audit, ok := byLogID[list[idx].ID]
+		if !ok {
+			continue
+		}
+		var messages []model.WafAuditMessage
+		_ = json.Unmarshal([]byte(audit.Messages), &messages)
+		texts := make([]string, 0, len(messages))
+		for _, msg := range messages {
+			texts = append(texts, fmt.Sprintf("%d: %s", msg.RuleID, msg.Message))
+		}
+
+		list[idx].WafAction = audit.Action
+		list[idx].WafRuleIDs = []int64(audit.RuleIDs)
+		list[idx].WafMessages = texts
+		list[idx].WafAnomalyScore = audit.InboundAnomalyScore
+		list[idx].WafPolicy = audit.PolicyName
+	}
+}
+
 func (s *LogService) GetSystemLogs(req *types.SystemLogReq) (*types.SystemLogResp, error) {
 	startTime, err := utils.ParseOptionalTime(req.StartTime)
 	if err != nil {
@@ -148,7 +148,7 @@ func (s *LogSourceService) Update(req *types.LogSourceUpdateReq) (*types.BaseRes
 		s.svcCtx.Ingestor.Stop(oldPath, source.Type)
 	}
 	if source.Enabled && source.Path != "" {
-		s.svcCtx.Ingestor.StartWithInterval(source.Path, source.ScanInterval, source.Type)
+		s.svcCtx.Ingestor.StartSource(*source)
 	}
 	return baseResp("更新成功"), nil
 }
@@ -33,7 +33,9 @@ type ServiceContext struct {
 	Ingestor        *ingest.IngestManager
 	ArchiveTask     *tasks.ArchiveTask
 	CronScheduler   *tasks.CronScheduler
+	Jobs            *tasks.JobRegistry
 	WafScheduler    *tasks.WafScheduler
+	ReportScheduler *tasks.ReportScheduler
 	NotificationMgr notification.NotificationManager
 	Permission      rest.Middleware
 	UserModel       model.UserModel
@@ -60,12 +62,23 @@ func NewServiceContext(c config.Config) *ServiceContext {
 		&model.Menu{},
 		&model.CaddyServer{},
 		&model.CaddyConfigHistory{},
+		&model.CaddyApplyVerifySetting{},
+		&model.CaddyHealthProbe{},
+		&model.CaddyCertificate{},
+		&model.CaddyChangeRequest{},
+		&model.CaddyChangeRequestComment{},
 		// 通知相关表
 		&model.NotificationChannel{},
 		&model.NotificationRule{},
 		&model.NotificationLog{},
 		&model.NotificationJob{},
 		&model.NotificationTemplate{},
+		&model.NotificationEscalationPolicy{},
+		&model.NotificationOnCallSchedule{},
+		&model.NotificationUserContact{},
+		&model.NotificationEscalation{},
+		&model.NotificationReport{},
+		&model.NotificationInboundToken{},
 		// 定时任务表
 		&model.CronTask{},
 		&model.CronTaskLog{},
@@ -76,8 +89,20 @@ func NewServiceContext(c config.Config) *ServiceContext {
 		&model.WafPolicy{},
 		&model.WafPolicyRevision{},
 		&model.WafRuleExclusion{},
+		&model.WafCustomRule{},
 		&model.WafPolicyBinding{},
 		&model.WafPolicyFalsePositiveFeedback{},
+		&model.WafAuditLog{},
+		&model.WafPolicyRollout{},
+		&model.WafIPList{},
+		&model.WafIPListEntry{},
+		&model.WafBanRule{},
+		&model.WafBan{},
+		&model.WafRateLimitZone{},
+		&model.WafMirrorClient{},
+		&model.CaddyServerGroup{},
+		&model.WafServerDeployment{},
+		&model.CaddyConfigDrift{},
 	)
 
 	initWafWorkspace(&c)
@@ -154,6 +179,22 @@ func NewServiceContext(c config.Config) *ServiceContext {
 		}
 	}
 
+	wafAuditLogPath := strings.TrimSpace(c.WafAuditLogPath)
+	if wafAuditLogPath != "" {
+		var cnt int64
+		db.Model(&model.LogSource{}).Where("path = ?", wafAuditLogPath).Count(&cnt)
+		if cnt == 0 {
+			db.Create(&model.LogSource{
+				Name:         "WAF Audit",
+				Path:         wafAuditLogPath,
+				Type:         "waf_audit",
+				Enabled:      true,
+				ScanInterval: ingest.DefaultScanIntervalSec(),
+			})
+			ingestor.StartWithInterval(wafAuditLogPath, ingest.DefaultScanIntervalSec(), "waf_audit")
+		}
+	}
+
 	// 初始化通知管理器（仅依赖数据库配置）
 	notificationMgr := initNotificationManager(db, rdb)
 
@@ -172,6 +213,12 @@ func NewServiceContext(c config.Config) *ServiceContext {
 	// 初始化 WAF 更新调度器（执行器在 main 中注入）
 	wafScheduler := tasks.NewWafScheduler(db)
 
+	// 共享的定时任务注册表，报表等调度器在其上登记条目（在 main 中启动）
+	jobs := tasks.NewJobRegistry()
+
+	// 初始化定时报表调度器（执行器在 main 中注入）
+	reportScheduler := tasks.NewReportScheduler(db, jobs)
+
 	return &ServiceContext{
 		Config:          c,
 		DB:              db,
@@ -179,7 +226,9 @@ func NewServiceContext(c config.Config) *ServiceContext {
 		Ingestor:        ingestor,
 		ArchiveTask:     archiveTask,
 		CronScheduler:   cronScheduler,
+		Jobs:            jobs,
 		WafScheduler:    wafScheduler,
+		ReportScheduler: reportScheduler,
 		NotificationMgr: notificationMgr,
 		Permission:      middleware.NewPermissionMiddleware(db).Handle,
 		UserModel:       model.NewUserModel(db),
@@ -396,6 +445,12 @@ func initRBACData(db *gorm2.DB) {
 			Description: "数据分析师，可访问系统日志（logs）与 Caddy 访问日志（logs_caddy）",
 			Permissions: []string{"dashboard", "logs", "logs_caddy"},
 		},
+		{
+			Name:        "approver",
+			DisplayName: "变更审批人",
+			Description: "审批 Caddy 配置与 WAF 策略变更申请，可查看 Caddy 配置",
+			Permissions: []string{"dashboard"},
+		},
 		{
 			Name:        "viewer",
 			DisplayName: "访客",
@@ -429,7 +484,7 @@ func initRBACData(db *gorm2.DB) {
 			Component:     "layout.base",
 			Order:         2,
 			Meta:          `{"title":"caddy","i18nKey":"route.caddy","icon":"carbon:cloud-monitoring","order":2}`,
-			RequiredRoles: []string{"admin", "analyst"},
+			RequiredRoles: []string{"admin", "analyst", "approver"},
 		},
 		{
 			Name:          "security",
@@ -461,7 +516,7 @@ func initRBACData(db *gorm2.DB) {
 			Path:          "/caddy/config",
 			Component:     "view.caddy_config",
 			Meta:          `{"title":"caddy_config","i18nKey":"route.caddy_config","icon":"carbon:settings"}`,
-			RequiredRoles: []string{"admin", "analyst"},
+			RequiredRoles: []string{"admin", "analyst", "approver"},
 		},
 		{
 			Name:          "caddy_log",
@@ -676,6 +731,11 @@ func initNotificationManager(db *gorm2.DB, rdb *redis.Client) notification.Notif
 	_ = mgr.RegisterProvider(providers.NewWeComProvider())
 	_ = mgr.RegisterProvider(providers.NewWeChatMPProvider())
 	_ = mgr.RegisterProvider(providers.NewDiscordProvider())
+	_ = mgr.RegisterProvider(providers.NewDingTalkProvider())
+	_ = mgr.RegisterProvider(providers.NewFeishuProvider())
+	_ = mgr.RegisterProvider(providers.NewPagerDutyProvider())
+	_ = mgr.RegisterProvider(providers.NewNtfyProvider())
+	_ = mgr.RegisterProvider(providers.NewGotifyProvider())
 	_ = mgr.RegisterProvider(providers.NewInAppProvider())
 
 	// 启动通知管理器（渠道/规则均从数据库加载）
@@ -0,0 +1,124 @@
+package tasks
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/robfig/cron/v3"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// JobRegistry 按名称管理 cron 条目。各业务调度器共享同一个 cron 实例，
+// 条目名按 "<类别>:<ID>" 或内置任务名区分，不再各自维护 cron 生命周期与条目表。
+type JobRegistry struct {
+	cron *cron.Cron
+
+	mu      sync.Mutex
+	entries map[string]cron.EntryID
+	started bool
+}
+
+func NewJobRegistry() *JobRegistry {
+	return &JobRegistry{
+		cron:    cron.New(cron.WithSeconds()),
+		entries: make(map[string]cron.EntryID),
+	}
+}
+
+func (registry *JobRegistry) Start() {
+	if registry == nil {
+		return
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if registry.started {
+		return
+	}
+	registry.started = true
+	registry.cron.Start()
+	logx.Info("定时任务注册表已启动")
+}
+
+func (registry *JobRegistry) Stop() {
+	if registry == nil {
+		return
+	}
+
+	registry.mu.Lock()
+	if !registry.started {
+		registry.mu.Unlock()
+		return
+	}
+	registry.started = false
+	registry.mu.Unlock()
+
+	<-registry.cron.Stop().Done()
+	logx.Info("定时任务注册表已停止")
+}
+
+// Set 新增或替换名为 name 的条目
+func (registry *JobRegistry) Set(name, spec string, job func()) error {
+	if registry == nil {
+		return nil
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if entryID, ok := registry.entries[name]; ok {
+		registry.cron.Remove(entryID)
+		delete(registry.entries, name)
+	}
+	entryID, err := registry.cron.AddFunc(strings.TrimSpace(spec), job)
+	if err != nil {
+		return err
+	}
+	registry.entries[name] = entryID
+	return nil
+}
+
+func (registry *JobRegistry) Remove(name string) {
+	if registry == nil {
+		return
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if entryID, ok := registry.entries[name]; ok {
+		registry.cron.Remove(entryID)
+		delete(registry.entries, name)
+	}
+}
+
+// RemovePrefix 移除名称以 prefix 开头的全部条目，用于调度器整体重载
+func (registry *JobRegistry) RemovePrefix(prefix string) {
+	if registry == nil {
+		return
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	for name, entryID := range registry.entries {
+		if strings.HasPrefix(name, prefix) {
+			registry.cron.Remove(entryID)
+			delete(registry.entries, name)
+		}
+	}
+}
+
+// Names 返回已注册的条目名（升序）
+func (registry *JobRegistry) Names() []string {
+	if registry == nil {
+		return nil
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	names := make([]string, 0, len(registry.entries))
+	for name := range registry.entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
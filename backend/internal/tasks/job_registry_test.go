@@ -0,0 +1,50 @@
+package tasks
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestJobRegistrySetRemove(t *testing.T) {
+	registry := NewJobRegistry()
+	noop := func() {}
+
+	for _, name := range []string{"report:1", "report:2", "waf_source:1"} {
+		if err := registry.Set(name, "0 0 * * * *", noop); err != nil {
+			t.Fatalf("Set(%s) error = %v", name, err)
+		}
+	}
+	if err := registry.Set("report:1", "0 30 * * * *", noop); err != nil {
+		t.Fatalf("replace report:1 error = %v", err)
+	}
+	if err := registry.Set("report:3", "bad spec", noop); err == nil {
+		t.Fatal("expected invalid spec to fail")
+	}
+	if got := len(registry.cron.Entries()); got != 3 {
+		t.Fatalf("expected 3 cron entries after replace, got %d", got)
+	}
+
+	registry.RemovePrefix("report:")
+	if got, want := registry.Names(), []string{"waf_source:1"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Names() = %v, want %v", got, want)
+	}
+	registry.Remove("waf_source:1")
+	if got := len(registry.cron.Entries()); got != 0 {
+		t.Fatalf("expected no cron entries, got %d", got)
+	}
+}
+
+func TestRegisterMaintenanceJobs(t *testing.T) {
+	registry := NewJobRegistry()
+	run := func(context.Context) error { return nil }
+
+	RegisterMaintenanceJobs(registry, []MaintenanceJob{
+		{Name: "waf_ban_evaluate", Title: "执行自动封禁", Spec: "30 * * * * *", Run: run},
+		{Name: "caddy_drift_check", Title: "检测 Caddy 配置漂移", Spec: "bad spec", Run: run},
+		{Name: "noop", Title: "空任务", Spec: "0 * * * * *"},
+	})
+	if got, want := registry.Names(), []string{"maintenance:waf_ban_evaluate"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Names() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,39 @@
+package tasks
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const maintenanceJobPrefix = "maintenance:"
+
+// MaintenanceJob 固定周期执行的内置维护任务（灰度评估、自动封禁、漂移检测等），
+// 在 JobRegistry 上以 "maintenance:<Name>" 登记。
+type MaintenanceJob struct {
+	Name  string
+	Title string
+	Spec  string
+	Run   func(ctx context.Context) error
+}
+
+// RegisterMaintenanceJobs 登记内置维护任务，同名任务会被替换
+func RegisterMaintenanceJobs(jobs *JobRegistry, items []MaintenanceJob) {
+	for _, item := range items {
+		job := item
+		if job.Run == nil {
+			continue
+		}
+		if err := jobs.Set(maintenanceJobPrefix+job.Name, job.Spec, func() {
+			runMaintenanceJob(job)
+		}); err != nil {
+			logx.Errorf("添加维护任务失败: name=%s title=%s err=%v", job.Name, job.Title, err)
+		}
+	}
+}
+
+func runMaintenanceJob(job MaintenanceJob) {
+	if err := job.Run(context.Background()); err != nil {
+		logx.Errorf("定时%s失败: %v", job.Title, err)
+	}
+}
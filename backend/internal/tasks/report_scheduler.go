@@ -0,0 +1,136 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"logflux/internal/utils/safego"
+	"logflux/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+const reportJobPrefix = "report:"
+
+// ReportJobExecutor 定义定时报表执行器。
+type ReportJobExecutor interface {
+	RunReport(ctx context.Context, reportID uint) error
+}
+
+// ReportScheduler 负责按 notification_reports 的周期在共享的 JobRegistry 上登记报表任务。
+type ReportScheduler struct {
+	db   *gorm.DB
+	jobs *JobRegistry
+
+	mu       sync.RWMutex
+	executor ReportJobExecutor
+}
+
+func NewReportScheduler(db *gorm.DB, jobs *JobRegistry) *ReportScheduler {
+	return &ReportScheduler{
+		db:   db,
+		jobs: jobs,
+	}
+}
+
+func (scheduler *ReportScheduler) SetExecutor(executor ReportJobExecutor) {
+	if scheduler == nil {
+		return
+	}
+
+	scheduler.mu.Lock()
+	scheduler.executor = executor
+	scheduler.mu.Unlock()
+}
+
+func (scheduler *ReportScheduler) Reload() error {
+	if scheduler == nil {
+		return nil
+	}
+	if scheduler.db == nil {
+		return fmt.Errorf("报表调度器数据库为空")
+	}
+
+	var reports []model.NotificationReport
+	if err := scheduler.db.Where("enabled = ?", true).Order("id asc").Find(&reports).Error; err != nil {
+		return fmt.Errorf("查询待调度报表失败: %w", err)
+	}
+
+	scheduler.jobs.RemovePrefix(reportJobPrefix)
+	for i := range reports {
+		report := reports[i]
+		if err := scheduler.addOrUpdateReportEntry(&report); err != nil {
+			logx.Errorf("添加定时报表失败: id=%d name=%s err=%v", report.ID, report.Name, err)
+		}
+	}
+	return nil
+}
+
+func (scheduler *ReportScheduler) ReloadReport(reportID uint) error {
+	if scheduler == nil || reportID == 0 {
+		return nil
+	}
+	if scheduler.db == nil {
+		return fmt.Errorf("报表调度器数据库为空")
+	}
+
+	var report model.NotificationReport
+	if err := scheduler.db.First(&report, reportID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			scheduler.RemoveReport(reportID)
+			return nil
+		}
+		return fmt.Errorf("查询报表失败: %w", err)
+	}
+
+	if !report.Enabled {
+		scheduler.RemoveReport(reportID)
+		return nil
+	}
+	return scheduler.addOrUpdateReportEntry(&report)
+}
+
+func (scheduler *ReportScheduler) RemoveReport(reportID uint) {
+	if scheduler == nil || reportID == 0 {
+		return
+	}
+	scheduler.jobs.Remove(reportJobName(reportID))
+}
+
+func (scheduler *ReportScheduler) addOrUpdateReportEntry(report *model.NotificationReport) error {
+	if scheduler == nil || report == nil {
+		return nil
+	}
+
+	reportID := report.ID
+	return scheduler.jobs.Set(reportJobName(reportID), report.CronSchedule(), func() {
+		scheduler.executeReport(reportID)
+	})
+}
+
+func (scheduler *ReportScheduler) executeReport(reportID uint) {
+	if scheduler == nil || reportID == 0 {
+		return
+	}
+
+	scheduler.mu.RLock()
+	executor := scheduler.executor
+	scheduler.mu.RUnlock()
+	if executor == nil {
+		logx.Errorf("跳过定时报表执行，执行器为空: reportID=%d", reportID)
+		return
+	}
+
+	safego.New(context.Background(), "定时报表任务").Go(func() {
+		if err := executor.RunReport(context.Background(), reportID); err != nil {
+			logx.Errorf("定时报表执行失败: reportID=%d err=%v", reportID, err)
+		}
+	})
+}
+
+func reportJobName(reportID uint) string {
+	return fmt.Sprintf("%s%d", reportJobPrefix, reportID)
+}
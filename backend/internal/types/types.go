@@ -18,33 +18,272 @@ type BatchDeleteNotificationLogsReq struct {
 	IDs []uint `json:"ids"`
 }
 
+type CaddyApplyVerifyResultResp struct {
+	Passed       bool                     `json:"passed"`
+	Message      string                   `json:"message"`
+	Probes       []CaddyHealthProbeResult `json:"probes"`
+	ErrorChecked bool                     `json:"errorChecked"` // 是否完成错误率判定（样本不足时为 false）
+	Requests     int64                    `json:"requests"`
+	Errors       int64                    `json:"errors"`
+	ErrorRate    float64                  `json:"errorRate"`
+}
+
+type CaddyApplyVerifySettingReq struct {
+	ServerId           uint                   `path:"serverId"`
+	Enabled            bool                   `json:"enabled"`
+	ErrorWindowSeconds int                    `json:"errorWindowSeconds,optional"`
+	MaxErrorRate       float64                `json:"maxErrorRate,optional"`
+	MinRequests        int                    `json:"minRequests,optional"`
+	Probes             []CaddyHealthProbeItem `json:"probes,optional"`
+}
+
+type CaddyApplyVerifySettingResp struct {
+	Enabled            bool                   `json:"enabled"`
+	ErrorWindowSeconds int                    `json:"errorWindowSeconds"`
+	MaxErrorRate       float64                `json:"maxErrorRate"`
+	MinRequests        int                    `json:"minRequests"`
+	Probes             []CaddyHealthProbeItem `json:"probes"`
+}
+
+type CaddyCertificateCheckReq struct {
+	ServerId uint `path:"serverId"`
+	Probe    bool `json:"probe,optional"` // 对配置中的站点做 TLS 握手探测
+}
+
+type CaddyCertificateItem struct {
+	ID             uint     `json:"id"`
+	ServerId       uint     `json:"serverId"`
+	ServerName     string   `json:"serverName"`
+	Host           string   `json:"host"`
+	Source         string   `json:"source"` // site | pki
+	Subject        string   `json:"subject"`
+	Issuer         string   `json:"issuer"`
+	Sans           []string `json:"sans"`
+	SerialNumber   string   `json:"serialNumber"`
+	Fingerprint    string   `json:"fingerprint"`
+	NotBefore      string   `json:"notBefore"`
+	NotAfter       string   `json:"notAfter"`
+	DaysLeft       int      `json:"daysLeft"` // 剩余天数，NotAfter 为空时为 0
+	Status         string   `json:"status"`
+	VerifyError    string   `json:"verifyError"`
+	ProbeError     string   `json:"probeError"`
+	LastProbedAt   string   `json:"lastProbedAt"`
+	RenewalError   string   `json:"renewalError"`
+	RenewalErrorAt string   `json:"renewalErrorAt"`
+	UpdatedAt      string   `json:"updatedAt"`
+}
+
+type CaddyCertificateListReq struct {
+	Page               int    `form:"page,default=1"`
+	PageSize           int    `form:"pageSize,default=20"`
+	ServerId           uint   `form:"serverId,optional"`
+	Status             string `form:"status,optional"`             // unknown | valid | expiring | expired | error
+	Keyword            string `form:"keyword,optional"`            // 按域名模糊匹配
+	ExpiringWithinDays int    `form:"expiringWithinDays,optional"` // 仅返回指定天数内到期的证书
+}
+
+type CaddyCertificateListResp struct {
+	List  []CaddyCertificateItem `json:"list"`
+	Total int64                  `json:"total"`
+}
+
+type CaddyChangeRequestActionReq struct {
+	ID          uint   `path:"id"`
+	Comment     string `json:"comment,optional"`
+	ScheduledAt string `json:"scheduledAt,optional"` // 仅批准时有效，覆盖申请中的期望应用时间
+}
+
+type CaddyChangeRequestCommentItem struct {
+	ID         uint   `json:"id"`
+	Action     string `json:"action"` // comment | submit | approve | reject | cancel | apply | fail
+	Author     string `json:"author"`
+	AuthorName string `json:"authorName"`
+	Content    string `json:"content"`
+	CreatedAt  string `json:"createdAt"`
+}
+
+type CaddyChangeRequestDetailResp struct {
+	Request  CaddyChangeRequestItem          `json:"request"`
+	Config   string                          `json:"config"`
+	Modules  string                          `json:"modules"`
+	Diff     string                          `json:"diff"`
+	Stale    bool                            `json:"stale"` // 节点配置在提交后已被修改，应用时会失败
+	Comments []CaddyChangeRequestCommentItem `json:"comments"`
+}
+
+type CaddyChangeRequestItem struct {
+	ID           uint   `json:"id"`
+	Kind         string `json:"kind"`
+	ServerId     uint   `json:"serverId"`
+	ServerName   string `json:"serverName"`
+	PolicyId     uint   `json:"policyId"`
+	PolicyName   string `json:"policyName"`
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+	Status       string `json:"status"` // draft | pending | rejected | cancelled | scheduled | applying | applied | failed
+	Added        int    `json:"added"`
+	Removed      int    `json:"removed"`
+	Author       string `json:"author"`
+	AuthorName   string `json:"authorName"`
+	Reviewer     string `json:"reviewer"`
+	ReviewerName string `json:"reviewerName"`
+	SubmittedAt  string `json:"submittedAt"`
+	ReviewedAt   string `json:"reviewedAt"`
+	ScheduledAt  string `json:"scheduledAt"`
+	AppliedAt    string `json:"appliedAt"`
+	HistoryId    uint   `json:"historyId"`
+	RevisionId   uint   `json:"revisionId"`
+	Message      string `json:"message"`
+	CreatedAt    string `json:"createdAt"`
+	UpdatedAt    string `json:"updatedAt"`
+}
+
+type CaddyChangeRequestListReq struct {
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"pageSize,default=20"`
+	Status   string `form:"status,optional"`
+	Kind     string `form:"kind,optional"`
+	ServerId uint   `form:"serverId,optional"`
+}
+
+type CaddyChangeRequestListResp struct {
+	List  []CaddyChangeRequestItem `json:"list"`
+	Total int64                    `json:"total"`
+}
+
+type CaddyChangeRequestReq struct {
+	Kind        string `json:"kind"`              // caddy_config | waf_policy
+	ServerId    uint   `json:"serverId,optional"` // caddy_config 必填
+	PolicyId    uint   `json:"policyId,optional"` // waf_policy 必填，发布到主节点
+	Title       string `json:"title"`
+	Description string `json:"description,optional"`
+	Config      string `json:"config,optional"` // caddy_config 提议的 Caddyfile
+	Modules     string `json:"modules,optional"`
+	ScheduledAt string `json:"scheduledAt,optional"` // 期望应用时间，为空表示批准后立即应用
+	Submit      bool   `json:"submit,optional"`      // true 直接提交审批，否则保存为草稿
+}
+
+type CaddyChangeRequestUpdateReq struct {
+	ID          uint   `path:"id"`
+	ServerId    uint   `json:"serverId,optional"`
+	PolicyId    uint   `json:"policyId,optional"`
+	Title       string `json:"title"`
+	Description string `json:"description,optional"`
+	Config      string `json:"config,optional"`
+	Modules     string `json:"modules,optional"`
+	ScheduledAt string `json:"scheduledAt,optional"`
+	Submit      bool   `json:"submit,optional"`
+}
+
+type CaddyConfigDiffReq struct {
+	ServerId uint   `path:"serverId"`
+	FromId   uint   `form:"fromId"`
+	ToId     uint   `form:"toId,optional"`            // 为 0 时与当前保存的配置比较
+	Format   string `form:"format,default=caddyfile"` // caddyfile | json（经 /adapt 转换后的 JSON）
+}
+
+type CaddyConfigDiffResp struct {
+	Format    string `json:"format"`
+	FromId    uint   `json:"fromId"`
+	ToId      uint   `json:"toId"`
+	FromLabel string `json:"fromLabel"`
+	ToLabel   string `json:"toLabel"`
+	FromHash  string `json:"fromHash"`
+	ToHash    string `json:"toHash"`
+	Diff      string `json:"diff"` // unified diff
+	Added     int    `json:"added"`
+	Removed   int    `json:"removed"`
+	Identical bool   `json:"identical"`
+}
+
+type CaddyConfigDriftCheckResp struct {
+	Drifted bool                  `json:"drifted"`
+	Drift   *CaddyConfigDriftItem `json:"drift,optional"`
+}
+
+type CaddyConfigDriftItem struct {
+	ID          uint   `json:"id"`
+	ServerId    uint   `json:"serverId"`
+	ServerName  string `json:"serverName"`
+	Status      string `json:"status"` // open | adopted | reapplied | resolved
+	Summary     string `json:"summary"`
+	Diff        string `json:"diff"` // JSON 数组：[{path, op, before, after}]
+	StoredHash  string `json:"storedHash"`
+	RunningHash string `json:"runningHash"`
+	Message     string `json:"message"`
+	Adoptable   bool   `json:"adoptable"` // 节点 Caddyfile 与运行配置一致，可采用为保存配置；否则采用时保存为运行配置快照
+	LastSeenAt  string `json:"lastSeenAt"`
+	ResolvedAt  string `json:"resolvedAt"`
+	ResolvedBy  string `json:"resolvedBy"`
+	CreatedAt   string `json:"createdAt"`
+}
+
+type CaddyConfigDriftListReq struct {
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"pageSize,default=20"`
+	ServerId uint   `form:"serverId,optional"`
+	Status   string `form:"status,optional"`
+}
+
+type CaddyConfigDriftListResp struct {
+	List  []CaddyConfigDriftItem `json:"list"`
+	Total int64                  `json:"total"`
+}
+
+type CaddyConfigHistoryAnnotateReq struct {
+	ServerId  uint   `path:"serverId"`
+	HistoryId uint   `path:"historyId"`
+	Message   string `json:"message,optional"`
+	KnownGood bool   `json:"knownGood,optional"`
+}
+
 type CaddyConfigHistoryDetailReq struct {
 	ServerId  uint `path:"serverId"`
 	HistoryId uint `path:"historyId"`
 }
 
 type CaddyConfigHistoryDetailResp struct {
-	ID        uint   `json:"id"`
-	ServerId  uint   `json:"serverId"`
-	Action    string `json:"action"`
-	Hash      string `json:"hash"`
-	Config    string `json:"config"`
-	Modules   string `json:"modules,optional"`
-	CreatedAt string `json:"createdAt"`
+	ID            uint   `json:"id"`
+	ServerId      uint   `json:"serverId"`
+	Action        string `json:"action"`
+	Hash          string `json:"hash"`
+	Config        string `json:"config"`
+	Modules       string `json:"modules,optional"`
+	Source        string `json:"source"`
+	Operator      string `json:"operator"`
+	Message       string `json:"message"`
+	KnownGood     bool   `json:"knownGood"`
+	TaggedBy      string `json:"taggedBy"`
+	TaggedAt      string `json:"taggedAt"`
+	CreatedAt     string `json:"createdAt"`
+	VerifyStatus  string `json:"verifyStatus"` // 加载后健康校验：空表示未启用，pending | passed | failed
+	VerifyMessage string `json:"verifyMessage"`
+	VerifiedAt    string `json:"verifiedAt"`
 }
 
 type CaddyConfigHistoryItem struct {
-	ID        uint   `json:"id"`
-	ServerId  uint   `json:"serverId"`
-	Action    string `json:"action"`
-	Hash      string `json:"hash"`
-	CreatedAt string `json:"createdAt"`
+	ID            uint   `json:"id"`
+	ServerId      uint   `json:"serverId"`
+	Action        string `json:"action"`
+	Hash          string `json:"hash"`
+	Source        string `json:"source"` // ui | waf_publish | rollback | drift_adopt | drift_reapply | change_request | system
+	Operator      string `json:"operator"`
+	Message       string `json:"message"`
+	KnownGood     bool   `json:"knownGood"`
+	TaggedBy      string `json:"taggedBy"`
+	TaggedAt      string `json:"taggedAt"`
+	CreatedAt     string `json:"createdAt"`
+	VerifyStatus  string `json:"verifyStatus"` // 加载后健康校验：空表示未启用，pending | passed | failed
+	VerifyMessage string `json:"verifyMessage"`
+	VerifiedAt    string `json:"verifiedAt"`
 }
 
 type CaddyConfigHistoryListReq struct {
-	ServerId uint `path:"serverId"`
-	Page     int  `form:"page,default=1"`
-	PageSize int  `form:"pageSize,default=20"`
+	ServerId  uint   `path:"serverId"`
+	Page      int    `form:"page,default=1"`
+	PageSize  int    `form:"pageSize,default=20"`
+	Source    string `form:"source,optional"`
+	KnownGood bool   `form:"knownGood,optional"` // 仅返回已知良好版本
 }
 
 type CaddyConfigHistoryListResp struct {
@@ -62,14 +301,37 @@ type CaddyConfigResp struct {
 }
 
 type CaddyConfigRollbackReq struct {
-	ServerId  uint `path:"serverId"`
-	HistoryId uint `json:"historyId"`
+	ServerId  uint   `path:"serverId"`
+	HistoryId uint   `json:"historyId,optional"` // 为 0 时回滚到最近的已知良好版本
+	Message   string `json:"message,optional"`
 }
 
 type CaddyConfigUpdateReq struct {
 	ServerId uint   `path:"serverId"`
 	Config   string `json:"config"`           // JSON string
 	Modules  string `json:"modules,optional"` // structured modules (JSON)
+	Message  string `json:"message,optional"` // 变更说明
+}
+
+type CaddyHealthProbeItem struct {
+	ID             uint   `json:"id,optional"`
+	Name           string `json:"name"`
+	Url            string `json:"url"`
+	Method         string `json:"method,optional"`
+	Host           string `json:"host,optional"`
+	ExpectStatus   int    `json:"expectStatus,optional"` // 0 表示任意 2xx/3xx
+	BodyPattern    string `json:"bodyPattern,optional"`  // 响应体需匹配的正则
+	TimeoutSeconds int    `json:"timeoutSeconds,optional"`
+	Enabled        bool   `json:"enabled,optional"`
+}
+
+type CaddyHealthProbeResult struct {
+	Name       string `json:"name"`
+	Url        string `json:"url"`
+	Status     int    `json:"status"`
+	DurationMs int64  `json:"durationMs"`
+	Passed     bool   `json:"passed"`
+	Message    string `json:"message"`
 }
 
 type CaddyLogItem struct {
@@ -88,6 +350,12 @@ type CaddyLogItem struct {
 	ClientIP  string `json:"clientIp"`
 	UserAgent string `json:"userAgent"`
 	RawLog    string `json:"rawLog"`
+	// WAF 审计关联信息（仅命中 WAF 规则的请求）
+	WafAction       string   `json:"wafAction,omitempty"` // blocked | detected
+	WafRuleIDs      []int64  `json:"wafRuleIds,omitempty"`
+	WafMessages     []string `json:"wafMessages,omitempty"`
+	WafAnomalyScore int      `json:"wafAnomalyScore,omitempty"`
+	WafPolicy       string   `json:"wafPolicy,omitempty"`
 }
 
 type CaddyLogReq struct {
@@ -107,12 +375,126 @@ type CaddyLogResp struct {
 	Total int64          `json:"total"`
 }
 
+type CaddyRouteDeleteReq struct {
+	ServerId   uint   `path:"serverId"`
+	SiteIndex  int    `path:"siteIndex"`
+	RouteIndex int    `path:"routeIndex"`
+	ConfigHash string `form:"configHash"`
+	DryRun     bool   `form:"dryRun,optional"`
+}
+
+type CaddyRouteFileServer struct {
+	Matcher string `json:"matcher,optional"`
+	Root    string `json:"root,optional"`
+	Browse  bool   `json:"browse,optional"`
+}
+
+type CaddyRouteHeader struct {
+	Matcher string               `json:"matcher,optional"`
+	Ops     []CaddyRouteHeaderOp `json:"ops"`
+}
+
+type CaddyRouteHeaderOp struct {
+	Op      string `json:"op"` // set | add | delete | default | replace
+	Field   string `json:"field"`
+	Value   string `json:"value,optional"`
+	Replace string `json:"replace,optional"`
+}
+
+type CaddyRouteItem struct {
+	Index        int                     `json:"index,optional"`
+	Kind         string                  `json:"kind"` // matcher | reverse_proxy | header | redir | file_server | tls | raw
+	Raw          string                  `json:"raw,optional"`
+	Matcher      *CaddyRouteMatcher      `json:"matcher,optional"`
+	ReverseProxy *CaddyRouteReverseProxy `json:"reverseProxy,optional"`
+	Header       *CaddyRouteHeader       `json:"header,optional"`
+	Redirect     *CaddyRouteRedirect     `json:"redirect,optional"`
+	FileServer   *CaddyRouteFileServer   `json:"fileServer,optional"`
+	Tls          *CaddyRouteTls          `json:"tls,optional"`
+}
+
+type CaddyRouteMatcher struct {
+	Name       string                       `json:"name"`
+	Conditions []CaddyRouteMatcherCondition `json:"conditions"`
+}
+
+type CaddyRouteMatcherCondition struct {
+	Type   string   `json:"type"`
+	Values []string `json:"values"`
+}
+
+type CaddyRouteRedirect struct {
+	Matcher string `json:"matcher,optional"`
+	To      string `json:"to"`
+	Code    string `json:"code,optional"`
+}
+
+type CaddyRouteReq struct {
+	ServerId   uint           `path:"serverId"`
+	SiteIndex  int            `path:"siteIndex"`
+	ConfigHash string         `json:"configHash"`
+	DryRun     bool           `json:"dryRun,optional"`
+	Index      int            `json:"index,default=-1"` // 插入位置，-1 表示追加
+	Route      CaddyRouteItem `json:"route"`
+}
+
+type CaddyRouteReverseProxy struct {
+	Matcher   string   `json:"matcher,optional"`
+	Upstreams []string `json:"upstreams"`
+	LbPolicy  string   `json:"lbPolicy,optional"`
+	HealthUri string   `json:"healthUri,optional"`
+}
+
+type CaddyRouteTls struct {
+	Mode     string `json:"mode"` // internal | email | files
+	Email    string `json:"email,optional"`
+	CertFile string `json:"certFile,optional"`
+	KeyFile  string `json:"keyFile,optional"`
+}
+
+type CaddyRouteUpdateReq struct {
+	ServerId   uint           `path:"serverId"`
+	SiteIndex  int            `path:"siteIndex"`
+	RouteIndex int            `path:"routeIndex"`
+	ConfigHash string         `json:"configHash"`
+	DryRun     bool           `json:"dryRun,optional"`
+	Route      CaddyRouteItem `json:"route"`
+}
+
+type CaddyServerGroupItem struct {
+	ID          uint     `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	ServerIds   []int64  `json:"serverIds"`
+	ServerNames []string `json:"serverNames"`
+	CreatedAt   string   `json:"createdAt"`
+	UpdatedAt   string   `json:"updatedAt"`
+}
+
+type CaddyServerGroupListResp struct {
+	List []CaddyServerGroupItem `json:"list"`
+}
+
+type CaddyServerGroupReq struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description,optional"`
+	ServerIds   []int64 `json:"serverIds"`
+}
+
+type CaddyServerGroupUpdateReq struct {
+	ID          uint    `path:"id"`
+	Name        string  `json:"name"`
+	Description string  `json:"description,optional"`
+	ServerIds   []int64 `json:"serverIds"`
+}
+
 type CaddyServerItem struct {
-	ID        uint   `json:"id"`
-	Name      string `json:"name"`
-	Url       string `json:"url"`
-	Type      string `json:"type"`
-	CreatedAt string `json:"createdAt"`
+	ID            uint   `json:"id"`
+	Name          string `json:"name"`
+	Url           string `json:"url"`
+	Type          string `json:"type"`
+	CaddyfilePath string `json:"caddyfilePath"`
+	CreatedAt     string `json:"createdAt"`
 }
 
 type CaddyServerListResp struct {
@@ -120,12 +502,53 @@ type CaddyServerListResp struct {
 }
 
 type CaddyServerReq struct {
-	Name     string `json:"name"`
-	Url      string `json:"url"`                // http://localhost:2019 or remote IP
-	Token    string `json:"token,optional"`     // For remote auth if needed
-	Type     string `json:"type,default=local"` // local | remote
-	Username string `json:"username,optional"`
-	Password string `json:"password,optional"`
+	Name          string `json:"name"`
+	Url           string `json:"url"`                // http://localhost:2019 or remote IP
+	Token         string `json:"token,optional"`     // For remote auth if needed
+	Type          string `json:"type,default=local"` // local | remote
+	Username      string `json:"username,optional"`
+	Password      string `json:"password,optional"`
+	CaddyfilePath string `json:"caddyfilePath,optional"` // 节点实际加载的 Caddyfile 路径，采用漂移的运行配置时据此回读
+}
+
+type CaddySiteDeleteReq struct {
+	ServerId   uint   `path:"serverId"`
+	SiteIndex  int    `path:"siteIndex"`
+	ConfigHash string `form:"configHash"`
+	DryRun     bool   `form:"dryRun,optional"`
+}
+
+type CaddySiteEditResp struct {
+	ConfigHash string `json:"configHash"`
+	Config     string `json:"config"`
+	Applied    bool   `json:"applied"`
+}
+
+type CaddySiteItem struct {
+	Index     int              `json:"index"`
+	Addresses []string         `json:"addresses"`
+	StartLine int              `json:"startLine"`
+	Routes    []CaddyRouteItem `json:"routes"`
+}
+
+type CaddySiteListResp struct {
+	ConfigHash string          `json:"configHash"`
+	Sites      []CaddySiteItem `json:"sites"`
+}
+
+type CaddySiteReq struct {
+	ServerId   uint     `path:"serverId"`
+	ConfigHash string   `json:"configHash"`
+	DryRun     bool     `json:"dryRun,optional"`
+	Addresses  []string `json:"addresses"`
+}
+
+type CaddySiteUpdateReq struct {
+	ServerId   uint     `path:"serverId"`
+	SiteIndex  int      `path:"siteIndex"`
+	ConfigHash string   `json:"configHash"`
+	DryRun     bool     `json:"dryRun,optional"`
+	Addresses  []string `json:"addresses"`
 }
 
 type ChangePasswordReq struct {
@@ -151,7 +574,7 @@ type ChannelListResp struct {
 
 type ChannelReq struct {
 	Name        string `json:"name"`
-	Type        string `json:"type"` // email, webhook, telegram, slack, wecom(机器人), wechat_mp(企业微信应用消息), discord, in_app
+	Type        string `json:"type"` // email, webhook, telegram, slack, wecom(机器人), wechat_mp(企业微信应用消息), discord, dingtalk, feishu, pagerduty, ntfy, gotify, in_app
 	Enabled     bool   `json:"enabled"`
 	Config      string `json:"config"` // JSON string
 	Events      string `json:"events"` // JSON string array ["*"]
@@ -301,41 +724,155 @@ type DashboardTrendItem struct {
 	Value int64  `json:"value"`
 }
 
+type EscalationItem struct {
+	ID             uint   `json:"id"`
+	PolicyID       uint   `json:"policyId"`
+	RuleID         uint   `json:"ruleId"`
+	EventType      string `json:"eventType"`
+	Title          string `json:"title"`
+	Level          string `json:"level"`
+	Status         string `json:"status"`
+	CurrentStep    int    `json:"currentStep"`
+	NextStepAt     string `json:"nextStepAt"`
+	AcknowledgedAt string `json:"acknowledgedAt"`
+	AcknowledgedBy string `json:"acknowledgedBy"`
+	ResolvedAt     string `json:"resolvedAt"`
+	CreatedAt      string `json:"createdAt"`
+}
+
+type EscalationListReq struct {
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"pageSize,default=20"`
+	Status   string `form:"status,optional"` // active/acknowledged/resolved/exhausted
+}
+
+type EscalationListResp struct {
+	List  []EscalationItem `json:"list"`
+	Total int64            `json:"total"`
+}
+
+type EscalationPolicyItem struct {
+	ID          uint                 `json:"id"`
+	Name        string               `json:"name"`
+	Enabled     bool                 `json:"enabled"`
+	Steps       []EscalationStepItem `json:"steps"`
+	Description string               `json:"description"`
+	CreatedAt   string               `json:"createdAt"`
+	UpdatedAt   string               `json:"updatedAt"`
+}
+
+type EscalationPolicyListResp struct {
+	List []EscalationPolicyItem `json:"list"`
+}
+
+type EscalationPolicyReq struct {
+	Name        string               `json:"name"`
+	Enabled     bool                 `json:"enabled"`
+	Steps       []EscalationStepItem `json:"steps"`
+	Description string               `json:"description,optional"`
+}
+
+type EscalationPolicyUpdateReq struct {
+	ID          uint                 `path:"id"`
+	Name        string               `json:"name,optional"`
+	Enabled     bool                 `json:"enabled,optional"`
+	Steps       []EscalationStepItem `json:"steps,optional"`
+	Description string               `json:"description,optional"`
+}
+
+type EscalationStepItem struct {
+	DelayMinutes int     `json:"delayMinutes"` // 距上一步的等待分钟数，首步忽略
+	ChannelIDs   []int64 `json:"channelIds,optional"`
+	ScheduleIDs  []int64 `json:"scheduleIds,optional"`
+}
+
 type IDReq struct {
 	ID uint `path:"id"`
 }
 
+type InboundAlertReq struct {
+	Token string `path:"token"`
+}
+
+type InboundAlertResp struct {
+	Accepted int `json:"accepted"`
+}
+
+type InboundTokenItem struct {
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	Token       string `json:"token"`
+	URL         string `json:"url"`
+	Enabled     bool   `json:"enabled"`
+	Format      string `json:"format"`
+	EventType   string `json:"eventType"`
+	Mapping     string `json:"mapping"`
+	RateLimit   int    `json:"rateLimit"`
+	Description string `json:"description"`
+	LastUsedAt  string `json:"lastUsedAt"`
+	CreatedAt   string `json:"createdAt"`
+	UpdatedAt   string `json:"updatedAt"`
+}
+
+type InboundTokenListResp struct {
+	List []InboundTokenItem `json:"list"`
+}
+
+type InboundTokenReq struct {
+	Name        string `json:"name"`
+	Enabled     bool   `json:"enabled"`
+	Format      string `json:"format"`               // alertmanager/grafana/generic
+	EventType   string `json:"eventType,optional"`   // 为空时使用 inbound.<format>
+	Mapping     string `json:"mapping,optional"`     // JSON string，通用格式的字段映射
+	RateLimit   int    `json:"rateLimit,default=60"` // 每分钟请求数，0 表示不限流
+	Description string `json:"description,optional"`
+}
+
+type InboundTokenUpdateReq struct {
+	ID          uint   `path:"id"`
+	Name        string `json:"name,optional"`
+	Enabled     bool   `json:"enabled,optional"`
+	Format      string `json:"format,optional"`
+	EventType   string `json:"eventType,optional"`
+	Mapping     string `json:"mapping,optional"`
+	RateLimit   int    `json:"rateLimit,default=-1"` // -1 表示不修改
+	Description string `json:"description,optional"`
+}
+
 type IsRouteExistReq struct {
 	RouteName string `form:"routeName"`
 }
 
 type LogItem struct {
-	ID            uint   `json:"id"`
-	EventID       string `json:"eventId"`
-	EventType     string `json:"eventType"`
-	Title         string `json:"title"`
-	Message       string `json:"message"`
-	Level         string `json:"level"`
-	ChannelID     uint   `json:"channelId"`
-	RuleID        uint   `json:"ruleId"`
-	Status        int    `json:"status"`
-	Error         string `json:"error"`
-	RetryCount    int    `json:"retryCount"`
-	SentAt        string `json:"sentAt"`
-	CreatedAt     string `json:"createdAt"`
-	JobStatus     string `json:"jobStatus"`
-	JobRetryCount int    `json:"jobRetryCount"`
-	NextRunAt     string `json:"nextRunAt"`
-	LastError     string `json:"lastError"`
+	ID             uint   `json:"id"`
+	EventID        string `json:"eventId"`
+	EventType      string `json:"eventType"`
+	Title          string `json:"title"`
+	Message        string `json:"message"`
+	Level          string `json:"level"`
+	ChannelID      uint   `json:"channelId"`
+	RuleID         uint   `json:"ruleId"`
+	Status         int    `json:"status"`
+	Error          string `json:"error"`
+	RetryCount     int    `json:"retryCount"`
+	SentAt         string `json:"sentAt"`
+	CreatedAt      string `json:"createdAt"`
+	JobStatus      string `json:"jobStatus"`
+	JobRetryCount  int    `json:"jobRetryCount"`
+	NextRunAt      string `json:"nextRunAt"`
+	LastError      string `json:"lastError"`
+	EscalationID   uint   `json:"escalationId"`
+	EscalationStep int    `json:"escalationStep"`
 }
 
 type LogListReq struct {
-	Page      int    `form:"page,default=1"`
-	PageSize  int    `form:"pageSize,default=20"`
-	Status    int    `form:"status,default=-1"`
-	ChannelID uint   `form:"channelId,optional"`
-	RuleID    uint   `form:"ruleId,optional"`
-	JobStatus string `form:"jobStatus,optional"`
+	Page         int    `form:"page,default=1"`
+	PageSize     int    `form:"pageSize,default=20"`
+	Status       int    `form:"status,default=-1"`
+	ChannelID    uint   `form:"channelId,optional"`
+	RuleID       uint   `form:"ruleId,optional"`
+	JobStatus    string `form:"jobStatus,optional"`
+	EscalationID uint   `form:"escalationId,optional"`
 }
 
 type LogListResp struct {
@@ -413,6 +950,43 @@ type MenuRoute struct {
 	Children  []MenuRoute `json:"children,omitempty"`
 }
 
+type OnCallScheduleItem struct {
+	ID                  uint    `json:"id"`
+	Name                string  `json:"name"`
+	Enabled             bool    `json:"enabled"`
+	RotationStart       string  `json:"rotationStart"`
+	RotationDays        int     `json:"rotationDays"`
+	Participants        []int64 `json:"participants"`
+	Description         string  `json:"description"`
+	CurrentOnCallUserID uint    `json:"currentOnCallUserId"`
+	CurrentOnCallUser   string  `json:"currentOnCallUser"`
+	CreatedAt           string  `json:"createdAt"`
+	UpdatedAt           string  `json:"updatedAt"`
+}
+
+type OnCallScheduleListResp struct {
+	List []OnCallScheduleItem `json:"list"`
+}
+
+type OnCallScheduleReq struct {
+	Name          string  `json:"name"`
+	Enabled       bool    `json:"enabled"`
+	RotationStart string  `json:"rotationStart"` // 2006-01-02 15:04:05
+	RotationDays  int     `json:"rotationDays,default=7"`
+	Participants  []int64 `json:"participants"` // 用户 ID，按轮换顺序
+	Description   string  `json:"description,optional"`
+}
+
+type OnCallScheduleUpdateReq struct {
+	ID            uint    `path:"id"`
+	Name          string  `json:"name,optional"`
+	Enabled       bool    `json:"enabled,optional"`
+	RotationStart string  `json:"rotationStart,optional"`
+	RotationDays  int     `json:"rotationDays,optional"`
+	Participants  []int64 `json:"participants,optional"`
+	Description   string  `json:"description,optional"`
+}
+
 type PreviewTemplateReq struct {
 	Format  string `json:"format"`
 	Content string `json:"content"`
@@ -427,6 +1001,60 @@ type RefreshTokenReq struct {
 	RefreshToken string `json:"refreshToken"`
 }
 
+type ReportItem struct {
+	ID               uint     `json:"id"`
+	Name             string   `json:"name"`
+	Enabled          bool     `json:"enabled"`
+	Period           string   `json:"period"`
+	Schedule         string   `json:"schedule"`
+	Hosts            []string `json:"hosts"`
+	PolicyIDs        []int64  `json:"policyIds"`
+	ChannelIDs       []int64  `json:"channelIds"`
+	HTMLTemplate     string   `json:"htmlTemplate"`
+	MarkdownTemplate string   `json:"markdownTemplate"`
+	TopN             int      `json:"topN"`
+	Description      string   `json:"description"`
+	NextRunAt        string   `json:"nextRunAt"`
+	LastRunAt        string   `json:"lastRunAt"`
+	LastStatus       string   `json:"lastStatus"` // success/partial/failed
+	LastError        string   `json:"lastError"`
+	CreatedAt        string   `json:"createdAt"`
+	UpdatedAt        string   `json:"updatedAt"`
+}
+
+type ReportListResp struct {
+	List []ReportItem `json:"list"`
+}
+
+type ReportReq struct {
+	Name             string   `json:"name"`
+	Enabled          bool     `json:"enabled"`
+	Period           string   `json:"period"`             // daily/weekly/monthly
+	Schedule         string   `json:"schedule,optional"`  // 带秒的 cron 表达式，为空按周期默认
+	Hosts            []string `json:"hosts,optional"`     // 为空表示全部站点
+	PolicyIDs        []int64  `json:"policyIds,optional"` // 为空表示全部启用的 WAF 策略
+	ChannelIDs       []int64  `json:"channelIds"`
+	HTMLTemplate     string   `json:"htmlTemplate,optional"`     // 邮件渠道，默认 default_report_email
+	MarkdownTemplate string   `json:"markdownTemplate,optional"` // IM 渠道，默认 default_report_markdown
+	TopN             int      `json:"topN,default=10"`
+	Description      string   `json:"description,optional"`
+}
+
+type ReportUpdateReq struct {
+	ID               uint     `path:"id"`
+	Name             string   `json:"name,optional"`
+	Enabled          bool     `json:"enabled,optional"`
+	Period           string   `json:"period,optional"`
+	Schedule         string   `json:"schedule,optional"`
+	Hosts            []string `json:"hosts,optional"`
+	PolicyIDs        []int64  `json:"policyIds,optional"`
+	ChannelIDs       []int64  `json:"channelIds,optional"`
+	HTMLTemplate     string   `json:"htmlTemplate,optional"`
+	MarkdownTemplate string   `json:"markdownTemplate,optional"`
+	TopN             int      `json:"topN,optional"`
+	Description      string   `json:"description,optional"`
+}
+
 type RoleItem struct {
 	ID          uint     `json:"id"`
 	Name        string   `json:"name"`
@@ -451,18 +1079,19 @@ type RouteMeta struct {
 }
 
 type RuleItem struct {
-	ID              uint    `json:"id"`
-	Name            string  `json:"name"`
-	Enabled         bool    `json:"enabled"`
-	RuleType        string  `json:"ruleType"`
-	EventType       string  `json:"eventType"`
-	Condition       string  `json:"condition"`
-	ChannelIDs      []int64 `json:"channelIds"`
-	Template        string  `json:"template"`
-	SilenceDuration int     `json:"silenceDuration"`
-	Description     string  `json:"description"`
-	CreatedAt       string  `json:"createdAt"`
-	UpdatedAt       string  `json:"updatedAt"`
+	ID                 uint    `json:"id"`
+	Name               string  `json:"name"`
+	Enabled            bool    `json:"enabled"`
+	RuleType           string  `json:"ruleType"`
+	EventType          string  `json:"eventType"`
+	Condition          string  `json:"condition"`
+	ChannelIDs         []int64 `json:"channelIds"`
+	Template           string  `json:"template"`
+	SilenceDuration    int     `json:"silenceDuration"`
+	Description        string  `json:"description"`
+	EscalationPolicyID uint    `json:"escalationPolicyId"`
+	CreatedAt          string  `json:"createdAt"`
+	UpdatedAt          string  `json:"updatedAt"`
 }
 
 type RuleListResp struct {
@@ -470,28 +1099,30 @@ type RuleListResp struct {
 }
 
 type RuleReq struct {
-	Name            string  `json:"name"`
-	Enabled         bool    `json:"enabled"`
-	RuleType        string  `json:"ruleType"` // threshold, frequency, pattern
-	EventType       string  `json:"eventType"`
-	Condition       string  `json:"condition"` // JSON string
-	ChannelIDs      []int64 `json:"channelIds"`
-	Template        string  `json:"template,optional"`
-	SilenceDuration int     `json:"silenceDuration"` // seconds
-	Description     string  `json:"description,optional"`
+	Name               string  `json:"name"`
+	Enabled            bool    `json:"enabled"`
+	RuleType           string  `json:"ruleType"` // threshold, frequency, pattern
+	EventType          string  `json:"eventType"`
+	Condition          string  `json:"condition"` // JSON string
+	ChannelIDs         []int64 `json:"channelIds"`
+	Template           string  `json:"template,optional"`
+	SilenceDuration    int     `json:"silenceDuration"` // seconds
+	Description        string  `json:"description,optional"`
+	EscalationPolicyID uint    `json:"escalationPolicyId,optional"`
 }
 
 type RuleUpdateReq struct {
-	ID              uint    `path:"id"`
-	Name            string  `json:"name,optional"`
-	Enabled         bool    `json:"enabled,optional"`
-	RuleType        string  `json:"ruleType,optional"`
-	EventType       string  `json:"eventType,optional"`
-	Condition       string  `json:"condition,optional"`
-	ChannelIDs      []int64 `json:"channelIds,optional"`
-	Template        string  `json:"template,optional"`
-	SilenceDuration int     `json:"silenceDuration,optional"`
-	Description     string  `json:"description,optional"`
+	ID                 uint    `path:"id"`
+	Name               string  `json:"name,optional"`
+	Enabled            bool    `json:"enabled,optional"`
+	RuleType           string  `json:"ruleType,optional"`
+	EventType          string  `json:"eventType,optional"`
+	Condition          string  `json:"condition,optional"`
+	ChannelIDs         []int64 `json:"channelIds,optional"`
+	Template           string  `json:"template,optional"`
+	SilenceDuration    int     `json:"silenceDuration,optional"`
+	Description        string  `json:"description,optional"`
+	EscalationPolicyID uint    `json:"escalationPolicyId,optional"`
 }
 
 type SimpleWafConfigReq struct {
@@ -600,13 +1231,14 @@ type TriggerTaskReq struct {
 }
 
 type UpdateCaddyServerReq struct {
-	ID       uint   `path:"id"`
-	Name     string `json:"name,optional"`
-	Url      string `json:"url,optional"`
-	Token    string `json:"token,optional"`
-	Type     string `json:"type,optional"`
-	Username string `json:"username,optional"`
-	Password string `json:"password,optional"`
+	ID            uint   `path:"id"`
+	Name          string `json:"name,optional"`
+	Url           string `json:"url,optional"`
+	Token         string `json:"token,optional"`
+	Type          string `json:"type,optional"`
+	Username      string `json:"username,optional"`
+	Password      string `json:"password,optional"`
+	CaddyfilePath string `json:"caddyfilePath,optional"` // 节点实际加载的 Caddyfile 路径，采用漂移的运行配置时据此回读
 }
 
 type UpdateMenuReq struct {
@@ -631,6 +1263,22 @@ type UpdateUserReq struct {
 	Roles    []string `json:"roles,optional"`
 }
 
+type UserContactItem struct {
+	UserID     uint    `json:"userId"`
+	Username   string  `json:"username"`
+	ChannelIDs []int64 `json:"channelIds"`
+	UpdatedAt  string  `json:"updatedAt"`
+}
+
+type UserContactListResp struct {
+	List []UserContactItem `json:"list"`
+}
+
+type UserContactReq struct {
+	UserID     uint    `path:"userId"`
+	ChannelIDs []int64 `json:"channelIds"`
+}
+
 type UserInfoReq struct {
 }
 
@@ -669,6 +1317,172 @@ type UserRouteResp struct {
 	Routes []MenuRoute `json:"routes"`
 }
 
+type WafBanItem struct {
+	ID        uint   `json:"id"`
+	RuleId    uint   `json:"ruleId"`
+	RuleName  string `json:"ruleName"`
+	IP        string `json:"ip"`
+	Host      string `json:"host"`
+	HitCount  int64  `json:"hitCount"`
+	Reason    string `json:"reason"`
+	Status    string `json:"status"`
+	ExpiresAt string `json:"expiresAt"`
+	RevokedAt string `json:"revokedAt"`
+	Operator  string `json:"operator"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+type WafBanListReq struct {
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"pageSize,default=20"`
+	RuleId   uint   `form:"ruleId,optional"`
+	Status   string `form:"status,optional"` // active | expired | revoked
+	IP       string `form:"ip,optional"`
+}
+
+type WafBanListResp struct {
+	List  []WafBanItem `json:"list"`
+	Total int64        `json:"total"`
+}
+
+type WafBanReq struct {
+	IP         string `json:"ip,optional"`         // IP 或 CIDR，与 caddyLogId 二选一
+	CaddyLogId uint   `json:"caddyLogId,optional"` // 直接取访问日志中的客户端 IP
+	Host       string `json:"host,optional"`       // 为空表示全部站点
+	BanMinutes int64  `json:"banMinutes,optional"`
+	Reason     string `json:"reason,optional"`
+}
+
+type WafBanRuleItem struct {
+	ID              uint   `json:"id"`
+	Name            string `json:"name"`
+	Description     string `json:"description"`
+	Enabled         bool   `json:"enabled"`
+	Host            string `json:"host"`
+	StatusCodes     string `json:"statusCodes"`
+	Threshold       int64  `json:"threshold"`
+	WindowMinutes   int64  `json:"windowMinutes"`
+	BanMinutes      int64  `json:"banMinutes"`
+	LastEvaluatedAt string `json:"lastEvaluatedAt"`
+	CreatedAt       string `json:"createdAt"`
+	UpdatedAt       string `json:"updatedAt"`
+}
+
+type WafBanRuleListReq struct {
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"pageSize,default=20"`
+	Name     string `form:"name,optional"`
+}
+
+type WafBanRuleListResp struct {
+	List  []WafBanRuleItem `json:"list"`
+	Total int64            `json:"total"`
+}
+
+type WafBanRuleReq struct {
+	Name          string `json:"name"`
+	Description   string `json:"description,optional"`
+	Enabled       bool   `json:"enabled,optional"`
+	Host          string `json:"host,optional"`          // 为空表示全部站点
+	StatusCodes   string `json:"statusCodes,optional"`   // 逗号分隔，默认 401,403,429
+	Threshold     int64  `json:"threshold,optional"`     // 窗口内请求数阈值，默认 20
+	WindowMinutes int64  `json:"windowMinutes,optional"` // 统计窗口，默认 5 分钟
+	BanMinutes    int64  `json:"banMinutes,optional"`    // 封禁时长，默认 60 分钟
+}
+
+type WafBanRuleUpdateReq struct {
+	ID            uint   `path:"id"`
+	Name          string `json:"name"`
+	Description   string `json:"description,optional"`
+	Enabled       bool   `json:"enabled"`
+	Host          string `json:"host,optional"`
+	StatusCodes   string `json:"statusCodes,optional"`
+	Threshold     int64  `json:"threshold,optional"`
+	WindowMinutes int64  `json:"windowMinutes,optional"`
+	BanMinutes    int64  `json:"banMinutes,optional"`
+}
+
+type WafBundleImportReq struct {
+	ActivateNow bool `json:"activateNow,optional"`
+}
+
+type WafBundleKeyResp struct {
+	KeyId       string `json:"keyId"`
+	PublicKey   string `json:"publicKey"`
+	TrustedKeys string `json:"trustedKeys"`
+}
+
+type WafCustomRuleItem struct {
+	ID          uint   `json:"id"`
+	PolicyId    uint   `json:"policyId"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+	RuleId      int64  `json:"ruleId"`
+	Phase       int64  `json:"phase"`
+	Priority    int64  `json:"priority"`
+	Directive   string `json:"directive"`
+	CreatedAt   string `json:"createdAt"`
+	UpdatedAt   string `json:"updatedAt"`
+}
+
+type WafCustomRuleListReq struct {
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"pageSize,default=20"`
+	PolicyId uint   `form:"policyId,optional"`
+	Name     string `form:"name,optional"`
+}
+
+type WafCustomRuleListResp struct {
+	List  []WafCustomRuleItem `json:"list"`
+	Total int64               `json:"total"`
+}
+
+type WafCustomRuleReq struct {
+	PolicyId    uint   `json:"policyId"`
+	Name        string `json:"name,optional"`
+	Description string `json:"description,optional"`
+	Enabled     bool   `json:"enabled,optional"`
+	Phase       int64  `json:"phase,optional"`    // 1-5，为空时以指令中的 phase 为准
+	Priority    int64  `json:"priority,optional"` // 渲染顺序，越小越靠前
+	Directive   string `json:"directive"`         // SecRule/SecAction，id 需在 10000-99999 之间
+}
+
+type WafCustomRuleUpdateReq struct {
+	ID          uint   `path:"id"`
+	PolicyId    uint   `json:"policyId"`
+	Name        string `json:"name,optional"`
+	Description string `json:"description,optional"`
+	Enabled     bool   `json:"enabled"`
+	Phase       int64  `json:"phase,optional"`
+	Priority    int64  `json:"priority,optional"`
+	Directive   string `json:"directive"`
+}
+
+type WafCustomRuleValidateReq struct {
+	Phase     int64  `json:"phase,optional"`
+	Directive string `json:"directive"`
+}
+
+type WafCustomRuleValidateResp struct {
+	RuleId    int64  `json:"ruleId"`
+	Phase     int64  `json:"phase"`
+	Directive string `json:"directive"`
+}
+
+type WafDistributeReq struct {
+	TargetType    string `json:"targetType"` // release | policy
+	TargetId      uint   `json:"targetId"`
+	GroupId       uint   `json:"groupId,optional"`            // 为空时分发到全部 Caddy 节点
+	Strategy      string `json:"strategy,default=sequential"` // sequential | parallel
+	StopOnFailure bool   `json:"stopOnFailure,optional"`      // 任一节点失败即终止并回滚已完成节点
+}
+
+type WafDistributeResp struct {
+	JobId uint `json:"jobId"`
+}
+
 type WafEngineStatusResp struct {
 	ServerId       uint   `json:"serverId"`
 	CurrentVersion string `json:"currentVersion,optional"`
@@ -679,6 +1493,89 @@ type WafEngineStatusResp struct {
 	Message        string `json:"message,optional"`
 }
 
+type WafIPListEntryItem struct {
+	ID        uint   `json:"id"`
+	ListId    uint   `json:"listId"`
+	Kind      string `json:"kind"`
+	Value     string `json:"value"`
+	Note      string `json:"note"`
+	Source    string `json:"source"`
+	SourceRef string `json:"sourceRef"`
+	ExpiresAt string `json:"expiresAt"`
+	Expired   bool   `json:"expired"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+type WafIPListEntryListReq struct {
+	ID       uint   `path:"id"`
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"pageSize,default=20"`
+	Keyword  string `form:"keyword,optional"`
+}
+
+type WafIPListEntryListResp struct {
+	List  []WafIPListEntryItem `json:"list"`
+	Total int64                `json:"total"`
+}
+
+type WafIPListEntryReq struct {
+	ID         uint   `path:"id"`
+	Kind       string `json:"kind,optional"`       // ip（含 CIDR）| country
+	Value      string `json:"value,optional"`      // 与 caddyLogId 二选一
+	CaddyLogId uint   `json:"caddyLogId,optional"` // 直接取访问日志中的客户端 IP
+	Source     string `json:"source,optional"`     // manual | dashboard，caddyLogId 非空时为 caddy_log
+	Note       string `json:"note,optional"`
+	ExpiresAt  string `json:"expiresAt,optional"`
+	Publish    bool   `json:"publish,optional"` // 保存后立即发布所属策略（含策略当前草稿）
+}
+
+type WafIPListItem struct {
+	ID               uint   `json:"id"`
+	PolicyId         uint   `json:"policyId"`
+	BindingId        uint   `json:"bindingId"`
+	Name             string `json:"name"`
+	Description      string `json:"description"`
+	Action           string `json:"action"`
+	Enabled          bool   `json:"enabled"`
+	EntryCount       int64  `json:"entryCount"`
+	ActiveEntryCount int64  `json:"activeEntryCount"`
+	CreatedAt        string `json:"createdAt"`
+	UpdatedAt        string `json:"updatedAt"`
+}
+
+type WafIPListListReq struct {
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"pageSize,default=20"`
+	PolicyId uint   `form:"policyId,optional"`
+	Action   string `form:"action,optional"`
+	Name     string `form:"name,optional"`
+}
+
+type WafIPListListResp struct {
+	List  []WafIPListItem `json:"list"`
+	Total int64           `json:"total"`
+}
+
+type WafIPListReq struct {
+	PolicyId    uint   `json:"policyId"`
+	BindingId   uint   `json:"bindingId,optional"` // 为空表示作用于整个策略
+	Name        string `json:"name"`
+	Description string `json:"description,optional"`
+	Action      string `json:"action,optional"` // allow | deny，默认 deny
+	Enabled     bool   `json:"enabled,optional"`
+}
+
+type WafIPListUpdateReq struct {
+	ID          uint   `path:"id"`
+	PolicyId    uint   `json:"policyId"`
+	BindingId   uint   `json:"bindingId,optional"`
+	Name        string `json:"name"`
+	Description string `json:"description,optional"`
+	Action      string `json:"action,optional"`
+	Enabled     bool   `json:"enabled"`
+}
+
 type WafIntegrationApplyReq struct {
 	ServerId      uint     `json:"serverId,optional"`
 	Enabled       bool     `json:"enabled"`
@@ -709,17 +1606,18 @@ type WafIntegrationStatusResp struct {
 }
 
 type WafJobItem struct {
-	ID          uint   `json:"id"`
-	SourceId    uint   `json:"sourceId"`
-	ReleaseId   uint   `json:"releaseId"`
-	Action      string `json:"action"`
-	TriggerMode string `json:"triggerMode"`
-	Operator    string `json:"operator"`
-	Status      string `json:"status"`
-	Message     string `json:"message"`
-	StartedAt   string `json:"startedAt"`
-	FinishedAt  string `json:"finishedAt"`
-	CreatedAt   string `json:"createdAt"`
+	ID          uint                `json:"id"`
+	SourceId    uint                `json:"sourceId"`
+	ReleaseId   uint                `json:"releaseId"`
+	Action      string              `json:"action"`
+	TriggerMode string              `json:"triggerMode"`
+	Operator    string              `json:"operator"`
+	Status      string              `json:"status"`
+	Message     string              `json:"message"`
+	RuleDiff    *WafRuleDiffSummary `json:"ruleDiff,omitempty"`
+	StartedAt   string              `json:"startedAt"`
+	FinishedAt  string              `json:"finishedAt"`
+	CreatedAt   string              `json:"createdAt"`
 }
 
 type WafJobListReq struct {
@@ -734,6 +1632,75 @@ type WafJobListResp struct {
 	Total int64        `json:"total"`
 }
 
+type WafMirrorClientItem struct {
+	ID             uint   `json:"id"`
+	Name           string `json:"name"`
+	Token          string `json:"token"`
+	Kind           string `json:"kind"`
+	PinnedVersion  string `json:"pinnedVersion"`
+	Enabled        bool   `json:"enabled"`
+	Description    string `json:"description"`
+	ManifestUrl    string `json:"manifestUrl"`
+	LastUsedAt     string `json:"lastUsedAt"`
+	LastClientIp   string `json:"lastClientIp"`
+	LastServedFile string `json:"lastServedFile"`
+	CreatedAt      string `json:"createdAt"`
+	UpdatedAt      string `json:"updatedAt"`
+}
+
+type WafMirrorClientListReq struct {
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"pageSize,default=20"`
+	Keyword  string `form:"keyword,optional"`
+}
+
+type WafMirrorClientListResp struct {
+	List  []WafMirrorClientItem `json:"list"`
+	Total int64                 `json:"total"`
+}
+
+type WafMirrorClientReq struct {
+	Name          string `json:"name"`
+	Kind          string `json:"kind,optional"`
+	PinnedVersion string `json:"pinnedVersion,optional"` // 为空时跟随当前激活版本
+	Enabled       bool   `json:"enabled,optional"`
+	Description   string `json:"description,optional"`
+}
+
+type WafMirrorClientUpdateReq struct {
+	ID            uint   `path:"id"`
+	Name          string `json:"name"`
+	Kind          string `json:"kind,optional"`
+	PinnedVersion string `json:"pinnedVersion,optional"`
+	Enabled       bool   `json:"enabled"`
+	Description   string `json:"description,optional"`
+}
+
+type WafMirrorFileReq struct {
+	Kind          string `path:"kind"`
+	Version       string `path:"version"`
+	Authorization string `header:"Authorization,optional"`
+}
+
+type WafMirrorManifestReq struct {
+	Kind          string `path:"kind"`
+	Version       string `form:"version,optional"`
+	Authorization string `header:"Authorization,optional"`
+}
+
+type WafMirrorManifestResp struct {
+	Kind             string `json:"kind"`
+	Version          string `json:"version"`
+	Pinned           bool   `json:"pinned"`
+	PackageSha256    string `json:"packageSha256"`
+	PackageSizeBytes int64  `json:"packageSizeBytes"`
+	PackageUrl       string `json:"packageUrl"`
+	SignatureUrl     string `json:"signatureUrl"`
+	SignatureType    string `json:"signatureType"`
+	BundleUrl        string `json:"bundleUrl"`
+	KeyId            string `json:"keyId"`
+}
+
 type WafPolicyActionReq struct {
 	ID uint `path:"id"`
 }
@@ -791,6 +1758,25 @@ type WafPolicyBindingUpdateReq struct {
 	Priority    int64  `json:"priority"`
 }
 
+type WafPolicyFalsePositiveFeedbackApplyReq struct {
+	ID           uint   `path:"id"`
+	PolicyId     uint   `json:"policyId,optional"`
+	Name         string `json:"name,optional"`
+	Description  string `json:"description,optional"`
+	ScopeType    string `json:"scopeType,optional"` // global | site | route
+	Host         string `json:"host,optional"`
+	Path         string `json:"path,optional"`
+	Method       string `json:"method,optional"`
+	RemoveType   string `json:"removeType,optional"`  // id | tag | target_id | target_tag
+	RemoveValue  string `json:"removeValue,optional"` // 为空时按自动建议创建
+	RemoveTarget string `json:"removeTarget,optional"`
+	ProcessNote  string `json:"processNote,optional"`
+}
+
+type WafPolicyFalsePositiveFeedbackApplyResp struct {
+	ExclusionId uint `json:"exclusionId"`
+}
+
 type WafPolicyFalsePositiveFeedbackBatchStatusUpdateReq struct {
 	IDs            []uint `json:"ids"`
 	FeedbackStatus string `json:"feedbackStatus"` // pending | confirmed | resolved
@@ -824,6 +1810,7 @@ type WafPolicyFalsePositiveFeedbackItem struct {
 	ProcessNote    string `json:"processNote"`
 	ProcessedBy    string `json:"processedBy"`
 	ProcessedAt    string `json:"processedAt"`
+	ExclusionId    uint   `json:"exclusionId"` // 由该反馈生成的排除规则，0 表示未关联
 	CreatedAt      string `json:"createdAt"`
 }
 
@@ -857,6 +1844,13 @@ type WafPolicyFalsePositiveFeedbackReq struct {
 	Suggestion string `json:"suggestion,optional"`
 }
 
+type WafPolicyFalsePositiveFeedbackRuleHit struct {
+	RuleId    int64    `json:"ruleId"`
+	Message   string   `json:"message"`
+	HitCount  int64    `json:"hitCount"`
+	Variables []string `json:"variables"`
+}
+
 type WafPolicyFalsePositiveFeedbackStatusUpdateReq struct {
 	ID             uint   `path:"id"`
 	FeedbackStatus string `json:"feedbackStatus"` // pending | confirmed | resolved
@@ -865,6 +1859,21 @@ type WafPolicyFalsePositiveFeedbackStatusUpdateReq struct {
 	DueAt          string `json:"dueAt,optional"` // YYYY-MM-DD HH:mm:ss
 }
 
+type WafPolicyFalsePositiveFeedbackSuggestionReq struct {
+	ID uint `path:"id"`
+}
+
+type WafPolicyFalsePositiveFeedbackSuggestionResp struct {
+	FeedbackId          uint                                    `json:"feedbackId"`
+	ExclusionId         uint                                    `json:"exclusionId"` // 已关联的排除规则
+	MatchedEventCount   int64                                   `json:"matchedEventCount"`
+	RuleHits            []WafPolicyFalsePositiveFeedbackRuleHit `json:"ruleHits"`
+	Candidate           WafRuleExclusionReq                     `json:"candidate"`
+	Rationale           string                                  `json:"rationale"`
+	ExclusionDirectives string                                  `json:"exclusionDirectives"`
+	Directives          string                                  `json:"directives"` // 应用候选排除规则后的策略预览
+}
+
 type WafPolicyItem struct {
 	ID                          uint   `json:"id"`
 	Name                        string `json:"name"`
@@ -902,6 +1911,41 @@ type WafPolicyPreviewResp struct {
 	Directives string `json:"directives"`
 }
 
+type WafPolicyReplayItem struct {
+	Ref              string  `json:"ref"`
+	Method           string  `json:"method"`
+	Host             string  `json:"host"`
+	Uri              string  `json:"uri"`
+	Status           int     `json:"status"`
+	InterruptRuleId  int64   `json:"interruptRuleId"`
+	BaselineRuleIds  []int64 `json:"baselineRuleIds"`
+	CandidateRuleIds []int64 `json:"candidateRuleIds"`
+}
+
+type WafPolicyReplayReq struct {
+	ID           uint   `path:"id"`
+	Source       string `json:"source,optional"` // logs | upload
+	SampleSize   int    `json:"sampleSize,optional"`
+	Hours        int    `json:"hours,optional"`
+	Host         string `json:"host,optional"`
+	CorpusFormat string `json:"corpusFormat,optional"` // auto | har | ndjson
+}
+
+type WafPolicyReplayResp struct {
+	PolicyId            uint                  `json:"policyId"`
+	BaselineRevisionId  uint                  `json:"baselineRevisionId"`
+	CrsVersion          string                `json:"crsVersion"`
+	Total               int64                 `json:"total"`
+	BaselineBlocked     int64                 `json:"baselineBlocked"`
+	CandidateBlocked    int64                 `json:"candidateBlocked"`
+	NewlyBlockedCount   int64                 `json:"newlyBlockedCount"`
+	NewlyUnblockedCount int64                 `json:"newlyUnblockedCount"`
+	NewlyBlocked        []WafPolicyReplayItem `json:"newlyBlocked"`
+	NewlyUnblocked      []WafPolicyReplayItem `json:"newlyUnblocked"`
+	Warnings            []string              `json:"warnings"`
+	DurationMs          int64                 `json:"durationMs"`
+}
+
 type WafPolicyReq struct {
 	Name                        string `json:"name"`
 	Description                 string `json:"description,optional"`
@@ -949,6 +1993,56 @@ type WafPolicyRollbackReq struct {
 	RevisionId uint `json:"revisionId"`
 }
 
+type WafPolicyRolloutActionReq struct {
+	ID      uint   `path:"id"`
+	Message string `json:"message,optional"`
+}
+
+type WafPolicyRolloutItem struct {
+	ID                   uint    `json:"id"`
+	PolicyId             uint    `json:"policyId"`
+	PolicyName           string  `json:"policyName"`
+	BaselineRevisionId   uint    `json:"baselineRevisionId"`
+	CandidateRevisionId  uint    `json:"candidateRevisionId"`
+	BindingIds           []int64 `json:"bindingIds"`
+	Status               string  `json:"status"` // canary | promoted | aborted
+	AutoPromote          bool    `json:"autoPromote"`
+	MaxFalsePositiveRate float64 `json:"maxFalsePositiveRate"`
+	ObserveHours         int64   `json:"observeHours"`
+	RequestCount         int64   `json:"requestCount"`
+	WouldBlockCount      int64   `json:"wouldBlockCount"`
+	NewlyBlockedCount    int64   `json:"newlyBlockedCount"`
+	FalsePositiveRate    float64 `json:"falsePositiveRate"`
+	EvaluatedAt          string  `json:"evaluatedAt"`
+	StartedAt            string  `json:"startedAt"`
+	FinishedAt           string  `json:"finishedAt"`
+	Operator             string  `json:"operator"`
+	Message              string  `json:"message"`
+	CreatedAt            string  `json:"createdAt"`
+	UpdatedAt            string  `json:"updatedAt"`
+}
+
+type WafPolicyRolloutListReq struct {
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"pageSize,default=20"`
+	PolicyId uint   `form:"policyId,optional"`
+	Status   string `form:"status,optional"`
+}
+
+type WafPolicyRolloutListResp struct {
+	List  []WafPolicyRolloutItem `json:"list"`
+	Total int64                  `json:"total"`
+}
+
+type WafPolicyRolloutStartReq struct {
+	ID                   uint    `path:"id"`
+	BindingIds           []int64 `json:"bindingIds"` // 灰度作用域，需为该策略的绑定
+	AutoPromote          bool    `json:"autoPromote,optional"`
+	MaxFalsePositiveRate float64 `json:"maxFalsePositiveRate,optional"` // 自动转正误报率阈值（百分比），默认 1
+	ObserveHours         int64   `json:"observeHours,optional"`         // 自动转正前的观察时长，默认 24
+	Message              string  `json:"message,optional"`
+}
+
 type WafPolicyStatsDimensionItem struct {
 	Key          string  `json:"key"`
 	HitCount     int64   `json:"hitCount"`
@@ -964,6 +2058,7 @@ type WafPolicyStatsItem struct {
 	BlockedCount                int64   `json:"blockedCount"`
 	AllowedCount                int64   `json:"allowedCount"`
 	SuspectedFalsePositiveCount int64   `json:"suspectedFalsePositiveCount"`
+	RateLimitedCount            int64   `json:"rateLimitedCount"`
 	BlockRate                   float64 `json:"blockRate"`
 }
 
@@ -986,13 +2081,42 @@ type WafPolicyStatsResp struct {
 	TopHosts   []WafPolicyStatsDimensionItem `json:"topHosts"`
 	TopPaths   []WafPolicyStatsDimensionItem `json:"topPaths"`
 	TopMethods []WafPolicyStatsDimensionItem `json:"topMethods"`
+	// 规则级统计（来自 WAF 审计日志）
+	TopRules              []WafPolicyStatsRuleItem      `json:"topRules"`
+	TopTags               []WafPolicyStatsDimensionItem `json:"topTags"`
+	RuleTrend             []WafPolicyStatsRuleTrendItem `json:"ruleTrend"`
+	AnomalyScoreHistogram []WafPolicyStatsScoreBucket   `json:"anomalyScoreHistogram"`
+	AnomalyThreshold      int64                         `json:"anomalyThreshold"`
+}
+
+type WafPolicyStatsRuleItem struct {
+	RuleId       int64    `json:"ruleId"`
+	Message      string   `json:"message"`
+	HitCount     int64    `json:"hitCount"`
+	BlockedCount int64    `json:"blockedCount"`
+	TopPaths     []string `json:"topPaths"`
+}
+
+type WafPolicyStatsRuleTrendItem struct {
+	RuleId int64                     `json:"ruleId"`
+	Points []WafPolicyStatsTrendItem `json:"points"`
+}
+
+type WafPolicyStatsScoreBucket struct {
+	Label          string `json:"label"`
+	Min            int    `json:"min"`
+	Max            int    `json:"max"` // -1 表示无上限
+	Count          int64  `json:"count"`
+	BlockedCount   int64  `json:"blockedCount"`
+	AboveThreshold bool   `json:"aboveThreshold"`
 }
 
 type WafPolicyStatsTrendItem struct {
-	Time         string `json:"time"`
-	HitCount     int64  `json:"hitCount"`
-	BlockedCount int64  `json:"blockedCount"`
-	AllowedCount int64  `json:"allowedCount"`
+	Time             string `json:"time"`
+	HitCount         int64  `json:"hitCount"`
+	BlockedCount     int64  `json:"blockedCount"`
+	AllowedCount     int64  `json:"allowedCount"`
+	RateLimitedCount int64  `json:"rateLimitedCount"`
 }
 
 type WafPolicyUpdateReq struct {
@@ -1015,6 +2139,61 @@ type WafPolicyUpdateReq struct {
 	Config                      string `json:"config,optional"` // JSON string
 }
 
+type WafRateLimitZoneItem struct {
+	ID            uint   `json:"id"`
+	PolicyId      uint   `json:"policyId"`
+	BindingId     uint   `json:"bindingId"`
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	Enabled       bool   `json:"enabled"`
+	KeyType       string `json:"keyType"`
+	KeyHeader     string `json:"keyHeader"`
+	Events        int64  `json:"events"`
+	WindowSeconds int64  `json:"windowSeconds"`
+	Burst         int64  `json:"burst"`
+	CreatedAt     string `json:"createdAt"`
+	UpdatedAt     string `json:"updatedAt"`
+}
+
+type WafRateLimitZoneListReq struct {
+	Page     int    `form:"page,optional"`
+	PageSize int    `form:"pageSize,optional"`
+	PolicyId uint   `form:"policyId,optional"`
+	Name     string `form:"name,optional"`
+}
+
+type WafRateLimitZoneListResp struct {
+	List  []WafRateLimitZoneItem `json:"list"`
+	Total int64                  `json:"total"`
+}
+
+type WafRateLimitZoneReq struct {
+	PolicyId      uint   `json:"policyId"`
+	BindingId     uint   `json:"bindingId,optional"` // 为空表示作用于整个策略
+	Name          string `json:"name"`
+	Description   string `json:"description,optional"`
+	Enabled       bool   `json:"enabled,optional"`
+	KeyType       string `json:"keyType,optional"`   // ip | header | path，默认 ip
+	KeyHeader     string `json:"keyHeader,optional"` // keyType=header 时必填
+	Events        int64  `json:"events"`
+	WindowSeconds int64  `json:"windowSeconds"`
+	Burst         int64  `json:"burst,optional"` // 突发余量，窗口内在 events 之上额外允许的请求数，0 表示无余量
+}
+
+type WafRateLimitZoneUpdateReq struct {
+	ID            uint   `path:"id"`
+	PolicyId      uint   `json:"policyId"`
+	BindingId     uint   `json:"bindingId,optional"`
+	Name          string `json:"name"`
+	Description   string `json:"description,optional"`
+	Enabled       bool   `json:"enabled"`
+	KeyType       string `json:"keyType,optional"`
+	KeyHeader     string `json:"keyHeader,optional"`
+	Events        int64  `json:"events"`
+	WindowSeconds int64  `json:"windowSeconds"`
+	Burst         int64  `json:"burst,optional"`
+}
+
 type WafReleaseActivateReq struct {
 	ID uint `path:"id"`
 }
@@ -1023,18 +2202,35 @@ type WafReleaseClearReq struct {
 	Kind string `json:"kind,optional"` // 仅允许 crs；为空默认 crs
 }
 
+type WafReleaseDiffReq struct {
+	ID     uint `path:"id"`
+	BaseId uint `form:"baseId,optional"` // 为空时与当前激活版本比较
+}
+
+type WafReleaseDiffResp struct {
+	Summary  WafRuleDiffSummary  `json:"summary"`
+	Added    []WafRuleDiffRule   `json:"added"`
+	Removed  []WafRuleDiffRule   `json:"removed"`
+	Modified []WafRuleDiffChange `json:"modified"`
+}
+
+type WafReleaseExportReq struct {
+	ID uint `path:"id"`
+}
+
 type WafReleaseItem struct {
-	ID           uint   `json:"id"`
-	SourceId     uint   `json:"sourceId"`
-	Kind         string `json:"kind"`
-	Version      string `json:"version"`
-	ArtifactType string `json:"artifactType"`
-	Checksum     string `json:"checksum"`
-	SizeBytes    int64  `json:"sizeBytes"`
-	StoragePath  string `json:"storagePath"`
-	Status       string `json:"status"`
-	CreatedAt    string `json:"createdAt"`
-	UpdatedAt    string `json:"updatedAt"`
+	ID           uint                `json:"id"`
+	SourceId     uint                `json:"sourceId"`
+	Kind         string              `json:"kind"`
+	Version      string              `json:"version"`
+	ArtifactType string              `json:"artifactType"`
+	Checksum     string              `json:"checksum"`
+	SizeBytes    int64               `json:"sizeBytes"`
+	StoragePath  string              `json:"storagePath"`
+	Status       string              `json:"status"`
+	RuleDiff     *WafRuleDiffSummary `json:"ruleDiff,omitempty"`
+	CreatedAt    string              `json:"createdAt"`
+	UpdatedAt    string              `json:"updatedAt"`
 }
 
 type WafReleaseListReq struct {
@@ -1054,20 +2250,56 @@ type WafReleaseRollbackReq struct {
 	Version string `json:"version,optional"`
 }
 
+type WafRuleDiffChange struct {
+	ID             int64    `json:"id"`
+	File           string   `json:"file"`
+	Msg            string   `json:"msg"`
+	Changes        []string `json:"changes"` // paranoia | tags | actions | logic
+	ParanoiaBefore int      `json:"paranoiaBefore"`
+	ParanoiaAfter  int      `json:"paranoiaAfter"`
+	TagsAdded      []string `json:"tagsAdded"`
+	TagsRemoved    []string `json:"tagsRemoved"`
+	ActionsAdded   []string `json:"actionsAdded"`
+	ActionsRemoved []string `json:"actionsRemoved"`
+}
+
+type WafRuleDiffRule struct {
+	ID            int64    `json:"id"`
+	File          string   `json:"file"`
+	Msg           string   `json:"msg"`
+	ParanoiaLevel int      `json:"paranoiaLevel"`
+	Tags          []string `json:"tags"`
+}
+
+type WafRuleDiffSummary struct {
+	BaseReleaseId   uint   `json:"baseReleaseId"`
+	BaseVersion     string `json:"baseVersion"`
+	TargetVersion   string `json:"targetVersion"`
+	BaseRuleCount   int    `json:"baseRuleCount"`
+	TargetRuleCount int    `json:"targetRuleCount"`
+	Added           int    `json:"added"`
+	Removed         int    `json:"removed"`
+	Modified        int    `json:"modified"`
+	ParanoiaChanged int    `json:"paranoiaChanged"`
+	Summary         string `json:"summary"`
+}
+
 type WafRuleExclusionItem struct {
-	ID          uint   `json:"id"`
-	PolicyId    uint   `json:"policyId"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Enabled     bool   `json:"enabled"`
-	ScopeType   string `json:"scopeType"`
-	Host        string `json:"host"`
-	Path        string `json:"path"`
-	Method      string `json:"method"`
-	RemoveType  string `json:"removeType"`
-	RemoveValue string `json:"removeValue"`
-	CreatedAt   string `json:"createdAt"`
-	UpdatedAt   string `json:"updatedAt"`
+	ID           uint   `json:"id"`
+	PolicyId     uint   `json:"policyId"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	Enabled      bool   `json:"enabled"`
+	ScopeType    string `json:"scopeType"`
+	Host         string `json:"host"`
+	Path         string `json:"path"`
+	Method       string `json:"method"`
+	RemoveType   string `json:"removeType"`
+	RemoveValue  string `json:"removeValue"`
+	RemoveTarget string `json:"removeTarget"`
+	FeedbackId   uint   `json:"feedbackId"` // 来源误报反馈，0 表示手工创建
+	CreatedAt    string `json:"createdAt"`
+	UpdatedAt    string `json:"updatedAt"`
 }
 
 type WafRuleExclusionListReq struct {
@@ -1084,30 +2316,60 @@ type WafRuleExclusionListResp struct {
 }
 
 type WafRuleExclusionReq struct {
-	PolicyId    uint   `json:"policyId"`
-	Name        string `json:"name,optional"`
-	Description string `json:"description,optional"`
-	Enabled     bool   `json:"enabled,optional"`
-	ScopeType   string `json:"scopeType,default=global"` // global | site | route
-	Host        string `json:"host,optional"`
-	Path        string `json:"path,optional"`
-	Method      string `json:"method,optional"`
-	RemoveType  string `json:"removeType,default=id"` // id | tag
-	RemoveValue string `json:"removeValue"`
+	PolicyId     uint   `json:"policyId"`
+	Name         string `json:"name,optional"`
+	Description  string `json:"description,optional"`
+	Enabled      bool   `json:"enabled,optional"`
+	ScopeType    string `json:"scopeType,default=global"` // global | site | route
+	Host         string `json:"host,optional"`
+	Path         string `json:"path,optional"`
+	Method       string `json:"method,optional"`
+	RemoveType   string `json:"removeType,default=id"` // id | tag | target_id | target_tag
+	RemoveValue  string `json:"removeValue"`
+	RemoveTarget string `json:"removeTarget,optional"` // 按目标移除时的检查目标，如 ARGS:content、REQUEST_HEADERS:/^x-/
 }
 
 type WafRuleExclusionUpdateReq struct {
-	ID          uint   `path:"id"`
-	PolicyId    uint   `json:"policyId"`
-	Name        string `json:"name,optional"`
-	Description string `json:"description,optional"`
-	Enabled     bool   `json:"enabled"`
-	ScopeType   string `json:"scopeType"` // global | site | route
-	Host        string `json:"host,optional"`
-	Path        string `json:"path,optional"`
-	Method      string `json:"method,optional"`
-	RemoveType  string `json:"removeType"` // id | tag
-	RemoveValue string `json:"removeValue"`
+	ID           uint   `path:"id"`
+	PolicyId     uint   `json:"policyId"`
+	Name         string `json:"name,optional"`
+	Description  string `json:"description,optional"`
+	Enabled      bool   `json:"enabled"`
+	ScopeType    string `json:"scopeType"` // global | site | route
+	Host         string `json:"host,optional"`
+	Path         string `json:"path,optional"`
+	Method       string `json:"method,optional"`
+	RemoveType   string `json:"removeType"` // id | tag | target_id | target_tag
+	RemoveValue  string `json:"removeValue"`
+	RemoveTarget string `json:"removeTarget,optional"` // 按目标移除时的检查目标，如 ARGS:content、REQUEST_HEADERS:/^x-/
+}
+
+type WafServerDeploymentItem struct {
+	ID         uint   `json:"id"`
+	JobId      uint   `json:"jobId"`
+	TargetType string `json:"targetType"`
+	TargetId   uint   `json:"targetId"`
+	ServerId   uint   `json:"serverId"`
+	ServerName string `json:"serverName"`
+	Status     string `json:"status"`
+	Message    string `json:"message"`
+	StartedAt  string `json:"startedAt"`
+	FinishedAt string `json:"finishedAt"`
+	CreatedAt  string `json:"createdAt"`
+}
+
+type WafServerDeploymentListReq struct {
+	Page       int    `form:"page,default=1"`
+	PageSize   int    `form:"pageSize,default=20"`
+	JobId      uint   `form:"jobId,optional"`
+	TargetType string `form:"targetType,optional"`
+	ServerId   uint   `form:"serverId,optional"`
+	Status     string `form:"status,optional"`
+}
+
+type WafServerDeploymentListResp struct {
+	List  []WafServerDeploymentItem `json:"list"`
+	Total int64                     `json:"total"`
 }
 
 type WafSourceActionReq struct {
@@ -1115,23 +2377,26 @@ type WafSourceActionReq struct {
 }
 
 type WafSourceItem struct {
-	ID           uint   `json:"id"`
-	Name         string `json:"name"`
-	Kind         string `json:"kind"`
-	Mode         string `json:"mode"`
-	Url          string `json:"url"`
-	ChecksumUrl  string `json:"checksumUrl"`
-	ProxyUrl     string `json:"proxyUrl,optional"`
-	AuthType     string `json:"authType"`
-	Schedule     string `json:"schedule"`
-	Enabled      bool   `json:"enabled"`
-	AutoCheck    bool   `json:"autoCheck"`
-	AutoDownload bool   `json:"autoDownload"`
-	AutoActivate bool   `json:"autoActivate"`
-	LastRelease  string `json:"lastRelease"`
-	LastError    string `json:"lastError"`
-	CreatedAt    string `json:"createdAt"`
-	UpdatedAt    string `json:"updatedAt"`
+	ID            uint   `json:"id"`
+	Name          string `json:"name"`
+	Kind          string `json:"kind"`
+	Mode          string `json:"mode"`
+	Url           string `json:"url"`
+	ChecksumUrl   string `json:"checksumUrl"`
+	ProxyUrl      string `json:"proxyUrl,optional"`
+	AuthType      string `json:"authType"`
+	SignatureType string `json:"signatureType"`
+	SignatureUrl  string `json:"signatureUrl"`
+	PublicKeys    string `json:"publicKeys"`
+	Schedule      string `json:"schedule"`
+	Enabled       bool   `json:"enabled"`
+	AutoCheck     bool   `json:"autoCheck"`
+	AutoDownload  bool   `json:"autoDownload"`
+	AutoActivate  bool   `json:"autoActivate"`
+	LastRelease   string `json:"lastRelease"`
+	LastError     string `json:"lastError"`
+	CreatedAt     string `json:"createdAt"`
+	UpdatedAt     string `json:"updatedAt"`
 }
 
 type WafSourceListReq struct {
@@ -1147,20 +2412,23 @@ type WafSourceListResp struct {
 }
 
 type WafSourceReq struct {
-	Name         string `json:"name"`
-	Kind         string `json:"kind,default=crs"`    // crs | coraza_engine
-	Mode         string `json:"mode,default=remote"` // remote | manual
-	Url          string `json:"url,optional"`
-	ChecksumUrl  string `json:"checksumUrl,optional"`
-	ProxyUrl     string `json:"proxyUrl,optional"`
-	AuthType     string `json:"authType,default=none"` // none | token | basic
-	AuthSecret   string `json:"authSecret,optional"`
-	Schedule     string `json:"schedule,optional"`
-	Enabled      bool   `json:"enabled,optional"`
-	AutoCheck    bool   `json:"autoCheck,optional"`
-	AutoDownload bool   `json:"autoDownload,optional"`
-	AutoActivate bool   `json:"autoActivate,optional"`
-	Meta         string `json:"meta,optional"` // JSON string
+	Name          string `json:"name"`
+	Kind          string `json:"kind,default=crs"`    // crs | coraza_engine
+	Mode          string `json:"mode,default=remote"` // remote | manual
+	Url           string `json:"url,optional"`
+	ChecksumUrl   string `json:"checksumUrl,optional"`
+	ProxyUrl      string `json:"proxyUrl,optional"`
+	AuthType      string `json:"authType,default=none"` // none | token | basic
+	AuthSecret    string `json:"authSecret,optional"`
+	SignatureType string `json:"signatureType,optional"` // none | minisign | gpg | cosign
+	SignatureUrl  string `json:"signatureUrl,optional"`  // 支持 {url} {version} 占位符
+	PublicKeys    string `json:"publicKeys,optional"`    // 受信公钥，可包含多把
+	Schedule      string `json:"schedule,optional"`
+	Enabled       bool   `json:"enabled,optional"`
+	AutoCheck     bool   `json:"autoCheck,optional"`
+	AutoDownload  bool   `json:"autoDownload,optional"`
+	AutoActivate  bool   `json:"autoActivate,optional"`
+	Meta          string `json:"meta,optional"` // JSON string
 }
 
 type WafSourceSyncReq struct {
@@ -1169,21 +2437,24 @@ type WafSourceSyncReq struct {
 }
 
 type WafSourceUpdateReq struct {
-	ID           uint   `path:"id"`
-	Name         string `json:"name,optional"`
-	Kind         string `json:"kind,optional"`
-	Mode         string `json:"mode,optional"`
-	Url          string `json:"url,optional"`
-	ChecksumUrl  string `json:"checksumUrl,optional"`
-	ProxyUrl     string `json:"proxyUrl,optional"`
-	AuthType     string `json:"authType,optional"`
-	AuthSecret   string `json:"authSecret,optional"`
-	Schedule     string `json:"schedule,optional"`
-	Enabled      bool   `json:"enabled,optional"`
-	AutoCheck    bool   `json:"autoCheck,optional"`
-	AutoDownload bool   `json:"autoDownload,optional"`
-	AutoActivate bool   `json:"autoActivate,optional"`
-	Meta         string `json:"meta,optional"` // JSON string
+	ID            uint   `path:"id"`
+	Name          string `json:"name,optional"`
+	Kind          string `json:"kind,optional"`
+	Mode          string `json:"mode,optional"`
+	Url           string `json:"url,optional"`
+	ChecksumUrl   string `json:"checksumUrl,optional"`
+	ProxyUrl      string `json:"proxyUrl,optional"`
+	AuthType      string `json:"authType,optional"`
+	AuthSecret    string `json:"authSecret,optional"`
+	SignatureType string `json:"signatureType,optional"` // none | minisign | gpg | cosign
+	SignatureUrl  string `json:"signatureUrl,optional"`  // 支持 {url} {version} 占位符
+	PublicKeys    string `json:"publicKeys,optional"`    // 受信公钥，可包含多把
+	Schedule      string `json:"schedule,optional"`
+	Enabled       bool   `json:"enabled,optional"`
+	AutoCheck     bool   `json:"autoCheck,optional"`
+	AutoDownload  bool   `json:"autoDownload,optional"`
+	AutoActivate  bool   `json:"autoActivate,optional"`
+	Meta          string `json:"meta,optional"` // JSON string
 }
 
 type WafUploadReq struct {
@@ -0,0 +1,230 @@
+// Package textdiff 提供按行比较文本并输出 unified diff 的能力。
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Op 行级编辑操作。
+type Op int
+
+const (
+	Equal Op = iota
+	Insert
+	Delete
+)
+
+// Line 编辑脚本中的一行。
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// Result 为 unified diff 文本及增删行数；两侧相同时 Unified 为空。
+type Result struct {
+	Unified string
+	Added   int
+	Removed int
+}
+
+// Identical 两侧文本是否一致。
+func (r Result) Identical() bool {
+	return r.Added == 0 && r.Removed == 0
+}
+
+// SplitLines 按行拆分文本，统一 CRLF，忽略末尾换行。
+func SplitLines(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// Lines 使用 Myers 算法计算 a 到 b 的最短编辑脚本。
+func Lines(a, b []string) []Line {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	lines := make([]Line, 0, len(a)+len(b)-prefix-suffix)
+	for _, text := range a[:prefix] {
+		lines = append(lines, Line{Op: Equal, Text: text})
+	}
+	lines = append(lines, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, text := range a[len(a)-suffix:] {
+		lines = append(lines, Line{Op: Equal, Text: text})
+	}
+	return lines
+}
+
+func myers(a, b []string) []Line {
+	n, m := len(a), len(b)
+	limit := n + m
+	if limit == 0 {
+		return nil
+	}
+
+	v := make([]int, 2*limit+2)
+	trace := make([][]int, 0, limit+1)
+search:
+	for d := 0; d <= limit; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[limit+k-1] < v[limit+k+1]) {
+				x = v[limit+k+1]
+			} else {
+				x = v[limit+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[limit+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// 自终点回溯，trace[d] 保存的是第 d 轮开始前（即 d-1 轮结束时）的状态
+	reversed := make([]Line, 0, n+m)
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[limit+k-1] < v[limit+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[limit+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			reversed = append(reversed, Line{Op: Equal, Text: a[x-1]})
+			x--
+			y--
+		}
+		if d == 0 {
+			break
+		}
+		if x == prevX {
+			reversed = append(reversed, Line{Op: Insert, Text: b[y-1]})
+		} else {
+			reversed = append(reversed, Line{Op: Delete, Text: a[x-1]})
+		}
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(reversed)-1; i < j; i, j = i+1, j-1 {
+		reversed[i], reversed[j] = reversed[j], reversed[i]
+	}
+	return reversed
+}
+
+// Unified 生成 fromName → toName 的 unified diff，context 为每个变更块保留的上下文行数。
+func Unified(fromName, toName, from, to string, context int) Result {
+	if context < 0 {
+		context = 0
+	}
+	lines := Lines(SplitLines(from), SplitLines(to))
+
+	result := Result{}
+	// oldBefore/newBefore[i] 为 lines[:i] 在两侧各占的行数，用于计算块头
+	oldBefore := make([]int, len(lines)+1)
+	newBefore := make([]int, len(lines)+1)
+	for i, line := range lines {
+		oldBefore[i+1], newBefore[i+1] = oldBefore[i], newBefore[i]
+		switch line.Op {
+		case Equal:
+			oldBefore[i+1]++
+			newBefore[i+1]++
+		case Delete:
+			oldBefore[i+1]++
+			result.Removed++
+		case Insert:
+			newBefore[i+1]++
+			result.Added++
+		}
+	}
+	if result.Identical() {
+		return result
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "--- %s\n+++ %s\n", fromName, toName)
+	for i := 0; i < len(lines); {
+		for i < len(lines) && lines[i].Op == Equal {
+			i++
+		}
+		if i >= len(lines) {
+			break
+		}
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for end < len(lines) {
+			if lines[end].Op != Equal {
+				end++
+				continue
+			}
+			run := end
+			for run < len(lines) && lines[run].Op == Equal {
+				run++
+			}
+			// 两处变更之间的相同行不超过 2*context 时合并为同一块
+			if run >= len(lines) || run-end > 2*context {
+				end += context
+				if end > len(lines) {
+					end = len(lines)
+				}
+				break
+			}
+			end = run
+		}
+
+		writeHunk(&buf, lines[start:end], oldBefore[start], oldBefore[end], newBefore[start], newBefore[end])
+		i = end
+	}
+	result.Unified = buf.String()
+	return result
+}
+
+func writeHunk(buf *strings.Builder, lines []Line, oldFrom, oldTo, newFrom, newTo int) {
+	fmt.Fprintf(buf, "@@ -%s +%s @@\n", hunkRange(oldFrom, oldTo-oldFrom), hunkRange(newFrom, newTo-newFrom))
+	for _, line := range lines {
+		switch line.Op {
+		case Equal:
+			buf.WriteString(" ")
+		case Delete:
+			buf.WriteString("-")
+		case Insert:
+			buf.WriteString("+")
+		}
+		buf.WriteString(line.Text)
+		buf.WriteString("\n")
+	}
+}
+
+// hunkRange 按 GNU diff 约定输出块范围：行数为 0 时起始行取块前一行
+func hunkRange(before, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", before+1)
+	}
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
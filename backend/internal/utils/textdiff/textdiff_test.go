@@ -0,0 +1,71 @@
+package textdiff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	from := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\n"
+	to := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\n"
+
+	result := Unified("a/Caddyfile", "b/Caddyfile", from, to, 3)
+	want := strings.Join([]string{
+		"--- a/Caddyfile",
+		"+++ b/Caddyfile",
+		"@@ -1,5 +1,5 @@",
+		" a",
+		"-b",
+		"+B",
+		" c",
+		" d",
+		" e",
+		"@@ -9,3 +9,4 @@",
+		" i",
+		" j",
+		" k",
+		"+l",
+		"",
+	}, "\n")
+	if result.Unified != want {
+		t.Fatalf("unexpected diff:\n%s", result.Unified)
+	}
+	if result.Added != 2 || result.Removed != 1 {
+		t.Fatalf("unexpected counts: +%d -%d", result.Added, result.Removed)
+	}
+
+	// 变更之间相同行不超过 2*context 时合并为一个块
+	merged := Unified("a", "b", "1\n2\n3\n4\n5\n", "1\nX\n3\n4\nY\n", 1)
+	if strings.Count(merged.Unified, "@@ -") != 1 || !strings.Contains(merged.Unified, "@@ -1,5 +1,5 @@") {
+		t.Fatalf("expected a single merged hunk:\n%s", merged.Unified)
+	}
+}
+
+func TestUnifiedEdgeCases(t *testing.T) {
+	if result := Unified("a", "b", "x\r\ny\n", "x\ny", 3); !result.Identical() || result.Unified != "" {
+		t.Fatalf("CRLF and trailing newline should be ignored: %+v", result)
+	}
+
+	created := Unified("a", "b", "", "x\ny\n", 3)
+	if !strings.Contains(created.Unified, "@@ -0,0 +1,2 @@\n+x\n+y\n") || created.Added != 2 {
+		t.Fatalf("unexpected diff for new file:\n%s", created.Unified)
+	}
+	removed := Unified("a", "b", "x\ny\nz\n", "x\n", 0)
+	if !strings.Contains(removed.Unified, "@@ -2,2 +1,0 @@\n-y\n-z\n") || removed.Removed != 2 {
+		t.Fatalf("unexpected diff for removed lines:\n%s", removed.Unified)
+	}
+}
+
+func TestLinesIsMinimal(t *testing.T) {
+	a := SplitLines("A\nB\nC\nA\nB\nB\nA")
+	b := SplitLines("C\nB\nA\nB\nA\nC")
+	edits := 0
+	for _, line := range Lines(a, b) {
+		if line.Op != Equal {
+			edits++
+		}
+	}
+	if edits != 5 {
+		t.Fatalf("expected shortest edit script of 5, got %d", edits)
+	}
+}
@@ -0,0 +1,422 @@
+package waf
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	BundleFormatVersion = 1
+	BundleManifestName  = "manifest.json"
+	BundlePackageName   = "package.tar.gz"
+	BundleChecksumsName = "SHA256SUMS"
+	BundleSignatureName = "SHA256SUMS.minisig"
+
+	maxBundleMetaBytes int64 = 1024 * 1024
+)
+
+// BundleManifest 离线规则包清单，随包一起签名
+type BundleManifest struct {
+	FormatVersion    int                    `json:"formatVersion"`
+	Kind             string                 `json:"kind"`
+	Version          string                 `json:"version"`
+	PackageSHA256    string                 `json:"packageSha256"`
+	PackageSizeBytes int64                  `json:"packageSizeBytes"`
+	OriginChecksum   string                 `json:"originChecksum,omitempty"`
+	ExportedAt       string                 `json:"exportedAt"`
+	ExportedBy       string                 `json:"exportedBy,omitempty"`
+	Meta             map[string]interface{} `json:"meta,omitempty"`
+}
+
+// OpenedBundle 校验通过的离线规则包，PackagePath 位于调用方提供的工作目录
+type OpenedBundle struct {
+	Manifest    BundleManifest
+	PackagePath string
+	Signature   *SignatureResult
+}
+
+// BundleSigner 本实例的离线包签名密钥，签名格式与 minisign 兼容
+type BundleSigner struct {
+	keyID      [minisignKeyIDBytes]byte
+	privateKey ed25519.PrivateKey
+}
+
+type bundleSignerFile struct {
+	KeyID string `json:"keyId"`
+	Seed  string `json:"seed"`
+}
+
+// LoadOrCreateBundleSigner 读取签名密钥，不存在时生成并以 0600 权限保存
+func LoadOrCreateBundleSigner(path string) (*BundleSigner, error) {
+	content, err := os.ReadFile(path)
+	if err == nil {
+		var stored bundleSignerFile
+		if err := json.Unmarshal(content, &stored); err != nil {
+			return nil, fmt.Errorf("离线包签名密钥格式无效: %w", err)
+		}
+		keyID, keyErr := hex.DecodeString(stored.KeyID)
+		seed, seedErr := base64.StdEncoding.DecodeString(stored.Seed)
+		if keyErr != nil || seedErr != nil || len(keyID) != minisignKeyIDBytes || len(seed) != ed25519.SeedSize {
+			return nil, fmt.Errorf("离线包签名密钥格式无效")
+		}
+		signer := &BundleSigner{privateKey: ed25519.NewKeyFromSeed(seed)}
+		copy(signer.keyID[:], keyID)
+		return signer, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("读取离线包签名密钥失败: %w", err)
+	}
+
+	signer := &BundleSigner{}
+	if _, err := rand.Read(signer.keyID[:]); err != nil {
+		return nil, fmt.Errorf("生成密钥 ID 失败: %w", err)
+	}
+	seed := make([]byte, ed25519.SeedSize)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, fmt.Errorf("生成签名密钥失败: %w", err)
+	}
+	signer.privateKey = ed25519.NewKeyFromSeed(seed)
+
+	encoded, _ := json.Marshal(bundleSignerFile{KeyID: hex.EncodeToString(signer.keyID[:]), Seed: base64.StdEncoding.EncodeToString(seed)})
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("创建密钥目录失败: %w", err)
+	}
+	if err := os.WriteFile(path, encoded, 0o600); err != nil {
+		return nil, fmt.Errorf("保存离线包签名密钥失败: %w", err)
+	}
+	return signer, nil
+}
+
+// KeyID 与 minisign 命令行显示的密钥 ID 一致
+func (signer *BundleSigner) KeyID() string {
+	return minisignKeyIDString(signer.keyID)
+}
+
+// PublicKey 返回 minisign 格式公钥，下游实例将其配置为受信公钥
+func (signer *BundleSigner) PublicKey() string {
+	blob := append(append([]byte(minisignAlgorithmLegacy), signer.keyID[:]...), signer.privateKey.Public().(ed25519.PublicKey)...)
+	return fmt.Sprintf("untrusted comment: logflux waf bundle key %s\n%s", signer.KeyID(), base64.StdEncoding.EncodeToString(blob))
+}
+
+// SignFile 以 minisign 预哈希模式签名文件，大文件无需整体读入内存
+func (signer *BundleSigner) SignFile(path, trustedComment string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开待签名文件失败: %w", err)
+	}
+	defer file.Close()
+
+	hasher, _ := blake2b.New512(nil)
+	if _, err := io.Copy(hasher, file); err != nil {
+		return nil, fmt.Errorf("读取待签名文件失败: %w", err)
+	}
+	sig := ed25519.Sign(signer.privateKey, hasher.Sum(nil))
+	trustedComment = strings.ReplaceAll(strings.TrimSpace(trustedComment), "\n", " ")
+	globalSig := ed25519.Sign(signer.privateKey, append(append([]byte{}, sig...), []byte(trustedComment)...))
+
+	return []byte(strings.Join([]string{
+		"untrusted comment: signature from logflux waf bundle key " + signer.KeyID(),
+		base64.StdEncoding.EncodeToString(append(append([]byte(minisignAlgorithmPrehashed), signer.keyID[:]...), sig...)),
+		minisignTrustedPrefix + trustedComment,
+		base64.StdEncoding.EncodeToString(globalSig),
+	}, "\n") + "\n"), nil
+}
+
+// PackDirectory 将已解压的版本目录重新打包为 tar.gz；条目排序且时间固定，同一目录产物的校验值稳定
+func PackDirectory(sourceDir, targetPath string) (sha string, sizeBytes int64, err error) {
+	files := make([]string, 0)
+	if err := filepath.WalkDir(sourceDir, func(path string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if entry.Type().IsRegular() {
+			files = append(files, path)
+		}
+		return nil
+	}); err != nil {
+		return "", 0, fmt.Errorf("遍历版本目录失败: %w", err)
+	}
+	if len(files) == 0 {
+		return "", 0, fmt.Errorf("版本目录为空")
+	}
+	sort.Strings(files)
+
+	tempPath := targetPath + ".tmp"
+	defer func() {
+		_ = os.Remove(tempPath)
+	}()
+	if err := writeTarGz(tempPath, func(writer *tar.Writer) error {
+		for _, path := range files {
+			relative, err := filepath.Rel(sourceDir, path)
+			if err != nil {
+				return err
+			}
+			if err := addTarFile(writer, filepath.ToSlash(relative), path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return "", 0, err
+	}
+	if err := os.Rename(tempPath, targetPath); err != nil {
+		return "", 0, fmt.Errorf("保存打包文件失败: %w", err)
+	}
+
+	sha, err = calculateFileSHA256(targetPath)
+	if err != nil {
+		return "", 0, err
+	}
+	info, err := os.Stat(targetPath)
+	if err != nil {
+		return "", 0, fmt.Errorf("读取打包文件失败: %w", err)
+	}
+	return sha, info.Size(), nil
+}
+
+// WriteBundle 生成离线规则包：清单、规则包、SHA256SUMS 以及对 SHA256SUMS 的签名
+func WriteBundle(targetPath string, manifest BundleManifest, packagePath string, signer *BundleSigner) error {
+	if signer == nil {
+		return fmt.Errorf("离线包签名密钥为空")
+	}
+	manifest.FormatVersion = BundleFormatVersion
+	manifestBytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化清单失败: %w", err)
+	}
+	manifestSum := sha256.Sum256(manifestBytes)
+	checksums := fmt.Sprintf("%x  %s\n%s  %s\n", manifestSum, BundleManifestName, manifest.PackageSHA256, BundlePackageName)
+
+	checksumsPath := targetPath + ".sums"
+	defer func() {
+		_ = os.Remove(checksumsPath)
+	}()
+	if err := os.WriteFile(checksumsPath, []byte(checksums), 0o644); err != nil {
+		return fmt.Errorf("写入校验文件失败: %w", err)
+	}
+	signature, err := signer.SignFile(checksumsPath, fmt.Sprintf("timestamp:%d\tkind:%s\tversion:%s", time.Now().Unix(), manifest.Kind, manifest.Version))
+	if err != nil {
+		return err
+	}
+
+	tempPath := targetPath + ".tmp"
+	defer func() {
+		_ = os.Remove(tempPath)
+	}()
+	if err := writeTarGz(tempPath, func(writer *tar.Writer) error {
+		if err := addTarBytes(writer, BundleManifestName, manifestBytes); err != nil {
+			return err
+		}
+		if err := addTarFile(writer, BundlePackageName, packagePath); err != nil {
+			return err
+		}
+		if err := addTarBytes(writer, BundleChecksumsName, []byte(checksums)); err != nil {
+			return err
+		}
+		return addTarBytes(writer, BundleSignatureName, signature)
+	}); err != nil {
+		return err
+	}
+	if err := os.Rename(tempPath, targetPath); err != nil {
+		return fmt.Errorf("保存离线包失败: %w", err)
+	}
+	return nil
+}
+
+// OpenBundle 解开离线规则包并校验签名与校验和；签名必须来自受信公钥，任何一步失败都拒绝导入
+func OpenBundle(bundlePath, workDir, trustedKeys string, maxPackageBytes int64) (*OpenedBundle, error) {
+	if strings.TrimSpace(trustedKeys) == "" {
+		return nil, fmt.Errorf("未配置离线包受信公钥")
+	}
+	if maxPackageBytes <= 0 {
+		maxPackageBytes = DefaultMaxPackageBytes
+	}
+	if err := os.MkdirAll(workDir, 0o755); err != nil {
+		return nil, fmt.Errorf("创建导入工作目录失败: %w", err)
+	}
+
+	bundleFile, err := os.Open(bundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("打开离线包失败: %w", err)
+	}
+	defer bundleFile.Close()
+	gzipReader, err := gzip.NewReader(bundleFile)
+	if err != nil {
+		return nil, fmt.Errorf("离线包不是有效的 tar.gz: %w", err)
+	}
+	defer gzipReader.Close()
+
+	entries := make(map[string][]byte)
+	packagePath := filepath.Join(workDir, BundlePackageName)
+	hasPackage := false
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("读取离线包条目失败: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg && header.Typeflag != tar.TypeRegA {
+			return nil, fmt.Errorf("离线包包含不支持的条目: %s", header.Name)
+		}
+		switch header.Name {
+		case BundlePackageName:
+			if header.Size > maxPackageBytes {
+				return nil, fmt.Errorf("离线包中的规则包过大: %d > %d", header.Size, maxPackageBytes)
+			}
+			if _, err := writeExtractedFile(packagePath, io.LimitReader(tarReader, maxPackageBytes)); err != nil {
+				return nil, err
+			}
+			hasPackage = true
+		case BundleManifestName, BundleChecksumsName, BundleSignatureName:
+			if header.Size > maxBundleMetaBytes {
+				return nil, fmt.Errorf("离线包条目过大: %s", header.Name)
+			}
+			content, err := io.ReadAll(io.LimitReader(tarReader, maxBundleMetaBytes))
+			if err != nil {
+				return nil, fmt.Errorf("读取离线包条目失败: %w", err)
+			}
+			entries[header.Name] = content
+		default:
+			return nil, fmt.Errorf("离线包包含未知条目: %s", header.Name)
+		}
+	}
+	for _, name := range []string{BundleManifestName, BundleChecksumsName, BundleSignatureName} {
+		if _, ok := entries[name]; !ok {
+			return nil, fmt.Errorf("离线包缺少 %s", name)
+		}
+	}
+	if !hasPackage {
+		return nil, fmt.Errorf("离线包缺少 %s", BundlePackageName)
+	}
+
+	checksumsPath := filepath.Join(workDir, BundleChecksumsName)
+	if err := os.WriteFile(checksumsPath, entries[BundleChecksumsName], 0o644); err != nil {
+		return nil, fmt.Errorf("写入校验文件失败: %w", err)
+	}
+	signatureResult, err := VerifySignature(checksumsPath, SignatureOptions{
+		Type:       SignatureTypeMinisign,
+		Signature:  entries[BundleSignatureName],
+		PublicKeys: trustedKeys,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("离线包签名校验失败: %w", err)
+	}
+
+	expected, err := parseChecksumFile(entries[BundleChecksumsName])
+	if err != nil {
+		return nil, err
+	}
+	manifestSum := sha256.Sum256(entries[BundleManifestName])
+	if expected[BundleManifestName] != hex.EncodeToString(manifestSum[:]) {
+		return nil, fmt.Errorf("离线包清单校验和不匹配")
+	}
+	packageSum, err := calculateFileSHA256(packagePath)
+	if err != nil {
+		return nil, err
+	}
+	if expected[BundlePackageName] != packageSum {
+		return nil, fmt.Errorf("离线包规则包校验和不匹配")
+	}
+
+	var manifest BundleManifest
+	if err := json.Unmarshal(entries[BundleManifestName], &manifest); err != nil {
+		return nil, fmt.Errorf("离线包清单格式无效: %w", err)
+	}
+	if manifest.FormatVersion != BundleFormatVersion {
+		return nil, fmt.Errorf("不支持的离线包格式版本: %d", manifest.FormatVersion)
+	}
+	if normalizeHash(manifest.PackageSHA256) != packageSum {
+		return nil, fmt.Errorf("离线包清单与规则包校验和不一致")
+	}
+	if strings.TrimSpace(manifest.Version) == "" {
+		return nil, fmt.Errorf("离线包清单缺少版本号")
+	}
+
+	return &OpenedBundle{Manifest: manifest, PackagePath: packagePath, Signature: signatureResult}, nil
+}
+
+func parseChecksumFile(content []byte) (map[string]string, error) {
+	result := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(string(content)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("SHA256SUMS 格式无效")
+		}
+		result[strings.TrimPrefix(fields[1], "*")] = normalizeHash(fields[0])
+	}
+	return result, nil
+}
+
+func writeTarGz(targetPath string, write func(writer *tar.Writer) error) error {
+	targetFile, err := os.OpenFile(targetPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("创建归档文件失败: %w", err)
+	}
+	gzipWriter := gzip.NewWriter(targetFile)
+	tarWriter := tar.NewWriter(gzipWriter)
+	writeErr := write(tarWriter)
+	if err := tarWriter.Close(); err != nil && writeErr == nil {
+		writeErr = err
+	}
+	if err := gzipWriter.Close(); err != nil && writeErr == nil {
+		writeErr = err
+	}
+	if err := targetFile.Close(); err != nil && writeErr == nil {
+		writeErr = err
+	}
+	if writeErr != nil {
+		return fmt.Errorf("写入归档文件失败: %w", writeErr)
+	}
+	return nil
+}
+
+func addTarFile(writer *tar.Writer, name, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if err := writer.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: info.Size(), ModTime: time.Unix(0, 0), Typeflag: tar.TypeReg}); err != nil {
+		return err
+	}
+	_, err = io.Copy(writer, file)
+	return err
+}
+
+func addTarBytes(writer *tar.Writer, name string, content []byte) error {
+	if err := writer.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), ModTime: time.Unix(0, 0), Typeflag: tar.TypeReg}); err != nil {
+		return err
+	}
+	_, err := writer.Write(content)
+	return err
+}
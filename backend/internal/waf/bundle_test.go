@@ -0,0 +1,171 @@
+package waf
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPackDirectoryIsDeterministic(t *testing.T) {
+	sourceDir := t.TempDir()
+	writeRuleFile(t, sourceDir, "rules/REQUEST-920.conf", `SecRule ARGS "@rx a" "id:920100,deny"`)
+	writeRuleFile(t, sourceDir, "crs-setup.conf", "SecDefaultAction \"phase:1,log\"")
+
+	first, firstSize, err := PackDirectory(sourceDir, filepath.Join(t.TempDir(), "a.tar.gz"))
+	if err != nil {
+		t.Fatalf("PackDirectory() error = %v", err)
+	}
+	second, _, err := PackDirectory(sourceDir, filepath.Join(t.TempDir(), "b.tar.gz"))
+	if err != nil {
+		t.Fatalf("PackDirectory() error = %v", err)
+	}
+	if first != second || firstSize <= 0 {
+		t.Fatalf("expected stable checksum, got %s and %s", first, second)
+	}
+
+	if _, _, err := PackDirectory(t.TempDir(), filepath.Join(t.TempDir(), "empty.tar.gz")); err == nil {
+		t.Fatalf("expected empty directory to be rejected")
+	}
+}
+
+func TestBundleRoundTrip(t *testing.T) {
+	baseDir := t.TempDir()
+	sourceDir := filepath.Join(baseDir, "release")
+	writeRuleFile(t, sourceDir, "rules/REQUEST-920.conf", `SecRule ARGS "@rx a" "id:920100,deny"`)
+	packagePath := filepath.Join(baseDir, "package.tar.gz")
+	sha, size, err := PackDirectory(sourceDir, packagePath)
+	if err != nil {
+		t.Fatalf("PackDirectory() error = %v", err)
+	}
+
+	keyPath := filepath.Join(baseDir, "keys", "bundle.key")
+	signer, err := LoadOrCreateBundleSigner(keyPath)
+	if err != nil {
+		t.Fatalf("LoadOrCreateBundleSigner() error = %v", err)
+	}
+	reloaded, err := LoadOrCreateBundleSigner(keyPath)
+	if err != nil || reloaded.PublicKey() != signer.PublicKey() {
+		t.Fatalf("expected signer to be persisted, err=%v", err)
+	}
+	if info, err := os.Stat(keyPath); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected key file with 0600 permission, got %v, %v", info, err)
+	}
+
+	bundlePath := filepath.Join(baseDir, "bundle.tar.gz")
+	manifest := BundleManifest{Kind: "crs", Version: "v4.7.0", PackageSHA256: sha, PackageSizeBytes: size, ExportedBy: "edge-1"}
+	if err := WriteBundle(bundlePath, manifest, packagePath, signer); err != nil {
+		t.Fatalf("WriteBundle() error = %v", err)
+	}
+
+	opened, err := OpenBundle(bundlePath, filepath.Join(baseDir, "import"), signer.PublicKey(), 0)
+	if err != nil {
+		t.Fatalf("OpenBundle() error = %v", err)
+	}
+	if opened.Manifest.Version != "v4.7.0" || opened.Manifest.ExportedBy != "edge-1" || opened.Signature.Signer != "minisign:"+signer.KeyID() {
+		t.Fatalf("unexpected bundle: %+v signature=%+v", opened.Manifest, opened.Signature)
+	}
+	if _, err := ExtractPackage(opened.PackagePath, filepath.Join(baseDir, "extracted"), ExtractOptions{}); err != nil {
+		t.Fatalf("ExtractPackage() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(baseDir, "extracted", "rules", "REQUEST-920.conf")); err != nil {
+		t.Fatalf("expected rule file in extracted package: %v", err)
+	}
+
+	otherSigner, err := LoadOrCreateBundleSigner(filepath.Join(baseDir, "other.key"))
+	if err != nil {
+		t.Fatalf("LoadOrCreateBundleSigner() error = %v", err)
+	}
+	if _, err := OpenBundle(bundlePath, filepath.Join(baseDir, "import2"), otherSigner.PublicKey(), 0); err == nil || !strings.Contains(err.Error(), "签名") {
+		t.Fatalf("expected untrusted signer to be rejected, got %v", err)
+	}
+	if _, err := OpenBundle(bundlePath, filepath.Join(baseDir, "import3"), "", 0); err == nil {
+		t.Fatalf("expected missing trusted keys to be rejected")
+	}
+}
+
+func TestOpenBundleRejectsTamperedPackage(t *testing.T) {
+	baseDir := t.TempDir()
+	sourceDir := filepath.Join(baseDir, "release")
+	writeRuleFile(t, sourceDir, "a.conf", `SecRule ARGS "@rx a" "id:1,deny"`)
+	packagePath := filepath.Join(baseDir, "package.tar.gz")
+	sha, size, err := PackDirectory(sourceDir, packagePath)
+	if err != nil {
+		t.Fatalf("PackDirectory() error = %v", err)
+	}
+	signer, err := LoadOrCreateBundleSigner(filepath.Join(baseDir, "bundle.key"))
+	if err != nil {
+		t.Fatalf("LoadOrCreateBundleSigner() error = %v", err)
+	}
+	bundlePath := filepath.Join(baseDir, "bundle.tar.gz")
+	if err := WriteBundle(bundlePath, BundleManifest{Kind: "crs", Version: "v1", PackageSHA256: sha, PackageSizeBytes: size}, packagePath, signer); err != nil {
+		t.Fatalf("WriteBundle() error = %v", err)
+	}
+
+	// 替换包内规则包但保留签名与校验文件
+	tamperedPath := filepath.Join(baseDir, "tampered.tar.gz")
+	rewriteBundleEntry(t, bundlePath, tamperedPath, BundlePackageName, []byte("tampered"))
+	if _, err := OpenBundle(tamperedPath, filepath.Join(baseDir, "import"), signer.PublicKey(), 0); err == nil || !strings.Contains(err.Error(), "校验和不匹配") {
+		t.Fatalf("expected tampered package to be rejected, got %v", err)
+	}
+}
+
+func rewriteBundleEntry(t *testing.T, sourcePath, targetPath, name string, content []byte) {
+	t.Helper()
+	sourceFile, err := os.Open(sourcePath)
+	if err != nil {
+		t.Fatalf("open bundle failed: %v", err)
+	}
+	defer sourceFile.Close()
+	gzipReader, err := gzip.NewReader(sourceFile)
+	if err != nil {
+		t.Fatalf("open gzip failed: %v", err)
+	}
+	tarReader := tar.NewReader(gzipReader)
+
+	if err := writeTarGz(targetPath, func(writer *tar.Writer) error {
+		for {
+			header, err := tarReader.Next()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			entry, err := io.ReadAll(tarReader)
+			if err != nil {
+				return err
+			}
+			if header.Name == name {
+				entry = content
+			}
+			if err := addTarBytes(writer, header.Name, entry); err != nil {
+				return err
+			}
+		}
+	}); err != nil {
+		t.Fatalf("rewrite bundle failed: %v", err)
+	}
+}
+
+func TestParseMirrorManifest(t *testing.T) {
+	content := []byte(`{"code":0,"msg":"成功","data":{"kind":"crs","version":"v4.7.0","pinned":true,"packageSha256":"abc",` +
+		`"packageUrl":"/api/waf/mirror/crs/v4.7.0/package.tar.gz","signatureUrl":"/api/waf/mirror/crs/v4.7.0/package.tar.gz.minisig"}}`)
+	manifest, err := parseMirrorManifest(content, "https://upstream.example.com/api/waf/mirror/crs/manifest")
+	if err != nil {
+		t.Fatalf("parseMirrorManifest() error = %v", err)
+	}
+	if manifest.PackageURL != "https://upstream.example.com/api/waf/mirror/crs/v4.7.0/package.tar.gz" || !manifest.Pinned {
+		t.Fatalf("unexpected manifest: %+v", manifest)
+	}
+	if manifest.SignatureURL != manifest.PackageURL+".minisig" || manifest.BundleURL != "" {
+		t.Fatalf("unexpected signature url: %+v", manifest)
+	}
+
+	if _, err := parseMirrorManifest([]byte(`{"code":404,"msg":"镜像暂无可用版本"}`), "https://upstream.example.com/m"); err == nil || !strings.Contains(err.Error(), "镜像暂无可用版本") {
+		t.Fatalf("expected upstream message in error, got %v", err)
+	}
+}
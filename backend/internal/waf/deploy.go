@@ -0,0 +1,300 @@
+package waf
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const (
+	DeployStatusSuccess    = "success"
+	DeployStatusFailed     = "failed"
+	DeployStatusSkipped    = "skipped"
+	DeployStatusRolledBack = "rolled_back"
+)
+
+// ErrDeploySkipped 顺序分发遇到失败并要求终止时，后续未执行的节点返回该错误
+var ErrDeploySkipped = errors.New("前序节点失败，已跳过")
+
+type DeployOptions struct {
+	Parallel      bool
+	StopOnFailure bool
+	// OnResult 每个节点得到最终状态时回调；并行模式下会被并发调用
+	OnResult func(index int, result DeployResult)
+}
+
+type DeployTarget struct {
+	// ServerID 决定节点独立的 current-<serverID> 链接
+	ServerID uint
+	Config   string
+	Loader   CaddyLoader
+	// Render 按节点自身的 current 链接路径渲染配置，为空时直接使用 Config
+	Render func(currentLink string) string
+}
+
+type DeployResult struct {
+	Status string
+	Err    error
+}
+
+// RunDeploySteps 对 indexes 中的节点执行 step，返回与 indexes 一一对应的错误。
+// 顺序模式下遇到失败且 StopOnFailure 时，剩余节点返回 ErrDeploySkipped；
+// 并行模式下所有节点同时执行，是否整体回滚由调用方根据结果决定
+func RunDeploySteps(indexes []int, options DeployOptions, step func(index int) error) []error {
+	errs := make([]error, len(indexes))
+	if !options.Parallel {
+		stopped := false
+		for position, index := range indexes {
+			if stopped {
+				errs[position] = ErrDeploySkipped
+				continue
+			}
+			errs[position] = step(index)
+			if errs[position] != nil && options.StopOnFailure {
+				stopped = true
+			}
+		}
+		return errs
+	}
+
+	var wg sync.WaitGroup
+	for position, index := range indexes {
+		wg.Add(1)
+		go func(position, index int) {
+			defer wg.Done()
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					errs[position] = fmt.Errorf("节点执行异常: %v", recovered)
+				}
+			}()
+			errs[position] = step(index)
+		}(position, index)
+	}
+	wg.Wait()
+	return errs
+}
+
+// DeployVersion 逐节点切换各自的 current-<serverID> 链接并加载按该链接渲染的 Caddy 配置，
+// 节点之间不共享发布路径。失败节点的链接立即切回原目标；开启 StopOnFailure 时任一节点失败
+// 即把所有已切换节点切回并重载上一版；未开启时仅记录失败节点，所有节点都失败时同样整体回滚。
+// 至少一个节点成功后共享 current 链接才指向新版本，仅用于展示当前版本与保留清理
+func (activator *Activator) DeployVersion(version string, targets []DeployTarget, options DeployOptions) ([]DeployResult, error) {
+	if activator == nil || activator.Store == nil {
+		return nil, fmt.Errorf("激活器存储为空")
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("分发目标节点为空")
+	}
+	for _, target := range targets {
+		if target.Loader == nil {
+			return nil, fmt.Errorf("Caddy 加载器为空")
+		}
+	}
+
+	lock := activator.Lock
+	if lock == nil {
+		lock = &defaultActivateLock
+	}
+	lock.Lock()
+	defer lock.Unlock()
+
+	store := activator.Store
+	releaseDir := store.ReleaseDir(version)
+	if !dirExists(releaseDir) {
+		return nil, fmt.Errorf("发布目录不存在: %s", releaseDir)
+	}
+
+	links := make([]deployLink, len(targets))
+	configs := make([]string, len(targets))
+	sharedTarget, _ := store.LinkTarget(store.CurrentLinkPath())
+	for index, target := range targets {
+		linkPath := store.ServerCurrentLinkPath(target.ServerID)
+		previous, err := store.LinkTarget(linkPath)
+		if err != nil || previous == "" {
+			// 尚未按节点拆分链接的旧部署沿用共享 current 的目标
+			previous = sharedTarget
+		}
+		links[index] = deployLink{path: linkPath, previous: previous}
+		configs[index] = target.Config
+		if target.Render != nil {
+			configs[index] = target.Render(linkPath)
+		}
+	}
+
+	results := make([]DeployResult, len(targets))
+	report := func(index int, status string, err error) {
+		results[index] = DeployResult{Status: status, Err: err}
+		if options.OnResult != nil {
+			options.OnResult(index, results[index])
+		}
+	}
+
+	all := make([]int, len(targets))
+	for index := range targets {
+		all[index] = index
+	}
+
+	// 校验阶段：全部节点先切换各自链接并适配，适配失败的节点立即切回
+	adaptErrs := RunDeploySteps(all, options, func(index int) error {
+		if err := store.SetLink(links[index].path, releaseDir); err != nil {
+			return fmt.Errorf("设置节点 current 链接失败: %w", err)
+		}
+		links[index].switched = true
+		if err := targets[index].Loader.Adapt(configs[index]); err != nil {
+			if restoreErr := links[index].restore(store); restoreErr != nil {
+				return fmt.Errorf("%v，恢复节点 current 链接失败: %v", err, restoreErr)
+			}
+			return err
+		}
+		return nil
+	})
+	loadable := make([]int, 0, len(targets))
+	adaptFailed := 0
+	for position, index := range all {
+		switch err := adaptErrs[position]; {
+		case err == nil:
+			loadable = append(loadable, index)
+		case errors.Is(err, ErrDeploySkipped):
+			report(index, DeployStatusSkipped, err)
+		default:
+			adaptFailed++
+			report(index, DeployStatusFailed, fmt.Errorf("适配失败: %w", err))
+		}
+	}
+	if adaptFailed > 0 && options.StopOnFailure || len(loadable) == 0 {
+		var restoreFailures []string
+		for _, index := range loadable {
+			if err := links[index].restore(store); err != nil {
+				restoreFailures = append(restoreFailures, err.Error())
+			}
+			report(index, DeployStatusSkipped, ErrDeploySkipped)
+		}
+		if len(restoreFailures) > 0 {
+			return results, fmt.Errorf("%d 个节点适配失败，恢复节点 current 链接失败: %s", adaptFailed, strings.Join(restoreFailures, "; "))
+		}
+		return results, fmt.Errorf("%d 个节点适配失败，已终止分发", adaptFailed)
+	}
+
+	loadErrs := RunDeploySteps(loadable, options, func(index int) error {
+		return targets[index].Loader.Load(configs[index])
+	})
+	attempted := make([]int, 0, len(loadable))
+	loadFailed := 0
+	succeeded := 0
+	for position, index := range loadable {
+		switch err := loadErrs[position]; {
+		case err == nil:
+			succeeded++
+			attempted = append(attempted, index)
+		case errors.Is(err, ErrDeploySkipped):
+			if restoreErr := links[index].restore(store); restoreErr != nil {
+				err = fmt.Errorf("%v，恢复节点 current 链接失败: %v", err, restoreErr)
+			}
+			report(index, DeployStatusSkipped, err)
+		default:
+			loadFailed++
+			attempted = append(attempted, index)
+			results[index] = DeployResult{Status: DeployStatusFailed, Err: fmt.Errorf("加载失败: %w", err)}
+		}
+	}
+
+	failed := adaptFailed + loadFailed
+	if failed > 0 && options.StopOnFailure || succeeded == 0 {
+		rollbackErr := rollbackDeployTargets(store, links, targets, configs, attempted, results, report)
+		if rollbackErr != nil {
+			return results, fmt.Errorf("%d 个节点激活失败，回滚失败: %v", failed, rollbackErr)
+		}
+		return results, fmt.Errorf("%d 个节点激活失败，已回滚到上一版", failed)
+	}
+
+	for _, index := range attempted {
+		if results[index].Status == DeployStatusFailed {
+			// 加载失败的节点仍运行旧配置，只需把链接切回原目标
+			err := results[index].Err
+			if restoreErr := links[index].restore(store); restoreErr != nil {
+				err = fmt.Errorf("%v，恢复节点 current 链接失败: %v", err, restoreErr)
+			}
+			report(index, DeployStatusFailed, err)
+			continue
+		}
+		if links[index].previous != "" {
+			if err := store.SetLink(store.ServerLastGoodLinkPath(targets[index].ServerID), links[index].previous); err != nil {
+				report(index, DeployStatusSuccess, fmt.Errorf("设置节点 last_good 链接失败: %w", err))
+				continue
+			}
+		}
+		report(index, DeployStatusSuccess, nil)
+	}
+	if err := switchSharedCurrentLink(store, sharedTarget, releaseDir); err != nil {
+		return results, err
+	}
+	if failed > 0 {
+		return results, fmt.Errorf("%d/%d 个节点激活失败", failed, len(targets))
+	}
+	return results, nil
+}
+
+// deployLink 记录单个节点 current 链接在本次分发前的目标，用于失败时精确切回
+type deployLink struct {
+	path     string
+	previous string
+	switched bool
+}
+
+func (link *deployLink) restore(store *Store) error {
+	if !link.switched {
+		return nil
+	}
+	link.switched = false
+	if strings.TrimSpace(link.previous) == "" {
+		return store.RemoveLink(link.path)
+	}
+	return store.SetLink(link.path, link.previous)
+}
+
+// switchSharedCurrentLink 在至少一个节点成功后更新共享的 current/last_good 链接
+func switchSharedCurrentLink(store *Store, previousTarget, releaseDir string) error {
+	if strings.TrimSpace(previousTarget) != "" && filepath.Clean(previousTarget) != filepath.Clean(releaseDir) {
+		if err := store.SetLink(store.LastGoodLinkPath(), previousTarget); err != nil {
+			return fmt.Errorf("设置 last_good 链接失败: %w", err)
+		}
+	}
+	if err := store.SetLink(store.CurrentLinkPath(), releaseDir); err != nil {
+		return fmt.Errorf("设置 current 链接失败: %w", err)
+	}
+	return nil
+}
+
+// rollbackDeployTargets 把尝试过加载的节点链接切回原目标并重载，成功节点标记为已回滚
+func rollbackDeployTargets(store *Store, links []deployLink, targets []DeployTarget, configs []string, attempted []int, results []DeployResult, report func(int, string, error)) error {
+	var failures []string
+	for _, index := range attempted {
+		if err := links[index].restore(store); err != nil {
+			failures = append(failures, err.Error())
+			report(index, DeployStatusFailed, fmt.Errorf("恢复节点 current 链接失败: %w", err))
+			continue
+		}
+		loadErr := targets[index].Loader.Load(configs[index])
+		if results[index].Status == DeployStatusFailed {
+			if loadErr != nil {
+				failures = append(failures, loadErr.Error())
+				report(index, DeployStatusFailed, fmt.Errorf("%v，重载上一版失败: %v", results[index].Err, loadErr))
+				continue
+			}
+			report(index, DeployStatusFailed, fmt.Errorf("%v，已重载上一版", results[index].Err))
+			continue
+		}
+		if loadErr != nil {
+			failures = append(failures, loadErr.Error())
+			report(index, DeployStatusFailed, fmt.Errorf("重载上一版配置失败: %w", loadErr))
+			continue
+		}
+		report(index, DeployStatusRolledBack, fmt.Errorf("其他节点失败，已回滚到上一版"))
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("回滚失败: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
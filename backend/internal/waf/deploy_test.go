@@ -0,0 +1,119 @@
+package waf
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunDeployStepsStopsSequentialRollout(t *testing.T) {
+	calls := make([]int, 0, 3)
+	errs := RunDeploySteps([]int{0, 1, 2}, DeployOptions{StopOnFailure: true}, func(index int) error {
+		calls = append(calls, index)
+		if index == 1 {
+			return fmt.Errorf("load failed")
+		}
+		return nil
+	})
+	if len(calls) != 2 || errs[0] != nil || errs[1] == nil || !errors.Is(errs[2], ErrDeploySkipped) {
+		t.Fatalf("unexpected sequential result: calls=%v errs=%v", calls, errs)
+	}
+
+	errs = RunDeploySteps([]int{0, 1, 2}, DeployOptions{Parallel: true, StopOnFailure: true}, func(index int) error {
+		if index == 1 {
+			panic("boom")
+		}
+		return nil
+	})
+	if errs[0] != nil || errs[1] == nil || errs[2] != nil {
+		t.Fatalf("unexpected parallel result: %v", errs)
+	}
+}
+
+func TestDeployVersionRollsBackAllServersOnFailure(t *testing.T) {
+	store, oldReleaseDir, _ := prepareActivatorStore(t)
+	loaders := []*mockCaddyLoader{{}, {loadErr: fmt.Errorf("load failed")}, {}}
+	targets := make([]DeployTarget, len(loaders))
+	for index, loader := range loaders {
+		targets[index] = DeployTarget{ServerID: uint(index + 1), Config: "config", Loader: loader}
+	}
+
+	reported := map[int]string{}
+	results, err := (&Activator{Store: store}).DeployVersion("v4.23.1", targets, DeployOptions{
+		StopOnFailure: true,
+		OnResult: func(index int, result DeployResult) {
+			reported[index] = result.Status
+		},
+	})
+	if err == nil {
+		t.Fatalf("expected deploy error")
+	}
+	if results[0].Status != DeployStatusRolledBack || results[1].Status != DeployStatusFailed || results[2].Status != DeployStatusSkipped {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	if reported[0] != DeployStatusRolledBack || reported[2] != DeployStatusSkipped {
+		t.Fatalf("unexpected reported statuses: %v", reported)
+	}
+	// 第一台加载新版本后回滚重载一次，第三台从未加载
+	if loaders[0].loadCalls != 2 || loaders[2].loadCalls != 0 {
+		t.Fatalf("unexpected load calls: %d %d", loaders[0].loadCalls, loaders[2].loadCalls)
+	}
+
+	currentTarget, err := store.LinkTarget(store.CurrentLinkPath())
+	if err != nil || currentTarget != filepath.Clean(oldReleaseDir) {
+		t.Fatalf("expected current link restored, got %s err=%v", currentTarget, err)
+	}
+	// 包括被跳过的节点在内，所有节点链接都不能停留在新版本
+	for serverID := uint(1); serverID <= 3; serverID++ {
+		serverTarget, err := store.LinkTarget(store.ServerCurrentLinkPath(serverID))
+		if err != nil || serverTarget != filepath.Clean(oldReleaseDir) {
+			t.Fatalf("expected server %d link restored, got %s err=%v", serverID, serverTarget, err)
+		}
+	}
+}
+
+func TestDeployVersionKeepsPartialSuccessWithoutStop(t *testing.T) {
+	store, oldReleaseDir, newReleaseDir := prepareActivatorStore(t)
+	rendered := make([]string, 3)
+	render := func(index int) func(string) string {
+		return func(currentLink string) string {
+			rendered[index] = currentLink
+			return "Include " + currentLink + "/rules/*.conf"
+		}
+	}
+	targets := []DeployTarget{
+		{ServerID: 1, Config: "a", Loader: &mockCaddyLoader{adaptErr: fmt.Errorf("adapt failed")}, Render: render(0)},
+		{ServerID: 2, Config: "b", Loader: &mockCaddyLoader{}, Render: render(1)},
+		{ServerID: 3, Config: "c", Loader: &mockCaddyLoader{}, Render: render(2)},
+	}
+
+	results, err := (&Activator{Store: store}).DeployVersion("v4.23.1", targets, DeployOptions{Parallel: true})
+	if err == nil {
+		t.Fatalf("expected partial failure error")
+	}
+	if results[0].Status != DeployStatusFailed || results[1].Status != DeployStatusSuccess || results[2].Status != DeployStatusSuccess {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+
+	currentTarget, err := store.LinkTarget(store.CurrentLinkPath())
+	if err != nil || currentTarget != filepath.Clean(newReleaseDir) {
+		t.Fatalf("expected current link on new release, got %s err=%v", currentTarget, err)
+	}
+
+	// 适配失败的节点链接切回旧版本，其余节点各自指向新版本
+	expected := map[uint]string{1: oldReleaseDir, 2: newReleaseDir, 3: newReleaseDir}
+	for serverID, want := range expected {
+		serverTarget, err := store.LinkTarget(store.ServerCurrentLinkPath(serverID))
+		if err != nil || serverTarget != filepath.Clean(want) {
+			t.Fatalf("unexpected server %d link: %s err=%v", serverID, serverTarget, err)
+		}
+		if rendered[serverID-1] != store.ServerCurrentLinkPath(serverID) {
+			t.Fatalf("expected server %d config rendered against own link, got %s", serverID, rendered[serverID-1])
+		}
+	}
+	lastGood, err := store.LinkTarget(store.ServerLastGoodLinkPath(2))
+	if err != nil || lastGood != filepath.Clean(oldReleaseDir) {
+		t.Fatalf("expected server last_good on old release, got %s err=%v", lastGood, err)
+	}
+}
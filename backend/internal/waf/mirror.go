@@ -0,0 +1,80 @@
+package waf
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const maxMirrorManifestBytes int64 = 1024 * 1024
+
+// MirrorManifest 上游实例镜像接口返回的版本清单，地址可为相对路径
+type MirrorManifest struct {
+	Kind             string `json:"kind"`
+	Version          string `json:"version"`
+	Pinned           bool   `json:"pinned"`
+	PackageSHA256    string `json:"packageSha256"`
+	PackageSizeBytes int64  `json:"packageSizeBytes"`
+	PackageURL       string `json:"packageUrl"`
+	SignatureURL     string `json:"signatureUrl"`
+	SignatureType    string `json:"signatureType"`
+	BundleURL        string `json:"bundleUrl"`
+	KeyID            string `json:"keyId"`
+}
+
+type mirrorManifestEnvelope struct {
+	Code int            `json:"code"`
+	Msg  string         `json:"msg"`
+	Data MirrorManifest `json:"data"`
+}
+
+// FetchMirrorManifest 拉取上游镜像清单，沿用包下载的 HTTPS、域名白名单与认证校验，并把相对地址解析为绝对地址
+func FetchMirrorManifest(manifestURL, tempPath string, options FetchOptions) (*MirrorManifest, error) {
+	options.MaxBytes = maxMirrorManifestBytes
+	defer func() {
+		_ = os.Remove(tempPath)
+	}()
+	if _, err := FetchPackage(manifestURL, tempPath, options); err != nil {
+		return nil, fmt.Errorf("获取镜像清单失败: %w", err)
+	}
+	content, err := os.ReadFile(tempPath)
+	if err != nil {
+		return nil, fmt.Errorf("读取镜像清单失败: %w", err)
+	}
+	return parseMirrorManifest(content, manifestURL)
+}
+
+func parseMirrorManifest(content []byte, manifestURL string) (*MirrorManifest, error) {
+	var envelope mirrorManifestEnvelope
+	if err := json.Unmarshal(content, &envelope); err != nil {
+		return nil, fmt.Errorf("镜像清单格式无效: %w", err)
+	}
+	manifest := envelope.Data
+	if envelope.Code != 0 {
+		return nil, fmt.Errorf("上游镜像返回错误(%d): %s", envelope.Code, strings.TrimSpace(envelope.Msg))
+	}
+	if strings.TrimSpace(manifest.Version) == "" || strings.TrimSpace(manifest.PackageURL) == "" {
+		if msg := strings.TrimSpace(envelope.Msg); msg != "" {
+			return nil, fmt.Errorf("镜像清单无可用版本: %s", msg)
+		}
+		return nil, fmt.Errorf("镜像清单无可用版本")
+	}
+
+	base, err := url.Parse(manifestURL)
+	if err != nil {
+		return nil, fmt.Errorf("URL 无效: %w", err)
+	}
+	for _, target := range []*string{&manifest.PackageURL, &manifest.SignatureURL, &manifest.BundleURL} {
+		if strings.TrimSpace(*target) == "" {
+			continue
+		}
+		resolved, err := base.Parse(strings.TrimSpace(*target))
+		if err != nil {
+			return nil, fmt.Errorf("镜像清单地址无效: %w", err)
+		}
+		*target = resolved.String()
+	}
+	return &manifest, nil
+}
@@ -0,0 +1,208 @@
+package waf
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/corazawaf/coraza/v3"
+)
+
+// 对应 coraza.conf-recommended 中与请求体解析相关的规则；回放时不依赖 Caddy 内置的 @ 虚拟文件系统
+const replayRecommendedDirectives = `SecRule REQUEST_HEADERS:Content-Type "^(?:application(?:/soap\+|/)|text/)xml" "id:200000,phase:1,t:none,t:lowercase,pass,nolog,ctl:requestBodyProcessor=XML"
+SecRule REQUEST_HEADERS:Content-Type "^application/json" "id:200001,phase:1,t:none,t:lowercase,pass,nolog,ctl:requestBodyProcessor=JSON"
+SecRule REQBODY_ERROR "!@eq 0" "id:200002,phase:2,t:none,log,deny,status:400,msg:'Failed to parse request body.'"`
+
+// CRSLayout 已激活 CRS 版本在磁盘上的文件布局
+type CRSLayout struct {
+	SetupFile string
+	RulesDir  string
+}
+
+// LocateCRSLayout 在 CRS 版本目录（含一层打包目录）中定位 crs-setup.conf.example 与 rules 目录
+func LocateCRSLayout(releaseDir string) (*CRSLayout, error) {
+	releaseDir = strings.TrimSpace(releaseDir)
+	if releaseDir == "" {
+		return nil, fmt.Errorf("CRS 版本目录为空")
+	}
+
+	candidates := []string{releaseDir}
+	entries, err := os.ReadDir(releaseDir)
+	if err != nil {
+		return nil, fmt.Errorf("读取 CRS 版本目录失败: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			candidates = append(candidates, filepath.Join(releaseDir, entry.Name()))
+		}
+	}
+
+	for _, dir := range candidates {
+		rulesDir := filepath.Join(dir, "rules")
+		if !dirExists(rulesDir) {
+			continue
+		}
+		layout := &CRSLayout{RulesDir: rulesDir}
+		for _, name := range []string{"crs-setup.conf", "crs-setup.conf.example"} {
+			if fileInfo, statErr := os.Stat(filepath.Join(dir, name)); statErr == nil && !fileInfo.IsDir() {
+				layout.SetupFile = filepath.Join(dir, name)
+				break
+			}
+		}
+		if layout.SetupFile == "" {
+			continue
+		}
+		return layout, nil
+	}
+	return nil, fmt.Errorf("CRS 版本目录中未找到 crs-setup.conf 与 rules 目录: %s", releaseDir)
+}
+
+// ResolveReplayIncludes 将 Caddy coraza 模块的 @ 内置文件替换为本地 CRS 文件，并关闭审计日志写入；
+// layout 为空时去掉 CRS 引用，仅回放策略自身指令
+func ResolveReplayIncludes(directives string, layout *CRSLayout) string {
+	lines := make([]string, 0, strings.Count(directives, "\n")+4)
+	for _, line := range strings.Split(directives, "\n") {
+		trimmed := strings.TrimSpace(line)
+		lower := strings.ToLower(trimmed)
+		switch {
+		case strings.HasPrefix(lower, "include @coraza.conf"):
+			lines = append(lines, replayRecommendedDirectives)
+		case strings.HasPrefix(lower, "include @crs-setup.conf"):
+			if layout != nil {
+				lines = append(lines, "Include "+layout.SetupFile)
+			}
+		case strings.HasPrefix(lower, "include @owasp_crs/"):
+			if layout != nil {
+				lines = append(lines, "Include "+filepath.Join(layout.RulesDir, "*.conf"))
+			}
+		case strings.HasPrefix(lower, "secauditlog "), strings.HasPrefix(lower, "secauditengine "), strings.HasPrefix(lower, "secdebuglog "):
+			continue
+		default:
+			lines = append(lines, line)
+		}
+	}
+	lines = append(lines, "SecAuditEngine Off")
+	return strings.Join(lines, "\n")
+}
+
+// ReplayRequest 回放用的单个 HTTP 请求
+type ReplayRequest struct {
+	Ref        string
+	Method     string
+	Host       string
+	URI        string
+	Proto      string
+	RemoteAddr string
+	Headers    map[string][]string
+	Body       string
+}
+
+// ReplayVerdict 单个请求的评估结果
+type ReplayVerdict struct {
+	Blocked         bool
+	Status          int
+	InterruptRuleID int
+	MatchedRuleIDs  []int
+}
+
+// ReplayEngine 嵌入式 Coraza 实例，只评估请求阶段（phase 1/2）
+type ReplayEngine struct {
+	waf coraza.WAF
+}
+
+func NewReplayEngine(directives string) (*ReplayEngine, error) {
+	instance, err := coraza.NewWAF(coraza.NewWAFConfig().WithDirectives(directives))
+	if err != nil {
+		return nil, fmt.Errorf("加载回放指令失败: %w", err)
+	}
+	return &ReplayEngine{waf: instance}, nil
+}
+
+func (engine *ReplayEngine) Evaluate(request ReplayRequest) ReplayVerdict {
+	tx := engine.waf.NewTransaction()
+	defer func() {
+		_ = tx.Close()
+	}()
+
+	clientIP, clientPort := splitReplayRemoteAddr(request.RemoteAddr)
+	tx.ProcessConnection(clientIP, clientPort, "", 0)
+
+	method := strings.ToUpper(strings.TrimSpace(request.Method))
+	if method == "" {
+		method = "GET"
+	}
+	uri := strings.TrimSpace(request.URI)
+	if uri == "" {
+		uri = "/"
+	}
+	proto := strings.TrimSpace(request.Proto)
+	if proto == "" {
+		proto = "HTTP/1.1"
+	}
+	tx.ProcessURI(uri, method, proto)
+
+	hasHost := false
+	for key, values := range request.Headers {
+		if strings.EqualFold(key, "host") {
+			hasHost = true
+		}
+		for _, value := range values {
+			tx.AddRequestHeader(key, value)
+		}
+	}
+	if host := strings.TrimSpace(request.Host); host != "" {
+		tx.SetServerName(host)
+		if !hasHost {
+			tx.AddRequestHeader("Host", host)
+		}
+	}
+
+	verdict := ReplayVerdict{}
+	interruption := tx.ProcessRequestHeaders()
+	if interruption == nil && request.Body != "" {
+		if bodyInterruption, _, err := tx.WriteRequestBody([]byte(request.Body)); err == nil {
+			interruption = bodyInterruption
+		}
+	}
+	if interruption == nil {
+		interruption, _ = tx.ProcessRequestBody()
+	}
+
+	if interruption != nil {
+		verdict.Blocked = true
+		verdict.Status = interruption.Status
+		verdict.InterruptRuleID = interruption.RuleID
+	}
+
+	seen := map[int]struct{}{}
+	for _, matched := range tx.MatchedRules() {
+		id := matched.Rule().ID()
+		if id == 0 {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		verdict.MatchedRuleIDs = append(verdict.MatchedRuleIDs, id)
+	}
+	sort.Ints(verdict.MatchedRuleIDs)
+	return verdict
+}
+
+func splitReplayRemoteAddr(remoteAddr string) (string, int) {
+	remoteAddr = strings.TrimSpace(remoteAddr)
+	if remoteAddr == "" {
+		return "127.0.0.1", 0
+	}
+	host, port, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr, 0
+	}
+	portNumber, _ := strconv.Atoi(port)
+	return host, portNumber
+}
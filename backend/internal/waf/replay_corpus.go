@@ -0,0 +1,241 @@
+package waf
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	ReplayCorpusFormatAuto   = "auto"
+	ReplayCorpusFormatHAR    = "har"
+	ReplayCorpusFormatNDJSON = "ndjson"
+)
+
+type harDocument struct {
+	Log struct {
+		Entries []struct {
+			Request struct {
+				Method      string `json:"method"`
+				URL         string `json:"url"`
+				HTTPVersion string `json:"httpVersion"`
+				Headers     []struct {
+					Name  string `json:"name"`
+					Value string `json:"value"`
+				} `json:"headers"`
+				PostData *struct {
+					Text string `json:"text"`
+				} `json:"postData"`
+			} `json:"request"`
+			ServerIPAddress string `json:"serverIPAddress"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+// ndjsonRequest 每行一个请求；同时兼容 Caddy 访问日志（request 字段）
+type ndjsonRequest struct {
+	Method     string          `json:"method"`
+	URI        string          `json:"uri"`
+	URL        string          `json:"url"`
+	Host       string          `json:"host"`
+	Proto      string          `json:"proto"`
+	RemoteAddr string          `json:"remoteAddr"`
+	Headers    json.RawMessage `json:"headers"`
+	Body       string          `json:"body"`
+	Request    *struct {
+		RemoteIP   string              `json:"remote_ip"`
+		RemotePort string              `json:"remote_port"`
+		ClientIP   string              `json:"client_ip"`
+		Proto      string              `json:"proto"`
+		Method     string              `json:"method"`
+		Host       string              `json:"host"`
+		URI        string              `json:"uri"`
+		Headers    map[string][]string `json:"headers"`
+	} `json:"request"`
+}
+
+// ParseReplayCorpus 解析上传的 HAR 或 NDJSON 语料，最多返回 limit 条（limit<=0 不限制）
+func ParseReplayCorpus(data []byte, format string, limit int) ([]ReplayRequest, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("回放语料为空")
+	}
+
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" || format == ReplayCorpusFormatAuto {
+		format = ReplayCorpusFormatNDJSON
+		var probe struct {
+			Log json.RawMessage `json:"log"`
+		}
+		if trimmed[0] == '{' && json.Unmarshal(trimmed, &probe) == nil && len(probe.Log) > 0 {
+			format = ReplayCorpusFormatHAR
+		}
+	}
+
+	var (
+		requests []ReplayRequest
+		err      error
+	)
+	switch format {
+	case ReplayCorpusFormatHAR:
+		requests, err = parseReplayHAR(trimmed, limit)
+	case ReplayCorpusFormatNDJSON:
+		requests, err = parseReplayNDJSON(trimmed, limit)
+	default:
+		return nil, fmt.Errorf("回放语料格式不支持: %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("回放语料中没有可用的请求")
+	}
+	return requests, nil
+}
+
+func parseReplayHAR(data []byte, limit int) ([]ReplayRequest, error) {
+	var document harDocument
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("解析 HAR 失败: %w", err)
+	}
+
+	requests := make([]ReplayRequest, 0, len(document.Log.Entries))
+	for index, entry := range document.Log.Entries {
+		if limit > 0 && len(requests) >= limit {
+			break
+		}
+		parsed, err := url.Parse(strings.TrimSpace(entry.Request.URL))
+		if err != nil || parsed.Host == "" {
+			continue
+		}
+		headers := make(map[string][]string, len(entry.Request.Headers))
+		for _, header := range entry.Request.Headers {
+			// HTTP/2 伪头部不是真实请求头
+			if strings.HasPrefix(header.Name, ":") {
+				continue
+			}
+			headers[header.Name] = append(headers[header.Name], header.Value)
+		}
+		request := ReplayRequest{
+			Ref:     fmt.Sprintf("har#%d", index+1),
+			Method:  entry.Request.Method,
+			Host:    parsed.Host,
+			URI:     parsed.RequestURI(),
+			Proto:   normalizeReplayProto(entry.Request.HTTPVersion),
+			Headers: headers,
+		}
+		if entry.Request.PostData != nil {
+			request.Body = entry.Request.PostData.Text
+		}
+		requests = append(requests, request)
+	}
+	return requests, nil
+}
+
+func parseReplayNDJSON(data []byte, limit int) ([]ReplayRequest, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 8*1024*1024)
+
+	requests := make([]ReplayRequest, 0)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if limit > 0 && len(requests) >= limit {
+			break
+		}
+
+		var item ndjsonRequest
+		if err := json.Unmarshal(line, &item); err != nil {
+			return nil, fmt.Errorf("解析 NDJSON 第 %d 行失败: %w", lineNumber, err)
+		}
+
+		request := ReplayRequest{Ref: fmt.Sprintf("line#%d", lineNumber)}
+		if item.Request != nil {
+			request.Method = item.Request.Method
+			request.Host = item.Request.Host
+			request.URI = item.Request.URI
+			request.Proto = item.Request.Proto
+			request.Headers = item.Request.Headers
+			remoteIP := firstNonEmpty(item.Request.ClientIP, item.Request.RemoteIP)
+			if remoteIP != "" && item.Request.RemotePort != "" {
+				request.RemoteAddr = remoteIP + ":" + item.Request.RemotePort
+			} else {
+				request.RemoteAddr = remoteIP
+			}
+		} else {
+			request.Method = item.Method
+			request.Host = item.Host
+			request.URI = item.URI
+			request.Proto = item.Proto
+			request.RemoteAddr = item.RemoteAddr
+			request.Body = item.Body
+			request.Headers = decodeReplayHeaders(item.Headers)
+			if request.URI == "" && item.URL != "" {
+				if parsed, err := url.Parse(item.URL); err == nil {
+					request.URI = parsed.RequestURI()
+					if request.Host == "" {
+						request.Host = parsed.Host
+					}
+				}
+			}
+		}
+		if strings.TrimSpace(request.URI) == "" {
+			return nil, fmt.Errorf("NDJSON 第 %d 行缺少 uri", lineNumber)
+		}
+		requests = append(requests, request)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取 NDJSON 失败: %w", err)
+	}
+	return requests, nil
+}
+
+// decodeReplayHeaders 兼容 {"k":"v"} 与 {"k":["v1","v2"]} 两种写法
+func decodeReplayHeaders(raw json.RawMessage) map[string][]string {
+	if len(raw) == 0 {
+		return nil
+	}
+	var multi map[string][]string
+	if err := json.Unmarshal(raw, &multi); err == nil {
+		return multi
+	}
+	var single map[string]string
+	if err := json.Unmarshal(raw, &single); err != nil {
+		return nil
+	}
+	headers := make(map[string][]string, len(single))
+	for key, value := range single {
+		headers[key] = []string{value}
+	}
+	return headers
+}
+
+func normalizeReplayProto(version string) string {
+	version = strings.ToUpper(strings.TrimSpace(version))
+	switch version {
+	case "", "UNKNOWN":
+		return "HTTP/1.1"
+	case "H2", "HTTP/2.0":
+		return "HTTP/2"
+	case "H3", "HTTP/3.0":
+		return "HTTP/3"
+	default:
+		return version
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if trimmed := strings.TrimSpace(value); trimmed != "" {
+			return trimmed
+		}
+	}
+	return ""
+}
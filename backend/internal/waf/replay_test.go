@@ -0,0 +1,149 @@
+package waf
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReplayEngineEvaluate(t *testing.T) {
+	engine, err := NewReplayEngine(strings.Join([]string{
+		"SecRuleEngine On",
+		"SecRequestBodyAccess On",
+		`SecRule REQUEST_HEADERS:User-Agent "@streq scanner" "id:1002,phase:1,pass,log"`,
+		`SecRule ARGS:q "@contains attack" "id:1001,phase:1,deny,status:403,log"`,
+		`SecRule ARGS_POST:comment "@contains drop" "id:1003,phase:2,deny,status:403,log"`,
+	}, "\n"))
+	if err != nil {
+		t.Fatalf("NewReplayEngine error: %v", err)
+	}
+
+	verdict := engine.Evaluate(ReplayRequest{
+		Method:  "GET",
+		Host:    "app.example.com",
+		URI:     "/search?q=attack",
+		Headers: map[string][]string{"User-Agent": {"scanner"}},
+	})
+	if !verdict.Blocked || verdict.Status != 403 || verdict.InterruptRuleID != 1001 {
+		t.Fatalf("expected block by 1001, got %+v", verdict)
+	}
+	if !reflect.DeepEqual(verdict.MatchedRuleIDs, []int{1001, 1002}) {
+		t.Fatalf("unexpected matched rules: %v", verdict.MatchedRuleIDs)
+	}
+
+	verdict = engine.Evaluate(ReplayRequest{
+		Method:  "POST",
+		URI:     "/comments",
+		Headers: map[string][]string{"Content-Type": {"application/x-www-form-urlencoded"}},
+		Body:    "comment=drop+table",
+	})
+	if !verdict.Blocked || verdict.InterruptRuleID != 1003 {
+		t.Fatalf("expected body block by 1003, got %+v", verdict)
+	}
+
+	verdict = engine.Evaluate(ReplayRequest{URI: "/search?q=hello"})
+	if verdict.Blocked || len(verdict.MatchedRuleIDs) != 0 {
+		t.Fatalf("expected clean request to pass, got %+v", verdict)
+	}
+}
+
+func TestResolveReplayIncludes(t *testing.T) {
+	directives := strings.Join([]string{
+		"Include @coraza.conf-recommended",
+		"Include @crs-setup.conf.example",
+		"SecRuleEngine On",
+		"SecAuditEngine RelevantOnly",
+		"SecAuditLog /var/log/caddy/waf_audit.log",
+		"Include @owasp_crs/*.conf",
+	}, "\n")
+
+	resolved := ResolveReplayIncludes(directives, &CRSLayout{SetupFile: "/crs/crs-setup.conf.example", RulesDir: "/crs/rules"})
+	for _, fragment := range []string{"id:200001", "Include /crs/crs-setup.conf.example", "Include /crs/rules/*.conf", "SecAuditEngine Off"} {
+		if !strings.Contains(resolved, fragment) {
+			t.Fatalf("expected %q in resolved directives: %s", fragment, resolved)
+		}
+	}
+	if strings.Contains(resolved, "Include @") || strings.Contains(resolved, "SecAuditLog ") || strings.Contains(resolved, "RelevantOnly") {
+		t.Fatalf("unexpected leftovers in resolved directives: %s", resolved)
+	}
+
+	withoutCRS := ResolveReplayIncludes(directives, nil)
+	if strings.Contains(withoutCRS, "Include") {
+		t.Fatalf("expected CRS includes to be dropped: %s", withoutCRS)
+	}
+}
+
+func TestLocateCRSLayout(t *testing.T) {
+	releaseDir := t.TempDir()
+	packageDir := filepath.Join(releaseDir, "coreruleset-4.0.0")
+	if err := os.MkdirAll(filepath.Join(packageDir, "rules"), 0o755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(packageDir, "crs-setup.conf.example"), []byte("# setup"), 0o644); err != nil {
+		t.Fatalf("write setup failed: %v", err)
+	}
+
+	layout, err := LocateCRSLayout(releaseDir)
+	if err != nil {
+		t.Fatalf("LocateCRSLayout error: %v", err)
+	}
+	if layout.RulesDir != filepath.Join(packageDir, "rules") || layout.SetupFile != filepath.Join(packageDir, "crs-setup.conf.example") {
+		t.Fatalf("unexpected layout: %+v", layout)
+	}
+
+	if _, err := LocateCRSLayout(t.TempDir()); err == nil {
+		t.Fatalf("expected error for empty release dir")
+	}
+}
+
+func TestParseReplayCorpus(t *testing.T) {
+	har := `{"log":{"entries":[
+		{"request":{"method":"POST","url":"https://app.example.com/api/posts?draft=1","httpVersion":"h2",
+			"headers":[{"name":":authority","value":"app.example.com"},{"name":"Content-Type","value":"application/json"}],
+			"postData":{"text":"{\"content\":\"hi\"}"}}},
+		{"request":{"method":"GET","url":"not a url"}}
+	]}}`
+	requests, err := ParseReplayCorpus([]byte(har), "", 0)
+	if err != nil {
+		t.Fatalf("parse HAR error: %v", err)
+	}
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 HAR request, got %d", len(requests))
+	}
+	first := requests[0]
+	if first.Method != "POST" || first.Host != "app.example.com" || first.URI != "/api/posts?draft=1" || first.Proto != "HTTP/2" || first.Body == "" {
+		t.Fatalf("unexpected HAR request: %+v", first)
+	}
+	if _, ok := first.Headers[":authority"]; ok {
+		t.Fatalf("pseudo headers should be skipped: %v", first.Headers)
+	}
+
+	ndjson := strings.Join([]string{
+		`{"method":"GET","url":"https://shop.example.com/search?q=1","headers":{"User-Agent":"curl"}}`,
+		``,
+		`{"level":"info","request":{"remote_ip":"203.0.113.9","remote_port":"5100","method":"GET","host":"shop.example.com","uri":"/cart","proto":"HTTP/1.1","headers":{"Accept":["*/*"]}}}`,
+		`{"method":"GET","uri":"/third"}`,
+	}, "\n")
+	requests, err = ParseReplayCorpus([]byte(ndjson), "auto", 2)
+	if err != nil {
+		t.Fatalf("parse NDJSON error: %v", err)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("expected limit to cap requests at 2, got %d", len(requests))
+	}
+	if requests[0].Host != "shop.example.com" || requests[0].URI != "/search?q=1" || requests[0].Headers["User-Agent"][0] != "curl" {
+		t.Fatalf("unexpected generic NDJSON request: %+v", requests[0])
+	}
+	if requests[1].RemoteAddr != "203.0.113.9:5100" || requests[1].URI != "/cart" || requests[1].Ref != "line#3" {
+		t.Fatalf("unexpected caddy log NDJSON request: %+v", requests[1])
+	}
+
+	if _, err := ParseReplayCorpus([]byte(`{"method":"GET"}`), "ndjson", 0); err == nil {
+		t.Fatalf("expected error for NDJSON line without uri")
+	}
+	if _, err := ParseReplayCorpus([]byte("  "), "", 0); err == nil {
+		t.Fatalf("expected error for empty corpus")
+	}
+}
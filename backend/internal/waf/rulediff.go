@@ -0,0 +1,458 @@
+package waf
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// 规则包内 .conf 文件总大小上限，避免异常目录拖垮解析
+	maxRuleDirBytes int64 = 64 * 1024 * 1024
+
+	ruleChangeParanoia = "paranoia"
+	ruleChangeTags     = "tags"
+	ruleChangeActions  = "actions"
+	ruleChangeLogic    = "logic"
+)
+
+var (
+	regexParanoiaTag    = regexp.MustCompile(`^paranoia-level/(\d+)$`)
+	regexParanoiaMarker = regexp.MustCompile(`(?i)^TX:(DETECTION_|BLOCKING_)?PARANOIA_LEVEL$`)
+	regexParanoiaLt     = regexp.MustCompile(`^@lt\s+(\d+)$`)
+
+	// 版本号类动作每次发版都会变化，比较规则时忽略
+	ruleDiffIgnoredActions = map[string]bool{"ver": true, "rev": true}
+	// 单独比较的动作，不计入 Actions
+	ruleDiffMetaActions = map[string]bool{"id": true, "msg": true, "tag": true, "logdata": true, "ver": true, "rev": true}
+)
+
+// RuleInfo 规则包中一条带 id 的规则，链式规则合并到首条规则
+type RuleInfo struct {
+	ID            int64
+	File          string
+	Msg           string
+	Phase         string
+	ParanoiaLevel int
+	Tags          []string
+	Actions       []string
+	canonical     string
+}
+
+// RuleChange 同一 id 在两个版本间的差异
+type RuleChange struct {
+	ID             int64
+	File           string
+	Msg            string
+	Changes        []string
+	ParanoiaBefore int
+	ParanoiaAfter  int
+	TagsAdded      []string
+	TagsRemoved    []string
+	ActionsAdded   []string
+	ActionsRemoved []string
+}
+
+// RuleSetDiff 两个版本规则集的语义差异
+type RuleSetDiff struct {
+	BaseRuleCount   int
+	TargetRuleCount int
+	Added           []RuleInfo
+	Removed         []RuleInfo
+	Modified        []RuleChange
+}
+
+// ParanoiaChangedCount 统计调整了 Paranoia Level 的规则数
+func (diff *RuleSetDiff) ParanoiaChangedCount() int {
+	if diff == nil {
+		return 0
+	}
+	count := 0
+	for _, change := range diff.Modified {
+		if slices.Contains(change.Changes, ruleChangeParanoia) {
+			count++
+		}
+	}
+	return count
+}
+
+// Summary 生成一句话摘要，用于通知与任务记录
+func (diff *RuleSetDiff) Summary() string {
+	if diff == nil {
+		return ""
+	}
+	if len(diff.Added) == 0 && len(diff.Removed) == 0 && len(diff.Modified) == 0 {
+		return fmt.Sprintf("规则无变化（共 %d 条）", diff.TargetRuleCount)
+	}
+	summary := fmt.Sprintf("新增 %d 条、移除 %d 条、修改 %d 条规则", len(diff.Added), len(diff.Removed), len(diff.Modified))
+	if paranoiaChanged := diff.ParanoiaChangedCount(); paranoiaChanged > 0 {
+		summary += fmt.Sprintf("，其中 %d 条调整了 Paranoia Level", paranoiaChanged)
+	}
+	return summary
+}
+
+// DiffRuleDirs 解析两个已解压版本目录中的 .conf 规则并比较
+func DiffRuleDirs(baseDir, targetDir string) (*RuleSetDiff, error) {
+	baseRules, err := ParseRuleDir(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	targetRules, err := ParseRuleDir(targetDir)
+	if err != nil {
+		return nil, err
+	}
+	return DiffRuleSets(baseRules, targetRules), nil
+}
+
+// DiffRuleSets 按规则 id 比较两个规则集，结果按 id 升序
+func DiffRuleSets(base, target map[int64]RuleInfo) *RuleSetDiff {
+	diff := &RuleSetDiff{
+		BaseRuleCount:   len(base),
+		TargetRuleCount: len(target),
+		Added:           []RuleInfo{},
+		Removed:         []RuleInfo{},
+		Modified:        []RuleChange{},
+	}
+	for id, rule := range target {
+		previous, ok := base[id]
+		if !ok {
+			diff.Added = append(diff.Added, rule)
+			continue
+		}
+		if change, changed := compareRules(previous, rule); changed {
+			diff.Modified = append(diff.Modified, change)
+		}
+	}
+	for id, rule := range base {
+		if _, ok := target[id]; !ok {
+			diff.Removed = append(diff.Removed, rule)
+		}
+	}
+
+	sort.Slice(diff.Added, func(i, j int) bool { return diff.Added[i].ID < diff.Added[j].ID })
+	sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i].ID < diff.Removed[j].ID })
+	sort.Slice(diff.Modified, func(i, j int) bool { return diff.Modified[i].ID < diff.Modified[j].ID })
+	return diff
+}
+
+func compareRules(before, after RuleInfo) (RuleChange, bool) {
+	change := RuleChange{
+		ID:             after.ID,
+		File:           after.File,
+		Msg:            after.Msg,
+		ParanoiaBefore: before.ParanoiaLevel,
+		ParanoiaAfter:  after.ParanoiaLevel,
+	}
+	if before.ParanoiaLevel != after.ParanoiaLevel {
+		change.Changes = append(change.Changes, ruleChangeParanoia)
+	}
+	change.TagsAdded, change.TagsRemoved = diffStringSets(before.Tags, after.Tags)
+	if len(change.TagsAdded) > 0 || len(change.TagsRemoved) > 0 {
+		change.Changes = append(change.Changes, ruleChangeTags)
+	}
+	change.ActionsAdded, change.ActionsRemoved = diffStringSets(before.Actions, after.Actions)
+	if len(change.ActionsAdded) > 0 || len(change.ActionsRemoved) > 0 {
+		change.Changes = append(change.Changes, ruleChangeActions)
+	}
+	if len(change.Changes) == 0 && before.canonical != after.canonical {
+		change.Changes = append(change.Changes, ruleChangeLogic)
+	}
+	return change, len(change.Changes) > 0
+}
+
+func diffStringSets(before, after []string) (added, removed []string) {
+	for _, item := range after {
+		if !slices.Contains(before, item) {
+			added = append(added, item)
+		}
+	}
+	for _, item := range before {
+		if !slices.Contains(after, item) {
+			removed = append(removed, item)
+		}
+	}
+	return added, removed
+}
+
+// ParseRuleDir 递归解析目录下的 .conf 文件；同一 id 重复出现时以后解析的为准
+func ParseRuleDir(dir string) (map[int64]RuleInfo, error) {
+	if strings.TrimSpace(dir) == "" {
+		return nil, fmt.Errorf("规则目录为空")
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取规则目录失败: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("规则路径不是目录: %s", dir)
+	}
+
+	files := make([]string, 0)
+	var totalBytes int64
+	if err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if entry.IsDir() || !strings.HasSuffix(strings.ToLower(entry.Name()), ".conf") {
+			return nil
+		}
+		fileInfo, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		totalBytes += fileInfo.Size()
+		if totalBytes > maxRuleDirBytes {
+			return fmt.Errorf("规则文件总大小超过上限 %d", maxRuleDirBytes)
+		}
+		files = append(files, path)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("遍历规则目录失败: %w", err)
+	}
+	sort.Strings(files)
+
+	rules := make(map[int64]RuleInfo)
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("读取规则文件失败: %w", err)
+		}
+		relative, err := filepath.Rel(dir, file)
+		if err != nil {
+			relative = filepath.Base(file)
+		}
+		for _, rule := range ParseRules(filepath.ToSlash(relative), string(content)) {
+			rules[rule.ID] = rule
+		}
+	}
+	return rules, nil
+}
+
+// ParseRules 解析单个规则文件中的 SecRule/SecAction；未标注 paranoia-level 标签的规则
+// 沿用 CRS 的分段方式，按最近一条 "TX:DETECTION_PARANOIA_LEVEL @lt N" 推断级别
+func ParseRules(file, content string) []RuleInfo {
+	rules := make([]RuleInfo, 0)
+	sectionParanoia := 1
+	chaining := false
+	var current *RuleInfo
+
+	flush := func() {
+		if current != nil && current.ID > 0 {
+			sort.Strings(current.Tags)
+			sort.Strings(current.Actions)
+			rules = append(rules, *current)
+		}
+		current = nil
+	}
+
+	for _, directive := range splitSecLangDirectives(content) {
+		args := splitSecLangArgs(directive)
+		if len(args) == 0 {
+			continue
+		}
+		name := strings.ToLower(args[0])
+		var variables, operator, actionText string
+		switch name {
+		case "secrule":
+			if len(args) < 3 {
+				continue
+			}
+			variables, operator = args[1], args[2]
+			if len(args) > 3 {
+				actionText = args[3]
+			}
+		case "secaction":
+			if len(args) > 1 {
+				actionText = args[1]
+			}
+		default:
+			continue
+		}
+
+		actions := splitSecLangActions(actionText)
+		canonical := strings.Join([]string{variables, operator, canonicalSecLangActions(actions)}, "|")
+		if chaining && current != nil {
+			current.canonical += "\n" + canonical
+			mergeRuleActions(current, actions)
+		} else {
+			flush()
+			current = &RuleInfo{File: file, ParanoiaLevel: sectionParanoia, canonical: canonical}
+			mergeRuleActions(current, actions)
+			if regexParanoiaMarker.MatchString(variables) {
+				if match := regexParanoiaLt.FindStringSubmatch(strings.TrimSpace(operator)); match != nil {
+					if level, err := strconv.Atoi(match[1]); err == nil {
+						sectionParanoia = level
+					}
+				}
+			}
+		}
+		chaining = hasSecLangAction(actions, "chain")
+		if !chaining {
+			flush()
+		}
+	}
+	flush()
+	return rules
+}
+
+type secLangAction struct {
+	Name  string
+	Value string
+}
+
+func mergeRuleActions(rule *RuleInfo, actions []secLangAction) {
+	for _, action := range actions {
+		switch action.Name {
+		case "id":
+			if id, err := strconv.ParseInt(action.Value, 10, 64); err == nil && rule.ID == 0 {
+				rule.ID = id
+			}
+		case "msg":
+			if rule.Msg == "" {
+				rule.Msg = action.Value
+			}
+		case "phase":
+			rule.Phase = action.Value
+		case "tag":
+			if match := regexParanoiaTag.FindStringSubmatch(action.Value); match != nil {
+				if level, err := strconv.Atoi(match[1]); err == nil {
+					rule.ParanoiaLevel = level
+				}
+			}
+			if !slices.Contains(rule.Tags, action.Value) {
+				rule.Tags = append(rule.Tags, action.Value)
+			}
+		}
+		if ruleDiffMetaActions[action.Name] || action.Name == "chain" {
+			continue
+		}
+		label := action.Name
+		if action.Value != "" {
+			label += ":" + action.Value
+		}
+		if !slices.Contains(rule.Actions, label) {
+			rule.Actions = append(rule.Actions, label)
+		}
+	}
+}
+
+func canonicalSecLangActions(actions []secLangAction) string {
+	parts := make([]string, 0, len(actions))
+	for _, action := range actions {
+		if ruleDiffIgnoredActions[action.Name] {
+			continue
+		}
+		parts = append(parts, action.Name+":"+action.Value)
+	}
+	return strings.Join(parts, ",")
+}
+
+func hasSecLangAction(actions []secLangAction, name string) bool {
+	for _, action := range actions {
+		if action.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// splitSecLangDirectives 去掉注释并合并以反斜杠续行的指令
+func splitSecLangDirectives(content string) []string {
+	directives := make([]string, 0)
+	var builder strings.Builder
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if builder.Len() == 0 && (line == "" || strings.HasPrefix(line, "#")) {
+			continue
+		}
+		if strings.HasSuffix(line, "\\") {
+			builder.WriteString(strings.TrimSuffix(line, "\\"))
+			builder.WriteString(" ")
+			continue
+		}
+		builder.WriteString(line)
+		directives = append(directives, builder.String())
+		builder.Reset()
+	}
+	if builder.Len() > 0 {
+		directives = append(directives, builder.String())
+	}
+	return directives
+}
+
+// splitSecLangArgs 按空白切分参数，双引号内的内容作为一个参数并处理转义引号
+func splitSecLangArgs(directive string) []string {
+	args := make([]string, 0, 4)
+	var builder strings.Builder
+	inQuote, hasToken := false, false
+	for i := 0; i < len(directive); i++ {
+		ch := directive[i]
+		switch {
+		case ch == '\\' && inQuote && i+1 < len(directive) && directive[i+1] == '"':
+			builder.WriteByte('"')
+			i++
+		case ch == '"':
+			inQuote = !inQuote
+			hasToken = true
+		case (ch == ' ' || ch == '\t') && !inQuote:
+			if hasToken {
+				args = append(args, builder.String())
+				builder.Reset()
+				hasToken = false
+			}
+		default:
+			builder.WriteByte(ch)
+			hasToken = true
+		}
+	}
+	if hasToken {
+		args = append(args, builder.String())
+	}
+	return args
+}
+
+// splitSecLangActions 按逗号切分动作列表，单引号内的逗号不切分
+func splitSecLangActions(actionText string) []secLangAction {
+	actions := make([]secLangAction, 0)
+	var builder strings.Builder
+	inQuote := false
+	appendAction := func() {
+		raw := strings.TrimSpace(builder.String())
+		builder.Reset()
+		if raw == "" {
+			return
+		}
+		name, value, _ := strings.Cut(raw, ":")
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+			value = value[1 : len(value)-1]
+		}
+		actions = append(actions, secLangAction{Name: strings.ToLower(strings.TrimSpace(name)), Value: value})
+	}
+	for i := 0; i < len(actionText); i++ {
+		ch := actionText[i]
+		switch {
+		case ch == '\\' && i+1 < len(actionText) && actionText[i+1] == '\'':
+			builder.WriteString(`\'`)
+			i++
+		case ch == '\'':
+			inQuote = !inQuote
+			builder.WriteByte(ch)
+		case ch == ',' && !inQuote:
+			appendAction()
+		default:
+			builder.WriteByte(ch)
+		}
+	}
+	appendAction()
+	return actions
+}
@@ -0,0 +1,108 @@
+package waf
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeRuleFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write rule file failed: %v", err)
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	content := `# comment SecRule ARGS "@rx x" "id:1"
+SecRule TX:DETECTION_PARANOIA_LEVEL "@lt 1" "id:911011,phase:1,pass,nolog,skipAfter:END-REQUEST-911"
+SecRule REQUEST_METHOD "!@within %{tx.allowed_methods}" \
+    "id:911100,\
+    phase:1,\
+    block,\
+    msg:'Method is not allowed by policy',\
+    tag:'attack-generic',\
+    ver:'OWASP_CRS/4.0.0',\
+    severity:'CRITICAL'"
+SecRule TX:DETECTION_PARANOIA_LEVEL "@lt 2" "id:911013,phase:2,pass,nolog,skipAfter:END-REQUEST-911"
+SecRule ARGS "@rx foo" "id:911200,phase:2,deny,msg:'a, b',chain"
+    SecRule REQUEST_HEADERS:Host "@rx bar" "t:lowercase,setvar:'tx.score=+5'"
+SecAction "id:911300,phase:1,pass,nolog,tag:'paranoia-level/3'"
+`
+	rules := ParseRules("REQUEST-911.conf", content)
+	byID := map[int64]RuleInfo{}
+	for _, rule := range rules {
+		byID[rule.ID] = rule
+	}
+	if len(byID) != 5 {
+		t.Fatalf("expected 5 rules, got %+v", rules)
+	}
+	if rule := byID[911100]; rule.ParanoiaLevel != 1 || rule.Msg != "Method is not allowed by policy" || rule.Phase != "1" || strings.Join(rule.Tags, ",") != "attack-generic" {
+		t.Fatalf("unexpected rule 911100: %+v", rule)
+	}
+	chained := byID[911200]
+	if chained.ParanoiaLevel != 2 || chained.Msg != "a, b" {
+		t.Fatalf("unexpected rule 911200: %+v", chained)
+	}
+	if !strings.Contains(strings.Join(chained.Actions, ","), "setvar:tx.score=+5") || !strings.Contains(strings.Join(chained.Actions, ","), "deny") {
+		t.Fatalf("expected chained actions to merge, got %v", chained.Actions)
+	}
+	if byID[911300].ParanoiaLevel != 3 {
+		t.Fatalf("expected paranoia tag to override section level, got %+v", byID[911300])
+	}
+}
+
+func TestDiffRuleDirs(t *testing.T) {
+	baseDir, targetDir := t.TempDir(), t.TempDir()
+	writeRuleFile(t, baseDir, "rules/REQUEST-920.conf", `
+SecRule ARGS "@rx a" "id:920100,phase:2,block,tag:'paranoia-level/1',ver:'OWASP_CRS/4.0.0'"
+SecRule ARGS "@rx b" "id:920200,phase:2,block,tag:'paranoia-level/1',tag:'attack-protocol'"
+SecRule ARGS "@rx c" "id:920300,phase:2,pass,log"
+SecRule ARGS "@rx d" "id:920400,phase:2,block"
+`)
+	writeRuleFile(t, targetDir, "rules/REQUEST-920.conf", `
+SecRule ARGS "@rx a" "id:920100,phase:2,block,tag:'paranoia-level/1',ver:'OWASP_CRS/4.1.0'"
+SecRule ARGS "@rx b" "id:920200,phase:2,block,tag:'paranoia-level/2',tag:'attack-protocol'"
+SecRule ARGS "@rx c" "id:920300,phase:2,deny,log"
+SecRule ARGS "@rx e" "id:920500,phase:2,block"
+`)
+	writeRuleFile(t, targetDir, "README.md", `SecRule ARGS "@rx z" "id:1,deny"`)
+
+	diff, err := DiffRuleDirs(baseDir, targetDir)
+	if err != nil {
+		t.Fatalf("DiffRuleDirs() error = %v", err)
+	}
+	if len(diff.Added) != 1 || diff.Added[0].ID != 920500 || diff.Added[0].File != "rules/REQUEST-920.conf" {
+		t.Fatalf("unexpected added rules: %+v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].ID != 920400 {
+		t.Fatalf("unexpected removed rules: %+v", diff.Removed)
+	}
+	if len(diff.Modified) != 2 {
+		t.Fatalf("expected version bump to be ignored, got %+v", diff.Modified)
+	}
+	paranoia := diff.Modified[0]
+	if paranoia.ID != 920200 || paranoia.ParanoiaBefore != 1 || paranoia.ParanoiaAfter != 2 || strings.Join(paranoia.Changes, ",") != "paranoia,tags" {
+		t.Fatalf("unexpected paranoia change: %+v", paranoia)
+	}
+	action := diff.Modified[1]
+	if action.ID != 920300 || strings.Join(action.ActionsAdded, ",") != "deny" || strings.Join(action.ActionsRemoved, ",") != "pass" {
+		t.Fatalf("unexpected action change: %+v", action)
+	}
+	if summary := diff.Summary(); summary != "新增 1 条、移除 1 条、修改 2 条规则，其中 1 条调整了 Paranoia Level" {
+		t.Fatalf("unexpected summary: %s", summary)
+	}
+
+	same, err := DiffRuleDirs(baseDir, baseDir)
+	if err != nil || same.Summary() != "规则无变化（共 4 条）" {
+		t.Fatalf("unexpected identical diff: %v, %v", same.Summary(), err)
+	}
+	if _, err := ParseRuleDir(filepath.Join(baseDir, "missing")); err == nil {
+		t.Fatalf("expected missing directory to fail")
+	}
+}
@@ -0,0 +1,19 @@
+package waf
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/corazawaf/coraza/v3"
+)
+
+// ValidateSecLang 使用 Coraza SecLang 解析器校验指令语法；Include 等依赖外部文件的指令不在此处支持
+func ValidateSecLang(directives string) error {
+	if strings.TrimSpace(directives) == "" {
+		return fmt.Errorf("SecLang 指令为空")
+	}
+	if _, err := coraza.NewWAF(coraza.NewWAFConfig().WithDirectives(directives)); err != nil {
+		return fmt.Errorf("SecLang 语法校验失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package waf
+
+import "testing"
+
+func TestValidateSecLang(t *testing.T) {
+	valid := `SecRule REQUEST_URI "@beginsWith /wp-admin" "id:10001,phase:1,deny,status:403,log,msg:'block wp-admin'"`
+	if err := ValidateSecLang(valid); err != nil {
+		t.Fatalf("expected valid rule, got %v", err)
+	}
+
+	chained := "SecRule REMOTE_ADDR \"@ipMatch 203.0.113.0/24\" \"id:10002,phase:1,deny,chain\"\n  SecRule REQUEST_URI \"@beginsWith /admin\" \"t:none\""
+	if err := ValidateSecLang(chained); err != nil {
+		t.Fatalf("expected valid chained rule, got %v", err)
+	}
+
+	for _, invalid := range []string{
+		"",
+		`SecRule REQUEST_URI "@unknownOperator x" "id:10003,phase:1,deny"`,
+		`SecRule REQUEST_URI "@rx x" "id:10004,phase:1,notAnAction"`,
+		`SecRuleX REQUEST_URI`,
+	} {
+		if err := ValidateSecLang(invalid); err == nil {
+			t.Fatalf("expected error for %q", invalid)
+		}
+	}
+}
@@ -0,0 +1,438 @@
+package waf
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	SignatureTypeNone     = "none"
+	SignatureTypeMinisign = "minisign"
+	SignatureTypeGPG      = "gpg"
+	SignatureTypeCosign   = "cosign"
+
+	// MaxSignatureBytes 分离签名文件的大小上限
+	MaxSignatureBytes int64 = 64 * 1024
+
+	minisignAlgorithmLegacy    = "Ed"
+	minisignAlgorithmPrehashed = "ED"
+	minisignKeyIDBytes         = 8
+	minisignTrustedPrefix      = "trusted comment: "
+	minisignUntrustedPrefix    = "untrusted comment:"
+)
+
+// SignatureOptions 分离签名校验参数，PublicKeys 可包含多把受信公钥
+type SignatureOptions struct {
+	Type       string
+	Signature  []byte
+	PublicKeys string
+}
+
+// SignatureResult 签名校验结果，Signer 为可读的签名者标识
+type SignatureResult struct {
+	Type    string
+	KeyID   string
+	Signer  string
+	Comment string
+}
+
+// NormalizeSignatureType 规范化签名类型，空值视为不校验
+func NormalizeSignatureType(signatureType string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(signatureType))
+	switch normalized {
+	case "":
+		return SignatureTypeNone, nil
+	case SignatureTypeNone, SignatureTypeMinisign, SignatureTypeGPG, SignatureTypeCosign:
+		return normalized, nil
+	case "pgp", "openpgp":
+		return SignatureTypeGPG, nil
+	default:
+		return "", fmt.Errorf("不支持的签名类型: %s", signatureType)
+	}
+}
+
+// ValidateTrustedKeys 在保存信任配置时解析公钥，避免到同步时才发现公钥无效
+func ValidateTrustedKeys(signatureType, publicKeys string) error {
+	normalized, err := NormalizeSignatureType(signatureType)
+	if err != nil {
+		return err
+	}
+	if normalized == SignatureTypeNone {
+		return nil
+	}
+	if strings.TrimSpace(publicKeys) == "" {
+		return fmt.Errorf("启用签名校验时必须配置受信公钥")
+	}
+	switch normalized {
+	case SignatureTypeMinisign:
+		_, err = parseMinisignPublicKeys(publicKeys)
+	case SignatureTypeGPG:
+		_, err = parseGPGKeyRing(publicKeys)
+	case SignatureTypeCosign:
+		_, err = parseCosignPublicKeys(publicKeys)
+	}
+	return err
+}
+
+// TrustedKeyIDs 解析受信公钥并返回各公钥的 ID，格式与校验结果中的 SignatureResult.KeyID 一致
+func TrustedKeyIDs(signatureType, publicKeys string) ([]string, error) {
+	normalized, err := NormalizeSignatureType(signatureType)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0)
+	switch normalized {
+	case SignatureTypeMinisign:
+		keys, err := parseMinisignPublicKeys(publicKeys)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			ids = append(ids, minisignKeyIDString(key.KeyID))
+		}
+	case SignatureTypeGPG:
+		keyring, err := parseGPGKeyRing(publicKeys)
+		if err != nil {
+			return nil, err
+		}
+		for _, entity := range keyring {
+			ids = append(ids, strings.ToUpper(hex.EncodeToString(entity.PrimaryKey.Fingerprint[:])))
+		}
+	case SignatureTypeCosign:
+		keys, err := parseCosignPublicKeys(publicKeys)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			ids = append(ids, key.Fingerprint)
+		}
+	}
+	return ids, nil
+}
+
+// DefaultSignatureURL 按签名类型约定的后缀推导签名地址；pattern 支持 {url} 与 {version} 占位符
+func DefaultSignatureURL(pattern, packageURL, version, signatureType string) string {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		switch signatureType {
+		case SignatureTypeMinisign:
+			pattern = "{url}.minisig"
+		case SignatureTypeGPG:
+			pattern = "{url}.asc"
+		case SignatureTypeCosign:
+			pattern = "{url}.sig"
+		default:
+			return ""
+		}
+	}
+	replacer := strings.NewReplacer("{url}", strings.TrimSpace(packageURL), "{version}", strings.TrimSpace(version))
+	return replacer.Replace(pattern)
+}
+
+// VerifySignature 使用受信公钥校验包文件的分离签名
+func VerifySignature(packagePath string, options SignatureOptions) (*SignatureResult, error) {
+	signatureType, err := NormalizeSignatureType(options.Type)
+	if err != nil {
+		return nil, err
+	}
+	if signatureType == SignatureTypeNone {
+		return nil, nil
+	}
+	if len(bytes.TrimSpace(options.Signature)) == 0 {
+		return nil, fmt.Errorf("签名文件为空")
+	}
+	if int64(len(options.Signature)) > MaxSignatureBytes {
+		return nil, fmt.Errorf("签名文件过大: %d > %d", len(options.Signature), MaxSignatureBytes)
+	}
+	if strings.TrimSpace(options.PublicKeys) == "" {
+		return nil, fmt.Errorf("未配置受信公钥，无法校验签名")
+	}
+
+	var result *SignatureResult
+	switch signatureType {
+	case SignatureTypeMinisign:
+		result, err = verifyMinisignSignature(packagePath, options.Signature, options.PublicKeys)
+	case SignatureTypeGPG:
+		result, err = verifyGPGSignature(packagePath, options.Signature, options.PublicKeys)
+	case SignatureTypeCosign:
+		result, err = verifyCosignSignature(packagePath, options.Signature, options.PublicKeys)
+	}
+	if err != nil {
+		return nil, err
+	}
+	result.Type = signatureType
+	return result, nil
+}
+
+type minisignPublicKey struct {
+	KeyID [minisignKeyIDBytes]byte
+	Key   ed25519.PublicKey
+}
+
+// minisignKeyIDString 与 minisign 命令行一致，按小端序输出 16 位十六进制
+func minisignKeyIDString(keyID [minisignKeyIDBytes]byte) string {
+	return fmt.Sprintf("%016X", binary.LittleEndian.Uint64(keyID[:]))
+}
+
+func parseMinisignPublicKeys(raw string) ([]minisignPublicKey, error) {
+	keys := make([]minisignPublicKey, 0)
+	for _, line := range strings.Split(raw, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, minisignUntrustedPrefix) {
+			continue
+		}
+		decoded, err := base64.StdEncoding.DecodeString(line)
+		if err != nil || len(decoded) != 2+minisignKeyIDBytes+ed25519.PublicKeySize || string(decoded[:2]) != minisignAlgorithmLegacy {
+			return nil, fmt.Errorf("minisign 公钥格式无效")
+		}
+		var key minisignPublicKey
+		copy(key.KeyID[:], decoded[2:2+minisignKeyIDBytes])
+		key.Key = ed25519.PublicKey(decoded[2+minisignKeyIDBytes:])
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("未找到有效的 minisign 公钥")
+	}
+	return keys, nil
+}
+
+func verifyMinisignSignature(packagePath string, signature []byte, publicKeys string) (*SignatureResult, error) {
+	keys, err := parseMinisignPublicKeys(publicKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]string, 0, 4)
+	for _, line := range strings.Split(strings.ReplaceAll(string(signature), "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], minisignUntrustedPrefix) || !strings.HasPrefix(lines[2], minisignTrustedPrefix) {
+		return nil, fmt.Errorf("minisign 签名格式无效")
+	}
+	sigBlob, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if err != nil || len(sigBlob) != 2+minisignKeyIDBytes+ed25519.SignatureSize {
+		return nil, fmt.Errorf("minisign 签名格式无效")
+	}
+	globalSig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if err != nil || len(globalSig) != ed25519.SignatureSize {
+		return nil, fmt.Errorf("minisign 可信注释签名格式无效")
+	}
+	algorithm := string(sigBlob[:2])
+	if algorithm != minisignAlgorithmLegacy && algorithm != minisignAlgorithmPrehashed {
+		return nil, fmt.Errorf("不支持的 minisign 签名算法: %s", algorithm)
+	}
+	var keyID [minisignKeyIDBytes]byte
+	copy(keyID[:], sigBlob[2:2+minisignKeyIDBytes])
+	sig := sigBlob[2+minisignKeyIDBytes:]
+
+	var key *minisignPublicKey
+	for i := range keys {
+		if keys[i].KeyID == keyID {
+			key = &keys[i]
+			break
+		}
+	}
+	if key == nil {
+		return nil, fmt.Errorf("签名公钥 %s 不在受信列表中", minisignKeyIDString(keyID))
+	}
+
+	var message []byte
+	if algorithm == minisignAlgorithmPrehashed {
+		message, err = hashFileBlake2b512(packagePath)
+	} else {
+		message, err = os.ReadFile(packagePath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取包文件失败: %w", err)
+	}
+	if !ed25519.Verify(key.Key, message, sig) {
+		return nil, fmt.Errorf("minisign 签名校验失败")
+	}
+
+	trustedComment := strings.TrimPrefix(lines[2], minisignTrustedPrefix)
+	if !ed25519.Verify(key.Key, append(append([]byte{}, sig...), []byte(trustedComment)...), globalSig) {
+		return nil, fmt.Errorf("minisign 可信注释校验失败")
+	}
+
+	keyIDString := minisignKeyIDString(keyID)
+	return &SignatureResult{
+		KeyID:   keyIDString,
+		Signer:  "minisign:" + keyIDString,
+		Comment: trustedComment,
+	}, nil
+}
+
+func hashFileBlake2b512(filePath string) ([]byte, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	hasher, err := blake2b.New512(nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(hasher, file); err != nil {
+		return nil, err
+	}
+	return hasher.Sum(nil), nil
+}
+
+// parseGPGKeyRing 同时接受 ASCII armor 与二进制公钥
+func parseGPGKeyRing(raw string) (openpgp.EntityList, error) {
+	var (
+		keyring openpgp.EntityList
+		err     error
+	)
+	if strings.Contains(raw, "-----BEGIN PGP PUBLIC KEY BLOCK-----") {
+		keyring, err = openpgp.ReadArmoredKeyRing(strings.NewReader(raw))
+	} else {
+		keyring, err = openpgp.ReadKeyRing(strings.NewReader(raw))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("GPG 公钥格式无效: %w", err)
+	}
+	if len(keyring) == 0 {
+		return nil, fmt.Errorf("未找到有效的 GPG 公钥")
+	}
+	return keyring, nil
+}
+
+func verifyGPGSignature(packagePath string, signature []byte, publicKeys string) (*SignatureResult, error) {
+	keyring, err := parseGPGKeyRing(publicKeys)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(packagePath)
+	if err != nil {
+		return nil, fmt.Errorf("读取包文件失败: %w", err)
+	}
+	defer file.Close()
+
+	var signer *openpgp.Entity
+	if bytes.Contains(signature, []byte("-----BEGIN PGP SIGNATURE-----")) {
+		signer, err = openpgp.CheckArmoredDetachedSignature(keyring, file, bytes.NewReader(signature), nil)
+	} else {
+		signer, err = openpgp.CheckDetachedSignature(keyring, file, bytes.NewReader(signature), nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("GPG 签名校验失败: %w", err)
+	}
+
+	result := &SignatureResult{KeyID: strings.ToUpper(hex.EncodeToString(signer.PrimaryKey.Fingerprint[:]))}
+	for name := range signer.Identities {
+		if result.Signer == "" || name < result.Signer {
+			result.Signer = name
+		}
+	}
+	if result.Signer == "" {
+		result.Signer = "gpg:" + result.KeyID
+	}
+	return result, nil
+}
+
+type cosignPublicKey struct {
+	Fingerprint string
+	Key         crypto.PublicKey
+}
+
+func parseCosignPublicKeys(raw string) ([]cosignPublicKey, error) {
+	keys := make([]cosignPublicKey, 0)
+	rest := []byte(raw)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "PUBLIC KEY" {
+			continue
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("cosign 公钥格式无效: %w", err)
+		}
+		switch key.(type) {
+		case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey:
+		default:
+			return nil, fmt.Errorf("不支持的 cosign 公钥类型: %T", key)
+		}
+		sum := sha256.Sum256(block.Bytes)
+		keys = append(keys, cosignPublicKey{Fingerprint: "sha256:" + hex.EncodeToString(sum[:]), Key: key})
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("未找到有效的 cosign 公钥（需 PEM 格式的 PUBLIC KEY）")
+	}
+	return keys, nil
+}
+
+// decodeCosignSignature 支持 sign-blob 输出的 base64 签名以及 --bundle 产出的 JSON
+func decodeCosignSignature(signature []byte) ([]byte, error) {
+	trimmed := bytes.TrimSpace(signature)
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		var bundle struct {
+			Base64Signature string `json:"base64Signature"`
+		}
+		if err := json.Unmarshal(trimmed, &bundle); err != nil || bundle.Base64Signature == "" {
+			return nil, fmt.Errorf("cosign 签名包格式无效")
+		}
+		trimmed = []byte(bundle.Base64Signature)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(string(trimmed))
+	if err != nil {
+		return nil, fmt.Errorf("cosign 签名格式无效: %w", err)
+	}
+	return decoded, nil
+}
+
+func verifyCosignSignature(packagePath string, signature []byte, publicKeys string) (*SignatureResult, error) {
+	keys, err := parseCosignPublicKeys(publicKeys)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := decodeCosignSignature(signature)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(packagePath)
+	if err != nil {
+		return nil, fmt.Errorf("读取包文件失败: %w", err)
+	}
+	digest := sha256.Sum256(content)
+
+	for _, key := range keys {
+		verified := false
+		switch publicKey := key.Key.(type) {
+		case *ecdsa.PublicKey:
+			verified = ecdsa.VerifyASN1(publicKey, digest[:], sig)
+		case *rsa.PublicKey:
+			verified = rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], sig) == nil
+		case ed25519.PublicKey:
+			verified = ed25519.Verify(publicKey, content, sig)
+		}
+		if verified {
+			return &SignatureResult{KeyID: key.Fingerprint, Signer: "cosign:" + key.Fingerprint}, nil
+		}
+	}
+	return nil, fmt.Errorf("cosign 签名校验失败：没有受信公钥能验证该签名")
+}
@@ -0,0 +1,196 @@
+package waf
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"golang.org/x/crypto/blake2b"
+)
+
+func writeSignedPackage(t *testing.T, content string) string {
+	t.Helper()
+	packagePath := filepath.Join(t.TempDir(), "rules.tar.gz")
+	if err := os.WriteFile(packagePath, []byte(content), 0o644); err != nil {
+		t.Fatalf("write package failed: %v", err)
+	}
+	return packagePath
+}
+
+func newMinisignFixture(t *testing.T, content []byte, prehashed bool) (publicKey string, signature []byte) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key failed: %v", err)
+	}
+	keyID := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	publicKey = "untrusted comment: minisign public key\n" +
+		base64.StdEncoding.EncodeToString(append(append([]byte(minisignAlgorithmLegacy), keyID...), pub...))
+
+	algorithm, message := minisignAlgorithmLegacy, content
+	if prehashed {
+		sum := blake2b.Sum512(content)
+		algorithm, message = minisignAlgorithmPrehashed, sum[:]
+	}
+	sig := ed25519.Sign(priv, message)
+	trustedComment := "timestamp:1700000000\tfile:rules.tar.gz"
+	globalSig := ed25519.Sign(priv, append(append([]byte{}, sig...), []byte(trustedComment)...))
+	signature = []byte(strings.Join([]string{
+		"untrusted comment: signature from minisign secret key",
+		base64.StdEncoding.EncodeToString(append(append([]byte(algorithm), keyID...), sig...)),
+		minisignTrustedPrefix + trustedComment,
+		base64.StdEncoding.EncodeToString(globalSig),
+	}, "\n") + "\n")
+	return publicKey, signature
+}
+
+func TestVerifySignature_Minisign(t *testing.T) {
+	for _, prehashed := range []bool{false, true} {
+		t.Run(fmt.Sprintf("prehashed=%v", prehashed), func(t *testing.T) {
+			packagePath := writeSignedPackage(t, "crs-package")
+			publicKey, signature := newMinisignFixture(t, []byte("crs-package"), prehashed)
+
+			result, err := VerifyPackage(packagePath, VerifyOptions{Signature: &SignatureOptions{
+				Type:       SignatureTypeMinisign,
+				Signature:  signature,
+				PublicKeys: publicKey,
+			}})
+			if err != nil {
+				t.Fatalf("VerifyPackage returned error: %v", err)
+			}
+			if result.Signature == nil || result.Signature.Signer != "minisign:0807060504030201" || !strings.Contains(result.Signature.Comment, "rules.tar.gz") {
+				t.Fatalf("unexpected signature result: %+v", result.Signature)
+			}
+		})
+	}
+
+	packagePath := writeSignedPackage(t, "tampered")
+	publicKey, signature := newMinisignFixture(t, []byte("crs-package"), false)
+	if _, err := VerifySignature(packagePath, SignatureOptions{Type: SignatureTypeMinisign, Signature: signature, PublicKeys: publicKey}); err == nil || !strings.Contains(err.Error(), "minisign 签名校验失败") {
+		t.Fatalf("expected tampered package to fail, got %v", err)
+	}
+
+	otherKey, _ := newMinisignFixture(t, nil, false)
+	if _, err := VerifySignature(writeSignedPackage(t, "crs-package"), SignatureOptions{Type: SignatureTypeMinisign, Signature: signature, PublicKeys: otherKey}); err == nil {
+		t.Fatalf("expected signature from another key to be rejected")
+	}
+}
+
+func TestVerifySignature_GPG(t *testing.T) {
+	entity, err := openpgp.NewEntity("Rules Bot", "", "rules@example.com", nil)
+	if err != nil {
+		t.Fatalf("create entity failed: %v", err)
+	}
+	var publicKey bytes.Buffer
+	writer, err := armor.Encode(&publicKey, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatalf("armor encode failed: %v", err)
+	}
+	if err := entity.Serialize(writer); err != nil {
+		t.Fatalf("serialize public key failed: %v", err)
+	}
+	_ = writer.Close()
+
+	var signature bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&signature, entity, strings.NewReader("crs-package"), nil); err != nil {
+		t.Fatalf("detach sign failed: %v", err)
+	}
+
+	result, err := VerifySignature(writeSignedPackage(t, "crs-package"), SignatureOptions{
+		Type:       "openpgp",
+		Signature:  signature.Bytes(),
+		PublicKeys: publicKey.String(),
+	})
+	if err != nil {
+		t.Fatalf("VerifySignature returned error: %v", err)
+	}
+	if result.Type != SignatureTypeGPG || result.Signer != "Rules Bot <rules@example.com>" || len(result.KeyID) != 40 {
+		t.Fatalf("unexpected signature result: %+v", result)
+	}
+
+	if _, err := VerifySignature(writeSignedPackage(t, "tampered"), SignatureOptions{Type: SignatureTypeGPG, Signature: signature.Bytes(), PublicKeys: publicKey.String()}); err == nil {
+		t.Fatalf("expected tampered package to fail")
+	}
+}
+
+func TestVerifySignature_Cosign(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key failed: %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("marshal public key failed: %v", err)
+	}
+	publicKey := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	digest := sha256.Sum256([]byte("crs-package"))
+	sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatalf("sign failed: %v", err)
+	}
+	encoded := base64.StdEncoding.EncodeToString(sig)
+
+	for _, signature := range []string{encoded + "\n", `{"base64Signature":"` + encoded + `"}`} {
+		result, err := VerifySignature(writeSignedPackage(t, "crs-package"), SignatureOptions{Type: SignatureTypeCosign, Signature: []byte(signature), PublicKeys: publicKey})
+		if err != nil {
+			t.Fatalf("VerifySignature returned error: %v", err)
+		}
+		fingerprint := sha256.Sum256(der)
+		if result.Signer != fmt.Sprintf("cosign:sha256:%x", fingerprint) {
+			t.Fatalf("unexpected signer: %+v", result)
+		}
+	}
+
+	if _, err := VerifySignature(writeSignedPackage(t, "tampered"), SignatureOptions{Type: SignatureTypeCosign, Signature: []byte(encoded), PublicKeys: publicKey}); err == nil {
+		t.Fatalf("expected tampered package to fail")
+	}
+}
+
+func TestTrustedKeyIDs(t *testing.T) {
+	publicKey, _ := newMinisignFixture(t, nil, false)
+	ids, err := TrustedKeyIDs(SignatureTypeMinisign, publicKey)
+	if err != nil || len(ids) != 1 || ids[0] != "0807060504030201" {
+		t.Fatalf("unexpected minisign key ids: %v err=%v", ids, err)
+	}
+	if ids, err := TrustedKeyIDs(SignatureTypeNone, ""); err != nil || len(ids) != 0 {
+		t.Fatalf("expected no key ids without signature, got %v err=%v", ids, err)
+	}
+	if _, err := TrustedKeyIDs(SignatureTypeCosign, "not a pem"); err == nil {
+		t.Fatalf("expected invalid cosign key to be rejected")
+	}
+}
+
+func TestValidateTrustedKeysAndSignatureURL(t *testing.T) {
+	if err := ValidateTrustedKeys("", ""); err != nil {
+		t.Fatalf("expected none type to skip key validation, got %v", err)
+	}
+	if err := ValidateTrustedKeys(SignatureTypeMinisign, ""); err == nil {
+		t.Fatalf("expected missing keys to be rejected")
+	}
+	if err := ValidateTrustedKeys(SignatureTypeCosign, "not a pem"); err == nil {
+		t.Fatalf("expected invalid cosign key to be rejected")
+	}
+	if _, err := NormalizeSignatureType("x509"); err == nil {
+		t.Fatalf("expected unknown signature type to be rejected")
+	}
+
+	if got := DefaultSignatureURL("", "https://example.com/v4.0.tar.gz", "v4.0", SignatureTypeMinisign); got != "https://example.com/v4.0.tar.gz.minisig" {
+		t.Fatalf("unexpected default signature url: %s", got)
+	}
+	if got := DefaultSignatureURL("https://sig.example.com/{version}.asc", "https://example.com/a.tar.gz", "v4.0", SignatureTypeGPG); got != "https://sig.example.com/v4.0.asc" {
+		t.Fatalf("unexpected signature url: %s", got)
+	}
+}
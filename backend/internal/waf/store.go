@@ -13,12 +13,14 @@ const (
 	CurrentLinkName  = "current"
 	LastGoodLinkName = "last_good"
 	linkTargetSuffix = ".linktarget"
+	signingKeyName   = "bundle_signing.key"
 )
 
 type Store struct {
 	BaseDir     string
 	PackagesDir string
 	ReleasesDir string
+	BundlesDir  string
 }
 
 func NewStore(baseDir string) *Store {
@@ -31,11 +33,12 @@ func NewStore(baseDir string) *Store {
 		BaseDir:     baseDir,
 		PackagesDir: filepath.Join(baseDir, "packages"),
 		ReleasesDir: filepath.Join(baseDir, "releases"),
+		BundlesDir:  filepath.Join(baseDir, "bundles"),
 	}
 }
 
 func (store *Store) EnsureDirs() error {
-	directories := []string{store.BaseDir, store.PackagesDir, store.ReleasesDir}
+	directories := []string{store.BaseDir, store.PackagesDir, store.ReleasesDir, store.BundlesDir}
 	for _, directory := range directories {
 		if err := os.MkdirAll(directory, 0o755); err != nil {
 			return fmt.Errorf("创建目录失败: %s, %w", directory, err)
@@ -53,6 +56,15 @@ func (store *Store) PackagePath(filename string) string {
 	return filepath.Join(store.PackagesDir, filepath.Base(filename))
 }
 
+// BundlePath 离线包与镜像归档的缓存路径
+func (store *Store) BundlePath(filename string) string {
+	return filepath.Join(store.BundlesDir, filepath.Base(filename))
+}
+
+func (store *Store) SigningKeyPath() string {
+	return filepath.Join(store.BaseDir, signingKeyName)
+}
+
 func (store *Store) StagePath(filename string) string {
 	baseName := filepath.Base(filename)
 	if baseName == "." || baseName == "/" || strings.TrimSpace(baseName) == "" {
@@ -69,6 +81,45 @@ func (store *Store) LastGoodLinkPath() string {
 	return filepath.Join(store.BaseDir, LastGoodLinkName)
 }
 
+// ServerCurrentLinkPath 多节点分发时每个节点独立的 current 链接
+func (store *Store) ServerCurrentLinkPath(serverID uint) string {
+	return filepath.Join(store.BaseDir, fmt.Sprintf("%s-%d", CurrentLinkName, serverID))
+}
+
+func (store *Store) ServerLastGoodLinkPath(serverID uint) string {
+	return filepath.Join(store.BaseDir, fmt.Sprintf("%s-%d", LastGoodLinkName, serverID))
+}
+
+// ServerLinkPaths 返回已存在的节点级 current/last_good 链接，供保留清理识别仍在使用的版本
+func (store *Store) ServerLinkPaths() []string {
+	var paths []string
+	for _, name := range []string{CurrentLinkName, LastGoodLinkName} {
+		matches, err := filepath.Glob(filepath.Join(store.BaseDir, name+"-*"))
+		if err != nil {
+			continue
+		}
+		for _, match := range matches {
+			if strings.HasSuffix(match, ".tmp") {
+				continue
+			}
+			paths = append(paths, strings.TrimSuffix(match, linkTargetSuffix))
+		}
+	}
+	return paths
+}
+
+// RemoveLink 删除链接及其元数据，节点分发前不存在链接时回滚使用
+func (store *Store) RemoveLink(linkPath string) error {
+	cleanLinkPath := filepath.Clean(linkPath)
+	if err := os.Remove(cleanLinkPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("删除链接失败: %w", err)
+	}
+	if err := os.Remove(cleanLinkPath + linkTargetSuffix); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("删除链接元数据失败: %w", err)
+	}
+	return nil
+}
+
 func (store *Store) LinkTarget(linkPath string) (string, error) {
 	targetPath, err := os.Readlink(linkPath)
 	if err != nil {
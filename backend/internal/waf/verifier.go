@@ -19,12 +19,15 @@ type VerifyOptions struct {
 	AllowedExt      []string
 	MaxPackageBytes int64
 	ExpectedSHA256  string
+	// Signature 为空时不校验签名
+	Signature *SignatureOptions
 }
 
 type VerifyResult struct {
 	SizeBytes int64
 	SHA256    string
 	Ext       string
+	Signature *SignatureResult
 }
 
 func VerifyPackage(packagePath string, options VerifyOptions) (*VerifyResult, error) {
@@ -65,10 +68,19 @@ func VerifyPackage(packagePath string, options VerifyOptions) (*VerifyResult, er
 		return nil, fmt.Errorf("SHA256 不匹配: 期望 %s，实际 %s", expectedHash, hash)
 	}
 
+	var signature *SignatureResult
+	if options.Signature != nil {
+		signature, err = VerifySignature(packagePath, *options.Signature)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &VerifyResult{
 		SizeBytes: fileInfo.Size(),
 		SHA256:    hash,
 		Ext:       packageExt,
+		Signature: signature,
 	}, nil
 }
 
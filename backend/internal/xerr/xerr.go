@@ -14,6 +14,8 @@ const (
 	Forbidden = 403
 	// NotFound 表示业务资源不存在。
 	NotFound = 404
+	// TooManyRequests 表示请求频率超过限制。
+	TooManyRequests = 429
 	// ServerCommonError 表示需要展示给前端的系统错误。
 	ServerCommonError = 500
 )
@@ -24,6 +26,7 @@ var errMsg = map[int]string{
 	Unauthorized:        "登录状态无效",
 	Forbidden:           "权限不足",
 	NotFound:            "资源不存在",
+	TooManyRequests:     "请求过于频繁",
 	ServerCommonError:   "系统繁忙，请稍后重试",
 }
 
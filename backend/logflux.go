@@ -10,9 +10,11 @@ import (
 	"logflux/internal/config"
 	"logflux/internal/handler"
 	caddylogic "logflux/internal/logic/caddy"
+	notificationlogic "logflux/internal/logic/notification"
 	"logflux/internal/middleware"
 	"logflux/internal/response"
 	"logflux/internal/svc"
+	"logflux/internal/tasks"
 	"logflux/internal/types"
 	"logflux/internal/xerr"
 	"strings"
@@ -50,6 +52,15 @@ func main() {
 		ctx.WafScheduler.Start()
 		defer ctx.WafScheduler.Stop()
 	}
+	if ctx.ReportScheduler != nil {
+		ctx.ReportScheduler.SetExecutor(&reportScheduleExecutor{svcCtx: ctx})
+		if err := ctx.ReportScheduler.Reload(); err != nil {
+			logx.Errorf("加载定时报表失败: %v", err)
+		}
+	}
+	tasks.RegisterMaintenanceJobs(ctx.Jobs, maintenanceJobs(ctx))
+	ctx.Jobs.Start()
+	defer ctx.Jobs.Stop()
 	handler.RegisterHandlers(server, ctx)
 
 	// Global Response Middleware
@@ -91,3 +102,62 @@ func (executor *wafScheduleExecutor) SyncSource(ctx context.Context, sourceID ui
 	})
 	return err
 }
+
+// maintenanceJobs 内置维护任务及其周期
+func maintenanceJobs(svcCtx *svc.ServiceContext) []tasks.MaintenanceJob {
+	return []tasks.MaintenanceJob{
+		{
+			Name:  "waf_rollout_evaluate",
+			Title: "评估 WAF 灰度",
+			Spec:  "0 */10 * * * *",
+			Run: func(ctx context.Context) error {
+				return caddylogic.NewWafRolloutService(ctx, svcCtx).EvaluateActive()
+			},
+		},
+		{
+			Name:  "waf_ban_evaluate",
+			Title: "执行自动封禁",
+			Spec:  "30 * * * * *",
+			Run: func(ctx context.Context) error {
+				return caddylogic.NewWafBanService(ctx, svcCtx).EvaluateActive()
+			},
+		},
+		{
+			Name:  "caddy_drift_check",
+			Title: "检测 Caddy 配置漂移",
+			Spec:  "15 */5 * * * *",
+			Run: func(ctx context.Context) error {
+				return caddylogic.NewCaddyDriftService(ctx, svcCtx).CheckAll()
+			},
+		},
+		{
+			Name:  "caddy_change_request",
+			Title: "应用变更申请",
+			Spec:  "45 * * * * *",
+			Run: func(ctx context.Context) error {
+				return caddylogic.NewCaddyChangeRequestService(ctx, svcCtx).RunDue()
+			},
+		},
+		{
+			Name:  "caddy_certificate_check",
+			Title: "巡检 Caddy 证书",
+			Spec:  "0 20 * * * *",
+			Run: func(ctx context.Context) error {
+				return caddylogic.NewCaddyCertificateService(ctx, svcCtx).CheckAll()
+			},
+		},
+	}
+}
+
+type reportScheduleExecutor struct {
+	svcCtx *svc.ServiceContext
+}
+
+func (executor *reportScheduleExecutor) RunReport(ctx context.Context, reportID uint) error {
+	if executor == nil || executor.svcCtx == nil {
+		return fmt.Errorf("报表调度器服务上下文为空")
+	}
+	logic := notificationlogic.NewRunReportLogic(ctx, executor.svcCtx)
+	_, err := logic.RunReport(&types.IDReq{ID: reportID})
+	return err
+}
@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// CaddyApplyVerifySetting 配置加载后的健康校验设置，每个节点一条；校验失败时自动回滚到上一个历史版本
+type CaddyApplyVerifySetting struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	ServerID uint `gorm:"uniqueIndex;not null" json:"serverId"`
+	Enabled  bool `gorm:"not null;default:false" json:"enabled"`
+
+	// 加载后观察新写入 caddy_logs 的秒数，0 表示只执行 HTTP 探测
+	ErrorWindowSeconds int     `gorm:"not null;default:0" json:"errorWindowSeconds"`
+	MaxErrorRate       float64 `gorm:"not null;default:0.5" json:"maxErrorRate"` // 5xx 占比上限
+	MinRequests        int     `gorm:"not null;default:20" json:"minRequests"`   // 样本数不足时不判定错误率
+}
+
+func (CaddyApplyVerifySetting) TableName() string {
+	return "caddy_apply_verify_settings"
+}
+
+// CaddyHealthProbe 配置加载后执行的 HTTP 探测
+type CaddyHealthProbe struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	ServerID       uint   `gorm:"index;not null" json:"serverId"`
+	Name           string `gorm:"size:100;not null" json:"name"`
+	URL            string `gorm:"size:1024;not null" json:"url"`
+	Method         string `gorm:"size:10;not null;default:'GET'" json:"method"`
+	Host           string `gorm:"size:255" json:"host"`                   // 可选，覆盖 Host 头
+	ExpectStatus   int    `gorm:"not null;default:0" json:"expectStatus"` // 0 表示任意 2xx/3xx
+	BodyPattern    string `gorm:"size:1024" json:"bodyPattern"`           // 可选，响应体需匹配的正则
+	TimeoutSeconds int    `gorm:"not null;default:5" json:"timeoutSeconds"`
+	Enabled        bool   `gorm:"not null;default:false" json:"enabled"`
+}
+
+func (CaddyHealthProbe) TableName() string {
+	return "caddy_health_probes"
+}
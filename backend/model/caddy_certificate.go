@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// CaddyCertificate Caddy 节点的证书清单，由配置中的站点地址、/pki/ca 接口与可选的 TLS 握手探测汇总而来。
+// 续期失败原因从 caddy_runtime 日志中解析
+type CaddyCertificate struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	ServerID uint   `gorm:"uniqueIndex:idx_caddy_cert_server_host,priority:1;not null" json:"serverId"`
+	Host     string `gorm:"size:255;uniqueIndex:idx_caddy_cert_server_host,priority:2;not null" json:"host"` // 站点域名；PKI 证书为 pki:<ca>/root 等
+	Source   string `gorm:"size:20;not null;default:'site'" json:"source"`                                   // site | pki
+
+	Subject      string         `gorm:"size:255" json:"subject"`
+	Issuer       string         `gorm:"size:255" json:"issuer"`
+	SANs         pq.StringArray `gorm:"type:text[]" json:"sans"`
+	SerialNumber string         `gorm:"size:100" json:"serialNumber"`
+	Fingerprint  string         `gorm:"size:64" json:"fingerprint"` // 叶子证书 SHA-256
+	NotBefore    *time.Time     `json:"notBefore,omitempty"`
+	NotAfter     *time.Time     `gorm:"index" json:"notAfter,omitempty"`
+
+	Status       string     `gorm:"size:20;index;not null;default:'unknown'" json:"status"` // unknown | valid | expiring | expired | error
+	VerifyError  string     `gorm:"type:text" json:"verifyError,omitempty"`                 // 证书链或主机名校验失败原因
+	ProbeError   string     `gorm:"type:text" json:"probeError,omitempty"`                  // 握手或接口请求失败原因
+	LastProbedAt *time.Time `json:"lastProbedAt,omitempty"`
+
+	RenewalError   string     `gorm:"type:text" json:"renewalError,omitempty"` // 最近一次签发/续期失败的日志摘要，成功后清空
+	RenewalErrorAt *time.Time `json:"renewalErrorAt,omitempty"`
+
+	// NotifiedDays 针对当前证书已发送过的最小提醒阈值（天），0 表示已发送过期通知；证书更换后清空
+	NotifiedDays *int `json:"notifiedDays,omitempty"`
+}
+
+func (CaddyCertificate) TableName() string {
+	return "caddy_certificates"
+}
@@ -0,0 +1,61 @@
+package model
+
+import "time"
+
+// CaddyChangeRequest Caddy 配置或 WAF 策略发布的变更申请，需由作者以外的审批人批准后才会应用。
+// 应用仍走常规发布流程，结果落在 caddy_config_history / waf_policy_revisions
+type CaddyChangeRequest struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	Kind        string `gorm:"size:20;index;not null" json:"kind"` // caddy_config | waf_policy
+	ServerID    uint   `gorm:"index;not null" json:"serverId"`
+	PolicyID    uint   `gorm:"index;default:0" json:"policyId"` // kind=waf_policy 时有效
+	Title       string `gorm:"size:200;not null" json:"title"`
+	Description string `gorm:"type:text" json:"description"`
+
+	// Config 为提议的 Caddyfile；waf_policy 为提交时按策略生成的候选配置，应用时重新生成并比对哈希
+	Config       string `gorm:"type:text" json:"config"`
+	Modules      string `gorm:"type:text" json:"modules"`
+	BaseHash     string `gorm:"size:64" json:"baseHash"`     // 提交时节点已保存配置的哈希
+	ProposedHash string `gorm:"size:64" json:"proposedHash"` // 提议配置的哈希
+	Diff         string `gorm:"type:text" json:"diff"`       // 相对提交时配置的 unified diff
+	Added        int    `gorm:"default:0" json:"added"`
+	Removed      int    `gorm:"default:0" json:"removed"`
+
+	Status      string     `gorm:"size:20;index;not null;default:'draft'" json:"status"` // draft | pending | rejected | cancelled | scheduled | applying | applied | failed
+	Author      string     `gorm:"size:100;index" json:"author"`                         // 用户 ID
+	AuthorName  string     `gorm:"size:255" json:"authorName"`
+	SubmittedAt *time.Time `json:"submittedAt,omitempty"`
+
+	Reviewer     string     `gorm:"size:100" json:"reviewer,omitempty"`
+	ReviewerName string     `gorm:"size:255" json:"reviewerName,omitempty"`
+	ReviewedAt   *time.Time `json:"reviewedAt,omitempty"`
+
+	ScheduledAt *time.Time `gorm:"index" json:"scheduledAt,omitempty"` // 为空或已过期时批准后立即应用
+	AppliedAt   *time.Time `json:"appliedAt,omitempty"`
+	HistoryID   uint       `gorm:"default:0" json:"historyId"`         // 应用后生成的 caddy_config_history
+	RevisionID  uint       `gorm:"default:0" json:"revisionId"`        // 应用后生成的 waf_policy_revisions
+	Message     string     `gorm:"type:text" json:"message,omitempty"` // 最近一次应用结果
+}
+
+func (CaddyChangeRequest) TableName() string {
+	return "caddy_change_requests"
+}
+
+// CaddyChangeRequestComment 变更申请的评论与状态流转记录
+type CaddyChangeRequestComment struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+
+	RequestID  uint   `gorm:"index;not null" json:"requestId"`
+	Action     string `gorm:"size:20;not null;default:'comment'" json:"action"` // comment | submit | approve | reject | cancel | apply | fail
+	Author     string `gorm:"size:100" json:"author"`
+	AuthorName string `gorm:"size:255" json:"authorName"`
+	Content    string `gorm:"type:text" json:"content"`
+}
+
+func (CaddyChangeRequestComment) TableName() string {
+	return "caddy_change_request_comments"
+}
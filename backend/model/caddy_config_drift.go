@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// CaddyConfigDrift 运行中的 Caddy 配置与 LogFlux 保存的 Caddyfile 适配结果不一致时的偏差记录，
+// 同一节点同一时刻最多一条 open 记录
+type CaddyConfigDrift struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	ServerID    uint   `gorm:"index;not null" json:"serverId"`
+	Status      string `gorm:"size:20;index;not null;default:'open'" json:"status"` // open | adopted | reapplied | resolved
+	StoredHash  string `gorm:"size:64" json:"storedHash"`                           // 保存的 Caddyfile 哈希
+	RunningHash string `gorm:"size:64;index" json:"runningHash"`                    // 运行配置（规范化 JSON）哈希
+	Summary     string `gorm:"size:255" json:"summary"`
+	Diff        string `gorm:"type:text" json:"diff"`        // JSON 差异列表
+	RunningJSON string `gorm:"type:text" json:"runningJson"` // 检测时的运行配置
+	Message     string `gorm:"type:text" json:"message,omitempty"`
+	// Adoptable 检测时节点 Caddyfile 路径下的文件适配结果与运行配置一致，可直接采用为保存配置；
+	// 否则采用时只把运行配置保存为可重新下发的历史快照
+	Adoptable bool `gorm:"not null;default:false" json:"adoptable"`
+
+	LastSeenAt time.Time  `json:"lastSeenAt"`
+	ResolvedAt *time.Time `json:"resolvedAt,omitempty"`
+	ResolvedBy string     `gorm:"size:100" json:"resolvedBy,omitempty"`
+}
+
+func (CaddyConfigDrift) TableName() string {
+	return "caddy_config_drifts"
+}
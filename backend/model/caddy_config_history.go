@@ -11,6 +11,20 @@ type CaddyConfigHistory struct {
 	Hash      string `gorm:"size:64;index"`
 	Config    string `gorm:"type:text"`
 	Modules   string `gorm:"type:jsonb"`
+
+	Source   string `gorm:"size:20;index;default:'system'"` // ui | waf_publish | rollback | drift_adopt | drift_reapply | system
+	Operator string `gorm:"size:100"`
+	Message  string `gorm:"type:text"` // 变更说明
+
+	// KnownGood 标记为已知良好的版本，未指定目标的回滚优先使用
+	KnownGood bool   `gorm:"index;not null;default:false"`
+	TaggedBy  string `gorm:"size:100"`
+	TaggedAt  *time.Time
+
+	// 加载后健康校验在后台执行：空表示未启用校验，pending | passed | failed
+	VerifyStatus  string `gorm:"size:20;index"`
+	VerifyMessage string `gorm:"type:text"`
+	VerifiedAt    *time.Time
 }
 
 func (CaddyConfigHistory) TableName() string {
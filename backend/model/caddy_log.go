@@ -9,6 +9,9 @@ type CaddyLog struct {
 	CreatedAt time.Time `gorm:"index"` // Index for time-range queries
 	UpdatedAt time.Time
 
+	// ServerID 日志源所属的 Caddy 节点，手动添加的日志源为 0
+	ServerID uint `gorm:"index;not null;default:0"`
+
 	// Parsed from [{ts}]
 	LogTime time.Time `gorm:"index:idx_log_time_status,priority:1;index:idx_log_time;not null"` // 复合索引和单独索引
 
@@ -16,6 +16,8 @@ type CaddyServer struct {
 	Password  string
 	Config    string `gorm:"type:text"`  // Store Caddyfile content
 	Modules   string `gorm:"type:jsonb"` // Store structured modules JSON
+	// CaddyfilePath 节点实际加载的 Caddyfile 路径（LogFlux 可读），采用漂移的运行配置时据此回读
+	CaddyfilePath string `gorm:"size:255"`
 }
 
 func (CaddyServer) TableName() string {
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// CaddyServerGroup Caddy 节点分组，WAF 版本与策略可按分组分发到多个节点
+type CaddyServerGroup struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	Name        string     `gorm:"size:100;uniqueIndex;not null" json:"name"`
+	Description string     `gorm:"size:255" json:"description,omitempty"`
+	ServerIDs   Int64Array `gorm:"type:bigint[];not null;default:'{}'" json:"serverIds"`
+}
+
+func (CaddyServerGroup) TableName() string {
+	return "caddy_server_groups"
+}
@@ -14,6 +14,7 @@ type LogSource struct {
 	Type         string `gorm:"size:50;default:'caddy'"`        // Source type (caddy, nginx, etc)
 	Enabled      bool   `gorm:"default:true"`                   // Is monitoring active?
 	ScanInterval int    `gorm:"default:60"`                     // Directory scan interval (seconds)
+	ServerID     uint   `gorm:"index;not null;default:0"`       // 由 Caddy 节点配置自动发现时所属节点，手动添加为 0
 }
 
 func (LogSource) TableName() string {
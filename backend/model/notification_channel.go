@@ -17,7 +17,7 @@ type NotificationChannel struct {
 
 	// 基本信息
 	Name        string `gorm:"size:100;uniqueIndex;not null" json:"name"`
-	Type        string `gorm:"size:50;index;not null" json:"type"` // webhook, email, telegram, slack, wecom, wechat_mp, dingtalk
+	Type        string `gorm:"size:50;index;not null" json:"type"` // webhook, email, telegram, slack, wecom, wechat_mp, dingtalk, feishu, pagerduty, ntfy, gotify
 	Enabled     bool   `gorm:"default:true;index;not null" json:"enabled"`
 	Description string `gorm:"type:text" json:"description,omitempty"`
 
@@ -153,14 +153,18 @@ func (c *NotificationChannel) BeforeSave(tx *gorm.DB) error {
 
 // ChannelType 渠道类型常量
 const (
-	ChannelTypeWebhook  = "webhook"
-	ChannelTypeEmail    = "email"
-	ChannelTypeTelegram = "telegram"
-	ChannelTypeSlack    = "slack"
-	ChannelTypeWeCom    = "wecom"
-	ChannelTypeWeChatMP = "wechat_mp"
-	ChannelTypeDingTalk = "dingtalk"
-	ChannelTypeDiscord  = "discord"
+	ChannelTypeWebhook   = "webhook"
+	ChannelTypeEmail     = "email"
+	ChannelTypeTelegram  = "telegram"
+	ChannelTypeSlack     = "slack"
+	ChannelTypeWeCom     = "wecom"
+	ChannelTypeWeChatMP  = "wechat_mp"
+	ChannelTypeDingTalk  = "dingtalk"
+	ChannelTypeDiscord   = "discord"
+	ChannelTypeFeishu    = "feishu"
+	ChannelTypePagerDuty = "pagerduty"
+	ChannelTypeNtfy      = "ntfy"
+	ChannelTypeGotify    = "gotify"
 )
 
 // Webhook 配置结构
@@ -215,8 +219,44 @@ type WechatMPConfig struct {
 
 // DingTalk 配置结构
 type DingTalkConfig struct {
-	WebhookURL string `json:"webhook_url"`
-	Secret     string `json:"secret,omitempty"`
+	WebhookURL string   `json:"webhook_url"`
+	Secret     string   `json:"secret,omitempty"`     // 加签密钥 (SEC 开头)，留空时不签名
+	AtMobiles  []string `json:"at_mobiles,omitempty"` // @ 指定手机号
+	AtAll      bool     `json:"at_all,omitempty"`     // @ 所有人
+}
+
+// Feishu/Lark 配置结构（自定义机器人）
+type FeishuConfig struct {
+	WebhookURL string `json:"webhook_url"`      // open.feishu.cn 或 open.larksuite.com 的机器人地址
+	Secret     string `json:"secret,omitempty"` // 签名校验密钥，留空时不签名
+}
+
+// PagerDuty Events v2 配置结构（兼容同协议的服务）
+type PagerDutyConfig struct {
+	RoutingKey    string `json:"routing_key"`
+	EventsURL     string `json:"events_url,omitempty"`      // 默认 https://events.pagerduty.com/v2/enqueue
+	Source        string `json:"source,omitempty"`          // 默认 logflux
+	DedupKeyField string `json:"dedup_key_field,omitempty"` // 从事件数据中读取去重键的字段
+	ResolveField  string `json:"resolve_field,omitempty"`   // 事件数据中该字段为 true 时发送 resolve，默认 resolved
+	Component     string `json:"component,omitempty"`
+	Group         string `json:"group,omitempty"`
+}
+
+// ntfy 配置结构
+type NtfyConfig struct {
+	ServerURL string `json:"server_url"` // 如 https://ntfy.sh
+	Topic     string `json:"topic"`
+	Token     string `json:"token,omitempty"` // Bearer 访问令牌
+	Username  string `json:"username,omitempty"`
+	Password  string `json:"password,omitempty"`
+	Tags      string `json:"tags,omitempty"` // 逗号分隔
+}
+
+// Gotify 配置结构
+type GotifyConfig struct {
+	ServerURL string `json:"server_url"`
+	AppToken  string `json:"app_token"`
+	Priority  int    `json:"priority,omitempty"` // 覆盖按级别推导的优先级
 }
 
 // Discord 配置结构
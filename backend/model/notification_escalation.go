@@ -0,0 +1,143 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// NotificationEscalationPolicy 升级策略模型
+// 每个步骤在上一步发出后等待 DelayMinutes 分钟，若告警仍未确认则通知下一步的渠道/值班人。
+type NotificationEscalationPolicy struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Name        string `gorm:"size:100;uniqueIndex;not null" json:"name"`
+	Enabled     bool   `gorm:"default:true;index;not null" json:"enabled"`
+	Description string `gorm:"type:text" json:"description,omitempty"`
+
+	// 升级步骤 (JSONB)
+	Steps EscalationSteps `gorm:"type:jsonb;not null" json:"steps"`
+}
+
+// TableName 返回表名
+func (NotificationEscalationPolicy) TableName() string {
+	return "notification_escalation_policies"
+}
+
+// EscalationStep 升级步骤
+type EscalationStep struct {
+	DelayMinutes int     `json:"delay_minutes"`          // 距上一步的等待时间（首步忽略）
+	ChannelIDs   []int64 `json:"channel_ids,omitempty"`  // 直接通知的渠道
+	ScheduleIDs  []int64 `json:"schedule_ids,omitempty"` // 通知当前值班人的联系渠道
+}
+
+// EscalationSteps 自定义类型,用于 JSONB 数组字段
+type EscalationSteps []EscalationStep
+
+// Value 实现 driver.Valuer 接口
+func (s EscalationSteps) Value() (driver.Value, error) {
+	if s == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(s)
+}
+
+// Scan 实现 sql.Scanner 接口
+func (s *EscalationSteps) Scan(value interface{}) error {
+	if value == nil {
+		*s = EscalationSteps{}
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return errors.New("解析 EscalationSteps 值失败")
+	}
+
+	return json.Unmarshal(bytes, s)
+}
+
+// NotificationOnCallSchedule 值班表模型（按周期轮换）
+type NotificationOnCallSchedule struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Name        string `gorm:"size:100;uniqueIndex;not null" json:"name"`
+	Enabled     bool   `gorm:"default:true;index;not null" json:"enabled"`
+	Description string `gorm:"type:text" json:"description,omitempty"`
+
+	// 轮换起点（首位值班人的交接时间）
+	RotationStart time.Time `gorm:"not null" json:"rotation_start"`
+	// 轮换周期 (天)，默认 7 天即每周轮换
+	RotationDays int `gorm:"default:7;not null" json:"rotation_days"`
+
+	// 参与轮换的用户 ID (按顺序)
+	Participants Int64Array `gorm:"type:bigint[];not null;default:'{}'" json:"participants"`
+}
+
+// TableName 返回表名
+func (NotificationOnCallSchedule) TableName() string {
+	return "notification_oncall_schedules"
+}
+
+// NotificationUserContact 用户联系渠道
+type NotificationUserContact struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	UserID     uint       `gorm:"uniqueIndex;not null" json:"user_id"`
+	ChannelIDs Int64Array `gorm:"type:bigint[];not null;default:'{}'" json:"channel_ids"`
+}
+
+// TableName 返回表名
+func (NotificationUserContact) TableName() string {
+	return "notification_user_contacts"
+}
+
+// NotificationEscalation 升级实例（一次规则触发对应一条）
+type NotificationEscalation struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	PolicyID uint  `gorm:"index;not null" json:"policy_id"`
+	RuleID   *uint `gorm:"index" json:"rule_id,omitempty"`
+
+	// 事件快照，用于后续步骤重新入队
+	EventType    string  `gorm:"size:100;index;not null" json:"event_type"`
+	EventLevel   string  `gorm:"size:20" json:"event_level"`
+	EventTitle   string  `gorm:"type:text" json:"event_title"`
+	EventMessage string  `gorm:"type:text" json:"event_message"`
+	EventData    JSONMap `gorm:"type:jsonb" json:"event_data,omitempty"`
+	TemplateName string  `gorm:"type:text" json:"template_name,omitempty"`
+
+	Status         string     `gorm:"size:32;index;not null" json:"status"` // active, acknowledged, resolved, exhausted
+	CurrentStep    int        `gorm:"default:0;not null" json:"current_step"`
+	NextStepAt     *time.Time `gorm:"index" json:"next_step_at,omitempty"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	AcknowledgedBy string     `gorm:"size:100" json:"acknowledged_by,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+}
+
+// TableName 返回表名
+func (NotificationEscalation) TableName() string {
+	return "notification_escalations"
+}
+
+// EscalationStatus 升级实例状态常量
+const (
+	EscalationStatusActive       = "active"
+	EscalationStatusAcknowledged = "acknowledged"
+	EscalationStatusResolved     = "resolved"
+	EscalationStatusExhausted    = "exhausted"
+)
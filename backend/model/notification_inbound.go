@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// NotificationInboundToken 入站告警令牌
+// 外部系统 (Alertmanager / Grafana / 自定义 JSON) 通过 POST /api/notification/inbound/{token} 推送告警，
+// 转换为通知事件后进入规则匹配与渠道分发。
+type NotificationInboundToken struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Name        string `gorm:"size:100;uniqueIndex;not null" json:"name"`
+	Token       string `gorm:"size:64;uniqueIndex;not null" json:"token"`
+	Enabled     bool   `gorm:"default:true;index;not null" json:"enabled"`
+	Description string `gorm:"type:text" json:"description,omitempty"`
+
+	// 负载格式: alertmanager, grafana, generic
+	Format string `gorm:"size:20;not null" json:"format"`
+	// 事件类型，为空时按格式使用 inbound.<format>
+	EventType string `gorm:"size:100" json:"event_type,omitempty"`
+	// 通用格式的字段映射 (JSONB)，见 notification.InboundMapping
+	Mapping JSONMap `gorm:"type:jsonb" json:"mapping,omitempty"`
+
+	// 每分钟允许的请求数，0 表示不限流
+	RateLimit int `gorm:"default:60;not null" json:"rate_limit"`
+
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// TableName 返回表名
+func (NotificationInboundToken) TableName() string {
+	return "notification_inbound_tokens"
+}
@@ -19,7 +19,11 @@ type NotificationJob struct {
 	EventData    JSONMap `gorm:"type:jsonb" json:"event_data,omitempty"`
 	TemplateName string  `gorm:"type:text" json:"template_name,omitempty"`
 
-	Status        string     `gorm:"size:50;index;not null" json:"status"` // queued, processing, succeeded, failed
+	// 升级链路 (可选)
+	EscalationID   *uint `gorm:"index" json:"escalation_id,omitempty"`
+	EscalationStep int   `gorm:"default:0" json:"escalation_step"`
+
+	Status        string     `gorm:"size:50;index;not null" json:"status"` // queued, processing, digesting, succeeded, failed
 	RetryCount    int        `gorm:"default:0" json:"retry_count"`
 	NextRunAt     time.Time  `gorm:"index" json:"next_run_at"`
 	LastError     string     `gorm:"type:text" json:"last_error,omitempty"`
@@ -33,6 +37,7 @@ func (NotificationJob) TableName() string {
 const (
 	NotificationJobStatusQueued     = "queued"
 	NotificationJobStatusProcessing = "processing"
+	NotificationJobStatusDigesting  = "digesting" // 等待合并进摘要消息
 	NotificationJobStatusSucceeded  = "succeeded"
 	NotificationJobStatusFailed     = "failed"
 )
@@ -13,6 +13,10 @@ type NotificationLog struct {
 	ChannelID *uint `gorm:"index" json:"channel_id"`        // Allow NULL for deleted channels
 	RuleID    *uint `gorm:"index" json:"rule_id,omitempty"` // 可选,手动发送的通知没有规则
 
+	// 升级链路 (可选)
+	EscalationID   *uint `gorm:"index" json:"escalation_id,omitempty"`
+	EscalationStep int   `gorm:"default:0" json:"escalation_step"`
+
 	// 事件信息
 	EventType string  `gorm:"size:100;index;not null" json:"event_type"`
 	EventData JSONMap `gorm:"type:jsonb" json:"event_data,omitempty"`
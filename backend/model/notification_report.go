@@ -0,0 +1,70 @@
+package model
+
+import "time"
+
+// NotificationReport 定时报表定义
+// 按周期统计 caddy_logs 的流量与安全数据，渲染后发送到指定通知渠道。
+type NotificationReport struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Name        string `gorm:"size:100;uniqueIndex;not null" json:"name"`
+	Enabled     bool   `gorm:"default:true;index;not null" json:"enabled"`
+	Description string `gorm:"type:text" json:"description,omitempty"`
+
+	// 统计周期: daily, weekly, monthly
+	Period string `gorm:"size:20;not null" json:"period"`
+	// 发送时间 (带秒的 cron 表达式)，为空时按周期使用默认时间
+	Schedule string `gorm:"size:100" json:"schedule,omitempty"`
+
+	// 统计范围：为空表示全部站点 / 全部策略
+	Hosts     StringArray `gorm:"type:text[];not null;default:'{}'" json:"hosts"`
+	PolicyIDs Int64Array  `gorm:"type:bigint[];not null;default:'{}'" json:"policy_ids"`
+
+	// 接收渠道
+	ChannelIDs Int64Array `gorm:"type:bigint[];not null;default:'{}'" json:"channel_ids"`
+
+	// 模板：邮件渠道使用 HTML 模板，其余渠道使用 Markdown 模板
+	HTMLTemplate     string `gorm:"size:100" json:"html_template,omitempty"`
+	MarkdownTemplate string `gorm:"size:100" json:"markdown_template,omitempty"`
+	TopN             int    `gorm:"default:10;not null" json:"top_n"`
+
+	LastRunAt  *time.Time `json:"last_run_at,omitempty"`
+	LastStatus string     `gorm:"size:20" json:"last_status,omitempty"` // success, partial, failed
+	LastError  string     `gorm:"type:text" json:"last_error,omitempty"`
+}
+
+// TableName 返回表名
+func (NotificationReport) TableName() string {
+	return "notification_reports"
+}
+
+// CronSchedule 返回实际生效的 cron 表达式（未配置时按周期默认早上 8 点发送）
+func (r *NotificationReport) CronSchedule() string {
+	if r.Schedule != "" {
+		return r.Schedule
+	}
+	switch r.Period {
+	case ReportPeriodWeekly:
+		return "0 0 8 * * 1"
+	case ReportPeriodMonthly:
+		return "0 0 8 1 * *"
+	default:
+		return "0 0 8 * * *"
+	}
+}
+
+// ReportPeriod 报表周期常量
+const (
+	ReportPeriodDaily   = "daily"
+	ReportPeriodWeekly  = "weekly"
+	ReportPeriodMonthly = "monthly"
+)
+
+// ReportStatus 报表执行结果常量
+const (
+	ReportStatusSuccess = "success"
+	ReportStatusPartial = "partial"
+	ReportStatusFailed  = "failed"
+)
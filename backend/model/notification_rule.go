@@ -31,6 +31,9 @@ type NotificationRule struct {
 	// 通知渠道 ID
 	ChannelIDs Int64Array `gorm:"type:bigint[];not null;default:'{}'" json:"channel_ids"`
 
+	// 升级策略 (可选)，配置后规则渠道由升级策略的步骤决定
+	EscalationPolicyID *uint `gorm:"index" json:"escalation_policy_id,omitempty"`
+
 	// 通知模板 (可选)
 	Template string `gorm:"type:text" json:"template,omitempty"`
 
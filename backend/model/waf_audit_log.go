@@ -0,0 +1,65 @@
+package model
+
+import "time"
+
+// WafAuditLog Coraza WAF 审计事件，一条记录对应一个被审计的事务
+type WafAuditLog struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	// 事务信息
+	TransactionID string    `gorm:"size:128;index" json:"transactionId"`
+	LogTime       time.Time `gorm:"index:idx_waf_audit_log_time;index:idx_waf_audit_client_time,priority:2;not null" json:"logTime"`
+	ClientIP      string    `gorm:"size:50;index:idx_waf_audit_client_time,priority:1" json:"clientIp"`
+	Host          string    `gorm:"size:255;index" json:"host"`
+	Method        string    `gorm:"size:10" json:"method"`
+	Uri           string    `gorm:"type:text" json:"uri"`
+	Status        int       `gorm:"index" json:"status"`
+
+	// 命中规则
+	RuleIDs          Int64Array  `gorm:"type:bigint[];not null;default:'{}'" json:"ruleIds"`
+	Tags             StringArray `gorm:"type:text[];not null;default:'{}'" json:"tags"`
+	MatchedVariables StringArray `gorm:"type:text[];not null;default:'{}'" json:"matchedVariables"`
+	Messages         string      `gorm:"type:jsonb;comment:命中规则消息列表" json:"messages"`
+	Severity         string      `gorm:"size:20" json:"severity"`
+
+	// CRS 异常评分
+	InboundAnomalyScore  int `gorm:"not null;default:0" json:"inboundAnomalyScore"`
+	OutboundAnomalyScore int `gorm:"not null;default:0" json:"outboundAnomalyScore"`
+
+	// 处置动作: blocked | detected
+	Action string `gorm:"size:16;index" json:"action"`
+
+	// 命中时生效的策略（按作用域绑定解析，无法确定时为默认策略）
+	PolicyID   uint   `gorm:"index;not null;default:0" json:"policyId"`
+	PolicyName string `gorm:"size:120" json:"policyName"`
+
+	// 灰度实例（DetectionOnly）产生的事件，对应 waf_policy_rollouts.id，0 表示生效实例
+	RolloutID uint `gorm:"index;not null;default:0" json:"rolloutId"`
+
+	// 关联的访问日志 (caddy_logs.id)，尚未匹配时为空
+	CaddyLogID *uint `gorm:"index" json:"caddyLogId,omitempty"`
+
+	RawLog string `gorm:"type:text;comment:原始审计日志" json:"-"`
+}
+
+// WafAuditMessage 单条规则命中消息，序列化后存入 WafAuditLog.Messages
+type WafAuditMessage struct {
+	RuleID   int64    `json:"ruleId"`
+	Message  string   `json:"message"`
+	Data     string   `json:"data,omitempty"`
+	Severity string   `json:"severity,omitempty"`
+	Variable string   `json:"variable,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+const (
+	WafAuditActionBlocked  = "blocked"
+	WafAuditActionDetected = "detected"
+)
+
+// TableName 返回表名
+func (WafAuditLog) TableName() string {
+	return "waf_audit_logs"
+}
@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+// WafBanRule 自动封禁规则：窗口内同一客户端 IP 命中指定状态码的请求数达到阈值即临时封禁
+type WafBanRule struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	Name        string `gorm:"size:120;not null;default:''" json:"name"`
+	Description string `gorm:"size:255" json:"description,omitempty"`
+	Enabled     bool   `gorm:"index;not null;default:true" json:"enabled"`
+
+	Host          string `gorm:"size:255;not null;default:''" json:"host"`                  // 为空表示全部站点；非空时封禁仅作用于该站点
+	StatusCodes   string `gorm:"size:64;not null;default:'401,403,429'" json:"statusCodes"` // 逗号分隔，WAF 拦截在访问日志中记为 403
+	Threshold     int64  `gorm:"not null;default:20" json:"threshold"`
+	WindowMinutes int64  `gorm:"not null;default:5" json:"windowMinutes"`
+	BanMinutes    int64  `gorm:"not null;default:60" json:"banMinutes"`
+
+	LastEvaluatedAt *time.Time `json:"lastEvaluatedAt,omitempty"`
+}
+
+func (WafBanRule) TableName() string {
+	return "waf_ban_rules"
+}
+
+const (
+	WafBanStatusActive  = "active"
+	WafBanStatusExpired = "expired"
+	WafBanStatusRevoked = "revoked"
+)
+
+// WafBan 临时封禁记录，同时作为审计轨迹：到期或撤销后保留记录，仅变更状态
+type WafBan struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	RuleID   uint   `gorm:"index;not null;default:0" json:"ruleId"` // 0 表示手动封禁
+	IP       string `gorm:"size:64;index;not null" json:"ip"`
+	Host     string `gorm:"size:255;not null;default:''" json:"host"`
+	HitCount int64  `gorm:"not null;default:0" json:"hitCount"`
+	Reason   string `gorm:"size:255" json:"reason,omitempty"`
+
+	Status    string     `gorm:"size:16;index;not null;default:'active'" json:"status"` // active | expired | revoked
+	ExpiresAt time.Time  `gorm:"index;not null" json:"expiresAt"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+	Operator  string     `gorm:"size:64" json:"operator,omitempty"`
+}
+
+func (WafBan) TableName() string {
+	return "waf_bans"
+}
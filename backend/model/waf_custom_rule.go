@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// WafCustomRule 策略自定义 SecLang 规则（虚拟补丁、地域/IP 封禁、限流标记等）
+type WafCustomRule struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	PolicyID uint `gorm:"uniqueIndex:idx_waf_custom_rule_policy_rule;not null" json:"policyId"`
+	Policy   WafPolicy
+
+	Name        string `gorm:"size:120;not null;default:''" json:"name"`
+	Description string `gorm:"size:255" json:"description,omitempty"`
+	Enabled     bool   `gorm:"index;not null;default:true" json:"enabled"`
+
+	// RuleID 与 Phase 由 Directive 中的 id/phase 动作解析得到
+	RuleID   int64 `gorm:"uniqueIndex:idx_waf_custom_rule_policy_rule;not null" json:"ruleId"`
+	Phase    int64 `gorm:"not null;default:2" json:"phase"`
+	Priority int64 `gorm:"index;not null;default:100" json:"priority"` // 越小越先渲染
+
+	Directive string `gorm:"type:text;not null" json:"directive"`
+}
+
+func (WafCustomRule) TableName() string {
+	return "waf_custom_rules"
+}
@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+// WafIPList 策略 IP 名单，绑定到策略或策略下的某个绑定作用域
+type WafIPList struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	PolicyID  uint `gorm:"index;not null" json:"policyId"`
+	BindingID uint `gorm:"index;not null;default:0" json:"bindingId"` // 0 表示作用于整个策略
+
+	Name        string `gorm:"size:120;not null;default:''" json:"name"`
+	Description string `gorm:"size:255" json:"description,omitempty"`
+	Action      string `gorm:"size:16;not null;default:'deny'" json:"action"` // allow（跳过 WAF 检测）| deny（直接拒绝）
+	Enabled     bool   `gorm:"index;not null;default:true" json:"enabled"`
+}
+
+func (WafIPList) TableName() string {
+	return "waf_ip_lists"
+}
+
+// WafIPListEntry 名单条目：IP、CIDR 或国家/地区代码，可设置到期时间
+type WafIPListEntry struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	ListID uint   `gorm:"uniqueIndex:idx_waf_ip_list_entry_value,priority:1;not null" json:"listId"`
+	Kind   string `gorm:"size:16;not null;default:'ip'" json:"kind"`                                      // ip（含 CIDR）| country
+	Value  string `gorm:"size:64;uniqueIndex:idx_waf_ip_list_entry_value,priority:2;not null" json:"value"` // 规范化后的 IP/CIDR 或 ISO 3166-1 国家代码
+	Note   string `gorm:"size:255" json:"note,omitempty"`
+
+	// 条目来源：manual | caddy_log | dashboard，SourceRef 记录来源日志 ID 等
+	Source    string `gorm:"size:32;not null;default:'manual'" json:"source"`
+	SourceRef string `gorm:"size:64" json:"sourceRef,omitempty"`
+
+	ExpiresAt *time.Time `gorm:"index" json:"expiresAt,omitempty"`
+}
+
+func (WafIPListEntry) TableName() string {
+	return "waf_ip_list_entries"
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// WafMirrorClient 下游实例访问本实例规则镜像的凭据，可将下游固定到指定版本
+type WafMirrorClient struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	Name          string `gorm:"size:100;uniqueIndex;not null" json:"name"`
+	Token         string `gorm:"size:64;uniqueIndex;not null" json:"token"`
+	Kind          string `gorm:"size:32;not null;default:'crs'" json:"kind"`
+	PinnedVersion string `gorm:"size:120" json:"pinnedVersion,omitempty"` // 为空时跟随当前激活版本
+	Enabled       bool   `gorm:"default:true;index;not null" json:"enabled"`
+	Description   string `gorm:"size:255" json:"description,omitempty"`
+
+	LastUsedAt     *time.Time `json:"lastUsedAt,omitempty"`
+	LastClientIP   string     `gorm:"size:64" json:"lastClientIp,omitempty"`
+	LastServedFile string     `gorm:"size:255" json:"lastServedFile,omitempty"`
+}
+
+func (WafMirrorClient) TableName() string {
+	return "waf_mirror_clients"
+}
@@ -23,6 +23,7 @@ type WafPolicyFalsePositiveFeedback struct {
 	ProcessNote    string     `gorm:"type:text"`
 	ProcessedBy    string     `gorm:"size:64;index"`
 	ProcessedAt    *time.Time `gorm:"index"`
+	ExclusionID    *uint      `gorm:"index"` // 由该反馈生成的排除规则 (waf_rule_exclusions.id)
 }
 
 func (WafPolicyFalsePositiveFeedback) TableName() string {
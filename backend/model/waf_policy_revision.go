@@ -16,6 +16,8 @@ type WafPolicyRevision struct {
 
 	ConfigSnapshot     JSONMap `gorm:"type:jsonb" json:"configSnapshot,omitempty"`
 	DirectivesSnapshot string  `gorm:"type:text" json:"directivesSnapshot,omitempty"`
+	// 生成版本时已启用自定义规则的 JSON 快照
+	CustomRulesSnapshot string `gorm:"type:text" json:"customRulesSnapshot,omitempty"`
 
 	Operator string `gorm:"size:100" json:"operator,omitempty"`
 	Message  string `gorm:"type:text" json:"message,omitempty"`
@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+// WafPolicyRollout 策略灰度发布：候选版本先在选定绑定作用域以 DetectionOnly 运行，
+// 已发布版本继续拦截，观察期满后人工或自动转正
+type WafPolicyRollout struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	PolicyID uint `gorm:"index;not null" json:"policyId"`
+	Policy   WafPolicy
+
+	BaselineRevisionID  uint       `gorm:"not null;default:0" json:"baselineRevisionId"` // 灰度期间继续拦截的已发布版本，0 表示策略尚未发布
+	CandidateRevisionID uint       `gorm:"index;not null;default:0" json:"candidateRevisionId"`
+	BindingIDs          Int64Array `gorm:"type:bigint[];not null;default:'{}'" json:"bindingIds"`
+
+	Status string `gorm:"size:16;index;not null;default:'canary'" json:"status"` // canary | promoted | aborted
+
+	// 自动转正：观察满 ObserveHours 且误报率不高于 MaxFalsePositiveRate（百分比）
+	AutoPromote          bool    `gorm:"not null;default:false" json:"autoPromote"`
+	MaxFalsePositiveRate float64 `gorm:"not null;default:1" json:"maxFalsePositiveRate"`
+	ObserveHours         int64   `gorm:"not null;default:24" json:"observeHours"`
+
+	// 最近一次评估结果
+	RequestCount      int64      `gorm:"not null;default:0" json:"requestCount"`
+	WouldBlockCount   int64      `gorm:"not null;default:0" json:"wouldBlockCount"`
+	NewlyBlockedCount int64      `gorm:"not null;default:0" json:"newlyBlockedCount"`
+	FalsePositiveRate float64    `gorm:"not null;default:0" json:"falsePositiveRate"`
+	EvaluatedAt       *time.Time `json:"evaluatedAt,omitempty"`
+
+	StartedAt  time.Time  `gorm:"not null" json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	Operator   string     `gorm:"size:100" json:"operator,omitempty"`
+	Message    string     `gorm:"type:text" json:"message,omitempty"`
+}
+
+// WafRolloutSignaturePrefix 灰度实例写入 SecComponentSignature 的前缀，后接灰度 ID
+const WafRolloutSignaturePrefix = "logflux-canary-"
+
+func (WafPolicyRollout) TableName() string {
+	return "waf_policy_rollouts"
+}
@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// WafRateLimitZone 限流区域，随所属策略渲染为 caddy-ratelimit 的 zone，作用域来自策略或策略绑定
+type WafRateLimitZone struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	PolicyID  uint `gorm:"index;not null" json:"policyId"`
+	BindingID uint `gorm:"index;not null;default:0" json:"bindingId"` // 0 表示作用于整个策略
+
+	Name        string `gorm:"size:48;uniqueIndex;not null" json:"name"` // 渲染为 zone 名称，全局唯一
+	Description string `gorm:"size:255" json:"description,omitempty"`
+	Enabled     bool   `gorm:"index;not null;default:true" json:"enabled"`
+
+	KeyType   string `gorm:"size:16;not null;default:'ip'" json:"keyType"` // ip | header | path
+	KeyHeader string `gorm:"size:64" json:"keyHeader,omitempty"`           // keyType=header 时的请求头名称
+
+	Events        int64 `gorm:"not null" json:"events"`          // 窗口内允许的请求数
+	WindowSeconds int64 `gorm:"not null" json:"windowSeconds"`   // 滑动窗口长度
+	Burst         int64 `gorm:"not null;default:0" json:"burst"` // 突发余量，窗口内在 Events 之上额外允许的请求数，0 表示无余量
+}
+
+func (WafRateLimitZone) TableName() string {
+	return "waf_rate_limit_zones"
+}
@@ -20,8 +20,12 @@ type WafRuleExclusion struct {
 	Path      string `gorm:"size:255;index;not null;default:''" json:"path"`
 	Method    string `gorm:"size:32;index;not null;default:''" json:"method"`
 
-	RemoveType  string `gorm:"size:16;not null;default:'id'" json:"removeType"` // id | tag
-	RemoveValue string `gorm:"size:255;not null;default:''" json:"removeValue"`
+	RemoveType   string `gorm:"size:16;not null;default:'id'" json:"removeType"` // id | tag | target_id | target_tag
+	RemoveValue  string `gorm:"size:255;not null;default:''" json:"removeValue"`
+	RemoveTarget string `gorm:"size:255;not null;default:''" json:"removeTarget"` // 按目标移除时的检查目标，如 ARGS:content
+
+	// 由误报反馈一键生成时关联的反馈记录
+	FeedbackID *uint `gorm:"index" json:"feedbackId,omitempty"`
 }
 
 func (WafRuleExclusion) TableName() string {
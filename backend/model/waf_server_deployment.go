@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// WafServerDeployment 多节点分发时单个 Caddy 节点的部署状态，按分发任务聚合
+type WafServerDeployment struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	JobID      uint   `gorm:"index;not null" json:"jobId"`
+	TargetType string `gorm:"size:20;index;not null" json:"targetType"` // release | policy
+	TargetID   uint   `gorm:"index;not null" json:"targetId"`
+	ServerID   uint   `gorm:"index;not null" json:"serverId"`
+	ServerName string `gorm:"size:100" json:"serverName"`
+
+	Status     string     `gorm:"size:20;index;not null;default:'pending'" json:"status"` // pending | running | success | failed | skipped | rolled_back
+	Message    string     `gorm:"type:text" json:"message,omitempty"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+func (WafServerDeployment) TableName() string {
+	return "waf_server_deployments"
+}
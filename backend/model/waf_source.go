@@ -18,6 +18,11 @@ type WafSource struct {
 	AuthType   string `gorm:"size:20;not null;default:'none'" json:"authType"` // none | token | basic
 	AuthSecret string `gorm:"type:text" json:"authSecret,omitempty"`
 
+	// 信任配置：启用后包必须带有受信公钥签发的分离签名才能进入 verified
+	SignatureType string `gorm:"size:20;not null;default:'none'" json:"signatureType"` // none | minisign | gpg | cosign
+	SignatureURL  string `gorm:"type:text" json:"signatureUrl,omitempty"`              // 支持 {url} {version} 占位符，为空按类型追加默认后缀
+	PublicKeys    string `gorm:"type:text" json:"publicKeys,omitempty"`
+
 	Schedule string `gorm:"size:120" json:"schedule,omitempty"`
 
 	Enabled      bool `gorm:"default:true;index;not null" json:"enabled"`
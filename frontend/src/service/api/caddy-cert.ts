@@ -0,0 +1,53 @@
+import { request } from '../request';
+
+export type CaddyCertificateStatus = 'unknown' | 'valid' | 'expiring' | 'expired' | 'error';
+
+export interface CaddyCertificateItem {
+  id: number;
+  serverId: number;
+  serverName: string;
+  /** 站点域名；PKI 证书为 pki:<ca>/root 等 */
+  host: string;
+  source: 'site' | 'pki';
+  subject: string;
+  issuer: string;
+  sans: string[];
+  serialNumber: string;
+  fingerprint: string;
+  notBefore: string;
+  notAfter: string;
+  /** 剩余天数，notAfter 为空时为 0 */
+  daysLeft: number;
+  status: CaddyCertificateStatus;
+  verifyError: string;
+  probeError: string;
+  lastProbedAt: string;
+  renewalError: string;
+  renewalErrorAt: string;
+  updatedAt: string;
+}
+
+export interface CaddyCertificateListResp {
+  list: CaddyCertificateItem[];
+  total: number;
+}
+
+export function fetchCaddyCertificates(params: {
+  serverId?: number;
+  status?: string;
+  keyword?: string;
+  expiringWithinDays?: number;
+  page?: number;
+  pageSize?: number;
+}) {
+  return request<CaddyCertificateListResp>({ url: '/api/caddy/certificate', params });
+}
+
+/** 刷新节点证书清单，probe 为 true 时对站点做 TLS 握手探测 */
+export function checkCaddyCertificates(serverId: number, probe: boolean) {
+  return request<CaddyCertificateListResp>({
+    url: `/api/caddy/server/${serverId}/certificate/check`,
+    method: 'post',
+    data: { probe }
+  });
+}
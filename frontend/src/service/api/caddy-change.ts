@@ -0,0 +1,114 @@
+import { request } from '../request';
+
+export type CaddyChangeRequestKind = 'caddy_config' | 'waf_policy';
+
+export type CaddyChangeRequestStatus =
+  | 'draft'
+  | 'pending'
+  | 'rejected'
+  | 'cancelled'
+  | 'scheduled'
+  | 'applying'
+  | 'applied'
+  | 'failed';
+
+export interface CaddyChangeRequestItem {
+  id: number;
+  kind: CaddyChangeRequestKind;
+  serverId: number;
+  serverName: string;
+  policyId: number;
+  policyName: string;
+  title: string;
+  description: string;
+  status: CaddyChangeRequestStatus;
+  added: number;
+  removed: number;
+  author: string;
+  authorName: string;
+  reviewer: string;
+  reviewerName: string;
+  submittedAt: string;
+  reviewedAt: string;
+  scheduledAt: string;
+  appliedAt: string;
+  historyId: number;
+  revisionId: number;
+  message: string;
+  createdAt: string;
+  updatedAt: string;
+}
+
+export interface CaddyChangeRequestComment {
+  id: number;
+  action: 'comment' | 'submit' | 'approve' | 'reject' | 'cancel' | 'apply' | 'fail';
+  author: string;
+  authorName: string;
+  content: string;
+  createdAt: string;
+}
+
+export interface CaddyChangeRequestDetail {
+  request: CaddyChangeRequestItem;
+  config: string;
+  modules: string;
+  diff: string;
+  /** 节点配置在提交后已被修改，应用时会失败 */
+  stale: boolean;
+  comments: CaddyChangeRequestComment[];
+}
+
+export interface CaddyChangeRequestListResp {
+  list: CaddyChangeRequestItem[];
+  total: number;
+}
+
+export interface CaddyChangeRequestPayload {
+  kind: CaddyChangeRequestKind;
+  serverId?: number;
+  policyId?: number;
+  title: string;
+  description?: string;
+  config?: string;
+  modules?: string;
+  /** 期望应用时间，为空表示批准后立即应用 */
+  scheduledAt?: string;
+  /** true 直接提交审批，否则保存为草稿 */
+  submit?: boolean;
+}
+
+export function fetchCaddyChangeRequests(params: {
+  serverId?: number;
+  status?: string;
+  kind?: string;
+  page?: number;
+  pageSize?: number;
+}) {
+  return request<CaddyChangeRequestListResp>({ url: '/api/caddy/change-request', params });
+}
+
+export function fetchCaddyChangeRequest(id: number) {
+  return request<CaddyChangeRequestDetail>({ url: `/api/caddy/change-request/${id}` });
+}
+
+export function createCaddyChangeRequest(data: CaddyChangeRequestPayload) {
+  return request<CaddyChangeRequestItem>({ url: '/api/caddy/change-request', method: 'post', data });
+}
+
+export function updateCaddyChangeRequest(id: number, data: Omit<CaddyChangeRequestPayload, 'kind'>) {
+  return request<CaddyChangeRequestItem>({ url: `/api/caddy/change-request/${id}`, method: 'put', data });
+}
+
+export type CaddyChangeRequestAction = 'submit' | 'comment' | 'approve' | 'reject' | 'cancel' | 'apply';
+
+export function actOnCaddyChangeRequest(
+  id: number,
+  action: CaddyChangeRequestAction,
+  data?: { comment?: string; scheduledAt?: string }
+) {
+  return request<CaddyChangeRequestItem>({
+    url: `/api/caddy/change-request/${id}/${action}`,
+    method: 'post',
+    data: data || {}
+  });
+}
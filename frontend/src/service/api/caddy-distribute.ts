@@ -0,0 +1,80 @@
+import { request } from '../request';
+
+export interface CaddyServerGroupItem {
+  id: number;
+  name: string;
+  description: string;
+  serverIds: number[];
+  serverNames: string[];
+  createdAt: string;
+  updatedAt: string;
+}
+
+export interface CaddyServerGroupPayload {
+  name: string;
+  description?: string;
+  serverIds: number[];
+}
+
+export type WafDistributeTargetType = 'release' | 'policy';
+export type WafDistributeStrategy = 'sequential' | 'parallel';
+
+export interface WafDistributePayload {
+  targetType: WafDistributeTargetType;
+  targetId: number;
+  groupId?: number;
+  strategy: WafDistributeStrategy;
+  stopOnFailure: boolean;
+}
+
+export type WafServerDeploymentStatus = 'pending' | 'running' | 'success' | 'failed' | 'skipped' | 'rolled_back';
+
+export interface WafServerDeploymentItem {
+  id: number;
+  jobId: number;
+  targetType: WafDistributeTargetType;
+  targetId: number;
+  serverId: number;
+  serverName: string;
+  status: WafServerDeploymentStatus;
+  message: string;
+  startedAt: string;
+  finishedAt: string;
+  createdAt: string;
+}
+
+export interface WafServerDeploymentListResp {
+  list: WafServerDeploymentItem[];
+  total: number;
+}
+
+export function fetchCaddyServerGroups() {
+  return request<{ list: CaddyServerGroupItem[] }>({ url: '/api/caddy/server-group' });
+}
+
+export function createCaddyServerGroup(data: CaddyServerGroupPayload) {
+  return request<any>({ url: '/api/caddy/server-group', method: 'post', data });
+}
+
+export function updateCaddyServerGroup(id: number, data: CaddyServerGroupPayload) {
+  return request<any>({ url: `/api/caddy/server-group/${id}`, method: 'put', data });
+}
+
+export function deleteCaddyServerGroup(id: number) {
+  return request<any>({ url: `/api/caddy/server-group/${id}`, method: 'delete' });
+}
+
+export function distributeWaf(data: WafDistributePayload) {
+  return request<{ jobId: number }>({ url: '/api/caddy/waf/distribute', method: 'post', data });
+}
+
+export function fetchWafServerDeployments(params: {
+  page: number;
+  pageSize: number;
+  jobId?: number;
+  targetType?: WafDistributeTargetType;
+  serverId?: number;
+  status?: string;
+}) {
+  return request<WafServerDeploymentListResp>({ url: '/api/caddy/waf/deployment', params });
+}
@@ -0,0 +1,55 @@
+import { request } from '../request';
+
+export type CaddyConfigDriftStatus = 'open' | 'adopted' | 'reapplied' | 'resolved';
+
+export interface CaddyConfigDriftItem {
+  id: number;
+  serverId: number;
+  serverName: string;
+  status: CaddyConfigDriftStatus;
+  summary: string;
+  /** JSON 数组字符串：[{ path, op, before, after }] */
+  diff: string;
+  storedHash: string;
+  runningHash: string;
+  message: string;
+  /** 节点 Caddyfile 与运行配置一致，可采用为保存配置；否则采用时仅保存运行配置快照 */
+  adoptable: boolean;
+  lastSeenAt: string;
+  resolvedAt: string;
+  resolvedBy: string;
+  createdAt: string;
+}
+
+export interface CaddyConfigDriftDiffEntry {
+  path: string;
+  op: 'added' | 'removed' | 'changed';
+  before?: unknown;
+  after?: unknown;
+}
+
+export interface CaddyConfigDriftListResp {
+  list: CaddyConfigDriftItem[];
+  total: number;
+}
+
+export interface CaddyConfigDriftCheckResp {
+  drifted: boolean;
+  drift?: CaddyConfigDriftItem;
+}
+
+export function fetchCaddyConfigDrifts(params: { serverId?: number; status?: string; page?: number; pageSize?: number }) {
+  return request<CaddyConfigDriftListResp>({ url: '/api/caddy/drift', params });
+}
+
+export function checkCaddyConfigDrift(serverId: number) {
+  return request<CaddyConfigDriftCheckResp>({ url: `/api/caddy/server/${serverId}/drift/check`, method: 'post' });
+}
+
+export function adoptCaddyConfigDrift(id: number) {
+  return request<any>({ url: `/api/caddy/drift/${id}/adopt`, method: 'post' });
+}
+
+export function reapplyCaddyConfigDrift(id: number) {
+  return request<any>({ url: `/api/caddy/drift/${id}/reapply`, method: 'post' });
+}
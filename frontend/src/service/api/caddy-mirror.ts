@@ -0,0 +1,50 @@
+import { request } from '../request';
+
+export interface WafMirrorClientItem {
+  id: number;
+  name: string;
+  token: string;
+  kind: string;
+  pinnedVersion: string;
+  enabled: boolean;
+  description: string;
+  manifestUrl: string;
+  lastUsedAt: string;
+  lastClientIp: string;
+  lastServedFile: string;
+  createdAt: string;
+  updatedAt: string;
+}
+
+export interface WafMirrorClientListResp {
+  list: WafMirrorClientItem[];
+  total: number;
+}
+
+export interface WafMirrorClientPayload {
+  name: string;
+  kind?: string;
+  pinnedVersion?: string;
+  enabled?: boolean;
+  description?: string;
+}
+
+export function fetchWafMirrorClientList(params: { page: number; pageSize: number; keyword?: string }) {
+  return request<WafMirrorClientListResp>({ url: '/api/caddy/waf/mirror', params });
+}
+
+export function createWafMirrorClient(data: WafMirrorClientPayload) {
+  return request<any>({ url: '/api/caddy/waf/mirror', method: 'post', data });
+}
+
+export function updateWafMirrorClient(id: number, data: WafMirrorClientPayload & { enabled: boolean }) {
+  return request<any>({ url: `/api/caddy/waf/mirror/${id}`, method: 'put', data });
+}
+
+export function deleteWafMirrorClient(id: number) {
+  return request<any>({ url: `/api/caddy/waf/mirror/${id}`, method: 'delete' });
+}
+
+export function rotateWafMirrorClient(id: number) {
+  return request<any>({ url: `/api/caddy/waf/mirror/${id}/rotate`, method: 'post' });
+}
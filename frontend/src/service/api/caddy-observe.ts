@@ -1,4 +1,5 @@
 import { request } from '../request';
+import type { WafRuleExclusionPayload } from './caddy-policy';
 
 export interface WafPolicyStatsItem {
   policyId: number;
@@ -7,6 +8,7 @@ export interface WafPolicyStatsItem {
   blockedCount: number;
   allowedCount: number;
   suspectedFalsePositiveCount: number;
+  rateLimitedCount: number;
   blockRate: number;
 }
 
@@ -15,6 +17,7 @@ export interface WafPolicyStatsTrendItem {
   hitCount: number;
   blockedCount: number;
   allowedCount: number;
+  rateLimitedCount: number;
 }
 
 export interface WafPolicyStatsDimensionItem {
@@ -58,6 +61,7 @@ export interface WafPolicyFalsePositiveFeedbackItem {
   processNote: string;
   processedBy: string;
   processedAt: string;
+  exclusionId: number;
   createdAt: string;
 }
 
@@ -142,3 +146,39 @@ export function batchUpdateWafPolicyFalsePositiveFeedbackStatus(data: WafPolicyF
     data
   });
 }
+
+export interface WafPolicyFalsePositiveFeedbackRuleHit {
+  ruleId: number;
+  message: string;
+  hitCount: number;
+  variables: string[];
+}
+
+export interface WafPolicyFalsePositiveFeedbackSuggestionResp {
+  feedbackId: number;
+  exclusionId: number;
+  matchedEventCount: number;
+  ruleHits: WafPolicyFalsePositiveFeedbackRuleHit[];
+  candidate: WafRuleExclusionPayload;
+  rationale: string;
+  exclusionDirectives: string;
+  directives: string;
+}
+
+export type WafPolicyFalsePositiveFeedbackApplyPayload = Partial<WafRuleExclusionPayload> & {
+  processNote?: string;
+};
+
+export function fetchWafPolicyFalsePositiveFeedbackSuggestion(id: number) {
+  return request<WafPolicyFalsePositiveFeedbackSuggestionResp>({
+    url: `/api/caddy/waf/policy/false-positive-feedback/${id}/suggestion`
+  });
+}
+
+export function applyWafPolicyFalsePositiveFeedbackSuggestion(id: number, data: WafPolicyFalsePositiveFeedbackApplyPayload = {}) {
+  return request<{ exclusionId: number }>({
+    url: `/api/caddy/waf/policy/false-positive-feedback/${id}/apply-suggestion`,
+    method: 'post',
+    data
+  });
+}
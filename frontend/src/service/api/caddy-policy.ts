@@ -4,9 +4,10 @@ export type WafPolicyEngineMode = 'on' | 'off' | 'detectiononly';
 export type WafPolicyAuditEngine = 'off' | 'on' | 'relevantonly';
 export type WafPolicyAuditLogFormat = 'json' | 'native';
 export type WafPolicyCrsTemplate = 'low_fp' | 'balanced' | 'high_blocking' | 'custom';
-export type WafPolicyRevisionStatus = 'draft' | 'published' | 'rolled_back';
+export type WafPolicyRevisionStatus = 'draft' | 'published' | 'rolled_back' | 'canary';
+export type WafPolicyRolloutStatus = 'canary' | 'promoted' | 'aborted';
 export type WafPolicyScopeType = 'global' | 'site' | 'route';
-export type WafPolicyRemoveType = 'id' | 'tag';
+export type WafPolicyRemoveType = 'id' | 'tag' | 'target_id' | 'target_tag';
 
 export interface WafPolicyItem {
   id: number;
@@ -76,6 +77,79 @@ export interface WafPolicyPreviewResp {
   directives: string;
 }
 
+export type WafPolicyReplaySource = 'logs' | 'upload';
+
+export interface WafPolicyReplayParams {
+  source?: WafPolicyReplaySource;
+  sampleSize?: number;
+  hours?: number;
+  host?: string;
+  corpusFormat?: 'auto' | 'har' | 'ndjson';
+}
+
+export interface WafPolicyReplayItem {
+  ref: string;
+  method: string;
+  host: string;
+  uri: string;
+  status: number;
+  interruptRuleId: number;
+  baselineRuleIds: number[];
+  candidateRuleIds: number[];
+}
+
+export interface WafPolicyReplayResp {
+  policyId: number;
+  baselineRevisionId: number;
+  crsVersion: string;
+  total: number;
+  baselineBlocked: number;
+  candidateBlocked: number;
+  newlyBlockedCount: number;
+  newlyUnblockedCount: number;
+  newlyBlocked: WafPolicyReplayItem[];
+  newlyUnblocked: WafPolicyReplayItem[];
+  warnings: string[];
+  durationMs: number;
+}
+
+export interface WafPolicyRolloutItem {
+  id: number;
+  policyId: number;
+  policyName: string;
+  baselineRevisionId: number;
+  candidateRevisionId: number;
+  bindingIds: number[];
+  status: WafPolicyRolloutStatus;
+  autoPromote: boolean;
+  maxFalsePositiveRate: number;
+  observeHours: number;
+  requestCount: number;
+  wouldBlockCount: number;
+  newlyBlockedCount: number;
+  falsePositiveRate: number;
+  evaluatedAt: string;
+  startedAt: string;
+  finishedAt: string;
+  operator: string;
+  message: string;
+  createdAt: string;
+  updatedAt: string;
+}
+
+export interface WafPolicyRolloutListResp {
+  list: WafPolicyRolloutItem[];
+  total: number;
+}
+
+export interface WafPolicyRolloutPayload {
+  bindingIds: number[];
+  autoPromote?: boolean;
+  maxFalsePositiveRate?: number;
+  observeHours?: number;
+  message?: string;
+}
+
 export interface WafRuleExclusionItem {
   id: number;
   policyId: number;
@@ -88,6 +162,8 @@ export interface WafRuleExclusionItem {
   method: string;
   removeType: WafPolicyRemoveType;
   removeValue: string;
+  removeTarget: string;
+  feedbackId: number;
   createdAt: string;
   updatedAt: string;
 }
@@ -108,6 +184,201 @@ export interface WafRuleExclusionPayload {
   method?: string;
   removeType?: WafPolicyRemoveType;
   removeValue: string;
+  removeTarget?: string;
+}
+
+export interface WafCustomRuleItem {
+  id: number;
+  policyId: number;
+  name: string;
+  description: string;
+  enabled: boolean;
+  ruleId: number;
+  phase: number;
+  priority: number;
+  directive: string;
+  createdAt: string;
+  updatedAt: string;
+}
+
+export interface WafCustomRuleListResp {
+  list: WafCustomRuleItem[];
+  total: number;
+}
+
+export interface WafCustomRulePayload {
+  policyId: number;
+  name?: string;
+  description?: string;
+  enabled?: boolean;
+  phase?: number;
+  priority?: number;
+  directive: string;
+}
+
+export interface WafCustomRuleValidateResp {
+  ruleId: number;
+  phase: number;
+  directive: string;
+}
+
+export type WafIPListAction = 'allow' | 'deny';
+
+export type WafIPListEntryKind = 'ip' | 'country';
+
+export interface WafIPListItem {
+  id: number;
+  policyId: number;
+  bindingId: number;
+  name: string;
+  description: string;
+  action: WafIPListAction;
+  enabled: boolean;
+  entryCount: number;
+  activeEntryCount: number;
+  createdAt: string;
+  updatedAt: string;
+}
+
+export interface WafIPListListResp {
+  list: WafIPListItem[];
+  total: number;
+}
+
+export interface WafIPListPayload {
+  policyId: number;
+  bindingId?: number;
+  name: string;
+  description?: string;
+  action?: WafIPListAction;
+  enabled?: boolean;
+}
+
+export interface WafIPListEntryItem {
+  id: number;
+  listId: number;
+  kind: WafIPListEntryKind;
+  value: string;
+  note: string;
+  source: 'manual' | 'caddy_log' | 'dashboard';
+  sourceRef: string;
+  expiresAt: string;
+  expired: boolean;
+  createdAt: string;
+  updatedAt: string;
+}
+
+export interface WafIPListEntryListResp {
+  list: WafIPListEntryItem[];
+  total: number;
+}
+
+export interface WafIPListEntryPayload {
+  kind?: WafIPListEntryKind;
+  value?: string;
+  caddyLogId?: number;
+  source?: 'manual' | 'dashboard';
+  note?: string;
+  expiresAt?: string;
+  publish?: boolean;
+}
+
+export type WafBanStatus = 'active' | 'expired' | 'revoked';
+
+export interface WafBanRuleItem {
+  id: number;
+  name: string;
+  description: string;
+  enabled: boolean;
+  host: string;
+  statusCodes: string;
+  threshold: number;
+  windowMinutes: number;
+  banMinutes: number;
+  lastEvaluatedAt: string;
+  createdAt: string;
+  updatedAt: string;
+}
+
+export interface WafBanRuleListResp {
+  list: WafBanRuleItem[];
+  total: number;
+}
+
+export interface WafBanRulePayload {
+  name: string;
+  description?: string;
+  enabled?: boolean;
+  host?: string;
+  statusCodes?: string;
+  threshold?: number;
+  windowMinutes?: number;
+  banMinutes?: number;
+}
+
+export interface WafBanItem {
+  id: number;
+  ruleId: number;
+  ruleName: string;
+  ip: string;
+  host: string;
+  hitCount: number;
+  reason: string;
+  status: WafBanStatus;
+  expiresAt: string;
+  revokedAt: string;
+  operator: string;
+  createdAt: string;
+  updatedAt: string;
+}
+
+export interface WafBanListResp {
+  list: WafBanItem[];
+  total: number;
+}
+
+export interface WafBanPayload {
+  ip?: string;
+  caddyLogId?: number;
+  host?: string;
+  banMinutes?: number;
+  reason?: string;
+}
+
+export type WafRateLimitKeyType = 'ip' | 'header' | 'path';
+
+export interface WafRateLimitZoneItem {
+  id: number;
+  policyId: number;
+  bindingId: number;
+  name: string;
+  description: string;
+  enabled: boolean;
+  keyType: WafRateLimitKeyType;
+  keyHeader: string;
+  events: number;
+  windowSeconds: number;
+  burst: number;
+  createdAt: string;
+  updatedAt: string;
+}
+
+export interface WafRateLimitZoneListResp {
+  list: WafRateLimitZoneItem[];
+  total: number;
+}
+
+export interface WafRateLimitZonePayload {
+  policyId: number;
+  bindingId?: number;
+  name: string;
+  description?: string;
+  enabled?: boolean;
+  keyType?: WafRateLimitKeyType;
+  keyHeader?: string;
+  events: number;
+  windowSeconds: number;
+  burst?: number;
 }
 
 export interface WafPolicyBindingItem {
@@ -162,6 +433,10 @@ export function previewWafPolicy(id: number) {
   return request<WafPolicyPreviewResp>({ url: `/api/caddy/waf/policy/${id}/preview`, method: 'post' });
 }
 
+export function replayWafPolicy(id: number, data: WafPolicyReplayParams | FormData) {
+  return request<WafPolicyReplayResp>({ url: `/api/caddy/waf/policy/${id}/replay`, method: 'post', data });
+}
+
 export function validateWafPolicy(id: number) {
   return request<any>({ url: `/api/caddy/waf/policy/${id}/validate`, method: 'post' });
 }
@@ -182,6 +457,31 @@ export function fetchWafPolicyRevisionList(params: { page: number; pageSize: num
   return request<WafPolicyRevisionListResp>({ url: '/api/caddy/waf/policy/revision', params });
 }
 
+export function fetchWafPolicyRolloutList(params: {
+  page: number;
+  pageSize: number;
+  policyId?: number;
+  status?: WafPolicyRolloutStatus | '';
+}) {
+  return request<WafPolicyRolloutListResp>({ url: '/api/caddy/waf/policy/rollout', params });
+}
+
+export function startWafPolicyRollout(id: number, data: WafPolicyRolloutPayload) {
+  return request<WafPolicyRolloutItem>({ url: `/api/caddy/waf/policy/${id}/rollout`, method: 'post', data });
+}
+
+export function evaluateWafPolicyRollout(id: number) {
+  return request<WafPolicyRolloutItem>({ url: `/api/caddy/waf/policy/rollout/${id}/evaluate`, method: 'post' });
+}
+
+export function promoteWafPolicyRollout(id: number, data?: { message?: string }) {
+  return request<WafPolicyRolloutItem>({ url: `/api/caddy/waf/policy/rollout/${id}/promote`, method: 'post', data });
+}
+
+export function abortWafPolicyRollout(id: number, data?: { message?: string }) {
+  return request<WafPolicyRolloutItem>({ url: `/api/caddy/waf/policy/rollout/${id}/abort`, method: 'post', data });
+}
+
 export function fetchWafRuleExclusionList(params: {
   page: number;
   pageSize: number;
@@ -204,6 +504,118 @@ export function deleteWafRuleExclusion(id: number) {
   return request<any>({ url: `/api/caddy/waf/policy/exclusion/${id}`, method: 'delete' });
 }
 
+export function fetchWafCustomRuleList(params: { page: number; pageSize: number; policyId?: number; name?: string }) {
+  return request<WafCustomRuleListResp>({ url: '/api/caddy/waf/policy/custom-rule', params });
+}
+
+export function createWafCustomRule(data: WafCustomRulePayload) {
+  return request<any>({ url: '/api/caddy/waf/policy/custom-rule', method: 'post', data });
+}
+
+export function validateWafCustomRule(data: { directive: string; phase?: number }) {
+  return request<WafCustomRuleValidateResp>({ url: '/api/caddy/waf/policy/custom-rule/validate', method: 'post', data });
+}
+
+export function updateWafCustomRule(id: number, data: WafCustomRulePayload) {
+  return request<any>({ url: `/api/caddy/waf/policy/custom-rule/${id}`, method: 'put', data });
+}
+
+export function deleteWafCustomRule(id: number) {
+  return request<any>({ url: `/api/caddy/waf/policy/custom-rule/${id}`, method: 'delete' });
+}
+
+export function fetchWafIPLists(params: {
+  page: number;
+  pageSize: number;
+  policyId?: number;
+  action?: WafIPListAction | '';
+  name?: string;
+}) {
+  return request<WafIPListListResp>({ url: '/api/caddy/waf/ip-list', params });
+}
+
+export function createWafIPList(data: WafIPListPayload) {
+  return request<any>({ url: '/api/caddy/waf/ip-list', method: 'post', data });
+}
+
+export function updateWafIPList(id: number, data: WafIPListPayload) {
+  return request<any>({ url: `/api/caddy/waf/ip-list/${id}`, method: 'put', data });
+}
+
+export function deleteWafIPList(id: number) {
+  return request<any>({ url: `/api/caddy/waf/ip-list/${id}`, method: 'delete' });
+}
+
+export function fetchWafIPListEntries(id: number, params: { page: number; pageSize: number; keyword?: string }) {
+  return request<WafIPListEntryListResp>({ url: `/api/caddy/waf/ip-list/${id}/entry`, params });
+}
+
+export function createWafIPListEntry(id: number, data: WafIPListEntryPayload) {
+  return request<WafIPListEntryItem>({ url: `/api/caddy/waf/ip-list/${id}/entry`, method: 'post', data });
+}
+
+export function deleteWafIPListEntry(id: number) {
+  return request<any>({ url: `/api/caddy/waf/ip-list/entry/${id}`, method: 'delete' });
+}
+
+export function fetchWafBanRules(params: { page: number; pageSize: number; name?: string }) {
+  return request<WafBanRuleListResp>({ url: '/api/caddy/waf/ban-rule', params });
+}
+
+export function createWafBanRule(data: WafBanRulePayload) {
+  return request<any>({ url: '/api/caddy/waf/ban-rule', method: 'post', data });
+}
+
+export function updateWafBanRule(id: number, data: WafBanRulePayload) {
+  return request<any>({ url: `/api/caddy/waf/ban-rule/${id}`, method: 'put', data });
+}
+
+export function deleteWafBanRule(id: number) {
+  return request<any>({ url: `/api/caddy/waf/ban-rule/${id}`, method: 'delete' });
+}
+
+export function fetchWafBans(params: {
+  page: number;
+  pageSize: number;
+  ruleId?: number;
+  status?: WafBanStatus | '';
+  ip?: string;
+}) {
+  return request<WafBanListResp>({ url: '/api/caddy/waf/ban', params });
+}
+
+export function createWafBan(data: WafBanPayload) {
+  return request<WafBanItem>({ url: '/api/caddy/waf/ban', method: 'post', data });
+}
+
+export function revokeWafBan(id: number) {
+  return request<any>({ url: `/api/caddy/waf/ban/${id}/revoke`, method: 'post' });
+}
+
+export function evaluateWafBans() {
+  return request<any>({ url: '/api/caddy/waf/ban/evaluate', method: 'post' });
+}
+
+export function fetchWafRateLimitZones(params: { page: number; pageSize: number; policyId?: number; name?: string }) {
+  return request<WafRateLimitZoneListResp>({ url: '/api/caddy/waf/rate-limit', params });
+}
+
+export function createWafRateLimitZone(data: WafRateLimitZonePayload) {
+  return request<any>({ url: '/api/caddy/waf/rate-limit', method: 'post', data });
+}
+
+export function validateWafRateLimitZone(data: WafRateLimitZonePayload) {
+  return request<any>({ url: '/api/caddy/waf/rate-limit/validate', method: 'post', data });
+}
+
+export function updateWafRateLimitZone(id: number, data: WafRateLimitZonePayload) {
+  return request<any>({ url: `/api/caddy/waf/rate-limit/${id}`, method: 'put', data });
+}
+
+export function deleteWafRateLimitZone(id: number) {
+  return request<any>({ url: `/api/caddy/waf/rate-limit/${id}`, method: 'delete' });
+}
+
 export function fetchWafPolicyBindingList(params: {
   page: number;
   pageSize: number;
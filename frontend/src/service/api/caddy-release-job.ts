@@ -4,6 +4,47 @@ import type { WafKind } from './caddy-source';
 export type WafReleaseStatus = 'downloaded' | 'verified' | 'active' | 'failed' | 'rolled_back';
 export type WafJobStatus = 'running' | 'success' | 'failed';
 
+export interface WafRuleDiffSummary {
+  baseReleaseId: number;
+  baseVersion: string;
+  targetVersion: string;
+  baseRuleCount: number;
+  targetRuleCount: number;
+  added: number;
+  removed: number;
+  modified: number;
+  paranoiaChanged: number;
+  summary: string;
+}
+
+export interface WafRuleDiffRule {
+  id: number;
+  file: string;
+  msg: string;
+  paranoiaLevel: number;
+  tags: string[];
+}
+
+export interface WafRuleDiffChange {
+  id: number;
+  file: string;
+  msg: string;
+  changes: Array<'paranoia' | 'tags' | 'actions' | 'logic'>;
+  paranoiaBefore: number;
+  paranoiaAfter: number;
+  tagsAdded: string[];
+  tagsRemoved: string[];
+  actionsAdded: string[];
+  actionsRemoved: string[];
+}
+
+export interface WafReleaseDiffResp {
+  summary: WafRuleDiffSummary;
+  added: WafRuleDiffRule[];
+  removed: WafRuleDiffRule[];
+  modified: WafRuleDiffChange[];
+}
+
 export interface WafReleaseItem {
   id: number;
   sourceId: number;
@@ -14,6 +55,7 @@ export interface WafReleaseItem {
   sizeBytes: number;
   storagePath: string;
   status: WafReleaseStatus;
+  ruleDiff?: WafRuleDiffSummary;
   createdAt: string;
   updatedAt: string;
 }
@@ -32,6 +74,7 @@ export interface WafJobItem {
   operator: string;
   status: WafJobStatus;
   message: string;
+  ruleDiff?: WafRuleDiffSummary;
   startedAt: string;
   finishedAt: string;
   createdAt: string;
@@ -55,6 +98,10 @@ export function activateWafRelease(id: number) {
   return request<any>({ url: `/api/caddy/waf/release/${id}/activate`, method: 'post' });
 }
 
+export function fetchWafReleaseDiff(id: number, baseId?: number) {
+  return request<WafReleaseDiffResp>({ url: `/api/caddy/waf/release/${id}/diff`, params: { baseId } });
+}
+
 export function rollbackWafRelease(data: { target?: 'last_good' | 'version'; version?: string }) {
   return request<any>({
     url: '/api/caddy/waf/release/rollback',
@@ -81,3 +128,32 @@ export function clearWafJobs() {
     method: 'post'
   });
 }
+
+export interface WafBundleKeyResp {
+  keyId: string;
+  publicKey: string;
+  trustedKeys: string;
+}
+
+/** 导出版本的签名离线包 */
+export function exportWafReleaseBundle(id: number) {
+  return request<Blob>({
+    url: `/api/caddy/waf/release/${id}/bundle`,
+    responseType: 'blob',
+    timeout: 120000
+  });
+}
+
+/** 导入离线包，FormData 字段：file、publicKeys、activateNow */
+export function importWafBundle(data: FormData) {
+  return request<any>({
+    url: '/api/caddy/waf/release/import',
+    method: 'post',
+    timeout: 240000,
+    data
+  });
+}
+
+export function fetchWafBundleKey() {
+  return request<WafBundleKeyResp>({ url: '/api/caddy/waf/bundle/key' });
+}
@@ -0,0 +1,138 @@
+import { request } from '../request';
+
+export type CaddyRouteKind = 'matcher' | 'reverse_proxy' | 'header' | 'redir' | 'file_server' | 'tls' | 'raw';
+
+export interface CaddyRouteMatcher {
+  name: string;
+  conditions: Array<{ type: string; values: string[] }>;
+}
+
+export interface CaddyRouteReverseProxy {
+  matcher?: string;
+  upstreams: string[];
+  lbPolicy?: string;
+  healthUri?: string;
+}
+
+export interface CaddyRouteHeaderOp {
+  op: 'set' | 'add' | 'delete' | 'default' | 'replace';
+  field: string;
+  value?: string;
+  replace?: string;
+}
+
+export interface CaddyRouteHeader {
+  matcher?: string;
+  ops: CaddyRouteHeaderOp[];
+}
+
+export interface CaddyRouteRedirect {
+  matcher?: string;
+  to: string;
+  code?: string;
+}
+
+export interface CaddyRouteFileServer {
+  matcher?: string;
+  root?: string;
+  browse?: boolean;
+}
+
+export interface CaddyRouteTls {
+  mode: 'internal' | 'email' | 'files';
+  email?: string;
+  certFile?: string;
+  keyFile?: string;
+}
+
+export interface CaddyRouteItem {
+  index?: number;
+  kind: CaddyRouteKind;
+  raw?: string;
+  matcher?: CaddyRouteMatcher;
+  reverseProxy?: CaddyRouteReverseProxy;
+  header?: CaddyRouteHeader;
+  redirect?: CaddyRouteRedirect;
+  fileServer?: CaddyRouteFileServer;
+  tls?: CaddyRouteTls;
+}
+
+export interface CaddySiteItem {
+  index: number;
+  addresses: string[];
+  startLine: number;
+  routes: CaddyRouteItem[];
+}
+
+export interface CaddySiteListResp {
+  configHash: string;
+  sites: CaddySiteItem[];
+}
+
+export interface CaddySiteEditResp {
+  configHash: string;
+  config: string;
+  applied: boolean;
+}
+
+interface CaddySiteEditOptions {
+  configHash: string;
+  dryRun?: boolean;
+}
+
+export function fetchCaddySites(serverId: number) {
+  return request<CaddySiteListResp>({ url: `/api/caddy/server/${serverId}/site` });
+}
+
+export function createCaddySite(serverId: number, data: CaddySiteEditOptions & { addresses: string[] }) {
+  return request<CaddySiteEditResp>({ url: `/api/caddy/server/${serverId}/site`, method: 'post', data });
+}
+
+export function updateCaddySite(
+  serverId: number,
+  siteIndex: number,
+  data: CaddySiteEditOptions & { addresses: string[] }
+) {
+  return request<CaddySiteEditResp>({ url: `/api/caddy/server/${serverId}/site/${siteIndex}`, method: 'put', data });
+}
+
+export function deleteCaddySite(serverId: number, siteIndex: number, params: CaddySiteEditOptions) {
+  return request<CaddySiteEditResp>({
+    url: `/api/caddy/server/${serverId}/site/${siteIndex}`,
+    method: 'delete',
+    params
+  });
+}
+
+export function createCaddyRoute(
+  serverId: number,
+  siteIndex: number,
+  data: CaddySiteEditOptions & { index?: number; route: CaddyRouteItem }
+) {
+  return request<CaddySiteEditResp>({
+    url: `/api/caddy/server/${serverId}/site/${siteIndex}/route`,
+    method: 'post',
+    data
+  });
+}
+
+export function updateCaddyRoute(
+  serverId: number,
+  siteIndex: number,
+  routeIndex: number,
+  data: CaddySiteEditOptions & { route: CaddyRouteItem }
+) {
+  return request<CaddySiteEditResp>({
+    url: `/api/caddy/server/${serverId}/site/${siteIndex}/route/${routeIndex}`,
+    method: 'put',
+    data
+  });
+}
+
+export function deleteCaddyRoute(serverId: number, siteIndex: number, routeIndex: number, params: CaddySiteEditOptions) {
+  return request<CaddySiteEditResp>({
+    url: `/api/caddy/server/${serverId}/site/${siteIndex}/route/${routeIndex}`,
+    method: 'delete',
+    params
+  });
+}
@@ -1,8 +1,9 @@
 import { request } from '../request';
 
 export type WafKind = 'crs' | 'coraza_engine';
-export type WafMode = 'remote' | 'manual';
+export type WafMode = 'remote' | 'manual' | 'mirror';
 export type WafAuthType = 'none' | 'token' | 'basic';
+export type WafSignatureType = 'none' | 'minisign' | 'gpg' | 'cosign';
 
 export interface WafSourceItem {
   id: number;
@@ -13,6 +14,9 @@ export interface WafSourceItem {
   checksumUrl: string;
   proxyUrl?: string;
   authType: WafAuthType;
+  signatureType: WafSignatureType;
+  signatureUrl: string;
+  publicKeys: string;
   schedule: string;
   enabled: boolean;
   autoCheck: boolean;
@@ -38,6 +42,9 @@ export interface WafSourcePayload {
   proxyUrl?: string;
   authType?: WafAuthType;
   authSecret?: string;
+  signatureType?: WafSignatureType;
+  signatureUrl?: string;
+  publicKeys?: string;
   schedule?: string;
   enabled?: boolean;
   autoCheck?: boolean;
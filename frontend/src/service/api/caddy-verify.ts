@@ -0,0 +1,57 @@
+import { request } from '../request';
+
+export interface CaddyHealthProbeItem {
+  id?: number;
+  name: string;
+  url: string;
+  method?: string;
+  /** 覆盖 Host 头 */
+  host?: string;
+  /** 0 表示任意 2xx/3xx */
+  expectStatus?: number;
+  /** 响应体需匹配的正则 */
+  bodyPattern?: string;
+  timeoutSeconds?: number;
+  enabled: boolean;
+}
+
+export interface CaddyApplyVerifySetting {
+  enabled: boolean;
+  /** 加载后观察 caddy_logs 的秒数，0 表示只执行 HTTP 探测 */
+  errorWindowSeconds: number;
+  maxErrorRate: number;
+  minRequests: number;
+  probes: CaddyHealthProbeItem[];
+}
+
+export interface CaddyHealthProbeResult {
+  name: string;
+  url: string;
+  status: number;
+  durationMs: number;
+  passed: boolean;
+  message: string;
+}
+
+export interface CaddyApplyVerifyResult {
+  passed: boolean;
+  message: string;
+  probes: CaddyHealthProbeResult[];
+  errorChecked: boolean;
+  requests: number;
+  errors: number;
+  errorRate: number;
+}
+
+export function fetchCaddyApplyVerifySetting(serverId: number) {
+  return request<CaddyApplyVerifySetting>({ url: `/api/caddy/server/${serverId}/verify` });
+}
+
+export function updateCaddyApplyVerifySetting(serverId: number, data: CaddyApplyVerifySetting) {
+  return request<any>({ url: `/api/caddy/server/${serverId}/verify`, method: 'put', data });
+}
+
+/** 对当前运行的配置立即校验一次，不会触发回滚 */
+export function testCaddyApplyVerify(serverId: number) {
+  return request<CaddyApplyVerifyResult>({ url: `/api/caddy/server/${serverId}/verify/test`, method: 'post' });
+}
@@ -50,7 +50,10 @@ export function fetchCaddyLogs(params: {
   return request<any>({ url: '/api/caddy/logs', params });
 }
 
-export function fetchCaddyConfigHistory(serverId: number, params: { page: number; pageSize: number }) {
+export function fetchCaddyConfigHistory(
+  serverId: number,
+  params: { page: number; pageSize: number; source?: string; knownGood?: boolean }
+) {
   return request<any>({ url: `/api/caddy/server/${serverId}/config/history`, params });
 }
 
@@ -58,14 +61,49 @@ export function fetchCaddyConfigHistoryDetail(serverId: number, historyId: numbe
   return request<any>({ url: `/api/caddy/server/${serverId}/config/history/${historyId}` });
 }
 
-export function rollbackCaddyConfig(serverId: number, historyId: number) {
+/** historyId 省略时回滚到最近的已知良好版本 */
+export function rollbackCaddyConfig(serverId: number, historyId?: number, message?: string) {
   return request<any>({
     url: `/api/caddy/server/${serverId}/config/rollback`,
     method: 'post',
-    data: { historyId }
+    data: { historyId, message }
   });
 }
 
+export function annotateCaddyConfigHistory(
+  serverId: number,
+  historyId: number,
+  data: { message: string; knownGood: boolean }
+) {
+  return request<any>({
+    url: `/api/caddy/server/${serverId}/config/history/${historyId}`,
+    method: 'put',
+    data
+  });
+}
+
+export interface CaddyConfigDiffResp {
+  format: 'caddyfile' | 'json';
+  fromId: number;
+  toId: number;
+  fromLabel: string;
+  toLabel: string;
+  fromHash: string;
+  toHash: string;
+  diff: string;
+  added: number;
+  removed: number;
+  identical: boolean;
+}
+
+/** toId 为 0 时与当前保存的配置比较；format=json 时比较经 /adapt 转换后的 JSON */
+export function fetchCaddyConfigDiff(
+  serverId: number,
+  params: { fromId: number; toId?: number; format?: 'caddyfile' | 'json' }
+) {
+  return request<CaddyConfigDiffResp>({ url: `/api/caddy/server/${serverId}/config/diff`, params });
+}
+
 export * from './caddy-source';
 export * from './caddy-policy';
 export * from './caddy-observe';
@@ -15,6 +15,10 @@ type MessageApi = {
   success: (content: string) => void;
 };
 
+function isTargetRemoveType(removeType: WafPolicyRemoveType) {
+  return removeType === 'target_id' || removeType === 'target_tag';
+}
+
 interface UseWafExclusionOptions {
   message: MessageApi;
   getDefaultPolicyId: () => number;
@@ -56,7 +60,8 @@ export function useWafExclusion(options: UseWafExclusionOptions) {
     path: '',
     method: '' as string | null,
     removeType: 'id' as WafPolicyRemoveType,
-    removeValue: ''
+    removeValue: '',
+    removeTarget: ''
   });
 
   const exclusionModalTitle = computed(() => (exclusionModalMode.value === 'add' ? '新增规则例外' : '编辑规则例外'));
@@ -75,6 +80,15 @@ export function useWafExclusion(options: UseWafExclusionOptions) {
       trigger: 'change'
     },
     removeValue: { required: true, message: '请输入移除值', trigger: 'blur' },
+    removeTarget: {
+      validator(_rule, value: string) {
+        if (isTargetRemoveType(exclusionForm.removeType) && !String(value || '').includes(':')) {
+          return new Error('请输入检查目标，格式如 ARGS:content');
+        }
+        return true;
+      },
+      trigger: ['blur', 'input']
+    },
     host: {
       validator(_rule, value: string) {
         if (exclusionForm.scopeType === 'site' && !String(value || '').trim()) {
@@ -145,6 +159,7 @@ export function useWafExclusion(options: UseWafExclusionOptions) {
     exclusionForm.method = '';
     exclusionForm.removeType = 'id';
     exclusionForm.removeValue = '';
+    exclusionForm.removeTarget = '';
   }
 
   function handleAddExclusion() {
@@ -166,6 +181,7 @@ export function useWafExclusion(options: UseWafExclusionOptions) {
     exclusionForm.method = row.method || '';
     exclusionForm.removeType = row.removeType;
     exclusionForm.removeValue = row.removeValue || '';
+    exclusionForm.removeTarget = row.removeTarget || '';
     exclusionModalVisible.value = true;
   }
 
@@ -180,7 +196,8 @@ export function useWafExclusion(options: UseWafExclusionOptions) {
       path: exclusionForm.path.trim(),
       method: String(exclusionForm.method || '').trim(),
       removeType: exclusionForm.removeType,
-      removeValue: exclusionForm.removeValue.trim()
+      removeValue: exclusionForm.removeValue.trim(),
+      removeTarget: isTargetRemoveType(exclusionForm.removeType) ? exclusionForm.removeTarget.trim() : ''
     };
   }
 
@@ -74,6 +74,7 @@ export function useWafObserve(options: UseWafObserveOptions) {
     blockedCount: 0,
     allowedCount: 0,
     suspectedFalsePositiveCount: 0,
+    rateLimitedCount: 0,
     blockRate: 0
   });
   const policyStatsTable = ref<WafPolicyStatsItem[]>([]);
@@ -249,6 +250,7 @@ export function useWafObserve(options: UseWafObserveOptions) {
           blockedCount: 0,
           allowedCount: 0,
           suspectedFalsePositiveCount: 0,
+          rateLimitedCount: 0,
           blockRate: 0
         };
         policyStatsTable.value = data.list || [];
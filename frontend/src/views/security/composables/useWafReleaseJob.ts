@@ -4,6 +4,7 @@ import {
   activateWafRelease,
   clearWafJobs,
   clearWafReleases,
+  exportWafReleaseBundle,
   fetchWafJobList,
   fetchWafReleaseList,
   rollbackWafRelease,
@@ -112,9 +113,12 @@ export function useWafReleaseJob(options: UseWafReleaseJobOptions) {
   }
 
   function handleActivateRelease(row: WafReleaseItem) {
+    const ruleDiffText = row.ruleDiff
+      ? `规则变更（对比 ${row.ruleDiff.baseVersion}）：${row.ruleDiff.summary}。`
+      : '';
     dialog.warning({
       title: '激活确认',
-      content: `确认激活版本 ${row.version} 吗？`,
+      content: `${ruleDiffText}确认激活版本 ${row.version} 吗？`,
       positiveText: '确认',
       negativeText: '取消',
       async onPositiveClick() {
@@ -128,6 +132,21 @@ export function useWafReleaseJob(options: UseWafReleaseJobOptions) {
     });
   }
 
+  async function handleExportRelease(row: WafReleaseItem) {
+    const { data, error } = await exportWafReleaseBundle(row.id);
+    if (error || !data) return;
+
+    const url = URL.createObjectURL(data);
+    const link = document.createElement('a');
+    link.href = url;
+    link.download = `logflux-waf-${row.kind}-${row.version}.bundle.tar.gz`;
+    document.body.appendChild(link);
+    link.click();
+    document.body.removeChild(link);
+    URL.revokeObjectURL(url);
+    message.success('离线包已导出');
+  }
+
   function handleClearReleases() {
     dialog.warning({
       title: '清空确认',
@@ -245,6 +264,7 @@ export function useWafReleaseJob(options: UseWafReleaseJobOptions) {
     handleReleasePageChange,
     handleReleasePageSizeChange,
     handleActivateRelease,
+    handleExportRelease,
     handleClearReleases,
     openRollbackModal,
     handleSubmitRollback,
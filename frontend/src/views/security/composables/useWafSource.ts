@@ -9,6 +9,7 @@ import {
   type WafAuthType,
   type WafKind,
   type WafMode,
+  type WafSignatureType,
   type WafSourceItem
 } from '@/service/api/caddy-source';
 
@@ -65,6 +66,9 @@ export function useWafSource(options: UseWafSourceOptions) {
     proxyUrl: '',
     authType: 'none' as WafAuthType,
     authSecret: '',
+    signatureType: 'none' as WafSignatureType,
+    signatureUrl: '',
+    publicKeys: '',
     schedule: '',
     enabled: true,
     autoCheck: true,
@@ -80,9 +84,9 @@ export function useWafSource(options: UseWafSourceOptions) {
     mode: { required: true, message: '请选择更新模式', trigger: 'change' },
     url: {
       validator() {
-        if (sourceForm.mode !== 'remote') return true;
+        if (sourceForm.mode === 'manual') return true;
         if (!sourceForm.url.trim()) {
-          return new Error('远程模式下请输入源地址');
+          return new Error('远程或镜像模式下请输入源地址');
         }
         return true;
       },
@@ -155,6 +159,9 @@ export function useWafSource(options: UseWafSourceOptions) {
     sourceForm.proxyUrl = '';
     sourceForm.authType = 'none';
     sourceForm.authSecret = '';
+    sourceForm.signatureType = 'none';
+    sourceForm.signatureUrl = '';
+    sourceForm.publicKeys = '';
     sourceForm.schedule = '';
     sourceForm.enabled = true;
     sourceForm.autoCheck = true;
@@ -217,6 +224,9 @@ export function useWafSource(options: UseWafSourceOptions) {
     sourceForm.proxyUrl = row.proxyUrl || '';
     sourceForm.authType = row.authType;
     sourceForm.authSecret = '';
+    sourceForm.signatureType = row.signatureType || 'none';
+    sourceForm.signatureUrl = row.signatureUrl || '';
+    sourceForm.publicKeys = row.publicKeys || '';
     sourceForm.schedule = row.schedule;
     sourceForm.enabled = row.enabled;
     sourceForm.autoCheck = row.autoCheck;
@@ -239,6 +249,9 @@ export function useWafSource(options: UseWafSourceOptions) {
         proxyUrl: sourceForm.proxyUrl.trim(),
         authType: sourceForm.authType,
         authSecret: sourceForm.authSecret.trim(),
+        signatureType: sourceForm.signatureType,
+        signatureUrl: sourceForm.signatureUrl.trim(),
+        publicKeys: sourceForm.publicKeys.trim(),
         schedule: sourceForm.schedule.trim(),
         enabled: sourceForm.enabled,
         autoCheck: sourceForm.autoCheck,
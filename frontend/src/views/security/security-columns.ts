@@ -11,6 +11,7 @@ import type {
   WafPolicyBindingItem,
   WafPolicyEngineMode,
   WafPolicyItem,
+  WafPolicyRemoveType,
   WafPolicyRevisionItem,
   WafPolicyRevisionStatus,
   WafPolicyScopeType,
@@ -19,6 +20,13 @@ import type {
 import type { WafSourceItem } from '@/service/api/caddy-source';
 import type { BindingEffectiveItem } from './composables/useWafBinding';
 
+const removeTypeLabels: Record<WafPolicyRemoveType, string> = {
+  id: 'removeById',
+  tag: 'removeByTag',
+  target_id: 'removeTargetById',
+  target_tag: 'removeTargetByTag'
+};
+
 export function createSourceColumns(options: {
   handleSyncSource: (row: WafSourceItem, activateNow: boolean) => void;
   handleEditSource: (row: WafSourceItem) => void;
@@ -48,7 +56,7 @@ export function createSourceColumns(options: {
       render(row: WafSourceItem) {
         return h(
           NTag,
-          { type: row.mode === 'remote' ? 'info' : 'default', bordered: false },
+          { type: row.mode === 'remote' ? 'info' : row.mode === 'mirror' ? 'success' : 'default', bordered: false },
           { default: () => row.mode }
         );
       }
@@ -172,6 +180,7 @@ export function createPolicyColumns(options: {
   handlePreviewPolicy: (row: WafPolicyItem) => void;
   handleValidatePolicy: (row: WafPolicyItem) => void;
   handlePublishPolicy: (row: WafPolicyItem) => void;
+  handleDistributePolicy: (row: WafPolicyItem) => void;
   handleEditPolicy: (row: WafPolicyItem) => void;
   handleDeletePolicy: (row: WafPolicyItem) => void;
 }) {
@@ -183,6 +192,7 @@ export function createPolicyColumns(options: {
     handlePreviewPolicy,
     handleValidatePolicy,
     handlePublishPolicy,
+    handleDistributePolicy,
     handleEditPolicy,
     handleDeletePolicy
   } = options;
@@ -246,7 +256,7 @@ export function createPolicyColumns(options: {
     {
       title: '操作',
       key: 'action',
-      width: 380,
+      width: 440,
       fixed: 'right',
       render(row: WafPolicyItem) {
         return h(
@@ -284,6 +294,15 @@ export function createPolicyColumns(options: {
                 },
                 { default: () => '发布' }
               ),
+              h(
+                NButton,
+                {
+                  size: 'small',
+                  secondary: true,
+                  onClick: () => handleDistributePolicy(row)
+                },
+                { default: () => '分发' }
+              ),
               h(NButton, { size: 'small', onClick: () => handleEditPolicy(row) }, { default: () => '编辑' }),
               h(
                 NPopconfirm,
@@ -442,13 +461,14 @@ export function createExclusionColumns(options: {
       title: '类型',
       key: 'removeType',
       width: 120,
-      render: (row: WafRuleExclusionItem) => (row.removeType === 'id' ? 'removeById' : 'removeByTag')
+      render: (row: WafRuleExclusionItem) => removeTypeLabels[row.removeType] || row.removeType
     },
     {
       title: '移除值',
       key: 'removeValue',
       minWidth: 180,
-      ellipsis: { tooltip: true }
+      ellipsis: { tooltip: true },
+      render: (row: WafRuleExclusionItem) => (row.removeTarget ? `${row.removeValue} → ${row.removeTarget}` : row.removeValue)
     },
     { title: '更新时间', key: 'updatedAt', width: 180 },
     {
@@ -626,6 +646,7 @@ export function createPolicyStatsColumns(options: { formatRatePercent: (value: n
     { title: '拦截', key: 'blockedCount', width: 100 },
     { title: '放行', key: 'allowedCount', width: 100 },
     { title: '疑似误报', key: 'suspectedFalsePositiveCount', width: 120 },
+    { title: '限流', key: 'rateLimitedCount', width: 100 },
     {
       title: '拦截率',
       key: 'blockRate',
@@ -830,8 +851,17 @@ export function createReleaseColumns(options: {
   formatBytes: (value: number) => string;
   mapReleaseStatusType: (status: WafReleaseItem['status']) => 'default' | 'warning' | 'error' | 'success' | 'info';
   handleActivateRelease: (row: WafReleaseItem) => void;
+  handleExportRelease: (row: WafReleaseItem) => void;
+  handleDistributeRelease: (row: WafReleaseItem) => void;
 }) {
-  const { mapSourceNameById, formatBytes, mapReleaseStatusType, handleActivateRelease } = options;
+  const {
+    mapSourceNameById,
+    formatBytes,
+    mapReleaseStatusType,
+    handleActivateRelease,
+    handleExportRelease,
+    handleDistributeRelease
+  } = options;
   return [
     { title: 'ID', key: 'id', width: 80 },
     {
@@ -868,6 +898,14 @@ export function createReleaseColumns(options: {
         return h(NTag, { type: mapReleaseStatusType(row.status), bordered: false }, { default: () => row.status });
       }
     },
+    {
+      title: '规则变更',
+      key: 'ruleDiff',
+      minWidth: 240,
+      ellipsis: { tooltip: true },
+      render: (row: WafReleaseItem) =>
+        row.ruleDiff ? `对比 ${row.ruleDiff.baseVersion}：${row.ruleDiff.summary}` : '-'
+    },
     {
       title: '路径',
       key: 'storagePath',
@@ -878,7 +916,7 @@ export function createReleaseColumns(options: {
     {
       title: '操作',
       key: 'action',
-      width: 120,
+      width: 220,
       fixed: 'right',
       render(row: WafReleaseItem) {
         return h(
@@ -896,6 +934,26 @@ export function createReleaseColumns(options: {
                   onClick: () => handleActivateRelease(row)
                 },
                 { default: () => '激活' }
+              ),
+              h(
+                NButton,
+                {
+                  size: 'small',
+                  secondary: true,
+                  disabled: row.status === 'failed' || row.status === 'downloaded',
+                  onClick: () => handleExportRelease(row)
+                },
+                { default: () => '导出' }
+              ),
+              h(
+                NButton,
+                {
+                  size: 'small',
+                  secondary: true,
+                  disabled: row.status === 'failed' || row.status === 'downloaded' || row.kind === 'coraza_engine',
+                  onClick: () => handleDistributeRelease(row)
+                },
+                { default: () => '分发' }
               )
             ]
           }
@@ -978,7 +1036,8 @@ export function createJobColumns(options: {
       key: 'message',
       minWidth: 320,
       ellipsis: { tooltip: true },
-      render: (row: WafJobItem) => mapJobMessage(row.message)
+      render: (row: WafJobItem) =>
+        row.ruleDiff ? `${mapJobMessage(row.message)}（${row.ruleDiff.summary}）` : mapJobMessage(row.message)
     }
   ] satisfies DataTableColumns<WafJobItem>;
 }
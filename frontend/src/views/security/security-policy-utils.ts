@@ -49,6 +49,8 @@ export function mapPolicyRevisionStatusLabel(status: WafPolicyRevisionStatus) {
       return '已发布';
     case 'rolled_back':
       return '已回滚';
+    case 'canary':
+      return '灰度中';
     default:
       return status || '-';
   }